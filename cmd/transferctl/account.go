@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// apiClient calls the API of a running instance, authenticated with TRANSFERS_API_KEY if set
+type apiClient struct {
+	client *http.Client
+	target string
+	key    string
+}
+
+// do sends a request with an optional JSON body and copies the response body to out. A response
+// other than 2xx is returned as an error carrying its status and body.
+func (c *apiClient) do(ctx context.Context, method, path string, body interface{}, out io.Writer) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.target, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.key != "" {
+		req.Header.Set("Authorization", "Bearer "+c.key)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+// newAccountCommand returns the account commands, which go through the API of a running instance
+// like any other client, so they are authorized and audited the same way
+func newAccountCommand() *cobra.Command {
+	api := &apiClient{key: os.Getenv("TRANSFERS_API_KEY")}
+	var timeout time.Duration
+	account := &cobra.Command{
+		Use:   "account",
+		Short: "Look up and create accounts through the API",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			api.client = &http.Client{Timeout: timeout}
+		},
+	}
+	target := os.Getenv("TRANSFERS_API_URL")
+	if target == "" {
+		target = "http://localhost:8080"
+	}
+	account.PersistentFlags().StringVar(&api.target, "target", target, "base URL of the API; TRANSFERS_API_URL sets the default")
+	account.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of the request")
+
+	account.AddCommand(&cobra.Command{
+		Use:   "get <account_id>",
+		Short: "Print an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := accountID(args[0])
+			if err != nil {
+				return err
+			}
+			return api.do(cmd.Context(), http.MethodGet, "/accounts/"+strconv.FormatInt(id, 10), nil, cmd.OutOrStdout())
+		},
+	})
+
+	var balance string
+	create := &cobra.Command{
+		Use:   "create <account_id>",
+		Short: "Create an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := accountID(args[0])
+			if err != nil {
+				return err
+			}
+			initialBalance, err := decimal.NewFromString(balance)
+			if err != nil {
+				return fmt.Errorf("invalid --balance %q: %w", balance, err)
+			}
+			body := map[string]interface{}{"account_id": id, "initial_balance": initialBalance.String()}
+			if err := api.do(cmd.Context(), http.MethodPost, "/accounts", body, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "created account %d\n", id)
+			return nil
+		},
+	}
+	create.Flags().StringVar(&balance, "balance", "0", "initial balance")
+	account.AddCommand(create)
+	return account
+}
+
+// accountID parses an account ID argument
+func accountID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid account ID %q", arg)
+	}
+	return id, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// newSeedCommand returns the command that loads development data: a run of funded accounts to make
+// transfers between. Accounts that already exist are left as they are, so it can be rerun.
+func newSeedCommand() *cobra.Command {
+	var first int64
+	var count int
+	var balance string
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Load development data into the database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			initialBalance, err := decimal.NewFromString(balance)
+			if err != nil {
+				return fmt.Errorf("invalid --balance %q: %w", balance, err)
+			}
+
+			ctx := cmd.Context()
+			_, db, err := openDatabase(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			// Loading the config set the precision of the amount columns
+			if err := models.ValidateAmountPrecision(initialBalance); err != nil {
+				return err
+			}
+
+			accounts := repository.NewAccountRepository(db)
+			created := 0
+			for id := first; id < first+int64(count); id++ {
+				err := accounts.CreateAccount(ctx, id, initialBalance)
+				if stderrors.Is(err, errors.ErrAccountAlreadyExists) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				created++
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "created %d of %d accounts from %d with balance %s\n", created, count, first, initialBalance)
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&first, "first", 1, "ID of the first account")
+	cmd.Flags().IntVar(&count, "accounts", 10, "number of accounts")
+	cmd.Flags().StringVar(&balance, "balance", "1000", "initial balance of each account")
+	return cmd
+}
+
+// newExportCommand returns the command that dumps the transaction history of an account or tenant
+// as newline-delimited JSON, oldest first, in the format of the transaction API. It reads the
+// database directly, so it also works while the API is down; transactions recorded after it
+// starts are left out.
+func newExportCommand() *cobra.Command {
+	var scope models.ExportScope
+	var output string
+	var batch int
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Dump the transactions of an account or tenant",
+		Example: "  transferctl export --account 123 > account-123.ndjson\n  transferctl export --tenant acme --output acme.ndjson",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := scope.Validate(); err != nil {
+				return err
+			}
+			if batch <= 0 {
+				return fmt.Errorf("invalid --batch %d", batch)
+			}
+
+			out := cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer f.Close()
+				out = f
+			}
+			w := bufio.NewWriter(out)
+
+			ctx := cmd.Context()
+			_, db, err := openDatabase(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			transactions := repository.NewTransactionRepository(db)
+			until, afterID, count := time.Now(), int64(0), 0
+			var line []byte
+			for {
+				page, err := transactions.ExportTransactions(ctx, scope, until, afterID, batch)
+				if err != nil {
+					return err
+				}
+				for _, tx := range page {
+					line = append(v1.FromTransaction(tx).AppendJSON(line[:0]), '\n')
+					if _, err := w.Write(line); err != nil {
+						return err
+					}
+					afterID = tx.ID
+				}
+				count += len(page)
+				if len(page) < batch {
+					break
+				}
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "exported %d transactions of %s\n", count, scope.Name())
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&scope.AccountID, "account", 0, "ID of the account to export")
+	cmd.Flags().StringVar(&scope.TenantID, "tenant", "", "ID of the tenant to export, instead of an account")
+	cmd.Flags().StringVar(&output, "output", "", "file to write to (default stdout)")
+	cmd.Flags().IntVar(&batch, "batch", 1000, "transactions read per query")
+	return cmd
+}
@@ -0,0 +1,78 @@
+// Command transferctl is the operator CLI of the transfers service: it applies migrations, seeds
+// development data, exports transaction history, manages accounts through the API and runs the
+// operational tools (preflight, verify, credentials, journal replay, precision widening).
+// Commands that work on the database read DATABASE_URL and the rest of the configuration from the
+// environment like the API does; commands that call the API authenticate with TRANSFERS_API_KEY.
+//
+// Usage:
+//
+//	transferctl migrate [--dry-run]
+//	transferctl seed --accounts 10 --balance 1000
+//	transferctl export --account 123 > account-123.ndjson
+//	transferctl account get 123
+//	transferctl account create 123 --balance 100.00
+//	transferctl preflight
+//	transferctl verify
+//	transferctl credential issue --name ops-admin --scopes admin
+//	transferctl journal replay --journal /var/lib/transfers/journal.log --since 2024-06-01T02:00:00Z
+//	transferctl widen-precision --precision 30 --scale 8
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/database"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		logger.Default().Error("Command failed", "err", err)
+		os.Exit(2)
+	}
+}
+
+// newRootCommand returns transferctl with all its subcommands
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "transferctl",
+		Short:         "Operate the internal transfers service",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(
+		newMigrateCommand(),
+		newSeedCommand(),
+		newExportCommand(),
+		newAccountCommand(),
+		newPreflightCommand(),
+		newVerifyCommand(),
+		newCredentialCommand(),
+		newJournalCommand(),
+		newWidenPrecisionCommand(),
+	)
+	return root
+}
+
+// openDatabase loads the configuration and opens the database it names
+func openDatabase(ctx context.Context) (*config.Config, *sql.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	db, err := database.Open(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return cfg, db, nil
+}
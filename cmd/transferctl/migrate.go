@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/preflight"
+	"github.com/spf13/cobra"
+)
+
+// newMigrateCommand returns the command that applies the migrations the database is missing, in
+// order. There is no migrations table: a migration is applied when the object preflight looks for
+// isn't in the schema, so a database created from migrations/ by hand or by the Postgres image is
+// picked up where it left off.
+func newMigrateCommand() *cobra.Command {
+	var dir string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply the migrations the database is missing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			_, db, err := openDatabase(ctx)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			missing, err := preflight.MissingMigrations(ctx, db)
+			if err != nil {
+				return err
+			}
+			if len(missing) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "schema is up to date (%s)\n", preflight.ExpectedSchemaVersion())
+				return nil
+			}
+			for _, migration := range missing {
+				if dryRun {
+					fmt.Fprintf(cmd.OutOrStdout(), "pending %s\n", migration)
+					continue
+				}
+				sql, err := os.ReadFile(filepath.Join(dir, migration+".sql"))
+				if err != nil {
+					return fmt.Errorf("failed to read migration %s: %w", migration, err)
+				}
+				if _, err := db.ExecContext(ctx, string(sql)); err != nil {
+					return fmt.Errorf("failed to apply migration %s: %w", migration, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "applied %s\n", migration)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "migrations", "directory of the migration files")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the migrations that would be applied")
+	return cmd
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/journal"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/migrate"
+	"github.com/khamiruf/internal_transfers_system_go/internal/preflight"
+	"github.com/khamiruf/internal_transfers_system_go/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+// newPreflightCommand returns the command that checks a deployment is ready to take traffic: the
+// configuration, the database connection and schema, the configured system and ledger accounts,
+// and a dry-run transfer that is rolled back. It exits with status 1 if any check fails and 2 if
+// the checks could not run.
+func newPreflightCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "preflight",
+		Short: "Check a deployment is ready to take traffic",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			report, err := preflight.NewRunner(cfg, nil).Run(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("preflight failed to run: %w", err)
+			}
+			report.Write(cmd.OutOrStdout())
+			if !report.OK() {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
+
+// newVerifyCommand returns the command that checks global invariants on the database and prints a
+// report. It exits with status 1 if any invariant is violated and 2 if the checks could not run.
+func newVerifyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check global invariants on the database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, db, err := openDatabase(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			report, err := verify.NewChecker(db).Run(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("verification failed to run: %w", err)
+			}
+			report.Write(cmd.OutOrStdout())
+			if !report.OK() {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
+
+// newCredentialCommand returns the credential commands
+func newCredentialCommand() *cobra.Command {
+	credential := &cobra.Command{
+		Use:   "credential",
+		Short: "Manage API credentials",
+	}
+	credential.AddCommand(newCredentialIssueCommand())
+	return credential
+}
+
+// newCredentialIssueCommand returns the command that issues an API credential directly in the
+// database and prints its key, which is shown only this once. Use it to create the first admin
+// credential; further credentials can be issued through POST /admin/credentials.
+func newCredentialIssueCommand() *cobra.Command {
+	var name, scopeList, roleList string
+	var signing bool
+	issue := &cobra.Command{
+		Use:   "issue",
+		Short: "Issue an API credential and print its key",
+		Example: `  transferctl credential issue --name ops-admin --scopes admin
+  transferctl credential issue --name payroll --scopes accounts:read,transfers:create
+  transferctl credential issue --name back-office --roles operator
+  transferctl credential issue --name settlement --roles client --signing`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var scopes []auth.Scope
+			for _, scope := range split(scopeList) {
+				scopes = append(scopes, auth.Scope(scope))
+			}
+			var roles []auth.Role
+			for _, role := range split(roleList) {
+				roles = append(roles, auth.Role(role))
+			}
+
+			_, db, err := openDatabase(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			manager := auth.NewManager(auth.NewPostgresStore(db))
+			credential, key, err := manager.Issue(cmd.Context(), name, scopes, roles)
+			if err != nil {
+				return fmt.Errorf("failed to issue credential: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "credential %d (%s): %s\n", credential.ID, credential.Name, key)
+
+			if signing {
+				_, secret, err := manager.IssueSigningSecret(cmd.Context(), credential.ID)
+				if err != nil {
+					return fmt.Errorf("failed to issue signing secret: %w", err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "signing secret: %s\n", secret)
+			}
+			return nil
+		},
+	}
+	issue.Flags().StringVar(&name, "name", "", "name of the credential, recorded as the actor of its requests")
+	issue.Flags().StringVar(&scopeList, "scopes", "", "comma-separated scopes: accounts:read, accounts:write, transfers:create, reports:read, operations, audit:read, admin")
+	issue.Flags().StringVar(&roleList, "roles", "", "comma-separated roles: client, operator, auditor")
+	issue.Flags().BoolVar(&signing, "signing", false, "also issue a secret to sign requests with")
+	return issue
+}
+
+// newJournalCommand returns the request journal commands
+func newJournalCommand() *cobra.Command {
+	j := &cobra.Command{
+		Use:   "journal",
+		Short: "Work with the request journal",
+	}
+	j.AddCommand(newJournalReplayCommand())
+	return j
+}
+
+// newJournalReplayCommand returns the command that replays a request journal onto a running
+// instance, to recover the writes made between the last backup and a failure after the database
+// was restored from it. Entries journaled before --since are skipped. With auth, set
+// TRANSFERS_API_KEY to the key of an admin credential; replayed requests then act as that
+// credential.
+func newJournalReplayCommand() *cobra.Command {
+	var path, target, since string
+	var timeout time.Duration
+	replay := &cobra.Command{
+		Use:     "replay",
+		Short:   "Replay a request journal onto a running instance",
+		Example: "  transferctl journal replay --journal /var/lib/transfers/journal.log --target http://localhost:8080 --since 2024-06-01T02:00:00Z",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			replayer := &journal.Replayer{Client: &http.Client{Timeout: timeout}, Target: target, Header: http.Header{}}
+			if since != "" {
+				t, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					return fmt.Errorf("invalid --since %q: %w", since, err)
+				}
+				replayer.Since = t
+			}
+			if key := os.Getenv("TRANSFERS_API_KEY"); key != "" {
+				replayer.Header.Set("Authorization", "Bearer "+key)
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open journal: %w", err)
+			}
+			defer f.Close()
+
+			result, err := replayer.Replay(cmd.Context(), f)
+			fmt.Fprintf(cmd.OutOrStdout(), "replayed %d, rejected %d, skipped %d\n", result.Replayed, result.Rejected, result.Skipped)
+			if err != nil {
+				return fmt.Errorf("replay stopped: %w", err)
+			}
+			return nil
+		},
+	}
+	replay.Flags().StringVar(&path, "journal", "", "path of the request journal")
+	replay.Flags().StringVar(&target, "target", "http://localhost:8080", "base URL of the instance to replay onto")
+	replay.Flags().StringVar(&since, "since", "", "RFC 3339 time of the restored backup; earlier entries are skipped")
+	replay.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "timeout of each replayed request")
+	replay.MarkFlagRequired("journal")
+	return replay
+}
+
+// newWidenPrecisionCommand returns the command that widens the NUMERIC precision/scale of the
+// amount and balance columns on a live database. After it completes, set AMOUNT_PRECISION and
+// AMOUNT_SCALE to the new values so validation and serialization accept the extra digits.
+func newWidenPrecisionCommand() *cobra.Command {
+	var precision, scale, batchSize int
+	var pause time.Duration
+	var dryRun bool
+	widen := &cobra.Command{
+		Use:   "widen-precision",
+		Short: "Widen the precision of the amount and balance columns",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, db, err := openDatabase(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			migration := migrate.NewPrecisionMigration(db, precision, scale)
+			migration.BatchSize = batchSize
+			migration.DryRun = dryRun
+			if pause > 0 {
+				migration.Pause = pause
+			}
+			if err := migration.Run(cmd.Context()); err != nil {
+				return fmt.Errorf("precision migration failed: %w", err)
+			}
+			logger.Default().Info("Precision migration finished", "precision", precision, "scale", scale)
+			if !dryRun && (cfg.AmountPrecision != int32(precision) || cfg.AmountScale != int32(scale)) {
+				logger.Default().Warn("Amounts are still validated to the old type; set AMOUNT_PRECISION and AMOUNT_SCALE and restart",
+					"amount_precision", precision, "amount_scale", scale)
+			}
+			return nil
+		},
+	}
+	widen.Flags().IntVar(&precision, "precision", 30, "target NUMERIC precision")
+	widen.Flags().IntVar(&scale, "scale", 8, "target NUMERIC scale")
+	widen.Flags().IntVar(&batchSize, "batch", 1000, "rows backfilled per batch")
+	widen.Flags().DurationVar(&pause, "pause", 0, "pause between batches (default 50ms)")
+	widen.Flags().BoolVar(&dryRun, "dry-run", false, "only report the columns that would be widened")
+	return widen
+}
+
+// split splits a comma-separated list, dropping blank entries
+func split(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
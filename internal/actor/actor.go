@@ -0,0 +1,67 @@
+// Package actor carries the identity of whoever made a request, taken from the X-Actor header,
+// from the HTTP request to the service, so status changes can be attributed in the history of a
+// transaction. A request made on behalf of someone else also carries that principal, taken from
+// the X-On-Behalf-Of header.
+package actor
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header carries the identifier of the operator or client system making the request
+const Header = "X-Actor"
+
+// OnBehalfOfHeader carries the principal a request acts for, under a delegation to its actor
+const OnBehalfOfHeader = "X-On-Behalf-Of"
+
+// System is the actor recorded for changes made without an identified actor, e.g. by the sweeper
+const System = "system"
+
+type contextKey struct{}
+
+type onBehalfOfKey struct{}
+
+// WithActor returns a copy of ctx carrying an actor; an empty actor leaves ctx unchanged
+func WithActor(ctx context.Context, actor string) context.Context {
+	if actor == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// FromContext returns the actor carried by ctx, or System if the request has none
+func FromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(contextKey{}).(string); ok {
+		return actor
+	}
+	return System
+}
+
+// WithOnBehalfOf returns a copy of ctx acting for principal; an empty principal leaves ctx
+// unchanged
+func WithOnBehalfOf(ctx context.Context, principal string) context.Context {
+	if principal == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, onBehalfOfKey{}, principal)
+}
+
+// OnBehalfOf returns the principal ctx acts for, or "" if it acts for its actor alone
+func OnBehalfOf(ctx context.Context) string {
+	principal, _ := ctx.Value(onBehalfOfKey{}).(string)
+	return principal
+}
+
+// Middleware copies the X-Actor and X-On-Behalf-Of headers of each request into its context
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(Header); actor != "" {
+			r = r.WithContext(WithActor(r.Context(), actor))
+		}
+		if principal := r.Header.Get(OnBehalfOfHeader); principal != "" {
+			r = r.WithContext(WithOnBehalfOf(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+package actor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/1/reverse", nil)
+	req.Header.Set(Header, "ops:alice")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "ops:alice", seen)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transactions/1/reverse", nil))
+	assert.Equal(t, System, seen)
+}
+
+func TestMiddleware_OnBehalfOf(t *testing.T) {
+	var principal string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = OnBehalfOf(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(OnBehalfOfHeader, "cust-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "cust-1", principal)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transactions", nil))
+	assert.Empty(t, principal)
+}
+
+func TestWithActor(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, System, FromContext(ctx))
+	assert.Equal(t, ctx, WithActor(ctx, ""))
+	assert.Equal(t, "a", FromContext(WithActor(ctx, "a")))
+}
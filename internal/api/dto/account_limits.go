@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// SetAccountLimitsRequest replaces the outbound transfer limits of an account; an omitted or null
+// limit doesn't apply
+type SetAccountLimitsRequest struct {
+	PerTransaction *decimal.Decimal `json:"per_transaction"`
+	Daily          *decimal.Decimal `json:"daily"`
+	Monthly        *decimal.Decimal `json:"monthly"`
+}
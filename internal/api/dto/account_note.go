@@ -0,0 +1,7 @@
+package dto
+
+// AddAccountNoteRequest adds a note to an account, optionally linked to an external support case
+type AddAccountNoteRequest struct {
+	Body   string `json:"body"`
+	CaseID string `json:"case_id,omitempty"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// AdjustmentRequest credits or debits an account by a manual balance adjustment
+type AdjustmentRequest struct {
+	Direction  models.EntrySide        `json:"direction"`
+	Amount     decimal.Decimal         `json:"amount"`
+	ReasonCode models.AdjustmentReason `json:"reason_code"`
+	Note       string                  `json:"note,omitempty"`
+}
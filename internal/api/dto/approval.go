@@ -0,0 +1,6 @@
+package dto
+
+// ReviewTransferRequest approves or rejects a transfer held for approval, with an optional note
+type ReviewTransferRequest struct {
+	Note string `json:"note"`
+}
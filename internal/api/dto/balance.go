@@ -0,0 +1,17 @@
+package dto
+
+import v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+
+// BalanceAsOfResponse is an account's balance at a historical instant
+type BalanceAsOfResponse struct {
+	AccountID int64  `json:"account_id"`
+	At        string `json:"at"`
+	Axis      string `json:"axis"`
+	Balance   string `json:"balance"`
+}
+
+// AccountBalancesResponse lists accounts read from a single snapshot taken at SnapshotAt
+type AccountBalancesResponse struct {
+	SnapshotAt string       `json:"snapshot_at"`
+	Accounts   []v1.Account `json:"accounts"`
+}
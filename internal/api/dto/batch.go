@@ -0,0 +1,39 @@
+package dto
+
+import v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+
+// BatchTransferRequest is a batch of transfers submitted with a batch-level Idempotency-Key header
+type BatchTransferRequest struct {
+	Items []BatchTransferItem `json:"items"`
+}
+
+// BatchTransferItem is one transfer of a batch; Key must be unique within the batch
+type BatchTransferItem struct {
+	Key                  string `json:"key"`
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+}
+
+// BatchTransferResponse reports the outcome of every item of a batch, in submission order
+type BatchTransferResponse struct {
+	BatchKey string                    `json:"batch_key"`
+	Created  int                       `json:"created"`
+	Replayed int                       `json:"replayed"`
+	Failed   int                       `json:"failed"`
+	Items    []BatchTransferItemResult `json:"items"`
+}
+
+// BatchTransferItemResult is the outcome of one batch item
+type BatchTransferItemResult struct {
+	Key         string          `json:"key"`
+	Status      string          `json:"status"`
+	Transaction *v1.Transaction `json:"transaction,omitempty"`
+	Error       *BatchItemError `json:"error,omitempty"`
+}
+
+// BatchItemError describes why a batch item failed
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+
+// BusinessDayReportResponse buckets completed transactions by business date
+type BusinessDayReportResponse struct {
+	From string                  `json:"from"`
+	To   string                  `json:"to"`
+	Days []v1.BusinessDaySummary `json:"days"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AdvanceClockRequest moves the sandbox clock forward, either by a Go duration such as "36h" or to
+// an RFC 3339 time. Exactly one of the two must be set.
+type AdvanceClockRequest struct {
+	Duration string `json:"duration,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// ClockResponse is the time shown by the sandbox clock and how far it is ahead of the wall clock
+type ClockResponse struct {
+	Now           time.Time `json:"now"`
+	OffsetSeconds int64     `json:"offset_seconds"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+
+// AccountClosureResponse is a closed account and, if it was force-closed with a residual
+// balance, the transfer that swept the balance
+type AccountClosureResponse struct {
+	Account          v1.Account      `json:"account"`
+	SweepTransaction *v1.Transaction `json:"sweep_transaction,omitempty"`
+}
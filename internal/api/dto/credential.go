@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/khamiruf/internal_transfers_system_go/internal/auth"
+
+// IssueCredentialRequest issues an API credential granting scopes and roles
+type IssueCredentialRequest struct {
+	Name   string       `json:"name"`
+	Scopes []auth.Scope `json:"scopes"`
+	Roles  []auth.Role  `json:"roles"`
+}
+
+// IssueCredentialResponse is an issued credential with its key, which is never shown again
+type IssueCredentialResponse struct {
+	Credential *auth.Credential `json:"credential"`
+	Key        string           `json:"key"`
+}
+
+// SigningSecretResponse is a credential with its new signing secret, which is never shown again
+type SigningSecretResponse struct {
+	Credential    *auth.Credential `json:"credential"`
+	SigningSecret string           `json:"signing_secret"`
+}
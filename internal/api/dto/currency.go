@@ -0,0 +1,6 @@
+package dto
+
+// SetAccountCurrencyRequest sets the ISO 4217 currency of an account
+type SetAccountCurrencyRequest struct {
+	Currency string `json:"currency"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreateDelegationRequest lets a grantee transfer on behalf of a grantor until expires_at,
+// optionally from one source account and up to max_amount per transfer
+type CreateDelegationRequest struct {
+	Grantor         string           `json:"grantor"`
+	Grantee         string           `json:"grantee"`
+	SourceAccountID int64            `json:"source_account_id,omitempty"`
+	MaxAmount       *decimal.Decimal `json:"max_amount,omitempty"`
+	ExpiresAt       time.Time        `json:"expires_at"`
+}
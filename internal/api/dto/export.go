@@ -0,0 +1,20 @@
+package dto
+
+// ExportTrailer is the last line of a chunk of a transaction history export. Every line before it
+// is a transaction with the resume token that continues the export after it.
+type ExportTrailer struct {
+	Count       int    `json:"count"`
+	ResumeToken string `json:"resume_token"`
+	Complete    bool   `json:"complete"`
+}
+
+// ExportArtifact describes a chunk of a transaction history export archived in the artifact
+// store. The artifact holds the same lines, trailer included, as a streamed chunk.
+type ExportArtifact struct {
+	Key         string `json:"key"`
+	URI         string `json:"uri"`
+	Size        int    `json:"size"`
+	Count       int    `json:"count"`
+	ResumeToken string `json:"resume_token"`
+	Complete    bool   `json:"complete"`
+}
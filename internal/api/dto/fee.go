@@ -0,0 +1,9 @@
+package dto
+
+// CreateFeeRuleRequest adds a fee rule. Without an account ID the rule is global and applies to
+// accounts without rules of their own.
+type CreateFeeRuleRequest struct {
+	AccountID int64  `json:"account_id,omitempty"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// DesignateFloatAccountRequest designates the system float account of a currency
+type DesignateFloatAccountRequest struct {
+	AccountID int64 `json:"account_id"`
+}
+
+// FundingRequest funds the system with money for an account, or defunds it
+type FundingRequest struct {
+	AccountID int64           `json:"account_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	Reference string          `json:"reference,omitempty"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// CreateLedgerAccountRequest adds an account to the ledger chart of accounts. NormalSide defaults
+// to the normal side of AccountType; the opposite side declares a contra account.
+type CreateLedgerAccountRequest struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	AccountType string `json:"account_type"`
+	NormalSide  string `json:"normal_side,omitempty"`
+	AccountID   int64  `json:"account_id,omitempty"`
+}
+
+// UpdateLedgerAccountRequest renames a ledger account and links it to a backing account (none if zero)
+type UpdateLedgerAccountRequest struct {
+	Name      string `json:"name"`
+	AccountID int64  `json:"account_id,omitempty"`
+}
+
+// SetPostingRulesRequest replaces the posting template of a transfer type; legs are numbered in order
+type SetPostingRulesRequest struct {
+	Legs []PostingLeg `json:"legs"`
+}
+
+// PostingLeg debits one side and credits the other with the transfer amount. A side is "@source",
+// "@destination" or a ledger account code.
+type PostingLeg struct {
+	Debit       string `json:"debit"`
+	Credit      string `json:"credit"`
+	Description string `json:"description,omitempty"`
+}
+
+// LedgerEntriesResponse lists the ledger entries posted for a transaction, debits first
+type LedgerEntriesResponse struct {
+	Entries []v1.LedgerEntry `json:"entries"`
+}
+
+// PostingRulesResponse lists posting rules ordered by transfer type and leg
+type PostingRulesResponse struct {
+	Rules []*models.PostingRule `json:"rules"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// LogLevel is the level of the application log: debug, info, warn, error or fatal
+type LogLevel struct {
+	Level string `json:"level"`
+}
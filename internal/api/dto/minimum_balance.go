@@ -0,0 +1,18 @@
+package dto
+
+// SetAccountTypeRequest changes the type of an account
+type SetAccountTypeRequest struct {
+	AccountType string `json:"account_type"`
+}
+
+// AdminTransferRequest is a transfer made by an operator. OverrideMinimumBalance allows the
+// transfer to take the source below the minimum balance of its account type; Actor and Reason
+// are then required and recorded in audit.
+type AdminTransferRequest struct {
+	SourceAccountID        int64  `json:"source_account_id"`
+	DestinationAccountID   int64  `json:"destination_account_id"`
+	Amount                 string `json:"amount"`
+	OverrideMinimumBalance bool   `json:"override_minimum_balance"`
+	Actor                  string `json:"actor"`
+	Reason                 string `json:"reason,omitempty"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// SetOverdraftLimitRequest sets how far below zero the balance of an account may go
+type SetOverdraftLimitRequest struct {
+	OverdraftLimit decimal.Decimal `json:"overdraft_limit"`
+}
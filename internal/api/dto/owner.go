@@ -0,0 +1,6 @@
+package dto
+
+// SetAccountOwnerRequest links an account to an external owner reference
+type SetAccountOwnerRequest struct {
+	OwnerRef string `json:"owner_ref"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// Page is the envelope every list endpoint responds with. NextCursor, passed back as the cursor
+// query parameter, fetches the following page and is omitted on the last one. TotalEstimate is
+// the number of items in the whole list, omitted when it isn't known. AppliedFilters echoes the
+// path and query parameters that narrowed the list, by parameter name.
+type Page[T any] struct {
+	Items          []T               `json:"items"`
+	NextCursor     string            `json:"next_cursor,omitempty"`
+	TotalEstimate  *int              `json:"total_estimate,omitempty"`
+	AppliedFilters map[string]string `json:"applied_filters"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// QueuesResponse is the backlog of the queues of background work and the liveness of the workers
+// draining them; it is healthy if every worker is alive
+type QueuesResponse struct {
+	Healthy bool                     `json:"healthy"`
+	Queues  []*models.QueueDepth     `json:"queues"`
+	Workers []metrics.WorkerLiveness `json:"workers"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/khamiruf/internal_transfers_system_go/internal/warmup"
+
+// ReadinessResponse reports whether the instance may take traffic, with the report of its
+// warm-up once it completed
+type ReadinessResponse struct {
+	Status string         `json:"status"`
+	Warmup *warmup.Report `json:"warmup,omitempty"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// ScheduleTransferRequest is a transfer to be made at ExecuteAt, an RFC 3339 time in the future
+type ScheduleTransferRequest struct {
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	ExecuteAt            string `json:"execute_at"`
+}
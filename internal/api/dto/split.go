@@ -0,0 +1,13 @@
+package dto
+
+// SplitTransferRequest debits the source once and pays every leg in one atomic operation
+type SplitTransferRequest struct {
+	SourceAccountID int64              `json:"source_account_id"`
+	Legs            []SplitTransferLeg `json:"legs"`
+}
+
+// SplitTransferLeg is the part of a split transfer paid to one destination
+type SplitTransferLeg struct {
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// CreateStandingOrderRequest is a transfer repeated at Frequency (daily, weekly or monthly) from
+// StartAt, an RFC 3339 time in the future, until the optional EndAt or MaxOccurrences
+type CreateStandingOrderRequest struct {
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	Frequency            string `json:"frequency"`
+	StartAt              string `json:"start_at"`
+	EndAt                string `json:"end_at,omitempty"`
+	MaxOccurrences       int    `json:"max_occurrences,omitempty"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+// ResolveSuspenseItemRequest re-applies or returns a suspense item on behalf of an operator
+type ResolveSuspenseItemRequest struct {
+	Actor string `json:"actor"`
+	Note  string `json:"note,omitempty"`
+}
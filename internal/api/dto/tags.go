@@ -0,0 +1,6 @@
+package dto
+
+// SetTransactionTagsRequest replaces the tags of a transaction
+type SetTransactionTagsRequest struct {
+	Tags []string `json:"tags"`
+}
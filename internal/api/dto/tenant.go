@@ -0,0 +1,19 @@
+package dto
+
+// PutTenantSettingsRequest replaces the settings of a tenant. Omitted settings keep the
+// deployment-wide behavior.
+type PutTenantSettingsRequest struct {
+	MaxTransferAmount string          `json:"max_transfer_amount,omitempty"`
+	AllowedCurrencies []string        `json:"allowed_currencies,omitempty"`
+	Features          map[string]bool `json:"features,omitempty"`
+	// MaxAccounts and MaxDailyTransactions cap the quotas of the tenant; QuotaWarnPercent is the
+	// share of a cap from which usage is warned about
+	MaxAccounts          *int64 `json:"max_accounts,omitempty"`
+	MaxDailyTransactions *int64 `json:"max_daily_transactions,omitempty"`
+	QuotaWarnPercent     *int   `json:"quota_warn_percent,omitempty"`
+}
+
+// SetAccountTenantRequest assigns an account to a tenant; an empty tenant clears it
+type SetAccountTenantRequest struct {
+	TenantID string `json:"tenant_id"`
+}
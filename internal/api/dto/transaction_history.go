@@ -0,0 +1,9 @@
+package dto
+
+import v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+
+// TransactionHistoryResponse lists the status changes of a transaction, oldest first
+type TransactionHistoryResponse struct {
+	TransactionID int64                        `json:"transaction_id"`
+	History       []v1.TransactionStatusChange `json:"history"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/khamiruf/internal_transfers_system_go/internal/priority"
+
+// TransferJobActionRequest gives the reason an operator cancels or requeues a transfer job
+type TransferJobActionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// TransferJobPriorityRequest moves a queued transfer job to another priority class
+type TransferJobPriorityRequest struct {
+	Priority priority.Class `json:"priority"`
+	Reason   string         `json:"reason,omitempty"`
+}
@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// The balance and transaction endpoints are the hottest in the API, so Account and Transaction
+// encode themselves by appending to a caller-supplied buffer instead of going through the
+// reflection-based encoder. The output is byte-for-byte what encoding/json produces for the
+// struct tags above (including its HTML-safe escaping); the tests in encode_test.go pin this.
+
+// AppendJSON appends the JSON encoding of the account to dst
+func (a Account) AppendJSON(dst []byte) []byte {
+	dst = append(dst, `{"account_id":`...)
+	dst = strconv.AppendInt(dst, a.AccountID, 10)
+	dst = append(dst, `,"balance":`...)
+	dst = appendString(dst, a.Balance)
+	if a.ReservedBalance != "" {
+		dst = append(dst, `,"reserved_balance":`...)
+		dst = appendString(dst, a.ReservedBalance)
+	}
+	if a.OverdraftLimit != "" {
+		dst = append(dst, `,"overdraft_limit":`...)
+		dst = appendString(dst, a.OverdraftLimit)
+	}
+	if a.PerTransactionLimit != "" {
+		dst = append(dst, `,"per_transaction_limit":`...)
+		dst = appendString(dst, a.PerTransactionLimit)
+	}
+	if a.DailyLimit != "" {
+		dst = append(dst, `,"daily_limit":`...)
+		dst = appendString(dst, a.DailyLimit)
+	}
+	if a.MonthlyLimit != "" {
+		dst = append(dst, `,"monthly_limit":`...)
+		dst = appendString(dst, a.MonthlyLimit)
+	}
+	if a.Currency != "" {
+		dst = append(dst, `,"currency":`...)
+		dst = appendString(dst, a.Currency)
+	}
+	dst = append(dst, `,"status":`...)
+	dst = appendString(dst, a.Status)
+	if a.OwnerRef != "" {
+		dst = append(dst, `,"owner_ref":`...)
+		dst = appendString(dst, a.OwnerRef)
+	}
+	if a.TenantID != "" {
+		dst = append(dst, `,"tenant_id":`...)
+		dst = appendString(dst, a.TenantID)
+	}
+	if a.AccountType != "" {
+		dst = append(dst, `,"account_type":`...)
+		dst = appendString(dst, a.AccountType)
+	}
+	if a.LastActivityAt != "" {
+		dst = append(dst, `,"last_activity_at":`...)
+		dst = appendString(dst, a.LastActivityAt)
+	}
+	return append(dst, '}')
+}
+
+// MarshalJSON implements json.Marshaler
+func (a Account) MarshalJSON() ([]byte, error) {
+	return a.AppendJSON(make([]byte, 0, 192)), nil
+}
+
+// AppendJSON appends the JSON encoding of the transaction to dst
+func (t Transaction) AppendJSON(dst []byte) []byte {
+	dst = append(dst, `{"id":`...)
+	dst = strconv.AppendInt(dst, t.ID, 10)
+	dst = append(dst, `,"source_account_id":`...)
+	dst = strconv.AppendInt(dst, t.SourceAccountID, 10)
+	dst = append(dst, `,"destination_account_id":`...)
+	dst = strconv.AppendInt(dst, t.DestinationAccountID, 10)
+	dst = append(dst, `,"amount":`...)
+	dst = appendString(dst, t.Amount)
+	dst = append(dst, `,"status":`...)
+	dst = appendString(dst, t.Status)
+	dst = append(dst, `,"created_at":`...)
+	dst = appendString(dst, t.CreatedAt)
+	dst = append(dst, `,"value_date":`...)
+	dst = appendString(dst, t.ValueDate)
+	if t.BusinessDate != "" {
+		dst = append(dst, `,"business_date":`...)
+		dst = appendString(dst, t.BusinessDate)
+	}
+	dst = append(dst, `,"tags":`...)
+	if t.Tags == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i, tag := range t.Tags {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendString(dst, tag)
+		}
+		dst = append(dst, ']')
+	}
+	if t.ReversalOf != 0 {
+		dst = append(dst, `,"reversal_of":`...)
+		dst = strconv.AppendInt(dst, t.ReversalOf, 10)
+	}
+	if t.ConvertedAmount != "" {
+		dst = append(dst, `,"converted_amount":`...)
+		dst = appendString(dst, t.ConvertedAmount)
+	}
+	if t.FXRate != "" {
+		dst = append(dst, `,"fx_rate":`...)
+		dst = appendString(dst, t.FXRate)
+	}
+	if t.FeeOf != 0 {
+		dst = append(dst, `,"fee_of":`...)
+		dst = strconv.AppendInt(dst, t.FeeOf, 10)
+	}
+	if t.SplitID != 0 {
+		dst = append(dst, `,"split_id":`...)
+		dst = strconv.AppendInt(dst, t.SplitID, 10)
+	}
+	if len(t.Fees) > 0 {
+		dst = append(dst, `,"fees":[`...)
+		for i, fee := range t.Fees {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = fee.appendJSON(dst)
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, '}')
+}
+
+// MarshalJSON implements json.Marshaler
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	return t.AppendJSON(make([]byte, 0, 256)), nil
+}
+
+// appendJSON appends the JSON encoding of f to dst
+func (f Fee) appendJSON(dst []byte) []byte {
+	dst = append(dst, '{')
+	if f.RuleID != 0 {
+		dst = append(dst, `"rule_id":`...)
+		dst = strconv.AppendInt(dst, f.RuleID, 10)
+		dst = append(dst, ',')
+	}
+	dst = append(dst, `"amount":`...)
+	dst = appendString(dst, f.Amount)
+	if f.TransactionID != 0 {
+		dst = append(dst, `,"transaction_id":`...)
+		dst = strconv.AppendInt(dst, f.TransactionID, 10)
+	}
+	return append(dst, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s as a JSON string, escaped the way encoding/json does with HTML escaping
+// on: control characters, quotes, backslashes, <, >, &, U+2028 and U+2029 are escaped and
+// invalid UTF-8 is replaced with U+FFFD
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
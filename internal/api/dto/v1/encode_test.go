@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// reflectAccount and reflectTransaction have the same fields and tags but no methods, so
+// encoding/json encodes them by reflection; they are the reference the hand-rolled encoders match
+type (
+	reflectAccount     Account
+	reflectTransaction Transaction
+)
+
+var (
+	benchAccount = Account{
+		AccountID:           123,
+		Balance:             "100.23344",
+		ReservedBalance:     "20.00000",
+		OverdraftLimit:      "500.00000",
+		PerTransactionLimit: "1000.00000",
+		DailyLimit:          "5000.00000",
+		MonthlyLimit:        "20000.00000",
+		Currency:            "USD",
+		Status:              "active",
+		OwnerRef:            "customer-42",
+		TenantID:            "retail",
+		AccountType:         "standard",
+		LastActivityAt:      "2024-03-01T02:00:00Z",
+	}
+	benchTransaction = Transaction{
+		ID:                   9,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               "100.12345",
+		Status:               "complete",
+		CreatedAt:            "2024-03-01T02:00:00Z",
+		ValueDate:            "2024-03-01T02:00:00Z",
+		BusinessDate:         "2024-03-01",
+		Tags:                 []string{"payroll", "march"},
+	}
+)
+
+func TestAppendJSON_MatchesEncodingJSON(t *testing.T) {
+	accounts := []Account{benchAccount, {AccountID: 1, Balance: "0.00000", Status: "active"}}
+	for _, account := range accounts {
+		expected, err := json.Marshal(reflectAccount(account))
+		require.NoError(t, err)
+		encoded, err := json.Marshal(account)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(encoded))
+	}
+
+	transactions := []Transaction{benchTransaction, {ID: 1, Amount: "1.00000", Tags: []string{}}, {ID: 2}, {ID: 3, Status: "complete", ReversalOf: 2}, {ID: 4, ConvertedAmount: "135.00000", FXRate: "1.35"},
+		{ID: 5, FeeOf: 4}, {ID: 8, SplitID: 3}, {ID: 6, Fees: []Fee{{RuleID: 1, Amount: "1.50000", TransactionID: 7}, {Amount: "0.25000"}}}}
+	for _, tx := range transactions {
+		expected, err := json.Marshal(reflectTransaction(tx))
+		require.NoError(t, err)
+		encoded, err := json.Marshal(tx)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(encoded))
+	}
+}
+
+func TestAppendString_MatchesEncodingJSON(t *testing.T) {
+	fixed := []string{"", "plain", `quote " and \ backslash`, "<script>&</script>", "tab\tnew\nline\r", "\x00\b\f\x1f\x7f", "line\u2028separator\u2029", "caf\xc3", "\xff\xfe", "日本語"}
+	for _, s := range fixed {
+		expected, err := json.Marshal(s)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(appendString(nil, s)), "%q", s)
+	}
+
+	rapid.Check(t, func(t *rapid.T) {
+		// Arbitrary bytes cover invalid UTF-8 as well as every escaped ASCII character
+		s := string(rapid.SliceOf(rapid.Byte()).Draw(t, "bytes"))
+		if rapid.Bool().Draw(t, "valid") {
+			s = rapid.String().Draw(t, "s")
+		}
+		expected, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(appendString(nil, s)); got != string(expected) {
+			t.Fatalf("appendString(%q) = %s, want %s", s, got, expected)
+		}
+	})
+}
+
+func BenchmarkAccountJSON(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(reflectAccount(benchAccount)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 256)
+		for i := 0; i < b.N; i++ {
+			buf = benchAccount.AppendJSON(buf[:0])
+		}
+	})
+}
+
+func BenchmarkTransactionJSON(b *testing.B) {
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(reflectTransaction(benchTransaction)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 256)
+		for i := 0; i < b.N; i++ {
+			buf = benchTransaction.AppendJSON(buf[:0])
+		}
+	})
+}
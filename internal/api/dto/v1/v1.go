@@ -0,0 +1,517 @@
+// Package v1 defines the v1 wire format of API resources and the converters between it and the
+// domain models. Handlers never encode models directly: a model change (money type, timestamp
+// type, new statuses) only needs the converters here updated, and the v1 JSON stays the same.
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// Version is the API version served by this package
+const Version = "v1"
+
+// Account is the v1 representation of an account. Amounts are decimal strings with the
+// stored number of decimal places; timestamps are RFC 3339 in UTC.
+type Account struct {
+	AccountID       int64  `json:"account_id"`
+	Balance         string `json:"balance"`
+	ReservedBalance string `json:"reserved_balance,omitempty"`
+	OverdraftLimit  string `json:"overdraft_limit,omitempty"`
+	// Outbound transfer limits; empty if not set
+	PerTransactionLimit string `json:"per_transaction_limit,omitempty"`
+	DailyLimit          string `json:"daily_limit,omitempty"`
+	MonthlyLimit        string `json:"monthly_limit,omitempty"`
+	Currency            string `json:"currency,omitempty"`
+	Status              string `json:"status"`
+	OwnerRef            string `json:"owner_ref,omitempty"`
+	TenantID            string `json:"tenant_id,omitempty"`
+	AccountType         string `json:"account_type,omitempty"`
+	LastActivityAt      string `json:"last_activity_at,omitempty"`
+}
+
+// Transaction is the v1 representation of a transaction
+type Transaction struct {
+	ID                   int64    `json:"id"`
+	SourceAccountID      int64    `json:"source_account_id"`
+	DestinationAccountID int64    `json:"destination_account_id"`
+	Amount               string   `json:"amount"`
+	Status               string   `json:"status"`
+	CreatedAt            string   `json:"created_at"`
+	ValueDate            string   `json:"value_date"`
+	BusinessDate         string   `json:"business_date,omitempty"`
+	Tags                 []string `json:"tags"`
+	ReversalOf           int64    `json:"reversal_of,omitempty"`
+	ConvertedAmount      string   `json:"converted_amount,omitempty"`
+	FXRate               string   `json:"fx_rate,omitempty"`
+	FeeOf                int64    `json:"fee_of,omitempty"`
+	SplitID              int64    `json:"split_id,omitempty"`
+	Fees                 []Fee    `json:"fees,omitempty"`
+}
+
+// Fee is the v1 representation of a fee charged on a transfer
+type Fee struct {
+	RuleID        int64  `json:"rule_id,omitempty"`
+	Amount        string `json:"amount"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+}
+
+// SplitTransfer is the v1 representation of a split transfer and its legs
+type SplitTransfer struct {
+	ID              int64         `json:"id"`
+	SourceAccountID int64         `json:"source_account_id"`
+	Amount          string        `json:"amount"`
+	CreatedAt       string        `json:"created_at"`
+	Legs            []Transaction `json:"legs"`
+}
+
+// Statement is the v1 representation of an account statement
+type Statement struct {
+	AccountID      int64         `json:"account_id"`
+	Axis           string        `json:"axis"`
+	From           string        `json:"from"`
+	To             string        `json:"to"`
+	OpeningBalance string        `json:"opening_balance"`
+	ClosingBalance string        `json:"closing_balance"`
+	Transactions   []Transaction `json:"transactions"`
+}
+
+// PreAuthorization is the v1 representation of a pre-authorization
+type PreAuthorization struct {
+	ID                   int64  `json:"id"`
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	Status               string `json:"status"`
+	ExpiresAt            string `json:"expires_at"`
+	TransactionID        int64  `json:"transaction_id,omitempty"`
+	ResolvedAt           string `json:"resolved_at,omitempty"`
+	CreatedAt            string `json:"created_at"`
+}
+
+// ScheduledTransfer is the v1 representation of a scheduled transfer
+type ScheduledTransfer struct {
+	ID                   int64  `json:"id"`
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	ExecuteAt            string `json:"execute_at"`
+	Status               string `json:"status"`
+	Attempts             int    `json:"attempts"`
+	NextAttemptAt        string `json:"next_attempt_at,omitempty"`
+	LastError            string `json:"last_error,omitempty"`
+	TransactionID        int64  `json:"transaction_id,omitempty"`
+	ResolvedAt           string `json:"resolved_at,omitempty"`
+	StandingOrderID      int64  `json:"standing_order_id,omitempty"`
+	CreatedAt            string `json:"created_at"`
+}
+
+// TransferApproval is the v1 representation of the approval request of a transfer
+type TransferApproval struct {
+	TransactionID int64  `json:"transaction_id"`
+	Status        string `json:"status"`
+	RequestedBy   string `json:"requested_by"`
+	ReviewedBy    string `json:"reviewed_by,omitempty"`
+	ReviewNote    string `json:"review_note,omitempty"`
+	ReviewedAt    string `json:"reviewed_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// StandingOrder is the v1 representation of a standing order
+type StandingOrder struct {
+	ID                   int64  `json:"id"`
+	SourceAccountID      int64  `json:"source_account_id"`
+	DestinationAccountID int64  `json:"destination_account_id"`
+	Amount               string `json:"amount"`
+	Frequency            string `json:"frequency"`
+	StartAt              string `json:"start_at"`
+	EndAt                string `json:"end_at,omitempty"`
+	MaxOccurrences       int    `json:"max_occurrences,omitempty"`
+	Occurrences          int    `json:"occurrences"`
+	Status               string `json:"status"`
+	NextRunAt            string `json:"next_run_at,omitempty"`
+	CreatedAt            string `json:"created_at"`
+	UpdatedAt            string `json:"updated_at"`
+}
+
+// LedgerAccount is the v1 representation of an account of the ledger chart of accounts
+type LedgerAccount struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Type       string `json:"account_type"`
+	NormalSide string `json:"normal_side"`
+	AccountID  int64  `json:"account_id,omitempty"`
+	Active     bool   `json:"active"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// LedgerEntry is the v1 representation of a ledger entry
+type LedgerEntry struct {
+	ID            int64  `json:"id"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+	AccountID     int64  `json:"account_id"`
+	EntryType     string `json:"entry_type"`
+	Amount        string `json:"amount"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// TransactionStatusChange is the v1 representation of an entry of a transaction's status
+// history. Statuses are reported as recorded, without the fallbacks applied to transactions,
+// since the history exists to show exactly what happened.
+type TransactionStatusChange struct {
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Actor      string `json:"actor"`
+	ChangedAt  string `json:"changed_at"`
+}
+
+// LedgerBalance is the v1 representation of a balance derived from ledger entries
+type LedgerBalance struct {
+	AccountID int64  `json:"account_id"`
+	Credits   string `json:"credits"`
+	Debits    string `json:"debits"`
+	Balance   string `json:"balance"`
+}
+
+// BusinessDaySummary is the v1 representation of the transactions booked on one business date
+type BusinessDaySummary struct {
+	BusinessDate     string `json:"business_date"`
+	TransactionCount int64  `json:"transaction_count"`
+	Volume           string `json:"volume"`
+}
+
+// TransactionRequest is the v1 body of a transfer request
+type TransactionRequest struct {
+	SourceAccountID      int64    `json:"source_account_id"`
+	DestinationAccountID int64    `json:"destination_account_id"`
+	Amount               string   `json:"amount"`
+	Tags                 []string `json:"tags,omitempty"`
+}
+
+// statusFallbacks maps model statuses added after v1 was published to the nearest status v1
+// clients already know, so clients switching on the value don't break. Statuses that v1 defined
+// are passed through unchanged.
+var statusFallbacks = map[string]string{
+	// A reversed transfer did complete; the compensating transfer carries reversal_of
+	"reversed": "complete",
+}
+
+func status(s string) string {
+	if fallback, ok := statusFallbacks[s]; ok {
+		return fallback
+	}
+	return s
+}
+
+// FromAccount converts an account to its v1 representation
+func FromAccount(account *models.Account) Account {
+	accountStatus := account.Status
+	if accountStatus == "" {
+		accountStatus = models.AccountStatusActive
+	}
+	out := Account{
+		AccountID:      account.AccountID,
+		Balance:        models.FormatAmount(account.Balance),
+		Status:         status(string(accountStatus)),
+		Currency:       account.Currency,
+		OwnerRef:       account.OwnerRef,
+		TenantID:       account.TenantID,
+		AccountType:    string(account.Type),
+		LastActivityAt: timestamp(account.LastActivityAt),
+	}
+	if account.Reserved.IsPositive() {
+		out.ReservedBalance = models.FormatAmount(account.Reserved)
+	}
+	if account.OverdraftLimit.IsPositive() {
+		out.OverdraftLimit = models.FormatAmount(account.OverdraftLimit)
+	}
+	if limit := account.Limits.PerTransaction; limit != nil {
+		out.PerTransactionLimit = models.FormatAmount(*limit)
+	}
+	if limit := account.Limits.Daily; limit != nil {
+		out.DailyLimit = models.FormatAmount(*limit)
+	}
+	if limit := account.Limits.Monthly; limit != nil {
+		out.MonthlyLimit = models.FormatAmount(*limit)
+	}
+	return out
+}
+
+// FromAccounts converts a list of accounts; the result is never nil
+func FromAccounts(accounts []*models.Account) []Account {
+	out := make([]Account, 0, len(accounts))
+	for _, account := range accounts {
+		out = append(out, FromAccount(account))
+	}
+	return out
+}
+
+// FromTransaction converts a transaction to its v1 representation
+func FromTransaction(tx *models.Transaction) Transaction {
+	tags := tx.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	var convertedAmount, fxRate string
+	if tx.ConvertedAmount != nil {
+		convertedAmount = models.FormatAmount(*tx.ConvertedAmount)
+	}
+	if tx.FXRate != nil {
+		fxRate = tx.FXRate.String()
+	}
+	var fees []Fee
+	for _, fee := range tx.Fees {
+		fees = append(fees, Fee{RuleID: fee.RuleID, Amount: models.FormatAmount(fee.Amount), TransactionID: fee.TransactionID})
+	}
+	return Transaction{
+		ID:                   tx.ID,
+		SourceAccountID:      tx.SourceAccountID,
+		DestinationAccountID: tx.DestinationAccountID,
+		Amount:               models.FormatAmount(tx.Amount),
+		Status:               status(string(tx.Status)),
+		CreatedAt:            timestamp(tx.CreatedAt),
+		ValueDate:            timestamp(tx.ValueDate),
+		BusinessDate:         tx.BusinessDate,
+		Tags:                 tags,
+		ReversalOf:           tx.ReversalOf,
+		ConvertedAmount:      convertedAmount,
+		FXRate:               fxRate,
+		FeeOf:                tx.FeeOf,
+		SplitID:              tx.SplitID,
+		Fees:                 fees,
+	}
+}
+
+// FromTransactions converts a list of transactions; the result is never nil
+func FromTransactions(transactions []*models.Transaction) []Transaction {
+	out := make([]Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		out = append(out, FromTransaction(tx))
+	}
+	return out
+}
+
+// FromSplitTransfer converts a split transfer and its legs to its v1 representation
+func FromSplitTransfer(split *models.SplitTransfer) SplitTransfer {
+	return SplitTransfer{
+		ID:              split.ID,
+		SourceAccountID: split.SourceAccountID,
+		Amount:          models.FormatAmount(split.Amount),
+		CreatedAt:       timestamp(split.CreatedAt),
+		Legs:            FromTransactions(split.Legs),
+	}
+}
+
+// FromStatement converts a statement to its v1 representation
+func FromStatement(statement *models.Statement) Statement {
+	return Statement{
+		AccountID:      statement.AccountID,
+		Axis:           string(statement.Axis),
+		From:           timestamp(statement.From),
+		To:             timestamp(statement.To),
+		OpeningBalance: models.FormatAmount(statement.OpeningBalance),
+		ClosingBalance: models.FormatAmount(statement.ClosingBalance),
+		Transactions:   FromTransactions(statement.Transactions),
+	}
+}
+
+// FromPreAuthorization converts a pre-authorization to its v1 representation
+func FromPreAuthorization(preAuth *models.PreAuthorization) PreAuthorization {
+	return PreAuthorization{
+		ID:                   preAuth.ID,
+		SourceAccountID:      preAuth.SourceAccountID,
+		DestinationAccountID: preAuth.DestinationAccountID,
+		Amount:               models.FormatAmount(preAuth.Amount),
+		Status:               string(preAuth.Status),
+		ExpiresAt:            timestamp(preAuth.ExpiresAt),
+		TransactionID:        preAuth.TransactionID,
+		ResolvedAt:           timestamp(preAuth.ResolvedAt),
+		CreatedAt:            timestamp(preAuth.CreatedAt),
+	}
+}
+
+// FromScheduledTransfer converts a scheduled transfer to its v1 representation. The next attempt
+// is only shown while the transfer is still waiting to be made.
+func FromScheduledTransfer(transfer *models.ScheduledTransfer) ScheduledTransfer {
+	out := ScheduledTransfer{
+		ID:                   transfer.ID,
+		SourceAccountID:      transfer.SourceAccountID,
+		DestinationAccountID: transfer.DestinationAccountID,
+		Amount:               models.FormatAmount(transfer.Amount),
+		ExecuteAt:            timestamp(transfer.ExecuteAt),
+		Status:               string(transfer.Status),
+		Attempts:             transfer.Attempts,
+		LastError:            transfer.LastError,
+		TransactionID:        transfer.TransactionID,
+		ResolvedAt:           timestamp(transfer.ResolvedAt),
+		StandingOrderID:      transfer.StandingOrderID,
+		CreatedAt:            timestamp(transfer.CreatedAt),
+	}
+	if transfer.IsScheduled() {
+		out.NextAttemptAt = timestamp(transfer.NextAttemptAt)
+	}
+	return out
+}
+
+// FromScheduledTransfers converts scheduled transfers to their v1 representation
+func FromScheduledTransfers(transfers []*models.ScheduledTransfer) []ScheduledTransfer {
+	out := make([]ScheduledTransfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		out = append(out, FromScheduledTransfer(transfer))
+	}
+	return out
+}
+
+// FromTransferApproval converts a transfer approval request to its v1 representation
+func FromTransferApproval(approval *models.TransferApproval) TransferApproval {
+	return TransferApproval{
+		TransactionID: approval.TransactionID,
+		Status:        string(approval.Status),
+		RequestedBy:   approval.RequestedBy,
+		ReviewedBy:    approval.ReviewedBy,
+		ReviewNote:    approval.ReviewNote,
+		ReviewedAt:    timestamp(approval.ReviewedAt),
+		CreatedAt:     timestamp(approval.CreatedAt),
+	}
+}
+
+// FromTransferApprovals converts transfer approval requests to their v1 representation
+func FromTransferApprovals(approvals []*models.TransferApproval) []TransferApproval {
+	out := make([]TransferApproval, 0, len(approvals))
+	for _, approval := range approvals {
+		out = append(out, FromTransferApproval(approval))
+	}
+	return out
+}
+
+// FromStandingOrder converts a standing order to its v1 representation. The next occurrence is
+// only shown while the order is active or paused.
+func FromStandingOrder(order *models.StandingOrder) StandingOrder {
+	out := StandingOrder{
+		ID:                   order.ID,
+		SourceAccountID:      order.SourceAccountID,
+		DestinationAccountID: order.DestinationAccountID,
+		Amount:               models.FormatAmount(order.Amount),
+		Frequency:            string(order.Frequency),
+		StartAt:              timestamp(order.StartAt),
+		EndAt:                timestamp(order.EndAt),
+		MaxOccurrences:       order.MaxOccurrences,
+		Occurrences:          order.Occurrences,
+		Status:               string(order.Status),
+		CreatedAt:            timestamp(order.CreatedAt),
+		UpdatedAt:            timestamp(order.UpdatedAt),
+	}
+	if order.Status == models.StandingOrderStatusActive || order.Status == models.StandingOrderStatusPaused {
+		out.NextRunAt = timestamp(order.NextRunAt)
+	}
+	return out
+}
+
+// FromLedgerAccount converts a ledger account to its v1 representation
+func FromLedgerAccount(account *models.LedgerAccount) LedgerAccount {
+	return LedgerAccount{
+		Code:       account.Code,
+		Name:       account.Name,
+		Type:       string(account.Type),
+		NormalSide: string(account.NormalSide),
+		AccountID:  account.AccountID,
+		Active:     account.Active,
+		CreatedAt:  timestamp(account.CreatedAt),
+		UpdatedAt:  timestamp(account.UpdatedAt),
+	}
+}
+
+// FromLedgerAccounts converts ledger accounts; the result is never nil
+func FromLedgerAccounts(accounts []*models.LedgerAccount) []LedgerAccount {
+	out := make([]LedgerAccount, 0, len(accounts))
+	for _, account := range accounts {
+		out = append(out, FromLedgerAccount(account))
+	}
+	return out
+}
+
+// FromLedgerEntries converts ledger entries; the result is never nil
+func FromLedgerEntries(entries []*models.LedgerEntry) []LedgerEntry {
+	out := make([]LedgerEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, LedgerEntry{
+			ID:            entry.ID,
+			TransactionID: entry.TransactionID,
+			AccountID:     entry.AccountID,
+			EntryType:     string(entry.Side),
+			Amount:        models.FormatAmount(entry.Amount),
+			CreatedAt:     timestamp(entry.CreatedAt),
+		})
+	}
+	return out
+}
+
+// FromTransactionHistory converts a transaction's status history to its v1 representation
+func FromTransactionHistory(history []*models.TransactionStatusChange) []TransactionStatusChange {
+	out := make([]TransactionStatusChange, 0, len(history))
+	for _, change := range history {
+		out = append(out, TransactionStatusChange{
+			FromStatus: string(change.FromStatus),
+			ToStatus:   string(change.ToStatus),
+			Actor:      change.Actor,
+			ChangedAt:  timestamp(change.ChangedAt),
+		})
+	}
+	return out
+}
+
+// FromLedgerBalance converts a derived ledger balance to its v1 representation
+func FromLedgerBalance(balance *models.LedgerBalance) LedgerBalance {
+	return LedgerBalance{
+		AccountID: balance.AccountID,
+		Credits:   models.FormatAmount(balance.Credits),
+		Debits:    models.FormatAmount(balance.Debits),
+		Balance:   models.FormatAmount(balance.Balance),
+	}
+}
+
+// FromBusinessDaySummaries converts business date summaries; the result is never nil
+func FromBusinessDaySummaries(summaries []*models.BusinessDaySummary) []BusinessDaySummary {
+	out := make([]BusinessDaySummary, 0, len(summaries))
+	for _, summary := range summaries {
+		out = append(out, BusinessDaySummary{
+			BusinessDate:     summary.BusinessDate,
+			TransactionCount: summary.TransactionCount,
+			Volume:           models.FormatAmount(summary.Volume),
+		})
+	}
+	return out
+}
+
+// ToTransaction converts a v1 transfer request to a pending transaction. Amount parsing
+// errors are validation errors; business rules are left to models.Transaction.Validate.
+func (r TransactionRequest) ToTransaction() (*models.Transaction, error) {
+	amount, err := decimal.NewFromString(r.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: amount %q is not a decimal number", errors.ErrValidationFailed, r.Amount)
+	}
+	return &models.Transaction{
+		SourceAccountID:      r.SourceAccountID,
+		DestinationAccountID: r.DestinationAccountID,
+		Amount:               amount,
+		Status:               models.TransactionStatusPending,
+		Tags:                 r.Tags,
+	}, nil
+}
+
+// timestamp normalises a model timestamp to RFC 3339 in UTC; unparseable values pass through
+func timestamp(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
+}
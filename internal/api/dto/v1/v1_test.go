@@ -0,0 +1,402 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The expected JSON below is the published v1 wire format: a failing test here means a
+// breaking API change, which belongs in a new version instead.
+
+func TestFromAccount(t *testing.T) {
+	dailyLimit := decimal.NewFromInt(250)
+	tests := []struct {
+		name     string
+		account  *models.Account
+		expected string
+	}{
+		{
+			name:     "fixed decimal places",
+			account:  &models.Account{AccountID: 1, Balance: decimal.RequireFromString("100.5"), Status: models.AccountStatusActive},
+			expected: `{"account_id":1,"balance":"100.50000","status":"active"}`,
+		},
+		{
+			name:     "owner and missing status",
+			account:  &models.Account{AccountID: 2, Balance: decimal.Zero, OwnerRef: "cust-42"},
+			expected: `{"account_id":2,"balance":"0.00000","status":"active","owner_ref":"cust-42"}`,
+		},
+		{
+			name:     "dormant savings account with last activity",
+			account:  &models.Account{AccountID: 3, Balance: decimal.Zero, Status: models.AccountStatusDormant, Type: "savings", LastActivityAt: "2024-03-01T10:00:00+08:00"},
+			expected: `{"account_id":3,"balance":"0.00000","status":"dormant","account_type":"savings","last_activity_at":"2024-03-01T02:00:00Z"}`,
+		},
+		{
+			name:     "funds reserved by pre-authorizations",
+			account:  &models.Account{AccountID: 4, Balance: decimal.NewFromInt(100), Reserved: decimal.RequireFromString("25.5"), Status: models.AccountStatusActive},
+			expected: `{"account_id":4,"balance":"100.00000","reserved_balance":"25.50000","status":"active"}`,
+		},
+		{
+			name:     "overdrawn within its limit",
+			account:  &models.Account{AccountID: 6, Balance: decimal.NewFromInt(-40), OverdraftLimit: decimal.NewFromInt(100), Status: models.AccountStatusActive},
+			expected: `{"account_id":6,"balance":"-40.00000","overdraft_limit":"100.00000","status":"active"}`,
+		},
+		{
+			name:     "outbound limits",
+			account:  &models.Account{AccountID: 7, Balance: decimal.NewFromInt(10), Limits: models.AccountLimits{Daily: &dailyLimit}, Status: models.AccountStatusActive},
+			expected: `{"account_id":7,"balance":"10.00000","daily_limit":"250.00000","status":"active"}`,
+		},
+		{
+			name:     "currency",
+			account:  &models.Account{AccountID: 5, Balance: decimal.NewFromInt(1500), Currency: "JPY", Status: models.AccountStatusActive},
+			expected: `{"account_id":5,"balance":"1500.00000","currency":"JPY","status":"active"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(FromAccount(tt.account))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(encoded))
+		})
+	}
+}
+
+func TestFromTransaction(t *testing.T) {
+	tx := &models.Transaction{
+		ID:                   7,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("12.3"),
+		Status:               models.TransactionStatusComplete,
+		CreatedAt:            "2024-03-01T10:00:00+08:00",
+		ValueDate:            "2024-02-29T00:00:00Z",
+		BusinessDate:         "2024-03-01",
+	}
+
+	encoded, err := json.Marshal(FromTransaction(tx))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 7,
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "12.30000",
+		"status": "complete",
+		"created_at": "2024-03-01T02:00:00Z",
+		"value_date": "2024-02-29T00:00:00Z",
+		"business_date": "2024-03-01",
+		"tags": []
+	}`, string(encoded))
+
+	assert.NotNil(t, FromTransactions(nil))
+}
+
+func TestFromTransaction_Reversal(t *testing.T) {
+	original := FromTransaction(&models.Transaction{ID: 7, Amount: decimal.NewFromInt(5), Status: models.TransactionStatusReversed})
+	assert.Equal(t, "complete", original.Status)
+
+	encoded, err := json.Marshal(FromTransaction(&models.Transaction{ID: 8, Amount: decimal.NewFromInt(5), Status: models.TransactionStatusComplete, ReversalOf: 7}))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"reversal_of":7`)
+}
+
+func TestFromTransaction_CrossCurrency(t *testing.T) {
+	converted, rate := decimal.NewFromInt(135), decimal.RequireFromString("1.35")
+	encoded, err := json.Marshal(FromTransaction(&models.Transaction{ID: 9, Amount: decimal.NewFromInt(100), ConvertedAmount: &converted, FXRate: &rate}))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"amount":"100.00000"`)
+	assert.Contains(t, string(encoded), `"converted_amount":"135.00000","fx_rate":"1.35"`)
+
+	encoded, err = json.Marshal(FromTransaction(&models.Transaction{ID: 10, Amount: decimal.NewFromInt(100)}))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "converted_amount")
+}
+
+func TestFromTransaction_Fees(t *testing.T) {
+	encoded, err := json.Marshal(FromTransaction(&models.Transaction{ID: 11, Amount: decimal.NewFromInt(100), Fees: []models.Fee{
+		{RuleID: 2, Amount: decimal.RequireFromString("1.5"), TransactionID: 12},
+	}}))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"fees":[{"rule_id":2,"amount":"1.50000","transaction_id":12}]`)
+
+	encoded, err = json.Marshal(FromTransaction(&models.Transaction{ID: 12, Amount: decimal.RequireFromString("1.5"), FeeOf: 11}))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"fee_of":11`)
+	assert.NotContains(t, string(encoded), `"fees"`)
+}
+
+func TestFromSplitTransfer(t *testing.T) {
+	split := FromSplitTransfer(&models.SplitTransfer{
+		ID:              3,
+		SourceAccountID: 1,
+		Amount:          decimal.NewFromInt(150),
+		Legs: []*models.Transaction{
+			{ID: 20, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), SplitID: 3},
+			{ID: 21, SourceAccountID: 1, DestinationAccountID: 3, Amount: decimal.NewFromInt(50), SplitID: 3},
+		},
+	})
+	assert.Equal(t, "150.00000", split.Amount)
+	require.Len(t, split.Legs, 2)
+	assert.Equal(t, int64(3), split.Legs[1].SplitID)
+
+	encoded, err := json.Marshal(split)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"split_id":3`)
+
+	empty := FromSplitTransfer(&models.SplitTransfer{ID: 4, Amount: decimal.NewFromInt(1)})
+	assert.NotNil(t, empty.Legs)
+}
+
+func TestFromStatement(t *testing.T) {
+	statement := &models.Statement{
+		AccountID:      1,
+		Axis:           models.TimeAxisEffective,
+		From:           "2024-01-01T00:00:00Z",
+		To:             "2024-02-01T00:00:00Z",
+		OpeningBalance: decimal.NewFromInt(10),
+		ClosingBalance: decimal.NewFromInt(15),
+	}
+
+	encoded, err := json.Marshal(FromStatement(statement))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"account_id": 1,
+		"axis": "effective",
+		"from": "2024-01-01T00:00:00Z",
+		"to": "2024-02-01T00:00:00Z",
+		"opening_balance": "10.00000",
+		"closing_balance": "15.00000",
+		"transactions": []
+	}`, string(encoded))
+}
+
+func TestFromPreAuthorization(t *testing.T) {
+	preAuth := &models.PreAuthorization{
+		ID:                   3,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("40"),
+		Status:               models.PreAuthorizationStatusExecuted,
+		ExpiresAt:            "2024-03-01T10:15:00+08:00",
+		TransactionID:        9,
+		ResolvedAt:           "2024-03-01T10:05:00+08:00",
+		CreatedAt:            "2024-03-01T10:00:00+08:00",
+	}
+
+	encoded, err := json.Marshal(FromPreAuthorization(preAuth))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 3,
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "40.00000",
+		"status": "executed",
+		"expires_at": "2024-03-01T02:15:00Z",
+		"transaction_id": 9,
+		"resolved_at": "2024-03-01T02:05:00Z",
+		"created_at": "2024-03-01T02:00:00Z"
+	}`, string(encoded))
+}
+
+func TestFromScheduledTransfer(t *testing.T) {
+	transfer := &models.ScheduledTransfer{
+		ID:                   5,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("25"),
+		ExecuteAt:            "2024-03-01T09:00:00+08:00",
+		Status:               models.ScheduledTransferStatusScheduled,
+		Attempts:             1,
+		NextAttemptAt:        "2024-03-01T09:05:00+08:00",
+		LastError:            "insufficient balance",
+		CreatedAt:            "2024-02-28T10:00:00+08:00",
+	}
+
+	encoded, err := json.Marshal(FromScheduledTransfer(transfer))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 5,
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "25.00000",
+		"execute_at": "2024-03-01T01:00:00Z",
+		"status": "scheduled",
+		"attempts": 1,
+		"next_attempt_at": "2024-03-01T01:05:00Z",
+		"last_error": "insufficient balance",
+		"created_at": "2024-02-28T02:00:00Z"
+	}`, string(encoded))
+
+	// A resolved transfer has no next attempt
+	transfer.Status, transfer.TransactionID, transfer.LastError = models.ScheduledTransferStatusExecuted, 9, ""
+	transfer.ResolvedAt = "2024-03-01T09:05:01+08:00"
+	executed := FromScheduledTransfer(transfer)
+	assert.Empty(t, executed.NextAttemptAt)
+	assert.Equal(t, int64(9), executed.TransactionID)
+	assert.Equal(t, "2024-03-01T01:05:01Z", executed.ResolvedAt)
+}
+
+func TestFromStandingOrder(t *testing.T) {
+	order := &models.StandingOrder{
+		ID:                   3,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("100"),
+		Frequency:            models.StandingOrderMonthly,
+		StartAt:              "2024-01-31T09:00:00+08:00",
+		MaxOccurrences:       12,
+		Occurrences:          1,
+		Status:               models.StandingOrderStatusActive,
+		NextRunAt:            "2024-02-29T09:00:00+08:00",
+		CreatedAt:            "2024-01-15T10:00:00+08:00",
+		UpdatedAt:            "2024-01-31T09:00:01+08:00",
+	}
+
+	encoded, err := json.Marshal(FromStandingOrder(order))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 3,
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "100.00000",
+		"frequency": "monthly",
+		"start_at": "2024-01-31T01:00:00Z",
+		"max_occurrences": 12,
+		"occurrences": 1,
+		"status": "active",
+		"next_run_at": "2024-02-29T01:00:00Z",
+		"created_at": "2024-01-15T02:00:00Z",
+		"updated_at": "2024-01-31T01:00:01Z"
+	}`, string(encoded))
+
+	// A finished order has no next occurrence
+	order.Status = models.StandingOrderStatusCompleted
+	assert.Empty(t, FromStandingOrder(order).NextRunAt)
+}
+
+func TestFromLedgerAccounts(t *testing.T) {
+	accounts := []*models.LedgerAccount{{
+		Code:       "fee_income",
+		Name:       "Fee income",
+		Type:       models.LedgerAccountTypeIncome,
+		NormalSide: models.EntrySideCredit,
+		AccountID:  900,
+		Active:     true,
+		CreatedAt:  "2024-03-01T10:00:00+08:00",
+		UpdatedAt:  "2024-03-02T10:00:00+08:00",
+	}}
+
+	encoded, err := json.Marshal(FromLedgerAccounts(accounts))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"code": "fee_income",
+		"name": "Fee income",
+		"account_type": "income",
+		"normal_side": "credit",
+		"account_id": 900,
+		"active": true,
+		"created_at": "2024-03-01T02:00:00Z",
+		"updated_at": "2024-03-02T02:00:00Z"
+	}]`, string(encoded))
+
+	assert.NotNil(t, FromLedgerAccounts(nil))
+}
+
+func TestFromLedgerEntries(t *testing.T) {
+	entries := []*models.LedgerEntry{
+		{ID: 1, AccountID: 5, Side: models.EntrySideCredit, Amount: decimal.NewFromInt(100), CreatedAt: "2024-03-01T10:00:00+08:00"},
+		{ID: 2, TransactionID: 9, AccountID: 5, Side: models.EntrySideDebit, Amount: decimal.RequireFromString("12.5"), CreatedAt: "2024-03-01T11:00:00+08:00"},
+	}
+
+	encoded, err := json.Marshal(FromLedgerEntries(entries))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "account_id": 5, "entry_type": "credit", "amount": "100.00000", "created_at": "2024-03-01T02:00:00Z"},
+		{"id": 2, "transaction_id": 9, "account_id": 5, "entry_type": "debit", "amount": "12.50000", "created_at": "2024-03-01T03:00:00Z"}
+	]`, string(encoded))
+	assert.NotNil(t, FromLedgerEntries(nil))
+
+	balance := FromLedgerBalance(&models.LedgerBalance{AccountID: 5, Credits: decimal.NewFromInt(100), Debits: decimal.RequireFromString("12.5"), Balance: decimal.RequireFromString("87.5")})
+	assert.Equal(t, LedgerBalance{AccountID: 5, Credits: "100.00000", Debits: "12.50000", Balance: "87.50000"}, balance)
+}
+
+func TestFromTransactionHistory(t *testing.T) {
+	history := []*models.TransactionStatusChange{
+		{ID: 1, TransactionID: 7, FromStatus: models.TransactionStatusPending, ToStatus: models.TransactionStatusComplete, Actor: "system", ChangedAt: "2024-03-01T10:00:00+08:00"},
+		{ID: 2, TransactionID: 7, FromStatus: models.TransactionStatusComplete, ToStatus: models.TransactionStatusReversed, Actor: "ops:alice", ChangedAt: "2024-03-02T10:00:00+08:00"},
+	}
+
+	encoded, err := json.Marshal(FromTransactionHistory(history))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"from_status": "pending", "to_status": "complete", "actor": "system", "changed_at": "2024-03-01T02:00:00Z"},
+		{"from_status": "complete", "to_status": "reversed", "actor": "ops:alice", "changed_at": "2024-03-02T02:00:00Z"}
+	]`, string(encoded))
+	assert.NotNil(t, FromTransactionHistory(nil))
+}
+
+func TestFromBusinessDaySummaries(t *testing.T) {
+	summaries := []*models.BusinessDaySummary{
+		{BusinessDate: "2024-03-01", TransactionCount: 3, Volume: decimal.RequireFromString("45.5")},
+	}
+
+	encoded, err := json.Marshal(FromBusinessDaySummaries(summaries))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"business_date": "2024-03-01", "transaction_count": 3, "volume": "45.50000"}]`, string(encoded))
+
+	assert.NotNil(t, FromBusinessDaySummaries(nil))
+}
+
+func TestTransactionRequest_ToTransaction(t *testing.T) {
+	var req TransactionRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100.25","tags":["payroll"]}`), &req))
+
+	tx, err := req.ToTransaction()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tx.SourceAccountID)
+	assert.Equal(t, int64(2), tx.DestinationAccountID)
+	assert.True(t, decimal.RequireFromString("100.25").Equal(tx.Amount))
+	assert.Equal(t, models.TransactionStatusPending, tx.Status)
+	assert.Equal(t, []string{"payroll"}, tx.Tags)
+
+	_, err = TransactionRequest{Amount: "ten"}.ToTransaction()
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
+
+func TestFromTransferApproval(t *testing.T) {
+	approval := &models.TransferApproval{
+		TransactionID: 12,
+		Status:        models.ApprovalStatusPending,
+		RequestedBy:   "maker",
+		CreatedAt:     "2024-03-01T09:00:00+08:00",
+	}
+
+	encoded, err := json.Marshal(FromTransferApproval(approval))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"transaction_id": 12,
+		"status": "pending",
+		"requested_by": "maker",
+		"created_at": "2024-03-01T01:00:00Z"
+	}`, string(encoded))
+
+	// A resolved request names its reviewer
+	approval.Status, approval.ReviewedBy, approval.ReviewNote = models.ApprovalStatusApproved, "checker", "ok"
+	approval.ReviewedAt = "2024-03-01T10:00:00+08:00"
+	encoded, err = json.Marshal(FromTransferApproval(approval))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"transaction_id": 12,
+		"status": "approved",
+		"requested_by": "maker",
+		"reviewed_by": "checker",
+		"review_note": "ok",
+		"reviewed_at": "2024-03-01T02:00:00Z",
+		"created_at": "2024-03-01T01:00:00Z"
+	}`, string(encoded))
+}
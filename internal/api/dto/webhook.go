@@ -0,0 +1,17 @@
+package dto
+
+// CreateWebhookRequest registers a webhook endpoint subscribed to EventTypes, or to every event
+// if it is empty
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// UpdateWebhookRequest changes the fields of a webhook that are present
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url,omitempty"`
+	Secret     *string   `json:"secret,omitempty"`
+	Active     *bool     `json:"active,omitempty"`
+	EventTypes *[]string `json:"event_types,omitempty"`
+}
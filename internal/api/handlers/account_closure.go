@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountClosureHandler exposes closing accounts, optionally sweeping their residual balance
+type AccountClosureHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewAccountClosureHandler creates a new account closure handler
+func NewAccountClosureHandler(transactionService service.TransactionService) *AccountClosureHandler {
+	return &AccountClosureHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the account closure endpoint on mux
+func (h *AccountClosureHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("DELETE /accounts/{account_id}", h.Close)
+}
+
+// Close handles DELETE /accounts/{account_id}?force=&sweep_to=
+func (h *AccountClosureHandler) Close(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	query := r.URL.Query()
+	force := false
+	if v := query.Get("force"); v != "" {
+		if force, err = strconv.ParseBool(v); err != nil {
+			response.Error(w, fmt.Errorf("%w: invalid force", errors.ErrValidationFailed))
+			return
+		}
+	}
+	var sweepTo int64
+	if v := query.Get("sweep_to"); v != "" {
+		if sweepTo, err = strconv.ParseInt(v, 10, 64); err != nil || sweepTo <= 0 {
+			response.Error(w, fmt.Errorf("%w: invalid sweep_to", errors.ErrValidationFailed))
+			return
+		}
+		if !force {
+			response.Error(w, fmt.Errorf("%w: sweep_to requires force", errors.ErrValidationFailed))
+			return
+		}
+	}
+
+	closure, err := h.transactionService.CloseAccount(r.Context(), accountID, force, sweepTo)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	resp := dto.AccountClosureResponse{Account: v1.FromAccount(closure.Account)}
+	if closure.Sweep != nil {
+		sweep := v1.FromTransaction(closure.Sweep)
+		resp.SweepTransaction = &sweep
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountLimitsHandler exposes the outbound transfer limits of accounts
+type AccountLimitsHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountLimitsHandler creates a new account limits handler
+func NewAccountLimitsHandler(accountService service.AccountService) *AccountLimitsHandler {
+	return &AccountLimitsHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the account limits endpoints on mux
+func (h *AccountLimitsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /admin/accounts/{account_id}/limits", h.SetAccountLimits)
+}
+
+// SetAccountLimits handles PUT /admin/accounts/{account_id}/limits
+func (h *AccountLimitsHandler) SetAccountLimits(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.SetAccountLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	limits := models.AccountLimits{PerTransaction: req.PerTransaction, Daily: req.Daily, Monthly: req.Monthly}
+	account, err := h.accountService.SetAccountLimits(r.Context(), accountID, limits)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
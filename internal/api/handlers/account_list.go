@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountListHandler pages through all accounts for operational review
+type AccountListHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountListHandler creates a new account list handler
+func NewAccountListHandler(accountService service.AccountService) *AccountListHandler {
+	return &AccountListHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the admin account listing on mux
+func (h *AccountListHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/accounts", h.List)
+}
+
+// List handles GET /admin/accounts?sort=&order=&min_balance=&max_balance=&limit=&cursor=
+func (h *AccountListHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "sort", "order", "min_balance", "max_balance")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	filter := models.AccountListFilter{Sort: models.AccountSort(q.filter("sort"))}
+	switch q.filter("order") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		response.Error(w, fmt.Errorf("%w: invalid order %q", errors.ErrValidationFailed, q.filter("order")))
+		return
+	}
+	if filter.MinBalance, err = queryAmount(q.filter("min_balance"), "min_balance"); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if filter.MaxBalance, err = queryAmount(q.filter("max_balance"), "max_balance"); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	page, err := h.accountService.ListAccounts(r.Context(), filter, q.limit, q.cursor)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromAccounts(page.Accounts), page.NextCursor, nil)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountNotesHandler exposes the operator notes on accounts
+type AccountNotesHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountNotesHandler creates a new account notes handler
+func NewAccountNotesHandler(accountService service.AccountService) *AccountNotesHandler {
+	return &AccountNotesHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the account note endpoints on mux
+func (h *AccountNotesHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /accounts/{account_id}/notes", h.List)
+	mux.HandleFunc("POST /accounts/{account_id}/notes", h.Add)
+}
+
+// List handles GET /accounts/{account_id}/notes?case_id=&limit=
+func (h *AccountNotesHandler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q, err := parseListQuery(r, "case_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q.scope("account_id", strconv.FormatInt(accountID, 10))
+
+	notes, err := h.accountService.ListAccountNotes(r.Context(), accountID, q.filter("case_id"), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, notes, "", nil)
+}
+
+// Add handles POST /accounts/{account_id}/notes
+func (h *AccountNotesHandler) Add(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var req dto.AddAccountNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	note, err := h.accountService.AddAccountNote(r.Context(), accountID, req.Body, req.CaseID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, note)
+}
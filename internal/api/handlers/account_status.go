@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountStatusHandler exposes the operator actions freezing, unfreezing and closing accounts
+type AccountStatusHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountStatusHandler creates a new account status handler
+func NewAccountStatusHandler(accountService service.AccountService) *AccountStatusHandler {
+	return &AccountStatusHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the account status endpoints on mux
+func (h *AccountStatusHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/accounts/{account_id}/freeze", h.Freeze)
+	mux.HandleFunc("POST /admin/accounts/{account_id}/unfreeze", h.Unfreeze)
+	mux.HandleFunc("POST /admin/accounts/{account_id}/close", h.Close)
+}
+
+// Freeze handles POST /admin/accounts/{account_id}/freeze
+func (h *AccountStatusHandler) Freeze(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, models.AccountStatusFrozen)
+}
+
+// Unfreeze handles POST /admin/accounts/{account_id}/unfreeze
+func (h *AccountStatusHandler) Unfreeze(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, models.AccountStatusActive)
+}
+
+// Close handles POST /admin/accounts/{account_id}/close
+func (h *AccountStatusHandler) Close(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, models.AccountStatusClosed)
+}
+
+// setStatus changes the account of the path to status and writes the account
+func (h *AccountStatusHandler) setStatus(w http.ResponseWriter, r *http.Request, status models.AccountStatus) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	account, err := h.accountService.SetAccountStatus(r.Context(), accountID, status)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
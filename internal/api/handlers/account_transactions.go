@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AccountTransactionsHandler pages through an account's transaction history, optionally filtered
+type AccountTransactionsHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewAccountTransactionsHandler creates a new account transactions handler
+func NewAccountTransactionsHandler(transactionService service.TransactionService) *AccountTransactionsHandler {
+	return &AccountTransactionsHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the account transaction listing on mux
+func (h *AccountTransactionsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /accounts/{account_id}/transactions", h.List)
+}
+
+// List handles GET /accounts/{account_id}/transactions?status=&from=&to=&min_amount=&max_amount=&direction=&limit=&cursor=
+func (h *AccountTransactionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q, err := parseListQuery(r, "status", "direction", "from", "to", "min_amount", "max_amount")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q.scope("account_id", strconv.FormatInt(accountID, 10))
+
+	filter := models.TransactionFilter{
+		Status:    models.TransactionStatus(q.filter("status")),
+		Direction: models.TransactionDirection(q.filter("direction")),
+	}
+	if filter.From, err = queryTime(q.filter("from")); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if filter.To, err = queryTime(q.filter("to")); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if filter.MinAmount, err = queryAmount(q.filter("min_amount"), "min_amount"); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if filter.MaxAmount, err = queryAmount(q.filter("max_amount"), "max_amount"); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	page, err := h.transactionService.ListAccountTransactions(r.Context(), accountID, filter, q.limit, q.cursor)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromTransactions(page.Transactions), page.NextCursor, nil)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// AdjustmentHandler exposes the manual balance adjustments of accounts
+type AdjustmentHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewAdjustmentHandler creates a new adjustment handler
+func NewAdjustmentHandler(transactionService service.TransactionService) *AdjustmentHandler {
+	return &AdjustmentHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the adjustment endpoints on mux
+func (h *AdjustmentHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/accounts/{account_id}/adjustments", h.Adjust)
+	mux.HandleFunc("GET /admin/accounts/{account_id}/adjustments", h.List)
+}
+
+// Adjust handles POST /admin/accounts/{account_id}/adjustments
+func (h *AdjustmentHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var req dto.AdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	adjustment, err := h.transactionService.AdjustBalance(r.Context(), &models.Adjustment{
+		AccountID:  accountID,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		ReasonCode: req.ReasonCode,
+		Note:       req.Note,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, adjustment)
+}
+
+// List handles GET /admin/accounts/{account_id}/adjustments?limit=
+func (h *AdjustmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q.scope("account_id", strconv.FormatInt(accountID, 10))
+
+	adjustments, err := h.transactionService.ListAdjustments(r.Context(), accountID, q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, adjustments, "", nil)
+}
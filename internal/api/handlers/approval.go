@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// ApprovalHandler exposes the review of transfers held for maker-checker approval
+type ApprovalHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler(transactionService service.TransactionService) *ApprovalHandler {
+	return &ApprovalHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the approval endpoints on mux
+func (h *ApprovalHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /transfer-approvals", h.List)
+	mux.HandleFunc("GET /transactions/{id}/approval", h.Get)
+	mux.HandleFunc("POST /transactions/{id}/approve", h.Approve)
+	mux.HandleFunc("POST /transactions/{id}/reject", h.Reject)
+}
+
+// List handles GET /transfer-approvals?status=&limit=
+func (h *ApprovalHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "status")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	approvals, err := h.transactionService.ListTransferApprovals(r.Context(), models.ApprovalStatus(q.filter("status")), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromTransferApprovals(approvals), "", nil)
+}
+
+// Get handles GET /transactions/{id}/approval
+func (h *ApprovalHandler) Get(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	approval, err := h.transactionService.GetTransferApproval(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromTransferApproval(approval))
+}
+
+// Approve handles POST /transactions/{id}/approve and responds with the transfer made
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.transactionService.ApproveTransfer)
+}
+
+// Reject handles POST /transactions/{id}/reject and responds with the rejected transfer
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.transactionService.RejectTransfer)
+}
+
+// review decodes a review of the transfer in the path and applies it with resolve
+func (h *ApprovalHandler) review(w http.ResponseWriter, r *http.Request, resolve func(ctx context.Context, transactionID int64, note string) (*models.Transaction, error)) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.ReviewTransferRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+			return
+		}
+	}
+
+	transaction, err := resolve(r.Context(), transactionID, req.Note)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromTransaction(transaction))
+}
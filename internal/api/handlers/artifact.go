@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/blob"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// ArtifactHandler archives generated artifacts, such as export chunks, in the artifact store and
+// serves them back by key
+type ArtifactHandler struct {
+	transactionService service.TransactionService
+	store              blob.Store
+	log                *slog.Logger
+}
+
+// NewArtifactHandler creates a new artifact handler; a nil store rejects every request
+func NewArtifactHandler(transactionService service.TransactionService, store blob.Store) *ArtifactHandler {
+	return &ArtifactHandler{transactionService: transactionService, store: store, log: logger.Default()}
+}
+
+// RegisterRoutes registers the artifact endpoints on mux
+func (h *ArtifactHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/exports/transactions/archive", h.ArchiveTransactions)
+	mux.HandleFunc("GET /admin/artifacts/{key...}", h.Get)
+}
+
+// ArchiveTransactions handles POST /admin/exports/transactions/archive?account_id=|tenant_id=&resume_token=&chunk_size=
+//
+// The chunk is written to the artifact store in the format of GET /admin/exports/transactions,
+// under a key naming the scope, the time of archiving and the range of transaction IDs. The
+// response carries the resume token of the next chunk.
+func (h *ArtifactHandler) ArchiveTransactions(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		response.Error(w, fmt.Errorf("%w: artifact storage is not enabled", errors.ErrValidationFailed))
+		return
+	}
+	scope, chunkSize, err := exportQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	var line []byte
+	var firstID, lastID int64
+	emit := func(tx *models.Transaction, resumeToken string) error {
+		if firstID == 0 {
+			firstID = tx.ID
+		}
+		lastID = tx.ID
+		line = appendExportLine(line[:0], tx, resumeToken)
+		buf.Write(line)
+		return nil
+	}
+	chunk, err := h.transactionService.ExportTransactions(r.Context(), scope, r.URL.Query().Get("resume_token"), chunkSize, emit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if err := json.NewEncoder(&buf).Encode(dto.ExportTrailer{Count: chunk.Count, ResumeToken: chunk.ResumeToken, Complete: chunk.Complete}); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	key := fmt.Sprintf("exports/transactions/%s/%s-%d-%d.ndjson", scope.Name(), time.Now().UTC().Format("20060102T150405Z"), firstID, lastID)
+	size := buf.Len()
+	if err := h.store.Put(r.Context(), key, &buf, blob.ContentType(key)); err != nil {
+		h.log.ErrorContext(r.Context(), "Failed to archive export", "scope", scope, "key", key, "err", err)
+		response.Error(w, err)
+		return
+	}
+	h.log.InfoContext(r.Context(), "Archived export", "count", chunk.Count, "scope", scope, "uri", h.store.URI(key))
+
+	response.JSON(w, http.StatusCreated, dto.ExportArtifact{
+		Key:         key,
+		URI:         h.store.URI(key),
+		Size:        size,
+		Count:       chunk.Count,
+		ResumeToken: chunk.ResumeToken,
+		Complete:    chunk.Complete,
+	})
+}
+
+// Get handles GET /admin/artifacts/{key...}
+func (h *ArtifactHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		response.Error(w, fmt.Errorf("%w: artifact storage is not enabled", errors.ErrValidationFailed))
+		return
+	}
+
+	object, err := h.store.Get(r.Context(), r.PathValue("key"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	defer object.Body.Close()
+
+	if object.ContentType != "" {
+		w.Header().Set("Content-Type", object.ContentType)
+	}
+	if object.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, object.Body); err != nil {
+		h.log.WarnContext(r.Context(), "Serving artifact cut short", "key", r.PathValue("key"), "err", err)
+	}
+}
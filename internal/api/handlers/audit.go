@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// Bounds on the number of audit entries listed
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler exposes the audit log
+type AuditHandler struct {
+	audit repository.AuditRepository
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(audit repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// RegisterRoutes registers the audit endpoints on mux
+func (h *AuditHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/audit", h.List)
+}
+
+// List handles GET /admin/audit?action=&account_id=&limit=
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "action", "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	filter := models.AuditFilter{Action: q.filter("action"), Limit: defaultAuditLimit}
+	if v := q.filter("account_id"); v != "" {
+		accountID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || accountID <= 0 {
+			response.Error(w, fmt.Errorf("%w: invalid account_id", errors.ErrValidationFailed))
+			return
+		}
+		filter.AccountID = accountID
+	}
+	if q.limit > 0 {
+		filter.Limit = min(q.limit, maxAuditLimit)
+	}
+
+	entries, err := h.audit.ListEntries(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, entries, "", nil)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// BalanceHandler reads the balances of several accounts at once and reconstructs account
+// balances at historical instants
+type BalanceHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewBalanceHandler creates a new balance handler
+func NewBalanceHandler(transactionService service.TransactionService) *BalanceHandler {
+	return &BalanceHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the balance endpoints on mux
+func (h *BalanceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /accounts/balances", h.GetBalances)
+	mux.HandleFunc("GET /accounts/{account_id}/balance", h.GetBalanceAsOf)
+}
+
+// GetBalances handles GET /accounts/balances?account_ids=1,2,3
+func (h *BalanceHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	var accountIDs []int64
+	for _, v := range strings.Split(r.URL.Query().Get("account_ids"), ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		accountID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || accountID <= 0 {
+			response.Error(w, fmt.Errorf("%w: invalid account id %q", errors.ErrValidationFailed, v))
+			return
+		}
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	accounts, at, err := h.transactionService.GetAccountBalances(r.Context(), accountIDs)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.AccountBalancesResponse{
+		SnapshotAt: at.UTC().Format(time.RFC3339Nano),
+		Accounts:   v1.FromAccounts(accounts),
+	})
+}
+
+// GetBalanceAsOf handles GET /accounts/{account_id}/balance?at=&axis=
+func (h *BalanceHandler) GetBalanceAsOf(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	query := r.URL.Query()
+	at, err := queryTime(query.Get("at"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if at.IsZero() {
+		response.Error(w, fmt.Errorf("%w: at is required", errors.ErrValidationFailed))
+		return
+	}
+	axis := models.TimeAxisRecorded
+	if v := query.Get("axis"); v != "" {
+		axis = models.TimeAxis(v)
+	}
+
+	balance, err := h.transactionService.GetBalanceAsOf(r.Context(), accountID, at, axis)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.BalanceAsOfResponse{
+		AccountID: accountID,
+		At:        at.UTC().Format(time.RFC3339),
+		Axis:      string(axis),
+		Balance:   models.FormatAmount(balance),
+	})
+}
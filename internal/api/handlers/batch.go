@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// IdempotencyKeyHeader carries the client's idempotency key
+const IdempotencyKeyHeader = idempotency.Header
+
+// BatchHandler exposes batch transfer submission
+type BatchHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewBatchHandler creates a new batch handler
+func NewBatchHandler(transactionService service.TransactionService) *BatchHandler {
+	return &BatchHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the batch endpoints on mux
+func (h *BatchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /transfers/batch", h.CreateBatch)
+}
+
+// CreateBatch handles POST /transfers/batch
+func (h *BatchHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	batchKey := r.Header.Get(IdempotencyKeyHeader)
+	if batchKey == "" {
+		response.Error(w, fmt.Errorf("%w: %s header is required", errors.ErrValidationFailed, IdempotencyKeyHeader))
+		return
+	}
+
+	var req dto.BatchTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	items := make([]models.BatchItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		amount, err := decimal.NewFromString(item.Amount)
+		if err != nil {
+			response.Error(w, fmt.Errorf("%w: item %q: amount %q is not a decimal number", errors.ErrValidationFailed, item.Key, item.Amount))
+			return
+		}
+		items = append(items, models.BatchItem{
+			Key:                  item.Key,
+			SourceAccountID:      item.SourceAccountID,
+			DestinationAccountID: item.DestinationAccountID,
+			Amount:               amount,
+		})
+	}
+
+	result, err := h.transactionService.CreateTransferBatch(r.Context(), batchKey, items)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	counts := result.Counts()
+	resp := dto.BatchTransferResponse{
+		BatchKey: result.BatchKey,
+		Created:  counts[models.BatchItemCreated],
+		Replayed: counts[models.BatchItemReplayed],
+		Failed:   counts[models.BatchItemFailed],
+		Items:    make([]dto.BatchTransferItemResult, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		itemResult := dto.BatchTransferItemResult{Key: item.Key, Status: string(item.Status)}
+		if item.Transaction != nil {
+			tx := v1.FromTransaction(item.Transaction)
+			itemResult.Transaction = &tx
+		}
+		if item.Err != nil {
+			itemResult.Error = &dto.BatchItemError{Code: errors.Code(item.Err), Message: item.Err.Error()}
+			if response.StatusForError(item.Err) == http.StatusInternalServerError {
+				itemResult.Error.Message = "internal server error"
+			}
+		}
+		resp.Items = append(resp.Items, itemResult)
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// BudgetHandler exposes the latency budget counters
+type BudgetHandler struct {
+	metrics *metrics.Budgets
+}
+
+// NewBudgetHandler creates a new latency budget handler
+func NewBudgetHandler(m *metrics.Budgets) *BudgetHandler {
+	return &BudgetHandler{metrics: m}
+}
+
+// RegisterRoutes registers the latency budget endpoints on mux
+func (h *BudgetHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/metrics/budgets", h.GetStats)
+}
+
+// GetStats handles GET /admin/metrics/budgets
+func (h *BudgetHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.metrics.Stats())
+}
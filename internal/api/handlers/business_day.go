@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// BusinessDayHandler exposes transactions and reports by business date
+type BusinessDayHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewBusinessDayHandler creates a new business day handler
+func NewBusinessDayHandler(transactionService service.TransactionService) *BusinessDayHandler {
+	return &BusinessDayHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the business day endpoints on mux
+func (h *BusinessDayHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /business-days/{date}/transactions", h.ListTransactions)
+	mux.HandleFunc("GET /reports/business-days", h.Report)
+}
+
+// ListTransactions handles GET /business-days/{date}/transactions?limit=
+func (h *BusinessDayHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	businessDate := r.PathValue("date")
+	q.scope("business_date", businessDate)
+
+	transactions, err := h.transactionService.ListTransactionsByBusinessDate(r.Context(), businessDate, q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromTransactions(transactions), "", nil)
+}
+
+// Report handles GET /reports/business-days?from=&to=
+func (h *BusinessDayHandler) Report(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	summaries, err := h.transactionService.GetBusinessDayReport(r.Context(), from, to)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.BusinessDayReportResponse{
+		From: from,
+		To:   to,
+		Days: v1.FromBusinessDaySummaries(summaries),
+	})
+}
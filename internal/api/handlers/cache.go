@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// CacheHandler exposes account cache metrics
+type CacheHandler struct {
+	cache *repository.CachedAccountRepository
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(cache *repository.CachedAccountRepository) *CacheHandler {
+	return &CacheHandler{cache: cache}
+}
+
+// RegisterRoutes registers the cache endpoints on mux
+func (h *CacheHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/cache", h.GetStats)
+}
+
+// GetStats handles GET /admin/cache
+func (h *CacheHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.cache.Stats())
+}
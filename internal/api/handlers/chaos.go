@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/chaos"
+)
+
+// ChaosHandler exposes the counters of chaos mode
+type ChaosHandler struct {
+	injector *chaos.Injector
+}
+
+// NewChaosHandler creates a new chaos handler
+func NewChaosHandler(injector *chaos.Injector) *ChaosHandler {
+	return &ChaosHandler{injector: injector}
+}
+
+// RegisterRoutes registers the chaos endpoints on mux
+func (h *ChaosHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/chaos", h.GetStats)
+}
+
+// GetStats handles GET /admin/chaos
+func (h *ChaosHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.injector.Stats())
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// ClockHandler lets sandbox operators move the virtual clock forward. It must only be registered
+// in sandbox mode.
+type ClockHandler struct {
+	clock *clock.Virtual
+	hooks []func(context.Context)
+}
+
+// NewClockHandler creates a new clock handler. hooks run after every advance, so background work
+// such as the expiry sweeper catches up with the new time before the response is sent.
+func NewClockHandler(clock *clock.Virtual, hooks ...func(context.Context)) *ClockHandler {
+	return &ClockHandler{clock: clock, hooks: hooks}
+}
+
+// RegisterRoutes registers the clock endpoints on mux
+func (h *ClockHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/clock", h.GetClock)
+	mux.HandleFunc("POST /admin/clock/advance", h.Advance)
+}
+
+// GetClock handles GET /admin/clock
+func (h *ClockHandler) GetClock(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.response(h.clock.Now()))
+}
+
+// Advance handles POST /admin/clock/advance
+func (h *ClockHandler) Advance(w http.ResponseWriter, r *http.Request) {
+	var req dto.AdvanceClockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	var (
+		now time.Time
+		err error
+	)
+	switch {
+	case req.Duration != "" && req.To != "":
+		err = fmt.Errorf("%w: set either duration or to, not both", errors.ErrValidationFailed)
+	case req.Duration != "":
+		var d time.Duration
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			err = fmt.Errorf("%w: invalid duration %q", errors.ErrValidationFailed, req.Duration)
+			break
+		}
+		now, err = h.clock.Advance(d)
+	case req.To != "":
+		var to time.Time
+		if to, err = time.Parse(time.RFC3339, req.To); err != nil {
+			err = fmt.Errorf("%w: invalid time %q, expected RFC 3339", errors.ErrValidationFailed, req.To)
+			break
+		}
+		now, err = h.clock.AdvanceTo(to)
+	default:
+		err = fmt.Errorf("%w: duration or to is required", errors.ErrValidationFailed)
+	}
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	for _, hook := range h.hooks {
+		hook(r.Context())
+	}
+	response.JSON(w, http.StatusOK, h.response(now))
+}
+
+func (h *ClockHandler) response(now time.Time) dto.ClockResponse {
+	return dto.ClockResponse{Now: now.UTC(), OffsetSeconds: int64(h.clock.Offset() / time.Second)}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// CredentialHandler issues and revokes API credentials and their signing secrets
+type CredentialHandler struct {
+	manager *auth.Manager
+}
+
+// NewCredentialHandler creates a new credential handler
+func NewCredentialHandler(manager *auth.Manager) *CredentialHandler {
+	return &CredentialHandler{manager: manager}
+}
+
+// RegisterRoutes registers the credential endpoints on mux
+func (h *CredentialHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/credentials", h.Issue)
+	mux.HandleFunc("GET /admin/credentials", h.List)
+	mux.HandleFunc("POST /admin/credentials/{id}/revoke", h.Revoke)
+	mux.HandleFunc("POST /admin/credentials/{id}/signing-secret", h.IssueSigningSecret)
+}
+
+// Issue handles POST /admin/credentials
+func (h *CredentialHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req dto.IssueCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	credential, key, err := h.manager.Issue(r.Context(), req.Name, req.Scopes, req.Roles)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, dto.IssueCredentialResponse{Credential: credential, Key: key})
+}
+
+// List handles GET /admin/credentials
+func (h *CredentialHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	credentials, err := h.manager.List(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, credentials)
+}
+
+// Revoke handles POST /admin/credentials/{id}/revoke
+func (h *CredentialHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	credentialID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	credential, err := h.manager.Revoke(r.Context(), credentialID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, credential)
+}
+
+// IssueSigningSecret handles POST /admin/credentials/{id}/signing-secret
+func (h *CredentialHandler) IssueSigningSecret(w http.ResponseWriter, r *http.Request) {
+	credentialID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	credential, secret, err := h.manager.IssueSigningSecret(r.Context(), credentialID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.SigningSecretResponse{Credential: credential, SigningSecret: secret})
+}
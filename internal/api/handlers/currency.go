@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// CurrencyHandler exposes the currency of accounts
+type CurrencyHandler struct {
+	accountService service.AccountService
+}
+
+// NewCurrencyHandler creates a new currency handler
+func NewCurrencyHandler(accountService service.AccountService) *CurrencyHandler {
+	return &CurrencyHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the currency endpoints on mux
+func (h *CurrencyHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /accounts/{account_id}/currency", h.SetCurrency)
+}
+
+// SetCurrency handles PUT /accounts/{account_id}/currency
+func (h *CurrencyHandler) SetCurrency(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.SetAccountCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	account, err := h.accountService.SetAccountCurrency(r.Context(), accountID, req.Currency)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// DelegationHandler manages the delegations of transfers on behalf of account owners
+type DelegationHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewDelegationHandler creates a new delegation handler
+func NewDelegationHandler(transactionService service.TransactionService) *DelegationHandler {
+	return &DelegationHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the delegation endpoints on mux
+func (h *DelegationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /admin/delegations", h.Create)
+	mux.HandleFunc("GET /admin/delegations", h.List)
+	mux.HandleFunc("GET /admin/delegations/{id}", h.Get)
+	mux.HandleFunc("POST /admin/delegations/{id}/revoke", h.Revoke)
+}
+
+// Create handles POST /admin/delegations
+func (h *DelegationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateDelegationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	delegation, err := h.transactionService.CreateDelegation(r.Context(), &models.Delegation{
+		Grantor:         req.Grantor,
+		Grantee:         req.Grantee,
+		SourceAccountID: req.SourceAccountID,
+		MaxAmount:       req.MaxAmount,
+		ExpiresAt:       req.ExpiresAt,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, delegation)
+}
+
+// List handles GET /admin/delegations?grantor=&grantee=&limit=
+func (h *DelegationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "grantor", "grantee")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	delegations, err := h.transactionService.ListDelegations(r.Context(), q.filter("grantor"), q.filter("grantee"), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, delegations, "", nil)
+}
+
+// Get handles GET /admin/delegations/{id}
+func (h *DelegationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	delegationID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	delegation, err := h.transactionService.GetDelegation(r.Context(), delegationID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, delegation)
+}
+
+// Revoke handles POST /admin/delegations/{id}/revoke
+func (h *DelegationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	delegationID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	delegation, err := h.transactionService.RevokeDelegation(r.Context(), delegationID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, delegation)
+}
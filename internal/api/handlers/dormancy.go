@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/dormancy"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// DormancyHandler exposes account reactivation and dormancy metrics
+type DormancyHandler struct {
+	accountService service.AccountService
+	job            *dormancy.Job
+}
+
+// NewDormancyHandler creates a new dormancy handler
+func NewDormancyHandler(accountService service.AccountService, job *dormancy.Job) *DormancyHandler {
+	return &DormancyHandler{accountService: accountService, job: job}
+}
+
+// RegisterRoutes registers the dormancy endpoints on mux
+func (h *DormancyHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /accounts/{account_id}/reactivate", h.Reactivate)
+	mux.HandleFunc("GET /admin/dormancy", h.GetStats)
+}
+
+// Reactivate handles POST /accounts/{account_id}/reactivate
+func (h *DormancyHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	account, err := h.accountService.ReactivateAccount(r.Context(), accountID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
+
+// GetStats handles GET /admin/dormancy
+func (h *DormancyHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.job.Stats(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, stats)
+}
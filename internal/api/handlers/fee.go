@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// FeeHandler exposes the fee rules charged on transfers
+type FeeHandler struct {
+	feeService service.FeeService
+}
+
+// NewFeeHandler creates a new fee handler
+func NewFeeHandler(feeService service.FeeService) *FeeHandler {
+	return &FeeHandler{feeService: feeService}
+}
+
+// RegisterRoutes registers the fee rule endpoints on mux
+func (h *FeeHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /fee-rules", h.List)
+	mux.HandleFunc("POST /fee-rules", h.Create)
+	mux.HandleFunc("DELETE /fee-rules/{id}", h.Delete)
+}
+
+// List handles GET /fee-rules
+func (h *FeeHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	rules, err := h.feeService.ListFeeRules(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, rules)
+}
+
+// Create handles POST /fee-rules
+func (h *FeeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateFeeRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	rule, err := h.feeService.CreateFeeRule(r.Context(), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, rule)
+}
+
+// Delete handles DELETE /fee-rules/{id}
+func (h *FeeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if err := h.feeService.DeleteFeeRule(r.Context(), id); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
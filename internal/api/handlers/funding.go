@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// FundingHandler exposes the system float accounts and the funding and defunding of the system
+type FundingHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewFundingHandler creates a new funding handler
+func NewFundingHandler(transactionService service.TransactionService) *FundingHandler {
+	return &FundingHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the funding endpoints on mux
+func (h *FundingHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/float-accounts", h.ListFloatAccounts)
+	mux.HandleFunc("PUT /admin/float-accounts/{currency}", h.DesignateFloatAccount)
+	mux.HandleFunc("POST /admin/float-accounts/{currency}/fund", h.Fund)
+	mux.HandleFunc("POST /admin/float-accounts/{currency}/defund", h.Defund)
+	mux.HandleFunc("GET /admin/float-accounts/{currency}/fundings", h.ListFundings)
+}
+
+// ListFloatAccounts handles GET /admin/float-accounts
+func (h *FundingHandler) ListFloatAccounts(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	floats, err := h.transactionService.ListFloatAccounts(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, floats)
+}
+
+// DesignateFloatAccount handles PUT /admin/float-accounts/{currency}
+func (h *FundingHandler) DesignateFloatAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.DesignateFloatAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	float, err := h.transactionService.DesignateFloatAccount(r.Context(), r.PathValue("currency"), req.AccountID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, float)
+}
+
+// Fund handles POST /admin/float-accounts/{currency}/fund
+func (h *FundingHandler) Fund(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, models.FundingDirectionFund)
+}
+
+// Defund handles POST /admin/float-accounts/{currency}/defund
+func (h *FundingHandler) Defund(w http.ResponseWriter, r *http.Request) {
+	h.record(w, r, models.FundingDirectionDefund)
+}
+
+// record decodes a funding request and records it in direction
+func (h *FundingHandler) record(w http.ResponseWriter, r *http.Request, direction models.FundingDirection) {
+	var req dto.FundingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	funding, err := h.transactionService.RecordFunding(r.Context(), &models.Funding{
+		Currency:  r.PathValue("currency"),
+		Direction: direction,
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, funding)
+}
+
+// ListFundings handles GET /admin/float-accounts/{currency}/fundings?limit=
+func (h *FundingHandler) ListFundings(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	currency := r.PathValue("currency")
+	q.scope("currency", currency)
+
+	fundings, err := h.transactionService.ListFundings(r.Context(), currency, q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, fundings, "", nil)
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// GraphQLHandler answers GraphQL queries over accounts and their transaction history, so a caller
+// can select just the fields it needs and follow an account to its transactions, and a
+// transaction to its accounts, in one request. Objects carry the fields and names of their v1
+// representations; ids are GraphQL IDs. The schema has no mutations.
+type GraphQLHandler struct {
+	schema graphql.Schema
+}
+
+// GraphQLRequest is the body of a GraphQL request
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// NewGraphQLHandler creates a new GraphQL handler, building its schema
+func NewGraphQLHandler(accountService service.AccountService, transactionService service.TransactionService) (*GraphQLHandler, error) {
+	schema, err := newGraphQLSchema(accountService, transactionService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graphql schema: %w", err)
+	}
+	return &GraphQLHandler{schema: schema}, nil
+}
+
+// RegisterRoutes registers the GraphQL endpoint on mux
+func (h *GraphQLHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /graphql", h.Query)
+	mux.HandleFunc("POST /graphql", h.Query)
+}
+
+// Query handles GET /graphql?query=&operationName=&variables= and POST /graphql. Errors resolving
+// the query are reported in the errors of a 200 response, as GraphQL has it; only a request that
+// can't be read is answered with an error status.
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				response.Error(w, fmt.Errorf("%w: invalid variables", errors.ErrValidationFailed))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	if req.Query == "" {
+		response.Error(w, fmt.Errorf("%w: query is required", errors.ErrValidationFailed))
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        r.Context(),
+	})
+	response.JSON(w, http.StatusOK, result)
+}
+
+// newGraphQLSchema builds the schema: account(account_id) and transaction(id) at the root, an
+// account's transactions paged as on GET /accounts/{account_id}/transactions, and a
+// transaction's source and destination accounts
+func newGraphQLSchema(accountService service.AccountService, transactionService service.TransactionService) (graphql.Schema, error) {
+	account := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Account",
+		Fields: graphql.Fields{
+			"account_id":            &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"balance":               &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"reserved_balance":      &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"overdraft_limit":       &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"per_transaction_limit": &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"daily_limit":           &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"monthly_limit":         &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"currency":              &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"status":                &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"owner_ref":             &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"tenant_id":             &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"account_type":          &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"last_activity_at":      &graphql.Field{Type: graphql.String, Resolve: optionalField},
+		},
+	})
+	resolveAccount := func(id func(p graphql.ResolveParams) (int64, error)) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			accountID, err := id(p)
+			if err != nil {
+				return nil, err
+			}
+			found, err := accountService.GetAccount(p.Context, accountID)
+			if err != nil {
+				return nil, err
+			}
+			return v1.FromAccount(found), nil
+		}
+	}
+
+	fee := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Fee",
+		Fields: graphql.Fields{
+			"rule_id":        &graphql.Field{Type: graphql.ID, Resolve: optionalField},
+			"amount":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"transaction_id": &graphql.Field{Type: graphql.ID, Resolve: optionalField},
+		},
+	})
+	transaction := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.Fields{
+			"id":                     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"source_account_id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"destination_account_id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"source_account": &graphql.Field{
+				Type: account,
+				Resolve: resolveAccount(func(p graphql.ResolveParams) (int64, error) {
+					return p.Source.(v1.Transaction).SourceAccountID, nil
+				}),
+			},
+			"destination_account": &graphql.Field{
+				Type: account,
+				Resolve: resolveAccount(func(p graphql.ResolveParams) (int64, error) {
+					return p.Source.(v1.Transaction).DestinationAccountID, nil
+				}),
+			},
+			"amount":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"created_at":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value_date":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"business_date":    &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"tags":             &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"reversal_of":      &graphql.Field{Type: graphql.ID, Resolve: optionalField},
+			"converted_amount": &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"fx_rate":          &graphql.Field{Type: graphql.String, Resolve: optionalField},
+			"fee_of":           &graphql.Field{Type: graphql.ID, Resolve: optionalField},
+			"split_id":         &graphql.Field{Type: graphql.ID, Resolve: optionalField},
+			"fees":             &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(fee))},
+		},
+	})
+	transactionPage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TransactionPage",
+		Fields: graphql.Fields{
+			"transactions": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transaction)))},
+			"next_cursor":  &graphql.Field{Type: graphql.String, Resolve: optionalField},
+		},
+	})
+
+	account.AddFieldConfig("transactions", &graphql.Field{
+		Type: graphql.NewNonNull(transactionPage),
+		Args: graphql.FieldConfigArgument{
+			"status":     &graphql.ArgumentConfig{Type: graphql.String},
+			"direction":  &graphql.ArgumentConfig{Type: graphql.String},
+			"from":       &graphql.ArgumentConfig{Type: graphql.String},
+			"to":         &graphql.ArgumentConfig{Type: graphql.String},
+			"min_amount": &graphql.ArgumentConfig{Type: graphql.String},
+			"max_amount": &graphql.ArgumentConfig{Type: graphql.String},
+			"limit":      &graphql.ArgumentConfig{Type: graphql.Int},
+			"cursor":     &graphql.ArgumentConfig{Type: graphql.String},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			arg := func(name string) string {
+				v, _ := p.Args[name].(string)
+				return v
+			}
+			filter := models.TransactionFilter{
+				Status:    models.TransactionStatus(arg("status")),
+				Direction: models.TransactionDirection(arg("direction")),
+			}
+			var err error
+			if filter.From, err = queryTime(arg("from")); err != nil {
+				return nil, err
+			}
+			if filter.To, err = queryTime(arg("to")); err != nil {
+				return nil, err
+			}
+			if filter.MinAmount, err = queryAmount(arg("min_amount"), "min_amount"); err != nil {
+				return nil, err
+			}
+			if filter.MaxAmount, err = queryAmount(arg("max_amount"), "max_amount"); err != nil {
+				return nil, err
+			}
+			limit, _ := p.Args["limit"].(int)
+			if _, set := p.Args["limit"]; set && limit <= 0 {
+				return nil, fmt.Errorf("%w: invalid limit", errors.ErrValidationFailed)
+			}
+
+			page, err := transactionService.ListAccountTransactions(p.Context, p.Source.(v1.Account).AccountID, filter, limit, arg("cursor"))
+			if err != nil {
+				return nil, err
+			}
+			return struct {
+				Transactions []v1.Transaction `json:"transactions"`
+				NextCursor   string           `json:"next_cursor"`
+			}{v1.FromTransactions(page.Transactions), page.NextCursor}, nil
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"account": &graphql.Field{
+				Type: account,
+				Args: graphql.FieldConfigArgument{
+					"account_id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: resolveAccount(func(p graphql.ResolveParams) (int64, error) {
+					return graphQLID(p, "account_id")
+				}),
+			},
+			"transaction": &graphql.Field{
+				Type: transaction,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					transactionID, err := graphQLID(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					found, err := transactionService.GetTransaction(p.Context, transactionID)
+					if err != nil {
+						return nil, err
+					}
+					return v1.FromTransaction(found), nil
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// graphQLID reads the ID argument name of p, which must be a positive integer
+func graphQLID(p graphql.ResolveParams, name string) (int64, error) {
+	v, _ := p.Args[name].(string)
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid %s", errors.ErrValidationFailed, name)
+	}
+	return id, nil
+}
+
+// optionalField resolves a field the v1 representation omits when empty to null rather than to
+// its zero value
+func optionalField(p graphql.ResolveParams) (interface{}, error) {
+	v, err := graphql.DefaultResolveFn(p)
+	switch v {
+	case "", int64(0):
+		return nil, err
+	}
+	return v, err
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// LedgerHandler exposes the chart of accounts, posting rules and entries of the ledger
+type LedgerHandler struct {
+	ledgerService service.LedgerService
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(ledgerService service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// RegisterRoutes registers the ledger endpoints on mux
+func (h *LedgerHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /ledger/accounts", h.CreateAccount)
+	mux.HandleFunc("GET /ledger/accounts", h.ListAccounts)
+	mux.HandleFunc("GET /ledger/accounts/{code}", h.GetAccount)
+	mux.HandleFunc("PUT /ledger/accounts/{code}", h.UpdateAccount)
+	mux.HandleFunc("DELETE /ledger/accounts/{code}", h.DeactivateAccount)
+	mux.HandleFunc("GET /ledger/posting-rules", h.ListPostingRules)
+	mux.HandleFunc("GET /ledger/posting-rules/{transfer_type}", h.GetPostingRules)
+	mux.HandleFunc("PUT /ledger/posting-rules/{transfer_type}", h.SetPostingRules)
+	mux.HandleFunc("GET /ledger/transactions/{id}/entries", h.GetTransactionEntries)
+	mux.HandleFunc("GET /ledger/balances/{account_id}", h.GetBalance)
+}
+
+// CreateAccount handles POST /ledger/accounts
+func (h *LedgerHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateLedgerAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	account, err := h.ledgerService.CreateLedgerAccount(r.Context(), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromLedgerAccount(account))
+}
+
+// ListAccounts handles GET /ledger/accounts?type=&include_inactive=
+func (h *LedgerHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "type", "include_inactive")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	filter := models.LedgerAccountFilter{Type: models.LedgerAccountType(q.filter("type"))}
+	if v := q.filter("include_inactive"); v != "" {
+		includeInactive, err := strconv.ParseBool(v)
+		if err != nil {
+			response.Error(w, fmt.Errorf("%w: invalid include_inactive", errors.ErrValidationFailed))
+			return
+		}
+		filter.IncludeInactive = includeInactive
+	}
+
+	accounts, err := h.ledgerService.ListLedgerAccounts(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, v1.FromLedgerAccounts(accounts))
+}
+
+// GetAccount handles GET /ledger/accounts/{code}
+func (h *LedgerHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	account, err := h.ledgerService.GetLedgerAccount(r.Context(), r.PathValue("code"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromLedgerAccount(account))
+}
+
+// UpdateAccount handles PUT /ledger/accounts/{code}
+func (h *LedgerHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateLedgerAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	account, err := h.ledgerService.UpdateLedgerAccount(r.Context(), r.PathValue("code"), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromLedgerAccount(account))
+}
+
+// DeactivateAccount handles DELETE /ledger/accounts/{code}. Ledger accounts are never removed,
+// only closed to further postings.
+func (h *LedgerHandler) DeactivateAccount(w http.ResponseWriter, r *http.Request) {
+	if err := h.ledgerService.DeactivateLedgerAccount(r.Context(), r.PathValue("code")); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPostingRules handles GET /ledger/posting-rules
+func (h *LedgerHandler) ListPostingRules(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	rules, err := h.ledgerService.ListPostingRules(r.Context(), "")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, rules)
+}
+
+// GetPostingRules handles GET /ledger/posting-rules/{transfer_type}
+func (h *LedgerHandler) GetPostingRules(w http.ResponseWriter, r *http.Request) {
+	transferType := models.TransferType(r.PathValue("transfer_type"))
+	rules, err := h.ledgerService.ListPostingRules(r.Context(), transferType)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if len(rules) == 0 {
+		response.Error(w, fmt.Errorf("%w: transfer type %s", errors.ErrPostingRulesNotFound, transferType))
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.PostingRulesResponse{Rules: rules})
+}
+
+// SetPostingRules handles PUT /ledger/posting-rules/{transfer_type}
+func (h *LedgerHandler) SetPostingRules(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetPostingRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	rules := make([]*models.PostingRule, 0, len(req.Legs))
+	for i, leg := range req.Legs {
+		rules = append(rules, &models.PostingRule{Leg: i + 1, Debit: leg.Debit, Credit: leg.Credit, Description: leg.Description})
+	}
+
+	rules, err := h.ledgerService.SetPostingRules(r.Context(), models.TransferType(r.PathValue("transfer_type")), rules)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.PostingRulesResponse{Rules: rules})
+}
+
+// GetTransactionEntries handles GET /ledger/transactions/{id}/entries
+func (h *LedgerHandler) GetTransactionEntries(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	entries, err := h.ledgerService.GetTransactionEntries(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.LedgerEntriesResponse{Entries: v1.FromLedgerEntries(entries)})
+}
+
+// GetBalance handles GET /ledger/balances/{account_id}
+func (h *LedgerHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	balance, err := h.ledgerService.GetLedgerBalance(r.Context(), accountID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromLedgerBalance(balance))
+}
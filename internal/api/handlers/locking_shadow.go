@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// LockingShadowHandler exposes how the row-locking transfer path compared with the current one
+type LockingShadowHandler struct {
+	metrics *metrics.LockingShadow
+}
+
+// NewLockingShadowHandler creates a new locking shadow handler
+func NewLockingShadowHandler(m *metrics.LockingShadow) *LockingShadowHandler {
+	return &LockingShadowHandler{metrics: m}
+}
+
+// RegisterRoutes registers the locking shadow endpoints on mux
+func (h *LockingShadowHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/metrics/locking-shadow", h.GetStats)
+}
+
+// GetStats handles GET /admin/metrics/locking-shadow
+func (h *LockingShadowHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.metrics.Stats())
+}
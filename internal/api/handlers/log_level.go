@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// LogLevelHandler lets operators change the level of the application log at runtime
+type LogLevelHandler struct {
+	log *slog.Logger
+}
+
+// NewLogLevelHandler creates a new log level handler
+func NewLogLevelHandler() *LogLevelHandler {
+	return &LogLevelHandler{log: logger.Default()}
+}
+
+// RegisterRoutes registers the log level endpoints on mux
+func (h *LogLevelHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/loglevel", h.GetLevel)
+	mux.HandleFunc("PUT /admin/loglevel", h.SetLevel)
+}
+
+// GetLevel handles GET /admin/loglevel
+func (h *LogLevelHandler) GetLevel(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, levelResponse())
+}
+
+// SetLevel handles PUT /admin/loglevel
+func (h *LogLevelHandler) SetLevel(w http.ResponseWriter, r *http.Request) {
+	var req dto.LogLevel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		response.Error(w, fmt.Errorf("%w: %v", errors.ErrValidationFailed, err))
+		return
+	}
+
+	previous := logger.SetLevel(level)
+	h.log.WarnContext(r.Context(), "Log level changed", "previous", previous, "level", level, "actor", actor.FromContext(r.Context()))
+	response.JSON(w, http.StatusOK, levelResponse())
+}
+
+// levelResponse is the current level of the application log
+func levelResponse() dto.LogLevel {
+	return dto.LogLevel{Level: strings.ToLower(logger.CurrentLevel().String())}
+}
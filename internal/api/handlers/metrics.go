@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// MetricsHandler exposes transfer outcome and tenant quota metrics
+type MetricsHandler struct {
+	transfers *metrics.Transfers
+	quotas    *metrics.Quotas
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(transfers *metrics.Transfers, quotas *metrics.Quotas) *MetricsHandler {
+	return &MetricsHandler{transfers: transfers, quotas: quotas}
+}
+
+// RegisterRoutes registers the metrics endpoints on mux
+func (h *MetricsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/metrics/transfers", h.GetTransferStats)
+	mux.HandleFunc("GET /admin/metrics/quotas", h.GetQuotaStats)
+}
+
+// GetTransferStats handles GET /admin/metrics/transfers
+func (h *MetricsHandler) GetTransferStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.transfers.Stats())
+}
+
+// GetQuotaStats handles GET /admin/metrics/quotas
+func (h *MetricsHandler) GetQuotaStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.quotas.Stats())
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// MinimumBalanceHandler exposes account types and operator transfers that may override the
+// minimum balance requirement
+type MinimumBalanceHandler struct {
+	accountService     service.AccountService
+	transactionService service.TransactionService
+}
+
+// NewMinimumBalanceHandler creates a new minimum balance handler
+func NewMinimumBalanceHandler(accountService service.AccountService, transactionService service.TransactionService) *MinimumBalanceHandler {
+	return &MinimumBalanceHandler{accountService: accountService, transactionService: transactionService}
+}
+
+// RegisterRoutes registers the minimum balance endpoints on mux
+func (h *MinimumBalanceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /accounts/{account_id}/type", h.SetType)
+	mux.HandleFunc("POST /admin/transfers", h.CreateTransfer)
+}
+
+// SetType handles PUT /accounts/{account_id}/type
+func (h *MinimumBalanceHandler) SetType(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.SetAccountTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	if err := h.accountService.SetAccountType(r.Context(), accountID, models.AccountType(req.AccountType)); err != nil {
+		response.Error(w, err)
+		return
+	}
+	account, err := h.accountService.GetAccount(r.Context(), accountID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
+
+// CreateTransfer handles POST /admin/transfers
+func (h *MinimumBalanceHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	var req dto.AdminTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil {
+		response.Error(w, fmt.Errorf("%w: amount %q is not a decimal number", errors.ErrValidationFailed, req.Amount))
+		return
+	}
+
+	var override *models.MinimumBalanceOverride
+	if req.OverrideMinimumBalance {
+		override = &models.MinimumBalanceOverride{Actor: req.Actor, Reason: req.Reason}
+	}
+	transaction, err := h.transactionService.CreateAdminTransaction(r.Context(), &dto.CreateTransactionRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               amount,
+	}, override)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromTransaction(transaction))
+}
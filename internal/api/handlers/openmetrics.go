@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// OpenMetricsHandler serves the metrics of its collectors in the OpenMetrics text format for
+// scrapers
+type OpenMetricsHandler struct {
+	collectors []metrics.Collector
+	log        *slog.Logger
+}
+
+// NewOpenMetricsHandler creates a handler exposing the collectors, in order
+func NewOpenMetricsHandler(collectors ...metrics.Collector) *OpenMetricsHandler {
+	return &OpenMetricsHandler{collectors: collectors, log: logger.Default()}
+}
+
+// RegisterRoutes registers the metrics endpoint on mux
+func (h *OpenMetricsHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /metrics", h.GetMetrics)
+}
+
+// GetMetrics handles GET /metrics. The exposition is buffered, so a collector failing, e.g. on a
+// database query, fails the scrape with an error instead of serving it truncated.
+func (h *OpenMetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	var body bytes.Buffer
+	out := metrics.NewOpenMetricsWriter(&body)
+	for _, collector := range h.collectors {
+		if err := collector.Collect(r.Context(), out); err != nil {
+			response.Error(w, err)
+			return
+		}
+	}
+	if err := out.Close(); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.OpenMetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		h.log.WarnContext(r.Context(), "Writing metrics failed", "err", err)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/outbox"
+)
+
+// OutboxHandler exposes the outbox relay metrics
+type OutboxHandler struct {
+	relay *outbox.Relay
+}
+
+// NewOutboxHandler creates a new outbox handler
+func NewOutboxHandler(relay *outbox.Relay) *OutboxHandler {
+	return &OutboxHandler{relay: relay}
+}
+
+// RegisterRoutes registers the outbox endpoints on mux
+func (h *OutboxHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/outbox", h.GetStats)
+}
+
+// GetStats handles GET /admin/outbox
+func (h *OutboxHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.relay.Stats(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// OverdraftHandler exposes the overdraft limits of accounts
+type OverdraftHandler struct {
+	accountService service.AccountService
+}
+
+// NewOverdraftHandler creates a new overdraft handler
+func NewOverdraftHandler(accountService service.AccountService) *OverdraftHandler {
+	return &OverdraftHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the overdraft endpoints on mux
+func (h *OverdraftHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("PUT /admin/accounts/{account_id}/overdraft-limit", h.SetOverdraftLimit)
+}
+
+// SetOverdraftLimit handles PUT /admin/accounts/{account_id}/overdraft-limit
+func (h *OverdraftHandler) SetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.SetOverdraftLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	account, err := h.accountService.SetOverdraftLimit(r.Context(), accountID, req.OverdraftLimit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromAccount(account))
+}
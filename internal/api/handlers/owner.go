@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// OwnerHandler exposes account ownership endpoints
+type OwnerHandler struct {
+	accountService service.AccountService
+}
+
+// NewOwnerHandler creates a new owner handler
+func NewOwnerHandler(accountService service.AccountService) *OwnerHandler {
+	return &OwnerHandler{accountService: accountService}
+}
+
+// RegisterRoutes registers the ownership endpoints on mux
+func (h *OwnerHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /owners/{ref}/accounts", h.ListAccounts)
+	mux.HandleFunc("PUT /accounts/{account_id}/owner", h.SetOwner)
+}
+
+// ListAccounts handles GET /owners/{ref}/accounts
+func (h *OwnerHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	ownerRef := r.PathValue("ref")
+	q.scope("owner_ref", ownerRef)
+
+	accounts, err := h.accountService.ListAccountsByOwner(r.Context(), ownerRef)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, v1.FromAccounts(accounts))
+}
+
+// SetOwner handles PUT /accounts/{account_id}/owner
+func (h *OwnerHandler) SetOwner(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var req dto.SetAccountOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	if err := h.accountService.SetAccountOwner(r.Context(), accountID, req.OwnerRef); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
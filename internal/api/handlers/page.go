@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// listQuery is the query of a list endpoint: the limit and cursor every list accepts, and the
+// filters narrowing this one
+type listQuery struct {
+	limit   int
+	cursor  string
+	filters map[string]string
+}
+
+// parseListQuery reads the limit and cursor query parameters of r, and those of the named filters
+// that are set. limit must be a positive integer; zero, when it's absent, leaves the endpoint's
+// default.
+func parseListQuery(r *http.Request, filters ...string) (*listQuery, error) {
+	query := r.URL.Query()
+	q := &listQuery{cursor: query.Get("cursor"), filters: map[string]string{}}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("%w: invalid limit", errors.ErrValidationFailed)
+		}
+		q.limit = limit
+	}
+	for _, name := range filters {
+		if v := query.Get(name); v != "" {
+			q.filters[name] = v
+		}
+	}
+	return q, nil
+}
+
+// filter returns the value of a named filter, empty if it wasn't set
+func (q *listQuery) filter(name string) string {
+	return q.filters[name]
+}
+
+// scope records a path parameter narrowing the list, so it's echoed with the query's filters
+func (q *listQuery) scope(name, value string) {
+	q.filters[name] = value
+}
+
+// writeList writes items as the single page of a list returned whole
+func writeList[T any](w http.ResponseWriter, q *listQuery, items []T) {
+	total := len(items)
+	writePage(w, q, items, "", &total)
+}
+
+// writePage writes items as a page of the list q asked for. next is the cursor of the following
+// page, empty on the last, and total the size of the whole list, nil if it isn't known.
+func writePage[T any](w http.ResponseWriter, q *listQuery, items []T, next string, total *int) {
+	if items == nil {
+		items = []T{}
+	}
+	response.JSON(w, http.StatusOK, dto.Page[T]{
+		Items:          items,
+		NextCursor:     next,
+		TotalEstimate:  total,
+		AppliedFilters: q.filters,
+	})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// pathID parses a positive integer path segment
+func pathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid %s", errors.ErrValidationFailed, name)
+	}
+	return id, nil
+}
+
+// queryTime parses an optional RFC 3339 query parameter; empty yields the zero time
+func queryTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid time %q, expected RFC 3339", errors.ErrValidationFailed, v)
+	}
+	return t, nil
+}
+
+// queryAmount parses an optional decimal query parameter; empty yields nil
+func queryAmount(v, name string) (*decimal.Decimal, error) {
+	if v == "" {
+		return nil, nil
+	}
+	amount, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s %q", errors.ErrValidationFailed, name, v)
+	}
+	return &amount, nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// PreAuthorizationHandler exposes two-step transfers: reserve funds, then execute the reservation
+type PreAuthorizationHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewPreAuthorizationHandler creates a new pre-authorization handler
+func NewPreAuthorizationHandler(transactionService service.TransactionService) *PreAuthorizationHandler {
+	return &PreAuthorizationHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the pre-authorization endpoints on mux
+func (h *PreAuthorizationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /preauthorizations", h.Create)
+	mux.HandleFunc("GET /preauthorizations/{id}", h.Get)
+	mux.HandleFunc("POST /preauthorizations/{id}/execute", h.Execute)
+}
+
+// Create handles POST /preauthorizations
+func (h *PreAuthorizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req v1.TransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	transaction, err := req.ToTransaction()
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	preAuth, err := h.transactionService.PreAuthorizeTransfer(r.Context(), &dto.CreateTransactionRequest{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromPreAuthorization(preAuth))
+}
+
+// Get handles GET /preauthorizations/{id}
+func (h *PreAuthorizationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	preAuthID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	preAuth, err := h.transactionService.GetPreAuthorization(r.Context(), preAuthID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromPreAuthorization(preAuth))
+}
+
+// Execute handles POST /preauthorizations/{id}/execute
+func (h *PreAuthorizationHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	preAuthID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transaction, err := h.transactionService.ExecutePreAuthorization(r.Context(), preAuthID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromTransaction(transaction))
+}
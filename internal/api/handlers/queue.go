@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// QueueHandler exposes the depth of the queues of background work and the liveness of the
+// workers draining them, as JSON and, as a metrics.Collector, on GET /metrics
+type QueueHandler struct {
+	queues  repository.QueueRepository
+	workers *metrics.Workers
+	clock   clock.Clock
+}
+
+// NewQueueHandler creates a new queue handler measuring queue ages on c
+func NewQueueHandler(queues repository.QueueRepository, workers *metrics.Workers, c clock.Clock) *QueueHandler {
+	return &QueueHandler{queues: queues, workers: workers, clock: c}
+}
+
+// RegisterRoutes registers the queue endpoints on mux
+func (h *QueueHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/queues", h.GetQueues)
+}
+
+// GetQueues handles GET /admin/queues
+func (h *QueueHandler) GetQueues(w http.ResponseWriter, r *http.Request) {
+	depths, err := h.queues.QueueDepths(r.Context(), h.clock.Now())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	health := h.workers.Health()
+	response.JSON(w, http.StatusOK, dto.QueuesResponse{Healthy: health.Healthy, Queues: depths, Workers: health.Workers})
+}
+
+// Collect writes the queue gauges, measured in the database, and the worker liveness metrics
+func (h *QueueHandler) Collect(ctx context.Context, out *metrics.OpenMetricsWriter) error {
+	depths, err := h.queues.QueueDepths(ctx, h.clock.Now())
+	if err != nil {
+		return err
+	}
+	health := h.workers.Health()
+
+	var depth, due, age []metrics.Sample
+	for _, queue := range depths {
+		labels := map[string]string{"queue": queue.Queue}
+		depth = append(depth, metrics.Sample{Labels: labels, Value: float64(queue.Depth)})
+		due = append(due, metrics.Sample{Labels: labels, Value: float64(queue.Due)})
+		age = append(age, metrics.Sample{Labels: labels, Value: queue.OldestDueAgeSeconds})
+	}
+	var up, since, beats []metrics.Sample
+	for _, worker := range health.Workers {
+		labels := map[string]string{"subsystem": worker.Subsystem, "worker": strconv.Itoa(worker.Worker)}
+		alive := 0.0
+		if worker.Alive {
+			alive = 1
+		}
+		up = append(up, metrics.Sample{Labels: labels, Value: alive})
+		since = append(since, metrics.Sample{Labels: labels, Value: worker.SecondsSinceBeat})
+		beats = append(beats, metrics.Sample{Labels: labels, Value: float64(worker.Beats)})
+	}
+
+	out.Gauge("queue_depth", "Items waiting in the queue.", depth...)
+	out.Gauge("queue_due", "Items in the queue that are due.", due...)
+	out.Gauge("queue_oldest_due_age_seconds", "Seconds the oldest due item has been due.", age...)
+	out.Gauge("worker_up", "Whether the worker beat within its interval plus the grace period.", up...)
+	out.Gauge("worker_seconds_since_heartbeat", "Seconds since the last heartbeat of the worker.", since...)
+	out.Counter("worker_heartbeats", "Heartbeats of the worker since it started.", beats...)
+	return nil
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/warmup"
+)
+
+// ReadinessHandler reports whether the instance finished warming up and may take traffic
+type ReadinessHandler struct {
+	state *warmup.State
+}
+
+// NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(state *warmup.State) *ReadinessHandler {
+	return &ReadinessHandler{state: state}
+}
+
+// RegisterRoutes registers the readiness endpoint on mux
+func (h *ReadinessHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /readyz", h.Ready)
+}
+
+// Ready handles GET /readyz: 200 once warm-up completed, 503 before
+func (h *ReadinessHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report, ready := h.state.Ready()
+	if !ready {
+		response.JSON(w, http.StatusServiceUnavailable, dto.ReadinessResponse{Status: "warming_up"})
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.ReadinessResponse{Status: "ready", Warmup: report})
+}
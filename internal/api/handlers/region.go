@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/region"
+)
+
+// RegionHandler exposes the multi-region status and promotion endpoints
+type RegionHandler struct {
+	manager *region.Manager
+	log     *slog.Logger
+}
+
+// NewRegionHandler creates a new region handler
+func NewRegionHandler(manager *region.Manager) *RegionHandler {
+	return &RegionHandler{manager: manager, log: logger.Default()}
+}
+
+// RegisterRoutes registers the region endpoints on mux
+func (h *RegionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/region", h.GetStatus)
+	mux.HandleFunc("POST /admin/region/promote", h.Promote)
+}
+
+// GetStatus handles GET /admin/region
+func (h *RegionHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.manager.Status(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// Promote handles POST /admin/region/promote, run after the database has been failed over
+func (h *RegionHandler) Promote(w http.ResponseWriter, r *http.Request) {
+	h.log.WarnContext(r.Context(), "Region promotion requested", "remote_addr", r.RemoteAddr)
+
+	status, err := h.manager.Promote(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
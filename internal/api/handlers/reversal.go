@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// ReversalHandler exposes reversals of completed transfers
+type ReversalHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewReversalHandler creates a new reversal handler
+func NewReversalHandler(transactionService service.TransactionService) *ReversalHandler {
+	return &ReversalHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the reversal endpoints on mux
+func (h *ReversalHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /transactions/{id}/reverse", h.Reverse)
+}
+
+// Reverse handles POST /transactions/{id}/reverse and responds with the compensating transaction
+func (h *ReversalHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	reversal, err := h.transactionService.ReverseTransaction(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromTransaction(reversal))
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// ScheduledTransferHandler exposes transfers requested now and made at a later time
+type ScheduledTransferHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewScheduledTransferHandler creates a new scheduled transfer handler
+func NewScheduledTransferHandler(transactionService service.TransactionService) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the scheduled transfer endpoints on mux
+func (h *ScheduledTransferHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /scheduled-transfers", h.Create)
+	mux.HandleFunc("GET /scheduled-transfers", h.List)
+	mux.HandleFunc("GET /scheduled-transfers/{id}", h.Get)
+	mux.HandleFunc("POST /scheduled-transfers/{id}/cancel", h.Cancel)
+}
+
+// Create handles POST /scheduled-transfers
+func (h *ScheduledTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.ScheduleTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	transaction, err := v1.TransactionRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+	}.ToTransaction()
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if req.ExecuteAt == "" {
+		response.Error(w, fmt.Errorf("%w: execute_at is required", errors.ErrValidationFailed))
+		return
+	}
+	executeAt, err := queryTime(req.ExecuteAt)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	scheduled, err := h.transactionService.ScheduleTransfer(r.Context(), &dto.CreateTransactionRequest{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+	}, executeAt)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromScheduledTransfer(scheduled))
+}
+
+// List handles GET /scheduled-transfers?status=&limit=
+func (h *ScheduledTransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "status")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transfers, err := h.transactionService.ListScheduledTransfers(r.Context(), models.ScheduledTransferStatus(q.filter("status")), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromScheduledTransfers(transfers), "", nil)
+}
+
+// Get handles GET /scheduled-transfers/{id}
+func (h *ScheduledTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+	transferID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transfer, err := h.transactionService.GetScheduledTransfer(r.Context(), transferID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromScheduledTransfer(transfer))
+}
+
+// Cancel handles POST /scheduled-transfers/{id}/cancel
+func (h *ScheduledTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	transferID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transfer, err := h.transactionService.CancelScheduledTransfer(r.Context(), transferID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromScheduledTransfer(transfer))
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// SLOHandler exposes the compliance and burn rates of the endpoint objectives
+type SLOHandler struct {
+	tracker *metrics.SLOTracker
+}
+
+// NewSLOHandler creates a new SLO handler
+func NewSLOHandler(tracker *metrics.SLOTracker) *SLOHandler {
+	return &SLOHandler{tracker: tracker}
+}
+
+// RegisterRoutes registers the SLO endpoints on mux
+func (h *SLOHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/metrics/slo", h.GetSLOStatus)
+}
+
+// GetSLOStatus handles GET /admin/metrics/slo
+func (h *SLOHandler) GetSLOStatus(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.tracker.Status())
+}
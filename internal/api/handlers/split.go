@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// SplitHandler exposes one-to-many split transfers
+type SplitHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewSplitHandler creates a new split transfer handler
+func NewSplitHandler(transactionService service.TransactionService) *SplitHandler {
+	return &SplitHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the split transfer endpoints on mux
+func (h *SplitHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /transfers/split", h.CreateSplit)
+	mux.HandleFunc("GET /transfers/split/{id}", h.GetSplit)
+}
+
+// CreateSplit handles POST /transfers/split
+func (h *SplitHandler) CreateSplit(w http.ResponseWriter, r *http.Request) {
+	var req dto.SplitTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	legs := make([]models.SplitLeg, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		amount, err := decimal.NewFromString(leg.Amount)
+		if err != nil {
+			response.Error(w, fmt.Errorf("%w: leg to %d: amount %q is not a decimal number", errors.ErrValidationFailed, leg.DestinationAccountID, leg.Amount))
+			return
+		}
+		legs = append(legs, models.SplitLeg{DestinationAccountID: leg.DestinationAccountID, Amount: amount})
+	}
+
+	split, err := h.transactionService.CreateSplitTransfer(r.Context(), req.SourceAccountID, legs)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromSplitTransfer(split))
+}
+
+// GetSplit handles GET /transfers/split/{id}
+func (h *SplitHandler) GetSplit(w http.ResponseWriter, r *http.Request) {
+	splitID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	split, err := h.transactionService.GetSplitTransfer(r.Context(), splitID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromSplitTransfer(split))
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// StandingOrderHandler exposes transfers repeated on a daily, weekly or monthly schedule
+type StandingOrderHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewStandingOrderHandler creates a new standing order handler
+func NewStandingOrderHandler(transactionService service.TransactionService) *StandingOrderHandler {
+	return &StandingOrderHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the standing order endpoints on mux
+func (h *StandingOrderHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /standing-orders", h.Create)
+	mux.HandleFunc("GET /standing-orders/{id}", h.Get)
+	mux.HandleFunc("GET /standing-orders/{id}/occurrences", h.Occurrences)
+	mux.HandleFunc("POST /standing-orders/{id}/pause", h.Pause)
+	mux.HandleFunc("POST /standing-orders/{id}/resume", h.Resume)
+	mux.HandleFunc("POST /standing-orders/{id}/cancel", h.Cancel)
+}
+
+// Create handles POST /standing-orders
+func (h *StandingOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateStandingOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	transaction, err := v1.TransactionRequest{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+	}.ToTransaction()
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if req.StartAt == "" {
+		response.Error(w, fmt.Errorf("%w: start_at is required", errors.ErrValidationFailed))
+		return
+	}
+	recurrence := models.Recurrence{
+		Frequency:      models.StandingOrderFrequency(req.Frequency),
+		MaxOccurrences: req.MaxOccurrences,
+	}
+	if recurrence.StartAt, err = queryTime(req.StartAt); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if req.EndAt != "" {
+		if recurrence.EndAt, err = queryTime(req.EndAt); err != nil {
+			response.Error(w, err)
+			return
+		}
+	}
+
+	order, err := h.transactionService.CreateStandingOrder(r.Context(), &dto.CreateTransactionRequest{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+	}, recurrence)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, v1.FromStandingOrder(order))
+}
+
+// Get handles GET /standing-orders/{id}
+func (h *StandingOrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orderID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	order, err := h.transactionService.GetStandingOrder(r.Context(), orderID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromStandingOrder(order))
+}
+
+// Occurrences handles GET /standing-orders/{id}/occurrences?limit=
+func (h *StandingOrderHandler) Occurrences(w http.ResponseWriter, r *http.Request) {
+	orderID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q.scope("standing_order_id", strconv.FormatInt(orderID, 10))
+
+	transfers, err := h.transactionService.ListStandingOrderOccurrences(r.Context(), orderID, q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromScheduledTransfers(transfers), "", nil)
+}
+
+// Pause handles POST /standing-orders/{id}/pause
+func (h *StandingOrderHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.transactionService.PauseStandingOrder)
+}
+
+// Resume handles POST /standing-orders/{id}/resume
+func (h *StandingOrderHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.transactionService.ResumeStandingOrder)
+}
+
+// Cancel handles POST /standing-orders/{id}/cancel
+func (h *StandingOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.transactionService.CancelStandingOrder)
+}
+
+// change applies a status change to the standing order of the path and writes the result
+func (h *StandingOrderHandler) change(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, orderID int64) (*models.StandingOrder, error)) {
+	orderID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	order, err := apply(r.Context(), orderID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromStandingOrder(order))
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// SuspenseHandler exposes the operator workflow for credits held on the suspense account
+type SuspenseHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewSuspenseHandler creates a new suspense handler
+func NewSuspenseHandler(transactionService service.TransactionService) *SuspenseHandler {
+	return &SuspenseHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the suspense endpoints on mux
+func (h *SuspenseHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /suspense", h.List)
+	mux.HandleFunc("GET /suspense/{id}", h.Get)
+	mux.HandleFunc("POST /suspense/{id}/reapply", h.Reapply)
+	mux.HandleFunc("POST /suspense/{id}/return", h.Return)
+}
+
+// List handles GET /suspense?status=&limit=
+func (h *SuspenseHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "status")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	items, err := h.transactionService.ListSuspenseItems(r.Context(), models.SuspenseStatus(q.filter("status")), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, items, "", nil)
+}
+
+// Get handles GET /suspense/{id}
+func (h *SuspenseHandler) Get(w http.ResponseWriter, r *http.Request) {
+	itemID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	item, err := h.transactionService.GetSuspenseItem(r.Context(), itemID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, item)
+}
+
+// Reapply handles POST /suspense/{id}/reapply
+func (h *SuspenseHandler) Reapply(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.transactionService.ReapplySuspenseItem)
+}
+
+// Return handles POST /suspense/{id}/return
+func (h *SuspenseHandler) Return(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.transactionService.ReturnSuspenseItem)
+}
+
+// resolve decodes the operator request and resolves the item with resolveFn
+func (h *SuspenseHandler) resolve(w http.ResponseWriter, r *http.Request, resolveFn func(ctx context.Context, itemID int64, actor, note string) (*models.SuspenseItem, error)) {
+	itemID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.ResolveSuspenseItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	item, err := resolveFn(r.Context(), itemID, req.Actor, req.Note)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, item)
+}
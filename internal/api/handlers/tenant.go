@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// TenantHandler exposes the settings of tenants and the assignment of accounts to tenants
+type TenantHandler struct {
+	tenantService  service.TenantService
+	accountService service.AccountService
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(tenantService service.TenantService, accountService service.AccountService) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService, accountService: accountService}
+}
+
+// RegisterRoutes registers the tenant endpoints on mux
+func (h *TenantHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /tenants", h.List)
+	mux.HandleFunc("GET /tenants/{tenant_id}", h.Get)
+	mux.HandleFunc("PUT /tenants/{tenant_id}", h.Put)
+	mux.HandleFunc("DELETE /tenants/{tenant_id}", h.Delete)
+	mux.HandleFunc("PUT /accounts/{account_id}/tenant", h.SetAccountTenant)
+}
+
+// List handles GET /tenants
+func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	tenants, err := h.tenantService.ListTenantSettings(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, tenants)
+}
+
+// Get handles GET /tenants/{tenant_id}
+func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.tenantService.GetTenantSettings(r.Context(), r.PathValue("tenant_id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, settings)
+}
+
+// Put handles PUT /tenants/{tenant_id}
+func (h *TenantHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var req dto.PutTenantSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	settings, err := h.tenantService.PutTenantSettings(r.Context(), r.PathValue("tenant_id"), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, settings)
+}
+
+// Delete handles DELETE /tenants/{tenant_id}
+func (h *TenantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.tenantService.DeleteTenantSettings(r.Context(), r.PathValue("tenant_id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetAccountTenant handles PUT /accounts/{account_id}/tenant
+func (h *TenantHandler) SetAccountTenant(w http.ResponseWriter, r *http.Request) {
+	accountID, err := pathID(r, "account_id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var req dto.SetAccountTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	if err := h.accountService.SetAccountTenant(r.Context(), accountID, req.TenantID); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
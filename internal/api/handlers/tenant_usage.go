@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// TenantUsageHandler exposes how much of their quotas tenants use
+type TenantUsageHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewTenantUsageHandler creates a new tenant usage handler
+func NewTenantUsageHandler(transactionService service.TransactionService) *TenantUsageHandler {
+	return &TenantUsageHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the tenant usage endpoints on mux
+func (h *TenantUsageHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /tenants/{tenant_id}/usage", h.Get)
+}
+
+// Get handles GET /tenants/{tenant_id}/usage
+func (h *TenantUsageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.transactionService.GetTenantUsage(r.Context(), r.PathValue("tenant_id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// TransactionStatusHandler exposes single transactions, so clients can poll a transfer's status
+type TransactionStatusHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewTransactionStatusHandler creates a new transaction status handler
+func NewTransactionStatusHandler(transactionService service.TransactionService) *TransactionStatusHandler {
+	return &TransactionStatusHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the transaction status endpoints on mux
+func (h *TransactionStatusHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /transactions/{id}", h.Get)
+	mux.HandleFunc("GET /transactions/{id}/history", h.GetHistory)
+	mux.HandleFunc("GET /idempotency-keys/{key}", h.GetByIdempotencyKey)
+}
+
+// Get handles GET /transactions/{id}
+func (h *TransactionStatusHandler) Get(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransaction(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromTransaction(transaction))
+}
+
+// GetHistory handles GET /transactions/{id}/history
+func (h *TransactionStatusHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	history, err := h.transactionService.GetTransactionHistory(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dto.TransactionHistoryResponse{
+		TransactionID: transactionID,
+		History:       v1.FromTransactionHistory(history),
+	})
+}
+
+// GetByIdempotencyKey handles GET /idempotency-keys/{key}. With a batch query parameter the key
+// is the item key of that batch. Only the caller's own keys are found.
+func (h *TransactionStatusHandler) GetByIdempotencyKey(w http.ResponseWriter, r *http.Request) {
+	var transaction *models.Transaction
+	var err error
+	if batchKey := r.URL.Query().Get("batch"); batchKey != "" {
+		transaction, err = h.transactionService.GetBatchItemTransaction(r.Context(), batchKey, r.PathValue("key"))
+	} else {
+		transaction, err = h.transactionService.GetTransactionByIdempotencyKey(r.Context(), r.PathValue("key"))
+	}
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, v1.FromTransaction(transaction))
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// TransactionTagHandler exposes tagging and tag search of transactions
+type TransactionTagHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewTransactionTagHandler creates a new transaction tag handler
+func NewTransactionTagHandler(transactionService service.TransactionService) *TransactionTagHandler {
+	return &TransactionTagHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the tag endpoints on mux
+func (h *TransactionTagHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /transactions", h.Search)
+	mux.HandleFunc("PUT /transactions/{id}/tags", h.SetTags)
+}
+
+// Search handles GET /transactions?tag=&from=&to=&limit=
+func (h *TransactionTagHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "tag", "from", "to")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	tag := q.filter("tag")
+	if tag == "" {
+		response.Error(w, fmt.Errorf("%w: tag is required", errors.ErrValidationFailed))
+		return
+	}
+	from, err := queryTime(q.filter("from"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	to, err := queryTime(q.filter("to"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	transactions, err := h.transactionService.SearchTransactionsByTag(r.Context(), tag, from, to, q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, v1.FromTransactions(transactions), "", nil)
+}
+
+// SetTags handles PUT /transactions/{id}/tags
+func (h *TransactionTagHandler) SetTags(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var req dto.SetTransactionTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	if err := h.transactionService.TagTransaction(r.Context(), transactionID, req.Tags); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
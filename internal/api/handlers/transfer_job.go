@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+)
+
+// TransferJobHandler exposes transfers submitted now and made by background workers
+type TransferJobHandler struct {
+	transactionService service.TransactionService
+}
+
+// NewTransferJobHandler creates a new transfer job handler
+func NewTransferJobHandler(transactionService service.TransactionService) *TransferJobHandler {
+	return &TransferJobHandler{transactionService: transactionService}
+}
+
+// RegisterRoutes registers the transfer job endpoints on mux
+func (h *TransferJobHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /transactions/async", h.Submit)
+	mux.HandleFunc("GET /jobs/{id}", h.Get)
+	mux.HandleFunc("POST /admin/jobs/{id}/cancel", h.Cancel)
+	mux.HandleFunc("POST /admin/jobs/{id}/requeue", h.Requeue)
+	mux.HandleFunc("POST /admin/jobs/{id}/priority", h.SetPriority)
+}
+
+// Submit handles POST /transactions/async. The job is answered with 202 Accepted and polled at
+// the URL in the Location header.
+func (h *TransferJobHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	var req v1.TransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+	if len(req.Tags) > 0 {
+		response.Error(w, fmt.Errorf("%w: tags are not supported on asynchronous transfers", errors.ErrValidationFailed))
+		return
+	}
+	transaction, err := req.ToTransaction()
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	job, err := h.transactionService.SubmitTransferJob(r.Context(), &dto.CreateTransactionRequest{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/jobs/%d", job.ID))
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+// Get handles GET /jobs/{id}
+func (h *TransferJobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	jobID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	job, err := h.transactionService.GetTransferJob(r.Context(), jobID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, job)
+}
+
+// Cancel handles POST /admin/jobs/{id}/cancel
+func (h *TransferJobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.transactionService.CancelTransferJob)
+}
+
+// Requeue handles POST /admin/jobs/{id}/requeue
+func (h *TransferJobHandler) Requeue(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.transactionService.RequeueTransferJob)
+}
+
+// act decodes the operator request and applies actFn to the job
+func (h *TransferJobHandler) act(w http.ResponseWriter, r *http.Request, actFn func(ctx context.Context, jobID int64, reason string) (*models.TransferJob, error)) {
+	jobID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.TransferJobActionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+			return
+		}
+	}
+
+	job, err := actFn(r.Context(), jobID, req.Reason)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, job)
+}
+
+// SetPriority handles POST /admin/jobs/{id}/priority
+func (h *TransferJobHandler) SetPriority(w http.ResponseWriter, r *http.Request) {
+	jobID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.TransferJobPriorityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	job, err := h.transactionService.SetTransferJobPriority(r.Context(), jobID, req.Priority, req.Reason)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/buildinfo"
+)
+
+// VersionHandler reports which build is running
+type VersionHandler struct{}
+
+// NewVersionHandler creates a new version handler
+func NewVersionHandler() *VersionHandler {
+	return &VersionHandler{}
+}
+
+// RegisterRoutes registers the version endpoint on mux
+func (h *VersionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /version", h.GetVersion)
+}
+
+// GetVersion handles GET /version
+func (h *VersionHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/webhook"
+)
+
+// Bounds on the number of delivery attempts listed
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// WebhookHandler exposes webhook subscriptions, test-fire and the delivery log
+type WebhookHandler struct {
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(dispatcher *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{dispatcher: dispatcher}
+}
+
+// RegisterRoutes registers the webhook endpoints on mux
+func (h *WebhookHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /webhooks", h.Create)
+	mux.HandleFunc("GET /webhooks", h.List)
+	mux.HandleFunc("GET /webhooks/{id}", h.Get)
+	mux.HandleFunc("PATCH /webhooks/{id}", h.Update)
+	mux.HandleFunc("DELETE /webhooks/{id}", h.Delete)
+	mux.HandleFunc("POST /webhooks/{id}/test", h.TestFire)
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", h.ListDeliveries)
+	mux.HandleFunc("POST /webhooks/{id}/deliveries/{attempt}/retry", h.Retry)
+}
+
+// Create handles POST /webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	hook, err := h.dispatcher.Register(r.Context(), req.URL, req.Secret, req.EventTypes)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, hook)
+}
+
+// List handles GET /webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	hooks, err := h.dispatcher.List(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writeList(w, q, hooks)
+}
+
+// Get handles GET /webhooks/{id}
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	hook, err := h.dispatcher.Get(r.Context(), webhookID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, hook)
+}
+
+// Update handles PATCH /webhooks/{id}
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, fmt.Errorf("%w: invalid request body", errors.ErrValidationFailed))
+		return
+	}
+
+	hook, err := h.dispatcher.Update(r.Context(), webhookID, webhook.Update{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		Active:     req.Active,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, hook)
+}
+
+// Delete handles DELETE /webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	if err := h.dispatcher.Delete(r.Context(), webhookID); err != nil {
+		response.Error(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TestFire handles POST /webhooks/{id}/test
+func (h *WebhookHandler) TestFire(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	delivery, err := h.dispatcher.TestFire(r.Context(), webhookID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, delivery)
+}
+
+// ListDeliveries handles GET /webhooks/{id}/deliveries?limit=
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q, err := parseListQuery(r)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	q.scope("webhook_id", strconv.FormatInt(webhookID, 10))
+	limit := defaultDeliveriesLimit
+	if q.limit > 0 {
+		limit = min(q.limit, maxDeliveriesLimit)
+	}
+
+	deliveries, err := h.dispatcher.Deliveries(r.Context(), webhookID, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, deliveries, "", nil)
+}
+
+// Retry handles POST /webhooks/{id}/deliveries/{attempt}/retry
+func (h *WebhookHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := pathID(r, "id")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	deliveryID, err := pathID(r, "attempt")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	delivery, err := h.dispatcher.Retry(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, delivery)
+}
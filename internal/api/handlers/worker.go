@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/khamiruf/internal_transfers_system_go/internal/worker"
+)
+
+// WorkerHandler exposes the background worker metrics and the work they gave up on
+type WorkerHandler struct {
+	pool               *worker.Pool
+	transactionService service.TransactionService
+}
+
+// NewWorkerHandler creates a new worker handler
+func NewWorkerHandler(pool *worker.Pool, transactionService service.TransactionService) *WorkerHandler {
+	return &WorkerHandler{pool: pool, transactionService: transactionService}
+}
+
+// RegisterRoutes registers the worker endpoints on mux
+func (h *WorkerHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/workers", h.GetStats)
+	mux.HandleFunc("GET /admin/dead-letters", h.ListDeadLetters)
+}
+
+// GetStats handles GET /admin/workers
+func (h *WorkerHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.pool.Stats())
+}
+
+// ListDeadLetters handles GET /admin/dead-letters?kind=&limit=
+func (h *WorkerHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r, "kind")
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	letters, err := h.transactionService.ListDeadLetters(r.Context(), models.DeadLetterKind(q.filter("kind")), q.limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	writePage(w, q, letters, "", nil)
+}
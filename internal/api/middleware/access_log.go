@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+)
+
+// AccessLogFormat selects how access log lines are written
+type AccessLogFormat string
+
+const (
+	// AccessLogJSON writes one JSON object per request
+	AccessLogJSON AccessLogFormat = "json"
+	// AccessLogCommon writes the NCSA Common Log Format
+	AccessLogCommon AccessLogFormat = "common"
+)
+
+// ParseAccessLogFormat validates an access log format name
+func ParseAccessLogFormat(name string) (AccessLogFormat, error) {
+	switch format := AccessLogFormat(name); format {
+	case AccessLogJSON, AccessLogCommon:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown access log format %q (available: json, common)", name)
+}
+
+// OpenAccessLogOutput resolves an access log target: "stdout", "stderr" or the path of a file
+// that is opened for appending. The returned close function releases the file, if any.
+func OpenAccessLogOutput(target string) (io.Writer, func() error, error) {
+	switch target {
+	case "", "stdout":
+		return os.Stdout, func() error { return nil }, nil
+	case "stderr":
+		return os.Stderr, func() error { return nil }, nil
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open access log %s: %w", target, err)
+	}
+	return f, f.Close, nil
+}
+
+// accessLogEntry is the JSON form of an access log line
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Bytes      int     `json:"bytes"`
+	DurationMS float64 `json:"duration_ms"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	Referer    string  `json:"referer,omitempty"`
+	RequestID  string  `json:"request_id,omitempty"`
+}
+
+// AccessLog writes one line per request to out in format. Unlike Logging, whose lines are
+// application log messages, the access log goes to its own output in a fixed, parseable format
+// for log pipelines. Each line is written with a single Write call.
+func AccessLog(format AccessLogFormat, out io.Writer) Middleware {
+	var mu sync.Mutex
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			var line []byte
+			if format == AccessLogCommon {
+				line = appendCommonLog(nil, r, rec, start)
+			} else {
+				line = appendJSONLog(nil, r, rec, start)
+			}
+			mu.Lock()
+			out.Write(line)
+			mu.Unlock()
+		})
+	}
+}
+
+// appendJSONLog appends the JSON access log line of a request
+func appendJSONLog(dst []byte, r *http.Request, rec *statusRecorder, start time.Time) []byte {
+	encoded, _ := json.Marshal(accessLogEntry{
+		Time:       start.UTC().Format(time.RFC3339Nano),
+		RemoteAddr: remoteHost(r),
+		Method:     r.Method,
+		URI:        r.URL.RequestURI(),
+		Proto:      r.Proto,
+		Status:     rec.status,
+		Bytes:      rec.bytes,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		UserAgent:  r.UserAgent(),
+		Referer:    r.Referer(),
+		RequestID:  rec.Header().Get(requestid.Header),
+	})
+	dst = append(dst, encoded...)
+	return append(dst, '\n')
+}
+
+// appendCommonLog appends the Common Log Format line of a request:
+// host ident authuser [date] "request" status bytes
+func appendCommonLog(dst []byte, r *http.Request, rec *statusRecorder, start time.Time) []byte {
+	user := "-"
+	if name, _, ok := r.BasicAuth(); ok && name != "" {
+		user = name
+	}
+	dst = append(dst, remoteHost(r)...)
+	dst = append(dst, " - "...)
+	dst = append(dst, user...)
+	dst = append(dst, " ["...)
+	dst = start.AppendFormat(dst, "02/Jan/2006:15:04:05 -0700")
+	dst = append(dst, "] \""...)
+	dst = append(dst, r.Method...)
+	dst = append(dst, ' ')
+	dst = append(dst, r.URL.RequestURI()...)
+	dst = append(dst, ' ')
+	dst = append(dst, r.Proto...)
+	dst = append(dst, "\" "...)
+	dst = strconv.AppendInt(dst, int64(rec.status), 10)
+	dst = append(dst, ' ')
+	if rec.bytes == 0 {
+		dst = append(dst, '-')
+	} else {
+		dst = strconv.AppendInt(dst, int64(rec.bytes), 10)
+	}
+	return append(dst, '\n')
+}
+
+// remoteHost returns the client address of a request without its port
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
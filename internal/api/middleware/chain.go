@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+	"github.com/khamiruf/internal_transfers_system_go/internal/tracecontext"
+)
+
+// Chain composes middlewares into one; the first wraps all the others
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// Builder assembles a middleware chain from named middlewares. The application registers every
+// middleware it can offer; configuration then selects which ones run and in what order, so a
+// deployment composes only what it needs and tests can build minimal stacks.
+type Builder struct {
+	registry map[string]Middleware
+}
+
+// NewBuilder creates a builder with the built-in middlewares that need no dependencies
+// (recover, trace_context, request_id, logging, compression, idempotency, actor) already
+// registered
+func NewBuilder() *Builder {
+	b := &Builder{registry: make(map[string]Middleware)}
+	b.Register("recover", Recover)
+	b.Register("trace_context", tracecontext.Middleware)
+	b.Register("request_id", requestid.Middleware)
+	b.Register("logging", Logging)
+	b.Register("compression", Compression)
+	b.Register("idempotency", idempotency.Middleware)
+	b.Register("actor", actor.Middleware)
+	return b
+}
+
+// Register makes a middleware available under name, replacing any previous registration
+func (b *Builder) Register(name string, mw Middleware) *Builder {
+	b.registry[name] = mw
+	return b
+}
+
+// Build returns the chain of the named middlewares, outermost first. Naming a middleware that
+// isn't registered is an error, so a typo in configuration fails at startup instead of silently
+// dropping e.g. authentication.
+func (b *Builder) Build(names []string) (Middleware, error) {
+	mws := make([]Middleware, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		mw, ok := b.registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware %q (available: %s)", name, strings.Join(b.Names(), ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("middleware %q listed more than once", name)
+		}
+		seen[name] = true
+		mws = append(mws, mw)
+	}
+	return Chain(mws...), nil
+}
+
+// Names returns the registered middleware names in alphabetical order
+func (b *Builder) Names() []string {
+	names := make([]string, 0, len(b.registry))
+	for name := range b.registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tag appends name to the X-Order response header, recording the order middlewares ran in
+func tag(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Order", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestBuilder_Build(t *testing.T) {
+	builder := NewBuilder().Register("a", tag("a")).Register("b", tag("b"))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name          string
+		names         []string
+		expectedOrder []string
+		expectedError string
+	}{
+		{name: "configured order", names: []string{"b", "a"}, expectedOrder: []string{"b", "a"}},
+		{name: "only enabled middlewares run", names: []string{"a"}, expectedOrder: []string{"a"}},
+		{name: "empty stack", names: nil, expectedOrder: nil},
+		{name: "unknown middleware", names: []string{"a", "auht"}, expectedError: `unknown middleware "auht"`},
+		{name: "duplicate middleware", names: []string{"a", "a"}, expectedError: "listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw, err := builder.Build(tt.names)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			mw(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.expectedOrder, rec.Header().Values("X-Order"))
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "internal_error")
+}
+
+func TestCompression(t *testing.T) {
+	body := strings.Repeat(`{"balance":"100.00000"}`, 100)
+	handler := Compression(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decoded))
+
+	// Bodiless responses stay uncompressed
+	req = httptest.NewRequest(http.MethodPost, "/empty", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+
+	// Clients that don't accept gzip get the plain body
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, rec.Body.String())
+}
+
+func TestAccessLog(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":1}`)
+	})
+	newRequest := func(path string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = "10.0.0.7:51234"
+		req.Header.Set("User-Agent", "transfers-test")
+		return req
+	}
+
+	var out strings.Builder
+	AccessLog(AccessLogJSON, &out)(handler).ServeHTTP(httptest.NewRecorder(), newRequest("/transactions?dry_run=1"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &entry))
+	assert.True(t, strings.HasSuffix(out.String(), "}\n"))
+	assert.Equal(t, "10.0.0.7", entry["remote_addr"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/transactions?dry_run=1", entry["uri"])
+	assert.Equal(t, float64(http.StatusCreated), entry["status"])
+	assert.Equal(t, float64(8), entry["bytes"])
+	assert.Equal(t, "transfers-test", entry["user_agent"])
+	assert.Contains(t, entry, "duration_ms")
+	assert.NotContains(t, entry, "referer")
+
+	out.Reset()
+	mw := AccessLog(AccessLogCommon, &out)
+	mw(handler).ServeHTTP(httptest.NewRecorder(), newRequest("/transactions"))
+	mw(handler).ServeHTTP(httptest.NewRecorder(), newRequest("/empty"))
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^10\.0\.0\.7 - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "POST /transactions HTTP/1\.1" 201 8$`, lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], `"POST /empty HTTP/1.1" 204 -`), lines[1])
+}
+
+func TestParseAccessLogFormat(t *testing.T) {
+	format, err := ParseAccessLogFormat("common")
+	require.NoError(t, err)
+	assert.Equal(t, AccessLogCommon, format)
+
+	_, err = ParseAccessLogFormat("combined")
+	assert.ErrorContains(t, err, "unknown access log format")
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// HTTPMetrics counts every request by route and status and records its latency in m, exposed on
+// GET /metrics. List it outside compression so latency includes encoding.
+func HTTPMetrics(m *metrics.HTTPRequests) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			m.Record(r, rec.status, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,7 @@
+// Package middleware contains HTTP middleware shared by all endpoints
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// SLO records the status and latency of every request in tracker, which keeps those of the
+// endpoints with an objective. List it outside compression so latency includes encoding.
+func SLO(tracker *metrics.SLOTracker) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			tracker.Record(r, rec.status, time.Since(start))
+		})
+	}
+}
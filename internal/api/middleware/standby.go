@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// StandbyGuard rejects mutating requests with 503 while writable reports false, so a standby
+// region serves reads only. Paths in allow (e.g. the promotion endpoint) are always let through.
+func StandbyGuard(writable func() bool, allow ...string) Middleware {
+	allowed := make(map[string]bool, len(allow))
+	for _, path := range allow {
+		allowed[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if !allowed[r.URL.Path] && !writable() {
+					response.Error(w, errors.ErrReadOnly)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
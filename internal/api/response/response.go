@@ -0,0 +1,170 @@
+// Package response writes JSON responses and the shared error envelope for HTTP endpoints
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+)
+
+// ErrorBody describes an error returned to API clients
+type ErrorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+	// RequestID identifies the request in the server logs, if the request_id middleware ran
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorResponse is the envelope of every error response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// statusByError maps domain errors to HTTP status codes; anything else is a 500
+var statusByError = []struct {
+	err    error
+	status int
+}{
+	{domainErrors.ErrInvalidAmount, http.StatusBadRequest},
+	{domainErrors.ErrAmountPrecision, http.StatusBadRequest},
+	{domainErrors.ErrAmountGranularity, http.StatusBadRequest},
+	{domainErrors.ErrSameAccount, http.StatusBadRequest},
+	{domainErrors.ErrValidationFailed, http.StatusBadRequest},
+	{domainErrors.ErrAccountNotFound, http.StatusNotFound},
+	{domainErrors.ErrSourceAccountNotFound, http.StatusNotFound},
+	{domainErrors.ErrDestinationAccountNotFound, http.StatusNotFound},
+	{domainErrors.ErrTransactionNotFound, http.StatusNotFound},
+	{domainErrors.ErrWebhookNotFound, http.StatusNotFound},
+	{domainErrors.ErrDeliveryNotFound, http.StatusNotFound},
+	{domainErrors.ErrSuspenseItemNotFound, http.StatusNotFound},
+	{domainErrors.ErrPreAuthorizationNotFound, http.StatusNotFound},
+	{domainErrors.ErrLedgerAccountNotFound, http.StatusNotFound},
+	{domainErrors.ErrPostingRulesNotFound, http.StatusNotFound},
+	{domainErrors.ErrTenantNotFound, http.StatusNotFound},
+	{domainErrors.ErrFeeRuleNotFound, http.StatusNotFound},
+	{domainErrors.ErrSplitTransferNotFound, http.StatusNotFound},
+	{domainErrors.ErrScheduledTransferNotFound, http.StatusNotFound},
+	{domainErrors.ErrStandingOrderNotFound, http.StatusNotFound},
+	{domainErrors.ErrArtifactNotFound, http.StatusNotFound},
+	{domainErrors.ErrApprovalNotFound, http.StatusNotFound},
+	{domainErrors.ErrFloatAccountNotFound, http.StatusNotFound},
+	{domainErrors.ErrTransferJobNotFound, http.StatusNotFound},
+	{domainErrors.ErrCredentialNotFound, http.StatusNotFound},
+	{domainErrors.ErrDelegationNotFound, http.StatusNotFound},
+	{domainErrors.ErrAccountAlreadyExists, http.StatusConflict},
+	{domainErrors.ErrDuplicateIdempotencyKey, http.StatusConflict},
+	{domainErrors.ErrDuplicateExternalReference, http.StatusConflict},
+	{domainErrors.ErrIdempotencyConflict, http.StatusConflict},
+	{domainErrors.ErrSuspenseItemResolved, http.StatusConflict},
+	{domainErrors.ErrTransferJobResolved, http.StatusConflict},
+	{domainErrors.ErrPreAuthorizationNotActive, http.StatusConflict},
+	{domainErrors.ErrLedgerAccountExists, http.StatusConflict},
+	{domainErrors.ErrTransactionAlreadyPosted, http.StatusConflict},
+	{domainErrors.ErrTransactionNotReversible, http.StatusConflict},
+	{domainErrors.ErrScheduledTransferResolved, http.StatusConflict},
+	{domainErrors.ErrStandingOrderStatus, http.StatusConflict},
+	{domainErrors.ErrOccurrenceScheduled, http.StatusConflict},
+	{domainErrors.ErrApprovalExists, http.StatusConflict},
+	{domainErrors.ErrApprovalResolved, http.StatusConflict},
+	{domainErrors.ErrSnapshotExists, http.StatusConflict},
+	{domainErrors.ErrDeadLetterExists, http.StatusConflict},
+	{domainErrors.ErrFloatAccountExists, http.StatusConflict},
+	{domainErrors.ErrSelfApproval, http.StatusForbidden},
+	{domainErrors.ErrUnauthenticated, http.StatusUnauthorized},
+	{domainErrors.ErrInsufficientScope, http.StatusForbidden},
+	{domainErrors.ErrDelegationDenied, http.StatusForbidden},
+	{domainErrors.ErrCredentialExists, http.StatusConflict},
+	{domainErrors.ErrInsufficientBalance, http.StatusUnprocessableEntity},
+	{domainErrors.ErrAccountNotActive, http.StatusUnprocessableEntity},
+	{domainErrors.ErrAccountDormant, http.StatusUnprocessableEntity},
+	{domainErrors.ErrAccountFrozen, http.StatusUnprocessableEntity},
+	{domainErrors.ErrAccountStatusConflict, http.StatusConflict},
+	{domainErrors.ErrMinimumBalance, http.StatusUnprocessableEntity},
+	{domainErrors.ErrSystemFloatAccount, http.StatusUnprocessableEntity},
+	{domainErrors.ErrLedgerAccountInactive, http.StatusUnprocessableEntity},
+	{domainErrors.ErrPostingMismatch, http.StatusUnprocessableEntity},
+	{domainErrors.ErrTransferLimitExceeded, http.StatusUnprocessableEntity},
+	{domainErrors.ErrLimitExceeded, http.StatusUnprocessableEntity},
+	{domainErrors.ErrVelocityExceeded, http.StatusTooManyRequests},
+	{domainErrors.ErrTenantQuotaExceeded, http.StatusUnprocessableEntity},
+	{domainErrors.ErrCurrencyNotAllowed, http.StatusUnprocessableEntity},
+	{domainErrors.ErrFXRateUnavailable, http.StatusUnprocessableEntity},
+	{domainErrors.ErrExportThrottled, http.StatusTooManyRequests},
+	{domainErrors.ErrRateLimited, http.StatusTooManyRequests},
+	{domainErrors.ErrDuplicateRecord, http.StatusConflict},
+	{domainErrors.ErrReferenceNotFound, http.StatusNotFound},
+	{domainErrors.ErrReadOnly, http.StatusServiceUnavailable},
+	{domainErrors.ErrFenced, http.StatusServiceUnavailable},
+	{domainErrors.ErrJournalUnavailable, http.StatusServiceUnavailable},
+}
+
+// StatusForError returns the HTTP status code for err
+func StatusForError(err error) int {
+	for _, s := range statusByError {
+		if errors.Is(err, s.err) {
+			return s.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// JSON writes v as a JSON response with the given status code
+func JSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if a, ok := v.(Appender); ok {
+		buf := bufferPool.Get().(*[]byte)
+		*buf = append(a.AppendJSON((*buf)[:0]), '\n')
+		if _, err := w.Write(*buf); err != nil {
+			logger.Default().Error("Failed to write response", "err", err)
+		}
+		bufferPool.Put(buf)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Default().Error("Failed to encode response", "err", err)
+	}
+}
+
+// Appender is implemented by response bodies that encode themselves without reflection, such as
+// the v1 Account and Transaction. JSON writes them through a pooled buffer.
+type Appender interface {
+	AppendJSON(dst []byte) []byte
+}
+
+// bufferPool holds the buffers Appender bodies are encoded into
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	},
+}
+
+// Error writes err as an error response. Internal errors are reported with a generic message
+// so database details never reach clients. The request identifier the request_id middleware set
+// on the response is echoed in the body, so clients can quote it with the error.
+func Error(w http.ResponseWriter, err error) {
+	status := StatusForError(err)
+	body := ErrorBody{
+		Code:      domainErrors.Code(err),
+		Message:   err.Error(),
+		Details:   domainErrors.Details(err),
+		RequestID: w.Header().Get(requestid.Header),
+	}
+	if status == http.StatusInternalServerError {
+		logger.Default().ErrorContext(requestid.WithID(context.Background(), body.RequestID), "Internal error", "err", err)
+		body.Message = "internal server error"
+		body.Details = nil
+	}
+	JSON(w, status, ErrorResponse{Error: body})
+}
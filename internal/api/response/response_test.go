@@ -0,0 +1,71 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/khamiruf/internal_transfers_system_go/internal/api/dto/v1"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+// reflectAccount has the fields of v1.Account but not its methods, so it takes the encoder path
+type reflectAccount v1.Account
+
+var benchAccount = v1.Account{AccountID: 123, Balance: "100.23344", Status: "active", Currency: "USD"}
+
+func TestJSON_AppenderMatchesEncoder(t *testing.T) {
+	fast := httptest.NewRecorder()
+	JSON(fast, http.StatusOK, benchAccount)
+
+	slow := httptest.NewRecorder()
+	JSON(slow, http.StatusOK, reflectAccount(benchAccount))
+
+	assert.Equal(t, "application/json", fast.Header().Get("Content-Type"))
+	assert.Equal(t, slow.Body.String(), fast.Body.String())
+
+	var decoded v1.Account
+	assert.NoError(t, json.Unmarshal(fast.Body.Bytes(), &decoded))
+	assert.Equal(t, benchAccount, decoded)
+}
+
+func TestError_EchoesRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(requestid.Header, "req-42")
+	Error(rec, domainErrors.NewAccountNotFoundError(7))
+
+	var decoded ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decoded))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "req-42", decoded.Error.RequestID)
+
+	rec = httptest.NewRecorder()
+	Error(rec, domainErrors.NewAccountNotFoundError(7))
+	assert.NotContains(t, rec.Body.String(), "request_id")
+}
+
+// discardWriter is a ResponseWriter that drops the body, so benchmarks measure encoding only
+type discardWriter struct{ header http.Header }
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func BenchmarkJSON(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	b.Run("encoder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			JSON(w, http.StatusOK, reflectAccount(benchAccount))
+		}
+	})
+	b.Run("appender", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			JSON(w, http.StatusOK, benchAccount)
+		}
+	})
+}
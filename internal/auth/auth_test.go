@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a Store keeping credentials in memory
+type memoryStore struct {
+	mu          sync.Mutex
+	credentials []*Credential
+	hashes      []string
+	secrets     []string
+}
+
+func (s *memoryStore) CreateCredential(ctx context.Context, credential *Credential, keyHash string) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.credentials {
+		if existing.Name == credential.Name {
+			return nil, domainErrors.ErrCredentialExists
+		}
+	}
+	created := *credential
+	created.ID = int64(len(s.credentials) + 1)
+	s.credentials = append(s.credentials, &created)
+	s.hashes = append(s.hashes, keyHash)
+	s.secrets = append(s.secrets, "")
+	return &created, nil
+}
+
+func (s *memoryStore) GetCredentialByKeyHash(ctx context.Context, keyHash string) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, hash := range s.hashes {
+		if hash == keyHash && s.credentials[i].RevokedAt == nil {
+			return s.credentials[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memoryStore) ListCredentials(ctx context.Context) ([]*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Credential{}, s.credentials...), nil
+}
+
+func (s *memoryStore) RevokeCredential(ctx context.Context, credentialID int64, now time.Time) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if credentialID < 1 || int(credentialID) > len(s.credentials) {
+		return nil, domainErrors.ErrCredentialNotFound
+	}
+	credential := s.credentials[credentialID-1]
+	if credential.RevokedAt == nil {
+		credential.RevokedAt = &now
+	}
+	return credential, nil
+}
+
+func (s *memoryStore) SetSigningSecret(ctx context.Context, credentialID int64, secret string) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if credentialID < 1 || int(credentialID) > len(s.credentials) || s.credentials[credentialID-1].RevokedAt != nil {
+		return nil, domainErrors.ErrCredentialNotFound
+	}
+	s.secrets[credentialID-1] = secret
+	s.credentials[credentialID-1].Signing = true
+	return s.credentials[credentialID-1], nil
+}
+
+func (s *memoryStore) GetSigningSecret(ctx context.Context, credentialID int64) (*Credential, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if credentialID < 1 || int(credentialID) > len(s.credentials) {
+		return nil, "", sql.ErrNoRows
+	}
+	credential, secret := s.credentials[credentialID-1], s.secrets[credentialID-1]
+	if credential.RevokedAt != nil || secret == "" {
+		return nil, "", sql.ErrNoRows
+	}
+	return credential, secret, nil
+}
+
+func TestCredential_HasScope(t *testing.T) {
+	reader := &Credential{Scopes: []Scope{ScopeAccountsRead}}
+	assert.True(t, reader.HasScope(ScopeAccountsRead))
+	assert.False(t, reader.HasScope(ScopeAccountsWrite))
+
+	writer := &Credential{Scopes: []Scope{ScopeAccountsWrite}}
+	assert.True(t, writer.HasScope(ScopeAccountsRead), "write grants read")
+	assert.False(t, writer.HasScope(ScopeTransfersCreate))
+
+	admin := &Credential{Scopes: []Scope{ScopeAdmin}}
+	assert.True(t, admin.HasScope(ScopeReportsRead))
+
+	operator := &Credential{Roles: []Role{RoleOperator}}
+	assert.True(t, operator.HasScope(ScopeOperations))
+	assert.True(t, operator.HasScope(ScopeAccountsRead))
+	assert.False(t, operator.HasScope(ScopeTransfersCreate))
+	assert.False(t, operator.HasScope(ScopeAuditRead))
+
+	auditor := &Credential{Scopes: []Scope{ScopeTransfersCreate}, Roles: []Role{RoleAuditor}}
+	assert.True(t, auditor.HasScope(ScopeAuditRead))
+	assert.True(t, auditor.HasScope(ScopeTransfersCreate), "scopes add to roles")
+	assert.False(t, auditor.HasScope(ScopeOperations))
+	assert.False(t, auditor.HasScope(ScopeAccountsWrite))
+
+	client := &Credential{Roles: []Role{RoleClient}}
+	assert.True(t, client.HasScope(ScopeAccountsRead))
+	assert.True(t, client.HasScope(ScopeTransfersCreate))
+	assert.False(t, client.HasScope(ScopeOperations))
+
+	unknown := &Credential{Roles: []Role{"superuser"}}
+	assert.False(t, unknown.HasScope(ScopeAccountsRead))
+}
+
+func TestRequire(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, Require(ctx, ScopeAdmin), "work without a credential isn't restricted")
+
+	ctx = WithCredential(ctx, &Credential{Name: "payroll", Scopes: []Scope{ScopeTransfersCreate}})
+	assert.NoError(t, Require(ctx, ScopeTransfersCreate))
+	assert.ErrorIs(t, Require(ctx, ScopeReportsRead), domainErrors.ErrInsufficientScope)
+}
+
+func TestOwner(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", Owner(ctx))
+	assert.Equal(t, "7", Owner(WithCredential(ctx, &Credential{ID: 7, Name: "payroll"})))
+	assert.Equal(t, TokenNamePrefix+"payroll", Owner(WithCredential(ctx, &Credential{Name: TokenNamePrefix + "payroll"})))
+}
+
+func TestMiddleware_OwnerHeader(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(&memoryStore{})
+	payroll, payrollKey, err := manager.Issue(ctx, "payroll", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+	_, adminKey, err := manager.Issue(ctx, "ops", []Scope{ScopeAdmin}, nil)
+	require.NoError(t, err)
+
+	var seenOwner string
+	handler := Middleware(manager, Routes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenOwner = Owner(r.Context())
+	}))
+	send := func(key string) string {
+		req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set(OwnerHeader, "99")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return seenOwner
+	}
+
+	assert.Equal(t, "99", send(adminKey), "an admin replays requests as their original owner")
+	assert.Equal(t, strconv.FormatInt(payroll.ID, 10), send(payrollKey), "anyone else uses its own keys")
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(&memoryStore{})
+
+	for _, scopes := range [][]Scope{nil, {"accounts:delete"}} {
+		_, _, err := manager.Issue(ctx, "payroll", scopes, nil)
+		assert.ErrorIs(t, err, domainErrors.ErrValidationFailed)
+	}
+	_, _, err := manager.Issue(ctx, "payroll", nil, []Role{"superuser"})
+	assert.ErrorIs(t, err, domainErrors.ErrValidationFailed)
+	_, _, err = manager.Issue(ctx, " ", []Scope{ScopeAdmin}, nil)
+	assert.ErrorIs(t, err, domainErrors.ErrValidationFailed)
+
+	credential, key, err := manager.Issue(ctx, "payroll", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, keyPrefix))
+	_, _, err = manager.Issue(ctx, "payroll", []Scope{ScopeAdmin}, nil)
+	assert.ErrorIs(t, err, domainErrors.ErrCredentialExists)
+
+	authenticated, err := manager.Authenticate(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, credential.ID, authenticated.ID)
+
+	auditor, _, err := manager.Issue(ctx, "internal-audit", nil, []Role{RoleAuditor})
+	require.NoError(t, err)
+	assert.Equal(t, []Scope{}, auditor.Scopes)
+	assert.Equal(t, []Role{RoleAuditor}, auditor.Roles)
+	for _, wrong := range []string{"", "itk_unknown", key[len(keyPrefix):]} {
+		_, err := manager.Authenticate(ctx, wrong)
+		assert.ErrorIs(t, err, domainErrors.ErrUnauthenticated, wrong)
+	}
+
+	_, err = manager.Revoke(ctx, credential.ID)
+	require.NoError(t, err)
+	_, err = manager.Authenticate(ctx, key)
+	assert.ErrorIs(t, err, domainErrors.ErrUnauthenticated, "revoked")
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(&memoryStore{})
+	_, payrollKey, err := manager.Issue(ctx, "payroll", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+	_, adminKey, err := manager.Issue(ctx, "ops", []Scope{ScopeAdmin}, nil)
+	require.NoError(t, err)
+	_, operatorKey, err := manager.Issue(ctx, "back-office", nil, []Role{RoleOperator})
+	require.NoError(t, err)
+	_, auditorKey, err := manager.Issue(ctx, "internal-audit", nil, []Role{RoleAuditor})
+	require.NoError(t, err)
+
+	var seenActor string
+	handler := Middleware(manager, Routes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenActor = actor.FromContext(r.Context())
+		_, ok := FromContext(r.Context())
+		assert.Equal(t, seenActor != actor.System, ok)
+	}))
+
+	for _, tc := range []struct {
+		method, path, key string
+		status            int
+	}{
+		{http.MethodGet, "/readyz", "", http.StatusOK},
+		{http.MethodGet, "/version", "", http.StatusOK},
+		{http.MethodPost, "/transactions", "", http.StatusUnauthorized},
+		{http.MethodPost, "/transactions", "itk_unknown", http.StatusUnauthorized},
+		{http.MethodPost, "/transactions", payrollKey, http.StatusOK},
+		{http.MethodPost, "/transactions/async", payrollKey, http.StatusOK},
+		{http.MethodPost, "/transactions/42/reverse", payrollKey, http.StatusForbidden},
+		{http.MethodGet, "/accounts/42", payrollKey, http.StatusForbidden},
+		{http.MethodGet, "/admin/workers", payrollKey, http.StatusForbidden},
+		{http.MethodGet, "/tenants", payrollKey, http.StatusForbidden},
+		{http.MethodGet, "/admin/workers", adminKey, http.StatusOK},
+		{http.MethodPost, "/admin/accounts/42/freeze", operatorKey, http.StatusOK},
+		{http.MethodPost, "/admin/accounts/42/adjustments", operatorKey, http.StatusOK},
+		{http.MethodGet, "/accounts/42", operatorKey, http.StatusOK},
+		{http.MethodGet, "/admin/audit", operatorKey, http.StatusForbidden},
+		{http.MethodPost, "/transactions", operatorKey, http.StatusForbidden},
+		{http.MethodGet, "/admin/workers", operatorKey, http.StatusForbidden},
+		{http.MethodGet, "/admin/audit", auditorKey, http.StatusOK},
+		{http.MethodGet, "/reports/daily", auditorKey, http.StatusOK},
+		{http.MethodPost, "/admin/accounts/42/freeze", auditorKey, http.StatusForbidden},
+		{http.MethodPost, "/accounts", auditorKey, http.StatusForbidden},
+		{http.MethodPost, "/admin/accounts/42/freeze", payrollKey, http.StatusForbidden},
+		{http.MethodPost, "/admin/accounts/42/freeze", adminKey, http.StatusOK},
+	} {
+		seenActor = ""
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set(actor.Header, "spoofed")
+		if tc.key != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tc.status, rec.Code, "%s %s", tc.method, tc.path)
+		if tc.status == http.StatusOK && tc.key == payrollKey {
+			assert.Equal(t, "payroll", seenActor)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// keyPrefix marks API keys so leaked keys are easy to recognize in logs and code
+const keyPrefix = "itk_"
+
+// signingSecretPrefix marks signing secrets likewise
+const signingSecretPrefix = "its_"
+
+// maxNameLength is the longest credential name the schema stores
+const maxNameLength = 100
+
+// Store persists credentials by the hash of their key
+type Store interface {
+	// CreateCredential stores a credential under keyHash, or returns ErrCredentialExists if its
+	// name is taken
+	CreateCredential(ctx context.Context, credential *Credential, keyHash string) (*Credential, error)
+	// GetCredentialByKeyHash retrieves the unrevoked credential of a key hash
+	GetCredentialByKeyHash(ctx context.Context, keyHash string) (*Credential, error)
+	// ListCredentials returns every credential, revoked ones included, oldest first
+	ListCredentials(ctx context.Context) ([]*Credential, error)
+	// RevokeCredential revokes a credential at now; revoking it again keeps the first revocation
+	RevokeCredential(ctx context.Context, credentialID int64, now time.Time) (*Credential, error)
+	// SetSigningSecret replaces the signing secret of an unrevoked credential, or returns
+	// ErrCredentialNotFound if there is none
+	SetSigningSecret(ctx context.Context, credentialID int64, secret string) (*Credential, error)
+	// GetSigningSecret retrieves an unrevoked credential having a signing secret, and the secret
+	GetSigningSecret(ctx context.Context, credentialID int64) (*Credential, string, error)
+}
+
+// Manager issues, revokes and authenticates API credentials
+type Manager struct {
+	store Store
+	now   func() time.Time
+	log   *slog.Logger
+}
+
+// NewManager creates a credential manager on store
+func NewManager(store Store) *Manager {
+	return &Manager{store: store, now: time.Now, log: logger.Default()}
+}
+
+// Issue creates a credential named name granting scopes and roles, at least one of either, and
+// returns it with its key, which is shown only this once
+func (m *Manager) Issue(ctx context.Context, name string, scopes []Scope, roles []Role) (*Credential, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", errors.ErrValidationFailed, maxNameLength)
+	}
+	if len(scopes) == 0 && len(roles) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope or role is required", errors.ErrValidationFailed)
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", errors.ErrValidationFailed, scope)
+		}
+	}
+	for _, role := range roles {
+		if !role.IsValid() {
+			return nil, "", fmt.Errorf("%w: unknown role %q", errors.ErrValidationFailed, role)
+		}
+	}
+	if scopes == nil {
+		scopes = []Scope{}
+	}
+	if roles == nil {
+		roles = []Role{}
+	}
+
+	key, err := randomToken(keyPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	credential, err := m.store.CreateCredential(ctx, &Credential{Name: name, Scopes: scopes, Roles: roles}, hashKey(key))
+	if err != nil {
+		return nil, "", err
+	}
+	m.log.InfoContext(ctx, "Issued API credential", "credential_id", credential.ID, "name", credential.Name, "scopes", credential.Scopes, "roles", credential.Roles)
+	return credential, key, nil
+}
+
+// IssueSigningSecret gives a credential a new secret to sign requests with, replacing any it had,
+// and returns it with the secret, which is shown only this once
+func (m *Manager) IssueSigningSecret(ctx context.Context, credentialID int64) (*Credential, string, error) {
+	secret, err := randomToken(signingSecretPrefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	credential, err := m.store.SetSigningSecret(ctx, credentialID, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	m.log.InfoContext(ctx, "Issued signing secret of API credential", "credential_id", credential.ID, "name", credential.Name)
+	return credential, secret, nil
+}
+
+// List returns every credential, revoked ones included, oldest first
+func (m *Manager) List(ctx context.Context) ([]*Credential, error) {
+	return m.store.ListCredentials(ctx)
+}
+
+// Revoke revokes a credential, so its key no longer authenticates
+func (m *Manager) Revoke(ctx context.Context, credentialID int64) (*Credential, error) {
+	credential, err := m.store.RevokeCredential(ctx, credentialID, m.now())
+	if err != nil {
+		return nil, err
+	}
+	m.log.InfoContext(ctx, "Revoked API credential", "credential_id", credential.ID, "name", credential.Name)
+	return credential, nil
+}
+
+// Authenticate returns the credential of key, or ErrUnauthenticated if key is unknown or revoked
+func (m *Manager) Authenticate(ctx context.Context, key string) (*Credential, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, errors.ErrUnauthenticated
+	}
+	credential, err := m.store.GetCredentialByKeyHash(ctx, hashKey(key))
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// randomToken returns 256 random bits, base64url-encoded after prefix
+func randomToken(prefix string) (string, error) {
+	var secret [32]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(secret[:]), nil
+}
+
+// hashKey returns the hex SHA-256 of key, under which its credential is stored. Keys carry 256
+// random bits, so an unsalted fast hash can't be reversed.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// PostgresStore is a Store backed by the api_credentials table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new Postgres credential store
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// credentialColumns is the column list selected by every credential read, in scanCredential order
+const credentialColumns = `id, name, scopes, roles, signing_secret IS NOT NULL, created_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCredential(row rowScanner) (*Credential, error) {
+	var credential Credential
+	var scopes, roles []byte
+	var revokedAt sql.NullTime
+	if err := row.Scan(&credential.ID, &credential.Name, &scopes, &roles, &credential.Signing, &credential.CreatedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	credential.Scopes = []Scope{}
+	if err := json.Unmarshal(scopes, &credential.Scopes); err != nil {
+		return nil, fmt.Errorf("invalid scopes of credential %d: %w", credential.ID, err)
+	}
+	credential.Roles = []Role{}
+	if err := json.Unmarshal(roles, &credential.Roles); err != nil {
+		return nil, fmt.Errorf("invalid roles of credential %d: %w", credential.ID, err)
+	}
+	if revokedAt.Valid {
+		credential.RevokedAt = &revokedAt.Time
+	}
+	return &credential, nil
+}
+
+// CreateCredential stores a credential under keyHash, or returns ErrCredentialExists if its name
+// is taken
+func (s *PostgresStore) CreateCredential(ctx context.Context, credential *Credential, keyHash string) (*Credential, error) {
+	scopes, err := json.Marshal(credential.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scopes: %w", err)
+	}
+	roles, err := json.Marshal(credential.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode roles: %w", err)
+	}
+	created, err := scanCredential(s.db.QueryRowContext(ctx, `
+		INSERT INTO api_credentials (name, key_hash, scopes, roles) VALUES ($1, $2, $3::jsonb, $4::jsonb)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING `+credentialColumns,
+		credential.Name, keyHash, string(scopes), string(roles),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", errors.ErrCredentialExists, credential.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential: %w", err)
+	}
+	return created, nil
+}
+
+// GetCredentialByKeyHash retrieves the unrevoked credential of a key hash, or sql.ErrNoRows if
+// there is none
+func (s *PostgresStore) GetCredentialByKeyHash(ctx context.Context, keyHash string) (*Credential, error) {
+	credential, err := scanCredential(s.db.QueryRowContext(ctx, `
+		SELECT `+credentialColumns+` FROM api_credentials WHERE key_hash = $1 AND revoked_at IS NULL
+	`, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credential: %w", err)
+	}
+	return credential, nil
+}
+
+// ListCredentials returns every credential, revoked ones included, oldest first
+func (s *PostgresStore) ListCredentials(ctx context.Context) ([]*Credential, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+credentialColumns+` FROM api_credentials ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	defer rows.Close()
+
+	credentials := []*Credential{}
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credential: %w", err)
+		}
+		credentials = append(credentials, credential)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// RevokeCredential revokes a credential at now; revoking it again keeps the first revocation
+func (s *PostgresStore) RevokeCredential(ctx context.Context, credentialID int64, now time.Time) (*Credential, error) {
+	credential, err := scanCredential(s.db.QueryRowContext(ctx, `
+		UPDATE api_credentials SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id = $1
+		RETURNING `+credentialColumns,
+		credentialID, now,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%d", errors.ErrCredentialNotFound, credentialID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke credential: %w", err)
+	}
+	return credential, nil
+}
+
+// SetSigningSecret replaces the signing secret of an unrevoked credential, or returns
+// ErrCredentialNotFound if there is none
+func (s *PostgresStore) SetSigningSecret(ctx context.Context, credentialID int64, secret string) (*Credential, error) {
+	credential, err := scanCredential(s.db.QueryRowContext(ctx, `
+		UPDATE api_credentials SET signing_secret = $2
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+credentialColumns,
+		credentialID, secret,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%d", errors.ErrCredentialNotFound, credentialID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set signing secret: %w", err)
+	}
+	return credential, nil
+}
+
+// GetSigningSecret retrieves an unrevoked credential having a signing secret, and the secret, or
+// sql.ErrNoRows if there is none
+func (s *PostgresStore) GetSigningSecret(ctx context.Context, credentialID int64) (*Credential, string, error) {
+	var secret string
+	credential, err := scanCredential(secretScanner{row: s.db.QueryRowContext(ctx, `
+		SELECT `+credentialColumns+`, signing_secret FROM api_credentials
+		WHERE id = $1 AND revoked_at IS NULL AND signing_secret IS NOT NULL
+	`, credentialID), secret: &secret})
+	if err == sql.ErrNoRows {
+		return nil, "", err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get signing secret: %w", err)
+	}
+	return credential, secret, nil
+}
+
+// secretScanner scans a credential row followed by its signing secret
+type secretScanner struct {
+	row    rowScanner
+	secret *string
+}
+
+func (s secretScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.secret)...)
+}
@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// TokenNamePrefix prefixes the credential name of a bearer token's caller, so an identity of the
+// identity provider can't pose as an API credential of the same name in audit logs
+const TokenNamePrefix = "oidc:"
+
+// minJWKSRefetch is the least time between fetches of the key set, so tokens with made-up key IDs
+// can't flood the identity provider
+const minJWKSRefetch = 30 * time.Second
+
+// OIDCConfig configures the validation of JWT bearer tokens issued by an OpenID Connect provider
+type OIDCConfig struct {
+	Issuer  string // the iss every token must carry
+	JWKSURL string // where the provider publishes its signing keys
+	// Audience, if set, must be among the aud of every token
+	Audience string
+	// NameClaim names the claim identifying the caller, "sub" if empty
+	NameClaim string
+	// ScopeClaim names the claim listing the scopes granted, space-separated or as an array,
+	// "scope" if empty
+	ScopeClaim string
+	// RoleClaim names the claim listing the roles granted, space-separated or as an array,
+	// "roles" if empty
+	RoleClaim string
+	// RefreshInterval is how long the signing keys are used before being fetched again
+	RefreshInterval time.Duration
+	// Leeway tolerates clock skew with the provider when checking exp and nbf
+	Leeway time.Duration
+}
+
+// JWTVerifier authenticates callers by JWT bearer tokens signed with RS256 or ES256 by a key of
+// the provider's JWKS. The caller becomes a credential named TokenNamePrefix plus its NameClaim,
+// granted the known scopes of its ScopeClaim and roles of its RoleClaim; unknown ones are ignored.
+type JWTVerifier struct {
+	cfg    OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // by key ID
+	fetchedAt   time.Time                   // when keys were fetched
+	attemptedAt time.Time                   // when a fetch was last attempted
+	log         *slog.Logger
+}
+
+// NewJWTVerifier creates a verifier of the tokens of the provider configured in cfg, fetching its
+// keys with client
+func NewJWTVerifier(cfg OIDCConfig, client *http.Client) (*JWTVerifier, error) {
+	if cfg.Issuer == "" || cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("%w: bearer token authentication requires an issuer and a JWKS URL", errors.ErrValidationFailed)
+	}
+	if cfg.NameClaim == "" {
+		cfg.NameClaim = "sub"
+	}
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = "scope"
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "roles"
+	}
+	return &JWTVerifier{cfg: cfg, client: client, now: time.Now, log: logger.Default()}, nil
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Authenticate verifies token and returns the credential of its caller, or ErrUnauthenticated if
+// it isn't a valid token of the provider. A failure to fetch the provider's keys is returned as is.
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*Credential, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.ErrUnauthenticated
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, v.unauthenticated(ctx, "malformed header", "err", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, v.unauthenticated(ctx, "malformed signature", "err", err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, v.unauthenticated(ctx, "unknown signing key", "kid", header.Kid)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], signature) {
+		return nil, v.unauthenticated(ctx, "invalid signature", "alg", header.Alg, "kid", header.Kid)
+	}
+
+	var claims map[string]interface{}
+	decoder := json.NewDecoder(base64.NewDecoder(base64.RawURLEncoding, strings.NewReader(parts[1])))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, v.unauthenticated(ctx, "malformed claims", "err", err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, v.unauthenticated(ctx, "invalid claims", "err", err)
+	}
+
+	name, _ := claims[v.cfg.NameClaim].(string)
+	if name == "" {
+		return nil, v.unauthenticated(ctx, "no name claim", "claim", v.cfg.NameClaim)
+	}
+	return &Credential{
+		Name:   TokenNamePrefix + name,
+		Scopes: scopesOf(claims[v.cfg.ScopeClaim]),
+		Roles:  rolesOf(claims[v.cfg.RoleClaim]),
+	}, nil
+}
+
+// checkClaims checks the issuer, audience and validity period of a token
+func (v *JWTVerifier) checkClaims(claims map[string]interface{}) error {
+	if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+		return fmt.Errorf("issuer %q isn't %q", iss, v.cfg.Issuer)
+	}
+	if v.cfg.Audience != "" && !hasAudience(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("audience %v doesn't include %q", claims["aud"], v.cfg.Audience)
+	}
+	now := v.now()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("no exp claim")
+	}
+	if !now.Before(exp.Add(v.cfg.Leeway)) {
+		return fmt.Errorf("expired at %s", exp.Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.cfg.Leeway).Before(nbf) {
+		return fmt.Errorf("not valid before %s", nbf.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// key returns the signing key kid, fetching the key set if it is stale or doesn't have the key.
+// Fetches are at least minJWKSRefetch apart; in between, and while the provider is unreachable,
+// the keys last fetched are used. It returns nil if the provider has no such key.
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	key, ok := v.keys[kid]
+	stale := v.keys == nil || (v.cfg.RefreshInterval > 0 && now.Sub(v.fetchedAt) >= v.cfg.RefreshInterval)
+	if ok && !stale {
+		return key, nil
+	}
+	if now.Sub(v.attemptedAt) < minJWKSRefetch {
+		if v.keys == nil {
+			return nil, fmt.Errorf("signing keys of %s are unavailable", v.cfg.Issuer)
+		}
+		return key, nil
+	}
+
+	v.attemptedAt = now
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		if v.keys == nil {
+			return nil, err
+		}
+		v.log.WarnContext(ctx, "Refreshing the signing keys failed", "issuer", v.cfg.Issuer, "err", err)
+		return key, nil
+	}
+	v.keys, v.fetchedAt = keys, now
+	return keys[kid], nil
+}
+
+// jwk is a JSON Web Key; only the fields of RSA and P-256 EC signing keys are read
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys fetches the signing keys of the provider's JWKS, skipping keys of other types and uses
+func (v *JWTVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS URL: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err != nil {
+			v.log.WarnContext(ctx, "Skipping signing key", "kid", k.Kid, "issuer", v.cfg.Issuer, "err", err)
+		} else if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey decodes an RSA or P-256 key, or returns nil for other key types
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid coordinates")
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("point not on P-256")
+		}
+		return key, nil
+	}
+	return nil, nil
+}
+
+// verifySignature checks the signature of digest by key with alg. Only RS256 and ES256 are
+// accepted, so neither an unsigned token ("none") nor one signed with the public key as an HMAC
+// secret gets through.
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) bool {
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest, signature) == nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(ecKey, digest, r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url JSON segment of a token into v
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(raw)).Decode(v)
+}
+
+// numericDate reads a NumericDate claim, seconds since the epoch
+func numericDate(claim interface{}) (time.Time, bool) {
+	n, ok := claim.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// hasAudience reports whether the aud claim, a string or an array of strings, includes audience
+func hasAudience(claim interface{}, audience string) bool {
+	switch aud := claim.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scopesOf reads the known scopes of a scope claim, space-separated or an array
+func scopesOf(claim interface{}) []Scope {
+	scopes := []Scope{}
+	for _, name := range namesOf(claim) {
+		if scope := Scope(name); scope.IsValid() {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// rolesOf reads the known roles of a role claim, space-separated or an array
+func rolesOf(claim interface{}) []Role {
+	roles := []Role{}
+	for _, name := range namesOf(claim) {
+		if role := Role(name); role.IsValid() {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// namesOf reads a claim listing names, space-separated or as an array of strings
+func namesOf(claim interface{}) []string {
+	var names []string
+	switch c := claim.(type) {
+	case string:
+		names = strings.Fields(c)
+	case []interface{}:
+		for _, name := range c {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+	return names
+}
+
+// unauthenticated logs why a token was rejected and returns ErrUnauthenticated, which doesn't
+// tell the caller
+func (v *JWTVerifier) unauthenticated(ctx context.Context, reason string, args ...interface{}) error {
+	v.log.WarnContext(ctx, "Rejected bearer token", append([]interface{}{"reason", reason}, args...)...)
+	return errors.ErrUnauthenticated
+}
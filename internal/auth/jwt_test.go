@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "https://idp.internal"
+
+type testProvider struct {
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p := &testProvider{rsaKey: rsaKey, ecKey: ecKey}
+	b64 := base64.RawURLEncoding.EncodeToString
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(rsaKey.N.Bytes()), "e": "AQAB"},
+		}})
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, p.rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, p.ecKey, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"aud":   []string{"transfers", "other"},
+		"sub":   "alice",
+		"scope": "accounts:read transfers:create openid",
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T, p *testProvider, now time.Time) *JWTVerifier {
+	v, err := NewJWTVerifier(OIDCConfig{
+		Issuer:          testIssuer,
+		JWKSURL:         p.server.URL,
+		Audience:        "transfers",
+		RefreshInterval: time.Hour,
+		Leeway:          30 * time.Second,
+	}, p.server.Client())
+	require.NoError(t, err)
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func TestJWTVerifier_Authenticate(t *testing.T) {
+	p := newTestProvider(t)
+	now := time.Now()
+	v := newTestVerifier(t, p, now)
+	ctx := context.Background()
+
+	for _, alg := range []struct{ alg, kid string }{{"RS256", "rsa-1"}, {"ES256", "ec-1"}} {
+		credential, err := v.Authenticate(ctx, p.sign(t, alg.alg, alg.kid, validClaims(now)))
+		require.NoError(t, err, alg.alg)
+		assert.Equal(t, "oidc:alice", credential.Name)
+		assert.Equal(t, []Scope{ScopeAccountsRead, ScopeTransfersCreate}, credential.Scopes)
+	}
+
+	credential, err := v.Authenticate(ctx, p.sign(t, "RS256", "rsa-1", with(validClaims(now), "roles", []string{"auditor", "root"})))
+	require.NoError(t, err)
+	assert.Equal(t, []Role{RoleAuditor}, credential.Roles)
+	assert.True(t, credential.HasScope(ScopeAuditRead))
+	assert.Equal(t, int32(1), p.fetches.Load())
+
+	invalid := map[string]string{
+		"wrong issuer":      p.sign(t, "RS256", "rsa-1", with(validClaims(now), "iss", "https://evil")),
+		"wrong audience":    p.sign(t, "RS256", "rsa-1", with(validClaims(now), "aud", "other")),
+		"expired":           p.sign(t, "RS256", "rsa-1", with(validClaims(now), "exp", now.Add(-time.Minute).Unix())),
+		"no expiry":         p.sign(t, "RS256", "rsa-1", with(validClaims(now), "exp", nil)),
+		"not yet valid":     p.sign(t, "RS256", "rsa-1", with(validClaims(now), "nbf", now.Add(time.Minute).Unix())),
+		"no subject":        p.sign(t, "RS256", "rsa-1", with(validClaims(now), "sub", nil)),
+		"algorithm none":    p.sign(t, "none", "rsa-1", validClaims(now)),
+		"key of other type": p.sign(t, "RS256", "ec-1", validClaims(now)),
+		"encryption key":    p.sign(t, "RS256", "enc-1", validClaims(now)),
+		"unknown key":       p.sign(t, "RS256", "rsa-2", validClaims(now)),
+		"api key":           "itk_abc",
+	}
+	forged, err := json.Marshal(with(validClaims(now), "scope", "admin"))
+	require.NoError(t, err)
+	parts := strings.Split(p.sign(t, "RS256", "rsa-1", validClaims(now)), ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString(forged)
+	invalid["tampered claims"] = strings.Join(parts, ".")
+
+	for name, token := range invalid {
+		_, err := v.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, errors.ErrUnauthenticated, name)
+	}
+	// Unknown keys refetch the key set at most every minJWKSRefetch
+	assert.Equal(t, int32(1), p.fetches.Load())
+	v.now = func() time.Time { return now.Add(minJWKSRefetch) }
+	_, err = v.Authenticate(ctx, invalid["unknown key"])
+	assert.ErrorIs(t, err, errors.ErrUnauthenticated)
+	assert.Equal(t, int32(2), p.fetches.Load())
+}
+
+func TestJWTVerifier_KeysUnavailable(t *testing.T) {
+	p := newTestProvider(t)
+	now := time.Now()
+	v := newTestVerifier(t, p, now)
+	token := p.sign(t, "RS256", "rsa-1", validClaims(now))
+
+	p.server.Close()
+	_, err := v.Authenticate(context.Background(), token)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errors.ErrUnauthenticated)
+}
+
+func TestAuthenticators(t *testing.T) {
+	p := newTestProvider(t)
+	now := time.Now()
+	manager := NewManager(&memoryStore{})
+	_, key, err := manager.Issue(context.Background(), "payroll", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+
+	a := Authenticators{manager, newTestVerifier(t, p, now)}
+	credential, err := a.Authenticate(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "payroll", credential.Name)
+
+	credential, err = a.Authenticate(context.Background(), p.sign(t, "RS256", "rsa-1", validClaims(now)))
+	require.NoError(t, err)
+	assert.Equal(t, "oidc:alice", credential.Name)
+
+	_, err = a.Authenticate(context.Background(), "itk_unknown")
+	assert.ErrorIs(t, err, errors.ErrUnauthenticated)
+}
+
+func with(claims map[string]interface{}, name string, value interface{}) map[string]interface{} {
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
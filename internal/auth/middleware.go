@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// Open marks a route that needs no credential
+const Open Scope = ""
+
+// Routes maps ServeMux patterns to the scope their requests require. The catch-all "/" requires
+// admin, so a route nobody gave a scope is closed to everyone else.
+var Routes = map[string]Scope{
+	"/": ScopeAdmin,
+
+	"GET /readyz":  Open,
+	"GET /metrics": Open,
+	"GET /version": Open,
+
+	"GET /accounts/":            ScopeAccountsRead,
+	"GET /owners/":              ScopeAccountsRead,
+	"GET /transactions":         ScopeAccountsRead,
+	"GET /transactions/":        ScopeAccountsRead,
+	"GET /transfers/":           ScopeAccountsRead,
+	"GET /jobs/":                ScopeAccountsRead,
+	"GET /preauthorizations/":   ScopeAccountsRead,
+	"GET /scheduled-transfers":  ScopeAccountsRead,
+	"GET /scheduled-transfers/": ScopeAccountsRead,
+	"GET /standing-orders/":     ScopeAccountsRead,
+	"GET /idempotency-keys/":    ScopeAccountsRead, // finds only the caller's own keys (Owner)
+	"GET /graphql":              ScopeAccountsRead,
+	"POST /graphql":             ScopeAccountsRead, // queries only; the schema has no mutations
+
+	"POST /accounts":    ScopeAccountsWrite,
+	"POST /accounts/":   ScopeAccountsWrite,
+	"PUT /accounts/":    ScopeAccountsWrite,
+	"DELETE /accounts/": ScopeAccountsWrite,
+
+	"POST /transactions":                   ScopeTransfersCreate,
+	"POST /transactions/async":             ScopeTransfersCreate,
+	"POST /transfers/":                     ScopeTransfersCreate,
+	"POST /preauthorizations":              ScopeTransfersCreate,
+	"POST /preauthorizations/{id}/execute": ScopeTransfersCreate,
+	"POST /scheduled-transfers":            ScopeTransfersCreate,
+	"POST /scheduled-transfers/":           ScopeTransfersCreate,
+	"POST /standing-orders":                ScopeTransfersCreate,
+	"POST /standing-orders/":               ScopeTransfersCreate,
+
+	"GET /reports/":       ScopeReportsRead,
+	"GET /business-days/": ScopeReportsRead,
+
+	"POST /admin/accounts/{account_id}/freeze":      ScopeOperations,
+	"POST /admin/accounts/{account_id}/unfreeze":    ScopeOperations,
+	"POST /admin/accounts/{account_id}/close":       ScopeOperations,
+	"POST /admin/accounts/{account_id}/adjustments": ScopeOperations,
+	"GET /admin/accounts/{account_id}/adjustments":  ScopeOperations,
+	"POST /admin/jobs/{id}/cancel":                  ScopeOperations,
+	"POST /admin/jobs/{id}/requeue":                 ScopeOperations,
+	"POST /admin/jobs/{id}/priority":                ScopeOperations,
+
+	"GET /admin/audit": ScopeAuditRead,
+}
+
+// Authenticator identifies the caller presenting a bearer token: an API key (Manager), a JWT
+// (JWTVerifier) or either (Authenticators)
+type Authenticator interface {
+	// Authenticate returns the credential of token, or ErrUnauthenticated if it isn't valid
+	Authenticate(ctx context.Context, token string) (*Credential, error)
+}
+
+// Authenticators accepts a token any of its authenticators accepts, trying them in order
+type Authenticators []Authenticator
+
+// Authenticate returns the credential of the first authenticator accepting token. An error other
+// than ErrUnauthenticated, such as a failed database query, stops the search.
+func (a Authenticators) Authenticate(ctx context.Context, token string) (*Credential, error) {
+	for _, authenticator := range a {
+		credential, err := authenticator.Authenticate(ctx, token)
+		if err == nil || !stdErrors.Is(err, errors.ErrUnauthenticated) {
+			return credential, err
+		}
+	}
+	return nil, errors.ErrUnauthenticated
+}
+
+// Middleware authenticates every request by the bearer token in its Authorization header, unless
+// SignatureMiddleware already authenticated it by its signature, and rejects it with 401 without
+// a valid token, or with 403 if the credential lacks the scope routes gives its route. The
+// credential is carried in the request context for Require, and its name becomes the actor of
+// the request, replacing any X-Actor header; an admin may give the owner of its idempotency keys
+// in OwnerHeader. It panics on an invalid or conflicting pattern in routes.
+func Middleware(authenticator Authenticator, routes map[string]Scope) func(http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	scopes := make(map[string]Scope, len(routes))
+	for pattern, scope := range routes {
+		mux.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {})
+		scopes[pattern] = scope
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+			scope, ok := scopes[pattern]
+			if !ok {
+				scope = ScopeAdmin
+			}
+			if scope == Open {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			credential, signed := FromContext(r.Context())
+			if !signed {
+				key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if !found {
+					response.Error(w, errors.ErrUnauthenticated)
+					return
+				}
+				var err error
+				credential, err = authenticator.Authenticate(r.Context(), strings.TrimSpace(key))
+				if err != nil {
+					response.Error(w, err)
+					return
+				}
+			}
+			if !credential.HasScope(scope) {
+				logger.Default().WarnContext(r.Context(), "Credential denied: missing scope", "name", credential.Name, "method", r.Method, "path", r.URL.Path, "scope", scope)
+				response.Error(w, errors.ErrInsufficientScope)
+				return
+			}
+
+			ctx := actor.WithActor(WithCredential(r.Context(), credential), credential.Name)
+			if owner := r.Header.Get(OwnerHeader); owner != "" && credential.HasScope(ScopeAdmin) {
+				ctx = withOwner(ctx, owner)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package auth authenticates API requests by API key or JWT bearer token and authorizes them by
+// the scopes of the caller's credential, granted directly or through its roles. Scopes are
+// checked twice: per route by Middleware, and per operation by the services calling Require, so
+// a route added without a rule still can't reach an operation its caller isn't allowed.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Scope is a permission granted to a credential
+type Scope string
+
+const (
+	// ScopeAccountsRead allows reading accounts, balances and transactions
+	ScopeAccountsRead Scope = "accounts:read"
+	// ScopeAccountsWrite allows creating and changing accounts, and reading them
+	ScopeAccountsWrite Scope = "accounts:write"
+	// ScopeTransfersCreate allows moving money: transfers, batches, scheduled and async transfers
+	ScopeTransfersCreate Scope = "transfers:create"
+	// ScopeReportsRead allows reading reports
+	ScopeReportsRead Scope = "reports:read"
+	// ScopeOperations allows the operator actions on accounts: freezing, unfreezing and closing
+	// them, and adjusting their balances; and cancelling, requeueing and reprioritizing transfer jobs
+	ScopeOperations Scope = "operations"
+	// ScopeAuditRead allows reading the audit trail
+	ScopeAuditRead Scope = "audit:read"
+	// ScopeAdmin allows everything, including the admin endpoints and managing credentials
+	ScopeAdmin Scope = "admin"
+)
+
+// IsValid checks if s is a known scope
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeAccountsRead, ScopeAccountsWrite, ScopeTransfersCreate, ScopeReportsRead,
+		ScopeOperations, ScopeAuditRead, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// Role is a named set of scopes matching a kind of caller
+type Role string
+
+const (
+	// RoleClient is an integration moving money: it reads and manages accounts and transfers
+	RoleClient Role = "client"
+	// RoleOperator is back-office staff: it reads accounts, freezes, unfreezes and closes them, and
+	// adjusts their balances
+	RoleOperator Role = "operator"
+	// RoleAuditor reviews without changing anything: it reads accounts, reports and the audit trail
+	RoleAuditor Role = "auditor"
+)
+
+// roleScopes are the scopes each role grants
+var roleScopes = map[Role][]Scope{
+	RoleClient:   {ScopeAccountsWrite, ScopeTransfersCreate},
+	RoleOperator: {ScopeAccountsRead, ScopeOperations},
+	RoleAuditor:  {ScopeAccountsRead, ScopeReportsRead, ScopeAuditRead},
+}
+
+// IsValid checks if r is a known role
+func (r Role) IsValid() bool {
+	_, ok := roleScopes[r]
+	return ok
+}
+
+// Scopes returns the scopes r grants, none if it isn't a known role
+func (r Role) Scopes() []Scope {
+	return roleScopes[r]
+}
+
+// Credential is an API key and the scopes and roles it grants. The key itself isn't kept, only its
+// hash. Callers authenticated by a JWT get a credential without ID, built from the token's claims.
+type Credential struct {
+	ID     int64   `json:"id"`
+	Name   string  `json:"name"`
+	Scopes []Scope `json:"scopes"`
+	Roles  []Role  `json:"roles"`
+	// Signing reports whether the credential has a secret to sign requests with
+	Signing   bool       `json:"signing"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope checks if the credential grants scope, itself or through one of its roles; admin grants
+// every scope, and accounts:write grants accounts:read
+func (c *Credential) HasScope(scope Scope) bool {
+	if grants(c.Scopes, scope) {
+		return true
+	}
+	for _, role := range c.Roles {
+		if grants(role.Scopes(), scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func grants(scopes []Scope, scope Scope) bool {
+	for _, s := range scopes {
+		if s == scope || s == ScopeAdmin || (s == ScopeAccountsWrite && scope == ScopeAccountsRead) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithCredential returns a copy of ctx carrying the credential a request authenticated with
+func WithCredential(ctx context.Context, credential *Credential) context.Context {
+	return context.WithValue(ctx, contextKey{}, credential)
+}
+
+// FromContext returns the credential carried by ctx, if any
+func FromContext(ctx context.Context) (*Credential, bool) {
+	credential, ok := ctx.Value(contextKey{}).(*Credential)
+	return credential, ok
+}
+
+// Require checks that the credential of ctx grants scope. Work without a credential, such as the
+// sweeper or a deployment not authenticating requests, isn't restricted.
+func Require(ctx context.Context, scope Scope) error {
+	credential, ok := FromContext(ctx)
+	if !ok || credential.HasScope(scope) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires %s", errors.ErrInsufficientScope, credential.Name, scope)
+}
+
+// OwnerHeader names the owner whose idempotency keys a request uses in place of its credential's.
+// Middleware honours it for admin credentials only, so a journal replay keeps the keys of the
+// callers that made the original requests.
+const OwnerHeader = "X-Idempotency-Owner"
+
+type ownerKey struct{}
+
+// withOwner returns a copy of ctx whose idempotency keys belong to owner
+func withOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// Owner returns who the idempotency keys used under ctx belong to, so two callers picking the same
+// key don't share it: the ID of the credential, the name of a token caller's credential, which has
+// no ID, the owner given in OwnerHeader to an admin, or "" for work without a credential.
+func Owner(ctx context.Context) string {
+	if owner, ok := ctx.Value(ownerKey{}).(string); ok {
+		return owner
+	}
+	credential, ok := FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if credential.ID == 0 {
+		return credential.Name
+	}
+	return strconv.FormatInt(credential.ID, 10)
+}
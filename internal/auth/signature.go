@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// Headers of a signed request
+const (
+	HeaderSignatureCredential = "X-Signature-Credential" // ID of the credential whose secret signed the request
+	HeaderSignatureTimestamp  = "X-Signature-Timestamp"  // when the request was signed, in Unix seconds
+	// HeaderSignature is hex(HMAC-SHA256(secret, StringToSign(...)))
+	HeaderSignature = "X-Signature"
+)
+
+// maxSignedBody is the largest body read to verify a signature; the body is read before the
+// caller is known, so it is capped
+const maxSignedBody = 10 << 20
+
+// StringToSign returns what a request's signature covers: its method, its path with the query
+// string, its timestamp and the hex SHA-256 of its body, one per line
+func StringToSign(method, requestURI, timestamp string, body []byte) string {
+	digest := sha256.Sum256(body)
+	return method + "\n" + requestURI + "\n" + timestamp + "\n" + hex.EncodeToString(digest[:])
+}
+
+// Sign returns the signature of a request by secret, as a caller sends it in HeaderSignature
+func Sign(secret, method, requestURI, timestamp string, body []byte) string {
+	return hex.EncodeToString(sign(secret, method, requestURI, timestamp, body))
+}
+
+func sign(secret, method, requestURI, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(StringToSign(method, requestURI, timestamp, body)))
+	return mac.Sum(nil)
+}
+
+// SignatureVerifier authenticates server-to-server callers by requests signed with the signing
+// secret of their credential, for callers that can't use OIDC and shouldn't send a key with every
+// request. A signature is accepted within window of its timestamp and only once: the signatures
+// seen are remembered in memory until they fall out of the window. Like the rate limiter's
+// buckets they are per process, so behind a load balancer a replay to another instance within
+// the window isn't caught.
+type SignatureVerifier struct {
+	store  Store
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	seen      map[string]time.Time // signatures accepted, with their timestamp
+	lastPrune time.Time
+	log       *slog.Logger
+}
+
+// NewSignatureVerifier creates a verifier of the requests signed with the secrets in store,
+// accepting timestamps at most window from now
+func NewSignatureVerifier(store Store, window time.Duration) (*SignatureVerifier, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: the signature window must be positive", errors.ErrValidationFailed)
+	}
+	return &SignatureVerifier{store: store, window: window, now: time.Now, seen: make(map[string]time.Time), log: logger.Default()}, nil
+}
+
+// Signed reports whether r carries any of the signature headers
+func Signed(r *http.Request) bool {
+	return r.Header.Get(HeaderSignature) != "" || r.Header.Get(HeaderSignatureCredential) != "" ||
+		r.Header.Get(HeaderSignatureTimestamp) != ""
+}
+
+// Verify returns the credential whose secret signed r, or ErrUnauthenticated if a signature
+// header is missing, the credential is unknown, revoked or has no secret, the timestamp is
+// outside the window, the signature doesn't match or was already used. It reads the body of r
+// and replaces it with a copy, so handlers can still read it.
+func (v *SignatureVerifier) Verify(r *http.Request) (*Credential, error) {
+	ctx := r.Context()
+	signature, err := hex.DecodeString(r.Header.Get(HeaderSignature))
+	if err != nil || len(signature) != sha256.Size {
+		return nil, v.rejected(ctx, "malformed header", "header", HeaderSignature)
+	}
+	credentialID, err := strconv.ParseInt(r.Header.Get(HeaderSignatureCredential), 10, 64)
+	if err != nil {
+		return nil, v.rejected(ctx, "malformed header", "header", HeaderSignatureCredential)
+	}
+	timestamp := r.Header.Get(HeaderSignatureTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, v.rejected(ctx, "malformed header", "header", HeaderSignatureTimestamp)
+	}
+	signedAt := time.Unix(unix, 0)
+	now := v.now()
+	if skew := now.Sub(signedAt); skew > v.window || skew < -v.window {
+		return nil, v.rejected(ctx, "signed outside the window", "credential_id", credentialID, "signed_at", signedAt.UTC().Format(time.RFC3339))
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > maxSignedBody {
+			return nil, v.rejected(ctx, "body too large", "credential_id", credentialID, "max_bytes", maxSignedBody)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	credential, secret, err := v.store.GetSigningSecret(ctx, credentialID)
+	if err == sql.ErrNoRows {
+		return nil, v.rejected(ctx, "credential is unknown, revoked or has no signing secret", "credential_id", credentialID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(signature, sign(secret, r.Method, r.URL.RequestURI(), timestamp, body)) {
+		return nil, v.rejected(ctx, "signature doesn't match", "credential_id", credentialID)
+	}
+	if !v.firstUse(credentialID, signature, signedAt, now) {
+		return nil, v.rejected(ctx, "signature replayed", "credential_id", credentialID)
+	}
+	return credential, nil
+}
+
+// firstUse records a signature as used and reports whether it wasn't already
+func (v *SignatureVerifier) firstUse(credentialID int64, signature []byte, signedAt, now time.Time) bool {
+	key := strconv.FormatInt(credentialID, 10) + ":" + hex.EncodeToString(signature)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.pruneLocked(now)
+	if _, ok := v.seen[key]; ok {
+		return false
+	}
+	v.seen[key] = signedAt
+	return true
+}
+
+// pruneLocked forgets the signatures whose timestamp has left the window, which would be rejected
+// anyway, scanning them at most once a minute
+func (v *SignatureVerifier) pruneLocked(now time.Time) {
+	if now.Sub(v.lastPrune) < time.Minute {
+		return
+	}
+	v.lastPrune = now
+	for key, signedAt := range v.seen {
+		if now.Sub(signedAt) > v.window {
+			delete(v.seen, key)
+		}
+	}
+}
+
+// rejected logs why a signature was rejected and returns ErrUnauthenticated, which doesn't tell
+// the caller
+func (v *SignatureVerifier) rejected(ctx context.Context, reason string, args ...interface{}) error {
+	v.log.WarnContext(ctx, "Rejected request signature", append([]interface{}{"reason", reason}, args...)...)
+	return errors.ErrUnauthenticated
+}
+
+// SignatureMiddleware authenticates signed requests by v and passes the credential on in the
+// request context, where Middleware accepts it in place of a bearer token. An invalid signature
+// is rejected with 401; unsigned requests pass through to be authenticated by Middleware, so list
+// it before auth.
+func SignatureMiddleware(v *SignatureVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Signed(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			credential, err := v.Verify(r)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithCredential(r.Context(), credential)))
+		})
+	}
+}
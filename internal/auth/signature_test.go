@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedRequest(credentialID int64, secret, method, target, body string, at time.Time) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set(HeaderSignatureCredential, strconv.FormatInt(credentialID, 10))
+	req.Header.Set(HeaderSignatureTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, method, req.URL.RequestURI(), timestamp, []byte(body)))
+	return req
+}
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	manager := NewManager(store)
+	credential, _, err := manager.Issue(ctx, "settlement", nil, []Role{RoleClient})
+	require.NoError(t, err)
+	assert.False(t, credential.Signing)
+	credential, secret, err := manager.IssueSigningSecret(ctx, credential.ID)
+	require.NoError(t, err)
+	assert.True(t, credential.Signing)
+	assert.True(t, strings.HasPrefix(secret, signingSecretPrefix))
+	unsigned, _, err := manager.Issue(ctx, "payroll", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+
+	_, err = NewSignatureVerifier(store, 0)
+	assert.ErrorIs(t, err, domainErrors.ErrValidationFailed)
+	v, err := NewSignatureVerifier(store, 5*time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	body := `{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00"}`
+	req := signedRequest(credential.ID, secret, http.MethodPost, "/transactions?dry_run=true", body, now.Add(-time.Minute))
+	authenticated, err := v.Verify(req)
+	require.NoError(t, err)
+	assert.Equal(t, "settlement", authenticated.Name)
+	read, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(read), "the body is still readable")
+
+	_, err = v.Verify(signedRequest(credential.ID, secret, http.MethodPost, "/transactions?dry_run=true", body, now.Add(-time.Minute)))
+	assert.ErrorIs(t, err, domainErrors.ErrUnauthenticated, "replayed")
+
+	tampered := func(mutate func(*http.Request)) *http.Request {
+		req := signedRequest(credential.ID, secret, http.MethodPost, "/transactions", body, now)
+		mutate(req)
+		return req
+	}
+	invalid := map[string]*http.Request{
+		"too old":        signedRequest(credential.ID, secret, http.MethodPost, "/transactions", body, now.Add(-6*time.Minute)),
+		"too new":        signedRequest(credential.ID, secret, http.MethodPost, "/transactions", body, now.Add(6*time.Minute)),
+		"wrong secret":   signedRequest(credential.ID, "its_other", http.MethodPost, "/transactions", body, now),
+		"no secret":      signedRequest(unsigned.ID, secret, http.MethodPost, "/transactions", body, now),
+		"unknown":        signedRequest(42, secret, http.MethodPost, "/transactions", body, now),
+		"other body":     tampered(func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"amount": "9999.00"}`)) }),
+		"other method":   tampered(func(r *http.Request) { r.Method = http.MethodPut }),
+		"other path":     tampered(func(r *http.Request) { r.URL.Path = "/transactions/async" }),
+		"other query":    tampered(func(r *http.Request) { r.URL.RawQuery = "dry_run=true" }),
+		"other time":     tampered(func(r *http.Request) { r.Header.Set(HeaderSignatureTimestamp, strconv.FormatInt(now.Unix()-1, 10)) }),
+		"no signature":   tampered(func(r *http.Request) { r.Header.Del(HeaderSignature) }),
+		"no credential":  tampered(func(r *http.Request) { r.Header.Del(HeaderSignatureCredential) }),
+		"no timestamp":   tampered(func(r *http.Request) { r.Header.Del(HeaderSignatureTimestamp) }),
+		"signature text": tampered(func(r *http.Request) { r.Header.Set(HeaderSignature, "not hex") }),
+	}
+	for name, req := range invalid {
+		_, err := v.Verify(req)
+		assert.ErrorIs(t, err, domainErrors.ErrUnauthenticated, name)
+	}
+
+	_, err = manager.Revoke(ctx, credential.ID)
+	require.NoError(t, err)
+	_, err = v.Verify(signedRequest(credential.ID, secret, http.MethodPost, "/transactions", body, now))
+	assert.ErrorIs(t, err, domainErrors.ErrUnauthenticated, "revoked")
+	_, _, err = manager.IssueSigningSecret(ctx, credential.ID)
+	assert.ErrorIs(t, err, domainErrors.ErrCredentialNotFound)
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	manager := NewManager(store)
+	credential, key, err := manager.Issue(ctx, "settlement", []Scope{ScopeTransfersCreate}, nil)
+	require.NoError(t, err)
+	_, secret, err := manager.IssueSigningSecret(ctx, credential.ID)
+	require.NoError(t, err)
+	v, err := NewSignatureVerifier(store, 5*time.Minute)
+	require.NoError(t, err)
+
+	var seenActor string
+	handler := SignatureMiddleware(v)(Middleware(manager, Routes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenActor = actor.FromContext(r.Context())
+	})))
+	serve := func(req *http.Request) int {
+		seenActor = ""
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(signedRequest(credential.ID, secret, http.MethodPost, "/transactions", "{}", time.Now())))
+	assert.Equal(t, "settlement", seenActor)
+	assert.Equal(t, http.StatusForbidden, serve(signedRequest(credential.ID, secret, http.MethodGet, "/admin/workers", "", time.Now())))
+	assert.Equal(t, http.StatusUnauthorized, serve(signedRequest(credential.ID, "its_other", http.MethodPost, "/transactions", "{}", time.Now())))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer "+key)
+	assert.Equal(t, http.StatusOK, serve(req), "unsigned requests fall back to the bearer token")
+	assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest(http.MethodPost, "/transactions", nil)))
+}
@@ -0,0 +1,148 @@
+// Package blob stores generated artifacts, such as archived exports, in a durable location they
+// can be referenced by: a local directory, an S3 bucket or a GCS bucket
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Store keeps artifacts under slash-separated keys such as "exports/transactions/account-1/x.ndjson"
+type Store interface {
+	// Put stores body under key, replacing any artifact already there. Readers never see a
+	// partially written artifact.
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+
+	// Get opens the artifact under key; it fails with ErrArtifactNotFound if there is none. The
+	// caller must close its body.
+	Get(ctx context.Context, key string) (*Object, error)
+
+	// URI returns where the artifact under key is kept, e.g. s3://bucket/prefix/key
+	URI(key string) string
+}
+
+// Object is an artifact read from a Store
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64 // -1 if unknown
+}
+
+// Backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+)
+
+// Config selects and configures a Store
+type Config struct {
+	Backend  string // BackendLocal, BackendS3 or BackendGCS; empty disables artifact storage
+	Dir      string // root directory of the local backend
+	Bucket   string
+	Prefix   string // prepended to every key in the bucket
+	Endpoint string // overrides the S3 or GCS endpoint, e.g. for MinIO or an emulator
+	Timeout  time.Duration
+
+	// S3
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// GCS; without a static token one is fetched from the metadata server
+	AccessToken string
+}
+
+// Open creates the Store selected by cfg, or returns nil if none is configured
+func Open(cfg Config) (Store, error) {
+	if cfg.Backend != BackendLocal && cfg.Backend != "" && cfg.Bucket == "" {
+		return nil, fmt.Errorf("%w: the %s artifact store needs a bucket", errors.ErrValidationFailed, cfg.Backend)
+	}
+	if cfg.Prefix != "" {
+		if err := ValidateKey(cfg.Prefix); err != nil {
+			return nil, err
+		}
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case BackendLocal:
+		return NewLocalStore(cfg.Dir)
+	case BackendS3:
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("%w: the s3 artifact store needs an access key", errors.ErrValidationFailed)
+		}
+		return NewS3Store(client, cfg), nil
+	case BackendGCS:
+		return NewGCSStore(client, cfg), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown artifact store %q", errors.ErrValidationFailed, cfg.Backend)
+	}
+}
+
+// MaxKeyLength is the longest key an artifact can be stored under
+const MaxKeyLength = 512
+
+// ValidateKey checks key is a relative slash-separated path of letters, digits, '.', '_' and
+// '-', which every backend stores verbatim and which can't escape the local root directory
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return fmt.Errorf("%w: artifact key must be 1-%d characters", errors.ErrValidationFailed, MaxKeyLength)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: invalid artifact key %q", errors.ErrValidationFailed, key)
+		}
+	}
+	for _, c := range key {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '.' && c != '_' && c != '-' && c != '/' {
+			return fmt.Errorf("%w: artifact key may only contain a-z, A-Z, 0-9, '.', '_', '-' and '/'", errors.ErrValidationFailed)
+		}
+	}
+	return nil
+}
+
+// ContentType returns the content type of an artifact by the extension of its key
+func ContentType(key string) string {
+	switch path.Ext(key) {
+	case ".ndjson":
+		return "application/x-ndjson"
+	case ".csv":
+		return "text/csv"
+	case ".json":
+		return "application/json"
+	case ".pdf":
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// objectName returns the name of the object holding key in a bucket with prefix
+func objectName(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + key
+}
+
+// notFound returns ErrArtifactNotFound for key
+func notFound(key string) error {
+	return fmt.Errorf("%w: %s", errors.ErrArtifactNotFound, key)
+}
+
+// statusError describes a failed request to a storage service, keeping the start of its body
+func statusError(service, op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s %s of %s failed: %s: %s", service, op, key, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,145 @@
+package blob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcsMetadataTokenURL serves access tokens of the service account of a GCE, GKE or Cloud Run workload
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCSStore keeps artifacts in a Google Cloud Storage bucket through its JSON API
+type GCSStore struct {
+	client   *http.Client
+	endpoint string
+	bucket   string
+	prefix   string
+
+	staticToken string
+	tokenURL    string
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCSStore creates a store for the bucket of cfg. Requests are authorized with the static
+// access token of cfg if set, otherwise with tokens of the workload's service account.
+func NewGCSStore(client *http.Client, cfg Config) *GCSStore {
+	endpoint := "https://storage.googleapis.com"
+	if cfg.Endpoint != "" {
+		endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	}
+	return &GCSStore{
+		client:      client,
+		endpoint:    endpoint,
+		bucket:      cfg.Bucket,
+		prefix:      cfg.Prefix,
+		staticToken: cfg.AccessToken,
+		tokenURL:    gcsMetadataTokenURL,
+	}
+}
+
+// Put uploads the artifact in one media upload; GCS makes it visible only once complete
+func (s *GCSStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		s.endpoint, url.PathEscape(s.bucket), url.QueryEscape(objectName(s.prefix, key)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if err := s.authorize(ctx, req); err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gcs put of %s failed: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError("gcs", "put", key, resp)
+	}
+	return nil
+}
+
+// Get downloads the artifact
+func (s *GCSStore) Get(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	objectURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+		s.endpoint, url.PathEscape(s.bucket), url.PathEscape(objectName(s.prefix, key)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gcs get of %s failed: %w", key, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, notFound(key)
+		}
+		return nil, statusError("gcs", "get", key, resp)
+	}
+	return &Object{Body: resp.Body, ContentType: resp.Header.Get("Content-Type"), Size: resp.ContentLength}, nil
+}
+
+// URI returns the gs:// URI of the artifact
+func (s *GCSStore) URI(key string) string {
+	return "gs://" + s.bucket + "/" + objectName(s.prefix, key)
+}
+
+// authorize sets the bearer token of req, fetching a new one from the metadata server when the
+// cached one is about to expire
+func (s *GCSStore) authorize(ctx context.Context, req *http.Request) error {
+	if s.staticToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.staticToken)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || time.Until(s.tokenExpiry) < time.Minute {
+		tokenReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.tokenURL, nil)
+		if err != nil {
+			return err
+		}
+		tokenReq.Header.Set("Metadata-Flavor", "Google")
+		resp, err := s.client.Do(tokenReq)
+		if err != nil {
+			return fmt.Errorf("failed to fetch gcs access token: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return statusError("gcs", "token fetch", "service account", resp)
+		}
+		var token struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+			return fmt.Errorf("invalid gcs access token response: %v", err)
+		}
+		s.token, s.tokenExpiry = token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn)*time.Second)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	return nil
+}
@@ -0,0 +1,79 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCSStore_PutGet(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string]string{}
+	tokenFetches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		tokenFetches++
+		io.WriteString(w, `{"access_token": "ya29.token", "expires_in": 3600, "token_type": "Bearer"}`)
+	})
+	authorized := func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer ya29.token" }
+	mux.HandleFunc("POST /upload/storage/v1/b/artifacts/o", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) || r.URL.Query().Get("uploadType") != "media" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		objects[r.URL.Query().Get("name")] = string(body)
+		mu.Unlock()
+		io.WriteString(w, `{}`)
+	})
+	mux.HandleFunc("GET /storage/v1/b/artifacts/o/{name}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body, ok := objects[r.PathValue("name")]
+		mu.Unlock()
+		if !authorized(r) || r.URL.Query().Get("alt") != "media" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		io.WriteString(w, body)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewGCSStore(&http.Client{Timeout: time.Second}, Config{Endpoint: server.URL, Bucket: "artifacts", Prefix: "ledger"})
+	store.tokenURL = server.URL + "/token"
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "exports/a.csv", strings.NewReader("id\n1\n"), "text/csv"))
+	assert.Equal(t, "id\n1\n", objects["ledger/exports/a.csv"])
+	assert.Equal(t, "gs://artifacts/ledger/exports/a.csv", store.URI("exports/a.csv"))
+
+	object, err := store.Get(ctx, "exports/a.csv")
+	require.NoError(t, err)
+	defer object.Body.Close()
+	body, err := io.ReadAll(object.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n", string(body))
+	assert.Equal(t, "text/csv", object.ContentType)
+
+	_, err = store.Get(ctx, "exports/missing.csv")
+	assert.ErrorIs(t, err, errors.ErrArtifactNotFound)
+
+	// The service account token is cached until it is about to expire
+	assert.Equal(t, 1, tokenFetches)
+}
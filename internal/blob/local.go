@@ -0,0 +1,100 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// LocalStore keeps artifacts as files under a root directory
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a store rooted at dir, creating the directory if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: the local artifact store needs a directory", errors.ErrValidationFailed)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes body to a temporary file next to the artifact and renames it into place
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory of artifact %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create artifact %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write artifact %s: %w", key, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write artifact %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write artifact %s: %w", key, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store artifact %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens the file of the artifact
+func (s *LocalStore) Get(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, notFound(key)
+		}
+		return nil, fmt.Errorf("failed to open artifact %s: %w", key, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open artifact %s: %w", key, err)
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, notFound(key)
+	}
+	return &Object{Body: f, ContentType: ContentType(key), Size: info.Size()}, nil
+}
+
+// URI returns the file URI of the artifact
+func (s *LocalStore) URI(key string) string {
+	return "file://" + filepath.ToSlash(s.path(key))
+}
+
+// path returns the file of the artifact under key
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
@@ -0,0 +1,71 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"a", "exports/transactions/account-1/20240301T000000Z-1-9.ndjson", "statements/2024_03.PDF"} {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+	for _, key := range []string{"", "/abs", "a//b", "a/", "../etc/passwd", "a/./b", "a b", "a?b", "a\\b", strings.Repeat("a", MaxKeyLength+1)} {
+		assert.ErrorIs(t, ValidateKey(key), errors.ErrValidationFailed, key)
+	}
+}
+
+func TestLocalStore_PutGet(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(Config{Backend: BackendLocal, Dir: dir})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "exports/a.ndjson", strings.NewReader("first\n"), "application/x-ndjson"))
+	require.NoError(t, store.Put(ctx, "exports/a.ndjson", strings.NewReader("second\n"), "application/x-ndjson"))
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(dir, "exports", "a.ndjson")), store.URI("exports/a.ndjson"))
+
+	object, err := store.Get(ctx, "exports/a.ndjson")
+	require.NoError(t, err)
+	defer object.Body.Close()
+	body, err := io.ReadAll(object.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(body))
+	assert.Equal(t, "application/x-ndjson", object.ContentType)
+	assert.Equal(t, int64(7), object.Size)
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(filepath.Join(dir, "exports"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = store.Get(ctx, "exports")
+	assert.ErrorIs(t, err, errors.ErrArtifactNotFound)
+	_, err = store.Get(ctx, "exports/missing.ndjson")
+	assert.ErrorIs(t, err, errors.ErrArtifactNotFound)
+	assert.ErrorIs(t, store.Put(ctx, "../escape", strings.NewReader(""), "text/plain"), errors.ErrValidationFailed)
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, store)
+
+	for _, cfg := range []Config{
+		{Backend: "ftp"},
+		{Backend: BackendLocal},
+		{Backend: BackendS3, AccessKeyID: "key", SecretAccessKey: "secret"},
+		{Backend: BackendS3, Bucket: "artifacts"},
+		{Backend: BackendGCS, Bucket: "artifacts", Prefix: "../up"},
+	} {
+		_, err := Open(cfg)
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, "%+v", cfg)
+	}
+}
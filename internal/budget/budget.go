@@ -0,0 +1,95 @@
+// Package budget gives every request a latency budget, the time it may take before its caller
+// gives up on it, and carries what is left of it to the work the request does. Repository and
+// external calls made with the request context stop at its deadline instead of running on for a
+// caller that has gone; optional work, such as publishing events directly or refreshing a cache
+// entry, is skipped once too little of the budget is left.
+package budget
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// Header lets a caller pass down what is left of its own budget, in milliseconds, so the request
+// gives up when its caller does; the server's limit still applies
+const Header = "X-Request-Budget-Ms"
+
+// Kinds of optional work skipped on a nearly exhausted budget
+const (
+	WorkEventPublish = "event_publish"
+	WorkCacheRefresh = "cache_refresh"
+)
+
+// budget is the deadline of a request, kept as a context value so it is still known in contexts
+// detached from the request's cancellation
+type budget struct {
+	deadline time.Time
+	reserve  time.Duration
+	metrics  *metrics.Budgets
+}
+
+type contextKey struct{}
+
+// Middleware gives each request a deadline limit from its start, or sooner if its Header asks
+// for less. Optional work is skipped once less than reserve is left. Every request is counted in
+// m, as exceeded if it ran past its deadline.
+func Middleware(limit, reserve time.Duration, m *metrics.Budgets) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed := limit
+			if v := r.Header.Get(Header); v != "" {
+				if ms, err := strconv.ParseInt(v, 10, 64); err == nil && ms > 0 && time.Duration(ms)*time.Millisecond < allowed {
+					allowed = time.Duration(ms) * time.Millisecond
+				}
+			}
+			b := &budget{deadline: time.Now().Add(allowed), reserve: reserve, metrics: m}
+			ctx, cancel := context.WithDeadline(context.WithValue(r.Context(), contextKey{}, b), b.deadline)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+
+			exceeded := ctx.Err() == context.DeadlineExceeded
+			if exceeded {
+				logger.Default().WarnContext(r.Context(), "Request ran past its latency budget", "method", r.Method, "path", r.URL.Path, "allowed", allowed)
+			}
+			m.Request(exceeded)
+		})
+	}
+}
+
+// Remaining returns the time left of the budget of the request ctx belongs to, and false if it
+// has no budget. It is known in contexts detached with context.WithoutCancel too.
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	b, ok := ctx.Value(contextKey{}).(*budget)
+	if !ok {
+		return 0, false
+	}
+	return time.Until(b.deadline), true
+}
+
+// Allows reports whether enough of the budget of ctx is left for optional work of a kind, which
+// is always the case without a budget. Work that isn't allowed is counted as skipped.
+func Allows(ctx context.Context, work string) bool {
+	b, ok := ctx.Value(contextKey{}).(*budget)
+	if !ok || time.Until(b.deadline) >= b.reserve {
+		return true
+	}
+	logger.Default().WarnContext(ctx, "Skipping work, too little of the latency budget is left", "work", work, "left", time.Until(b.deadline).Round(time.Millisecond))
+	b.metrics.Skip(work)
+	return false
+}
+
+// Bound returns a copy of ctx that ends at the deadline of its request's budget, for work done
+// in a context detached from the request. Without a budget, ctx is only made cancelable.
+func Bound(ctx context.Context) (context.Context, context.CancelFunc) {
+	b, ok := ctx.Value(contextKey{}).(*budget)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, b.deadline)
+}
@@ -0,0 +1,82 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	m := metrics.NewBudgets()
+	var remaining time.Duration
+	var deadline bool
+	handler := Middleware(time.Second, 10*time.Millisecond, m)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining, _ = Remaining(r.Context())
+		_, deadline = r.Context().Deadline()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/1", nil))
+	assert.True(t, deadline)
+	assert.InDelta(t, time.Second, remaining, float64(100*time.Millisecond))
+
+	// A caller can ask for less than the limit, but not for more
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	req.Header.Set(Header, "200")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.InDelta(t, 200*time.Millisecond, remaining, float64(100*time.Millisecond))
+
+	req.Header.Set(Header, "60000")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.InDelta(t, time.Second, remaining, float64(100*time.Millisecond))
+
+	assert.Equal(t, uint64(3), m.Stats().Requests)
+	assert.Zero(t, m.Stats().Exceeded)
+}
+
+func TestMiddleware_Exceeded(t *testing.T) {
+	m := metrics.NewBudgets()
+	handler := Middleware(time.Millisecond, 0, m)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transactions", nil))
+	assert.Equal(t, uint64(1), m.Stats().Exceeded)
+}
+
+func TestAllows(t *testing.T) {
+	assert.True(t, Allows(context.Background(), WorkEventPublish), "no budget")
+
+	m := metrics.NewBudgets()
+	var allowed, detachedAllowed bool
+	var bounded bool
+	handler := Middleware(50*time.Millisecond, time.Second, m)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed = Allows(r.Context(), WorkCacheRefresh)
+
+		// The budget is still known once detached from the request
+		detached := context.WithoutCancel(r.Context())
+		detachedAllowed = Allows(detached, WorkEventPublish)
+		ctx, cancel := Bound(detached)
+		defer cancel()
+		_, bounded = ctx.Deadline()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transactions", nil))
+
+	assert.False(t, allowed)
+	assert.False(t, detachedAllowed)
+	assert.True(t, bounded)
+	assert.Equal(t, map[string]uint64{WorkCacheRefresh: 1, WorkEventPublish: 1}, m.Stats().Skipped)
+}
+
+func TestBound_NoBudget(t *testing.T) {
+	ctx, cancel := Bound(context.Background())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+	require.Error(t, ctx.Err())
+}
@@ -0,0 +1,55 @@
+// Package buildinfo identifies the running build. The release pipeline injects the version, git
+// commit and build time with the linker, e.g.
+//
+//	go build -ldflags "-X github.com/khamiruf/internal_transfers_system_go/internal/buildinfo.Version=v1.4.0
+//	  -X github.com/khamiruf/internal_transfers_system_go/internal/buildinfo.Commit=$(git rev-parse HEAD)
+//	  -X github.com/khamiruf/internal_transfers_system_go/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without them fall back to the version control details the go command stamps into
+// binaries built from a checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags "-X"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	// Modified is set when the build was made from a checkout with uncommitted changes
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build info, taking the commit and build time the linker didn't set from the
+// version control stamp of the binary, if there is one
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
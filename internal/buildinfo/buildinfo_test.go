@@ -0,0 +1,20 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	saved := [3]string{Version, Commit, BuildTime}
+	defer func() { Version, Commit, BuildTime = saved[0], saved[1], saved[2] }()
+	Version, Commit, BuildTime = "v1.4.0", "0123abc", "2026-10-16T12:00:00Z"
+
+	info := Get()
+	assert.Equal(t, "v1.4.0", info.Version)
+	assert.Equal(t, "0123abc", info.Commit)
+	assert.Equal(t, "2026-10-16T12:00:00Z", info.BuildTime)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+}
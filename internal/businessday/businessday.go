@@ -0,0 +1,56 @@
+// Package businessday assigns business dates to instants. A business date is the trading day an
+// instant belongs to in the configured timezone: instants at or after the daily cutoff belong to
+// the next day, so a transfer made at 18:30 with a 17:00 cutoff is booked on tomorrow's date.
+package businessday
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of a business date
+const DateLayout = "2006-01-02"
+
+// Calendar derives business dates from a timezone and a daily cutoff
+type Calendar struct {
+	location *time.Location
+	cutoff   time.Duration // offset of the cutoff from local midnight, at most 24h
+}
+
+// NewCalendar creates a calendar for an IANA timezone and a cutoff time of day ("HH:MM").
+// A cutoff of "24:00" makes business dates follow calendar dates.
+func NewCalendar(timezone, cutoff string) (*Calendar, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business day timezone %q: %w", timezone, err)
+	}
+	var hours, minutes int
+	if _, err := fmt.Sscanf(cutoff, "%d:%d", &hours, &minutes); err != nil ||
+		hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return nil, fmt.Errorf("invalid business day cutoff %q, expected HH:MM", cutoff)
+	}
+	return &Calendar{
+		location: location,
+		cutoff:   time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute,
+	}, nil
+}
+
+// UTC returns a calendar whose business dates are UTC calendar dates
+func UTC() *Calendar {
+	return &Calendar{location: time.UTC, cutoff: 24 * time.Hour}
+}
+
+// Date returns the business date t belongs to
+func (c *Calendar) Date(t time.Time) string {
+	local := t.In(c.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
+	if local.Sub(midnight) >= c.cutoff {
+		midnight = midnight.AddDate(0, 0, 1)
+	}
+	return midnight.Format(DateLayout)
+}
+
+// ParseDate parses a business date
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse(DateLayout, s)
+}
@@ -0,0 +1,56 @@
+package businessday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendar_Date(t *testing.T) {
+	singapore, err := NewCalendar("Asia/Singapore", "17:00")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		calendar *Calendar
+		at       string
+		expected string
+	}{
+		{"utc calendar day", UTC(), "2024-03-01T23:59:59Z", "2024-03-01"},
+		{"utc midnight", UTC(), "2024-03-02T00:00:00Z", "2024-03-02"},
+		{"before cutoff", singapore, "2024-03-01T08:59:59Z", "2024-03-01"}, // 16:59:59 local
+		{"at cutoff", singapore, "2024-03-01T09:00:00Z", "2024-03-02"},     // 17:00 local
+		{"local date differs from utc", singapore, "2024-02-29T20:00:00Z", "2024-03-01"},
+		{"rolls over month end", singapore, "2024-02-29T12:00:00Z", "2024-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.calendar.Date(at))
+		})
+	}
+}
+
+func TestNewCalendar_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		cutoff   string
+	}{
+		{"unknown timezone", "Mars/Olympus", "17:00"},
+		{"malformed cutoff", "UTC", "5pm"},
+		{"cutoff past midnight", "UTC", "24:30"},
+		{"minutes out of range", "UTC", "17:75"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCalendar(tt.timezone, tt.cutoff)
+			assert.Error(t, err)
+		})
+	}
+}
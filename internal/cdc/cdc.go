@@ -0,0 +1,284 @@
+// Package cdc exports row changes on accounts and transactions from the Postgres logical
+// replication stream, so downstream consumers get an ordered change feed without any work
+// being added to the transfer path.
+package cdc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// outputPlugin is the logical decoding plugin used for the slot. test_decoding ships with
+// Postgres, so no server extension has to be installed.
+const outputPlugin = "test_decoding"
+
+// exportedTables are the tables whose changes are forwarded to the sink
+var exportedTables = map[string]bool{
+	"accounts":     true,
+	"transactions": true,
+}
+
+// Operation is the kind of row change
+type Operation string
+
+const (
+	OperationInsert Operation = "INSERT"
+	OperationUpdate Operation = "UPDATE"
+	OperationDelete Operation = "DELETE"
+)
+
+// Change is a single decoded row change
+type Change struct {
+	// LSN is the log sequence number of the change, unique and increasing across the feed
+	LSN string
+	// XID is the ID of the database transaction that made the change
+	XID int64
+	// Table is the unqualified table name
+	Table string
+	// Operation is the kind of change
+	Operation Operation
+	// Columns holds the new row values (key columns only for deletes); nil means NULL
+	Columns map[string]*string
+}
+
+// Sink receives changes in commit order.
+// A batch is acknowledged on the replication slot only after Publish returns nil, so a failing
+// sink is handed the same changes again; sinks deduplicate on LSN to get exactly-once delivery.
+type Sink interface {
+	Publish(ctx context.Context, changes []*Change) error
+}
+
+// LogSink is a Sink that writes every change to the application log
+type LogSink struct{}
+
+// Publish logs each change
+func (LogSink) Publish(ctx context.Context, changes []*Change) error {
+	for _, change := range changes {
+		logger.Default().Info("CDC change", "lsn", change.LSN, "xid", change.XID, "table", change.Table, "operation", change.Operation)
+	}
+	return nil
+}
+
+// Publisher publishes events to an event bus; events.Publisher fits it
+type Publisher interface {
+	Publish(ctx context.Context, event string, payload interface{}) error
+}
+
+// ChangeEvent is the payload a PublisherSink publishes for a change. AccountID is the account the
+// row belongs to (the source account of a transaction), so the Kafka publisher keys an account's
+// changes to one partition and keeps them in order there.
+type ChangeEvent struct {
+	LSN       string             `json:"lsn"`
+	XID       int64              `json:"xid"`
+	Table     string             `json:"table"`
+	Operation Operation          `json:"operation"`
+	AccountID *int64             `json:"account_id,omitempty"`
+	Columns   map[string]*string `json:"columns"`
+}
+
+// PublisherSink is a Sink that publishes every change to an event bus as the event
+// "cdc.<table>", e.g. cdc.transactions
+type PublisherSink struct {
+	publisher Publisher
+}
+
+// NewPublisherSink creates a sink publishing changes to publisher
+func NewPublisherSink(publisher Publisher) *PublisherSink {
+	return &PublisherSink{publisher: publisher}
+}
+
+// Publish publishes the changes one at a time, in the order given, and stops at the first that
+// fails, so no change is published ahead of one before it
+func (s *PublisherSink) Publish(ctx context.Context, changes []*Change) error {
+	for _, change := range changes {
+		event := ChangeEvent{
+			LSN:       change.LSN,
+			XID:       change.XID,
+			Table:     change.Table,
+			Operation: change.Operation,
+			AccountID: changeAccountID(change),
+			Columns:   change.Columns,
+		}
+		if err := s.publisher.Publish(ctx, "cdc."+change.Table, event); err != nil {
+			return fmt.Errorf("failed to publish change at %s: %w", change.LSN, err)
+		}
+	}
+	return nil
+}
+
+// changeAccountID returns the account a change belongs to, or nil if its row doesn't say
+func changeAccountID(change *Change) *int64 {
+	column := "account_id"
+	if change.Table == "transactions" {
+		column = "source_account_id"
+	}
+	v := change.Columns[column]
+	if v == nil {
+		return nil
+	}
+	accountID, err := strconv.ParseInt(*v, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &accountID
+}
+
+// Consumer polls a logical replication slot and forwards decoded changes to a Sink
+type Consumer struct {
+	db           *sql.DB
+	sink         Sink
+	slotName     string
+	pollInterval time.Duration
+	batchSize    int
+	log          *slog.Logger
+}
+
+// NewConsumer creates a new CDC consumer using the CDC settings in cfg
+func NewConsumer(db *sql.DB, sink Sink, cfg *config.Config) *Consumer {
+	return &Consumer{
+		db:           db,
+		sink:         sink,
+		slotName:     cfg.CDCSlotName,
+		pollInterval: time.Duration(cfg.CDCPollInterval) * time.Millisecond,
+		batchSize:    cfg.CDCBatchSize,
+		log:          logger.Default(),
+	}
+}
+
+// FromConfig creates the consumer configured by the CDC settings in cfg, or returns nil when
+// CDC_ENABLED is off. Changes go to publisher, the event bus selected by EVENT_BUS, or to the
+// log when there is none.
+func FromConfig(db *sql.DB, publisher Publisher, cfg *config.Config) *Consumer {
+	if !cfg.CDCEnabled {
+		return nil
+	}
+	var sink Sink = LogSink{}
+	if publisher != nil {
+		sink = NewPublisherSink(publisher)
+	}
+	return NewConsumer(db, sink, cfg)
+}
+
+// EnsureSlot creates the replication slot if it doesn't exist yet.
+// The server must run with wal_level=logical.
+func (c *Consumer) EnsureSlot(ctx context.Context) error {
+	var exists bool
+	err := c.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)`, c.slotName,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to look up replication slot: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	c.log.Info("Creating logical replication slot", "slot_name", c.slotName, "plugin", outputPlugin)
+	_, err = c.db.ExecContext(ctx, `SELECT pg_create_logical_replication_slot($1, $2)`, c.slotName, outputPlugin)
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot: %w", err)
+	}
+	return nil
+}
+
+// Run polls the slot until ctx is cancelled
+func (c *Consumer) Run(ctx context.Context) error {
+	if err := c.EnsureSlot(ctx); err != nil {
+		return err
+	}
+
+	c.log.Info("CDC consumer started", "slot", c.slotName, "poll_interval", c.pollInterval, "batch_size", c.batchSize)
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.log.Info("CDC consumer stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick
+			for {
+				n, err := c.poll(ctx)
+				if err != nil {
+					c.log.Error("CDC poll failed", "err", err)
+					break
+				}
+				if n < c.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// poll reads one batch from the slot without consuming it, publishes the relevant changes and
+// then advances the slot past the batch. It returns the number of records read.
+func (c *Consumer) poll(ctx context.Context) (int, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT lsn::text, xid::text::bigint, data
+		FROM pg_logical_slot_peek_changes($1, NULL, $2, 'skip-empty-xacts', '1')
+	`, c.slotName, c.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replication slot: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		records int
+		lastLSN string
+		changes []*Change
+	)
+	for rows.Next() {
+		var lsn, data string
+		var xid int64
+		if err := rows.Scan(&lsn, &xid, &data); err != nil {
+			return 0, fmt.Errorf("failed to scan change: %w", err)
+		}
+		records++
+		lastLSN = lsn
+
+		if !strings.HasPrefix(data, "table ") {
+			continue // BEGIN / COMMIT markers
+		}
+		change, err := decodeChange(data)
+		if err != nil {
+			return 0, err
+		}
+		if !exportedTables[change.Table] {
+			continue
+		}
+		change.LSN = lsn
+		change.XID = xid
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating changes: %w", err)
+	}
+	if records == 0 {
+		return 0, nil
+	}
+
+	if len(changes) > 0 {
+		if err := c.sink.Publish(ctx, changes); err != nil {
+			return 0, fmt.Errorf("failed to publish %d changes: %w", len(changes), err)
+		}
+	}
+
+	// peek_changes always returns whole transactions, so the last record is a COMMIT and
+	// advancing to it never splits a transaction across batches
+	if _, err := c.db.ExecContext(ctx, `SELECT pg_replication_slot_advance($1, $2::pg_lsn)`, c.slotName, lastLSN); err != nil {
+		return 0, fmt.Errorf("failed to advance replication slot: %w", err)
+	}
+
+	c.log.Debug("CDC batch processed", "records", records, "published", len(changes), "lsn", lastLSN)
+	return records, nil
+}
@@ -0,0 +1,78 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records the events it is handed and fails the one at failAt
+type recordingPublisher struct {
+	events   []string
+	payloads []ChangeEvent
+	failAt   int
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event string, payload interface{}) error {
+	if len(p.events) == p.failAt {
+		return errors.New("bus unavailable")
+	}
+	p.events = append(p.events, event)
+	p.payloads = append(p.payloads, payload.(ChangeEvent))
+	return nil
+}
+
+func TestPublisherSink(t *testing.T) {
+	var changes []*Change
+	for i, record := range []struct {
+		lsn  string
+		xid  int64
+		data string
+	}{
+		{"0/16B3748", 731, `table public.accounts: UPDATE: account_id[bigint]:1 balance[numeric]:90.00000`},
+		{"0/16B3790", 731, `table public.accounts: UPDATE: account_id[bigint]:2 balance[numeric]:10.00000`},
+		{"0/16B37D8", 731, `table public.transactions: INSERT: id[integer]:5 source_account_id[bigint]:1 destination_account_id[bigint]:2`},
+		{"0/16B3900", 732, `table public.transactions: UPDATE: id[integer]:5 source_account_id[bigint]:1 status[character varying]:'reversed'`},
+	} {
+		change, err := decodeChange(record.data)
+		require.NoError(t, err, i)
+		change.LSN = record.lsn
+		change.XID = record.xid
+		changes = append(changes, change)
+	}
+
+	// Changes reach the publisher in commit order, keyed by their account
+	publisher := &recordingPublisher{failAt: -1}
+	require.NoError(t, NewPublisherSink(publisher).Publish(context.Background(), changes))
+	assert.Equal(t, []string{"cdc.accounts", "cdc.accounts", "cdc.transactions", "cdc.transactions"}, publisher.events)
+	for i, payload := range publisher.payloads {
+		assert.Equal(t, changes[i].LSN, payload.LSN)
+		assert.Equal(t, changes[i].XID, payload.XID)
+		assert.Equal(t, changes[i].Operation, payload.Operation)
+	}
+	require.NotNil(t, publisher.payloads[1].AccountID)
+	assert.Equal(t, int64(2), *publisher.payloads[1].AccountID)
+	require.NotNil(t, publisher.payloads[2].AccountID)
+	assert.Equal(t, int64(1), *publisher.payloads[2].AccountID)
+
+	// A failure stops the batch, so no later change is published ahead of it
+	publisher = &recordingPublisher{failAt: 2}
+	err := NewPublisherSink(publisher).Publish(context.Background(), changes)
+	assert.ErrorContains(t, err, "0/16B37D8")
+	assert.Len(t, publisher.events, 2)
+}
+
+func TestFromConfig(t *testing.T) {
+	cfg := &config.Config{CDCSlotName: "transfers_cdc", CDCPollInterval: 1000, CDCBatchSize: 500}
+	assert.Nil(t, FromConfig(nil, &recordingPublisher{}, cfg))
+
+	cfg.CDCEnabled = true
+	consumer := FromConfig(nil, &recordingPublisher{}, cfg)
+	require.NotNil(t, consumer)
+	assert.IsType(t, &PublisherSink{}, consumer.sink)
+	assert.IsType(t, LogSink{}, FromConfig(nil, nil, cfg).sink)
+}
@@ -0,0 +1,101 @@
+package cdc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// decodeChange parses one row change emitted by the test_decoding output plugin, e.g.
+//
+//	table public.accounts: UPDATE: account_id[bigint]:1 balance[numeric]:90.00000
+//
+// BEGIN/COMMIT markers are not row changes and must be handled by the caller.
+func decodeChange(data string) (*Change, error) {
+	rest, ok := strings.CutPrefix(data, "table ")
+	if !ok {
+		return nil, fmt.Errorf("unexpected change record: %q", data)
+	}
+
+	table, rest, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return nil, fmt.Errorf("missing table name in change record: %q", data)
+	}
+	operation, rest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("missing operation in change record: %q", data)
+	}
+
+	change := &Change{
+		Table:     strings.TrimPrefix(table, "public."),
+		Operation: Operation(operation),
+		Columns:   make(map[string]*string),
+	}
+
+	columns, err := decodeColumns(strings.TrimSpace(rest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode columns of %s %s: %w", change.Operation, change.Table, err)
+	}
+	for _, column := range columns {
+		change.Columns[column.name] = column.value
+	}
+	return change, nil
+}
+
+type column struct {
+	name  string
+	value *string // nil for SQL NULL
+}
+
+// decodeColumns parses a sequence of name[type]:value pairs. String-like values are
+// single-quoted with embedded quotes doubled; everything else runs to the next space.
+func decodeColumns(s string) ([]column, error) {
+	var columns []column
+	for len(s) > 0 {
+		open := strings.IndexByte(s, '[')
+		if open < 0 {
+			// "(no-tuple-data)" and similar annotations carry no columns
+			return columns, nil
+		}
+		name := s[:open]
+
+		closing := strings.Index(s[open:], "]:")
+		if closing < 0 {
+			return nil, fmt.Errorf("missing type terminator for column %q", name)
+		}
+		s = s[open+closing+2:]
+
+		var value *string
+		switch {
+		case strings.HasPrefix(s, "'"):
+			var b strings.Builder
+			i := 1
+			for ; i < len(s); i++ {
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						b.WriteByte('\'')
+						i++
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated value for column %q", name)
+			}
+			v := b.String()
+			value = &v
+			s = s[i+1:]
+		default:
+			raw, remainder, _ := strings.Cut(s, " ")
+			if raw != "null" {
+				value = &raw
+			}
+			s = remainder
+		}
+
+		columns = append(columns, column{name: name, value: value})
+		s = strings.TrimLeft(s, " ")
+	}
+	return columns, nil
+}
@@ -0,0 +1,33 @@
+package cdc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeChange(t *testing.T) {
+	change, err := decodeChange(`table public.transactions: INSERT: id[integer]:7 source_account_id[bigint]:1 ` +
+		`destination_account_id[bigint]:2 amount[numeric]:10.50000 status[character varying]:'complete' ` +
+		`created_at[timestamp with time zone]:'2024-05-01 10:00:00+00' note[text]:'it''s' reversal_of[integer]:null`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "transactions", change.Table)
+	assert.Equal(t, OperationInsert, change.Operation)
+	assert.Equal(t, "7", *change.Columns["id"])
+	assert.Equal(t, "10.50000", *change.Columns["amount"])
+	assert.Equal(t, "complete", *change.Columns["status"])
+	assert.Equal(t, "2024-05-01 10:00:00+00", *change.Columns["created_at"])
+	assert.Equal(t, "it's", *change.Columns["note"])
+	assert.Contains(t, change.Columns, "reversal_of")
+	assert.Nil(t, change.Columns["reversal_of"])
+}
+
+func TestDecodeChange_Invalid(t *testing.T) {
+	_, err := decodeChange("BEGIN 529")
+	assert.Error(t, err)
+
+	_, err = decodeChange("table public.accounts: UPDATE: balance[numeric")
+	assert.Error(t, err)
+}
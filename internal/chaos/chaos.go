@@ -0,0 +1,192 @@
+// Package chaos injects faults into the repository calls of the transfer pipeline: latency,
+// dropped connections and serialization failures, each at a configured rate. It is meant for
+// sandbox and soak runs with the simulator, to show that retries, idempotency keys and
+// reconciliation hold up when the database misbehaves, and refuses to run outside sandbox mode.
+package chaos
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/lib/pq"
+)
+
+// Fault is a kind of injected fault
+type Fault string
+
+const (
+	// FaultLatency delays a call by a random duration up to the maximum latency
+	FaultLatency Fault = "latency"
+	// FaultDrop fails a call as if the database connection was lost
+	FaultDrop Fault = "drop"
+	// FaultSerialization fails a call with a serialization failure (SQLSTATE 40001)
+	FaultSerialization Fault = "serialization"
+)
+
+// faults lists the faults in the order they are rolled for each call
+var faults = []Fault{FaultLatency, FaultDrop, FaultSerialization}
+
+// sqlStateSerializationFailure is reported by Postgres when a serializable transaction conflicts
+const sqlStateSerializationFailure = "40001"
+
+// Rates are the probabilities, between 0 and 1, of each fault being injected into a call
+type Rates map[Fault]float64
+
+// ParseRates parses rates configured as fault=probability pairs, such as "drop=0.01"
+func ParseRates(raw map[string]string) (Rates, error) {
+	rates := make(Rates, len(raw))
+	for name, value := range raw {
+		fault := Fault(strings.TrimSpace(name))
+		known := false
+		for _, f := range faults {
+			known = known || f == fault
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown chaos fault %q", errors.ErrValidationFailed, name)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%w: rate of chaos fault %s must be between 0 and 1", errors.ErrValidationFailed, fault)
+		}
+		rates[fault] = rate
+	}
+	return rates, nil
+}
+
+// Stats counts the calls seen and the faults injected into them
+type Stats struct {
+	Calls    uint64           `json:"calls"`
+	Injected map[Fault]uint64 `json:"injected"`
+}
+
+// Injector decides, call by call, which faults to inject
+type Injector struct {
+	rates      Rates
+	maxLatency time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	calls    uint64
+	injected map[Fault]uint64
+	log      *slog.Logger
+}
+
+// NewInjector creates an injector with the given rates. Delays are drawn up to maxLatency, and
+// seed makes the sequence of faults reproducible for a given sequence of calls.
+func NewInjector(rates Rates, maxLatency time.Duration, seed int64) *Injector {
+	return &Injector{
+		rates:      rates,
+		maxLatency: maxLatency,
+		sleep:      sleep,
+		rng:        rand.New(rand.NewSource(seed)),
+		injected:   make(map[Fault]uint64),
+		log:        logger.Default(),
+	}
+}
+
+// FromConfig creates the injector configured by CHAOS_* settings, or returns nil when chaos mode
+// is disabled. Chaos mode is refused unless SANDBOX_MODE is set, so it can't reach production.
+func FromConfig(cfg *config.Config) (*Injector, error) {
+	if !cfg.ChaosEnabled {
+		return nil, nil
+	}
+	if !cfg.SandboxMode {
+		return nil, fmt.Errorf("%w: CHAOS_ENABLED requires SANDBOX_MODE", errors.ErrValidationFailed)
+	}
+	rates, err := ParseRates(cfg.ChaosFaults)
+	if err != nil {
+		return nil, err
+	}
+	seed := int64(cfg.ChaosSeed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger.Default().Warn("Chaos mode enabled", "faults", rates, "max_latency", cfg.ChaosMaxLatency, "seed", seed)
+	return NewInjector(rates, time.Duration(cfg.ChaosMaxLatency)*time.Millisecond, seed), nil
+}
+
+// Inject is called before the repository call op. It may delay the call, and returns the error
+// the call fails with instead of running, or nil to let it run.
+func (i *Injector) Inject(ctx context.Context, op string) error {
+	delay, fault := i.roll()
+	if delay > 0 {
+		if err := i.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+	switch fault {
+	case FaultDrop:
+		i.log.Debug("Chaos: dropping connection", "op", op)
+		return fmt.Errorf("chaos: connection dropped in %s: %w", op, driver.ErrBadConn)
+	case FaultSerialization:
+		i.log.Debug("Chaos: injecting serialization failure", "op", op)
+		return &pq.Error{
+			Code:    sqlStateSerializationFailure,
+			Message: "could not serialize access due to concurrent update (injected by chaos mode in " + op + ")",
+		}
+	}
+	return nil
+}
+
+// roll draws the delay and the failing fault, if any, of a call
+func (i *Injector) roll() (time.Duration, Fault) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.calls++
+	var delay time.Duration
+	if i.hit(FaultLatency) && i.maxLatency > 0 {
+		delay = time.Duration(i.rng.Int63n(int64(i.maxLatency)) + 1)
+	}
+	for _, fault := range []Fault{FaultDrop, FaultSerialization} {
+		if i.hit(fault) {
+			return delay, fault
+		}
+	}
+	return delay, ""
+}
+
+// hit rolls fault and counts it when injected. The caller holds i.mu.
+func (i *Injector) hit(fault Fault) bool {
+	rate := i.rates[fault]
+	if rate <= 0 || i.rng.Float64() >= rate {
+		return false
+	}
+	i.injected[fault]++
+	return true
+}
+
+// Stats returns the counters of the injector
+func (i *Injector) Stats() *Stats {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	stats := &Stats{Calls: i.calls, Injected: make(map[Fault]uint64, len(faults))}
+	for _, fault := range faults {
+		stats.Injected[fault] = i.injected[fault]
+	}
+	return stats
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package chaos
+
+import (
+	"context"
+	"database/sql/driver"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(map[string]string{"latency": "0.5", "drop": "0", "serialization": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, Rates{FaultLatency: 0.5, FaultDrop: 0, FaultSerialization: 1}, rates)
+
+	_, err = ParseRates(map[string]string{"meteor": "0.1"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = ParseRates(map[string]string{"drop": "1.5"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
+
+func TestFromConfig(t *testing.T) {
+	injector, err := FromConfig(&config.Config{})
+	require.NoError(t, err)
+	assert.Nil(t, injector)
+
+	_, err = FromConfig(&config.Config{ChaosEnabled: true})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed, "chaos mode outside the sandbox")
+
+	injector, err = FromConfig(&config.Config{ChaosEnabled: true, SandboxMode: true, ChaosFaults: map[string]string{"drop": "1"}})
+	require.NoError(t, err)
+	assert.Error(t, injector.Inject(context.Background(), "op"))
+}
+
+func TestInjector_Inject(t *testing.T) {
+	ctx := context.Background()
+
+	quiet := NewInjector(Rates{}, time.Second, 1)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, quiet.Inject(ctx, "op"))
+	}
+
+	err := NewInjector(Rates{FaultDrop: 1}, 0, 1).Inject(ctx, "op")
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+
+	err = NewInjector(Rates{FaultSerialization: 1}, 0, 1).Inject(ctx, "op")
+	var pqErr *pq.Error
+	require.True(t, stderrors.As(err, &pqErr))
+	assert.Equal(t, "40001", pqErr.SQLState())
+
+	// Delays are bounded and cut short when ctx is done
+	slow := NewInjector(Rates{FaultLatency: 1}, 50*time.Millisecond, 1)
+	var delays []time.Duration
+	slow.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	require.NoError(t, slow.Inject(ctx, "op"))
+	require.Len(t, delays, 1)
+	assert.True(t, delays[0] > 0 && delays[0] <= 50*time.Millisecond)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, slow.Inject(cancelled, "op"), context.Canceled)
+
+	stats := slow.Stats()
+	assert.Equal(t, uint64(2), stats.Calls)
+	assert.Equal(t, map[Fault]uint64{FaultLatency: 2, FaultDrop: 0, FaultSerialization: 0}, stats.Injected)
+}
+
+func TestInjector_SeedReproducesFaults(t *testing.T) {
+	ctx := context.Background()
+	rates := Rates{FaultDrop: 0.3, FaultSerialization: 0.3}
+	outcomes := func() []bool {
+		injector := NewInjector(rates, 0, 42)
+		var failed []bool
+		for i := 0; i < 50; i++ {
+			failed = append(failed, injector.Inject(ctx, "op") != nil)
+		}
+		return failed
+	}
+	assert.Equal(t, outcomes(), outcomes())
+}
+
+func TestWrapAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryAccountRepository(repository.NewMemoryStore())
+	require.NoError(t, repo.CreateAccount(ctx, 1, decimal.NewFromInt(10)))
+
+	assert.Same(t, repo, WrapAccounts(repo, nil))
+
+	_, err := WrapAccounts(repo, NewInjector(Rates{FaultDrop: 1}, 0, 1)).GetAccount(ctx, 1)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+
+	account, err := WrapAccounts(repo, NewInjector(Rates{}, 0, 1)).GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(account.Balance))
+}
@@ -0,0 +1,82 @@
+package chaos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// accountRepository injects faults into the account calls made by every transfer
+type accountRepository struct {
+	repository.AccountRepository
+	injector *Injector
+}
+
+// WrapAccounts returns repo with faults injected into the account reads and balance updates of
+// the transfer path. It returns repo unchanged when injector is nil.
+func WrapAccounts(repo repository.AccountRepository, injector *Injector) repository.AccountRepository {
+	if injector == nil {
+		return repo
+	}
+	return &accountRepository{AccountRepository: repo, injector: injector}
+}
+
+func (r *accountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	if err := r.injector.Inject(ctx, "GetAccount"); err != nil {
+		return nil, err
+	}
+	return r.AccountRepository.GetAccount(ctx, accountID)
+}
+
+func (r *accountRepository) GetAccountWithTx(ctx context.Context, tx *sql.Tx, accountID int64) (*models.Account, error) {
+	if err := r.injector.Inject(ctx, "GetAccountWithTx"); err != nil {
+		return nil, err
+	}
+	return r.AccountRepository.GetAccountWithTx(ctx, tx, accountID)
+}
+
+func (r *accountRepository) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+	if err := r.injector.Inject(ctx, "UpdateBalanceWithTx"); err != nil {
+		return err
+	}
+	return r.AccountRepository.UpdateBalanceWithTx(ctx, tx, accountID, newBalance)
+}
+
+// transactionRepository injects faults into the transaction calls made by every transfer
+type transactionRepository struct {
+	repository.TransactionRepository
+	injector *Injector
+}
+
+// WrapTransactions returns repo with faults injected into recording transactions, their status
+// changes and idempotency key lookups. It returns repo unchanged when injector is nil.
+func WrapTransactions(repo repository.TransactionRepository, injector *Injector) repository.TransactionRepository {
+	if injector == nil {
+		return repo
+	}
+	return &transactionRepository{TransactionRepository: repo, injector: injector}
+}
+
+func (r *transactionRepository) CreateTransactionWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
+	if err := r.injector.Inject(ctx, "CreateTransactionWithTx"); err != nil {
+		return nil, err
+	}
+	return r.TransactionRepository.CreateTransactionWithTx(ctx, tx, transaction)
+}
+
+func (r *transactionRepository) AddStatusChangeWithTx(ctx context.Context, tx *sql.Tx, change *models.TransactionStatusChange) error {
+	if err := r.injector.Inject(ctx, "AddStatusChangeWithTx"); err != nil {
+		return err
+	}
+	return r.TransactionRepository.AddStatusChangeWithTx(ctx, tx, change)
+}
+
+func (r *transactionRepository) GetTransactionByBatchItemKey(ctx context.Context, key string) (*models.Transaction, error) {
+	if err := r.injector.Inject(ctx, "GetTransactionByBatchItemKey"); err != nil {
+		return nil, err
+	}
+	return r.TransactionRepository.GetTransactionByBatchItemKey(ctx, key)
+}
@@ -0,0 +1,76 @@
+// Package clock provides the time the service makes its decisions on: business dates, expiry of
+// pre-authorizations, dormancy. Production uses the wall clock; sandbox deployments use a Virtual
+// clock that operators move forward to exercise time-dependent behavior without waiting.
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the wall clock
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns the wall clock time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Virtual is a clock that runs at wall clock speed from an offset. The offset only ever grows, so
+// time never goes backwards for the work scheduled against it.
+type Virtual struct {
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+// NewVirtual creates a virtual clock showing the wall clock time
+func NewVirtual() *Virtual {
+	return &Virtual{}
+}
+
+// Now returns the wall clock time moved forward by the offset
+func (v *Virtual) Now() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Now().Add(v.offset)
+}
+
+// Offset returns how far the clock is ahead of the wall clock
+func (v *Virtual) Offset() time.Duration {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.offset
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (v *Virtual) Advance(d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("%w: the clock can only move forward, got %s", errors.ErrValidationFailed, d)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.offset += d
+	return time.Now().Add(v.offset), nil
+}
+
+// AdvanceTo moves the clock forward to t and returns the new time
+func (v *Virtual) AdvanceTo(t time.Time) (time.Time, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	if !t.After(now.Add(v.offset)) {
+		return time.Time{}, fmt.Errorf("%w: the clock can only move forward, %s is in the past", errors.ErrValidationFailed, t.Format(time.RFC3339))
+	}
+	v.offset = t.Sub(now)
+	return t, nil
+}
@@ -0,0 +1,46 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtual_Advance(t *testing.T) {
+	v := NewVirtual()
+	assert.WithinDuration(t, time.Now(), v.Now(), time.Second)
+
+	now, err := v.Advance(48 * time.Hour)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), now, time.Second)
+	assert.WithinDuration(t, now, v.Now(), time.Second)
+	assert.Equal(t, 48*time.Hour, v.Offset())
+
+	_, err = v.Advance(0)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = v.Advance(-time.Hour)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	assert.Equal(t, 48*time.Hour, v.Offset())
+}
+
+func TestVirtual_AdvanceTo(t *testing.T) {
+	v := NewVirtual()
+	target := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	now, err := v.AdvanceTo(target)
+	require.NoError(t, err)
+	assert.Equal(t, target, now)
+	assert.WithinDuration(t, target, v.Now(), time.Second)
+
+	// Moving back, even to a time still ahead of the wall clock, is rejected
+	_, err = v.AdvanceTo(target.Add(-24 * time.Hour))
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	assert.WithinDuration(t, target, v.Now(), time.Second)
+}
+
+func TestSystem(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), System.Now(), time.Second)
+}
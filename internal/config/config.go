@@ -4,15 +4,158 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
 )
 
 type Config struct {
-	DatabaseURL      string
-	ServerPort       int
-	MaxDBConnections int
-	MaxIdleConns     int
-	ConnMaxLifetime  int // in minutes
-	LogLevel         string
+	DatabaseURL            string
+	ServerPort             int
+	MaxDBConnections       int
+	MaxIdleConns           int
+	ConnMaxLifetime        int // in minutes
+	LogLevel               string
+	LogFormat              string // "text" or "json"
+	LogRedactAmounts       bool   // mask amounts and balances in the log
+	LogHashAccountIDs      bool   // log account IDs as keyed hashes
+	LogHashKey             string // key of the account ID hashes, required to hash them
+	PgBouncerMode          bool   // disable session-level features for transaction-pooling proxies
+	CDCEnabled             bool
+	CDCSlotName            string
+	CDCPollInterval        int // in milliseconds
+	CDCBatchSize           int
+	OutboxEnabled          bool
+	OutboxPollInterval     int // in milliseconds
+	OutboxBatchSize        int
+	EventBus               string            // "kafka", "nats" or empty for none
+	KafkaBrokers           []string          // "host:port" addresses of the bootstrap brokers
+	KafkaTopics            map[string]string // topic of each event type published to Kafka
+	KafkaClientID          string
+	KafkaTimeout           int  // in milliseconds, per request to a broker
+	KafkaPlaintext         bool // accepts that the producer has no TLS or SASL; EVENT_BUS=kafka requires it
+	NATSURL                string
+	NATSClientName         string
+	NATSStream             string   // JetStream stream provisioned to capture the events
+	NATSSubjectPrefix      string   // events are published to "<prefix>.<event type>"
+	NATSConsumers          []string // durable consumers provisioned on the stream
+	NATSTimeout            int      // in milliseconds, per request to the server
+	AmountPrecision        int32
+	AmountScale            int32
+	RegionName             string
+	RegionRole             string // "primary" or "standby"
+	ReplicaDatabaseURL     string
+	HedgedReads            bool
+	HedgeDelay             int  // in milliseconds
+	LockingShadow          bool // dry-run every transfer on the row-locking path and compare outcomes
+	AccountCacheTTL        int  // in milliseconds, 0 disables the cache
+	AccountCacheMaxEntries int
+	AccountCacheNegative   bool
+	WarmupConnections      int      // database connections opened before serving, 0 disables warm-up
+	WarmupHotAccounts      []string // IDs of accounts loaded into the account cache before serving
+	WarmupTimeout          int      // in milliseconds
+	TenantCacheTTL         int      // in milliseconds
+	WebhookTimeout         int      // in milliseconds
+	WebhookMaxAttempts     int      // attempts made to deliver an event, 1 disables automatic retries
+	WebhookRetryDelay      int      // in milliseconds, before the first automatic retry
+	Middlewares            []string // names of the HTTP middlewares to apply, outermost first
+	AccessLogFormat        string   // "json" or "common"
+	AccessLogOutput        string   // "stdout", "stderr" or a file path
+	JournalPath            string   // file the journal middleware appends accepted writes to
+	HTTPReadTimeout        int      // in milliseconds
+	HTTPReadHeaderTimeout  int      // in milliseconds
+	HTTPWriteTimeout       int      // in milliseconds
+	HTTPIdleTimeout        int      // in milliseconds
+	HTTPMaxHeaderBytes     int
+	HTTPKeepAlives         bool
+	HTTP2Enabled           bool // serve cleartext HTTP/2 (h2c) alongside HTTP/1.1
+	HTTP2MaxStreams        int
+	SuspenseAccountID      int64  // credited when a batch transfer's destination is frozen or closed, 0 disables
+	FeesAccountID          int64  // collects the fees charged on transfers, 0 disables fees
+	AdjustmentsAccountID   int64  // pays and receives manual balance adjustments, 0 disables them
+	ClosureSweepAccountID  int64  // receives the residual balance of force-closed accounts by default, 0 for none
+	BusinessDayTimezone    string // IANA timezone business dates are evaluated in
+	BusinessDayCutoff      string // "HH:MM" local time from which transactions are booked on the next business date
+	DormancyEnabled        bool
+	DormancyPeriodDays     int  // days without activity before an account is marked dormant
+	DormancyBlockOutbound  bool // reject transfers from dormant accounts until reactivated
+	DormancySweepInterval  int  // in minutes
+	DormancySweepBatchSize int
+	MinimumBalances        map[string]string // minimum maintained balance by account type, as decimal strings
+	FXRates                map[string]string // exchange rates keyed by "FROM/TO", as decimal strings
+	PreAuthValidity        int               // in minutes
+	SweepInterval          int               // in seconds
+	SweepBatchSize         int
+	SandboxMode            bool // run on a virtual clock that can be advanced through /admin/clock
+	BalanceSnapshotHours   int  // hours between balance snapshots, 0 disables them
+
+	ChaosEnabled    bool              // inject faults into transfer repository calls; requires SandboxMode
+	ChaosFaults     map[string]string // probability of each fault per call, by fault name
+	ChaosMaxLatency int               // in milliseconds, bounds injected delays
+	ChaosSeed       int               // seeds fault injection, 0 seeds from the clock
+
+	ScheduledTransferMaxAttempts int // attempts before a scheduled transfer is marked failed
+	ScheduledTransferRetryDelay  int // in seconds, before the first retry; doubles with each failure
+
+	WorkerPollInterval       int // in milliseconds, between polls of an idle background worker
+	WorkerStallGrace         int // in seconds, a worker may go without a heartbeat beyond its interval
+	AsyncTransferWorkers     int // goroutines making asynchronously submitted transfers
+	AsyncTransferMaxAttempts int // attempts before a transfer job is marked failed; rejected transfers fail at once
+	AsyncTransferRetryDelay  int // in milliseconds, before the first retry; doubles with each failure
+
+	ExportRowsPerSecond int // rows read per second by all history exports together, 0 leaves them unpaced
+	ExportMaxConcurrent int // export chunks served at once; more are rejected
+
+	TransferLaneSlots       int               // transfers made at once, 0 leaves them unbounded
+	TransferLaneBulkSlots   int               // of those, taken by bulk work at most; 0 allows all
+	TransferLaneWeights     map[string]string // share of freed slots per priority class
+	TransferPriorityByActor map[string]string // default priority class of each X-Actor
+
+	SLOTargets               map[string]string // "threshold/latency_target/availability_target" by endpoint pattern
+	SLOWindowHours           int               // compliance window of the SLOs
+	SLOEvaluationIntervalSec int               // how often burn rate alerts are evaluated
+
+	LatencyBudget        int // in milliseconds, what a request may take before its work is cut off
+	LatencyBudgetReserve int // in milliseconds; with less left, optional work is skipped
+
+	ArtifactStore      string // "local", "s3" or "gcs"; empty disables artifact storage
+	ArtifactDir        string // root directory of the local artifact store
+	ArtifactBucket     string
+	ArtifactPrefix     string // prepended to artifact keys in the bucket
+	ArtifactEndpoint   string // overrides the S3 or GCS endpoint, e.g. for MinIO or an emulator
+	ArtifactRegion     string // of the S3 bucket
+	ArtifactTimeout    int    // in milliseconds, per storage request
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	GCSAccessToken     string // static token; without one the metadata server's is used
+
+	IdempotencySealingKeys map[string]string // base64 AES-256 keys by ID; empty stores responses unsealed
+	IdempotencyActiveKey   string            // ID of the key new responses are sealed with
+	IdempotencyTTLHours    int               // hours a stored response is kept, 0 keeps them
+
+	VelocityRules map[string]string // "window/max_count/max_volume/action" by rule name
+
+	RateLimits map[string]string // "per_second/burst" by route class: read, transfer or write
+
+	OIDCIssuer             string // iss of accepted JWT bearer tokens; empty disables them
+	OIDCJWKSURL            string
+	OIDCAudience           string // required aud of accepted tokens; empty doesn't check it
+	OIDCNameClaim          string
+	OIDCScopeClaim         string
+	OIDCRoleClaim          string
+	OIDCJWKSRefreshSeconds int
+	OIDCClockSkewSeconds   int
+
+	SignatureWindowSeconds int // how far a signed request's timestamp may be from now
+
+	ApprovalThreshold string // decimal amount above which transfers need approval; empty disables approvals
+}
+
+// SessionFeaturesEnabled reports whether features that rely on a dedicated server session
+// (named prepared statements, session advisory locks, LISTEN/NOTIFY) may be used.
+// They are unavailable behind PgBouncer in transaction pooling mode.
+func (c *Config) SessionFeaturesEnabled() bool {
+	return !c.PgBouncerMode
 }
 
 // LogLevel represents the severity of a log message
@@ -70,14 +213,260 @@ func Load() (*Config, error) {
 	maxIdleConns := getEnvAsInt("MAX_IDLE_CONNECTIONS", 5)
 	connMaxLifetime := getEnvAsInt("CONN_MAX_LIFETIME_MINUTES", 30)
 	logLevel := getEnv("LOG_LEVEL", "info")
+	logFormat := getEnv("LOG_FORMAT", "text")
+	logRedactAmounts := getEnvAsBool("LOG_REDACT_AMOUNTS", false)
+	logHashAccountIDs := getEnvAsBool("LOG_HASH_ACCOUNT_IDS", false)
+	logHashKey := getEnv("LOG_HASH_KEY", "")
+	pgBouncerMode := getEnvAsBool("PGBOUNCER_MODE", false)
+	cdcEnabled := getEnvAsBool("CDC_ENABLED", false)
+	cdcSlotName := getEnv("CDC_SLOT_NAME", "transfers_cdc")
+	cdcPollInterval := getEnvAsInt("CDC_POLL_INTERVAL_MS", 1000)
+	cdcBatchSize := getEnvAsInt("CDC_BATCH_SIZE", 500)
+	outboxEnabled := getEnvAsBool("OUTBOX_ENABLED", false)
+	outboxPollInterval := getEnvAsInt("OUTBOX_POLL_INTERVAL_MS", 1000)
+	outboxBatchSize := getEnvAsInt("OUTBOX_BATCH_SIZE", 100)
+	eventBus := getEnv("EVENT_BUS", "")
+	kafkaBrokers := getEnvAsList("KAFKA_BROKERS", nil)
+	kafkaTopics := getEnvAsMap("KAFKA_TOPICS")
+	kafkaClientID := getEnv("KAFKA_CLIENT_ID", "internal-transfers")
+	kafkaTimeout := getEnvAsInt("KAFKA_TIMEOUT_MS", 10000)
+	kafkaPlaintext := getEnvAsBool("KAFKA_PLAINTEXT", false)
+	natsURL := getEnv("NATS_URL", "nats://localhost:4222")
+	natsClientName := getEnv("NATS_CLIENT_NAME", "internal-transfers")
+	natsStream := getEnv("NATS_STREAM", "TRANSFERS")
+	natsSubjectPrefix := getEnv("NATS_SUBJECT_PREFIX", "transfers")
+	natsConsumers := getEnvAsList("NATS_CONSUMERS", nil)
+	natsTimeout := getEnvAsInt("NATS_TIMEOUT_MS", 5000)
+	amountPrecision := getEnvAsInt("AMOUNT_PRECISION", 20)
+	amountScale := getEnvAsInt("AMOUNT_SCALE", 5)
+	regionName := getEnv("REGION_NAME", "default")
+	regionRole := getEnv("REGION_ROLE", "primary")
+	replicaDatabaseURL := getEnv("REPLICA_DATABASE_URL", "")
+	hedgedReads := getEnvAsBool("HEDGED_READS_ENABLED", false)
+	hedgeDelay := getEnvAsInt("HEDGE_DELAY_MS", 50)
+	lockingShadow := getEnvAsBool("LOCKING_SHADOW_ENABLED", false)
+	accountCacheTTL := getEnvAsInt("ACCOUNT_CACHE_TTL_MS", 0)
+	accountCacheMaxEntries := getEnvAsInt("ACCOUNT_CACHE_MAX_ENTRIES", 10000)
+	accountCacheNegative := getEnvAsBool("ACCOUNT_CACHE_NEGATIVE", false)
+	warmupConnections := getEnvAsInt("WARMUP_CONNECTIONS", 0)
+	warmupHotAccounts := getEnvAsList("WARMUP_HOT_ACCOUNTS", nil)
+	warmupTimeout := getEnvAsInt("WARMUP_TIMEOUT_MS", 10000)
+	tenantCacheTTL := getEnvAsInt("TENANT_CACHE_TTL_MS", 30000)
+	webhookTimeout := getEnvAsInt("WEBHOOK_TIMEOUT_MS", 5000)
+	webhookMaxAttempts := getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5)
+	webhookRetryDelay := getEnvAsInt("WEBHOOK_RETRY_DELAY_MS", 30000)
+	middlewares := getEnvAsList("MIDDLEWARES", []string{"recover", "trace_context", "request_id", "logging", "idempotency", "actor"})
+	accessLogFormat := getEnv("ACCESS_LOG_FORMAT", "json")
+	accessLogOutput := getEnv("ACCESS_LOG_OUTPUT", "stdout")
+	journalPath := getEnv("JOURNAL_PATH", "")
+	httpReadTimeout := getEnvAsInt("HTTP_READ_TIMEOUT_MS", 5000)
+	httpReadHeaderTimeout := getEnvAsInt("HTTP_READ_HEADER_TIMEOUT_MS", 2000)
+	httpWriteTimeout := getEnvAsInt("HTTP_WRITE_TIMEOUT_MS", 10000)
+	httpIdleTimeout := getEnvAsInt("HTTP_IDLE_TIMEOUT_MS", 120000)
+	httpMaxHeaderBytes := getEnvAsInt("HTTP_MAX_HEADER_BYTES", 1<<20)
+	httpKeepAlives := getEnvAsBool("HTTP_KEEP_ALIVES", true)
+	http2Enabled := getEnvAsBool("HTTP2_ENABLED", false)
+	http2MaxStreams := getEnvAsInt("HTTP2_MAX_CONCURRENT_STREAMS", 250)
+	suspenseAccountID := getEnvAsInt("SUSPENSE_ACCOUNT_ID", 0)
+	feesAccountID := getEnvAsInt("FEES_ACCOUNT_ID", 0)
+	adjustmentsAccountID := getEnvAsInt("ADJUSTMENTS_ACCOUNT_ID", 0)
+	closureSweepAccountID := getEnvAsInt("CLOSURE_SWEEP_ACCOUNT_ID", 0)
+	businessDayTimezone := getEnv("BUSINESS_DAY_TIMEZONE", "UTC")
+	businessDayCutoff := getEnv("BUSINESS_DAY_CUTOFF", "24:00")
+	dormancyEnabled := getEnvAsBool("DORMANCY_ENABLED", false)
+	dormancyPeriodDays := getEnvAsInt("DORMANCY_PERIOD_DAYS", 365)
+	dormancyBlockOutbound := getEnvAsBool("DORMANCY_BLOCK_OUTBOUND", true)
+	dormancySweepInterval := getEnvAsInt("DORMANCY_SWEEP_INTERVAL_MINUTES", 60)
+	dormancySweepBatchSize := getEnvAsInt("DORMANCY_SWEEP_BATCH_SIZE", 1000)
+	minimumBalances := getEnvAsMap("MINIMUM_BALANCES")
+	fxRates := getEnvAsMap("FX_RATES")
+	preAuthValidity := getEnvAsInt("PREAUTH_VALIDITY_MINUTES", 15)
+	sweepInterval := getEnvAsInt("SWEEP_INTERVAL_SECONDS", 60)
+	sweepBatchSize := getEnvAsInt("SWEEP_BATCH_SIZE", 500)
+	sandboxMode := getEnvAsBool("SANDBOX_MODE", false)
+	balanceSnapshotHours := getEnvAsInt("BALANCE_SNAPSHOT_INTERVAL_HOURS", 24)
+	chaosEnabled := getEnvAsBool("CHAOS_ENABLED", false)
+	chaosFaults := getEnvAsMap("CHAOS_FAULTS")
+	chaosMaxLatency := getEnvAsInt("CHAOS_MAX_LATENCY_MS", 200)
+	chaosSeed := getEnvAsInt("CHAOS_SEED", 0)
+	scheduledTransferMaxAttempts := getEnvAsInt("SCHEDULED_TRANSFER_MAX_ATTEMPTS", 5)
+	scheduledTransferRetryDelay := getEnvAsInt("SCHEDULED_TRANSFER_RETRY_DELAY_SECONDS", 300)
+	workerPollInterval := getEnvAsInt("WORKER_POLL_INTERVAL_MS", 500)
+	workerStallGrace := getEnvAsInt("WORKER_STALL_GRACE_SECONDS", 60)
+	asyncTransferWorkers := getEnvAsInt("ASYNC_TRANSFER_WORKERS", 4)
+	asyncTransferMaxAttempts := getEnvAsInt("ASYNC_TRANSFER_MAX_ATTEMPTS", 5)
+	asyncTransferRetryDelay := getEnvAsInt("ASYNC_TRANSFER_RETRY_DELAY_MS", 1000)
+	exportRowsPerSecond := getEnvAsInt("EXPORT_ROWS_PER_SECOND", 2000)
+	exportMaxConcurrent := getEnvAsInt("EXPORT_MAX_CONCURRENT", 2)
+	idempotencySealingKeys := getEnvAsMap("IDEMPOTENCY_SEALING_KEYS")
+	idempotencyActiveKey := getEnv("IDEMPOTENCY_ACTIVE_KEY", "")
+	idempotencyTTLHours := getEnvAsInt("IDEMPOTENCY_TTL_HOURS", 24)
+	velocityRules := getEnvAsMap("VELOCITY_RULES")
+	rateLimits := getEnvAsMap("RATE_LIMITS")
+	approvalThreshold := getEnv("APPROVAL_THRESHOLD", "")
+	transferLaneSlots := getEnvAsInt("TRANSFER_LANE_SLOTS", 0)
+	transferLaneBulkSlots := getEnvAsInt("TRANSFER_LANE_BULK_SLOTS", 0)
+	transferLaneWeights := getEnvAsMap("TRANSFER_LANE_WEIGHTS")
+	transferPriorityByActor := getEnvAsMap("TRANSFER_PRIORITY_BY_ACTOR")
+	sloTargets := getEnvAsMap("SLO_TARGETS")
+	sloWindowHours := getEnvAsInt("SLO_WINDOW_HOURS", 24)
+	sloEvaluationInterval := getEnvAsInt("SLO_EVALUATION_INTERVAL_SECONDS", 60)
+	latencyBudget := getEnvAsInt("LATENCY_BUDGET_MS", 9000)
+	latencyBudgetReserve := getEnvAsInt("LATENCY_BUDGET_RESERVE_MS", 200)
+	artifactStore := getEnv("ARTIFACT_STORE", "")
+	artifactDir := getEnv("ARTIFACT_DIR", "./artifacts")
+	artifactBucket := getEnv("ARTIFACT_BUCKET", "")
+	artifactPrefix := getEnv("ARTIFACT_PREFIX", "")
+	artifactEndpoint := getEnv("ARTIFACT_ENDPOINT", "")
+	artifactRegion := getEnv("ARTIFACT_REGION", getEnv("AWS_REGION", "us-east-1"))
+	artifactTimeout := getEnvAsInt("ARTIFACT_TIMEOUT_MS", 30000)
+
+	// Amounts are validated and formatted to the type of the columns; preflight reports an
+	// invalid setting, so it leaves the default in place here
+	if amountScale >= 0 && amountPrecision > amountScale {
+		models.SetAmountPrecision(int32(amountPrecision), int32(amountScale))
+	}
 
 	return &Config{
-		DatabaseURL:      databaseURL,
-		ServerPort:       serverPort,
-		MaxDBConnections: maxDBConns,
-		MaxIdleConns:     maxIdleConns,
-		ConnMaxLifetime:  connMaxLifetime,
-		LogLevel:         logLevel,
+		DatabaseURL:            databaseURL,
+		ServerPort:             serverPort,
+		MaxDBConnections:       maxDBConns,
+		MaxIdleConns:           maxIdleConns,
+		ConnMaxLifetime:        connMaxLifetime,
+		LogLevel:               logLevel,
+		LogFormat:              logFormat,
+		LogRedactAmounts:       logRedactAmounts,
+		LogHashAccountIDs:      logHashAccountIDs,
+		LogHashKey:             logHashKey,
+		PgBouncerMode:          pgBouncerMode,
+		CDCEnabled:             cdcEnabled,
+		CDCSlotName:            cdcSlotName,
+		CDCPollInterval:        cdcPollInterval,
+		CDCBatchSize:           cdcBatchSize,
+		OutboxEnabled:          outboxEnabled,
+		OutboxPollInterval:     outboxPollInterval,
+		OutboxBatchSize:        outboxBatchSize,
+		EventBus:               eventBus,
+		KafkaBrokers:           kafkaBrokers,
+		KafkaTopics:            kafkaTopics,
+		KafkaClientID:          kafkaClientID,
+		KafkaTimeout:           kafkaTimeout,
+		KafkaPlaintext:         kafkaPlaintext,
+		NATSURL:                natsURL,
+		NATSClientName:         natsClientName,
+		NATSStream:             natsStream,
+		NATSSubjectPrefix:      natsSubjectPrefix,
+		NATSConsumers:          natsConsumers,
+		NATSTimeout:            natsTimeout,
+		AmountPrecision:        int32(amountPrecision),
+		AmountScale:            int32(amountScale),
+		RegionName:             regionName,
+		RegionRole:             regionRole,
+		ReplicaDatabaseURL:     replicaDatabaseURL,
+		HedgedReads:            hedgedReads,
+		LockingShadow:          lockingShadow,
+		HedgeDelay:             hedgeDelay,
+		AccountCacheTTL:        accountCacheTTL,
+		AccountCacheMaxEntries: accountCacheMaxEntries,
+		AccountCacheNegative:   accountCacheNegative,
+		WarmupConnections:      warmupConnections,
+		WarmupHotAccounts:      warmupHotAccounts,
+		WarmupTimeout:          warmupTimeout,
+		TenantCacheTTL:         tenantCacheTTL,
+		WebhookTimeout:         webhookTimeout,
+		WebhookMaxAttempts:     webhookMaxAttempts,
+		WebhookRetryDelay:      webhookRetryDelay,
+		Middlewares:            middlewares,
+		AccessLogFormat:        accessLogFormat,
+		AccessLogOutput:        accessLogOutput,
+		JournalPath:            journalPath,
+		HTTPReadTimeout:        httpReadTimeout,
+		HTTPReadHeaderTimeout:  httpReadHeaderTimeout,
+		HTTPWriteTimeout:       httpWriteTimeout,
+		HTTPIdleTimeout:        httpIdleTimeout,
+		HTTPMaxHeaderBytes:     httpMaxHeaderBytes,
+		HTTPKeepAlives:         httpKeepAlives,
+		HTTP2Enabled:           http2Enabled,
+		HTTP2MaxStreams:        http2MaxStreams,
+		SuspenseAccountID:      int64(suspenseAccountID),
+		FeesAccountID:          int64(feesAccountID),
+		AdjustmentsAccountID:   int64(adjustmentsAccountID),
+		ClosureSweepAccountID:  int64(closureSweepAccountID),
+		BusinessDayTimezone:    businessDayTimezone,
+		BusinessDayCutoff:      businessDayCutoff,
+		DormancyEnabled:        dormancyEnabled,
+		DormancyPeriodDays:     dormancyPeriodDays,
+		DormancyBlockOutbound:  dormancyBlockOutbound,
+		DormancySweepInterval:  dormancySweepInterval,
+		DormancySweepBatchSize: dormancySweepBatchSize,
+		MinimumBalances:        minimumBalances,
+		FXRates:                fxRates,
+		PreAuthValidity:        preAuthValidity,
+		SweepInterval:          sweepInterval,
+		SweepBatchSize:         sweepBatchSize,
+		SandboxMode:            sandboxMode,
+		BalanceSnapshotHours:   balanceSnapshotHours,
+
+		ChaosEnabled:    chaosEnabled,
+		ChaosFaults:     chaosFaults,
+		ChaosMaxLatency: chaosMaxLatency,
+		ChaosSeed:       chaosSeed,
+
+		ScheduledTransferMaxAttempts: scheduledTransferMaxAttempts,
+		ScheduledTransferRetryDelay:  scheduledTransferRetryDelay,
+
+		WorkerPollInterval:       workerPollInterval,
+		WorkerStallGrace:         workerStallGrace,
+		AsyncTransferWorkers:     asyncTransferWorkers,
+		AsyncTransferMaxAttempts: asyncTransferMaxAttempts,
+		AsyncTransferRetryDelay:  asyncTransferRetryDelay,
+
+		ExportRowsPerSecond: exportRowsPerSecond,
+		ExportMaxConcurrent: exportMaxConcurrent,
+
+		TransferLaneSlots:       transferLaneSlots,
+		TransferLaneBulkSlots:   transferLaneBulkSlots,
+		TransferLaneWeights:     transferLaneWeights,
+		TransferPriorityByActor: transferPriorityByActor,
+
+		SLOTargets:               sloTargets,
+		SLOWindowHours:           sloWindowHours,
+		SLOEvaluationIntervalSec: sloEvaluationInterval,
+
+		LatencyBudget:        latencyBudget,
+		LatencyBudgetReserve: latencyBudgetReserve,
+
+		ArtifactStore:      artifactStore,
+		ArtifactDir:        artifactDir,
+		ArtifactBucket:     artifactBucket,
+		ArtifactPrefix:     artifactPrefix,
+		ArtifactEndpoint:   artifactEndpoint,
+		ArtifactRegion:     artifactRegion,
+		ArtifactTimeout:    artifactTimeout,
+		AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+		AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		AWSSessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+		GCSAccessToken:     getEnv("GCS_ACCESS_TOKEN", ""),
+
+		IdempotencySealingKeys: idempotencySealingKeys,
+		IdempotencyActiveKey:   idempotencyActiveKey,
+		IdempotencyTTLHours:    idempotencyTTLHours,
+
+		VelocityRules: velocityRules,
+
+		RateLimits: rateLimits,
+
+		OIDCIssuer:             getEnv("OIDC_ISSUER", ""),
+		OIDCJWKSURL:            getEnv("OIDC_JWKS_URL", ""),
+		OIDCAudience:           getEnv("OIDC_AUDIENCE", ""),
+		OIDCNameClaim:          getEnv("OIDC_NAME_CLAIM", "sub"),
+		OIDCScopeClaim:         getEnv("OIDC_SCOPE_CLAIM", "scope"),
+		OIDCRoleClaim:          getEnv("OIDC_ROLE_CLAIM", "roles"),
+		OIDCJWKSRefreshSeconds: getEnvAsInt("OIDC_JWKS_REFRESH_SECONDS", 3600),
+		OIDCClockSkewSeconds:   getEnvAsInt("OIDC_CLOCK_SKEW_SECONDS", 60),
+		SignatureWindowSeconds: getEnvAsInt("SIGNATURE_WINDOW_SECONDS", 300),
+
+		ApprovalThreshold: approvalThreshold,
 	}, nil
 }
 
@@ -96,3 +485,40 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList reads a comma-separated list, ignoring blank entries. An empty variable yields
+// an empty list rather than the default.
+func getEnvAsList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvAsMap reads a comma-separated list of key=value pairs. Entries without '=' are ignored.
+func getEnvAsMap(key string) map[string]string {
+	m := make(map[string]string)
+	for _, item := range getEnvAsList(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			m[k] = strings.TrimSpace(v)
+		}
+	}
+	return m
+}
@@ -0,0 +1,70 @@
+// Package database opens and configures the Postgres connection pool
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	_ "github.com/lib/pq"
+)
+
+// Open opens a connection pool using the database settings in cfg and verifies connectivity
+func Open(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	return open(ctx, cfg, cfg.DatabaseURL)
+}
+
+// OpenReplica opens a connection pool to the read replica, or returns nil if none is configured
+func OpenReplica(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	if cfg.ReplicaDatabaseURL == "" {
+		return nil, nil
+	}
+	return open(ctx, cfg, cfg.ReplicaDatabaseURL)
+}
+
+func open(ctx context.Context, cfg *config.Config, dsn string) (*sql.DB, error) {
+	if cfg.PgBouncerMode {
+		logger.Default().Info("PgBouncer compatibility mode enabled: session-level features disabled")
+		dsn = withPgBouncerParams(dsn)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxDBConnections)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	logger.Default().Info("Connected to database", "max_open_conns", cfg.MaxDBConnections, "max_idle_conns", cfg.MaxIdleConns, "conn_max_lifetime", cfg.ConnMaxLifetime)
+	return db, nil
+}
+
+// withPgBouncerParams adjusts the connection string for PgBouncer transaction pooling.
+// binary_parameters makes lib/pq send parameters inline with the unnamed statement instead of
+// preparing it in a separate round trip, so a query never depends on server-side statement
+// state that may live on a different backend once the transaction ends.
+func withPgBouncerParams(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		q := u.Query()
+		q.Set("binary_parameters", "yes")
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " binary_parameters=yes"
+}
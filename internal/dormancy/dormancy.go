@@ -0,0 +1,146 @@
+// Package dormancy periodically marks accounts without activity for the dormancy period as
+// dormant. Whether dormant accounts may still send transfers is decided by the transaction
+// service; reactivation is an explicit operator action.
+package dormancy
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// Stats is a snapshot of the dormancy job counters and the current account status counts
+type Stats struct {
+	PeriodDays       int                            `json:"period_days"`
+	Sweeps           uint64                         `json:"sweeps"`
+	SweepFailures    uint64                         `json:"sweep_failures"`
+	MarkedDormant    uint64                         `json:"marked_dormant"`
+	LastSweepAt      string                         `json:"last_sweep_at,omitempty"`
+	LastSweepMarked  int                            `json:"last_sweep_marked"`
+	DormantAccounts  int64                          `json:"dormant_accounts"`
+	AccountsByStatus map[models.AccountStatus]int64 `json:"accounts_by_status"`
+}
+
+// Job marks inactive accounts dormant on a fixed interval
+type Job struct {
+	accounts  repository.AccountRepository
+	period    time.Duration
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+
+	mu              sync.Mutex
+	sweeps          uint64
+	sweepFailures   uint64
+	markedDormant   uint64
+	lastSweepAt     time.Time
+	lastSweepMarked int
+	log             *slog.Logger
+}
+
+// NewJob creates a dormancy job using the dormancy settings in cfg
+func NewJob(accounts repository.AccountRepository, cfg *config.Config) *Job {
+	return &Job{
+		accounts:  accounts,
+		period:    time.Duration(cfg.DormancyPeriodDays) * 24 * time.Hour,
+		interval:  time.Duration(cfg.DormancySweepInterval) * time.Minute,
+		batchSize: cfg.DormancySweepBatchSize,
+		now:       time.Now,
+		log:       logger.Default(),
+	}
+}
+
+// SetClock makes the job judge inactivity by c instead of the wall clock. Call it before Run.
+func (j *Job) SetClock(c clock.Clock) {
+	j.now = c.Now
+}
+
+// Run sweeps immediately and then on every interval until ctx is cancelled
+func (j *Job) Run(ctx context.Context) error {
+	j.log.Info("Dormancy job started", "period", j.period, "interval", j.interval, "batch_size", j.batchSize)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.Sweep(ctx); err != nil {
+			j.log.Error("Dormancy sweep failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			j.log.Info("Dormancy job stopped")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep marks every active account without activity for the dormancy period as dormant, in
+// batches, and returns the number of accounts marked
+func (j *Job) Sweep(ctx context.Context) (int, error) {
+	inactiveSince := j.now().Add(-j.period)
+
+	marked := 0
+	for {
+		accountIDs, err := j.accounts.MarkDormant(ctx, inactiveSince, j.batchSize)
+		marked += len(accountIDs)
+		if err != nil {
+			j.record(marked, err)
+			return marked, err
+		}
+		if len(accountIDs) < j.batchSize {
+			break
+		}
+	}
+
+	if marked > 0 {
+		j.log.Info("Marked accounts dormant (no activity)", "marked", marked, "inactive_since", inactiveSince.Format(time.RFC3339))
+	}
+	j.record(marked, nil)
+	return marked, nil
+}
+
+// record updates the counters after a sweep
+func (j *Job) record(marked int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.sweeps++
+	if err != nil {
+		j.sweepFailures++
+	}
+	j.markedDormant += uint64(marked)
+	j.lastSweepAt = j.now()
+	j.lastSweepMarked = marked
+}
+
+// Stats returns the job counters together with the current number of accounts in each status
+func (j *Job) Stats(ctx context.Context) (*Stats, error) {
+	counts, err := j.accounts.CountAccountsByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stats := &Stats{
+		PeriodDays:       int(j.period / (24 * time.Hour)),
+		Sweeps:           j.sweeps,
+		SweepFailures:    j.sweepFailures,
+		MarkedDormant:    j.markedDormant,
+		LastSweepMarked:  j.lastSweepMarked,
+		DormantAccounts:  counts[models.AccountStatusDormant],
+		AccountsByStatus: counts,
+	}
+	if !j.lastSweepAt.IsZero() {
+		stats.LastSweepAt = j.lastSweepAt.Format(time.RFC3339)
+	}
+	return stats, nil
+}
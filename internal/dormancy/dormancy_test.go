@@ -0,0 +1,76 @@
+package dormancy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJob_Sweep(t *testing.T) {
+	ctx := context.Background()
+	accounts := repository.NewMemoryAccountRepository(repository.NewMemoryStore())
+	for _, id := range []int64{1, 2, 3} {
+		require.NoError(t, accounts.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+
+	job := NewJob(accounts, &config.Config{DormancyPeriodDays: 30, DormancySweepInterval: 60, DormancySweepBatchSize: 2})
+
+	// Nothing is inactive yet
+	marked, err := job.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+
+	// 31 days later every account is inactive; batches of 2 still mark all 3
+	job.now = func() time.Time { return time.Now().Add(31 * 24 * time.Hour) }
+	marked, err = job.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, marked)
+
+	account, err := accounts.GetAccount(ctx, 2)
+	require.NoError(t, err)
+	assert.True(t, account.IsDormant())
+	assert.True(t, account.CanReceiveCredits())
+
+	// Reactivation restarts the dormancy period
+	require.NoError(t, accounts.ReactivateAccount(ctx, 2))
+	job.now = time.Now
+	marked, err = job.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+
+	stats, err := job.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), stats.Sweeps)
+	assert.Equal(t, uint64(3), stats.MarkedDormant)
+	assert.Equal(t, int64(2), stats.DormantAccounts)
+	assert.Equal(t, int64(1), stats.AccountsByStatus[models.AccountStatusActive])
+	assert.Equal(t, 30, stats.PeriodDays)
+}
+
+func TestJob_SetClock(t *testing.T) {
+	ctx := context.Background()
+	accounts := repository.NewMemoryAccountRepository(repository.NewMemoryStore())
+	require.NoError(t, accounts.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+
+	virtual := clock.NewVirtual()
+	job := NewJob(accounts, &config.Config{DormancyPeriodDays: 30, DormancySweepInterval: 60, DormancySweepBatchSize: 10})
+	job.SetClock(virtual)
+
+	marked, err := job.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+
+	_, err = virtual.Advance(31 * 24 * time.Hour)
+	require.NoError(t, err)
+	marked, err = job.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+}
@@ -22,12 +22,208 @@ var (
 	// ErrInvalidAmount is returned when a transaction amount is invalid (zero or negative)
 	ErrInvalidAmount = errors.New("invalid amount: must be greater than zero")
 
+	// ErrAmountPrecision is returned when an amount has more decimal places or integer digits than the schema can store
+	ErrAmountPrecision = errors.New("invalid amount: exceeds supported precision")
+
+	// ErrAmountGranularity is returned when an amount is finer than the minor unit of the account currency
+	ErrAmountGranularity = errors.New("invalid amount: finer than the minor unit of the currency")
+
 	// ErrSameAccount is returned when trying to transfer between the same account
 	ErrSameAccount = errors.New("source and destination accounts must be different")
 
+	// ErrDuplicateRecord is returned when a write violates a unique constraint that no more
+	// specific error covers
+	ErrDuplicateRecord = errors.New("record already exists")
+
+	// ErrReferenceNotFound is returned when a write refers to a row that doesn't exist through a
+	// foreign key that no more specific error covers
+	ErrReferenceNotFound = errors.New("referenced record not found")
+
 	// ErrDatabaseError is returned when a database operation fails
 	ErrDatabaseError = errors.New("database operation failed")
 
+	// ErrReadOnly is returned when a write is attempted on an instance running in standby mode
+	ErrReadOnly = errors.New("service is in standby mode: writes are disabled")
+
+	// ErrFenced is returned when a write is attempted by an instance whose primary lease was taken over
+	ErrFenced = errors.New("write fenced: another region holds the primary lease")
+
+	// ErrJournalUnavailable is returned when a write can't be recorded in the request journal
+	ErrJournalUnavailable = errors.New("request journal unavailable: writes are disabled")
+
+	// ErrTransactionNotFound is returned when a transaction cannot be found
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// ErrWebhookNotFound is returned when a webhook cannot be found
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrDeliveryNotFound is returned when a webhook delivery attempt cannot be found
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrDuplicateIdempotencyKey is returned when a transaction with the same idempotency key was already recorded
+	ErrDuplicateIdempotencyKey = errors.New("a transaction with this idempotency key already exists")
+
+	// ErrDuplicateExternalReference is returned when a transaction with the same external reference was already recorded
+	ErrDuplicateExternalReference = errors.New("a transaction with this external reference already exists")
+
+	// ErrIdempotencyConflict is returned when an idempotency key is reused for a different request
+	ErrIdempotencyConflict = errors.New("idempotency key was already used for a different request")
+
+	// ErrAccountNotActive is returned when a transfer credits an account that is closed
+	ErrAccountNotActive = errors.New("account is not active")
+
+	// ErrAccountFrozen is returned when a transfer debits or credits an account that is frozen
+	ErrAccountFrozen = errors.New("account is frozen")
+
+	// ErrAccountStatusConflict is returned when an account's status can't be changed from its current status
+	ErrAccountStatusConflict = errors.New("account status can't be changed")
+
+	// ErrAccountDormant is returned when a dormant account sends a transfer before being reactivated
+	ErrAccountDormant = errors.New("account is dormant")
+
+	// ErrMinimumBalance is returned when a transfer would take an account below the minimum balance of its type
+	ErrMinimumBalance = errors.New("transfer would breach the minimum balance of the account")
+
+	// ErrSuspenseItemNotFound is returned when a suspense item cannot be found
+	ErrSuspenseItemNotFound = errors.New("suspense item not found")
+
+	// ErrSuspenseItemResolved is returned when a suspense item was already re-applied or returned
+	ErrSuspenseItemResolved = errors.New("suspense item is already resolved")
+
+	// ErrPreAuthorizationNotFound is returned when a pre-authorization cannot be found
+	ErrPreAuthorizationNotFound = errors.New("pre-authorization not found")
+
+	// ErrPreAuthorizationNotActive is returned when a pre-authorization was already executed or has expired
+	ErrPreAuthorizationNotActive = errors.New("pre-authorization is no longer active")
+
+	// ErrLedgerAccountNotFound is returned when a ledger account is not in the chart of accounts
+	ErrLedgerAccountNotFound = errors.New("ledger account not found")
+
+	// ErrLedgerAccountExists is returned when a ledger account code is already in the chart of accounts
+	ErrLedgerAccountExists = errors.New("ledger account already exists")
+
+	// ErrLedgerAccountInactive is returned when posting to a deactivated ledger account
+	ErrLedgerAccountInactive = errors.New("ledger account is inactive")
+
+	// ErrPostingRulesNotFound is returned when no posting template is configured for a transfer type
+	ErrPostingRulesNotFound = errors.New("posting rules not found")
+
+	// ErrPostingMismatch is returned when the posting template of a transfer type doesn't move the
+	// balances the transfer moves, so its entries would disagree with the balances
+	ErrPostingMismatch = errors.New("posting template doesn't match the transfer")
+
+	// ErrTransactionNotReversible is returned when reversing a transaction that isn't complete,
+	// was already reversed or is itself a reversal
+	ErrTransactionNotReversible = errors.New("transaction cannot be reversed")
+
+	// ErrTransactionAlreadyPosted is returned when a transaction's ledger entries are recorded twice
+	ErrTransactionAlreadyPosted = errors.New("transaction already posted to the ledger")
+
+	// ErrTenantNotFound is returned when a tenant has no settings
+	ErrTenantNotFound = errors.New("tenant not found")
+
+	// ErrTransferLimitExceeded is returned when a transfer exceeds the limit set for the tenant of its source account
+	ErrTransferLimitExceeded = errors.New("transfer exceeds the limit of the tenant")
+
+	// ErrLimitExceeded is returned when a transfer exceeds the per-transaction, daily or monthly outbound limit of its source account
+	ErrLimitExceeded = errors.New("transfer exceeds the outbound limit of the account")
+
+	// ErrVelocityExceeded is returned when a transfer would send more transfers, or more in total, within a sliding window than a velocity rule allows
+	ErrVelocityExceeded = errors.New("transfer exceeds the velocity limits of the account")
+
+	// ErrTenantQuotaExceeded is returned when assigning an account or sending a transfer would take the tenant of the account beyond one of its quotas
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+
+	// ErrCurrencyNotAllowed is returned when the tenant of an account doesn't allow transfers in its currency
+	ErrCurrencyNotAllowed = errors.New("currency is not allowed for the tenant")
+
+	// ErrFXRateUnavailable is returned when a cross-currency transfer has no exchange rate for its currency pair
+	ErrFXRateUnavailable = errors.New("exchange rate unavailable")
+
+	// ErrFeeRuleNotFound is returned when a fee rule doesn't exist
+	ErrFeeRuleNotFound = errors.New("fee rule not found")
+
+	// ErrSplitTransferNotFound is returned when a split transfer doesn't exist
+	ErrSplitTransferNotFound = errors.New("split transfer not found")
+
+	// ErrScheduledTransferNotFound is returned when a scheduled transfer doesn't exist
+	ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+
+	// ErrScheduledTransferResolved is returned when a scheduled transfer was already executed, failed or cancelled
+	ErrScheduledTransferResolved = errors.New("scheduled transfer is already resolved")
+
+	// ErrStandingOrderNotFound is returned when a standing order doesn't exist
+	ErrStandingOrderNotFound = errors.New("standing order not found")
+
+	// ErrOccurrenceScheduled is returned when an occurrence of a standing order is scheduled twice
+	ErrOccurrenceScheduled = errors.New("standing order occurrence is already scheduled")
+
+	// ErrStandingOrderStatus is returned when a standing order can't be paused, resumed or cancelled in its current status
+	ErrStandingOrderStatus = errors.New("standing order status doesn't allow this change")
+
+	// ErrExportThrottled is returned when an export can't start because too many are running
+	ErrExportThrottled = errors.New("too many exports in progress")
+
+	// ErrRateLimited is returned when a client sends requests faster than its rate limit allows
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrArtifactNotFound is returned when no artifact is stored under a key
+	ErrArtifactNotFound = errors.New("artifact not found")
+
+	// ErrApprovalNotFound is returned when a transaction has no approval request
+	ErrApprovalNotFound = errors.New("transfer approval not found")
+
+	// ErrApprovalExists is returned when a transfer already held for approval is held again
+	ErrApprovalExists = errors.New("transfer already has an approval request")
+
+	// ErrApprovalResolved is returned when a transfer was already approved or rejected
+	ErrApprovalResolved = errors.New("transfer approval is already resolved")
+
+	// ErrSelfApproval is returned when the caller that requested a transfer tries to approve or reject it
+	ErrSelfApproval = errors.New("a transfer must be approved by someone other than its requester")
+
+	// ErrFloatAccountNotFound is returned when a currency has no system float account
+	ErrFloatAccountNotFound = errors.New("system float account not found")
+
+	// ErrFloatAccountExists is returned when designating a second float account for a currency
+	ErrFloatAccountExists = errors.New("currency already has a system float account")
+
+	// ErrSystemFloatAccount is returned when an ordinary transfer or change involves a system float
+	// account, which only moves money by funding and defunding
+	ErrSystemFloatAccount = errors.New("account is a system float account")
+
+	// ErrSnapshotExists is returned when an account's balance is snapshotted twice at the same time
+	ErrSnapshotExists = errors.New("balance snapshot already taken")
+
+	// ErrDeadLetterExists is returned when work already given up on is dead-lettered again
+	ErrDeadLetterExists = errors.New("work is already dead-lettered")
+
+	// ErrTransferJobNotFound is returned when an asynchronously submitted transfer job doesn't exist
+	ErrTransferJobNotFound = errors.New("transfer job not found")
+
+	// ErrTransferJobResolved is returned when an operator acts on a transfer job that was already
+	// made or given up on in a way the action doesn't apply to
+	ErrTransferJobResolved = errors.New("transfer job is already resolved")
+
+	// ErrUnauthenticated is returned when a request carries no API key, or one that is unknown or revoked
+	ErrUnauthenticated = errors.New("missing or invalid API key")
+
+	// ErrInsufficientScope is returned when the credential of a request lacks the scope an operation requires
+	ErrInsufficientScope = errors.New("credential lacks the required scope")
+
+	// ErrCredentialNotFound is returned when an API credential doesn't exist
+	ErrCredentialNotFound = errors.New("API credential not found")
+
+	// ErrCredentialExists is returned when issuing a credential under a name already in use
+	ErrCredentialExists = errors.New("an API credential with this name already exists")
+
+	// ErrDelegationNotFound is returned when a delegation doesn't exist
+	ErrDelegationNotFound = errors.New("delegation not found")
+
+	// ErrDelegationDenied is returned when a transfer on behalf of a grantor isn't covered by an
+	// active delegation to the caller
+	ErrDelegationDenied = errors.New("no active delegation covers this transfer")
+
 	// ErrValidationFailed is returned when input validation fails
 	ErrValidationFailed = errors.New("validation failed")
 )
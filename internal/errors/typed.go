@@ -0,0 +1,315 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Error is a domain error carrying structured context about a failed operation.
+// It wraps one of the sentinel errors above, so errors.Is(err, ErrInsufficientBalance)
+// keeps working while callers can still extract the details with errors.As.
+type Error struct {
+	// Err is the sentinel error being wrapped
+	Err error
+
+	// AccountID is the account the error relates to (zero if not applicable)
+	AccountID int64
+
+	// TransactionID is the transaction the error relates to (zero if not applicable)
+	TransactionID int64
+
+	// Amount is the requested amount (nil if not applicable)
+	Amount *decimal.Decimal
+
+	// Available is the balance available to the operation (nil if not applicable)
+	Available *decimal.Decimal
+
+	// Limit is the limit that was breached (nil if not applicable)
+	Limit *decimal.Decimal
+
+	// Currency is the ISO 4217 currency the error relates to (empty if not applicable)
+	Currency string
+
+	// Quota is the tenant quota that was breached (empty if not applicable)
+	Quota string
+}
+
+// Error returns the sentinel message followed by the structured context
+func (e *Error) Error() string {
+	return e.LogString(func(id int64) string { return strconv.FormatInt(id, 10) }, decimal.Decimal.String)
+}
+
+// LogString returns the message of Error with the account ID and amounts written by account and
+// amount, so the log can hash or mask them like the fields of a record
+func (e *Error) LogString(account func(int64) string, amount func(decimal.Decimal) string) string {
+	var parts []string
+	if e.AccountID != 0 {
+		parts = append(parts, "account_id="+account(e.AccountID))
+	}
+	if e.TransactionID != 0 {
+		parts = append(parts, "transaction_id="+strconv.FormatInt(e.TransactionID, 10))
+	}
+	if e.Amount != nil {
+		parts = append(parts, "requested="+amount(*e.Amount))
+	}
+	if e.Available != nil {
+		parts = append(parts, "available="+amount(*e.Available))
+	}
+	if e.Limit != nil {
+		parts = append(parts, "limit="+amount(*e.Limit))
+	}
+	if e.Currency != "" {
+		parts = append(parts, "currency="+e.Currency)
+	}
+	if e.Quota != "" {
+		parts = append(parts, "quota="+e.Quota)
+	}
+	if len(parts) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (%s)", e.Err.Error(), strings.Join(parts, ", "))
+}
+
+// Unwrap returns the wrapped sentinel error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Details returns the structured context as string fields suitable for API responses
+func (e *Error) Details() map[string]string {
+	details := make(map[string]string)
+	if e.AccountID != 0 {
+		details["account_id"] = strconv.FormatInt(e.AccountID, 10)
+	}
+	if e.TransactionID != 0 {
+		details["transaction_id"] = strconv.FormatInt(e.TransactionID, 10)
+	}
+	if e.Amount != nil {
+		details["requested_amount"] = e.Amount.String()
+	}
+	if e.Available != nil {
+		details["available_balance"] = e.Available.String()
+	}
+	if e.Limit != nil {
+		details["limit"] = e.Limit.String()
+	}
+	if e.Currency != "" {
+		details["currency"] = e.Currency
+	}
+	if e.Quota != "" {
+		details["quota"] = e.Quota
+	}
+	return details
+}
+
+// WithAccount attaches an account ID to err, preserving any context it already carries
+func WithAccount(err error, accountID int64) error {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		withAccount := *domainErr
+		withAccount.AccountID = accountID
+		return &withAccount
+	}
+	return &Error{Err: err, AccountID: accountID}
+}
+
+// NewAccountNotFoundError returns ErrAccountNotFound for the given account
+func NewAccountNotFoundError(accountID int64) error {
+	return &Error{Err: ErrAccountNotFound, AccountID: accountID}
+}
+
+// NewSourceAccountNotFoundError returns ErrSourceAccountNotFound for the given account
+func NewSourceAccountNotFoundError(accountID int64) error {
+	return &Error{Err: ErrSourceAccountNotFound, AccountID: accountID}
+}
+
+// NewDestinationAccountNotFoundError returns ErrDestinationAccountNotFound for the given account
+func NewDestinationAccountNotFoundError(accountID int64) error {
+	return &Error{Err: ErrDestinationAccountNotFound, AccountID: accountID}
+}
+
+// NewAccountAlreadyExistsError returns ErrAccountAlreadyExists for the given account
+func NewAccountAlreadyExistsError(accountID int64) error {
+	return &Error{Err: ErrAccountAlreadyExists, AccountID: accountID}
+}
+
+// NewTransactionNotFoundError returns ErrTransactionNotFound for the given transaction
+func NewTransactionNotFoundError(transactionID int64) error {
+	return &Error{Err: ErrTransactionNotFound, TransactionID: transactionID}
+}
+
+// NewAccountNotActiveError returns ErrAccountNotActive for the given account
+func NewAccountNotActiveError(accountID int64) error {
+	return &Error{Err: ErrAccountNotActive, AccountID: accountID}
+}
+
+// NewAccountFrozenError returns ErrAccountFrozen for the given account
+func NewAccountFrozenError(accountID int64) error {
+	return &Error{Err: ErrAccountFrozen, AccountID: accountID}
+}
+
+// NewAccountDormantError returns ErrAccountDormant for the given account
+func NewAccountDormantError(accountID int64) error {
+	return &Error{Err: ErrAccountDormant, AccountID: accountID}
+}
+
+// NewInvalidAmountError returns ErrInvalidAmount for the given amount
+func NewInvalidAmountError(amount decimal.Decimal) error {
+	return &Error{Err: ErrInvalidAmount, Amount: &amount}
+}
+
+// NewAmountPrecisionError returns ErrAmountPrecision for the given amount
+func NewAmountPrecisionError(amount decimal.Decimal) error {
+	return &Error{Err: ErrAmountPrecision, Amount: &amount}
+}
+
+// NewAmountGranularityError returns ErrAmountGranularity for an amount not representable in currency
+func NewAmountGranularityError(amount decimal.Decimal, currency string) error {
+	return &Error{Err: ErrAmountGranularity, Amount: &amount, Currency: currency}
+}
+
+// NewSameAccountError returns ErrSameAccount for the given account
+func NewSameAccountError(accountID int64) error {
+	return &Error{Err: ErrSameAccount, AccountID: accountID}
+}
+
+// NewInsufficientBalanceError returns ErrInsufficientBalance with the requested and available amounts
+func NewInsufficientBalanceError(accountID int64, requested, available decimal.Decimal) error {
+	return &Error{Err: ErrInsufficientBalance, AccountID: accountID, Amount: &requested, Available: &available}
+}
+
+// NewMinimumBalanceError returns ErrMinimumBalance with the requested amount, the current balance and the minimum
+func NewMinimumBalanceError(accountID int64, requested, available, minimum decimal.Decimal) error {
+	return &Error{Err: ErrMinimumBalance, AccountID: accountID, Amount: &requested, Available: &available, Limit: &minimum}
+}
+
+// NewTransferLimitError returns ErrTransferLimitExceeded with the requested amount and the limit
+func NewTransferLimitError(accountID int64, requested, limit decimal.Decimal) error {
+	return &Error{Err: ErrTransferLimitExceeded, AccountID: accountID, Amount: &requested, Limit: &limit}
+}
+
+// NewLimitExceededError returns ErrLimitExceeded with the requested amount, the amount still
+// available within the breached limit and the limit itself
+func NewLimitExceededError(accountID int64, requested, available, limit decimal.Decimal) error {
+	return &Error{Err: ErrLimitExceeded, AccountID: accountID, Amount: &requested, Available: &available, Limit: &limit}
+}
+
+// NewVelocityExceededError returns ErrVelocityExceeded with the requested amount and the count or
+// volume cap of the breached rule
+func NewVelocityExceededError(accountID int64, requested, limit decimal.Decimal) error {
+	return &Error{Err: ErrVelocityExceeded, AccountID: accountID, Amount: &requested, Limit: &limit}
+}
+
+// NewTenantQuotaError returns ErrTenantQuotaExceeded with the breached quota of the tenant of
+// the account and its cap
+func NewTenantQuotaError(accountID int64, quota string, limit int64) error {
+	capped := decimal.NewFromInt(limit)
+	return &Error{Err: ErrTenantQuotaExceeded, AccountID: accountID, Limit: &capped, Quota: quota}
+}
+
+// NewCurrencyNotAllowedError returns ErrCurrencyNotAllowed for the given account and currency
+func NewCurrencyNotAllowedError(accountID int64, currency string) error {
+	return &Error{Err: ErrCurrencyNotAllowed, AccountID: accountID, Currency: currency}
+}
+
+// codes maps sentinel errors to the stable codes exposed in API error responses
+var codes = []struct {
+	err  error
+	code string
+}{
+	{ErrInsufficientBalance, "insufficient_balance"},
+	{ErrSourceAccountNotFound, "source_account_not_found"},
+	{ErrDestinationAccountNotFound, "destination_account_not_found"},
+	{ErrAccountNotFound, "account_not_found"},
+	{ErrAccountAlreadyExists, "account_already_exists"},
+	{ErrTransactionNotFound, "transaction_not_found"},
+	{ErrWebhookNotFound, "webhook_not_found"},
+	{ErrDeliveryNotFound, "delivery_not_found"},
+	{ErrDuplicateIdempotencyKey, "duplicate_idempotency_key"},
+	{ErrDuplicateExternalReference, "duplicate_external_reference"},
+	{ErrIdempotencyConflict, "idempotency_conflict"},
+	{ErrAccountNotActive, "account_not_active"},
+	{ErrAccountDormant, "account_dormant"},
+	{ErrAccountFrozen, "account_frozen"},
+	{ErrAccountStatusConflict, "account_status_conflict"},
+	{ErrMinimumBalance, "minimum_balance_breached"},
+	{ErrSuspenseItemNotFound, "suspense_item_not_found"},
+	{ErrSuspenseItemResolved, "suspense_item_resolved"},
+	{ErrPreAuthorizationNotFound, "preauthorization_not_found"},
+	{ErrPreAuthorizationNotActive, "preauthorization_not_active"},
+	{ErrLedgerAccountNotFound, "ledger_account_not_found"},
+	{ErrLedgerAccountExists, "ledger_account_exists"},
+	{ErrLedgerAccountInactive, "ledger_account_inactive"},
+	{ErrPostingRulesNotFound, "posting_rules_not_found"},
+	{ErrPostingMismatch, "posting_mismatch"},
+	{ErrTransactionAlreadyPosted, "transaction_already_posted"},
+	{ErrTransactionNotReversible, "transaction_not_reversible"},
+	{ErrTenantNotFound, "tenant_not_found"},
+	{ErrTransferLimitExceeded, "transfer_limit_exceeded"},
+	{ErrLimitExceeded, "limit_exceeded"},
+	{ErrVelocityExceeded, "velocity_exceeded"},
+	{ErrTenantQuotaExceeded, "tenant_quota_exceeded"},
+	{ErrCurrencyNotAllowed, "currency_not_allowed"},
+	{ErrFXRateUnavailable, "fx_rate_unavailable"},
+	{ErrFeeRuleNotFound, "fee_rule_not_found"},
+	{ErrSplitTransferNotFound, "split_transfer_not_found"},
+	{ErrScheduledTransferNotFound, "scheduled_transfer_not_found"},
+	{ErrScheduledTransferResolved, "scheduled_transfer_resolved"},
+	{ErrStandingOrderNotFound, "standing_order_not_found"},
+	{ErrStandingOrderStatus, "standing_order_status_conflict"},
+	{ErrOccurrenceScheduled, "occurrence_scheduled"},
+	{ErrExportThrottled, "export_throttled"},
+	{ErrRateLimited, "rate_limited"},
+	{ErrArtifactNotFound, "artifact_not_found"},
+	{ErrApprovalNotFound, "approval_not_found"},
+	{ErrApprovalExists, "approval_exists"},
+	{ErrApprovalResolved, "approval_resolved"},
+	{ErrSelfApproval, "self_approval"},
+	{ErrFloatAccountNotFound, "float_account_not_found"},
+	{ErrFloatAccountExists, "float_account_exists"},
+	{ErrSystemFloatAccount, "system_float_account"},
+	{ErrSnapshotExists, "snapshot_exists"},
+	{ErrDeadLetterExists, "dead_letter_exists"},
+	{ErrTransferJobNotFound, "transfer_job_not_found"},
+	{ErrTransferJobResolved, "transfer_job_resolved"},
+	{ErrUnauthenticated, "unauthenticated"},
+	{ErrInsufficientScope, "insufficient_scope"},
+	{ErrCredentialNotFound, "credential_not_found"},
+	{ErrCredentialExists, "credential_exists"},
+	{ErrDelegationNotFound, "delegation_not_found"},
+	{ErrDelegationDenied, "delegation_denied"},
+	{ErrInvalidAmount, "invalid_amount"},
+	{ErrAmountPrecision, "amount_precision_exceeded"},
+	{ErrAmountGranularity, "amount_granularity"},
+	{ErrSameAccount, "same_account"},
+	{ErrValidationFailed, "validation_failed"},
+	{ErrDuplicateRecord, "duplicate_record"},
+	{ErrReferenceNotFound, "reference_not_found"},
+	{ErrReadOnly, "read_only"},
+	{ErrFenced, "write_fenced"},
+	{ErrJournalUnavailable, "journal_unavailable"},
+	{ErrDatabaseError, "database_error"},
+}
+
+// Code returns the stable error code for err, or "internal_error" if err is not a domain error
+func Code(err error) string {
+	for _, c := range codes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "internal_error"
+}
+
+// Details returns the structured context attached to err, or nil if it carries none
+func Details(err error) map[string]string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Details()
+	}
+	return nil
+}
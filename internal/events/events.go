@@ -0,0 +1,64 @@
+// Package events selects the event bus the outbox relay publishes events to, configured by
+// EVENT_BUS: Kafka or NATS JetStream.
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/kafka"
+	"github.com/khamiruf/internal_transfers_system_go/internal/nats"
+)
+
+// Event buses EVENT_BUS selects
+const (
+	// BusNone publishes events to no bus; webhooks still receive them
+	BusNone = ""
+	// BusKafka publishes events to Kafka topics
+	BusKafka = "kafka"
+	// BusNATS publishes events to a NATS JetStream stream
+	BusNATS = "nats"
+)
+
+// Publisher publishes events to an event bus. It fits outbox.Sink and service.EventPublisher.
+type Publisher interface {
+	Publish(ctx context.Context, event string, payload interface{}) error
+	Close() error
+}
+
+// ValidateBus checks bus names a supported event bus
+func ValidateBus(bus string) error {
+	switch bus {
+	case BusNone, BusKafka, BusNATS:
+		return nil
+	}
+	return fmt.Errorf("%w: EVENT_BUS %q must be kafka, nats or empty", errors.ErrValidationFailed, bus)
+}
+
+// FromConfig creates the publisher of the event bus selected by EVENT_BUS, or returns nil when
+// none is. The NATS stream and its consumers are provisioned before it returns.
+func FromConfig(ctx context.Context, cfg *config.Config) (Publisher, error) {
+	if err := ValidateBus(cfg.EventBus); err != nil {
+		return nil, err
+	}
+	switch cfg.EventBus {
+	case BusKafka:
+		if len(cfg.KafkaBrokers) == 0 {
+			return nil, fmt.Errorf("%w: EVENT_BUS=kafka requires KAFKA_BROKERS", errors.ErrValidationFailed)
+		}
+		return kafka.FromConfig(cfg)
+	case BusNATS:
+		publisher, err := nats.FromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := publisher.Provision(ctx); err != nil {
+			publisher.Close()
+			return nil, err
+		}
+		return publisher, nil
+	}
+	return nil, nil
+}
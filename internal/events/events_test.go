@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromConfig(t *testing.T) {
+	ctx := context.Background()
+
+	publisher, err := FromConfig(ctx, &config.Config{})
+	require.NoError(t, err)
+	assert.Nil(t, publisher)
+
+	_, err = FromConfig(ctx, &config.Config{EventBus: BusKafka, KafkaBrokers: []string{"localhost:9092"}, KafkaTimeout: 1000})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed, "the plaintext producer must be accepted")
+	publisher, err = FromConfig(ctx, &config.Config{EventBus: BusKafka, KafkaBrokers: []string{"localhost:9092"}, KafkaTimeout: 1000, KafkaPlaintext: true})
+	require.NoError(t, err)
+	assert.NotNil(t, publisher)
+	require.NoError(t, publisher.Close())
+
+	_, err = FromConfig(ctx, &config.Config{EventBus: BusKafka})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = FromConfig(ctx, &config.Config{EventBus: "rabbitmq"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = FromConfig(ctx, &config.Config{EventBus: BusNATS, NATSStream: "TRANS FERS", NATSSubjectPrefix: "transfers"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
@@ -0,0 +1,74 @@
+// Package export paces bulk exports of transaction history so they can't starve the OLTP workload
+// of the database they read from
+package export
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Throttle bounds the load of all exports together: at most maxConcurrent export chunks run at
+// once, and between them they read at most rowsPerSecond rows. A nil *Throttle doesn't throttle.
+type Throttle struct {
+	slots   chan struct{}
+	perRow  time.Duration // zero leaves reads unpaced
+	mu      sync.Mutex
+	nextRow time.Time // when the next reservation of rows may start
+}
+
+// NewThrottle creates a throttle; rowsPerSecond <= 0 leaves reads unpaced
+func NewThrottle(rowsPerSecond, maxConcurrent int) *Throttle {
+	t := &Throttle{slots: make(chan struct{}, max(maxConcurrent, 1))}
+	if rowsPerSecond > 0 {
+		t.perRow = time.Second / time.Duration(rowsPerSecond)
+	}
+	return t
+}
+
+// Acquire takes a slot for one export chunk, or fails with ErrExportThrottled if every slot is
+// taken. The caller must call release when the chunk is done.
+func (t *Throttle) Acquire() (release func(), err error) {
+	if t == nil {
+		return func() {}, nil
+	}
+	select {
+	case t.slots <- struct{}{}:
+		return func() { <-t.slots }, nil
+	default:
+		return nil, fmt.Errorf("%w: %d exports are already running", errors.ErrExportThrottled, cap(t.slots))
+	}
+}
+
+// Wait blocks until reading rows more rows keeps all exports within the rate, or ctx is done.
+// Reservations are made in call order, so concurrent exports share the rate evenly.
+func (t *Throttle) Wait(ctx context.Context, rows int) error {
+	if t == nil || t.perRow == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	start := t.nextRow
+	if start.Before(now) {
+		start = now
+	}
+	t.nextRow = start.Add(time.Duration(rows) * t.perRow)
+	t.mu.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
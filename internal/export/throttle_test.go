@@ -0,0 +1,50 @@
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle_Acquire(t *testing.T) {
+	throttle := NewThrottle(0, 2)
+
+	first, err := throttle.Acquire()
+	require.NoError(t, err)
+	_, err = throttle.Acquire()
+	require.NoError(t, err)
+	_, err = throttle.Acquire()
+	assert.ErrorIs(t, err, errors.ErrExportThrottled)
+
+	first()
+	_, err = throttle.Acquire()
+	assert.NoError(t, err)
+}
+
+func TestThrottle_Wait(t *testing.T) {
+	throttle := NewThrottle(1000, 1)
+	ctx := context.Background()
+
+	// The first reservation starts right away; the next waits for the rows of the first
+	start := time.Now()
+	require.NoError(t, throttle.Wait(ctx, 100))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	require.NoError(t, throttle.Wait(ctx, 100))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, throttle.Wait(cancelled, 100), context.Canceled)
+}
+
+func TestThrottle_Nil(t *testing.T) {
+	var throttle *Throttle
+	release, err := throttle.Acquire()
+	require.NoError(t, err)
+	release()
+	assert.NoError(t, throttle.Wait(context.Background(), 1_000_000))
+}
@@ -0,0 +1,86 @@
+// Package fx converts amounts between currencies for cross-currency transfers. Rates come from a
+// RateProvider; StaticRates is the default, backed by rates from configuration.
+package fx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// RateScale is the number of decimal places a rate is kept to, matching transactions.fx_rate
+const RateScale = 12
+
+// RateProvider quotes exchange rates
+type RateProvider interface {
+	// Rate returns the units of to bought by one unit of from, or an error wrapping
+	// errors.ErrFXRateUnavailable if the pair isn't quoted
+	Rate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// pair is a currency pair, quoted as units of to per unit of from
+type pair struct {
+	from, to string
+}
+
+// StaticRates is a RateProvider with a fixed set of rates. A pair quoted in one direction is
+// also quoted in the other at the inverse rate.
+type StaticRates struct {
+	rates map[pair]decimal.Decimal
+}
+
+// NewStaticRates creates a provider from rates given as decimal strings keyed by "FROM/TO", e.g.
+// {"USD/SGD": "1.35"} for 1.35 SGD per USD
+func NewStaticRates(raw map[string]string) (*StaticRates, error) {
+	rates := make(map[pair]decimal.Decimal, len(raw))
+	for key, value := range raw {
+		from, to, ok := strings.Cut(key, "/")
+		if !ok || from == to {
+			return nil, fmt.Errorf("%w: invalid currency pair %q, expected FROM/TO", errors.ErrValidationFailed, key)
+		}
+		if err := models.ValidateCurrency(from); err != nil {
+			return nil, err
+		}
+		if err := models.ValidateCurrency(to); err != nil {
+			return nil, err
+		}
+		rate, err := decimal.NewFromString(value)
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("%w: rate %q of %s must be a positive decimal", errors.ErrValidationFailed, value, key)
+		}
+		rates[pair{from, to}] = rate
+	}
+	return &StaticRates{rates: rates}, nil
+}
+
+// Rate returns the configured rate of the pair, or the inverse of the rate of the opposite pair
+func (r *StaticRates) Rate(ctx context.Context, from, to string) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := r.rates[pair{from, to}]; ok {
+		return rate, nil
+	}
+	if rate, ok := r.rates[pair{to, from}]; ok {
+		return Inverse(rate), nil
+	}
+	return decimal.Zero, fmt.Errorf("%w: no rate from %s to %s", errors.ErrFXRateUnavailable, from, to)
+}
+
+// Inverse returns the rate of the opposite pair, rounded to RateScale
+func Inverse(rate decimal.Decimal) decimal.Decimal {
+	return decimal.NewFromInt(1).DivRound(rate, RateScale)
+}
+
+// Convert converts amount at rate to currency, rounding half to even to the currency's minor unit
+func Convert(amount, rate decimal.Decimal, currency string) decimal.Decimal {
+	converted := amount.Mul(rate)
+	if exponent, ok := models.CurrencyExponent(currency); ok {
+		return converted.RoundBank(exponent)
+	}
+	return converted.RoundBank(models.AmountScale())
+}
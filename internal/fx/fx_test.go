@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRates_Rate(t *testing.T) {
+	ctx := context.Background()
+	rates, err := NewStaticRates(map[string]string{"USD/SGD": "1.35", "EUR/USD": "1.08"})
+	require.NoError(t, err)
+
+	rate, err := rates.Rate(ctx, "USD", "SGD")
+	require.NoError(t, err)
+	assert.Equal(t, "1.35", rate.String())
+
+	// The opposite pair is quoted at the inverse rate
+	rate, err = rates.Rate(ctx, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "0.740740740741", rate.String())
+
+	rate, err = rates.Rate(ctx, "JPY", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, "1", rate.String())
+
+	_, err = rates.Rate(ctx, "EUR", "SGD")
+	assert.ErrorIs(t, err, errors.ErrFXRateUnavailable)
+}
+
+func TestNewStaticRates_Invalid(t *testing.T) {
+	for _, raw := range []map[string]string{
+		{"USDSGD": "1.35"},
+		{"USD/USD": "1"},
+		{"USD/XXX": "1.35"},
+		{"USD/SGD": "abc"},
+		{"USD/SGD": "0"},
+		{"USD/SGD": "-1.35"},
+	} {
+		_, err := NewStaticRates(raw)
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, "%v", raw)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount, rate, currency, want string
+	}{
+		{"100", "1.35", "SGD", "135"},
+		{"10.01", "1.35", "SGD", "13.51"},   // 13.5135 rounds down
+		{"0.10", "0.25", "USD", "0.02"},     // 0.025 rounds half to even
+		{"0.30", "0.25", "USD", "0.08"},     // 0.075 rounds half to even
+		{"12.34", "149.876", "JPY", "1849"}, // no minor unit
+		{"5", "0.376", "KWD", "1.88"},
+	}
+	for _, tt := range tests {
+		got := Convert(decimal.RequireFromString(tt.amount), decimal.RequireFromString(tt.rate), tt.currency)
+		assert.Equal(t, tt.want, got.String(), "%s at %s to %s", tt.amount, tt.rate, tt.currency)
+	}
+}
@@ -0,0 +1,38 @@
+// Package idempotency carries the client's Idempotency-Key from the HTTP request to the service
+// that makes the transfer, so a retried request is answered with the stored response instead of
+// moving money twice.
+package idempotency
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header carries the client's idempotency key
+const Header = "Idempotency-Key"
+
+type contextKey struct{}
+
+// WithKey returns a copy of ctx carrying an idempotency key; an empty key leaves ctx unchanged
+func WithKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// KeyFromContext returns the idempotency key carried by ctx, or "" if the request has none
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(contextKey{}).(string)
+	return key
+}
+
+// Middleware copies the Idempotency-Key header of each request into its context
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(Header); key != "" {
+			r = r.WithContext(WithKey(r.Context(), key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = KeyFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(Header, "retry-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "retry-1", seen)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/transactions", nil))
+	assert.Empty(t, seen)
+}
+
+func TestWithKey(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, KeyFromContext(ctx))
+	assert.Equal(t, ctx, WithKey(ctx, ""))
+	assert.Equal(t, "k", KeyFromContext(WithKey(ctx, "k")))
+}
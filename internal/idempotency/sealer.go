@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealVersion prefixes every sealed payload, so the format can change without misreading old ones
+const sealVersion byte = 1
+
+// ErrUnsealable is returned when a sealed payload was altered, moved to another record, or sealed
+// with a key the sealer doesn't have
+var ErrUnsealable = errors.New("stored response can't be unsealed")
+
+// Sealer encrypts stored responses with AES-256-GCM. The GCM tag authenticates the ciphertext
+// together with associated data naming the record it belongs to, so a payload that was altered or
+// copied to another record fails to open instead of being replayed. Payloads record the ID of the
+// key that sealed them: new payloads use the active key while payloads sealed with older keys
+// still open, so keys can be rotated without losing stored responses.
+type Sealer struct {
+	aeads  map[string]cipher.AEAD
+	active string
+}
+
+// ParseKeys decodes the sealing keys, e.g. from {"2024-06": "<base64 of 32 random bytes>"}
+func ParseKeys(raw map[string]string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(raw))
+	for id, encoded := range raw {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("idempotency key %s is not base64: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// NewSealer creates a sealer that seals with the key active and opens with any of keys. Keys are
+// 32 bytes (AES-256) and their IDs at most 255 bytes.
+func NewSealer(keys map[string][]byte, active string) (*Sealer, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active idempotency key %q is not among the configured keys", active)
+	}
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("idempotency key ID %q must be 1-255 bytes", id)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("idempotency key %s must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[id] = aead
+	}
+	return &Sealer{aeads: aeads, active: active}, nil
+}
+
+// Seal encrypts plaintext with the active key, binding it to associatedData
+func (s *Sealer) Seal(plaintext, associatedData []byte) ([]byte, error) {
+	aead := s.aeads[s.active]
+	sealed := make([]byte, 0, 2+len(s.active)+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	sealed = append(sealed, sealVersion, byte(len(s.active)))
+	sealed = append(sealed, s.active...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, plaintext, associatedData), nil
+}
+
+// Open decrypts a payload sealed with associatedData; ErrUnsealable if it doesn't authenticate
+func (s *Sealer) Open(sealed, associatedData []byte) ([]byte, error) {
+	if len(sealed) < 2 || sealed[0] != sealVersion {
+		return nil, fmt.Errorf("%w: unknown format", ErrUnsealable)
+	}
+	idLength := int(sealed[1])
+	if len(sealed) < 2+idLength {
+		return nil, fmt.Errorf("%w: truncated", ErrUnsealable)
+	}
+	id := string(sealed[2 : 2+idLength])
+	aead, ok := s.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: sealed with unknown key %q", ErrUnsealable, id)
+	}
+	rest := sealed[2+idLength:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: truncated", ErrUnsealable)
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: authentication failed", ErrUnsealable)
+	}
+	return plaintext, nil
+}
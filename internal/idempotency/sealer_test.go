@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestSealer_RoundTrip(t *testing.T) {
+	sealer, err := NewSealer(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+
+	plaintext := []byte(`{"id":1,"amount":"10.5"}`)
+	sealed, err := sealer.Seal(plaintext, []byte("retry-1"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "amount")
+
+	opened, err := sealer.Open(sealed, []byte("retry-1"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Sealing twice gives different ciphertexts of the same payload
+	again, err := sealer.Seal(plaintext, []byte("retry-1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+}
+
+func TestSealer_RejectsTampering(t *testing.T) {
+	sealer, err := NewSealer(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	sealed, err := sealer.Seal([]byte(`{"id":1}`), []byte("retry-1"))
+	require.NoError(t, err)
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+	_, err = sealer.Open(flipped, []byte("retry-1"))
+	assert.ErrorIs(t, err, ErrUnsealable)
+
+	// A payload copied to another record doesn't open there
+	_, err = sealer.Open(sealed, []byte("retry-2"))
+	assert.ErrorIs(t, err, ErrUnsealable)
+
+	for _, malformed := range [][]byte{nil, {2, 0}, {1, 10, 'k'}, sealed[:6]} {
+		_, err = sealer.Open(malformed, []byte("retry-1"))
+		assert.ErrorIs(t, err, ErrUnsealable)
+	}
+}
+
+func TestSealer_KeyRotation(t *testing.T) {
+	old, err := NewSealer(map[string][]byte{"k1": testKey(1)}, "k1")
+	require.NoError(t, err)
+	sealed, err := old.Seal([]byte(`{"id":1}`), nil)
+	require.NoError(t, err)
+
+	rotated, err := NewSealer(map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	opened, err := rotated.Open(sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, string(opened))
+
+	// Once the old key is retired its payloads no longer open
+	retired, err := NewSealer(map[string][]byte{"k2": testKey(2)}, "k2")
+	require.NoError(t, err)
+	_, err = retired.Open(sealed, nil)
+	assert.ErrorIs(t, err, ErrUnsealable)
+}
+
+func TestNewSealer_Validation(t *testing.T) {
+	_, err := NewSealer(map[string][]byte{"k1": testKey(1)}, "k2")
+	assert.Error(t, err)
+	_, err = NewSealer(map[string][]byte{"k1": testKey(1)[:16]}, "k1")
+	assert.Error(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys(map[string]string{"k1": base64.StdEncoding.EncodeToString(testKey(7))})
+	require.NoError(t, err)
+	assert.Equal(t, testKey(7), keys["k1"])
+
+	_, err = ParseKeys(map[string]string{"k1": "not base64!"})
+	assert.Error(t, err)
+}
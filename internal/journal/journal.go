@@ -0,0 +1,96 @@
+// Package journal records every accepted mutating API request, before it runs, in an
+// append-only file, so the requests made since the last backup can be replayed onto a restored
+// database after a failure. Each request is given an idempotency key if it has none, so replaying
+// one whose effects survived in the backup is answered from its idempotency record instead of
+// being applied twice.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxEntrySize is the longest journal line Read accepts
+const maxEntrySize = 16 << 20
+
+// Entry is one journaled request
+type Entry struct {
+	Time   time.Time         `json:"time"`
+	Method string            `json:"method"`
+	URI    string            `json:"uri"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body,omitempty"`
+}
+
+// Journal appends entries to a file, one JSON object per line. Every entry is synced to disk
+// before Append returns, so a request is never executed without being journaled.
+type Journal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// Open opens the journal at path for appending, creating it if it doesn't exist
+func Open(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open request journal %s: %w", path, err)
+	}
+	return &Journal{f: f}, nil
+}
+
+// Append writes entry to the journal and syncs it to disk
+func (j *Journal) Append(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.f.Write(line); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.f.Close()
+}
+
+// Read calls fn with every entry of a journal in the order they were appended, stopping at the
+// first error. A torn last line, left by a crash while it was written, is ignored.
+func Read(r io.Reader, fn func(*Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEntrySize)
+	var pending []byte
+	line := 0
+	for scanner.Scan() {
+		if pending != nil {
+			return fmt.Errorf("invalid journal entry on line %d", line)
+		}
+		line++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			pending = append([]byte{}, scanner.Bytes()...)
+			continue
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,155 @@
+package journal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, path string) []*Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []*Entry
+	require.NoError(t, Read(f, func(entry *Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	return entries
+}
+
+func TestMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	j, err := Open(path)
+	require.NoError(t, err)
+	defer j.Close()
+
+	var seenKey, seenBody string
+	handler := Middleware(j)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenKey = r.Header.Get(idempotency.Header)
+		body, _ := io.ReadAll(r.Body)
+		seenBody = string(body)
+	}))
+
+	// A write without a key is given one, which the handler and the journal share
+	req := httptest.NewRequest(http.MethodPost, "/transactions?dry_run=false", strings.NewReader(`{"amount":"10"}`))
+	req.Header.Set(actor.Header, "ops:alice")
+	req.Header.Set("Authorization", "Bearer itk_secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, strings.HasPrefix(seenKey, keyPrefix))
+	assert.Equal(t, `{"amount":"10"}`, seenBody)
+
+	// A client's key is kept, with the owner of its keys
+	req = httptest.NewRequest(http.MethodPut, "/accounts/1/owner", strings.NewReader(`{"owner_ref":"cust-1"}`))
+	req = req.WithContext(auth.WithCredential(req.Context(), &auth.Credential{ID: 7, Name: "payroll"}))
+	req.Header.Set(idempotency.Header, "client-key")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "client-key", seenKey)
+
+	// Reads aren't journaled
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/1", nil))
+
+	entries := readAll(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, http.MethodPost, entries[0].Method)
+	assert.Equal(t, "/transactions?dry_run=false", entries[0].URI)
+	assert.Equal(t, `{"amount":"10"}`, string(entries[0].Body))
+	assert.Equal(t, "ops:alice", entries[0].Header[actor.Header])
+	assert.NotEqual(t, "", entries[0].Header[idempotency.Header])
+	assert.NotContains(t, entries[0].Header, "Authorization")
+	assert.NotContains(t, entries[0].Header, auth.OwnerHeader)
+	assert.Equal(t, "client-key", entries[1].Header[idempotency.Header])
+	assert.Equal(t, "7", entries[1].Header[auth.OwnerHeader])
+}
+
+func TestMiddleware_Unavailable(t *testing.T) {
+	j, err := Open(filepath.Join(t.TempDir(), "journal.log"))
+	require.NoError(t, err)
+	require.NoError(t, j.Close())
+
+	called := false
+	handler := Middleware(j)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.False(t, called)
+}
+
+func TestRead_TornLastLine(t *testing.T) {
+	journal := `{"time":"2024-06-01T00:00:00Z","method":"POST","uri":"/a"}` + "\n" + `{"time":"2024-06-01T00:00:01Z","meth`
+	var uris []string
+	require.NoError(t, Read(strings.NewReader(journal), func(entry *Entry) error {
+		uris = append(uris, entry.URI)
+		return nil
+	}))
+	assert.Equal(t, []string{"/a"}, uris)
+
+	corrupt := `{"time":` + "\n" + `{"time":"2024-06-01T00:00:00Z","method":"POST","uri":"/a"}` + "\n"
+	assert.Error(t, Read(strings.NewReader(corrupt), func(*Entry) error { return nil }))
+}
+
+func TestReplayer(t *testing.T) {
+	var received []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Method+" "+r.URL.RequestURI()+" "+string(body)+" "+r.Header.Get(idempotency.Header)+" "+r.Header.Get("Authorization"))
+		if r.URL.Path == "/accounts" {
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer target.Close()
+
+	backup := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "journal.log")
+	j, err := Open(path)
+	require.NoError(t, err)
+	for _, entry := range []*Entry{
+		{Time: backup.Add(-time.Minute), Method: http.MethodPost, URI: "/transactions", Body: []byte(`{"amount":"1"}`), Header: map[string]string{idempotency.Header: "k1"}},
+		{Time: backup.Add(time.Minute), Method: http.MethodPost, URI: "/accounts", Body: []byte(`{"account_id":1}`), Header: map[string]string{idempotency.Header: "k2"}},
+		{Time: backup.Add(2 * time.Minute), Method: http.MethodPost, URI: "/transactions", Body: []byte(`{"amount":"2"}`), Header: map[string]string{idempotency.Header: "k3"}},
+	} {
+		require.NoError(t, j.Append(entry))
+	}
+	require.NoError(t, j.Close())
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	replayer := &Replayer{Target: target.URL + "/", Since: backup, Header: http.Header{"Authorization": {"Bearer itk_admin"}}}
+	result, err := replayer.Replay(context.Background(), f)
+	require.NoError(t, err)
+	assert.Equal(t, ReplayResult{Replayed: 1, Rejected: 1, Skipped: 1}, result)
+	assert.Equal(t, []string{
+		`POST /accounts {"account_id":1} k2 Bearer itk_admin`,
+		`POST /transactions {"amount":"2"} k3 Bearer itk_admin`,
+	}, received)
+}
+
+func TestReplayer_StopsOnServerError(t *testing.T) {
+	calls := 0
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	journal := `{"time":"2024-06-01T00:00:00Z","method":"POST","uri":"/a"}` + "\n" + `{"time":"2024-06-01T00:00:01Z","method":"POST","uri":"/b"}` + "\n"
+	result, err := (&Replayer{Target: target.URL}).Replay(context.Background(), strings.NewReader(journal))
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Zero(t, result.Replayed)
+}
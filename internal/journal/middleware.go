@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// keyPrefix marks the idempotency keys given to requests that came without one
+const keyPrefix = "journal-"
+
+// journaledHeaders are the request headers replayed with an entry. Authorization isn't kept: a
+// replay authenticates with the operator's own key.
+var journaledHeaders = []string{"Content-Type", idempotency.Header, actor.OnBehalfOfHeader}
+
+// Middleware journals every POST, PUT, PATCH and DELETE request before passing it on, and
+// rejects it with 503 journal_unavailable if it can't be journaled. A request without an
+// Idempotency-Key header is given one, which is journaled with it. List it after auth, so only
+// accepted requests are journaled, and before idempotency and actor.
+func Middleware(j *Journal) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				response.Error(w, fmt.Errorf("%w: unreadable request body", errors.ErrValidationFailed))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if r.Header.Get(idempotency.Header) == "" {
+				key, err := newKey()
+				if err != nil {
+					logger.Default().ErrorContext(r.Context(), "Failed to generate idempotency key for journal", "err", err)
+					response.Error(w, errors.ErrJournalUnavailable)
+					return
+				}
+				r.Header.Set(idempotency.Header, key)
+			}
+
+			// The actor is the credential's name under auth, else the X-Actor header
+			who := actor.FromContext(r.Context())
+			if who == actor.System && r.Header.Get(actor.Header) != "" {
+				who = r.Header.Get(actor.Header)
+			}
+			entry := &Entry{
+				Time:   time.Now().UTC(),
+				Method: r.Method,
+				URI:    r.URL.RequestURI(),
+				Header: map[string]string{actor.Header: who},
+				Body:   body,
+			}
+			for _, name := range journaledHeaders {
+				if v := r.Header.Get(name); v != "" {
+					entry.Header[name] = v
+				}
+			}
+			// The replay authenticates as an admin, which keeps the keys of the original caller
+			if owner := auth.Owner(r.Context()); owner != "" {
+				entry.Header[auth.OwnerHeader] = owner
+			}
+			if err := j.Append(entry); err != nil {
+				logger.Default().ErrorContext(r.Context(), "Failed to journal", "method", r.Method, "path", r.URL.Path, "err", err)
+				response.Error(w, errors.ErrJournalUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// newKey returns a random idempotency key for a request that came without one
+func newKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b[:]), nil
+}
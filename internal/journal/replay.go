@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// ReplayResult counts the entries of a replay
+type ReplayResult struct {
+	// Replayed entries were accepted by the target
+	Replayed int
+	// Rejected entries were answered with a 4xx, e.g. an account that already exists
+	Rejected int
+	// Skipped entries were journaled before the replay's starting time
+	Skipped int
+}
+
+// Replayer sends journaled requests to a running instance, in journal order
+type Replayer struct {
+	// Client sends the requests; http.DefaultClient if nil
+	Client *http.Client
+	// Target is the base URL of the instance, e.g. http://localhost:8080
+	Target string
+	// Header is added to every request, e.g. the Authorization of the operator's credential
+	Header http.Header
+	// Since skips the entries journaled before it, such as those already in the restored backup
+	Since time.Time
+	// Logger logs the rejected entries, the package-level logger if nil
+	Logger *slog.Logger
+}
+
+// Replay sends every entry of the journal read from r. A 4xx response is counted as rejected and
+// the replay moves on, since the original request was most likely rejected the same way; a 5xx
+// response or a failed request stops it, so later requests aren't applied out of order.
+func (p *Replayer) Replay(ctx context.Context, r io.Reader) (ReplayResult, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	target := strings.TrimRight(p.Target, "/")
+
+	var result ReplayResult
+	err := Read(r, func(entry *Entry) error {
+		if entry.Time.Before(p.Since) {
+			result.Skipped++
+			return nil
+		}
+
+		req, err := http.NewRequestWithContext(ctx, entry.Method, target+entry.URI, bytes.NewReader(entry.Body))
+		if err != nil {
+			return fmt.Errorf("invalid journal entry %s %s: %w", entry.Method, entry.URI, err)
+		}
+		for name, value := range entry.Header {
+			req.Header.Set(name, value)
+		}
+		for name, values := range p.Header {
+			req.Header[name] = values
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to replay %s %s from %s: %w", entry.Method, entry.URI, entry.Time.Format(time.RFC3339), err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 500:
+			return fmt.Errorf("replaying %s %s from %s failed with %s", entry.Method, entry.URI, entry.Time.Format(time.RFC3339), resp.Status)
+		case resp.StatusCode >= 400:
+			p.logger().Warn("Replayed was rejected", "method", entry.Method, "uri", entry.URI, "time", entry.Time.Format(time.RFC3339), "status", resp.Status)
+			result.Rejected++
+		default:
+			result.Replayed++
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (p *Replayer) logger() *slog.Logger {
+	if p.Logger != nil {
+		return p.Logger
+	}
+	return logger.Default()
+}
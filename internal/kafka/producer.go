@@ -0,0 +1,364 @@
+// Package kafka publishes events to Apache Kafka. It speaks the part of the Kafka protocol a
+// producer needs, metadata requests to find partition leaders and produce requests, over plain
+// TCP. Messages are acknowledged by all in-sync replicas.
+//
+// The producer is plaintext only: it has no TLS and no SASL, so brokers must accept
+// unauthenticated connections on a network trusted with the events. FromConfig refuses to
+// create it unless KAFKA_PLAINTEXT acknowledges that. It stands in for franz-go or sarama, which
+// can't be built here: both need github.com/pierrec/lz4, which isn't available to the build.
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// Config configures a producer
+type Config struct {
+	// Brokers are the "host:port" addresses the cluster metadata is bootstrapped from
+	Brokers []string
+	// ClientID identifies the producer in broker logs and quotas
+	ClientID string
+	// Timeout bounds each request, including the wait for replicas to acknowledge a message
+	Timeout time.Duration
+}
+
+// maxResponseSize bounds the responses read; the producer's responses are small, so anything
+// larger means the stream is out of sync
+const maxResponseSize = 1 << 20
+
+// BrokerError is an error code returned by a broker
+type BrokerError struct {
+	Code int16
+}
+
+func (e *BrokerError) Error() string {
+	return fmt.Sprintf("kafka: broker returned error code %d", e.Code)
+}
+
+// retriable reports whether the error is cleared by refreshing the partition leaders
+func (e *BrokerError) retriable() bool {
+	switch e.Code {
+	case errUnknownTopicOrPartition, errLeaderNotAvailable, errNotLeaderOrFollower:
+		return true
+	}
+	return false
+}
+
+// Producer produces messages to the partition leaders of a Kafka cluster. It keeps a connection
+// per broker, on which requests are made one at a time, and caches the partition leaders of each
+// topic until a produce request fails. It is safe for concurrent use.
+type Producer struct {
+	cfg  Config
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+	now  func() time.Time
+
+	mu      sync.Mutex
+	conns   map[string]*brokerConn
+	leaders map[string][]string // addresses of the partition leaders by topic, by partition
+	log     *slog.Logger
+}
+
+// NewProducer creates a producer; connections are opened on first use
+func NewProducer(cfg Config) *Producer {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	return &Producer{
+		cfg:     cfg,
+		dial:    dialer.DialContext,
+		now:     time.Now,
+		conns:   make(map[string]*brokerConn),
+		leaders: make(map[string][]string),
+		log:     logger.Default(),
+	}
+}
+
+// Produce appends message to topic and returns the partition and offset it was written at.
+// Messages with the same key go to the same partition, so their order is kept. A message
+// refused because the partition leaders changed is retried once with fresh metadata.
+func (p *Producer) Produce(ctx context.Context, topic string, message Message) (int32, int64, error) {
+	for attempt := 0; ; attempt++ {
+		leaders, err := p.partitionLeaders(ctx, topic, attempt > 0)
+		if err != nil {
+			return 0, 0, err
+		}
+		partition := partitionFor(message.Key, len(leaders))
+		offset, err := p.produce(ctx, leaders[partition], topic, partition, message)
+		if err == nil {
+			return partition, offset, nil
+		}
+		var brokerErr *BrokerError
+		if attempt > 0 || ctx.Err() != nil || (errors.As(err, &brokerErr) && !brokerErr.retriable()) {
+			return 0, 0, fmt.Errorf("kafka: failed to produce to %s/%d: %w", topic, partition, err)
+		}
+		p.log.Warn("Producing to partition failed, refreshing metadata", "topic", topic, "partition", partition, "err", err)
+	}
+}
+
+// Close closes the connections to the brokers
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for address, conn := range p.conns {
+		conn.close()
+		delete(p.conns, address)
+	}
+	return nil
+}
+
+// partitionFor picks the partition of a message: by the FNV-1a hash of its key, the way the
+// common clients' hash partitioners do, or the first partition for a message without key
+func partitionFor(key []byte, partitions int) int32 {
+	if key == nil {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write(key)
+	return int32(h.Sum32() % uint32(partitions))
+}
+
+// produce writes message to partition of topic on its leader and returns its offset
+func (p *Producer) produce(ctx context.Context, address, topic string, partition int32, message Message) (int64, error) {
+	req := &encoder{}
+	req.nullableString("") // transactional ID
+	req.int16(-1)          // acks from all in-sync replicas
+	req.int32(int32(p.cfg.Timeout.Milliseconds()))
+	req.int32(1)
+	req.string(topic)
+	req.int32(1)
+	req.int32(partition)
+	req.bytes(encodeRecordBatch([]Message{message}, p.now().UnixMilli()))
+
+	resp, err := p.roundTrip(ctx, address, apiKeyProduce, produceVersion, req.buf)
+	if err != nil {
+		return 0, err
+	}
+
+	d := &decoder{buf: resp}
+	code, offset, found := errNone, int64(0), false
+	for i, topics := 0, d.arrayLen(); i < topics; i++ {
+		name := d.string()
+		for j, partitions := 0, d.arrayLen(); j < partitions; j++ {
+			index, partitionCode, baseOffset := d.int32(), d.int16(), d.int64()
+			d.int64() // log append time
+			if name == topic && index == partition {
+				code, offset, found = partitionCode, baseOffset, true
+			}
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	if !found {
+		return 0, fmt.Errorf("kafka: produce response is missing %s/%d", topic, partition)
+	}
+	if code != errNone {
+		if (&BrokerError{Code: code}).retriable() {
+			p.forgetLeaders(topic)
+		}
+		return 0, &BrokerError{Code: code}
+	}
+	return offset, nil
+}
+
+// partitionLeaders returns the addresses of the partition leaders of topic, from cache unless
+// refresh is set or they aren't known yet
+func (p *Producer) partitionLeaders(ctx context.Context, topic string, refresh bool) ([]string, error) {
+	p.mu.Lock()
+	leaders, ok := p.leaders[topic]
+	p.mu.Unlock()
+	if ok && !refresh {
+		return leaders, nil
+	}
+
+	var lastErr error
+	for _, broker := range p.cfg.Brokers {
+		leaders, lastErr = p.fetchLeaders(ctx, broker, topic)
+		if lastErr == nil {
+			p.mu.Lock()
+			p.leaders[topic] = leaders
+			p.mu.Unlock()
+			return leaders, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("kafka: failed to fetch metadata of topic %s: %w", topic, lastErr)
+}
+
+// forgetLeaders drops the cached partition leaders of topic
+func (p *Producer) forgetLeaders(topic string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.leaders, topic)
+}
+
+// fetchLeaders asks broker for the partition leaders of topic
+func (p *Producer) fetchLeaders(ctx context.Context, broker, topic string) ([]string, error) {
+	req := &encoder{}
+	req.int32(1)
+	req.string(topic)
+	resp, err := p.roundTrip(ctx, broker, apiKeyMetadata, metadataVersion, req.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{buf: resp}
+	brokers := make(map[int32]string)
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		nodeID, host, port := d.int32(), d.string(), d.int32()
+		d.string() // rack
+		brokers[nodeID] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32() // controller ID
+
+	var leaders []string
+	topicCode := errUnknownTopicOrPartition
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		code, name := d.int16(), d.string()
+		d.int8() // is internal
+		partitions := make(map[int32]string)
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			d.int16() // partition error, reflected by a missing leader
+			index, leader := d.int32(), d.int32()
+			for k, replicas := 0, d.arrayLen(); k < replicas; k++ {
+				d.int32()
+			}
+			for k, isr := 0, d.arrayLen(); k < isr; k++ {
+				d.int32()
+			}
+			partitions[index] = brokers[leader]
+		}
+		if name != topic {
+			continue
+		}
+		topicCode = code
+		leaders = make([]string, len(partitions))
+		for index, address := range partitions {
+			if index < 0 || int(index) >= len(leaders) {
+				return nil, fmt.Errorf("kafka: metadata of topic %s skips partitions", topic)
+			}
+			leaders[index] = address
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if topicCode != errNone {
+		return nil, &BrokerError{Code: topicCode}
+	}
+	for _, address := range leaders {
+		if address == "" {
+			return nil, &BrokerError{Code: errLeaderNotAvailable}
+		}
+	}
+	if len(leaders) == 0 {
+		return nil, fmt.Errorf("kafka: topic %s has no partitions", topic)
+	}
+	return leaders, nil
+}
+
+// roundTrip sends a request to the broker at address and returns the response body. A
+// connection that failed is closed and reopened by the next request.
+func (p *Producer) roundTrip(ctx context.Context, address string, apiKey, version int16, body []byte) ([]byte, error) {
+	conn, err := p.conn(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := conn.roundTrip(ctx, p.cfg.ClientID, apiKey, version, body, p.now().Add(p.cfg.Timeout))
+	if err != nil {
+		p.mu.Lock()
+		if p.conns[address] == conn {
+			delete(p.conns, address)
+		}
+		p.mu.Unlock()
+		conn.close()
+		return nil, fmt.Errorf("kafka: request to %s failed: %w", address, err)
+	}
+	return resp, nil
+}
+
+// conn returns the connection to the broker at address, opening it if needed
+func (p *Producer) conn(ctx context.Context, address string) (*brokerConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if conn, ok := p.conns[address]; ok {
+		return conn, nil
+	}
+	netConn, err := p.dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: failed to connect to %s: %w", address, err)
+	}
+	conn := &brokerConn{conn: netConn}
+	p.conns[address] = conn
+	return conn, nil
+}
+
+// brokerConn is a connection to a broker carrying one request at a time
+type brokerConn struct {
+	mu            sync.Mutex
+	conn          net.Conn
+	correlationID int32
+}
+
+// roundTrip writes a request and reads its response, both before deadline or ctx is done
+func (c *brokerConn) roundTrip(ctx context.Context, clientID string, apiKey, version int16, body []byte, deadline time.Time) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c.correlationID++
+	req := &encoder{}
+	req.int32(0) // size, filled in below
+	req.int16(apiKey)
+	req.int16(version)
+	req.int32(c.correlationID)
+	req.nullableString(clientID)
+	req.buf = append(req.buf, body...)
+	binary.BigEndian.PutUint32(req.buf, uint32(len(req.buf)-4))
+	if _, err := c.conn.Write(req.buf); err != nil {
+		return nil, err
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(c.conn, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxResponseSize {
+		return nil, fmt.Errorf("kafka: response of %d bytes exceeds %d", n, maxResponseSize)
+	}
+	resp := make([]byte, n)
+	if _, err := io.ReadFull(c.conn, resp); err != nil {
+		return nil, err
+	}
+	d := &decoder{buf: resp}
+	if correlationID := d.int32(); d.err != nil || correlationID != c.correlationID {
+		return nil, fmt.Errorf("kafka: response out of order")
+	}
+	return d.buf, nil
+}
+
+// close closes the connection
+func (c *brokerConn) close() {
+	c.conn.Close()
+}
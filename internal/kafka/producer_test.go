@@ -0,0 +1,307 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// produced is a message received by the fake broker
+type produced struct {
+	topic     string
+	partition int32
+	key       string
+	value     string
+	headers   map[string]string
+}
+
+// fakeBroker is a single-node cluster answering metadata and produce requests
+type fakeBroker struct {
+	t          *testing.T
+	listener   net.Listener
+	partitions int32
+
+	mu        sync.Mutex
+	messages  []produced
+	refuse    []int16 // error codes returned to the next produce requests
+	metadatas int
+}
+
+func newFakeBroker(t *testing.T, partitions int32) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &fakeBroker{t: t, listener: listener, partitions: partitions}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) address() string {
+	return b.listener.Addr().String()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		d := &decoder{buf: req}
+		apiKey, _, correlationID := d.int16(), d.int16(), d.int32()
+		d.string() // client ID
+
+		resp := &encoder{}
+		resp.int32(0)
+		resp.int32(correlationID)
+		switch apiKey {
+		case apiKeyMetadata:
+			b.metadata(d, resp)
+		case apiKeyProduce:
+			b.produce(d, resp)
+		}
+		binary.BigEndian.PutUint32(resp.buf, uint32(len(resp.buf)-4))
+		if _, err := conn.Write(resp.buf); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) metadata(d *decoder, resp *encoder) {
+	b.mu.Lock()
+	b.metadatas++
+	b.mu.Unlock()
+
+	host, port, _ := net.SplitHostPort(b.address())
+	portNumber, _ := strconv.Atoi(port)
+	resp.int32(1)
+	resp.int32(1)
+	resp.string(host)
+	resp.int32(int32(portNumber))
+	resp.nullableString("")
+	resp.int32(1) // controller
+
+	topics := d.arrayLen()
+	resp.int32(int32(topics))
+	for i := 0; i < topics; i++ {
+		resp.int16(errNone)
+		resp.string(d.string())
+		resp.int8(0)
+		resp.int32(b.partitions)
+		for p := int32(0); p < b.partitions; p++ {
+			resp.int16(errNone)
+			resp.int32(p)
+			resp.int32(1) // leader
+			resp.int32(1)
+			resp.int32(1)
+			resp.int32(1)
+			resp.int32(1)
+		}
+	}
+}
+
+func (b *fakeBroker) produce(d *decoder, resp *encoder) {
+	d.string() // transactional ID
+	assert.Equal(b.t, int16(-1), d.int16(), "acks")
+	d.int32() // timeout
+	require.Equal(b.t, 1, d.arrayLen())
+	topic := d.string()
+	require.Equal(b.t, 1, d.arrayLen())
+	partition := d.int32()
+	batch := d.take(int(d.int32()))
+	require.NoError(b.t, d.err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	code := errNone
+	if len(b.refuse) > 0 {
+		code, b.refuse = b.refuse[0], b.refuse[1:]
+	} else {
+		for _, message := range decodeRecordBatch(b.t, batch) {
+			message.topic, message.partition = topic, partition
+			b.messages = append(b.messages, message)
+		}
+	}
+
+	resp.int32(1)
+	resp.string(topic)
+	resp.int32(1)
+	resp.int32(partition)
+	resp.int16(code)
+	resp.int64(int64(len(b.messages) - 1))
+	resp.int64(-1)
+	resp.int32(0) // throttle time
+}
+
+// decodeRecordBatch decodes a record batch, checking its length and CRC
+func decodeRecordBatch(t *testing.T, batch []byte) []produced {
+	d := &decoder{buf: batch}
+	assert.Zero(t, d.int64(), "base offset")
+	require.Equal(t, int(d.int32()), len(d.buf), "batch length")
+	d.int32() // leader epoch
+	require.Equal(t, int8(2), d.int8(), "magic")
+	crc := uint32(d.int32())
+	require.Equal(t, crc32.Checksum(d.buf, castagnoli), crc, "crc")
+	d.int16()
+	d.int32()
+	d.int64()
+	d.int64()
+	d.int64()
+	d.int16()
+	d.int32()
+	count := d.int32()
+	require.NoError(t, d.err)
+
+	varint := func() int64 {
+		v, n := binary.Varint(d.buf)
+		require.Positive(t, n)
+		d.buf = d.buf[n:]
+		return v
+	}
+	varbytes := func() string {
+		n := varint()
+		if n < 0 {
+			return ""
+		}
+		return string(d.take(int(n)))
+	}
+	var messages []produced
+	for i := int32(0); i < count; i++ {
+		varint() // length
+		d.int8()
+		varint()
+		assert.Equal(t, int64(i), varint(), "offset delta")
+		message := produced{key: varbytes(), value: varbytes(), headers: make(map[string]string)}
+		for h := varint(); h > 0; h-- {
+			key := varbytes()
+			message.headers[key] = varbytes()
+		}
+		messages = append(messages, message)
+	}
+	require.NoError(t, d.err)
+	assert.Empty(t, d.buf)
+	return messages
+}
+
+func TestProducer_Produce(t *testing.T) {
+	broker := newFakeBroker(t, 4)
+	producer := NewProducer(Config{Brokers: []string{broker.address()}, ClientID: "test", Timeout: 5 * time.Second})
+	defer producer.Close()
+	ctx := context.Background()
+
+	partition, offset, err := producer.Produce(ctx, "transfers", Message{Key: []byte("42"), Value: []byte("first")})
+	require.NoError(t, err)
+	assert.Equal(t, partitionFor([]byte("42"), 4), partition)
+	assert.Zero(t, offset)
+
+	// The same key lands on the same partition, and metadata is cached
+	again, offset, err := producer.Produce(ctx, "transfers", Message{Key: []byte("42"), Value: []byte("second"), Headers: []Header{{Key: "event", Value: []byte("e")}}})
+	require.NoError(t, err)
+	assert.Equal(t, partition, again)
+	assert.Equal(t, int64(1), offset)
+	assert.Equal(t, 1, broker.metadatas)
+	assert.Equal(t, []produced{
+		{topic: "transfers", partition: partition, key: "42", value: "first", headers: map[string]string{}},
+		{topic: "transfers", partition: partition, key: "42", value: "second", headers: map[string]string{"event": "e"}},
+	}, broker.messages)
+}
+
+func TestProducer_RefreshesLeaders(t *testing.T) {
+	broker := newFakeBroker(t, 1)
+	producer := NewProducer(Config{Brokers: []string{"127.0.0.1:1", broker.address()}, Timeout: 5 * time.Second})
+	defer producer.Close()
+	ctx := context.Background()
+
+	// A moved leader is retried once with fresh metadata
+	broker.refuse = []int16{errNotLeaderOrFollower}
+	_, _, err := producer.Produce(ctx, "transfers", Message{Value: []byte("moved")})
+	require.NoError(t, err)
+	assert.Equal(t, 2, broker.metadatas)
+	assert.Len(t, broker.messages, 1)
+
+	// Other errors aren't retried
+	broker.refuse = []int16{87}
+	_, _, err = producer.Produce(ctx, "transfers", Message{Value: []byte("invalid")})
+	var brokerErr *BrokerError
+	require.ErrorAs(t, err, &brokerErr)
+	assert.Equal(t, int16(87), brokerErr.Code)
+	assert.Len(t, broker.messages, 1)
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	broker := newFakeBroker(t, 3)
+	publisher, err := FromConfig(&config.Config{
+		KafkaBrokers: []string{broker.address()},
+		KafkaTopics: map[string]string{
+			models.EventAccountCreated:       "accounts",
+			models.EventTransactionCompleted: "transactions",
+		},
+		KafkaTimeout:   5000,
+		KafkaPlaintext: true,
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+	ctx := context.Background()
+
+	require.NoError(t, publisher.Publish(ctx, models.EventAccountCreated, &models.AccountCreated{AccountID: 7, InitialBalance: decimal.NewFromInt(10)}))
+	// The outbox relay passes payloads as recorded
+	require.NoError(t, publisher.Publish(ctx, models.EventTransactionCompleted, json.RawMessage(`{"id": 1, "source_account_id": 7}`)))
+	// Events without a topic are skipped
+	require.NoError(t, publisher.Publish(ctx, models.EventTransactionFailed, &models.TransferFailure{}))
+
+	require.Len(t, broker.messages, 2)
+	assert.Equal(t, "accounts", broker.messages[0].topic)
+	assert.Equal(t, "7", broker.messages[0].key)
+	assert.Equal(t, map[string]string{"event": models.EventAccountCreated}, broker.messages[0].headers)
+	assert.JSONEq(t, `{"account_id": 7, "initial_balance": "10", "created_at": ""}`, broker.messages[0].value)
+	assert.Equal(t, "transactions", broker.messages[1].topic)
+	assert.Equal(t, "7", broker.messages[1].key)
+	assert.Equal(t, broker.messages[0].partition, broker.messages[1].partition, "events of an account share a partition")
+	assert.Equal(t, `{"id": 1, "source_account_id": 7}`, broker.messages[1].value)
+}
+
+func TestFromConfig(t *testing.T) {
+	publisher, err := FromConfig(&config.Config{})
+	require.NoError(t, err)
+	assert.Nil(t, publisher)
+
+	// The plaintext producer must be accepted explicitly
+	_, err = FromConfig(&config.Config{KafkaBrokers: []string{"localhost:9092"}})
+	assert.ErrorIs(t, err, ErrPlaintext)
+	publisher, err = FromConfig(&config.Config{KafkaBrokers: []string{"localhost:9092"}, KafkaPlaintext: true})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Close())
+
+	_, err = FromConfig(&config.Config{KafkaBrokers: []string{"localhost"}, KafkaPlaintext: true})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = FromConfig(&config.Config{KafkaBrokers: []string{"localhost:9092"}, KafkaPlaintext: true, KafkaTopics: map[string]string{"account.deleted": "accounts"}})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = FromConfig(&config.Config{KafkaBrokers: []string{"localhost:9092"}, KafkaPlaintext: true, KafkaTopics: map[string]string{models.EventAccountCreated: "bad topic"}})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// API keys and versions of the requests the producer makes. Produce v3 is the oldest version
+// carrying record batches (magic 2), which every broker since 0.11 accepts and 4.0 still does.
+const (
+	apiKeyProduce   int16 = 0
+	apiKeyMetadata  int16 = 3
+	produceVersion  int16 = 3
+	metadataVersion int16 = 1
+)
+
+// Error codes the producer reacts to; any other non-zero code fails the request
+const (
+	errNone                    int16 = 0
+	errUnknownTopicOrPartition int16 = 3
+	errLeaderNotAvailable      int16 = 5
+	errNotLeaderOrFollower     int16 = 6
+)
+
+// castagnoli is the CRC-32C table record batches are checksummed with
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// encoder appends values in the big-endian wire format of the Kafka protocol
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+
+// varint appends a zigzag-encoded variable-length integer, as used inside records
+func (e *encoder) varint(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// nullableString appends s, or null when it is empty
+func (e *encoder) nullableString(s string) {
+	if s == "" {
+		e.int16(-1)
+		return
+	}
+	e.string(s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// varbytes appends b prefixed with its varint length, or -1 when it is nil
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// decoder reads values in the wire format of the Kafka protocol. The first error sticks: later
+// reads return zero values, so a response is decoded in one go and err checked at the end.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+// take returns the next n bytes, or nil once the buffer is exhausted
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = fmt.Errorf("kafka: truncated response")
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string reads a string; null reads as empty
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen reads the length of an array; null reads as empty
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) {
+		// Every element takes at least a byte; a larger count can only be a corrupt response
+		d.err = fmt.Errorf("kafka: truncated response")
+		return 0
+	}
+	return int(n)
+}
+
+// Header is a record header
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a record to produce
+type Message struct {
+	Key     []byte
+	Value   []byte
+	Headers []Header
+}
+
+// encodeRecordBatch encodes messages as an uncompressed record batch (magic 2) stamped with
+// timestamp, in milliseconds. The producer is not idempotent, so producer ID, epoch and base
+// sequence are unset.
+func encodeRecordBatch(messages []Message, timestamp int64) []byte {
+	// Everything after the CRC field, which is what the CRC covers
+	body := &encoder{}
+	body.int16(0) // attributes: no compression, create time, not transactional
+	body.int32(int32(len(messages) - 1))
+	body.int64(timestamp)
+	body.int64(timestamp)
+	body.int64(-1) // producer ID
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(int32(len(messages)))
+	for i, message := range messages {
+		record := &encoder{}
+		record.int8(0)   // attributes
+		record.varint(0) // timestamp delta
+		record.varint(int64(i))
+		record.varbytes(message.Key)
+		record.varbytes(message.Value)
+		record.varint(int64(len(message.Headers)))
+		for _, header := range message.Headers {
+			record.varbytes([]byte(header.Key))
+			record.varbytes(header.Value)
+		}
+		body.varint(int64(len(record.buf)))
+		body.buf = append(body.buf, record.buf...)
+	}
+
+	batch := &encoder{}
+	batch.int64(0) // base offset, assigned by the broker
+	// batch length counts the bytes after it: leader epoch, magic, CRC and the body
+	batch.int32(int32(4 + 1 + 4 + len(body.buf)))
+	batch.int32(-1) // partition leader epoch
+	batch.int8(2)   // magic
+	batch.int32(int32(crc32.Checksum(body.buf, castagnoli)))
+	batch.buf = append(batch.buf, body.buf...)
+	return batch.buf
+}
@@ -0,0 +1,158 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// maxTopicLength is the longest topic name Kafka accepts
+const maxTopicLength = 249
+
+// ErrPlaintext is returned for a Kafka configuration that didn't accept the producer's plaintext
+// connections
+var ErrPlaintext = fmt.Errorf("%w: the Kafka producer has no TLS or SASL; set KAFKA_PLAINTEXT=true to use it on a trusted network", errors.ErrValidationFailed)
+
+// ParseTopics parses the topics events are published to, keyed by event type
+func ParseTopics(raw map[string]string) (map[string]string, error) {
+	topics := make(map[string]string, len(raw))
+	for event, topic := range raw {
+		known := false
+		for _, t := range models.EventTypes {
+			known = known || t == event
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown event type %q", errors.ErrValidationFailed, event)
+		}
+		if err := validateTopic(topic); err != nil {
+			return nil, err
+		}
+		topics[event] = topic
+	}
+	return topics, nil
+}
+
+// validateTopic checks topic is a name Kafka accepts
+func validateTopic(topic string) error {
+	if topic == "" || topic == "." || topic == ".." || len(topic) > maxTopicLength {
+		return fmt.Errorf("%w: invalid Kafka topic %q", errors.ErrValidationFailed, topic)
+	}
+	for _, c := range topic {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '.' && c != '_' && c != '-' {
+			return fmt.Errorf("%w: invalid Kafka topic %q", errors.ErrValidationFailed, topic)
+		}
+	}
+	return nil
+}
+
+// ValidateBrokers checks every broker is a "host:port" address
+func ValidateBrokers(brokers []string) error {
+	for _, broker := range brokers {
+		host, port, err := net.SplitHostPort(broker)
+		if _, portErr := strconv.Atoi(port); err != nil || host == "" || portErr != nil {
+			return fmt.Errorf("%w: Kafka broker %q must be host:port", errors.ErrValidationFailed, broker)
+		}
+	}
+	return nil
+}
+
+// Publisher publishes events to the Kafka topic configured for their type. It serves as the sink
+// of the outbox relay, so events are produced only once their change committed, and at least
+// once. Messages are keyed by the account the event is about, so the events of an account stay
+// in order on one partition, and carry the event type in an "event" header.
+type Publisher struct {
+	producer *Producer
+	topics   map[string]string
+	log      *slog.Logger
+}
+
+// NewPublisher creates a publisher producing with producer to topics, keyed by event type
+func NewPublisher(producer *Producer, topics map[string]string) *Publisher {
+	return &Publisher{producer: producer, topics: topics, log: logger.Default()}
+}
+
+// FromConfig creates the publisher configured by the KAFKA_* settings, or returns nil when no
+// broker is configured. The producer has no TLS or SASL, so it is refused unless KAFKA_PLAINTEXT
+// accepts that.
+func FromConfig(cfg *config.Config) (*Publisher, error) {
+	if len(cfg.KafkaBrokers) == 0 {
+		return nil, nil
+	}
+	if !cfg.KafkaPlaintext {
+		return nil, ErrPlaintext
+	}
+	if err := ValidateBrokers(cfg.KafkaBrokers); err != nil {
+		return nil, err
+	}
+	topics, err := ParseTopics(cfg.KafkaTopics)
+	if err != nil {
+		return nil, err
+	}
+	producer := NewProducer(Config{
+		Brokers:  cfg.KafkaBrokers,
+		ClientID: cfg.KafkaClientID,
+		Timeout:  time.Duration(cfg.KafkaTimeout) * time.Millisecond,
+	})
+	logger.Default().Info("Kafka publisher configured", "brokers", strings.Join(cfg.KafkaBrokers, ","), "topics", topics)
+	return NewPublisher(producer, topics), nil
+}
+
+// Publish produces event to its topic. Events without a topic aren't published to Kafka.
+func (p *Publisher) Publish(ctx context.Context, event string, payload interface{}) error {
+	topic, ok := p.topics[event]
+	if !ok {
+		return nil
+	}
+	value, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if value, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event, err)
+		}
+	}
+
+	partition, offset, err := p.producer.Produce(ctx, topic, Message{
+		Key:     messageKey(value),
+		Value:   value,
+		Headers: []Header{{Key: "event", Value: []byte(event)}},
+	})
+	if err != nil {
+		return err
+	}
+	p.log.Debug("Published event", "event", event, "topic", topic, "partition", partition, "offset", offset)
+	return nil
+}
+
+// Close closes the connections of the producer
+func (p *Publisher) Close() error {
+	return p.producer.Close()
+}
+
+// messageKey returns the ID of the account an event is about: the account of account events,
+// the source of transaction events. It returns nil for events about no account.
+func messageKey(value []byte) []byte {
+	var subject struct {
+		AccountID       *int64 `json:"account_id"`
+		SourceAccountID *int64 `json:"source_account_id"`
+	}
+	if err := json.Unmarshal(value, &subject); err != nil {
+		return nil
+	}
+	switch {
+	case subject.AccountID != nil:
+		return strconv.AppendInt(nil, *subject.AccountID, 10)
+	case subject.SourceAccountID != nil:
+		return strconv.AppendInt(nil, *subject.SourceAccountID, 10)
+	}
+	return nil
+}
@@ -1,113 +1,273 @@
+// Package logger writes the application log through log/slog. New builds a structured logger
+// that components take through their constructors, so tests can capture what they log; the
+// package-level logger built by Initialize serves code that isn't handed one. Every record
+// written with the context of a request carries its request_id and, if traced, its trace_id and
+// span_id.
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+	"github.com/khamiruf/internal_transfers_system_go/internal/tracecontext"
 )
 
-var (
-	instance *Logger
-	once     sync.Once
+// Keys of the structured fields shared across the application
+const (
+	KeyRequestID     = "request_id"
+	KeyAccountID     = "account_id"
+	KeyTransactionID = "transaction_id"
+	KeyTraceID       = "trace_id"
+	KeySpanID        = "span_id"
 )
 
-// Logger wraps the standard logger with additional functionality
-type Logger struct {
-	*log.Logger
-	level config.LogLevel
+// Format selects how records are written
+type Format string
+
+const (
+	// FormatText writes logfmt-style key=value lines
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates a log format name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatText, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (available: text, json)", name)
+}
+
+// LevelFatal is the slog level of fatal messages, above slog.LevelError
+const LevelFatal = slog.Level(12)
+
+// Level returns the slog level of a configured log level
+func Level(level config.LogLevel) slog.Level {
+	switch level {
+	case config.DEBUG:
+		return slog.LevelDebug
+	case config.WARN:
+		return slog.LevelWarn
+	case config.ERROR:
+		return slog.LevelError
+	case config.FATAL:
+		return LevelFatal
+	}
+	return slog.LevelInfo
+}
+
+// ParseLevel parses the name of a log level, rejecting unknown names instead of defaulting
+func ParseLevel(name string) (config.LogLevel, error) {
+	switch strings.ToUpper(name) {
+	case "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "FATAL":
+		return config.ParseLogLevel(name), nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (available: debug, info, warn, error, fatal)", name)
+}
+
+// configLevel returns the configured log level of a slog level
+func configLevel(level slog.Level) config.LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return config.DEBUG
+	case level < slog.LevelWarn:
+		return config.INFO
+	case level < slog.LevelError:
+		return config.WARN
+	case level < LevelFatal:
+		return config.ERROR
+	}
+	return config.FATAL
 }
 
 // Config holds the logger configuration
 type Config struct {
 	Level  config.LogLevel
+	Format Format
 	Output io.Writer
-	Prefix string
+	// AddSource records the file and line of the call site
+	AddSource bool
+	// LevelVar, if set, holds the level instead, starting at Level, so it can be changed while
+	// the logger is in use
+	LevelVar *slog.LevelVar
+	// Redaction, if set, keeps amounts and account IDs out of the log
+	Redaction *Redaction
 }
 
 // DefaultConfig returns the default logger configuration
 func DefaultConfig() *Config {
 	return &Config{
-		Level:  config.INFO,
-		Output: os.Stdout,
+		Level:     config.INFO,
+		Format:    FormatText,
+		Output:    os.Stdout,
+		AddSource: true,
 	}
 }
 
-// Initialize sets up the logger with the given configuration
-func Initialize(cfg *Config) {
-	once.Do(func() {
-		if cfg == nil {
-			cfg = DefaultConfig()
+// New builds a structured logger from cfg, DefaultConfig if nil
+func New(cfg *Config) *slog.Logger {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	var level slog.Leveler = Level(cfg.Level)
+	if cfg.LevelVar != nil {
+		cfg.LevelVar.Set(Level(cfg.Level))
+		level = cfg.LevelVar
+	}
+	redaction := cfg.Redaction
+	opts := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: cfg.AddSource,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			return redaction.attr(replaceAttr(groups, a))
+		},
+	}
+	var handler slog.Handler
+	if cfg.Format == FormatJSON {
+		handler = slog.NewJSONHandler(cfg.Output, opts)
+	} else {
+		handler = slog.NewTextHandler(cfg.Output, opts)
+	}
+	return slog.New(NewContextHandler(handler))
+}
+
+// replaceAttr names LevelFatal FATAL instead of ERROR+4 and trims the source file of a record to
+// its package directory and name
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		if level, ok := a.Value.Any().(slog.Level); ok && level == LevelFatal {
+			return slog.String(slog.LevelKey, "FATAL")
 		}
-		instance = &Logger{
-			Logger: log.New(cfg.Output, cfg.Prefix, log.LstdFlags|log.Lmicroseconds|log.Lshortfile),
-			level:  cfg.Level,
+	case slog.SourceKey:
+		if source, ok := a.Value.Any().(*slog.Source); ok {
+			trimmed := *source
+			trimmed.File = filepath.Join(filepath.Base(filepath.Dir(source.File)), filepath.Base(source.File))
+			return slog.Any(slog.SourceKey, &trimmed)
 		}
-	})
+	}
+	return a
 }
 
-// GetInstance returns the singleton logger instance
-func GetInstance() *Logger {
-	if instance == nil {
-		Initialize(nil)
-	}
-	return instance
+// ContextHandler adds the request_id and the trace_id and span_id of the context a record is
+// written with
+type ContextHandler struct {
+	slog.Handler
 }
 
-// log formats and outputs a log message if the level is sufficient
-func (l *Logger) log(level config.LogLevel, format string, v ...interface{}) {
-	if level >= l.level {
-		msg := fmt.Sprintf(format, v...)
-		l.Output(2, fmt.Sprintf("[%s] %s", level.String(), msg))
+// NewContextHandler wraps handler so records carry the request_id of their context
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+// Handle adds the request_id and trace of ctx, if any, to r
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String(KeyRequestID, id))
+	}
+	if sc := tracecontext.FromContext(ctx); sc.Valid() {
+		r.AddAttrs(slog.String(KeyTraceID, sc.TraceIDString()), slog.String(KeySpanID, sc.SpanIDString()))
 	}
+	return h.Handler.Handle(ctx, r)
 }
 
-// Debug logs a debug message
-func (l *Logger) Debug(format string, v ...interface{}) {
-	l.log(config.DEBUG, format, v...)
+// WithAttrs keeps the request_id handling on loggers derived with With
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
 }
 
-// Info logs an info message
-func (l *Logger) Info(format string, v ...interface{}) {
-	l.log(config.INFO, format, v...)
+// WithGroup keeps the request_id handling on loggers derived with WithGroup
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
 }
 
-// Warn logs a warning message
-func (l *Logger) Warn(format string, v ...interface{}) {
-	l.log(config.WARN, format, v...)
+// AccountID is the structured field of an account
+func AccountID(id int64) slog.Attr {
+	return slog.Int64(KeyAccountID, id)
 }
 
-// Error logs an error message
-func (l *Logger) Error(format string, v ...interface{}) {
-	l.log(config.ERROR, format, v...)
+// TransactionID is the structured field of a transaction
+func TransactionID(id int64) slog.Attr {
+	return slog.Int64(KeyTransactionID, id)
 }
 
-// Fatal logs a fatal message and exits
-func (l *Logger) Fatal(format string, v ...interface{}) {
-	l.log(config.FATAL, format, v...)
-	os.Exit(1)
+var (
+	instance *slog.Logger
+	once     sync.Once
+	// level is the level of the package-level logger, changed at runtime by SetLevel
+	level = new(slog.LevelVar)
+)
+
+// Initialize sets up the package-level logger with the given configuration and makes it the slog
+// default. Only the first call has an effect.
+func Initialize(cfg *Config) {
+	once.Do(func() {
+		if cfg == nil {
+			cfg = DefaultConfig()
+		}
+		withLevel := *cfg
+		withLevel.LevelVar = level
+		instance = New(&withLevel)
+		slog.SetDefault(instance)
+	})
 }
 
-// Convenience functions for package-level logging
-func Debug(format string, v ...interface{}) {
-	GetInstance().Debug(format, v...)
+// SetLevel changes the level of the package-level logger, and of every logger derived from it,
+// at once and without a restart. It returns the previous level.
+func SetLevel(l config.LogLevel) config.LogLevel {
+	Default()
+	previous := configLevel(level.Level())
+	level.Set(Level(l))
+	return previous
 }
 
-func Info(format string, v ...interface{}) {
-	GetInstance().Info(format, v...)
+// CurrentLevel returns the level of the package-level logger
+func CurrentLevel() config.LogLevel {
+	Default()
+	return configLevel(level.Level())
 }
 
-func Warn(format string, v ...interface{}) {
-	GetInstance().Warn(format, v...)
+// Default returns the package-level logger, for constructors given none
+func Default() *slog.Logger {
+	if instance == nil {
+		Initialize(nil)
+	}
+	return instance
 }
 
-func Error(format string, v ...interface{}) {
-	GetInstance().Error(format, v...)
+// Enabled reports whether the package-level logger writes messages at l. It is one comparison,
+// for guarding messages on hot paths: the arguments of a call are boxed onto the heap before the
+// call can drop the message, so an unguarded Debug allocates even when filtered.
+func Enabled(l slog.Level) bool {
+	Default()
+	return l >= level.Level()
 }
 
-func Fatal(format string, v ...interface{}) {
-	GetInstance().Fatal(format, v...)
+// Fatal writes a message at LevelFatal through the package-level logger and exits, for the
+// commands that can't continue
+func Fatal(msg string, args ...interface{}) {
+	l := Default()
+	if l.Enabled(context.Background(), LevelFatal) {
+		var pcs [1]uintptr
+		runtime.Callers(2, pcs[:])
+		r := slog.NewRecord(time.Now(), LevelFatal, msg, pcs[0])
+		r.Add(args...)
+		_ = l.Handler().Handle(context.Background(), r)
+	}
+	os.Exit(1)
 }
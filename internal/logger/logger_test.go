@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+	"github.com/khamiruf/internal_transfers_system_go/internal/tracecontext"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(w io.Writer, level config.LogLevel, format Format) *slog.Logger {
+	return New(&Config{Level: level, Format: format, Output: w})
+}
+
+// stripTime drops the time attribute, so text output can be compared
+func stripTime(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey && len(groups) == 0 {
+		return slog.Attr{}
+	}
+	return replaceAttr(groups, a)
+}
+
+func TestLogger_Levels(t *testing.T) {
+	var out bytes.Buffer
+	l := slog.New(NewContextHandler(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: Level(config.INFO), ReplaceAttr: stripTime})))
+
+	l.Debug("hidden", "n", 1)
+	l.Info("transfer complete", AccountID(42))
+	l.Error("failed", "err", errors.New("boom"))
+	l.Log(context.Background(), LevelFatal, "giving up")
+
+	assert.Equal(t, "level=INFO msg=\"transfer complete\" account_id=42\n"+
+		"level=ERROR msg=failed err=boom\n"+
+		"level=FATAL msg=\"giving up\"\n", out.String())
+}
+
+func TestLogger_JSON(t *testing.T) {
+	var out bytes.Buffer
+	l := newTestLogger(&out, config.INFO, FormatJSON)
+
+	ctx := requestid.WithID(context.Background(), "req-42")
+	trace, _ := tracecontext.Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx = tracecontext.WithSpanContext(ctx, trace)
+	l.With(AccountID(7)).InfoContext(ctx, "transfer complete", TransactionID(9))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "transfer complete", record["msg"])
+	assert.Equal(t, "req-42", record[KeyRequestID])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[KeyTraceID])
+	assert.Equal(t, "00f067aa0ba902b7", record[KeySpanID])
+	assert.EqualValues(t, 7, record[KeyAccountID])
+	assert.EqualValues(t, 9, record[KeyTransactionID])
+}
+
+func TestContextHandler_RequestID(t *testing.T) {
+	var out bytes.Buffer
+	l := slog.New(NewContextHandler(slog.NewTextHandler(&out, &slog.HandlerOptions{ReplaceAttr: stripTime})))
+
+	l.InfoContext(requestid.WithID(context.Background(), "req-42"), "Transfer complete", TransactionID(42))
+	l.WarnContext(context.Background(), "No request")
+
+	assert.Equal(t, "level=INFO msg=\"Transfer complete\" transaction_id=42 request_id=req-42\nlevel=WARN msg=\"No request\"\n", out.String())
+}
+
+func TestDefault_Source(t *testing.T) {
+	var out bytes.Buffer
+	saved := Default()
+	instance = New(&Config{Level: config.INFO, Format: FormatJSON, Output: &out, AddSource: true})
+	defer func() { instance = saved }()
+
+	Default().Info("hello")
+
+	var record struct {
+		Source slog.Source `json:"source"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "logger/logger_test.go", record.Source.File)
+}
+
+func TestSetLevel(t *testing.T) {
+	defer SetLevel(SetLevel(config.INFO))
+
+	derived := Default().With(AccountID(1))
+	assert.False(t, derived.Enabled(context.Background(), slog.LevelDebug))
+
+	assert.Equal(t, config.INFO, SetLevel(config.DEBUG))
+	assert.Equal(t, config.DEBUG, CurrentLevel())
+	assert.True(t, derived.Enabled(context.Background(), slog.LevelDebug))
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warning")
+	require.NoError(t, err)
+	assert.Equal(t, config.WARN, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("json")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestEnabled(t *testing.T) {
+	previous := SetLevel(config.INFO)
+	defer SetLevel(previous)
+
+	assert.False(t, Enabled(slog.LevelDebug))
+	assert.True(t, Enabled(slog.LevelInfo))
+	assert.True(t, Enabled(LevelFatal))
+}
+
+func TestEnabled_GuardedDisabledLevelDoesNotAllocate(t *testing.T) {
+	previous := SetLevel(config.INFO)
+	defer SetLevel(previous)
+	amount := decimal.NewFromInt(100)
+	accountID := int64(123456)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if Enabled(slog.LevelDebug) {
+			Default().Debug("Transfer failed", AccountID(accountID), "amount", amount, "err", io.EOF)
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkLogger(b *testing.B) {
+	l := newTestLogger(io.Discard, config.INFO, FormatText)
+	ctx := context.Background()
+	amount := decimal.NewFromInt(100)
+
+	b.Run("disabled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.DebugContext(ctx, "transfer", "source", int64(i), "destination", int64(i)+1, "amount", amount)
+		}
+	})
+	b.Run("disabled, guarded", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if l.Enabled(ctx, slog.LevelDebug) {
+				l.DebugContext(ctx, "transfer", "source", int64(i), "destination", int64(i)+1, "amount", amount)
+			}
+		}
+	})
+	b.Run("enabled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.InfoContext(ctx, "transfer", "source", int64(i), "destination", int64(i)+1, "amount", amount)
+		}
+	})
+}
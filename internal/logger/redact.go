@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Masked replaces a redacted amount
+const Masked = "***"
+
+// amountKeys are the structured fields holding amounts or balances
+var amountKeys = map[string]bool{
+	"amount":            true,
+	"balance":           true,
+	"new_balance":       true,
+	"available_balance": true,
+	"initial_balance":   true,
+	"required_amount":   true,
+	"overdraft_limit":   true,
+	"fee":               true,
+
+	"reserved_balance":     true,
+	"new_reserved_balance": true,
+	"opening_balance":      true,
+	"closing_balance":      true,
+}
+
+// accountKeys are the structured fields holding account IDs
+var accountKeys = map[string]bool{
+	KeyAccountID:             true,
+	"source_account_id":      true,
+	"destination_account_id": true,
+	"suspense_account_id":    true,
+	"sweep_account_id":       true,
+	"adjustments_account_id": true,
+}
+
+// loggableError is an error carrying an account ID or amounts in its message, which it can write
+// hashed and masked; internal/errors.Error is one
+type loggableError interface {
+	error
+	LogString(account func(int64) string, amount func(decimal.Decimal) string) string
+}
+
+// Redaction is a policy keeping financial data out of the log. Amounts are masked by field name,
+// and by type wherever a decimal.Decimal is logged. Account IDs are replaced by a keyed hash, the
+// same for every record of an account so its lines can still be correlated. Logged errors are
+// redacted too: the account ID and amounts a domain error puts in its message are hashed and
+// masked in place.
+type Redaction struct {
+	maskAmounts bool
+	hashKey     []byte
+}
+
+// NewRedaction creates a redaction policy. Hashing account IDs requires a key: IDs are small
+// integers, so an unkeyed hash could be reversed by hashing every candidate.
+func NewRedaction(maskAmounts, hashAccountIDs bool, hashKey string) (*Redaction, error) {
+	r := &Redaction{maskAmounts: maskAmounts}
+	if hashAccountIDs {
+		if hashKey == "" {
+			return nil, errors.New("hashing account IDs in the log requires a hash key")
+		}
+		r.hashKey = []byte(hashKey)
+	}
+	return r, nil
+}
+
+// HashAccountID returns the hash an account ID is logged as, for looking up its lines
+func (r *Redaction) HashAccountID(id int64) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write(strconv.AppendInt(nil, id, 10))
+	return "acct_" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// attr redacts a structured field
+func (r *Redaction) attr(a slog.Attr) slog.Attr {
+	if r == nil {
+		return a
+	}
+	switch {
+	case r.maskAmounts && amountKeys[a.Key]:
+		return slog.String(a.Key, Masked)
+	case r.hashKey != nil && accountKeys[a.Key] && a.Value.Kind() == slog.KindInt64:
+		return slog.String(a.Key, r.HashAccountID(a.Value.Int64()))
+	case a.Value.Kind() != slog.KindAny:
+		return a
+	}
+	switch v := a.Value.Any().(type) {
+	case decimal.Decimal, *decimal.Decimal, decimal.NullDecimal:
+		if r.maskAmounts {
+			return slog.String(a.Key, Masked)
+		}
+	case error:
+		if text, ok := r.error(v); ok {
+			return slog.String(a.Key, text)
+		}
+	}
+	return a
+}
+
+// error returns the message of err with the account ID and amounts of the domain error it wraps
+// redacted, and false if it wraps none or the policy redacts nothing
+func (r *Redaction) error(err error) (string, bool) {
+	var loggable loggableError
+	if (!r.maskAmounts && r.hashKey == nil) || !errors.As(err, &loggable) {
+		return "", false
+	}
+	redacted := loggable.LogString(r.account, r.amount)
+	return strings.Replace(err.Error(), loggable.Error(), redacted, 1), true
+}
+
+// account writes an account ID of an error message
+func (r *Redaction) account(id int64) string {
+	if r.hashKey == nil {
+		return strconv.FormatInt(id, 10)
+	}
+	return r.HashAccountID(id)
+}
+
+// amount writes an amount of an error message
+func (r *Redaction) amount(d decimal.Decimal) string {
+	if r.maskAmounts {
+		return Masked
+	}
+	return d.String()
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedaction_StructuredFields(t *testing.T) {
+	redaction, err := NewRedaction(true, true, "secret")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	l := New(&Config{Level: config.INFO, Format: FormatJSON, Output: &out, Redaction: redaction})
+
+	l.With(AccountID(7)).Info("Updating balance", "balance", "100.50", "new_balance", "70.50",
+		"destination_account_id", int64(8), TransactionID(9), "status", "complete")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, Masked, record["balance"])
+	assert.Equal(t, Masked, record["new_balance"])
+	assert.Equal(t, redaction.HashAccountID(7), record[KeyAccountID])
+	assert.Equal(t, redaction.HashAccountID(8), record["destination_account_id"])
+	assert.EqualValues(t, 9, record[KeyTransactionID])
+	assert.Equal(t, "complete", record["status"])
+
+	// The hash is stable per account and keyed
+	assert.Regexp(t, `^acct_[0-9a-f]{12}$`, redaction.HashAccountID(7))
+	assert.NotEqual(t, redaction.HashAccountID(7), redaction.HashAccountID(8))
+	other, err := NewRedaction(false, true, "other")
+	require.NoError(t, err)
+	assert.NotEqual(t, redaction.HashAccountID(7), other.HashAccountID(7))
+}
+
+func TestRedaction_DecimalsByType(t *testing.T) {
+	redaction, err := NewRedaction(true, false, "")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	l := New(&Config{Level: config.INFO, Format: FormatJSON, Output: &out, Redaction: redaction})
+
+	limit := decimal.RequireFromString("250.75")
+	l.Info("Limits", "limit", limit, "daily", &limit, "count", 3)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, Masked, record["limit"])
+	assert.Equal(t, Masked, record["daily"])
+	assert.EqualValues(t, 3, record["count"])
+}
+
+func TestRedaction_Errors(t *testing.T) {
+	redaction, err := NewRedaction(true, true, "secret")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	l := New(&Config{Level: config.INFO, Format: FormatJSON, Output: &out, Redaction: redaction})
+
+	amount := decimal.RequireFromString("500.25")
+	domainErr := &domainErrors.Error{Err: domainErrors.ErrInsufficientBalance, AccountID: 4815162342, Amount: &amount}
+	l.Error("Transfer failed", "err", fmt.Errorf("debiting source: %w", domainErr), "other", io.EOF)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "debiting source: "+domainErrors.ErrInsufficientBalance.Error()+
+		" (account_id="+redaction.HashAccountID(4815162342)+", requested=***)", record["err"])
+	assert.Equal(t, "EOF", record["other"])
+	assert.NotContains(t, out.String(), "4815162342")
+	assert.NotContains(t, out.String(), "500.25")
+}
+
+func TestRedaction_Disabled(t *testing.T) {
+	redaction, err := NewRedaction(false, false, "")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	l := New(&Config{Level: config.INFO, Format: FormatJSON, Output: &out, Redaction: redaction})
+
+	l.Info("Balance", AccountID(7), "balance", "100.50")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "100.50", record["balance"])
+	assert.EqualValues(t, 7, record[KeyAccountID])
+}
+
+func TestNewRedaction_RequiresHashKey(t *testing.T) {
+	_, err := NewRedaction(false, true, "")
+	assert.Error(t, err)
+}
+
+func TestRedaction_DisabledLevelDoesNotAllocate(t *testing.T) {
+	redaction, err := NewRedaction(true, false, "")
+	require.NoError(t, err)
+	l := New(&Config{Level: config.INFO, Output: io.Discard, Redaction: redaction})
+	ctx := context.Background()
+	amount := decimal.NewFromInt(100)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if l.Enabled(ctx, slog.LevelDebug) {
+			l.DebugContext(ctx, "Transfer", "amount", amount)
+		}
+	})
+	assert.Zero(t, allocs)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logger
+
+import "context"
+
+// ToggleDebugOnSignal does nothing where SIGUSR1 doesn't exist; the level can still be changed
+// with SetLevel
+func ToggleDebugOnSignal(ctx context.Context) {}
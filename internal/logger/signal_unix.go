@@ -0,0 +1,48 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+)
+
+// ToggleDebugOnSignal switches the package-level logger to DEBUG on SIGUSR1 and back to the
+// level it had on the next one, until ctx is done. An operator can then turn on debug logging
+// during an incident with kill -USR1 and turn it off again the same way.
+func ToggleDebugOnSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		defer signal.Stop(signals)
+		restore := CurrentLevel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				restore = toggleDebug(restore)
+			}
+		}
+	}()
+}
+
+// toggleDebug switches to DEBUG, or to restore if DEBUG is already on, and returns the level to
+// restore on the next toggle
+func toggleDebug(restore config.LogLevel) config.LogLevel {
+	if CurrentLevel() == config.DEBUG {
+		if restore == config.DEBUG {
+			restore = config.INFO
+		}
+		SetLevel(restore)
+		Default().Warn("Log level set on SIGUSR1", "level", restore)
+		return restore
+	}
+	previous := SetLevel(config.DEBUG)
+	Default().Warn("Log level set on SIGUSR1", "level", config.DEBUG)
+	return previous
+}
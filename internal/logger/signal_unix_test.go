@@ -0,0 +1,28 @@
+//go:build unix
+
+package logger
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	defer SetLevel(SetLevel(config.WARN))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ToggleDebugOnSignal(ctx)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return CurrentLevel() == config.DEBUG }, time.Second, time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return CurrentLevel() == config.WARN }, time.Second, time.Millisecond)
+}
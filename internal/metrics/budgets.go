@@ -0,0 +1,72 @@
+package metrics
+
+import "sync"
+
+// BudgetStats is a snapshot of the latency budget counters. Skipped counts optional work by
+// kind, such as event_publish or cache_refresh.
+type BudgetStats struct {
+	Requests      uint64            `json:"requests"`
+	Exceeded      uint64            `json:"exceeded"`
+	ExceededRatio float64           `json:"exceeded_ratio"`
+	Skipped       map[string]uint64 `json:"skipped"`
+}
+
+// Budgets counts the requests that ran past their latency budget and the optional work skipped
+// because too little of a budget was left, to guide the tuning of budgets and timeouts. A nil
+// *Budgets records nothing.
+type Budgets struct {
+	mu       sync.Mutex
+	requests uint64
+	exceeded uint64
+	skipped  map[string]uint64
+}
+
+// NewBudgets creates an empty set of latency budget counters
+func NewBudgets() *Budgets {
+	return &Budgets{skipped: make(map[string]uint64)}
+}
+
+// Request counts a finished request, as exceeded if it ran past its budget
+func (m *Budgets) Request(exceeded bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	if exceeded {
+		m.exceeded++
+	}
+}
+
+// Skip counts optional work of a kind skipped because the budget was nearly exhausted
+func (m *Budgets) Skip(work string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.skipped[work]++
+}
+
+// Stats returns a snapshot of the counters
+func (m *Budgets) Stats() BudgetStats {
+	stats := BudgetStats{Skipped: map[string]uint64{}}
+	if m == nil {
+		return stats
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats.Requests = m.requests
+	stats.Exceeded = m.exceeded
+	if m.requests > 0 {
+		stats.ExceededRatio = float64(m.exceeded) / float64(m.requests)
+	}
+	for work, count := range m.skipped {
+		stats.Skipped[work] = count
+	}
+	return stats
+}
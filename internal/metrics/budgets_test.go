@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgets_Stats(t *testing.T) {
+	m := NewBudgets()
+	m.Request(false)
+	m.Request(false)
+	m.Request(false)
+	m.Request(true)
+	m.Skip("event_publish")
+	m.Skip("event_publish")
+	m.Skip("cache_refresh")
+
+	assert.Equal(t, BudgetStats{
+		Requests:      4,
+		Exceeded:      1,
+		ExceededRatio: 0.25,
+		Skipped:       map[string]uint64{"event_publish": 2, "cache_refresh": 1},
+	}, m.Stats())
+}
+
+func TestBudgets_Nil(t *testing.T) {
+	var m *Budgets
+	m.Request(true)
+	m.Skip("event_publish")
+	assert.Equal(t, BudgetStats{Skipped: map[string]uint64{}}, m.Stats())
+}
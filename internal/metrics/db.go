@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// DBPools exposes the connection pool statistics of the database handles, labelled by pool name,
+// e.g. "primary" and "replica". Statistics are read from the handles on every scrape.
+type DBPools struct {
+	names []string
+	pools map[string]*sql.DB
+}
+
+// NewDBPools creates a collector of the pools by name; nil handles, such as an unconfigured
+// replica, are skipped
+func NewDBPools(pools map[string]*sql.DB) *DBPools {
+	m := &DBPools{pools: make(map[string]*sql.DB, len(pools))}
+	for name, db := range pools {
+		if db == nil {
+			continue
+		}
+		m.names = append(m.names, name)
+		m.pools[name] = db
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+// Collect writes the db_pool gauges and counters of every pool
+func (m *DBPools) Collect(_ context.Context, out *OpenMetricsWriter) error {
+	var open, inUse, idle, maxOpen, waits, waitSeconds, idleClosed, lifetimeClosed []Sample
+	for _, name := range m.names {
+		stats := m.pools[name].Stats()
+		labels := map[string]string{"pool": name}
+		open = append(open, Sample{Labels: labels, Value: float64(stats.OpenConnections)})
+		inUse = append(inUse, Sample{Labels: labels, Value: float64(stats.InUse)})
+		idle = append(idle, Sample{Labels: labels, Value: float64(stats.Idle)})
+		maxOpen = append(maxOpen, Sample{Labels: labels, Value: float64(stats.MaxOpenConnections)})
+		waits = append(waits, Sample{Labels: labels, Value: float64(stats.WaitCount)})
+		waitSeconds = append(waitSeconds, Sample{Labels: labels, Value: stats.WaitDuration.Seconds()})
+		idleClosed = append(idleClosed, Sample{Labels: labels, Value: float64(stats.MaxIdleClosed + stats.MaxIdleTimeClosed)})
+		lifetimeClosed = append(lifetimeClosed, Sample{Labels: labels, Value: float64(stats.MaxLifetimeClosed)})
+	}
+
+	out.Gauge("db_pool_open_connections", "Open connections, in use and idle.", open...)
+	out.Gauge("db_pool_in_use_connections", "Connections in use.", inUse...)
+	out.Gauge("db_pool_idle_connections", "Idle connections.", idle...)
+	out.Gauge("db_pool_max_open_connections", "Maximum number of open connections, 0 if unlimited.", maxOpen...)
+	out.Counter("db_pool_waits", "Requests for a connection that had to wait for one.", waits...)
+	out.Counter("db_pool_wait_seconds", "Seconds spent waiting for a connection.", waitSeconds...)
+	out.Counter("db_pool_idle_closed", "Connections closed for exceeding the idle limits.", idleClosed...)
+	out.Counter("db_pool_lifetime_closed", "Connections closed for exceeding their maximum lifetime.", lifetimeClosed...)
+	return nil
+}
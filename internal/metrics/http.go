@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// UnmatchedRoute labels requests that match no route, so unknown paths can't grow the label set
+const UnmatchedRoute = "unmatched"
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HTTPRequests counts requests by route and status code and keeps a latency histogram per route.
+// Routes are the ServeMux patterns, e.g. "GET /accounts/{account_id}", so the label set is bounded
+// by the registered routes whatever paths clients send. A nil *HTTPRequests records nothing.
+type HTTPRequests struct {
+	routes  *http.ServeMux
+	buckets []float64
+
+	mu       sync.Mutex
+	statuses map[routeStatus]uint64
+	latency  map[string]*histogram
+}
+
+type routeStatus struct {
+	route  string
+	status int
+}
+
+// histogram counts observations per bucket, not cumulatively; the exposition accumulates them
+type histogram struct {
+	counts []uint64 // one per bound, then one for observations above every bound
+	sum    float64
+}
+
+// NewHTTPRequests creates request metrics for the routes of mux, with the DefaultLatencyBuckets
+func NewHTTPRequests(routes *http.ServeMux) *HTTPRequests {
+	return &HTTPRequests{
+		routes:   routes,
+		buckets:  DefaultLatencyBuckets,
+		statuses: make(map[routeStatus]uint64),
+		latency:  make(map[string]*histogram),
+	}
+}
+
+// Record counts a request that was answered with status after elapsed
+func (m *HTTPRequests) Record(r *http.Request, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	route := UnmatchedRoute
+	if _, pattern := m.routes.Handler(r); pattern != "" {
+		route = pattern
+	}
+	seconds := elapsed.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.statuses[routeStatus{route: route, status: status}]++
+	h, ok := m.latency[route]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets)+1)}
+		m.latency[route] = h
+	}
+	h.counts[sort.SearchFloat64s(m.buckets, seconds)]++
+	h.sum += seconds
+}
+
+// Collect writes the http_requests counter and the http_request_duration_seconds histogram
+func (m *HTTPRequests) Collect(_ context.Context, out *OpenMetricsWriter) error {
+	var requests []Sample
+	var durations []HistogramSample
+	if m != nil {
+		m.mu.Lock()
+		for key, n := range m.statuses {
+			requests = append(requests, Sample{
+				Labels: map[string]string{"route": key.route, "code": strconv.Itoa(key.status)},
+				Value:  float64(n),
+			})
+		}
+		for route, h := range m.latency {
+			sample := HistogramSample{
+				Labels: map[string]string{"route": route},
+				Bounds: m.buckets,
+				Counts: make([]uint64, len(m.buckets)),
+				Sum:    h.sum,
+			}
+			for i, n := range h.counts {
+				sample.Count += n
+				if i < len(sample.Counts) {
+					sample.Counts[i] = sample.Count
+				}
+			}
+			durations = append(durations, sample)
+		}
+		m.mu.Unlock()
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i].Labels, requests[j].Labels
+		return a["route"] < b["route"] || a["route"] == b["route"] && a["code"] < b["code"]
+	})
+	sort.Slice(durations, func(i, j int) bool { return durations[i].Labels["route"] < durations[j].Labels["route"] })
+
+	out.Counter("http_requests", "HTTP requests by route and status code.", requests...)
+	out.Histogram("http_request_duration_seconds", "Latency of HTTP requests by route.", durations...)
+	return nil
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPRequests_Collect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/{account_id}", func(http.ResponseWriter, *http.Request) {})
+	m := NewHTTPRequests(mux)
+	m.buckets = []float64{0.1, 1}
+
+	m.Record(httptest.NewRequest(http.MethodGet, "/accounts/1", nil), http.StatusOK, 50*time.Millisecond)
+	m.Record(httptest.NewRequest(http.MethodGet, "/accounts/2", nil), http.StatusOK, 100*time.Millisecond)
+	m.Record(httptest.NewRequest(http.MethodGet, "/accounts/3", nil), http.StatusNotFound, 2*time.Second)
+	m.Record(httptest.NewRequest(http.MethodGet, "/nowhere", nil), http.StatusNotFound, 500*time.Millisecond)
+
+	var out strings.Builder
+	w := NewOpenMetricsWriter(&out)
+	require.NoError(t, m.Collect(context.Background(), w))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `# TYPE http_requests counter
+# HELP http_requests HTTP requests by route and status code.
+http_requests_total{code="200",route="GET /accounts/{account_id}"} 2
+http_requests_total{code="404",route="GET /accounts/{account_id}"} 1
+http_requests_total{code="404",route="unmatched"} 1
+# TYPE http_request_duration_seconds histogram
+# HELP http_request_duration_seconds Latency of HTTP requests by route.
+http_request_duration_seconds_bucket{le="0.1",route="GET /accounts/{account_id}"} 2
+http_request_duration_seconds_bucket{le="1",route="GET /accounts/{account_id}"} 2
+http_request_duration_seconds_bucket{le="+Inf",route="GET /accounts/{account_id}"} 3
+http_request_duration_seconds_count{route="GET /accounts/{account_id}"} 3
+http_request_duration_seconds_sum{route="GET /accounts/{account_id}"} 2.15
+http_request_duration_seconds_bucket{le="0.1",route="unmatched"} 0
+http_request_duration_seconds_bucket{le="1",route="unmatched"} 1
+http_request_duration_seconds_bucket{le="+Inf",route="unmatched"} 1
+http_request_duration_seconds_count{route="unmatched"} 1
+http_request_duration_seconds_sum{route="unmatched"} 0.5
+# EOF
+`, out.String())
+}
+
+func TestHTTPRequests_Nil(t *testing.T) {
+	var m *HTTPRequests
+	m.Record(httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, time.Millisecond)
+
+	var out strings.Builder
+	require.NoError(t, m.Collect(context.Background(), NewOpenMetricsWriter(&out)))
+	assert.Contains(t, out.String(), "# TYPE http_requests counter")
+}
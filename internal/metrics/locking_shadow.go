@@ -0,0 +1,93 @@
+package metrics
+
+import "sync"
+
+// Kinds of divergence between the current transfer path and the row-locking path shadowing it
+const (
+	DivergenceOutcome = "outcome" // one path accepted the transfer, the other rejected it, or they rejected it differently
+	DivergenceBalance = "balance" // both accepted it but left different balances
+)
+
+// LockingShadowStats is a snapshot of the locking shadow counters. Compared transfers either
+// matched or diverged; Divergences counts them by kind. Uncompared transfers were rejected for a
+// reason the row-locking path doesn't decide, or its dry run failed.
+type LockingShadowStats struct {
+	Compared    uint64            `json:"compared"`
+	Matched     uint64            `json:"matched"`
+	Diverged    uint64            `json:"diverged"`
+	Divergences map[string]uint64 `json:"divergences"`
+	Uncompared  uint64            `json:"uncompared"`
+	Failed      uint64            `json:"failed"`
+}
+
+// LockingShadow counts how the transfers shadow-run on the row-locking path compared with the
+// current serializable path, to decide whether it is safe to cut over. A nil *LockingShadow
+// records nothing.
+type LockingShadow struct {
+	mu          sync.Mutex
+	matched     uint64
+	divergences map[string]uint64
+	uncompared  uint64
+	failed      uint64
+}
+
+// NewLockingShadow creates an empty set of locking shadow counters
+func NewLockingShadow() *LockingShadow {
+	return &LockingShadow{divergences: make(map[string]uint64)}
+}
+
+// Match counts a transfer both paths decided the same way
+func (m *LockingShadow) Match() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.matched++
+}
+
+// Diverge counts a transfer the paths decided differently, by kind of divergence
+func (m *LockingShadow) Diverge(kind string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.divergences[kind]++
+}
+
+// Uncompared counts a transfer that couldn't be compared; failed if the dry run itself failed
+func (m *LockingShadow) Uncompared(failed bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.uncompared++
+	if failed {
+		m.failed++
+	}
+}
+
+// Stats returns a snapshot of the counters
+func (m *LockingShadow) Stats() LockingShadowStats {
+	stats := LockingShadowStats{Divergences: map[string]uint64{}}
+	if m == nil {
+		return stats
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats.Matched = m.matched
+	for kind, count := range m.divergences {
+		stats.Divergences[kind] = count
+		stats.Diverged += count
+	}
+	stats.Compared = stats.Matched + stats.Diverged
+	stats.Uncompared = m.uncompared
+	stats.Failed = m.failed
+	return stats
+}
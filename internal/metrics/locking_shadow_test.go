@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockingShadow_Stats(t *testing.T) {
+	m := NewLockingShadow()
+	m.Match()
+	m.Match()
+	m.Diverge(DivergenceOutcome)
+	m.Diverge(DivergenceBalance)
+	m.Diverge(DivergenceBalance)
+	m.Uncompared(false)
+	m.Uncompared(true)
+
+	assert.Equal(t, LockingShadowStats{
+		Compared:    5,
+		Matched:     2,
+		Diverged:    3,
+		Divergences: map[string]uint64{DivergenceOutcome: 1, DivergenceBalance: 2},
+		Uncompared:  2,
+		Failed:      1,
+	}, m.Stats())
+}
+
+func TestLockingShadow_Nil(t *testing.T) {
+	var m *LockingShadow
+	m.Match()
+	m.Diverge(DivergenceOutcome)
+	m.Uncompared(true)
+	assert.Equal(t, LockingShadowStats{Divergences: map[string]uint64{}}, m.Stats())
+}
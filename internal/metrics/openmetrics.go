@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text exposition format
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Sample is one value of a metric family, identified by its labels
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// HistogramSample is one histogram of a histogram family: the cumulative count of observations at
+// or below each bucket bound, the count of all observations and their sum
+type HistogramSample struct {
+	Labels map[string]string
+	Bounds []float64 // upper bounds of the buckets, ascending, without +Inf
+	Counts []uint64  // cumulative counts, one per bound
+	Count  uint64
+	Sum    float64
+}
+
+// Collector writes metric families to an exposition on every scrape
+type Collector interface {
+	Collect(ctx context.Context, out *OpenMetricsWriter) error
+}
+
+// OpenMetricsWriter writes metric families in the OpenMetrics text exposition format that
+// Prometheus and compatible scrapers read. The first write error is kept and returned by Close.
+type OpenMetricsWriter struct {
+	w   io.Writer
+	err error
+}
+
+// NewOpenMetricsWriter creates a writer of the exposition to w
+func NewOpenMetricsWriter(w io.Writer) *OpenMetricsWriter {
+	return &OpenMetricsWriter{w: w}
+}
+
+// Gauge writes a gauge family with its samples
+func (o *OpenMetricsWriter) Gauge(name, help string, samples ...Sample) {
+	o.family(name, "gauge", help, "", samples)
+}
+
+// Counter writes a counter family with its samples, which are exposed with the _total suffix
+func (o *OpenMetricsWriter) Counter(name, help string, samples ...Sample) {
+	o.family(name, "counter", help, "_total", samples)
+}
+
+// Histogram writes a histogram family: per sample the _bucket series with their le label, ending
+// with +Inf, and the _count and _sum series
+func (o *OpenMetricsWriter) Histogram(name, help string, samples ...HistogramSample) {
+	o.printf("# TYPE %s histogram\n", name)
+	o.printf("# HELP %s %s\n", name, escapeOpenMetrics(help, false))
+	for _, sample := range samples {
+		for i, bound := range sample.Bounds {
+			o.printf("%s_bucket%s %d\n", name, formatLabels(withLabel(sample.Labels, "le", formatFloat(bound))), sample.Counts[i])
+		}
+		o.printf("%s_bucket%s %d\n", name, formatLabels(withLabel(sample.Labels, "le", "+Inf")), sample.Count)
+		o.printf("%s_count%s %d\n", name, formatLabels(sample.Labels), sample.Count)
+		o.printf("%s_sum%s %s\n", name, formatLabels(sample.Labels), formatFloat(sample.Sum))
+	}
+}
+
+// Close terminates the exposition and returns the first write error
+func (o *OpenMetricsWriter) Close() error {
+	o.printf("# EOF\n")
+	return o.err
+}
+
+func (o *OpenMetricsWriter) family(name, metricType, help, suffix string, samples []Sample) {
+	o.printf("# TYPE %s %s\n", name, metricType)
+	o.printf("# HELP %s %s\n", name, escapeOpenMetrics(help, false))
+	for _, sample := range samples {
+		o.printf("%s%s%s %s\n", name, suffix, formatLabels(sample.Labels), formatFloat(sample.Value))
+	}
+}
+
+func (o *OpenMetricsWriter) printf(format string, args ...interface{}) {
+	if o.err == nil {
+		_, o.err = fmt.Fprintf(o.w, format, args...)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// withLabel returns a copy of labels with one more label
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	extended := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		extended[k] = v
+	}
+	extended[name] = value
+	return extended
+}
+
+// formatLabels formats labels as a label set sorted by name, or nothing if there are none
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, escapeOpenMetrics(labels[name], true))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeOpenMetrics escapes backslashes and line feeds, and double quotes in label values
+func escapeOpenMetrics(s string, quoted bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quoted {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMetricsWriter(t *testing.T) {
+	var out strings.Builder
+	w := NewOpenMetricsWriter(&out)
+	w.Gauge("queue_depth", "Items waiting in the queue.",
+		Sample{Labels: map[string]string{"queue": "outbox"}, Value: 3},
+		Sample{Labels: map[string]string{"queue": `odd "name"\`}, Value: 0.5},
+	)
+	w.Counter("worker_heartbeats", "Heartbeats\nof the worker.",
+		Sample{Labels: map[string]string{"worker": "0", "subsystem": "sweeper"}, Value: 12},
+	)
+	w.Gauge("empty", "No samples.")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `# TYPE queue_depth gauge
+# HELP queue_depth Items waiting in the queue.
+queue_depth{queue="outbox"} 3
+queue_depth{queue="odd \"name\"\\"} 0.5
+# TYPE worker_heartbeats counter
+# HELP worker_heartbeats Heartbeats\nof the worker.
+worker_heartbeats_total{subsystem="sweeper",worker="0"} 12
+# TYPE empty gauge
+# HELP empty No samples.
+# EOF
+`, out.String())
+}
+
+func TestOpenMetricsWriter_Histogram(t *testing.T) {
+	var out strings.Builder
+	w := NewOpenMetricsWriter(&out)
+	w.Histogram("request_duration_seconds", "Request latency.",
+		HistogramSample{
+			Labels: map[string]string{"route": "GET /accounts"},
+			Bounds: []float64{0.1, 1},
+			Counts: []uint64{2, 3},
+			Count:  4,
+			Sum:    3.5,
+		},
+	)
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `# TYPE request_duration_seconds histogram
+# HELP request_duration_seconds Request latency.
+request_duration_seconds_bucket{le="0.1",route="GET /accounts"} 2
+request_duration_seconds_bucket{le="1",route="GET /accounts"} 3
+request_duration_seconds_bucket{le="+Inf",route="GET /accounts"} 4
+request_duration_seconds_count{route="GET /accounts"} 4
+request_duration_seconds_sum{route="GET /accounts"} 3.5
+# EOF
+`, out.String())
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+)
+
+// TenantQuotaStats is the last observed usage of a capped quota of a tenant, with how many
+// assignments or transfers took it past its warning threshold and how many it rejected
+type TenantQuotaStats struct {
+	TenantID   string `json:"tenant_id"`
+	Quota      string `json:"quota"`
+	Used       int64  `json:"used"`
+	Limit      int64  `json:"limit"`
+	Warnings   uint64 `json:"warnings"`
+	Rejections uint64 `json:"rejections"`
+}
+
+// QuotaStats is a snapshot of the quota counters, sorted by tenant and quota
+type QuotaStats struct {
+	Quotas []TenantQuotaStats `json:"quotas"`
+}
+
+// Quotas tracks the usage of the capped quotas of tenants, as observed by the assignments and
+// transfers that count against them. Usage is observed before the database transaction commits,
+// so a transfer that fails afterwards can leave it one ahead until the next observation. A nil
+// *Quotas records nothing.
+type Quotas struct {
+	mu     sync.Mutex
+	quotas map[quotaKey]*TenantQuotaStats
+}
+
+type quotaKey struct {
+	tenantID string
+	quota    string
+}
+
+// NewQuotas creates an empty set of quota counters
+func NewQuotas() *Quotas {
+	return &Quotas{quotas: make(map[quotaKey]*TenantQuotaStats)}
+}
+
+// Observe records that tenantID uses used of quota, capped at limit; warning counts a use past
+// the warning threshold
+func (m *Quotas) Observe(tenantID, quota string, used, limit int64, warning bool) {
+	m.record(tenantID, quota, used, limit, func(stats *TenantQuotaStats) {
+		if warning {
+			stats.Warnings++
+		}
+	})
+}
+
+// Reject records that an assignment or transfer was rejected because tenantID uses used of
+// quota, capped at limit
+func (m *Quotas) Reject(tenantID, quota string, used, limit int64) {
+	m.record(tenantID, quota, used, limit, func(stats *TenantQuotaStats) {
+		stats.Rejections++
+	})
+}
+
+func (m *Quotas) record(tenantID, quota string, used, limit int64, count func(*TenantQuotaStats)) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := quotaKey{tenantID: tenantID, quota: quota}
+	stats, ok := m.quotas[key]
+	if !ok {
+		stats = &TenantQuotaStats{TenantID: tenantID, Quota: quota}
+		m.quotas[key] = stats
+	}
+	stats.Used, stats.Limit = used, limit
+	count(stats)
+}
+
+// Stats returns a snapshot of the counters
+func (m *Quotas) Stats() QuotaStats {
+	stats := QuotaStats{Quotas: []TenantQuotaStats{}}
+	if m == nil {
+		return stats
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, quota := range m.quotas {
+		stats.Quotas = append(stats.Quotas, *quota)
+	}
+	sort.Slice(stats.Quotas, func(i, j int) bool {
+		a, b := stats.Quotas[i], stats.Quotas[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		return a.Quota < b.Quota
+	})
+	return stats
+}
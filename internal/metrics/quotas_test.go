@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotas_Stats(t *testing.T) {
+	m := NewQuotas()
+	m.Observe("retail", "daily_transactions", 7, 10, false)
+	m.Observe("retail", "daily_transactions", 8, 10, true)
+	m.Reject("retail", "daily_transactions", 10, 10)
+	m.Observe("corporate", "accounts", 3, 4, false)
+	m.Observe("retail", "accounts", 1, 5, false)
+
+	assert.Equal(t, []TenantQuotaStats{
+		{TenantID: "corporate", Quota: "accounts", Used: 3, Limit: 4},
+		{TenantID: "retail", Quota: "accounts", Used: 1, Limit: 5},
+		{TenantID: "retail", Quota: "daily_transactions", Used: 10, Limit: 10, Warnings: 1, Rejections: 1},
+	}, m.Stats().Quotas)
+}
+
+func TestQuotas_Nil(t *testing.T) {
+	var m *Quotas
+	m.Observe("retail", "accounts", 1, 5, true)
+	m.Reject("retail", "accounts", 5, 5)
+	assert.Empty(t, m.Stats().Quotas)
+}
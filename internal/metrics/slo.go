@@ -0,0 +1,359 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// SLO objectives
+const (
+	ObjectiveAvailability = "availability" // share of requests not failing with a 5xx
+	ObjectiveLatency      = "latency"      // share of requests served within the threshold
+)
+
+// SLO is the service level objective of one endpoint
+type SLO struct {
+	Endpoint           string // ServeMux pattern, e.g. "POST /transactions"
+	LatencyThreshold   time.Duration
+	LatencyTarget      float64 // e.g. 0.99: 99% of requests are served within the threshold
+	AvailabilityTarget float64 // e.g. 0.999: 99.9% of requests don't fail with a 5xx
+}
+
+// ParseSLOs parses objectives keyed by endpoint pattern, each given as
+// "threshold/latency_target/availability_target", e.g. {"POST /transactions": "300ms/0.99/0.999"}
+func ParseSLOs(raw map[string]string) ([]SLO, error) {
+	slos := make([]SLO, 0, len(raw))
+	for endpoint, value := range raw {
+		parts := strings.Split(value, "/")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%w: SLO of %s must be threshold/latency_target/availability_target", errors.ErrValidationFailed, endpoint)
+		}
+		threshold, err := time.ParseDuration(strings.TrimSpace(parts[0]))
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("%w: invalid latency threshold %q of %s", errors.ErrValidationFailed, parts[0], endpoint)
+		}
+		slo := SLO{Endpoint: strings.TrimSpace(endpoint), LatencyThreshold: threshold}
+		for i, target := range []*float64{&slo.LatencyTarget, &slo.AvailabilityTarget} {
+			if *target, err = strconv.ParseFloat(strings.TrimSpace(parts[i+1]), 64); err != nil || *target <= 0 || *target >= 1 {
+				return nil, fmt.Errorf("%w: SLO target %q of %s must be between 0 and 1", errors.ErrValidationFailed, parts[i+1], endpoint)
+			}
+		}
+		slos = append(slos, slo)
+	}
+	sort.Slice(slos, func(i, j int) bool { return slos[i].Endpoint < slos[j].Endpoint })
+	return slos, nil
+}
+
+// BurnRateRule fires when the error budget burns at least Factor times faster than sustainable
+// over both its long and its short window; the short window makes it resolve soon after the
+// burn stops
+type BurnRateRule struct {
+	Name   string
+	Long   time.Duration
+	Short  time.Duration
+	Factor float64
+}
+
+// DefaultBurnRateRules are the multiwindow rules of the SRE workbook: a fast burn spending 2% of a
+// 30-day budget in an hour, and a slow burn spending 5% in six hours
+var DefaultBurnRateRules = []BurnRateRule{
+	{Name: "fast_burn", Long: time.Hour, Short: 5 * time.Minute, Factor: 14.4},
+	{Name: "slow_burn", Long: 6 * time.Hour, Short: 30 * time.Minute, Factor: 6},
+}
+
+// SLOAlert reports a burn rate rule of an objective starting or stopping to fire
+type SLOAlert struct {
+	Endpoint  string  `json:"endpoint"`
+	Objective string  `json:"objective"`
+	Rule      string  `json:"rule"`
+	Firing    bool    `json:"firing"`
+	BurnRate  float64 `json:"burn_rate"` // over the rule's long window
+	Factor    float64 `json:"factor"`
+	At        string  `json:"at"`
+}
+
+// SLOStatus is a snapshot of one objective of an endpoint over the compliance window
+type SLOStatus struct {
+	Endpoint             string             `json:"endpoint"`
+	Objective            string             `json:"objective"`
+	Target               float64            `json:"target"`
+	WindowSeconds        int64              `json:"window_seconds"`
+	Requests             uint64             `json:"requests"`
+	Bad                  uint64             `json:"bad"`
+	Compliance           float64            `json:"compliance"`             // 1 without requests
+	ErrorBudgetRemaining float64            `json:"error_budget_remaining"` // share left; negative once overspent
+	BurnRates            map[string]float64 `json:"burn_rates"`             // keyed by window, e.g. "1h0m0s"
+	Firing               []string           `json:"firing"`
+}
+
+// sloBucket counts the requests of one minute
+type sloBucket struct {
+	minute int64
+	total  uint64
+	errors uint64
+	slow   uint64
+}
+
+type endpointSLO struct {
+	slo     SLO
+	buckets []sloBucket // ring of minutes, indexed by minute modulo its length
+	firing  map[string]bool
+}
+
+// SLOTracker keeps per-minute request counts of the endpoints with an objective over a rolling
+// compliance window, from which it computes compliance and error budget burn rates. Hooks added
+// with OnAlert are told when a burn rate rule starts or stops firing; Evaluate checks the rules,
+// and Run does so periodically. A nil *SLOTracker records nothing.
+type SLOTracker struct {
+	routes *http.ServeMux // matches requests to the pattern of their objective
+	window time.Duration
+	rules  []BurnRateRule
+	now    func() time.Time
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointSLO
+	hooks     []func(SLOAlert)
+	log       *slog.Logger
+}
+
+// NewSLOTracker creates a tracker of slos over a compliance window of at least an hour, alerting
+// by rules. It fails if an endpoint pattern is invalid or listed twice.
+func NewSLOTracker(slos []SLO, window time.Duration, rules []BurnRateRule) (_ *SLOTracker, err error) {
+	if window < time.Hour {
+		window = time.Hour
+	}
+	t := &SLOTracker{
+		routes:    http.NewServeMux(),
+		window:    window,
+		rules:     rules,
+		now:       time.Now,
+		endpoints: make(map[string]*endpointSLO, len(slos)),
+		log:       logger.Default(),
+	}
+	defer func() {
+		// ServeMux panics on invalid or conflicting patterns
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: invalid SLO endpoint: %v", errors.ErrValidationFailed, p)
+		}
+	}()
+	for _, slo := range slos {
+		t.routes.Handle(slo.Endpoint, http.NotFoundHandler())
+		t.endpoints[slo.Endpoint] = &endpointSLO{
+			slo:     slo,
+			buckets: make([]sloBucket, int(window/time.Minute)),
+			firing:  make(map[string]bool),
+		}
+	}
+	return t, nil
+}
+
+// OnAlert adds a hook called with every alert raised by Evaluate
+func (t *SLOTracker) OnAlert(hook func(SLOAlert)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks = append(t.hooks, hook)
+}
+
+// Record counts a request that got status after elapsed, if its endpoint has an objective
+func (t *SLOTracker) Record(r *http.Request, status int, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	_, pattern := t.routes.Handler(r)
+	endpoint, ok := t.endpoints[pattern]
+	if !ok {
+		return
+	}
+	minute := t.now().Unix() / 60
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bucket := &endpoint.buckets[minute%int64(len(endpoint.buckets))]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if status >= http.StatusInternalServerError {
+		bucket.errors++
+	}
+	if elapsed > endpoint.slo.LatencyThreshold {
+		bucket.slow++
+	}
+}
+
+// Status returns a snapshot of every objective, ordered by endpoint
+func (t *SLOTracker) Status() []SLOStatus {
+	statuses := []SLOStatus{}
+	if t == nil {
+		return statuses
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, endpoint := range t.sortedEndpoints() {
+		for _, objective := range []string{ObjectiveAvailability, ObjectiveLatency} {
+			target := endpoint.target(objective)
+			total, bad := endpoint.sum(now, t.window, objective)
+			status := SLOStatus{
+				Endpoint:             endpoint.slo.Endpoint,
+				Objective:            objective,
+				Target:               target,
+				WindowSeconds:        int64(t.window / time.Second),
+				Requests:             total,
+				Bad:                  bad,
+				Compliance:           1,
+				ErrorBudgetRemaining: 1,
+				BurnRates:            make(map[string]float64),
+				Firing:               []string{},
+			}
+			if total > 0 {
+				status.Compliance = 1 - float64(bad)/float64(total)
+				status.ErrorBudgetRemaining = 1 - burnRate(total, bad, target)
+			}
+			for _, rule := range t.rules {
+				for _, window := range []time.Duration{rule.Short, rule.Long} {
+					status.BurnRates[window.String()] = endpoint.burnRate(now, window, objective)
+				}
+				if endpoint.firing[objective+"/"+rule.Name] {
+					status.Firing = append(status.Firing, rule.Name)
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// Evaluate checks every burn rate rule of every objective and calls the hooks with the alerts of
+// rules that started or stopped firing since the last evaluation
+func (t *SLOTracker) Evaluate() []SLOAlert {
+	if t == nil {
+		return nil
+	}
+	now := t.now()
+
+	t.mu.Lock()
+	var alerts []SLOAlert
+	for _, endpoint := range t.sortedEndpoints() {
+		for _, objective := range []string{ObjectiveAvailability, ObjectiveLatency} {
+			for _, rule := range t.rules {
+				long := endpoint.burnRate(now, rule.Long, objective)
+				firing := long >= rule.Factor && endpoint.burnRate(now, rule.Short, objective) >= rule.Factor
+				key := objective + "/" + rule.Name
+				if firing == endpoint.firing[key] {
+					continue
+				}
+				endpoint.firing[key] = firing
+				alerts = append(alerts, SLOAlert{
+					Endpoint:  endpoint.slo.Endpoint,
+					Objective: objective,
+					Rule:      rule.Name,
+					Firing:    firing,
+					BurnRate:  long,
+					Factor:    rule.Factor,
+					At:        now.UTC().Format(time.RFC3339),
+				})
+			}
+		}
+	}
+	hooks := t.hooks
+	t.mu.Unlock()
+
+	for _, alert := range alerts {
+		if alert.Firing {
+			t.log.Warn("SLO alert firing", "endpoint", alert.Endpoint, "objective", alert.Objective, "rule", alert.Rule, "burn_rate", alert.BurnRate, "factor", alert.Factor)
+		} else {
+			t.log.Info("SLO alert resolved", "endpoint", alert.Endpoint, "objective", alert.Objective, "rule", alert.Rule)
+		}
+		for _, hook := range hooks {
+			hook(alert)
+		}
+	}
+	return alerts
+}
+
+// Run evaluates the rules on every interval until ctx is cancelled
+func (t *SLOTracker) Run(ctx context.Context, interval time.Duration) error {
+	t.log.Info("SLO evaluation started", "endpoints", len(t.endpoints), "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.log.Info("SLO evaluation stopped")
+			return ctx.Err()
+		case <-ticker.C:
+			t.Evaluate()
+		}
+	}
+}
+
+// sortedEndpoints returns the tracked endpoints ordered by pattern
+func (t *SLOTracker) sortedEndpoints() []*endpointSLO {
+	endpoints := make([]*endpointSLO, 0, len(t.endpoints))
+	for _, endpoint := range t.endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].slo.Endpoint < endpoints[j].slo.Endpoint })
+	return endpoints
+}
+
+// target returns the target of objective
+func (e *endpointSLO) target(objective string) float64 {
+	if objective == ObjectiveLatency {
+		return e.slo.LatencyTarget
+	}
+	return e.slo.AvailabilityTarget
+}
+
+// sum returns the requests and the bad requests for objective in the window ending at now,
+// including the current minute
+func (e *endpointSLO) sum(now time.Time, window time.Duration, objective string) (total, bad uint64) {
+	minute := now.Unix() / 60
+	minutes := min(int64(window/time.Minute), int64(len(e.buckets)))
+	for m := minute - minutes + 1; m <= minute; m++ {
+		bucket := e.buckets[m%int64(len(e.buckets))]
+		if bucket.minute != m {
+			continue
+		}
+		total += bucket.total
+		if objective == ObjectiveLatency {
+			bad += bucket.slow
+		} else {
+			bad += bucket.errors
+		}
+	}
+	return total, bad
+}
+
+// burnRate returns how many times faster than sustainable the error budget of objective burned
+// in the window ending at now
+func (e *endpointSLO) burnRate(now time.Time, window time.Duration, objective string) float64 {
+	total, bad := e.sum(now, window, objective)
+	return burnRate(total, bad, e.target(objective))
+}
+
+// burnRate returns the share of bad requests relative to the share the target allows
+func burnRate(total, bad uint64, target float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / (1 - target)
+}
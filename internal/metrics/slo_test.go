@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs(map[string]string{"POST /transactions": "300ms/0.99/0.999"})
+	require.NoError(t, err)
+	assert.Equal(t, []SLO{{Endpoint: "POST /transactions", LatencyThreshold: 300 * time.Millisecond, LatencyTarget: 0.99, AvailabilityTarget: 0.999}}, slos)
+
+	for _, value := range []string{"300ms/0.99", "fast/0.99/0.999", "300ms/1/0.999", "300ms/0.99/0"} {
+		_, err := ParseSLOs(map[string]string{"POST /transactions": value})
+		assert.ErrorIs(t, err, domainErrors.ErrValidationFailed, value)
+	}
+}
+
+func TestNewSLOTracker_InvalidEndpoint(t *testing.T) {
+	_, err := NewSLOTracker([]SLO{{Endpoint: "POST /a"}, {Endpoint: "POST /a"}}, time.Hour, nil)
+	assert.ErrorIs(t, err, domainErrors.ErrValidationFailed)
+}
+
+func TestSLOTracker_Status(t *testing.T) {
+	tracker, err := NewSLOTracker([]SLO{{
+		Endpoint:           "GET /accounts/{account_id}",
+		LatencyThreshold:   100 * time.Millisecond,
+		LatencyTarget:      0.9,
+		AvailabilityTarget: 0.99,
+	}}, 24*time.Hour, DefaultBurnRateRules)
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	get := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	for i := 0; i < 97; i++ {
+		tracker.Record(get, http.StatusOK, 10*time.Millisecond)
+	}
+	tracker.Record(get, http.StatusOK, time.Second)
+	tracker.Record(get, http.StatusServiceUnavailable, 10*time.Millisecond)
+	tracker.Record(get, http.StatusNotFound, 10*time.Millisecond)
+	// Endpoints without an objective aren't tracked
+	tracker.Record(httptest.NewRequest(http.MethodPost, "/transactions", nil), http.StatusInternalServerError, 0)
+
+	statuses := tracker.Status()
+	require.Len(t, statuses, 2)
+
+	availability := statuses[0]
+	assert.Equal(t, ObjectiveAvailability, availability.Objective)
+	assert.Equal(t, uint64(100), availability.Requests)
+	assert.Equal(t, uint64(1), availability.Bad)
+	assert.InDelta(t, 0.99, availability.Compliance, 1e-9)
+	assert.InDelta(t, 0, availability.ErrorBudgetRemaining, 1e-9)
+	assert.InDelta(t, 1, availability.BurnRates["1h0m0s"], 1e-9)
+
+	latency := statuses[1]
+	assert.Equal(t, ObjectiveLatency, latency.Objective)
+	assert.Equal(t, uint64(1), latency.Bad)
+	assert.InDelta(t, 0.9, latency.ErrorBudgetRemaining, 1e-9)
+	assert.InDelta(t, 0.1, latency.BurnRates["5m0s"], 1e-9)
+
+	// Minutes older than a window drop out of it
+	now = now.Add(10 * time.Minute)
+	statuses = tracker.Status()
+	assert.Zero(t, statuses[0].BurnRates["5m0s"])
+	assert.InDelta(t, 1, statuses[0].BurnRates["30m0s"], 1e-9)
+	now = now.Add(24 * time.Hour)
+	assert.Zero(t, tracker.Status()[0].Requests)
+}
+
+func TestSLOTracker_Evaluate(t *testing.T) {
+	tracker, err := NewSLOTracker([]SLO{{
+		Endpoint:           "POST /transactions",
+		LatencyThreshold:   time.Second,
+		LatencyTarget:      0.9,
+		AvailabilityTarget: 0.99,
+	}}, 24*time.Hour, DefaultBurnRateRules)
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	var received []SLOAlert
+	tracker.OnAlert(func(alert SLOAlert) { received = append(received, alert) })
+
+	// 20% of requests failing burns the 1% budget 20 times too fast: both rules fire
+	post := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	for i := 0; i < 100; i++ {
+		status := http.StatusCreated
+		if i%5 == 0 {
+			status = http.StatusInternalServerError
+		}
+		tracker.Record(post, status, time.Millisecond)
+	}
+	alerts := tracker.Evaluate()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, received, alerts)
+	assert.Equal(t, "fast_burn", alerts[0].Rule)
+	assert.True(t, alerts[0].Firing)
+	assert.InDelta(t, 20, alerts[0].BurnRate, 1e-9)
+	assert.Equal(t, []string{"fast_burn", "slow_burn"}, tracker.Status()[0].Firing)
+
+	// Firing rules aren't raised again
+	assert.Empty(t, tracker.Evaluate())
+
+	// Once the failures leave the short windows, both rules resolve
+	now = now.Add(31 * time.Minute)
+	tracker.Record(post, http.StatusCreated, time.Millisecond)
+	alerts = tracker.Evaluate()
+	require.Len(t, alerts, 2)
+	assert.False(t, alerts[0].Firing)
+	assert.False(t, alerts[1].Firing)
+	assert.Len(t, received, 4)
+}
+
+func TestSLOTracker_Nil(t *testing.T) {
+	var tracker *SLOTracker
+	tracker.Record(httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, 0)
+	tracker.OnAlert(func(SLOAlert) {})
+	assert.Empty(t, tracker.Evaluate())
+	assert.Empty(t, tracker.Status())
+}
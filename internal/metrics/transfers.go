@@ -0,0 +1,184 @@
+// Package metrics collects in-process counters that are served as JSON snapshots on the admin
+// endpoints, and writes metrics in the OpenMetrics format for scrapers
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// internalErrorCode is the code errors.Code returns for errors that aren't domain errors
+const internalErrorCode = "internal_error"
+
+// insufficientBalanceCode is the code of errors.ErrInsufficientBalance
+const insufficientBalanceCode = "insufficient_balance"
+
+// TenantTransferStats are the transfer outcomes of the accounts of one tenant
+type TenantTransferStats struct {
+	TenantID                string            `json:"tenant_id"`
+	Accepted                uint64            `json:"accepted"`
+	Rejected                uint64            `json:"rejected"`
+	RejectedToAcceptedRatio float64           `json:"rejected_to_accepted_ratio"`
+	RejectionsByCode        map[string]uint64 `json:"rejections_by_code"`
+}
+
+// TransferStats is a snapshot of the transfer outcome counters. Tenants are sorted by ID; the
+// entry with an empty ID covers accounts without a tenant and transfers whose source account
+// couldn't be read.
+type TransferStats struct {
+	Accepted                uint64                `json:"accepted"`
+	Rejected                uint64                `json:"rejected"`
+	RejectedToAcceptedRatio float64               `json:"rejected_to_accepted_ratio"`
+	RejectionsByCode        map[string]uint64     `json:"rejections_by_code"`
+	Tenants                 []TenantTransferStats `json:"tenants"`
+	// VolumeByCurrency is the amount moved by accepted transfers, in the currency of the source
+	VolumeByCurrency map[string]decimal.Decimal `json:"volume_by_currency"`
+}
+
+// Transfers counts accepted transfers and transfers rejected with a domain error, by error code
+// and by the tenant of the source account. Failures that aren't domain errors, such as a lost
+// database connection, say nothing about the transfer and aren't counted. A nil *Transfers
+// records nothing, so callers don't need to check whether metrics are enabled.
+type Transfers struct {
+	mu      sync.Mutex
+	tenants map[string]*tenantCounters
+	volume  map[string]decimal.Decimal // by currency
+}
+
+type tenantCounters struct {
+	accepted uint64
+	rejected map[string]uint64 // by error code
+}
+
+// NewTransfers creates an empty set of transfer counters
+func NewTransfers() *Transfers {
+	return &Transfers{tenants: make(map[string]*tenantCounters), volume: make(map[string]decimal.Decimal)}
+}
+
+// Record counts the outcome of a transfer from an account of tenantID: accepted if err is nil,
+// rejected under its error code if err is a domain error, and not at all otherwise
+func (m *Transfers) Record(tenantID string, err error) {
+	if m == nil {
+		return
+	}
+	code := ""
+	if err != nil {
+		if code = errors.Code(err); code == internalErrorCode {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counters, ok := m.tenants[tenantID]
+	if !ok {
+		counters = &tenantCounters{rejected: make(map[string]uint64)}
+		m.tenants[tenantID] = counters
+	}
+	if err == nil {
+		counters.accepted++
+	} else {
+		counters.rejected[code]++
+	}
+}
+
+// AddVolume adds the amount of an accepted transfer to the volume moved in currency
+func (m *Transfers) AddVolume(currency string, amount decimal.Decimal) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.volume[currency] = m.volume[currency].Add(amount)
+}
+
+// Stats returns a snapshot of the counters. A ratio is zero until a transfer is accepted.
+func (m *Transfers) Stats() TransferStats {
+	stats := TransferStats{
+		RejectionsByCode: make(map[string]uint64),
+		Tenants:          []TenantTransferStats{},
+		VolumeByCurrency: make(map[string]decimal.Decimal),
+	}
+	if m == nil {
+		return stats
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for tenantID, counters := range m.tenants {
+		tenant := TenantTransferStats{
+			TenantID:         tenantID,
+			Accepted:         counters.accepted,
+			RejectionsByCode: make(map[string]uint64, len(counters.rejected)),
+		}
+		for code, n := range counters.rejected {
+			tenant.Rejected += n
+			tenant.RejectionsByCode[code] = n
+			stats.RejectionsByCode[code] += n
+		}
+		tenant.RejectedToAcceptedRatio = ratio(tenant.Rejected, tenant.Accepted)
+		stats.Accepted += tenant.Accepted
+		stats.Rejected += tenant.Rejected
+		stats.Tenants = append(stats.Tenants, tenant)
+	}
+	stats.RejectedToAcceptedRatio = ratio(stats.Rejected, stats.Accepted)
+	for currency, amount := range m.volume {
+		stats.VolumeByCurrency[currency] = amount
+	}
+	sort.Slice(stats.Tenants, func(i, j int) bool { return stats.Tenants[i].TenantID < stats.Tenants[j].TenantID })
+	return stats
+}
+
+// Collect writes the transfers counter by outcome and rejection code, the transfer_volume counter
+// by currency, and insufficient_balance_rejections, the rejections for lack of funds. Amounts are
+// exposed as floats, which is precise enough for dashboards but not for reconciliation.
+func (m *Transfers) Collect(_ context.Context, out *OpenMetricsWriter) error {
+	stats := m.Stats()
+
+	transfers := []Sample{{Labels: map[string]string{"outcome": "accepted"}, Value: float64(stats.Accepted)}}
+	codes := make([]string, 0, len(stats.RejectionsByCode))
+	for code := range stats.RejectionsByCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		transfers = append(transfers, Sample{
+			Labels: map[string]string{"outcome": "rejected", "code": code},
+			Value:  float64(stats.RejectionsByCode[code]),
+		})
+	}
+
+	currencies := make([]string, 0, len(stats.VolumeByCurrency))
+	for currency := range stats.VolumeByCurrency {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	volume := make([]Sample, 0, len(currencies))
+	for _, currency := range currencies {
+		volume = append(volume, Sample{
+			Labels: map[string]string{"currency": currency},
+			Value:  stats.VolumeByCurrency[currency].InexactFloat64(),
+		})
+	}
+
+	out.Counter("transfers", "Transfers by outcome, and rejected transfers by error code.", transfers...)
+	out.Counter("transfer_volume", "Amount moved by accepted transfers, by source currency.", volume...)
+	out.Counter("insufficient_balance_rejections", "Transfers rejected for insufficient balance.",
+		Sample{Value: float64(stats.RejectionsByCode[insufficientBalanceCode])})
+	return nil
+}
+
+func ratio(rejected, accepted uint64) float64 {
+	if accepted == 0 {
+		return 0
+	}
+	return float64(rejected) / float64(accepted)
+}
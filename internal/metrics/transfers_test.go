@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfers_Stats(t *testing.T) {
+	m := NewTransfers()
+	insufficient := domainErrors.NewInsufficientBalanceError(1, decimal.NewFromInt(10), decimal.NewFromInt(5))
+
+	for i := 0; i < 4; i++ {
+		m.Record("retail", nil)
+	}
+	m.Record("retail", insufficient)
+	m.Record("retail", fmt.Errorf("%w: over the limit", domainErrors.ErrTransferLimitExceeded))
+	m.Record("", nil)
+	m.Record("", insufficient)
+	m.Record("corporate", domainErrors.ErrSourceAccountNotFound)
+	// Not a domain error, so not counted
+	m.Record("retail", errors.New("connection reset"))
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(5), stats.Accepted)
+	assert.Equal(t, uint64(4), stats.Rejected)
+	assert.InDelta(t, 0.8, stats.RejectedToAcceptedRatio, 1e-9)
+	assert.Equal(t, map[string]uint64{
+		"insufficient_balance":     2,
+		"transfer_limit_exceeded":  1,
+		"source_account_not_found": 1,
+	}, stats.RejectionsByCode)
+
+	if assert.Len(t, stats.Tenants, 3) {
+		assert.Equal(t, "", stats.Tenants[0].TenantID)
+		assert.InDelta(t, 1.0, stats.Tenants[0].RejectedToAcceptedRatio, 1e-9)
+
+		assert.Equal(t, "corporate", stats.Tenants[1].TenantID)
+		assert.Equal(t, uint64(1), stats.Tenants[1].Rejected)
+		assert.Zero(t, stats.Tenants[1].RejectedToAcceptedRatio)
+
+		retail := stats.Tenants[2]
+		assert.Equal(t, "retail", retail.TenantID)
+		assert.Equal(t, uint64(4), retail.Accepted)
+		assert.Equal(t, uint64(2), retail.Rejected)
+		assert.InDelta(t, 0.5, retail.RejectedToAcceptedRatio, 1e-9)
+		assert.Equal(t, map[string]uint64{"insufficient_balance": 1, "transfer_limit_exceeded": 1}, retail.RejectionsByCode)
+	}
+}
+
+func TestTransfers_Nil(t *testing.T) {
+	var m *Transfers
+	m.Record("retail", nil)
+	m.AddVolume("EUR", decimal.NewFromInt(10))
+
+	stats := m.Stats()
+	assert.Zero(t, stats.Accepted)
+	assert.Empty(t, stats.Tenants)
+}
+
+func TestTransfers_Collect(t *testing.T) {
+	m := NewTransfers()
+	m.Record("retail", nil)
+	m.AddVolume("EUR", decimal.RequireFromString("10.25"))
+	m.Record("retail", nil)
+	m.AddVolume("EUR", decimal.RequireFromString("4.75"))
+	m.Record("corporate", nil)
+	m.AddVolume("USD", decimal.NewFromInt(100))
+	m.Record("retail", domainErrors.NewInsufficientBalanceError(1, decimal.NewFromInt(10), decimal.NewFromInt(5)))
+	m.Record("retail", domainErrors.ErrSourceAccountNotFound)
+
+	assert.True(t, m.Stats().VolumeByCurrency["EUR"].Equal(decimal.NewFromInt(15)))
+
+	var out strings.Builder
+	w := NewOpenMetricsWriter(&out)
+	require.NoError(t, m.Collect(context.Background(), w))
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, `# TYPE transfers counter
+# HELP transfers Transfers by outcome, and rejected transfers by error code.
+transfers_total{outcome="accepted"} 3
+transfers_total{code="insufficient_balance",outcome="rejected"} 1
+transfers_total{code="source_account_not_found",outcome="rejected"} 1
+# TYPE transfer_volume counter
+# HELP transfer_volume Amount moved by accepted transfers, by source currency.
+transfer_volume_total{currency="EUR"} 15
+transfer_volume_total{currency="USD"} 100
+# TYPE insufficient_balance_rejections counter
+# HELP insufficient_balance_rejections Transfers rejected for insufficient balance.
+insufficient_balance_rejections_total 1
+# EOF
+`, out.String())
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+)
+
+// WorkerLiveness is the last heartbeat of one background worker. A worker is alive while its
+// last heartbeat is no older than its interval plus the grace period.
+type WorkerLiveness struct {
+	Subsystem        string  `json:"subsystem"`
+	Worker           int     `json:"worker"`
+	Beats            uint64  `json:"beats"`
+	LastBeatAt       string  `json:"last_beat_at"`
+	SecondsSinceBeat float64 `json:"seconds_since_beat"`
+	IntervalSeconds  float64 `json:"interval_seconds"`
+	Alive            bool    `json:"alive"`
+}
+
+// WorkerHealth is a snapshot of the liveness of the running workers, sorted by subsystem and
+// worker. It is healthy if every worker is alive.
+type WorkerHealth struct {
+	Healthy bool             `json:"healthy"`
+	Workers []WorkerLiveness `json:"workers"`
+}
+
+// Workers tracks the heartbeats of the background workers, such as the sweeper, the outbox relay
+// and the worker pool. A worker beats after every batch it runs; one that stops beating while it
+// should be running, because it is stuck on a lock or its goroutine died, shows as not alive. A
+// nil *Workers records nothing.
+type Workers struct {
+	grace time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	workers map[workerKey]*workerState
+}
+
+type workerKey struct {
+	subsystem string
+	worker    int
+}
+
+type workerState struct {
+	interval time.Duration
+	beats    uint64
+	lastBeat time.Time
+}
+
+// NewWorkers creates a liveness tracker allowing workers grace beyond their interval between
+// heartbeats
+func NewWorkers(grace time.Duration) *Workers {
+	return &Workers{grace: grace, now: time.Now, workers: make(map[workerKey]*workerState)}
+}
+
+// SetClock makes the tracker time heartbeats with c instead of the wall clock
+func (m *Workers) SetClock(c clock.Clock) {
+	if m != nil {
+		m.now = c.Now
+	}
+}
+
+// Start registers a worker of subsystem that beats at least every interval while idle, counting
+// its start as its first heartbeat
+func (m *Workers) Start(subsystem string, worker int, interval time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.workers[workerKey{subsystem: subsystem, worker: worker}] = &workerState{interval: interval, beats: 1, lastBeat: m.now()}
+}
+
+// Beat records a heartbeat of a started worker
+func (m *Workers) Beat(subsystem string, worker int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state, ok := m.workers[workerKey{subsystem: subsystem, worker: worker}]; ok {
+		state.beats++
+		state.lastBeat = m.now()
+	}
+}
+
+// Stop unregisters a worker that stopped on purpose, so it isn't reported as stalled
+func (m *Workers) Stop(subsystem string, worker int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.workers, workerKey{subsystem: subsystem, worker: worker})
+}
+
+// Health returns the liveness of every running worker
+func (m *Workers) Health() WorkerHealth {
+	health := WorkerHealth{Healthy: true, Workers: []WorkerLiveness{}}
+	if m == nil {
+		return health
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for key, state := range m.workers {
+		since := now.Sub(state.lastBeat)
+		alive := since <= state.interval+m.grace
+		health.Healthy = health.Healthy && alive
+		health.Workers = append(health.Workers, WorkerLiveness{
+			Subsystem:        key.subsystem,
+			Worker:           key.worker,
+			Beats:            state.beats,
+			LastBeatAt:       state.lastBeat.UTC().Format(time.RFC3339),
+			SecondsSinceBeat: since.Seconds(),
+			IntervalSeconds:  state.interval.Seconds(),
+			Alive:            alive,
+		})
+	}
+	sort.Slice(health.Workers, func(i, j int) bool {
+		a, b := health.Workers[i], health.Workers[j]
+		if a.Subsystem != b.Subsystem {
+			return a.Subsystem < b.Subsystem
+		}
+		return a.Worker < b.Worker
+	})
+	return health
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkers_Health(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	m := NewWorkers(10 * time.Second)
+	m.now = func() time.Time { return now }
+
+	m.Start("transfer_jobs", 1, time.Second)
+	m.Start("transfer_jobs", 0, time.Second)
+	m.Start("sweeper", 0, time.Minute)
+	m.Start("outbox_relay", 0, time.Second)
+	m.Stop("outbox_relay", 0)
+
+	now = now.Add(30 * time.Second)
+	m.Beat("transfer_jobs", 0)
+	m.Beat("stopped", 0)
+
+	health := m.Health()
+	assert.False(t, health.Healthy)
+	require.Len(t, health.Workers, 3, "stopped workers aren't reported")
+
+	assert.Equal(t, WorkerLiveness{
+		Subsystem: "sweeper", Worker: 0, Beats: 1, LastBeatAt: "2024-03-01T12:00:00Z",
+		SecondsSinceBeat: 30, IntervalSeconds: 60, Alive: true,
+	}, health.Workers[0])
+	assert.Equal(t, "transfer_jobs", health.Workers[1].Subsystem)
+	assert.Equal(t, 0, health.Workers[1].Worker)
+	assert.Equal(t, uint64(2), health.Workers[1].Beats)
+	assert.True(t, health.Workers[1].Alive)
+	assert.False(t, health.Workers[2].Alive, "no heartbeat within a second plus the grace period")
+}
+
+func TestWorkers_Nil(t *testing.T) {
+	var m *Workers
+	m.Start("sweeper", 0, time.Minute)
+	m.Beat("sweeper", 0)
+	m.Stop("sweeper", 0)
+	health := m.Health()
+	assert.True(t, health.Healthy)
+	assert.Empty(t, health.Workers)
+}
@@ -0,0 +1,352 @@
+// Package migrate contains online schema migration helpers that run against a live database
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// amountColumn describes a NUMERIC money column that can be widened
+type amountColumn struct {
+	table      string
+	column     string
+	key        string // single-column primary key, integer or text, used to walk the table in batches
+	constraint string // check constraint re-created on the widened column, if any
+	check      string
+	nullable   bool     // NULL is meaningful, so the widened column isn't made NOT NULL
+	dependents []string // constraints of other columns that refer to this one, re-created by the swap
+}
+
+// amountColumns are the money columns of the schema
+var amountColumns = []amountColumn{
+	{table: "accounts", column: "balance", key: "account_id", constraint: "accounts_balance_check", check: "balance >= -overdraft_limit OR account_type = 'system_float'"},
+	{table: "accounts", column: "initial_balance", key: "account_id"},
+	{table: "accounts", column: "reserved_balance", key: "account_id", constraint: "accounts_reserved_balance_check", check: "reserved_balance >= 0"},
+	{table: "accounts", column: "overdraft_limit", key: "account_id", constraint: "accounts_overdraft_limit_check", check: "overdraft_limit >= 0", dependents: []string{"accounts_balance_check"}},
+	{table: "accounts", column: "per_transaction_limit", key: "account_id", constraint: "accounts_per_transaction_limit_check", check: "per_transaction_limit > 0", nullable: true},
+	{table: "accounts", column: "daily_limit", key: "account_id", constraint: "accounts_daily_limit_check", check: "daily_limit > 0", nullable: true},
+	{table: "accounts", column: "monthly_limit", key: "account_id", constraint: "accounts_monthly_limit_check", check: "monthly_limit > 0", nullable: true},
+	{table: "transactions", column: "amount", key: "id", constraint: "transactions_amount_check", check: "amount > 0"},
+	{table: "transactions", column: "converted_amount", key: "id", constraint: "transactions_converted_amount_check", check: "converted_amount > 0", nullable: true},
+	{table: "suspense_items", column: "amount", key: "id", constraint: "suspense_items_amount_check", check: "amount > 0"},
+	{table: "preauthorizations", column: "amount", key: "id", constraint: "preauthorizations_amount_check", check: "amount > 0"},
+	{table: "ledger_entries", column: "amount", key: "id", constraint: "ledger_entries_amount_check", check: "amount > 0"},
+	{table: "balance_snapshots", column: "balance", key: "id"},
+	{table: "tenant_settings", column: "max_transfer_amount", key: "tenant_id", constraint: "tenant_settings_max_transfer_amount_check", check: "max_transfer_amount > 0", nullable: true},
+	{table: "fee_rules", column: "value", key: "id", constraint: "fee_rules_value_check", check: "value > 0"},
+	{table: "split_transfers", column: "amount", key: "id", constraint: "split_transfers_amount_check", check: "amount > 0"},
+	{table: "scheduled_transfers", column: "amount", key: "id", constraint: "scheduled_transfers_amount_check", check: "amount > 0"},
+	{table: "standing_orders", column: "amount", key: "id", constraint: "standing_orders_amount_check", check: "amount > 0"},
+	{table: "system_fundings", column: "amount", key: "id", constraint: "system_fundings_amount_check", check: "amount > 0"},
+	{table: "balance_adjustments", column: "amount", key: "id", constraint: "balance_adjustments_amount_check", check: "amount > 0"},
+	{table: "transfer_jobs", column: "amount", key: "id", constraint: "transfer_jobs_amount_check", check: "amount > 0"},
+	{table: "delegations", column: "max_amount", key: "id", constraint: "delegations_max_amount_check", check: "max_amount > 0", nullable: true},
+}
+
+// PrecisionMigration widens the precision/scale of the amount and balance columns without
+// holding long locks. For each column it:
+//
+//  1. adds a shadow column with the new type, kept in sync for new writes by a trigger
+//  2. backfills existing rows in small batches, pausing between batches
+//  3. verifies every shadow value equals the original
+//  4. swaps the columns in a short transaction, re-verifying under an exclusive lock and
+//     restoring the default and the indexes of the original column
+type PrecisionMigration struct {
+	db        *sql.DB
+	Precision int
+	Scale     int
+	BatchSize int
+	Pause     time.Duration // pause between batches to leave room for OLTP traffic
+	DryRun    bool          // only report what would change
+	log       *slog.Logger
+}
+
+// NewPrecisionMigration creates a migration to NUMERIC(precision, scale)
+func NewPrecisionMigration(db *sql.DB, precision, scale int) *PrecisionMigration {
+	return &PrecisionMigration{
+		db:        db,
+		Precision: precision,
+		Scale:     scale,
+		BatchSize: 1000,
+		Pause:     50 * time.Millisecond,
+		log:       logger.Default(),
+	}
+}
+
+// Run migrates every money column
+func (m *PrecisionMigration) Run(ctx context.Context) error {
+	if m.Scale > m.Precision || m.Precision > 1000 {
+		return fmt.Errorf("invalid target type NUMERIC(%d,%d)", m.Precision, m.Scale)
+	}
+
+	for _, col := range amountColumns {
+		if err := m.migrateColumn(ctx, col); err != nil {
+			return fmt.Errorf("failed to migrate %s.%s: %w", col.table, col.column, err)
+		}
+	}
+	return nil
+}
+
+func (m *PrecisionMigration) migrateColumn(ctx context.Context, col amountColumn) error {
+	precision, scale, err := m.currentType(ctx, col)
+	if err != nil {
+		return err
+	}
+	if precision > m.Precision || scale > m.Scale {
+		return fmt.Errorf("NUMERIC(%d,%d) -> NUMERIC(%d,%d) would narrow the column", precision, scale, m.Precision, m.Scale)
+	}
+	if precision == m.Precision && scale == m.Scale {
+		m.log.Info("Column is already at the target type, skipping", "table", col.table, "column", col.column, "precision", precision, "scale", scale)
+		return nil
+	}
+
+	m.log.Info("Widening column", "table", col.table, "column", col.column, "from", fmt.Sprintf("NUMERIC(%d,%d)", precision, scale),
+		"to", fmt.Sprintf("NUMERIC(%d,%d)", m.Precision, m.Scale))
+	if m.DryRun {
+		return nil
+	}
+
+	shadow := col.column + "_widened"
+	trigger := fmt.Sprintf("%s_%s_widen_sync", col.table, col.column)
+
+	// Step 1: shadow column and sync trigger. From here on every write keeps both columns equal.
+	steps := []string{
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s NUMERIC(%d,%d)`, col.table, shadow, m.Precision, m.Scale),
+		fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$
+			BEGIN
+				NEW.%s := NEW.%s;
+				RETURN NEW;
+			END;
+			$$ LANGUAGE plpgsql`, trigger, shadow, col.column),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %s ON %s`, trigger, col.table),
+		fmt.Sprintf(`CREATE TRIGGER %s BEFORE INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()`, trigger, col.table, trigger),
+	}
+	for _, stmt := range steps {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to prepare shadow column: %w", err)
+		}
+	}
+
+	// Step 2: backfill in key order
+	if err := m.backfill(ctx, col, shadow); err != nil {
+		return err
+	}
+
+	// Step 3: verify outside the lock so the swap only has to re-check
+	mismatches, err := m.countMismatches(ctx, m.db, col, shadow)
+	if err != nil {
+		return err
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("verification failed: %d rows differ between %s and %s", mismatches, col.column, shadow)
+	}
+
+	// Step 4: swap
+	return m.swap(ctx, col, shadow, trigger)
+}
+
+func (m *PrecisionMigration) currentType(ctx context.Context, col amountColumn) (int, int, error) {
+	var precision, scale int
+	err := m.db.QueryRowContext(ctx, `
+		SELECT numeric_precision, numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2
+	`, col.table, col.column).Scan(&precision, &scale)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read column type: %w", err)
+	}
+	return precision, scale, nil
+}
+
+func (m *PrecisionMigration) backfill(ctx context.Context, col amountColumn, shadow string) error {
+	// The first batch starts at the lowest key; later ones after the last key backfilled. Keys are
+	// passed as text so the same walk serves integer and text keys.
+	batch := func(where string) string {
+		return fmt.Sprintf(`
+			WITH batch AS (
+				SELECT %[2]s FROM %[1]s %[5]s ORDER BY %[2]s LIMIT $1
+			), backfilled AS (
+				UPDATE %[1]s SET %[3]s = %[1]s.%[4]s
+				FROM batch
+				WHERE %[1]s.%[2]s = batch.%[2]s
+				RETURNING %[1]s.%[2]s
+			)
+			SELECT COUNT(*), MAX(%[2]s)::text FROM backfilled
+		`, col.table, col.key, shadow, col.column, where)
+	}
+	first, next := batch(""), batch(fmt.Sprintf("WHERE %s > $2", col.key))
+
+	var (
+		lastKey sql.NullString
+		total   int64
+	)
+	for {
+		var (
+			n   int
+			err error
+		)
+		if lastKey.Valid {
+			err = m.db.QueryRowContext(ctx, next, m.BatchSize, lastKey.String).Scan(&n, &lastKey)
+		} else {
+			err = m.db.QueryRowContext(ctx, first, m.BatchSize).Scan(&n, &lastKey)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to backfill batch after key %q: %w", lastKey.String, err)
+		}
+
+		total += int64(n)
+		if n < m.BatchSize {
+			m.log.Info("Backfilled shadow column", "table", col.table, "shadow", shadow, "total", total)
+			return nil
+		}
+		m.log.Debug("Backfilling shadow column", "table", col.table, "shadow", shadow, "total", total, "last_key", lastKey.String)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.Pause):
+		}
+	}
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (m *PrecisionMigration) countMismatches(ctx context.Context, q querier, col amountColumn, shadow string) (int64, error) {
+	var mismatches int64
+	err := q.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT COUNT(*) FROM %s WHERE %s IS DISTINCT FROM %s`, col.table, shadow, col.column,
+	)).Scan(&mismatches)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify backfill: %w", err)
+	}
+	return mismatches, nil
+}
+
+func (m *PrecisionMigration) swap(ctx context.Context, col amountColumn, shadow, trigger string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting swap transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`LOCK TABLE %s IN ACCESS EXCLUSIVE MODE`, col.table)); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", col.table, err)
+	}
+
+	mismatches, err := m.countMismatches(ctx, tx, col, shadow)
+	if err != nil {
+		return err
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("verification under lock failed: %d rows differ", mismatches)
+	}
+
+	// Dropping the column drops its default and its indexes, so they are read first and restored
+	columnDefault, indexes, err := columnDependents(ctx, tx, col)
+	if err != nil {
+		return err
+	}
+
+	steps := []string{
+		fmt.Sprintf(`DROP TRIGGER %s ON %s`, trigger, col.table),
+		fmt.Sprintf(`DROP FUNCTION %s()`, trigger),
+		fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s`, col.table, col.column),
+		fmt.Sprintf(`ALTER TABLE %s RENAME COLUMN %s TO %s`, col.table, shadow, col.column),
+	}
+	if columnDefault.Valid {
+		steps = append(steps, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s`, col.table, col.column, columnDefault.String))
+	}
+	// The indexes are rebuilt under the lock; the money columns are indexed on few tables
+	steps = append(steps, indexes...)
+	if !col.nullable {
+		steps = append(steps, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s SET NOT NULL`, col.table, col.column))
+	}
+	if col.constraint != "" {
+		steps = append(steps, fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)`, col.table, col.constraint, col.check))
+	}
+	// Dropping the old column dropped the constraints of other columns that referred to it
+	for _, name := range col.dependents {
+		dependent, ok := constraintColumn(name)
+		if !ok {
+			return fmt.Errorf("unknown dependent constraint %s", name)
+		}
+		steps = append(steps, fmt.Sprintf(`ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)`, dependent.table, dependent.constraint, dependent.check))
+	}
+	for _, stmt := range steps {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to swap columns: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing swap: %w", err)
+	}
+	m.log.Info("Swapped in the widened column", "table", col.table, "column", col.column, "precision", m.Precision, "scale", m.Scale)
+	return nil
+}
+
+// columnDependents returns the default of a column, if it has one, and the definitions of the
+// indexes on it. An index that backs a constraint can't be rebuilt by its definition alone, so
+// a column with one is refused.
+func columnDependents(ctx context.Context, tx *sql.Tx, col amountColumn) (sql.NullString, []string, error) {
+	var columnDefault sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT column_default
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2
+	`, col.table, col.column).Scan(&columnDefault)
+	if err != nil {
+		return columnDefault, nil, fmt.Errorf("failed to read column default: %w", err)
+	}
+
+	// An index depends on every column it covers, in its keys, expressions or predicate
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT i.relname, pg_get_indexdef(i.oid), EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.oid)
+		FROM pg_depend d
+		JOIN pg_class i ON i.oid = d.objid AND i.relkind = 'i'
+		JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE d.classid = 'pg_class'::regclass AND d.refclassid = 'pg_class'::regclass
+			AND d.refobjid = (quote_ident(current_schema()) || '.' || quote_ident($1))::regclass
+			AND a.attname = $2
+		ORDER BY i.relname
+	`, col.table, col.column)
+	if err != nil {
+		return columnDefault, nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []string
+	for rows.Next() {
+		var name, definition string
+		var constraint bool
+		if err := rows.Scan(&name, &definition, &constraint); err != nil {
+			return columnDefault, nil, fmt.Errorf("failed to read indexes: %w", err)
+		}
+		if constraint {
+			return columnDefault, nil, fmt.Errorf("index %s backs a constraint and can't be rebuilt by the swap", name)
+		}
+		indexes = append(indexes, definition)
+	}
+	if err := rows.Err(); err != nil {
+		return columnDefault, nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return columnDefault, indexes, nil
+}
+
+// constraintColumn returns the money column whose check constraint is named name
+func constraintColumn(name string) (amountColumn, bool) {
+	for _, col := range amountColumns {
+		if col.constraint == name {
+			return col, true
+		}
+	}
+	return amountColumn{}, false
+}
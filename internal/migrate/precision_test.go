@@ -0,0 +1,125 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// notAmounts are the DECIMAL columns of the schema that don't hold money, so keep their own type
+var notAmounts = map[string]bool{
+	"transactions.fx_rate": true,
+}
+
+var (
+	createTable   = regexp.MustCompile(`(?i)^CREATE TABLE IF NOT EXISTS (\w+)`)
+	decimalColumn = regexp.MustCompile(`(?i)^\s*(\w+) DECIMAL\b`)
+	addDecimal    = regexp.MustCompile(`(?i)^ALTER TABLE (\w+) ADD COLUMN IF NOT EXISTS (\w+) DECIMAL\b`)
+)
+
+// decimalColumns returns every DECIMAL column the migrations create, as table.column
+func decimalColumns(t *testing.T) map[string]bool {
+	t.Helper()
+
+	_, b, _, _ := runtime.Caller(0)
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(b), "../..", "migrations", "*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	columns := make(map[string]bool)
+	for _, path := range paths {
+		migration, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		table := ""
+		for _, line := range strings.Split(string(migration), "\n") {
+			if m := createTable.FindStringSubmatch(line); m != nil {
+				table = m[1]
+				continue
+			}
+			if strings.HasPrefix(strings.TrimSpace(line), ")") {
+				table = ""
+				continue
+			}
+			if m := addDecimal.FindStringSubmatch(line); m != nil {
+				columns[m[1]+"."+m[2]] = true
+				continue
+			}
+			if m := decimalColumn.FindStringSubmatch(line); m != nil && table != "" {
+				columns[table+"."+m[1]] = true
+			}
+		}
+	}
+	return columns
+}
+
+func TestAmountColumns_MatchMigrations(t *testing.T) {
+	migrated := decimalColumns(t)
+
+	listed := make(map[string]bool)
+	for _, col := range amountColumns {
+		name := col.table + "." + col.column
+		assert.False(t, listed[name], "%s is listed twice", name)
+		listed[name] = true
+		assert.True(t, migrated[name], "%s is listed but no migration creates it as DECIMAL", name)
+	}
+
+	for name := range migrated {
+		if notAmounts[name] {
+			continue
+		}
+		assert.True(t, listed[name], "%s is a DECIMAL column missing from amountColumns", name)
+	}
+}
+
+func TestAmountColumns_DependentsAreListed(t *testing.T) {
+	for _, col := range amountColumns {
+		for _, name := range col.dependents {
+			_, ok := constraintColumn(name)
+			assert.True(t, ok, "%s.%s depends on unknown constraint %s", col.table, col.column, name)
+		}
+	}
+}
+
+func TestPrecisionMigration_KeepsDefaultsAndIndexes(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	ctx := context.Background()
+
+	migration := NewPrecisionMigration(db, 30, 8)
+	migration.Pause = 0
+	require.NoError(t, migration.Run(ctx))
+
+	for _, column := range []string{"initial_balance", "reserved_balance", "overdraft_limit"} {
+		var columnDefault sql.NullString
+		var scale int
+		err := db.QueryRowContext(ctx, `
+			SELECT column_default, numeric_scale
+			FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'accounts' AND column_name = $1
+		`, column).Scan(&columnDefault, &scale)
+		require.NoError(t, err)
+		assert.True(t, columnDefault.Valid, "accounts.%s lost its default", column)
+		assert.Equal(t, 8, scale, "accounts.%s wasn't widened", column)
+	}
+
+	var indexed bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema() AND indexname = 'idx_accounts_balance_id')
+	`).Scan(&indexed)
+	require.NoError(t, err)
+	assert.True(t, indexed, "idx_accounts_balance_id was dropped")
+
+	// Accounts are created without the columns that have defaults
+	_, err = db.ExecContext(ctx, `INSERT INTO accounts (account_id, balance, initial_balance) VALUES (1, 0.12345678, 0.12345678)`)
+	require.NoError(t, err)
+}
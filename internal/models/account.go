@@ -1,21 +1,231 @@
 package models
 
 import (
+	"fmt"
+
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/shopspring/decimal"
 )
 
+// AccountStatus represents the lifecycle status of an account
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	// AccountStatusFrozen marks an account barred by an operator from sending and receiving
+	// transfers until unfrozen
+	AccountStatusFrozen AccountStatus = "frozen"
+	// AccountStatusClosed marks an account that was emptied and closed for good
+	AccountStatusClosed AccountStatus = "closed"
+	// AccountStatusDormant marks an account without activity for the dormancy period; it still
+	// receives credits but may be barred from sending until reactivated
+	AccountStatusDormant AccountStatus = "dormant"
+)
+
+// AccountType classifies accounts, e.g. for minimum balance requirements
+type AccountType string
+
+// AccountTypeStandard is the type of accounts that weren't given one
+const AccountTypeStandard AccountType = "standard"
+
+// AccountTypeSystemFloat is the type of the system float accounts, which only move money by
+// funding and defunding the system and may go negative. It is reserved: accounts get it by being
+// designated the float account of a currency.
+const AccountTypeSystemFloat AccountType = "system_float"
+
+// MaxAccountTypeLength is the longest account type that can be stored
+const MaxAccountTypeLength = 32
+
+// ValidateAccountType checks an account type is a short lowercase identifier
+func ValidateAccountType(accountType AccountType) error {
+	if accountType == "" || len(accountType) > MaxAccountTypeLength {
+		return fmt.Errorf("%w: account type must be 1-%d characters", errors.ErrValidationFailed, MaxAccountTypeLength)
+	}
+	for _, c := range accountType {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' {
+			return fmt.Errorf("%w: account type may only contain a-z, 0-9, '_' and '-'", errors.ErrValidationFailed)
+		}
+	}
+	if accountType == AccountTypeSystemFloat {
+		return fmt.Errorf("%w: account type %s is reserved for system float accounts", errors.ErrValidationFailed, accountType)
+	}
+	return nil
+}
+
+// ParseMinimumBalances parses the minimum maintained balance of each account type, given as
+// decimal strings keyed by type
+func ParseMinimumBalances(raw map[string]string) (map[AccountType]decimal.Decimal, error) {
+	minimums := make(map[AccountType]decimal.Decimal, len(raw))
+	for accountType, value := range raw {
+		if err := ValidateAccountType(AccountType(accountType)); err != nil {
+			return nil, err
+		}
+		minimum, err := decimal.NewFromString(value)
+		if err != nil || minimum.IsNegative() {
+			return nil, fmt.Errorf("%w: minimum balance %q of account type %s must be a non-negative decimal", errors.ErrValidationFailed, value, accountType)
+		}
+		if err := ValidateAmountPrecision(minimum); err != nil {
+			return nil, err
+		}
+		minimums[AccountType(accountType)] = minimum
+	}
+	return minimums, nil
+}
+
+// MinimumBalanceOverride authorizes a transfer to take the source account below its minimum
+// maintained balance; the actor and reason are recorded in the audit log
+type MinimumBalanceOverride struct {
+	Actor  string
+	Reason string
+}
+
+// MaxOwnerRefLength is the longest external owner reference that can be stored
+const MaxOwnerRefLength = 128
+
 // Account represents an account in the system
 type Account struct {
-	AccountID int64
-	Balance   decimal.Decimal
-	CreatedAt string
-	UpdatedAt string
+	AccountID      int64           `json:"account_id"`
+	Balance        decimal.Decimal `json:"balance"`
+	Reserved       decimal.Decimal `json:"reserved_balance"`
+	OverdraftLimit decimal.Decimal `json:"overdraft_limit"`
+	Limits         AccountLimits   `json:"limits"`
+	Currency       string          `json:"currency,omitempty"`
+	OwnerRef       string          `json:"owner_ref,omitempty"`
+	TenantID       string          `json:"tenant_id,omitempty"`
+	Type           AccountType     `json:"account_type,omitempty"`
+	Status         AccountStatus   `json:"status"`
+	LastActivityAt string          `json:"last_activity_at,omitempty"`
+	CreatedAt      string          `json:"created_at,omitempty"`
+	UpdatedAt      string          `json:"updated_at,omitempty"`
+}
+
+// CanReceiveCredits checks if the account accepts incoming transfers
+func (a *Account) CanReceiveCredits() bool {
+	return a.Status == "" || a.Status == AccountStatusActive || a.Status == AccountStatusDormant
 }
 
-// HasSufficientBalance checks if the account has sufficient balance for a withdrawal
+// IsFrozen checks if the account was frozen
+func (a *Account) IsFrozen() bool {
+	return a.Status == AccountStatusFrozen
+}
+
+// CheckStatusChange checks an operator may change the account to status: active and dormant
+// accounts can be frozen, frozen accounts unfrozen to active, and any account but a closed one
+// closed once it holds no funds
+func (a *Account) CheckStatusChange(status AccountStatus) error {
+	var allowed bool
+	switch status {
+	case AccountStatusFrozen:
+		allowed = a.CanReceiveCredits()
+	case AccountStatusActive:
+		allowed = a.Status == AccountStatusFrozen
+	case AccountStatusClosed:
+		allowed = a.Status != AccountStatusClosed
+		if allowed && (!a.Balance.IsZero() || !a.Reserved.IsZero()) {
+			return fmt.Errorf("%w: account %d still holds %s, of which %s reserved", errors.ErrAccountStatusConflict, a.AccountID, a.Balance.String(), a.Reserved.String())
+		}
+	default:
+		return fmt.Errorf("%w: status must be active, frozen or closed", errors.ErrValidationFailed)
+	}
+	if !allowed {
+		return fmt.Errorf("%w: account %d is %s and can't be made %s", errors.ErrAccountStatusConflict, a.AccountID, a.Status, status)
+	}
+	return nil
+}
+
+// AccountClosure is the result of closing an account: the closed account and, if the account
+// was force-closed with a residual balance, the transfer that swept it
+type AccountClosure struct {
+	Account *Account
+	Sweep   *Transaction
+}
+
+// IsSystemFloat checks if the account is the system float account of its currency
+func (a *Account) IsSystemFloat() bool {
+	return a.Type == AccountTypeSystemFloat
+}
+
+// IsDormant checks if the account was marked dormant
+func (a *Account) IsDormant() bool {
+	return a.Status == AccountStatusDormant
+}
+
+// AvailableBalance is the part of the balance not reserved by active pre-authorizations
+func (a *Account) AvailableBalance() decimal.Decimal {
+	return a.Balance.Sub(a.Reserved)
+}
+
+// HasSufficientBalance checks if the account has sufficient available balance for a withdrawal.
+// An account with an overdraft limit may go negative down to minus the limit.
 func (a *Account) HasSufficientBalance(amount decimal.Decimal) bool {
-	return a.Balance.GreaterThanOrEqual(amount)
+	return a.AvailableBalance().Add(a.OverdraftLimit).GreaterThanOrEqual(amount)
+}
+
+// ValidateOverdraftLimit checks an overdraft limit is a non-negative amount the account's current
+// balance stays within
+func (a *Account) ValidateOverdraftLimit(limit decimal.Decimal) error {
+	if limit.IsNegative() {
+		return fmt.Errorf("%w: overdraft limit must not be negative", errors.ErrValidationFailed)
+	}
+	if err := ValidateAmountPrecision(limit); err != nil {
+		return err
+	}
+	if a.Balance.LessThan(limit.Neg()) {
+		return fmt.Errorf("%w: account %d is overdrawn by %s, more than the limit %s", errors.ErrValidationFailed, a.AccountID, a.Balance.Neg().String(), limit.String())
+	}
+	return nil
+}
+
+// AccountLimits caps the outbound transfers of an account: the amount of each transfer, and the
+// total sent per business day and per calendar month of business days. A nil limit doesn't apply.
+type AccountLimits struct {
+	PerTransaction *decimal.Decimal `json:"per_transaction,omitempty"`
+	Daily          *decimal.Decimal `json:"daily,omitempty"`
+	Monthly        *decimal.Decimal `json:"monthly,omitempty"`
+}
+
+// IsZero checks no limit is set
+func (l AccountLimits) IsZero() bool {
+	return l.PerTransaction == nil && l.Daily == nil && l.Monthly == nil
+}
+
+// Validate checks every limit set is a positive amount
+func (l AccountLimits) Validate() error {
+	for _, limit := range []struct {
+		name  string
+		value *decimal.Decimal
+	}{{"per-transaction", l.PerTransaction}, {"daily", l.Daily}, {"monthly", l.Monthly}} {
+		if limit.value == nil {
+			continue
+		}
+		if !limit.value.IsPositive() {
+			return fmt.Errorf("%w: %s limit must be positive", errors.ErrValidationFailed, limit.name)
+		}
+		if err := ValidateAmountPrecision(*limit.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckOutbound checks a transfer of amount from account accountID fits its limits, given the
+// total already sent on the current business day and in the current month
+func (l AccountLimits) CheckOutbound(accountID int64, amount, sentToday, sentThisMonth decimal.Decimal) error {
+	if l.PerTransaction != nil && amount.GreaterThan(*l.PerTransaction) {
+		return errors.NewLimitExceededError(accountID, amount, *l.PerTransaction, *l.PerTransaction)
+	}
+	if l.Daily != nil && sentToday.Add(amount).GreaterThan(*l.Daily) {
+		return errors.NewLimitExceededError(accountID, amount, remaining(*l.Daily, sentToday), *l.Daily)
+	}
+	if l.Monthly != nil && sentThisMonth.Add(amount).GreaterThan(*l.Monthly) {
+		return errors.NewLimitExceededError(accountID, amount, remaining(*l.Monthly, sentThisMonth), *l.Monthly)
+	}
+	return nil
+}
+
+// remaining is what is left of limit once sent is used, never below zero
+func remaining(limit, sent decimal.Decimal) decimal.Decimal {
+	return decimal.Max(limit.Sub(sent), decimal.Zero)
 }
 
 // Credit adds the specified amount to the account balance
@@ -27,7 +237,7 @@ func (a *Account) Credit(amount decimal.Decimal) {
 // Returns an error if insufficient balance
 func (a *Account) Debit(amount decimal.Decimal) error {
 	if !a.HasSufficientBalance(amount) {
-		return errors.ErrInsufficientBalance
+		return errors.NewInsufficientBalanceError(a.AccountID, amount, a.AvailableBalance())
 	}
 	a.Balance = a.Balance.Sub(amount)
 	return nil
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+const (
+	// MaxAccountNoteLength is the longest note body, in characters
+	MaxAccountNoteLength = 4000
+	// MaxCaseIDLength is the longest external support case ID that can be stored
+	MaxCaseIDLength = 128
+)
+
+// AccountNote is an operator's note on an account, optionally linked to a case in the external
+// support tracker by CaseID
+type AccountNote struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	Body      string `json:"body"`
+	CaseID    string `json:"case_id,omitempty"`
+	Author    string `json:"author"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Validate checks the body, case ID and author of a note about to be added
+func (n *AccountNote) Validate() error {
+	if strings.TrimSpace(n.Body) == "" || utf8.RuneCountInString(n.Body) > MaxAccountNoteLength {
+		return fmt.Errorf("%w: body must be 1-%d characters", errors.ErrValidationFailed, MaxAccountNoteLength)
+	}
+	if len(n.CaseID) > MaxCaseIDLength || strings.ContainsAny(n.CaseID, " \t\r\n") {
+		return fmt.Errorf("%w: case_id must be at most %d characters without whitespace", errors.ErrValidationFailed, MaxCaseIDLength)
+	}
+	if len(n.Author) > MaxAuditActorLength {
+		return fmt.Errorf("%w: actor must be at most %d characters", errors.ErrValidationFailed, MaxAuditActorLength)
+	}
+	return nil
+}
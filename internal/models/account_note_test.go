@@ -0,0 +1,30 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountNote_Validate(t *testing.T) {
+	valid := AccountNote{AccountID: 1, Body: "Customer called about a missing payment", CaseID: "SUP-1234", Author: "ops"}
+	assert.NoError(t, valid.Validate())
+
+	noCase := valid
+	noCase.CaseID = ""
+	assert.NoError(t, noCase.Validate())
+
+	for name, mutate := range map[string]func(n *AccountNote){
+		"blank body":     func(n *AccountNote) { n.Body = "  " },
+		"long body":      func(n *AccountNote) { n.Body = strings.Repeat("x", MaxAccountNoteLength+1) },
+		"long case id":   func(n *AccountNote) { n.CaseID = strings.Repeat("c", MaxCaseIDLength+1) },
+		"spaced case id": func(n *AccountNote) { n.CaseID = "SUP 1234" },
+		"long author":    func(n *AccountNote) { n.Author = strings.Repeat("a", MaxAuditActorLength+1) },
+	} {
+		n := valid
+		mutate(&n)
+		assert.ErrorIs(t, n.Validate(), errors.ErrValidationFailed, name)
+	}
+}
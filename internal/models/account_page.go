@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Bounds on the page size of account listings
+const (
+	DefaultAccountPageSize = 100
+	MaxAccountPageSize     = 1000
+)
+
+// AccountSort is the key an account listing is ordered by. Accounts with the same balance are
+// ordered by account ID.
+type AccountSort string
+
+const (
+	// AccountSortID orders accounts by account ID
+	AccountSortID AccountSort = "account_id"
+	// AccountSortBalance orders accounts by balance
+	AccountSortBalance AccountSort = "balance"
+)
+
+// AccountListFilter narrows and orders an account listing. MinBalance and MaxBalance are
+// inclusive; an empty Sort orders by account ID.
+type AccountListFilter struct {
+	MinBalance *decimal.Decimal
+	MaxBalance *decimal.Decimal
+	Sort       AccountSort
+	Descending bool
+}
+
+// Validate checks that the filter's sort is known and its balance range is not inverted. Bounds
+// may be negative, to find overdrawn accounts.
+func (f AccountListFilter) Validate() error {
+	switch f.Sort {
+	case "", AccountSortID, AccountSortBalance:
+	default:
+		return fmt.Errorf("%w: invalid sort %q", errors.ErrValidationFailed, f.Sort)
+	}
+	if f.MinBalance != nil && f.MaxBalance != nil && f.MinBalance.GreaterThan(*f.MaxBalance) {
+		return fmt.Errorf("%w: min_balance must not exceed max_balance", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// Matches reports whether an account's balance is within the filter's bounds
+func (f AccountListFilter) Matches(account *Account) bool {
+	return (f.MinBalance == nil || !account.Balance.LessThan(*f.MinBalance)) &&
+		(f.MaxBalance == nil || !account.Balance.GreaterThan(*f.MaxBalance))
+}
+
+// SortKey returns the filter's sort, defaulting to account ID
+func (f AccountListFilter) SortKey() AccountSort {
+	if f.Sort == "" {
+		return AccountSortID
+	}
+	return f.Sort
+}
+
+// AccountCursor is the position of an account in a listing. It records the order it was issued
+// under, since it can't continue a listing in another order.
+type AccountCursor struct {
+	Sort       AccountSort
+	Descending bool
+	Balance    decimal.Decimal
+	AccountID  int64
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c AccountCursor) Encode() string {
+	raw := strings.Join([]string{string(c.Sort), strconv.FormatBool(c.Descending), c.Balance.String(), strconv.FormatInt(c.AccountID, 10)}, ",")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeAccountCursor parses a cursor produced by Encode
+func DecodeAccountCursor(s string) (AccountCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", errors.ErrValidationFailed)
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return AccountCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), ",")
+	if len(parts) != 4 {
+		return AccountCursor{}, invalid
+	}
+	cursor := AccountCursor{Sort: AccountSort(parts[0])}
+	if cursor.Sort != AccountSortID && cursor.Sort != AccountSortBalance {
+		return AccountCursor{}, invalid
+	}
+	if cursor.Descending, err = strconv.ParseBool(parts[1]); err != nil {
+		return AccountCursor{}, invalid
+	}
+	if cursor.Balance, err = decimal.NewFromString(parts[2]); err != nil {
+		return AccountCursor{}, invalid
+	}
+	if cursor.AccountID, err = strconv.ParseInt(parts[3], 10, 64); err != nil || cursor.AccountID <= 0 {
+		return AccountCursor{}, invalid
+	}
+	return cursor, nil
+}
+
+// Continues reports whether the cursor was issued for a listing in the order of filter
+func (c AccountCursor) Continues(filter AccountListFilter) bool {
+	return c.Sort == filter.SortKey() && c.Descending == filter.Descending
+}
+
+// AccountPage is one page of an account listing. NextCursor is empty on the last page.
+type AccountPage struct {
+	Accounts   []*Account `json:"accounts"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountCursor_RoundTrip(t *testing.T) {
+	cursor := AccountCursor{Sort: AccountSortBalance, Descending: true, Balance: decimal.RequireFromString("-12.345"), AccountID: 42}
+
+	decoded, err := DecodeAccountCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, AccountSortBalance, decoded.Sort)
+	assert.True(t, decoded.Descending)
+	assert.True(t, cursor.Balance.Equal(decoded.Balance))
+	assert.Equal(t, int64(42), decoded.AccountID)
+
+	assert.True(t, decoded.Continues(AccountListFilter{Sort: AccountSortBalance, Descending: true}))
+	assert.False(t, decoded.Continues(AccountListFilter{Sort: AccountSortBalance}))
+	assert.True(t, AccountCursor{Sort: AccountSortID, AccountID: 1}.Continues(AccountListFilter{}))
+
+	for _, invalid := range []string{"", "!!", "bm90LWEtY3Vyc29y", AccountCursor{Sort: "owner", AccountID: 1}.Encode(), AccountCursor{Sort: AccountSortID}.Encode()} {
+		_, err := DecodeAccountCursor(invalid)
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, invalid)
+	}
+}
+
+func TestAccountListFilter(t *testing.T) {
+	ten, hundred, negative := decimal.NewFromInt(10), decimal.NewFromInt(100), decimal.NewFromInt(-1)
+
+	assert.NoError(t, AccountListFilter{}.Validate())
+	assert.NoError(t, AccountListFilter{Sort: AccountSortBalance, MinBalance: &negative, MaxBalance: &ten}.Validate())
+	assert.ErrorIs(t, AccountListFilter{Sort: "owner"}.Validate(), errors.ErrValidationFailed)
+	assert.ErrorIs(t, AccountListFilter{MinBalance: &hundred, MaxBalance: &ten}.Validate(), errors.ErrValidationFailed)
+
+	account := &Account{Balance: decimal.NewFromInt(10)}
+	assert.True(t, AccountListFilter{MinBalance: &ten, MaxBalance: &hundred}.Matches(account), "bounds are inclusive")
+	assert.False(t, AccountListFilter{MaxBalance: &negative}.Matches(account))
+	assert.Equal(t, AccountSortID, AccountListFilter{}.SortKey())
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount_CheckStatusChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		account Account
+		status  AccountStatus
+		err     error
+	}{
+		{name: "freeze active", account: Account{Status: AccountStatusActive}, status: AccountStatusFrozen},
+		{name: "freeze dormant", account: Account{Status: AccountStatusDormant}, status: AccountStatusFrozen},
+		{name: "freeze frozen", account: Account{Status: AccountStatusFrozen}, status: AccountStatusFrozen, err: errors.ErrAccountStatusConflict},
+		{name: "freeze closed", account: Account{Status: AccountStatusClosed}, status: AccountStatusFrozen, err: errors.ErrAccountStatusConflict},
+		{name: "unfreeze frozen", account: Account{Status: AccountStatusFrozen}, status: AccountStatusActive},
+		{name: "unfreeze active", account: Account{Status: AccountStatusActive}, status: AccountStatusActive, err: errors.ErrAccountStatusConflict},
+		{name: "close empty frozen", account: Account{Status: AccountStatusFrozen}, status: AccountStatusClosed},
+		{name: "close with balance", account: Account{Status: AccountStatusActive, Balance: decimal.NewFromInt(1)}, status: AccountStatusClosed, err: errors.ErrAccountStatusConflict},
+		{name: "close with reservation", account: Account{Status: AccountStatusActive, Reserved: decimal.NewFromInt(1)}, status: AccountStatusClosed, err: errors.ErrAccountStatusConflict},
+		{name: "close closed", account: Account{Status: AccountStatusClosed}, status: AccountStatusClosed, err: errors.ErrAccountStatusConflict},
+		{name: "make dormant", account: Account{Status: AccountStatusActive}, status: AccountStatusDormant, err: errors.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.account.CheckStatusChange(tt.status)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAccount_HasSufficientBalance(t *testing.T) {
+	account := &Account{Balance: decimal.NewFromInt(50), Reserved: decimal.NewFromInt(20)}
+	assert.True(t, account.HasSufficientBalance(decimal.NewFromInt(30)))
+	assert.False(t, account.HasSufficientBalance(decimal.NewFromInt(31)))
+
+	// An overdraft limit extends what can be withdrawn below zero
+	account.OverdraftLimit = decimal.NewFromInt(100)
+	assert.True(t, account.HasSufficientBalance(decimal.NewFromInt(130)))
+	assert.False(t, account.HasSufficientBalance(decimal.NewFromInt(131)))
+}
+
+func TestAccount_ValidateOverdraftLimit(t *testing.T) {
+	account := &Account{AccountID: 1, Balance: decimal.NewFromInt(-40)}
+	assert.NoError(t, account.ValidateOverdraftLimit(decimal.NewFromInt(40)))
+	assert.ErrorIs(t, account.ValidateOverdraftLimit(decimal.NewFromInt(39)), errors.ErrValidationFailed)
+	assert.ErrorIs(t, account.ValidateOverdraftLimit(decimal.NewFromInt(-1)), errors.ErrValidationFailed)
+}
+
+func TestAccountLimits_Validate(t *testing.T) {
+	ten, zero := decimal.NewFromInt(10), decimal.Zero
+	assert.NoError(t, AccountLimits{}.Validate())
+	assert.NoError(t, AccountLimits{PerTransaction: &ten, Daily: &ten, Monthly: &ten}.Validate())
+	assert.ErrorIs(t, AccountLimits{Daily: &zero}.Validate(), errors.ErrValidationFailed)
+	tooPrecise := decimal.RequireFromString("1.000001")
+	assert.Error(t, AccountLimits{Monthly: &tooPrecise}.Validate())
+}
+
+func TestAccountLimits_CheckOutbound(t *testing.T) {
+	perTransaction, daily, monthly := decimal.NewFromInt(100), decimal.NewFromInt(150), decimal.NewFromInt(500)
+	limits := AccountLimits{PerTransaction: &perTransaction, Daily: &daily, Monthly: &monthly}
+	amount := func(v int64) decimal.Decimal { return decimal.NewFromInt(v) }
+
+	assert.NoError(t, limits.CheckOutbound(1, amount(100), amount(50), amount(400)))
+	assert.ErrorIs(t, limits.CheckOutbound(1, amount(101), amount(0), amount(0)), errors.ErrLimitExceeded)
+	assert.ErrorIs(t, limits.CheckOutbound(1, amount(60), amount(100), amount(100)), errors.ErrLimitExceeded)
+	assert.ErrorIs(t, limits.CheckOutbound(1, amount(60), amount(0), amount(450)), errors.ErrLimitExceeded)
+
+	err := limits.CheckOutbound(1, amount(60), amount(100), amount(100))
+	var domainErr *errors.Error
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.True(t, domainErr.Available.Equal(amount(50)))
+		assert.True(t, domainErr.Limit.Equal(daily))
+	}
+
+	// Without limits anything goes
+	assert.NoError(t, AccountLimits{}.CheckOutbound(1, amount(1_000_000), amount(1_000_000), amount(1_000_000)))
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// AdjustmentReason is the reason code an operator gives for a manual balance adjustment
+type AdjustmentReason string
+
+const (
+	// AdjustmentReasonCorrection corrects a balance left wrong by an error
+	AdjustmentReasonCorrection AdjustmentReason = "correction"
+	// AdjustmentReasonGoodwill credits a customer as a gesture of goodwill
+	AdjustmentReasonGoodwill AdjustmentReason = "goodwill"
+	// AdjustmentReasonFeeRefund refunds a fee charged in error or waived afterwards
+	AdjustmentReasonFeeRefund AdjustmentReason = "fee_refund"
+	// AdjustmentReasonChargeback moves the money of a disputed payment
+	AdjustmentReasonChargeback AdjustmentReason = "chargeback"
+	// AdjustmentReasonWriteOff writes off a balance that won't be recovered
+	AdjustmentReasonWriteOff AdjustmentReason = "write_off"
+)
+
+// IsValid checks if r is a known adjustment reason code
+func (r AdjustmentReason) IsValid() bool {
+	switch r {
+	case AdjustmentReasonCorrection, AdjustmentReasonGoodwill, AdjustmentReasonFeeRefund, AdjustmentReasonChargeback, AdjustmentReasonWriteOff:
+		return true
+	}
+	return false
+}
+
+// MaxAdjustmentNoteLength is the longest note an adjustment can carry
+const MaxAdjustmentNoteLength = 512
+
+// Adjustment is a manual credit or debit of an account made by an operator outside normal
+// transfers, recorded with the transfer that moved it from or to the adjustments account
+type Adjustment struct {
+	ID            int64            `json:"id"`
+	TransactionID int64            `json:"transaction_id"`
+	AccountID     int64            `json:"account_id"`
+	Direction     EntrySide        `json:"direction"`
+	Amount        decimal.Decimal  `json:"amount"`
+	ReasonCode    AdjustmentReason `json:"reason_code"`
+	Note          string           `json:"note,omitempty"`
+	Actor         string           `json:"actor"`
+	CreatedAt     string           `json:"created_at"`
+}
+
+// Validate checks an adjustment can be made: a credit or debit of a positive amount within the
+// supported precision, with a known reason code and the operator who made it
+func (a *Adjustment) Validate() error {
+	if !a.Direction.IsValid() {
+		return fmt.Errorf("%w: direction must be credit or debit", errors.ErrValidationFailed)
+	}
+	if a.AccountID <= 0 {
+		return fmt.Errorf("%w: account_id is required", errors.ErrValidationFailed)
+	}
+	if !a.Amount.IsPositive() {
+		return errors.NewInvalidAmountError(a.Amount)
+	}
+	if err := ValidateAmountPrecision(a.Amount); err != nil {
+		return err
+	}
+	if !a.ReasonCode.IsValid() {
+		return fmt.Errorf("%w: reason_code must be one of correction, goodwill, fee_refund, chargeback, write_off", errors.ErrValidationFailed)
+	}
+	if len(a.Note) > MaxAdjustmentNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", errors.ErrValidationFailed, MaxAdjustmentNoteLength)
+	}
+	if a.Actor == "" || len(a.Actor) > MaxAuditActorLength {
+		return fmt.Errorf("%w: actor must be 1-%d characters", errors.ErrValidationFailed, MaxAuditActorLength)
+	}
+	return nil
+}
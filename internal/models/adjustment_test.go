@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustment_Validate(t *testing.T) {
+	valid := func() *Adjustment {
+		return &Adjustment{
+			AccountID:  1,
+			Direction:  EntrySideCredit,
+			Amount:     decimal.RequireFromString("25.50"),
+			ReasonCode: AdjustmentReasonGoodwill,
+			Actor:      "ops:alice",
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*Adjustment)
+		want   error
+	}{
+		{"unknown direction", func(a *Adjustment) { a.Direction = "sideways" }, errors.ErrValidationFailed},
+		{"missing account", func(a *Adjustment) { a.AccountID = 0 }, errors.ErrValidationFailed},
+		{"zero amount", func(a *Adjustment) { a.Amount = decimal.Zero }, errors.ErrInvalidAmount},
+		{"too precise", func(a *Adjustment) { a.Amount = decimal.RequireFromString("0.123456") }, errors.ErrAmountPrecision},
+		{"missing reason", func(a *Adjustment) { a.ReasonCode = "" }, errors.ErrValidationFailed},
+		{"unknown reason", func(a *Adjustment) { a.ReasonCode = "because" }, errors.ErrValidationFailed},
+		{"long note", func(a *Adjustment) { a.Note = strings.Repeat("n", MaxAdjustmentNoteLength+1) }, errors.ErrValidationFailed},
+		{"missing actor", func(a *Adjustment) { a.Actor = "" }, errors.ErrValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjustment := valid()
+			tt.modify(adjustment)
+			assert.ErrorIs(t, adjustment.Validate(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Precision and scale of the NUMERIC amount and balance columns. They must match the schema:
+// config.Load sets them from AMOUNT_PRECISION and AMOUNT_SCALE, which are raised after widening
+// the columns with transferctl widen-precision.
+var (
+	amountPrecision int32 = 20
+	amountScale     int32 = 5
+)
+
+// SetAmountPrecision sets the NUMERIC(precision, scale) used to validate and format amounts
+func SetAmountPrecision(precision, scale int32) {
+	amountPrecision = precision
+	amountScale = scale
+}
+
+// AmountScale returns the number of decimal places stored for amounts and balances
+func AmountScale() int32 {
+	return amountScale
+}
+
+// ValidateAmountPrecision checks that amount can be stored without rounding or overflow
+func ValidateAmountPrecision(amount decimal.Decimal) error {
+	if !amount.Equal(amount.Truncate(amountScale)) {
+		return errors.NewAmountPrecisionError(amount)
+	}
+	maxInteger := decimal.New(1, amountPrecision-amountScale)
+	if amount.Abs().GreaterThanOrEqual(maxInteger) {
+		return errors.NewAmountPrecisionError(amount)
+	}
+	return nil
+}
+
+// FormatAmount formats amount with exactly the stored number of decimal places
+func FormatAmount(amount decimal.Decimal) string {
+	return amount.StringFixed(amountScale)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// ApprovalStatus is the lifecycle status of a transfer approval request
+type ApprovalStatus string
+
+const (
+	// ApprovalStatusPending means the transfer waits for a reviewer
+	ApprovalStatusPending ApprovalStatus = "pending"
+	// ApprovalStatusApproved means a reviewer approved the transfer and it was made
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	// ApprovalStatusRejected means a reviewer refused the transfer
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+// IsValid checks if s is a known approval status
+func (s ApprovalStatus) IsValid() bool {
+	return s == ApprovalStatusPending || s == ApprovalStatusApproved || s == ApprovalStatusRejected
+}
+
+// MaxReviewNoteLength is the longest note a reviewer can leave
+const MaxReviewNoteLength = 1024
+
+// TransferApproval is the maker-checker request of a transfer held in pending_approval: who
+// requested the transfer and, once resolved, who reviewed it
+type TransferApproval struct {
+	TransactionID int64          `json:"transaction_id"`
+	Status        ApprovalStatus `json:"status"`
+	RequestedBy   string         `json:"requested_by"`
+	ReviewedBy    string         `json:"reviewed_by,omitempty"`
+	ReviewNote    string         `json:"review_note,omitempty"`
+	ReviewedAt    string         `json:"reviewed_at,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+}
+
+// IsPending checks if the transfer still waits for a reviewer
+func (a *TransferApproval) IsPending() bool {
+	return a.Status == ApprovalStatusPending
+}
+
+// CheckReview checks reviewer may approve or reject the transfer with note: the request must
+// still be pending and the reviewer must not be the caller that requested it
+func (a *TransferApproval) CheckReview(reviewer, note string) error {
+	if !a.IsPending() {
+		return fmt.Errorf("%w: transfer %d is %s", errors.ErrApprovalResolved, a.TransactionID, a.Status)
+	}
+	if reviewer == a.RequestedBy {
+		return fmt.Errorf("%w: transfer %d was requested by %s", errors.ErrSelfApproval, a.TransactionID, reviewer)
+	}
+	if len(reviewer) > MaxAuditActorLength {
+		return fmt.Errorf("%w: reviewer must be at most %d characters", errors.ErrValidationFailed, MaxAuditActorLength)
+	}
+	if len(note) > MaxReviewNoteLength {
+		return fmt.Errorf("%w: note must be at most %d characters", errors.ErrValidationFailed, MaxReviewNoteLength)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferApproval_CheckReview(t *testing.T) {
+	pending := &TransferApproval{TransactionID: 1, Status: ApprovalStatusPending, RequestedBy: "alice"}
+
+	assert.NoError(t, pending.CheckReview("bob", "looks fine"))
+	assert.ErrorIs(t, pending.CheckReview("alice", ""), errors.ErrSelfApproval)
+	assert.ErrorIs(t, pending.CheckReview(strings.Repeat("b", MaxAuditActorLength+1), ""), errors.ErrValidationFailed)
+	assert.ErrorIs(t, pending.CheckReview("bob", strings.Repeat("n", MaxReviewNoteLength+1)), errors.ErrValidationFailed)
+
+	resolved := &TransferApproval{TransactionID: 1, Status: ApprovalStatusApproved, RequestedBy: "alice", ReviewedBy: "bob"}
+	assert.ErrorIs(t, resolved.CheckReview("carol", ""), errors.ErrApprovalResolved)
+}
@@ -0,0 +1,30 @@
+package models
+
+// Audited actions
+const (
+	AuditActionMinimumBalanceOverride = "minimum_balance_override"
+	AuditActionVelocityFlag           = "velocity_flag"
+	AuditActionDelegatedTransfer      = "delegated_transfer"
+	AuditActionBalanceAdjustment      = "balance_adjustment"
+)
+
+// MaxAuditActorLength is the longest actor identifier that can be recorded
+const MaxAuditActorLength = 128
+
+// AuditEntry is a record of a privileged action, who took it and on what
+type AuditEntry struct {
+	ID            int64             `json:"id"`
+	Action        string            `json:"action"`
+	Actor         string            `json:"actor"`
+	AccountID     int64             `json:"account_id,omitempty"`
+	TransactionID int64             `json:"transaction_id,omitempty"`
+	Details       map[string]string `json:"details,omitempty"`
+	CreatedAt     string            `json:"created_at"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Action    string
+	AccountID int64
+	Limit     int
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Limits on transfer batches
+const (
+	MaxBatchItems           = 1000
+	MaxIdempotencyKeyLength = 128
+)
+
+// BatchItemStatus is the outcome of one item of a batch
+type BatchItemStatus string
+
+const (
+	// BatchItemCreated means the transfer was made by this submission
+	BatchItemCreated BatchItemStatus = "created"
+	// BatchItemReplayed means the transfer had already been made by an earlier submission of the batch
+	BatchItemReplayed BatchItemStatus = "replayed"
+	// BatchItemFailed means the transfer was rejected; resubmitting the batch tries it again
+	BatchItemFailed BatchItemStatus = "failed"
+)
+
+// Batch is a set of transfers submitted under one batch-level idempotency key
+type Batch struct {
+	Owner       string // who the batch key belongs to (see auth.Owner)
+	Key         string
+	Fingerprint string
+	ItemCount   int
+}
+
+// BatchItem is one transfer of a batch, identified by a key unique within the batch
+type BatchItem struct {
+	Key                  string
+	SourceAccountID      int64
+	DestinationAccountID int64
+	Amount               decimal.Decimal
+}
+
+// BatchItemResult is the outcome of one batch item
+type BatchItemResult struct {
+	Key         string
+	Status      BatchItemStatus
+	Transaction *Transaction // nil if the item failed
+	Err         error        // set if the item failed
+}
+
+// BatchResult is the outcome of a batch submission, items in submission order
+type BatchResult struct {
+	BatchKey string
+	Items    []*BatchItemResult
+}
+
+// Counts returns the number of items with each status
+func (r *BatchResult) Counts() map[BatchItemStatus]int {
+	counts := map[BatchItemStatus]int{BatchItemCreated: 0, BatchItemReplayed: 0, BatchItemFailed: 0}
+	for _, item := range r.Items {
+		counts[item.Status]++
+	}
+	return counts
+}
+
+// NewBatch validates a batch submission and computes its fingerprint
+func NewBatch(key string, items []BatchItem) (*Batch, error) {
+	if err := validateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 || len(items) > MaxBatchItems {
+		return nil, fmt.Errorf("%w: a batch must have 1-%d items", errors.ErrValidationFailed, MaxBatchItems)
+	}
+
+	seen := make(map[string]bool, len(items))
+	hash := sha256.New()
+	for _, item := range items {
+		if err := validateIdempotencyKey(item.Key); err != nil {
+			return nil, err
+		}
+		if seen[item.Key] {
+			return nil, fmt.Errorf("%w: duplicate item key %q", errors.ErrValidationFailed, item.Key)
+		}
+		seen[item.Key] = true
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\x00%s\n", item.Key, item.SourceAccountID, item.DestinationAccountID, item.Amount.String())
+	}
+
+	return &Batch{Key: key, Fingerprint: hex.EncodeToString(hash.Sum(nil)), ItemCount: len(items)}, nil
+}
+
+// ItemKey is the key recorded on the transaction of a batch item: the hex SHA-256 of the owner,
+// the batch key, each prefixed by its length so no two sets of keys run together, and the item
+// key. Scoping item keys by the batch lets different batches reuse item keys, and the digest fits
+// the column however long the keys are.
+func (b *Batch) ItemKey(itemKey string) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d:%s%d:%s%s", len(b.Owner), b.Owner, len(b.Key), b.Key, itemKey)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func validateIdempotencyKey(key string) error {
+	if strings.TrimSpace(key) == "" || len(key) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("%w: idempotency keys must be 1-%d characters", errors.ErrValidationFailed, MaxIdempotencyKeyLength)
+	}
+	return nil
+}
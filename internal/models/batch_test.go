@@ -0,0 +1,22 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch_ItemKey(t *testing.T) {
+	batch := &Batch{Key: "b1"}
+
+	// migration 047 computes the same digest for the keys recorded before it, which have no owner
+	assert.Equal(t, "1b6fac8a9356972f8db409b0d0b854a54e2839efa0a8f159603e19b0ad160372", batch.ItemKey("item-1"))
+	assert.Equal(t, batch.ItemKey("item-1"), (&Batch{Key: "b1"}).ItemKey("item-1"))
+	assert.NotEqual(t, batch.ItemKey("item-1"), batch.ItemKey("item-2"))
+	assert.NotEqual(t, (&Batch{Key: "ab"}).ItemKey("c"), (&Batch{Key: "a"}).ItemKey("bc"))
+	assert.NotEqual(t, batch.ItemKey("item-1"), (&Batch{Owner: "7", Key: "b1"}).ItemKey("item-1"))
+
+	long := &Batch{Key: strings.Repeat("b", MaxIdempotencyKeyLength)}
+	assert.LessOrEqual(t, len(long.ItemKey(strings.Repeat("i", MaxIdempotencyKeyLength))), MaxIdempotencyKeyLength)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// currencyExponents is the ISO 4217 minor unit exponent of each supported currency: the number
+// of decimal places an amount in that currency may have
+var currencyExponents = map[string]int32{
+	// No minor unit
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+
+	// Two decimal places
+	"AED": 2, "ARS": 2, "AUD": 2, "BDT": 2, "BGN": 2, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "KES": 2, "MXN": 2, "MYR": 2, "NGN": 2,
+	"NOK": 2, "NZD": 2, "PEN": 2, "PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "RON": 2,
+	"RUB": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "UAH": 2,
+	"USD": 2, "ZAR": 2,
+
+	// Three decimal places
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+
+	// Four decimal places (units of account)
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the minor unit exponent of an ISO 4217 currency code
+func CurrencyExponent(currency string) (int32, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// ValidateCurrency checks currency is a supported ISO 4217 code
+func ValidateCurrency(currency string) error {
+	if _, ok := currencyExponents[currency]; !ok {
+		return fmt.Errorf("%w: unsupported currency %q, expected an ISO 4217 code", errors.ErrValidationFailed, currency)
+	}
+	return nil
+}
+
+// ValidateAmountForCurrency checks amount is a whole number of minor units of currency, e.g. no
+// fractional yen or sub-fils dinars. Accounts without a currency only have the storage precision
+// checked by ValidateAmountPrecision.
+func ValidateAmountForCurrency(amount decimal.Decimal, currency string) error {
+	if currency == "" {
+		return nil
+	}
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return ValidateCurrency(currency)
+	}
+	if !amount.Equal(amount.Truncate(exponent)) {
+		return errors.NewAmountGranularityError(amount, currency)
+	}
+	return nil
+}
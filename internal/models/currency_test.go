@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAmountForCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		err      error
+	}{
+		{name: "whole yen", amount: "1500", currency: "JPY"},
+		{name: "fractional yen", amount: "1500.5", currency: "JPY", err: errors.ErrAmountGranularity},
+		{name: "cents", amount: "10.25", currency: "USD"},
+		{name: "trailing zeros are not extra precision", amount: "10.2500", currency: "USD"},
+		{name: "sub-cent", amount: "10.255", currency: "USD", err: errors.ErrAmountGranularity},
+		{name: "fils", amount: "1.125", currency: "BHD"},
+		{name: "sub-fils", amount: "1.1255", currency: "BHD", err: errors.ErrAmountGranularity},
+		{name: "no currency", amount: "1.12345", currency: ""},
+		{name: "unknown currency", amount: "1", currency: "XYZ", err: errors.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAmountForCurrency(decimal.RequireFromString(tt.amount), tt.currency)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAmountGranularityErrorDetails(t *testing.T) {
+	err := ValidateAmountForCurrency(decimal.RequireFromString("0.5"), "JPY")
+	assert.Equal(t, "amount_granularity", errors.Code(err))
+	assert.Equal(t, map[string]string{"requested_amount": "0.5", "currency": "JPY"}, errors.Details(err))
+}
@@ -0,0 +1,33 @@
+package models
+
+import "encoding/json"
+
+// DeadLetterKind is the kind of background work a dead letter gave up on
+type DeadLetterKind string
+
+const (
+	// DeadLetterKindTransferJob is an asynchronously submitted transfer; ReferenceID is the job ID
+	DeadLetterKindTransferJob DeadLetterKind = "transfer_job"
+	// DeadLetterKindScheduledTransfer is a scheduled transfer; ReferenceID is its ID
+	DeadLetterKindScheduledTransfer DeadLetterKind = "scheduled_transfer"
+	// DeadLetterKindWebhookDelivery is an event delivery to a webhook; ReferenceID is the ID of
+	// its last attempt
+	DeadLetterKindWebhookDelivery DeadLetterKind = "webhook_delivery"
+)
+
+// IsValid checks if k is a known dead letter kind
+func (k DeadLetterKind) IsValid() bool {
+	return k == DeadLetterKindTransferJob || k == DeadLetterKindScheduledTransfer || k == DeadLetterKindWebhookDelivery
+}
+
+// DeadLetter is background work given up on after its attempts ran out or it was rejected, kept
+// with the error of its last attempt and a snapshot of the work as Payload
+type DeadLetter struct {
+	ID          int64           `json:"id"`
+	Kind        DeadLetterKind  `json:"kind"`
+	ReferenceID int64           `json:"reference_id"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   string          `json:"created_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Delegation lets Grantee, the actor a credential acts as, make transfers on behalf of Grantor,
+// the owner reference of the source accounts, until ExpiresAt. SourceAccountID narrows it to one
+// account and MaxAmount caps each transfer; zero and nil leave them open.
+type Delegation struct {
+	ID              int64            `json:"id"`
+	Grantor         string           `json:"grantor"`
+	Grantee         string           `json:"grantee"`
+	SourceAccountID int64            `json:"source_account_id,omitempty"`
+	MaxAmount       *decimal.Decimal `json:"max_amount,omitempty"`
+	ExpiresAt       time.Time        `json:"expires_at"`
+	CreatedBy       string           `json:"created_by"`
+	RevokedAt       *time.Time       `json:"revoked_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// Validate checks a delegation about to be granted at now
+func (d *Delegation) Validate(now time.Time) error {
+	for _, party := range []struct{ name, value string }{{"grantor", d.Grantor}, {"grantee", d.Grantee}} {
+		if strings.TrimSpace(party.value) == "" || len(party.value) > MaxAuditActorLength {
+			return fmt.Errorf("%w: %s must be 1-%d characters", errors.ErrValidationFailed, party.name, MaxAuditActorLength)
+		}
+	}
+	if d.Grantor == d.Grantee {
+		return fmt.Errorf("%w: grantor and grantee must be different", errors.ErrValidationFailed)
+	}
+	if d.SourceAccountID < 0 {
+		return fmt.Errorf("%w: invalid source_account_id", errors.ErrValidationFailed)
+	}
+	if d.MaxAmount != nil {
+		if !d.MaxAmount.IsPositive() {
+			return fmt.Errorf("%w: max_amount must be positive", errors.ErrValidationFailed)
+		}
+		if err := ValidateAmountPrecision(*d.MaxAmount); err != nil {
+			return err
+		}
+	}
+	if !d.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// IsActive checks if the delegation can be used at now
+func (d *Delegation) IsActive(now time.Time) bool {
+	return d.RevokedAt == nil && now.Before(d.ExpiresAt)
+}
+
+// Permits checks if the delegation covers a transfer of amount from source
+func (d *Delegation) Permits(source *Account, amount decimal.Decimal) bool {
+	if source.OwnerRef != d.Grantor {
+		return false
+	}
+	if d.SourceAccountID != 0 && source.AccountID != d.SourceAccountID {
+		return false
+	}
+	return d.MaxAmount == nil || amount.LessThanOrEqual(*d.MaxAmount)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegation_Validate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	maxAmount := decimal.NewFromInt(100)
+	valid := Delegation{Grantor: "cust-1", Grantee: "assistant", MaxAmount: &maxAmount, ExpiresAt: now.Add(time.Hour)}
+	assert.NoError(t, valid.Validate(now))
+
+	zero := decimal.Zero
+	for name, mutate := range map[string]func(d *Delegation){
+		"no grantor":      func(d *Delegation) { d.Grantor = " " },
+		"long grantee":    func(d *Delegation) { d.Grantee = strings.Repeat("g", MaxAuditActorLength+1) },
+		"self delegation": func(d *Delegation) { d.Grantee = d.Grantor },
+		"negative source": func(d *Delegation) { d.SourceAccountID = -1 },
+		"zero max amount": func(d *Delegation) { d.MaxAmount = &zero },
+		"already expired": func(d *Delegation) { d.ExpiresAt = now },
+		"missing expiry":  func(d *Delegation) { d.ExpiresAt = time.Time{} },
+	} {
+		d := valid
+		mutate(&d)
+		assert.ErrorIs(t, d.Validate(now), errors.ErrValidationFailed, name)
+	}
+
+	fine := decimal.RequireFromString("1.123456")
+	tooPrecise := valid
+	tooPrecise.MaxAmount = &fine
+	assert.ErrorIs(t, tooPrecise.Validate(now), errors.ErrAmountPrecision)
+}
+
+func TestDelegation_Permits(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	maxAmount := decimal.NewFromInt(100)
+	d := &Delegation{Grantor: "cust-1", Grantee: "assistant", SourceAccountID: 7, MaxAmount: &maxAmount, ExpiresAt: now.Add(time.Hour)}
+	source := &Account{AccountID: 7, OwnerRef: "cust-1"}
+
+	assert.True(t, d.Permits(source, decimal.NewFromInt(100)))
+	assert.False(t, d.Permits(source, decimal.NewFromInt(101)))
+	assert.False(t, d.Permits(&Account{AccountID: 8, OwnerRef: "cust-1"}, decimal.NewFromInt(1)))
+	assert.False(t, d.Permits(&Account{AccountID: 7, OwnerRef: "cust-2"}, decimal.NewFromInt(1)))
+
+	open := &Delegation{Grantor: "cust-1", Grantee: "assistant", ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, open.Permits(&Account{AccountID: 8, OwnerRef: "cust-1"}, decimal.NewFromInt(1000000)))
+
+	assert.True(t, d.IsActive(now))
+	assert.False(t, d.IsActive(now.Add(time.Hour)))
+	d.RevokedAt = &now
+	assert.False(t, d.IsActive(now))
+}
@@ -0,0 +1,46 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// Events published when transfers or pending work change state, so the initiator can react (e.g.
+// resubmit)
+const (
+	// EventPreAuthorizationExpired is published when a pre-authorization lapses and its reservation
+	// is released; the payload is the expired pre-authorization
+	EventPreAuthorizationExpired = "preauthorization.expired"
+	// EventTransactionCompleted is published once a transfer is committed; the payload is the
+	// completed transaction
+	EventTransactionCompleted = "transaction.completed"
+	// EventTransactionFailed is published when a transfer is refused by a business rule, such as
+	// an insufficient balance; the payload is a TransferFailure
+	EventTransactionFailed = "transaction.failed"
+	// EventAccountCreated is published once an account is created; the payload is an
+	// AccountCreated
+	EventAccountCreated = "account.created"
+)
+
+// EventTypes lists every event published, in the order they were introduced
+var EventTypes = []string{
+	EventPreAuthorizationExpired,
+	EventTransactionCompleted,
+	EventTransactionFailed,
+	EventAccountCreated,
+}
+
+// TransferFailure describes a transfer that was refused; no funds moved
+type TransferFailure struct {
+	SourceAccountID      int64             `json:"source_account_id"`
+	DestinationAccountID int64             `json:"destination_account_id"`
+	Amount               decimal.Decimal   `json:"amount"`
+	Code                 string            `json:"code"`
+	Message              string            `json:"message"`
+	Details              map[string]string `json:"details,omitempty"`
+	FailedAt             string            `json:"failed_at"`
+}
+
+// AccountCreated describes a newly created account
+type AccountCreated struct {
+	AccountID      int64           `json:"account_id"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+	CreatedAt      string          `json:"created_at"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// ExportScope selects the transaction history exported: that of one account, or of every account
+// of a tenant. Exactly one field is set.
+type ExportScope struct {
+	AccountID int64
+	TenantID  string
+}
+
+// Validate checks that exactly one of the account and the tenant is set
+func (s ExportScope) Validate() error {
+	if (s.AccountID == 0) == (s.TenantID == "") {
+		return fmt.Errorf("%w: exactly one of account_id and tenant_id is required", errors.ErrValidationFailed)
+	}
+	if s.AccountID < 0 {
+		return fmt.Errorf("%w: invalid account_id", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// Name identifies the scope in artifact keys, e.g. "account-42" or "tenant-retail"
+func (s ExportScope) Name() string {
+	if s.TenantID != "" {
+		return "tenant-" + s.TenantID
+	}
+	return "account-" + strconv.FormatInt(s.AccountID, 10)
+}
+
+// ExportCursor is the position of an export in the history of its scope. Transactions are exported
+// by ID, oldest first, up to those recorded before Until, which is fixed when the export starts so
+// it covers a finite history. An export continues with the transactions after AfterID.
+type ExportCursor struct {
+	Scope   ExportScope
+	Until   time.Time
+	AfterID int64
+}
+
+// Encode returns the opaque resume token handed to clients
+func (c ExportCursor) Encode() string {
+	kind, value := "account", strconv.FormatInt(c.Scope.AccountID, 10)
+	if c.Scope.TenantID != "" {
+		kind, value = "tenant", c.Scope.TenantID
+	}
+	raw := strings.Join([]string{kind, c.Until.UTC().Format(time.RFC3339Nano), strconv.FormatInt(c.AfterID, 10), value}, ",")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeExportCursor parses a resume token produced by Encode
+func DecodeExportCursor(s string) (ExportCursor, error) {
+	invalid := fmt.Errorf("%w: invalid resume token", errors.ErrValidationFailed)
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ExportCursor{}, invalid
+	}
+	// The scope value goes last as a tenant ID may contain commas
+	parts := strings.SplitN(string(raw), ",", 4)
+	if len(parts) != 4 {
+		return ExportCursor{}, invalid
+	}
+	var cursor ExportCursor
+	if cursor.Until, err = time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+		return ExportCursor{}, invalid
+	}
+	if cursor.AfterID, err = strconv.ParseInt(parts[2], 10, 64); err != nil || cursor.AfterID < 0 {
+		return ExportCursor{}, invalid
+	}
+	switch parts[0] {
+	case "account":
+		if cursor.Scope.AccountID, err = strconv.ParseInt(parts[3], 10, 64); err != nil {
+			return ExportCursor{}, invalid
+		}
+	case "tenant":
+		cursor.Scope.TenantID = parts[3]
+	default:
+		return ExportCursor{}, invalid
+	}
+	if cursor.Scope.Validate() != nil {
+		return ExportCursor{}, invalid
+	}
+	return cursor, nil
+}
+
+// ExportChunk is the outcome of one chunk of an export. ResumeToken continues the export after the
+// last transaction of the chunk; Complete is set once the history up to the export's end is exhausted.
+type ExportChunk struct {
+	Count       int
+	ResumeToken string
+	Complete    bool
+}
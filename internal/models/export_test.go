@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCursor_RoundTrip(t *testing.T) {
+	until := time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.FixedZone("SGT", 8*3600))
+	for _, scope := range []ExportScope{{AccountID: 7}, {TenantID: "acme,asia"}} {
+		cursor := ExportCursor{Scope: scope, Until: until, AfterID: 42}
+
+		decoded, err := DecodeExportCursor(cursor.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, scope, decoded.Scope)
+		assert.True(t, until.Equal(decoded.Until))
+		assert.Equal(t, int64(42), decoded.AfterID)
+	}
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, invalid := range []string{
+		"", "!!",
+		encode("account,2024-03-01T00:00:00Z,1"),
+		encode("account,2024-03-01T00:00:00Z,1,abc"),
+		encode("account,2024-03-01T00:00:00Z,-1,7"),
+		encode("account,2024-03-01T00:00:00Z,1,0"),
+		encode("tenant,2024-03-01T00:00:00Z,1,"),
+		encode("ledger,2024-03-01T00:00:00Z,1,7"),
+		encode("account,yesterday,1,7"),
+	} {
+		_, err := DecodeExportCursor(invalid)
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, invalid)
+	}
+}
+
+func TestExportScope_Validate(t *testing.T) {
+	assert.NoError(t, ExportScope{AccountID: 1}.Validate())
+	assert.NoError(t, ExportScope{TenantID: "acme"}.Validate())
+	assert.ErrorIs(t, ExportScope{}.Validate(), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ExportScope{AccountID: 1, TenantID: "acme"}.Validate(), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ExportScope{AccountID: -1}.Validate(), errors.ErrValidationFailed)
+}
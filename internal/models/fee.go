@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// FeeType is how a fee rule computes its fee
+type FeeType string
+
+const (
+	// FeeTypeFlat charges the rule's value on every transfer
+	FeeTypeFlat FeeType = "flat"
+	// FeeTypePercentage charges the rule's value in percent of the transfer amount
+	FeeTypePercentage FeeType = "percentage"
+)
+
+// FeeRule is a fee charged on transfers from one account, or from every account without rules of
+// its own if AccountID is zero
+type FeeRule struct {
+	ID        int64           `json:"id"`
+	AccountID int64           `json:"account_id,omitempty"`
+	Type      FeeType         `json:"type"`
+	Value     decimal.Decimal `json:"value"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// IsGlobal checks if the rule applies to accounts without rules of their own
+func (r *FeeRule) IsGlobal() bool {
+	return r.AccountID == 0
+}
+
+// Validate checks the rule has a known type and a positive value; a percentage is at most 100
+func (r *FeeRule) Validate() error {
+	switch r.Type {
+	case FeeTypeFlat, FeeTypePercentage:
+	default:
+		return fmt.Errorf("%w: invalid fee type %q, expected flat or percentage", errors.ErrValidationFailed, r.Type)
+	}
+	if !r.Value.IsPositive() {
+		return fmt.Errorf("%w: fee value must be positive", errors.ErrValidationFailed)
+	}
+	if r.Type == FeeTypePercentage && r.Value.GreaterThan(decimal.NewFromInt(100)) {
+		return fmt.Errorf("%w: percentage fee %s exceeds 100", errors.ErrValidationFailed, r.Value)
+	}
+	return ValidateAmountPrecision(r.Value)
+}
+
+// Fee computes the fee the rule charges on a transfer of amount in currency. A percentage fee is
+// rounded half to even to the currency's minor unit, so it may be zero on a small transfer.
+func (r *FeeRule) Fee(amount decimal.Decimal, currency string) decimal.Decimal {
+	if r.Type == FeeTypeFlat {
+		return r.Value
+	}
+	fee := amount.Mul(r.Value).Div(decimal.NewFromInt(100))
+	if exponent, ok := CurrencyExponent(currency); ok {
+		return fee.RoundBank(exponent)
+	}
+	return fee.RoundBank(amountScale)
+}
+
+// Fee is one fee charged on a transfer, collected by its own transaction to the fees account.
+// RuleID is zero if the fee wasn't charged by a fee rule.
+type Fee struct {
+	RuleID        int64           `json:"rule_id,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	TransactionID int64           `json:"transaction_id,omitempty"`
+}
+
+// FeesFromTransactions returns the breakdown of the fees collected by transactions
+func FeesFromTransactions(transactions []*Transaction) []Fee {
+	fees := make([]Fee, 0, len(transactions))
+	for _, tx := range transactions {
+		fees = append(fees, Fee{RuleID: tx.FeeRuleID, Amount: tx.Amount, TransactionID: tx.ID})
+	}
+	return fees
+}
+
+// TotalFees returns the sum of fees
+func TotalFees(fees []Fee) decimal.Decimal {
+	total := decimal.Zero
+	for _, fee := range fees {
+		total = total.Add(fee.Amount)
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeRule_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  FeeRule
+		valid bool
+	}{
+		{name: "flat", rule: FeeRule{Type: FeeTypeFlat, Value: decimal.NewFromInt(2)}, valid: true},
+		{name: "percentage", rule: FeeRule{Type: FeeTypePercentage, Value: decimal.RequireFromString("0.5")}, valid: true},
+		{name: "whole amount", rule: FeeRule{Type: FeeTypePercentage, Value: decimal.NewFromInt(100)}, valid: true},
+		{name: "over 100 percent", rule: FeeRule{Type: FeeTypePercentage, Value: decimal.NewFromInt(101)}},
+		{name: "zero", rule: FeeRule{Type: FeeTypeFlat, Value: decimal.Zero}},
+		{name: "negative", rule: FeeRule{Type: FeeTypeFlat, Value: decimal.NewFromInt(-1)}},
+		{name: "unknown type", rule: FeeRule{Type: "tiered", Value: decimal.NewFromInt(1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errors.ErrValidationFailed)
+			}
+		})
+	}
+}
+
+func TestFeeRule_Fee(t *testing.T) {
+	flat := FeeRule{Type: FeeTypeFlat, Value: decimal.RequireFromString("1.50")}
+	assert.Equal(t, "1.5", flat.Fee(decimal.NewFromInt(1000), "USD").String())
+
+	percentage := FeeRule{Type: FeeTypePercentage, Value: decimal.RequireFromString("0.5")}
+	assert.Equal(t, "5", percentage.Fee(decimal.NewFromInt(1000), "USD").String())
+	assert.Equal(t, "0.06", percentage.Fee(decimal.RequireFromString("12.34"), "USD").String())
+	assert.Equal(t, "62", percentage.Fee(decimal.NewFromInt(12345), "JPY").String())
+	assert.Equal(t, "0.0617", percentage.Fee(decimal.RequireFromString("12.34"), "").String())
+	assert.True(t, percentage.Fee(decimal.RequireFromString("0.99"), "USD").IsZero())
+}
+
+func TestTotalFees(t *testing.T) {
+	assert.True(t, TotalFees(nil).IsZero())
+	fees := []Fee{{Amount: decimal.RequireFromString("1.50")}, {Amount: decimal.RequireFromString("0.25")}}
+	assert.Equal(t, "1.75", TotalFees(fees).String())
+}
+
+func TestFeesFromTransactions(t *testing.T) {
+	fees := FeesFromTransactions([]*Transaction{{ID: 7, Amount: decimal.NewFromInt(2), FeeOf: 6, FeeRuleID: 3}})
+	assert.Equal(t, []Fee{{RuleID: 3, Amount: decimal.NewFromInt(2), TransactionID: 7}}, fees)
+	assert.NotNil(t, FeesFromTransactions(nil))
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// FundingDirection is whether a funding brings money into the system or takes it out
+type FundingDirection string
+
+const (
+	// FundingDirectionFund moves money from the float account of its currency to an account
+	FundingDirectionFund FundingDirection = "fund"
+	// FundingDirectionDefund moves money from an account back to the float account of its currency
+	FundingDirectionDefund FundingDirection = "defund"
+)
+
+// IsValid checks if d is a known funding direction
+func (d FundingDirection) IsValid() bool {
+	return d == FundingDirectionFund || d == FundingDirectionDefund
+}
+
+// MaxFundingReferenceLength is the longest external reference a funding can carry, e.g. the ID of
+// the bank transfer that brought the money in
+const MaxFundingReferenceLength = 128
+
+// FloatAccount is the system float account of a currency with the totals of its funding history.
+// The float's balance is its initial balance less NetFunded, the money funded into the system
+// that is still in it.
+type FloatAccount struct {
+	Currency  string          `json:"currency"`
+	AccountID int64           `json:"account_id"`
+	Balance   decimal.Decimal `json:"balance"`
+	Funded    decimal.Decimal `json:"funded"`
+	Defunded  decimal.Decimal `json:"defunded"`
+	NetFunded decimal.Decimal `json:"net_funded"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// Funding is money moved into or out of the system through the float account of its currency,
+// recorded with the transfer that moved it
+type Funding struct {
+	ID            int64            `json:"id"`
+	TransactionID int64            `json:"transaction_id"`
+	Currency      string           `json:"currency"`
+	Direction     FundingDirection `json:"direction"`
+	AccountID     int64            `json:"account_id"`
+	Amount        decimal.Decimal  `json:"amount"`
+	Reference     string           `json:"reference,omitempty"`
+	Actor         string           `json:"actor"`
+	CreatedAt     string           `json:"created_at"`
+}
+
+// Validate checks a funding can be made: a known direction, a valid currency and a positive
+// amount within the supported precision
+func (f *Funding) Validate() error {
+	if !f.Direction.IsValid() {
+		return fmt.Errorf("%w: direction must be fund or defund", errors.ErrValidationFailed)
+	}
+	if err := ValidateCurrency(f.Currency); err != nil {
+		return err
+	}
+	if f.AccountID <= 0 {
+		return fmt.Errorf("%w: account_id is required", errors.ErrValidationFailed)
+	}
+	if !f.Amount.IsPositive() {
+		return errors.NewInvalidAmountError(f.Amount)
+	}
+	if err := ValidateAmountPrecision(f.Amount); err != nil {
+		return err
+	}
+	if len(f.Reference) > MaxFundingReferenceLength {
+		return fmt.Errorf("%w: reference must be at most %d characters", errors.ErrValidationFailed, MaxFundingReferenceLength)
+	}
+	if len(f.Actor) > MaxAuditActorLength {
+		return fmt.Errorf("%w: actor must be at most %d characters", errors.ErrValidationFailed, MaxAuditActorLength)
+	}
+	return nil
+}
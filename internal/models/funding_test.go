@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunding_Validate(t *testing.T) {
+	valid := func() *Funding {
+		return &Funding{Currency: "USD", Direction: FundingDirectionFund, AccountID: 1, Amount: decimal.NewFromInt(100), Reference: "wire-1", Actor: "treasury"}
+	}
+	assert.NoError(t, valid().Validate())
+
+	for name, mutate := range map[string]func(*Funding){
+		"direction": func(f *Funding) { f.Direction = "mint" },
+		"currency":  func(f *Funding) { f.Currency = "usd" },
+		"account":   func(f *Funding) { f.AccountID = 0 },
+		"reference": func(f *Funding) { f.Reference = strings.Repeat("r", MaxFundingReferenceLength+1) },
+		"actor":     func(f *Funding) { f.Actor = strings.Repeat("a", MaxAuditActorLength+1) },
+	} {
+		funding := valid()
+		mutate(funding)
+		assert.ErrorIs(t, funding.Validate(), errors.ErrValidationFailed, name)
+	}
+
+	negative := valid()
+	negative.Amount = decimal.NewFromInt(-1)
+	assert.ErrorIs(t, negative.Validate(), errors.ErrInvalidAmount)
+}
+
+func TestValidateAccountType_ReservesSystemFloat(t *testing.T) {
+	assert.NoError(t, ValidateAccountType("savings"))
+	assert.ErrorIs(t, ValidateAccountType(AccountTypeSystemFloat), errors.ErrValidationFailed)
+}
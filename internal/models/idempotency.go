@@ -0,0 +1,46 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// IdempotencyRecord is the stored outcome of a transfer made under a client idempotency key
+type IdempotencyRecord struct {
+	Owner         string // who the key belongs to (see auth.Owner); keys of different owners don't meet
+	Key           string
+	Fingerprint   string
+	TransactionID int64
+	Response      []byte // JSON encoded response returned to the first request, sealed if Sealed
+	Sealed        bool   // Response was encrypted and authenticated by an idempotency.Sealer
+	ExpiresAt     *time.Time
+	CreatedAt     string
+}
+
+// Expired checks if the record expired by now; a record without an expiry never does
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// SealingContext is the associated data a sealed response is bound to: the key, the transfer
+// it was stored for and the request fingerprint
+func (r *IdempotencyRecord) SealingContext() []byte {
+	return []byte(fmt.Sprintf("%s\x00%d\x00%s", r.Key, r.TransactionID, r.Fingerprint))
+}
+
+// ValidateIdempotencyKey checks a client idempotency key is non-blank and short enough to store
+func ValidateIdempotencyKey(key string) error {
+	return validateIdempotencyKey(key)
+}
+
+// TransferFingerprint identifies the request of a single transfer, so a key reused for a different
+// transfer can be told apart from a retry
+func TransferFingerprint(sourceID, destinationID int64, amount decimal.Decimal) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%d\x00%s", sourceID, destinationID, amount.String())
+	return hex.EncodeToString(hash.Sum(nil))
+}
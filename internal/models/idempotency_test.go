@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRecord_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Minute)
+
+	assert.False(t, (&IdempotencyRecord{}).Expired(now), "a record without an expiry never expires")
+	assert.False(t, (&IdempotencyRecord{ExpiresAt: &later}).Expired(now))
+	assert.True(t, (&IdempotencyRecord{ExpiresAt: &later}).Expired(later))
+	assert.True(t, (&IdempotencyRecord{ExpiresAt: &now}).Expired(later))
+}
+
+func TestIdempotencyRecord_SealingContext(t *testing.T) {
+	record := &IdempotencyRecord{Key: "retry-1", TransactionID: 7, Fingerprint: "abc"}
+	other := &IdempotencyRecord{Key: "retry-1", TransactionID: 8, Fingerprint: "abc"}
+
+	assert.Equal(t, record.SealingContext(), record.SealingContext())
+	assert.NotEqual(t, record.SealingContext(), other.SealingContext())
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// LedgerAccountType is the accounting classification of a ledger account
+type LedgerAccountType string
+
+const (
+	LedgerAccountTypeAsset     LedgerAccountType = "asset"
+	LedgerAccountTypeLiability LedgerAccountType = "liability"
+	LedgerAccountTypeEquity    LedgerAccountType = "equity"
+	LedgerAccountTypeIncome    LedgerAccountType = "income"
+	LedgerAccountTypeExpense   LedgerAccountType = "expense"
+)
+
+// IsValid checks if the type is one of the supported account types
+func (t LedgerAccountType) IsValid() bool {
+	switch t {
+	case LedgerAccountTypeAsset, LedgerAccountTypeLiability, LedgerAccountTypeEquity, LedgerAccountTypeIncome, LedgerAccountTypeExpense:
+		return true
+	}
+	return false
+}
+
+// NormalSide returns the side on which balances of this type normally increase: debit for assets
+// and expenses, credit for liabilities, equity and income
+func (t LedgerAccountType) NormalSide() EntrySide {
+	if t == LedgerAccountTypeAsset || t == LedgerAccountTypeExpense {
+		return EntrySideDebit
+	}
+	return EntrySideCredit
+}
+
+// EntrySide is the debit or credit side of a ledger posting
+type EntrySide string
+
+const (
+	EntrySideDebit  EntrySide = "debit"
+	EntrySideCredit EntrySide = "credit"
+)
+
+// IsValid checks if the side is debit or credit
+func (s EntrySide) IsValid() bool {
+	return s == EntrySideDebit || s == EntrySideCredit
+}
+
+// Limits on ledger account fields
+const (
+	MaxLedgerAccountCodeLength = 64
+	MaxLedgerAccountNameLength = 128
+)
+
+// LedgerAccount is an entry of the chart of accounts that ledger postings are made against.
+// AccountID optionally links it to the balance-carrying account that holds its funds.
+type LedgerAccount struct {
+	Code       string            `json:"code"`
+	Name       string            `json:"name"`
+	Type       LedgerAccountType `json:"account_type"`
+	NormalSide EntrySide         `json:"normal_side"`
+	AccountID  int64             `json:"account_id,omitempty"`
+	Active     bool              `json:"active"`
+	CreatedAt  string            `json:"created_at,omitempty"`
+	UpdatedAt  string            `json:"updated_at,omitempty"`
+}
+
+// LedgerAccountFilter selects ledger accounts; zero fields match everything
+type LedgerAccountFilter struct {
+	Type            LedgerAccountType
+	IncludeInactive bool
+}
+
+// Validate checks the code, name, type and normal side of a ledger account. An empty normal side
+// defaults to the normal side of the type; an explicit opposite side declares a contra account.
+func (a *LedgerAccount) Validate() error {
+	if err := ValidateLedgerAccountCode(a.Code); err != nil {
+		return err
+	}
+	if a.Name == "" || len(a.Name) > MaxLedgerAccountNameLength {
+		return fmt.Errorf("%w: ledger account name must be 1-%d characters", errors.ErrValidationFailed, MaxLedgerAccountNameLength)
+	}
+	if !a.Type.IsValid() {
+		return fmt.Errorf("%w: invalid ledger account type %q", errors.ErrValidationFailed, a.Type)
+	}
+	if a.NormalSide == "" {
+		a.NormalSide = a.Type.NormalSide()
+	}
+	if !a.NormalSide.IsValid() {
+		return fmt.Errorf("%w: normal side must be debit or credit", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// ValidateLedgerAccountCode checks a ledger account code is a short lowercase identifier
+func ValidateLedgerAccountCode(code string) error {
+	if code == "" || len(code) > MaxLedgerAccountCodeLength {
+		return fmt.Errorf("%w: ledger account code must be 1-%d characters", errors.ErrValidationFailed, MaxLedgerAccountCodeLength)
+	}
+	for _, c := range code {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '.' && c != '-' {
+			return fmt.Errorf("%w: ledger account code may only contain a-z, 0-9, '_', '.' and '-'", errors.ErrValidationFailed)
+		}
+	}
+	return nil
+}
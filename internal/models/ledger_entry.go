@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// LedgerEntry is one side of a leg of a double-entry posting. It is made against a
+// balance-carrying account, a ledger account of the chart, or a ledger account and the account
+// backing it; AccountID is zero for a ledger account kept in the ledger only. TransactionID is
+// zero for the opening credit recorded when an account is funded at creation. TransferType is the
+// product flow the posting belongs to, standard if empty. Currency is empty for entries posted
+// before they recorded one, which are in the currency of their account.
+type LedgerEntry struct {
+	ID            int64           `json:"id"`
+	TransactionID int64           `json:"transaction_id,omitempty"`
+	Leg           int             `json:"leg,omitempty"`
+	AccountID     int64           `json:"account_id,omitempty"`
+	LedgerCode    string          `json:"ledger_code,omitempty"`
+	Side          EntrySide       `json:"entry_type"`
+	TransferType  TransferType    `json:"transfer_type"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
+
+// LedgerBalance is an account's balance derived from its ledger entries
+type LedgerBalance struct {
+	AccountID int64           `json:"account_id"`
+	Credits   decimal.Decimal `json:"credits"`
+	Debits    decimal.Decimal `json:"debits"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// PostingEntries returns the entries of the postings of a transaction of a transfer type: a debit
+// and a credit per leg
+func PostingEntries(transactionID int64, transferType TransferType, postings []Posting) []*LedgerEntry {
+	entries := make([]*LedgerEntry, 0, 2*len(postings))
+	for _, posting := range postings {
+		entries = append(entries,
+			&LedgerEntry{TransactionID: transactionID, Leg: posting.Leg, AccountID: posting.Debit.AccountID, LedgerCode: posting.Debit.LedgerCode,
+				Side: EntrySideDebit, TransferType: transferType, Amount: posting.Amount, Currency: posting.Currency},
+			&LedgerEntry{TransactionID: transactionID, Leg: posting.Leg, AccountID: posting.Credit.AccountID, LedgerCode: posting.Credit.LedgerCode,
+				Side: EntrySideCredit, TransferType: transferType, Amount: posting.Amount, Currency: posting.Currency},
+		)
+	}
+	return entries
+}
+
+// CheckPostedBalances checks the entries of a transfer move exactly the balances the transfer
+// moves: debit off the source, credit onto the destination and nothing on any other account.
+// Entries of ledger accounts kept in the ledger only move no balance.
+func CheckPostedBalances(entries []*LedgerEntry, sourceID, destinationID int64, debit, credit decimal.Decimal) error {
+	moved := make(map[int64]decimal.Decimal)
+	for _, entry := range entries {
+		if entry.AccountID == 0 {
+			continue
+		}
+		amount := entry.Amount
+		if entry.Side == EntrySideDebit {
+			amount = amount.Neg()
+		}
+		moved[entry.AccountID] = moved[entry.AccountID].Add(amount)
+	}
+
+	for _, party := range []struct {
+		accountID int64
+		amount    decimal.Decimal
+	}{{sourceID, debit.Neg()}, {destinationID, credit}} {
+		if !moved[party.accountID].Equal(party.amount) {
+			return fmt.Errorf("%w: it moves %s on account %d, the transfer %s", errors.ErrPostingMismatch, moved[party.accountID], party.accountID, party.amount)
+		}
+	}
+	for accountID, amount := range moved {
+		if accountID != sourceID && accountID != destinationID && !amount.IsZero() {
+			return fmt.Errorf("%w: it moves %s on account %d, which isn't a party of the transfer", errors.ErrPostingMismatch, amount, accountID)
+		}
+	}
+	return nil
+}
+
+// ValidateBalancedEntries checks that the entries of a posting are well formed and that their
+// debits equal their credits in each currency
+func ValidateBalancedEntries(entries []*LedgerEntry) error {
+	if len(entries) < 2 {
+		return fmt.Errorf("%w: a posting needs at least two entries", errors.ErrValidationFailed)
+	}
+	debits, credits := make(map[string]decimal.Decimal), make(map[string]decimal.Decimal)
+	var currencies []string
+	for _, entry := range entries {
+		if !entry.Amount.IsPositive() {
+			return errors.NewInvalidAmountError(entry.Amount)
+		}
+		if _, ok := debits[entry.Currency]; !ok {
+			currencies = append(currencies, entry.Currency)
+			debits[entry.Currency], credits[entry.Currency] = decimal.Zero, decimal.Zero
+		}
+		switch entry.Side {
+		case EntrySideDebit:
+			debits[entry.Currency] = debits[entry.Currency].Add(entry.Amount)
+		case EntrySideCredit:
+			credits[entry.Currency] = credits[entry.Currency].Add(entry.Amount)
+		default:
+			return fmt.Errorf("%w: invalid entry type %q", errors.ErrValidationFailed, entry.Side)
+		}
+	}
+	for _, currency := range currencies {
+		if !debits[currency].Equal(credits[currency]) {
+			in := ""
+			if currency != "" {
+				in = " in " + currency
+			}
+			return fmt.Errorf("%w: debits %s do not equal credits %s%s", errors.ErrValidationFailed, debits[currency], credits[currency], in)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostingEntries(t *testing.T) {
+	amount := decimal.RequireFromString("12.5")
+	rules := []*PostingRule{
+		{Leg: 1, Debit: PostingPartySource, Credit: "customer_deposits"},
+		{Leg: 2, Debit: "customer_deposits", Credit: PostingPartyDestination},
+	}
+	entries := PostingEntries(7, TransferTypeAdjustment, ApplyPostingTemplate(rules, 1, 2, amount))
+
+	assert.Equal(t, []*LedgerEntry{
+		{TransactionID: 7, Leg: 1, AccountID: 1, Side: EntrySideDebit, TransferType: TransferTypeAdjustment, Amount: amount},
+		{TransactionID: 7, Leg: 1, LedgerCode: "customer_deposits", Side: EntrySideCredit, TransferType: TransferTypeAdjustment, Amount: amount},
+		{TransactionID: 7, Leg: 2, LedgerCode: "customer_deposits", Side: EntrySideDebit, TransferType: TransferTypeAdjustment, Amount: amount},
+		{TransactionID: 7, Leg: 2, AccountID: 2, Side: EntrySideCredit, TransferType: TransferTypeAdjustment, Amount: amount},
+	}, entries)
+	assert.NoError(t, ValidateBalancedEntries(entries))
+	assert.NoError(t, CheckPostedBalances(entries, 1, 2, amount, amount))
+}
+
+func TestCheckPostedBalances(t *testing.T) {
+	amount := decimal.NewFromInt(10)
+	post := func(rules ...*PostingRule) []*LedgerEntry {
+		for i, rule := range rules {
+			rule.Leg = i + 1
+		}
+		return PostingEntries(7, TransferTypeStandard, ApplyPostingTemplate(rules, 1, 2, amount))
+	}
+
+	// A ledger account backed by a third account moves its balance
+	backed := post(&PostingRule{Debit: PostingPartySource, Credit: "fee_income"})
+	backed[1].AccountID = 3
+
+	tests := map[string][]*LedgerEntry{
+		"reversed":            post(&PostingRule{Debit: PostingPartyDestination, Credit: PostingPartySource}),
+		"source only":         post(&PostingRule{Debit: PostingPartySource, Credit: "suspense"}),
+		"destination only":    post(&PostingRule{Debit: "suspense", Credit: PostingPartyDestination}),
+		"third account moved": backed,
+	}
+	for name, entries := range tests {
+		assert.ErrorIs(t, CheckPostedBalances(entries, 1, 2, amount, amount), errors.ErrPostingMismatch, name)
+	}
+}
+
+func TestValidateBalancedEntries(t *testing.T) {
+	one, two := decimal.NewFromInt(1), decimal.NewFromInt(2)
+
+	assert.NoError(t, ValidateBalancedEntries([]*LedgerEntry{
+		{Side: EntrySideDebit, Amount: two},
+		{Side: EntrySideCredit, Amount: one},
+		{Side: EntrySideCredit, Amount: one},
+	}))
+
+	tests := map[string][]*LedgerEntry{
+		"single entry": {{Side: EntrySideDebit, Amount: one}},
+		"unbalanced":   {{Side: EntrySideDebit, Amount: two}, {Side: EntrySideCredit, Amount: one}},
+		"bad side":     {{Side: EntrySideDebit, Amount: one}, {Side: "both", Amount: one}},
+	}
+	for name, entries := range tests {
+		assert.ErrorIs(t, ValidateBalancedEntries(entries), errors.ErrValidationFailed, name)
+	}
+
+	// Each currency balances on its own
+	assert.NoError(t, ValidateBalancedEntries([]*LedgerEntry{
+		{Side: EntrySideDebit, Amount: two, Currency: "USD"}, {Side: EntrySideCredit, Amount: two, Currency: "USD"},
+		{Side: EntrySideDebit, Amount: one, Currency: "EUR"}, {Side: EntrySideCredit, Amount: one, Currency: "EUR"},
+	}))
+	assert.ErrorIs(t, ValidateBalancedEntries([]*LedgerEntry{
+		{Side: EntrySideDebit, Amount: two, Currency: "USD"}, {Side: EntrySideCredit, Amount: two, Currency: "EUR"},
+	}), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ValidateBalancedEntries([]*LedgerEntry{
+		{Side: EntrySideDebit, Amount: decimal.Zero}, {Side: EntrySideCredit, Amount: decimal.Zero},
+	}), errors.ErrInvalidAmount)
+}
+
+func TestConvertPostings(t *testing.T) {
+	amount, converted := decimal.NewFromInt(10), decimal.NewFromInt(9)
+	rules := []*PostingRule{
+		{Leg: 1, Debit: PostingPartySource, Credit: "customer_deposits"},
+		{Leg: 2, Debit: "customer_deposits", Credit: PostingPartyDestination},
+	}
+
+	// Within one currency the postings only get the currency
+	postings := ConvertPostings(ApplyPostingTemplate(rules, 1, 2, amount), 2, "", "USD", nil)
+	require.Len(t, postings, 2)
+	assert.Equal(t, "USD", postings[0].Currency)
+	assert.Equal(t, "USD", postings[1].Currency)
+
+	// The leg crossing currencies is split through fx_position
+	postings = ConvertPostings(ApplyPostingTemplate(rules, 1, 2, amount), 2, "USD", "EUR", &converted)
+	fxPosition := PostingTarget{LedgerCode: LedgerCodeFXPosition}
+	assert.Equal(t, []Posting{
+		{Leg: 1, Debit: PostingTarget{AccountID: 1}, Credit: PostingTarget{LedgerCode: "customer_deposits"}, Amount: amount, Currency: "USD"},
+		{Leg: 2, Debit: PostingTarget{LedgerCode: "customer_deposits"}, Credit: fxPosition, Amount: amount, Currency: "USD"},
+		{Leg: 3, Debit: fxPosition, Credit: PostingTarget{AccountID: 2}, Amount: converted, Currency: "EUR"},
+	}, postings)
+
+	entries := PostingEntries(7, TransferTypeStandard, postings)
+	assert.NoError(t, ValidateBalancedEntries(entries))
+	assert.NoError(t, CheckPostedBalances(entries, 1, 2, amount, converted))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerAccount_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		account LedgerAccount
+		side    EntrySide
+		err     error
+	}{
+		{name: "asset defaults to debit", account: LedgerAccount{Code: "cash", Name: "Cash", Type: LedgerAccountTypeAsset}, side: EntrySideDebit},
+		{name: "income defaults to credit", account: LedgerAccount{Code: "fee_income", Name: "Fees", Type: LedgerAccountTypeIncome}, side: EntrySideCredit},
+		{name: "contra account", account: LedgerAccount{Code: "fee_refunds", Name: "Fee refunds", Type: LedgerAccountTypeIncome, NormalSide: EntrySideDebit}, side: EntrySideDebit},
+		{name: "uppercase code", account: LedgerAccount{Code: "Cash", Name: "Cash", Type: LedgerAccountTypeAsset}, err: errors.ErrValidationFailed},
+		{name: "missing name", account: LedgerAccount{Code: "cash", Type: LedgerAccountTypeAsset}, err: errors.ErrValidationFailed},
+		{name: "unknown type", account: LedgerAccount{Code: "cash", Name: "Cash", Type: "revenue"}, err: errors.ErrValidationFailed},
+		{name: "unknown side", account: LedgerAccount{Code: "cash", Name: "Cash", Type: LedgerAccountTypeAsset, NormalSide: "left"}, err: errors.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.account.Validate()
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.side, tt.account.NormalSide)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// ShadowTransfer is what the row-locking transfer path would have left on the two accounts of a
+// transfer had its dry run been committed
+type ShadowTransfer struct {
+	SourceBalance      decimal.Decimal
+	DestinationBalance decimal.Decimal
+}
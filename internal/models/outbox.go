@@ -0,0 +1,16 @@
+package models
+
+import "encoding/json"
+
+// OutboxMessage is an event recorded in the outbox by the database transaction that raised it,
+// waiting to be published by the relay
+type OutboxMessage struct {
+	ID      int64           `json:"id"`
+	Event   string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempts counts the failed attempts to publish the message, LastError is the latest failure
+	Attempts    int    `json:"attempts"`
+	LastError   string `json:"last_error,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
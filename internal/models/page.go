@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Bounds on the page size of transaction listings
+const (
+	DefaultTransactionPageSize = 50
+	MaxTransactionPageSize     = 500
+)
+
+// TransactionCursor is the position of a transaction in an account's history, which is ordered by
+// (created_at, id) newest first. A page continues with the transactions strictly after it.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTransactionCursor parses a cursor produced by Encode
+func DecodeTransactionCursor(s string) (TransactionCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", errors.ErrValidationFailed)
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TransactionCursor{}, invalid
+	}
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return TransactionCursor{}, invalid
+	}
+	var cursor TransactionCursor
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return TransactionCursor{}, invalid
+	}
+	if cursor.ID, err = strconv.ParseInt(id, 10, 64); err != nil || cursor.ID <= 0 {
+		return TransactionCursor{}, invalid
+	}
+	return cursor, nil
+}
+
+// TransactionPage is one page of an account's transactions, newest first. NextCursor is empty on
+// the last page.
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+}
+
+// TransactionDirection selects which side of a transfer the listed account was on
+type TransactionDirection string
+
+const (
+	// TransactionDirectionOutgoing matches transfers the account was the source of
+	TransactionDirectionOutgoing TransactionDirection = "outgoing"
+	// TransactionDirectionIncoming matches transfers the account was the destination of
+	TransactionDirectionIncoming TransactionDirection = "incoming"
+)
+
+// TransactionFilter narrows an account's transaction listing; zero fields match everything. From
+// and To bound created_at as [From, To); MinAmount and MaxAmount are inclusive.
+type TransactionFilter struct {
+	Status    TransactionStatus
+	From      time.Time
+	To        time.Time
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
+	Direction TransactionDirection
+}
+
+// Validate checks that the filter's values are known and its ranges are not inverted
+func (f TransactionFilter) Validate() error {
+	switch f.Status {
+	case "", TransactionStatusPending, TransactionStatusComplete, TransactionStatusFailed, TransactionStatusReversed:
+	default:
+		return fmt.Errorf("%w: invalid status %q", errors.ErrValidationFailed, f.Status)
+	}
+	switch f.Direction {
+	case "", TransactionDirectionOutgoing, TransactionDirectionIncoming:
+	default:
+		return fmt.Errorf("%w: invalid direction %q", errors.ErrValidationFailed, f.Direction)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("%w: from must be before to", errors.ErrValidationFailed)
+	}
+	if f.MinAmount != nil && f.MinAmount.IsNegative() || f.MaxAmount != nil && f.MaxAmount.IsNegative() {
+		return fmt.Errorf("%w: amount bounds must not be negative", errors.ErrValidationFailed)
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && f.MinAmount.GreaterThan(*f.MaxAmount) {
+		return fmt.Errorf("%w: min_amount must not exceed max_amount", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// Matches reports whether a transaction listed for accountID passes the filter
+func (f TransactionFilter) Matches(accountID int64, tx *Transaction, createdAt time.Time) bool {
+	switch {
+	case f.Status != "" && tx.Status != f.Status,
+		!f.From.IsZero() && createdAt.Before(f.From),
+		!f.To.IsZero() && !createdAt.Before(f.To),
+		f.MinAmount != nil && tx.Amount.LessThan(*f.MinAmount),
+		f.MaxAmount != nil && tx.Amount.GreaterThan(*f.MaxAmount),
+		f.Direction == TransactionDirectionOutgoing && tx.SourceAccountID != accountID,
+		f.Direction == TransactionDirectionIncoming && tx.DestinationAccountID != accountID:
+		return false
+	}
+	return true
+}
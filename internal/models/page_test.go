@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	cursor := TransactionCursor{CreatedAt: time.Date(2024, 3, 1, 10, 0, 0, 123456000, time.FixedZone("SGT", 8*3600)), ID: 42}
+
+	decoded, err := DecodeTransactionCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt), "sub-second precision is kept")
+	assert.Equal(t, int64(42), decoded.ID)
+
+	for _, invalid := range []string{"", "!!", "bm90LWEtY3Vyc29y", TransactionCursor{CreatedAt: time.Now()}.Encode()} {
+		_, err := DecodeTransactionCursor(invalid)
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, invalid)
+	}
+}
+
+func TestTransactionFilter(t *testing.T) {
+	ten, hundred, negative := decimal.NewFromInt(10), decimal.NewFromInt(100), decimal.NewFromInt(-1)
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := TransactionFilter{Status: TransactionStatusComplete, From: now, To: now.Add(time.Hour), MinAmount: &ten, MaxAmount: &hundred, Direction: TransactionDirectionIncoming}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, TransactionFilter{}.Validate())
+
+	for name, invalid := range map[string]TransactionFilter{
+		"status":          {Status: "settled"},
+		"direction":       {Direction: "sideways"},
+		"inverted window": {From: now, To: now},
+		"negative amount": {MinAmount: &negative},
+		"inverted amount": {MinAmount: &hundred, MaxAmount: &ten},
+	} {
+		assert.ErrorIs(t, invalid.Validate(), errors.ErrValidationFailed, name)
+	}
+
+	tx := &Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(50), Status: TransactionStatusComplete}
+	assert.True(t, valid.Matches(2, tx, now))
+	assert.True(t, TransactionFilter{}.Matches(1, tx, now))
+	assert.False(t, valid.Matches(1, tx, now), "outgoing for account 1")
+	assert.False(t, valid.Matches(2, tx, now.Add(time.Hour)), "to is exclusive")
+	assert.False(t, TransactionFilter{MaxAmount: &ten}.Matches(1, tx, now))
+	assert.False(t, TransactionFilter{Status: TransactionStatusFailed}.Matches(1, tx, now))
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// TransferType is the product flow a transfer belongs to; it selects the posting template
+type TransferType string
+
+const (
+	TransferTypeStandard   TransferType = "standard"
+	TransferTypeFee        TransferType = "fee"
+	TransferTypeInterest   TransferType = "interest"
+	TransferTypeAdjustment TransferType = "adjustment"
+	TransferTypeReversal   TransferType = "reversal"
+)
+
+// MaxTransferTypeLength is the longest transfer type that can be stored
+const MaxTransferTypeLength = 32
+
+// ValidateTransferType checks a transfer type is a short lowercase identifier. Types beyond the
+// predefined ones are allowed so new flows only need their posting rules configured.
+func ValidateTransferType(t TransferType) error {
+	if t == "" || len(t) > MaxTransferTypeLength {
+		return fmt.Errorf("%w: transfer type must be 1-%d characters", errors.ErrValidationFailed, MaxTransferTypeLength)
+	}
+	for _, c := range t {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return fmt.Errorf("%w: transfer type may only contain a-z, 0-9 and '_'", errors.ErrValidationFailed)
+		}
+	}
+	return nil
+}
+
+// Parties of a transfer that a posting rule side can refer to instead of a ledger account
+const (
+	PostingPartySource      = "@source"
+	PostingPartyDestination = "@destination"
+)
+
+// MaxPostingLegs is the largest number of legs a posting template may have
+const MaxPostingLegs = 10
+
+// PostingRule is one leg of the posting template of a transfer type: it debits Debit and credits
+// Credit with the transfer amount. Each side is a transfer party or a ledger account code.
+type PostingRule struct {
+	TransferType TransferType `json:"transfer_type"`
+	Leg          int          `json:"leg"`
+	Debit        string       `json:"debit"`
+	Credit       string       `json:"credit"`
+	Description  string       `json:"description,omitempty"`
+}
+
+// IsParty checks if a posting rule side refers to a party of the transfer
+func IsParty(side string) bool {
+	return side == PostingPartySource || side == PostingPartyDestination
+}
+
+// ValidatePostingTemplate checks the legs of a template are numbered 1..n in order and that each
+// side is a party or a well-formed ledger account code, distinct from the other side
+func ValidatePostingTemplate(rules []*PostingRule) error {
+	if len(rules) == 0 || len(rules) > MaxPostingLegs {
+		return fmt.Errorf("%w: a posting template must have 1-%d legs", errors.ErrValidationFailed, MaxPostingLegs)
+	}
+	for i, rule := range rules {
+		if rule.Leg != i+1 {
+			return fmt.Errorf("%w: posting legs must be numbered 1-%d in order", errors.ErrValidationFailed, len(rules))
+		}
+		for _, side := range []string{rule.Debit, rule.Credit} {
+			if strings.HasPrefix(side, "@") {
+				if !IsParty(side) {
+					return fmt.Errorf("%w: leg %d: unknown party %q", errors.ErrValidationFailed, rule.Leg, side)
+				}
+				continue
+			}
+			if err := ValidateLedgerAccountCode(side); err != nil {
+				return fmt.Errorf("leg %d: %w", rule.Leg, err)
+			}
+		}
+		if rule.Debit == rule.Credit {
+			return fmt.Errorf("%w: leg %d debits and credits %s", errors.ErrValidationFailed, rule.Leg, rule.Debit)
+		}
+	}
+	return nil
+}
+
+// PostingTarget is the resolved side of a posting: a customer account, or a ledger account and the
+// account backing it, if any
+type PostingTarget struct {
+	AccountID  int64  `json:"account_id,omitempty"`
+	LedgerCode string `json:"ledger_code,omitempty"`
+}
+
+// Posting is a posting rule applied to a transfer. Currency is that of the amount, empty if the
+// transfer's accounts don't keep one.
+type Posting struct {
+	Leg      int             `json:"leg"`
+	Debit    PostingTarget   `json:"debit"`
+	Credit   PostingTarget   `json:"credit"`
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency,omitempty"`
+}
+
+// LedgerCodeFXPosition is the ledger account the legs of a cross-currency transfer are converted
+// through
+const LedgerCodeFXPosition = "fx_position"
+
+// ApplyPostingTemplate resolves the parties of a template against a transfer. Ledger account codes
+// are passed through; callers must check they are postable and set their backing accounts.
+func ApplyPostingTemplate(rules []*PostingRule, sourceID, destinationID int64, amount decimal.Decimal) []Posting {
+	target := func(side string) PostingTarget {
+		switch side {
+		case PostingPartySource:
+			return PostingTarget{AccountID: sourceID}
+		case PostingPartyDestination:
+			return PostingTarget{AccountID: destinationID}
+		}
+		return PostingTarget{LedgerCode: side}
+	}
+
+	postings := make([]Posting, 0, len(rules))
+	for _, rule := range rules {
+		postings = append(postings, Posting{
+			Leg:    rule.Leg,
+			Debit:  target(rule.Debit),
+			Credit: target(rule.Credit),
+			Amount: amount,
+		})
+	}
+	return postings
+}
+
+// ConvertPostings prices the postings of a transfer in its currencies. Sides on the destination
+// are in the destination currency, at the converted amount of a cross-currency transfer; the
+// others are in the source currency. A leg whose sides are in different currencies is split in
+// two through fx_position, so each currency balances, and the legs are renumbered.
+func ConvertPostings(postings []Posting, destinationID int64, sourceCurrency, destinationCurrency string, converted *decimal.Decimal) []Posting {
+	if converted == nil {
+		currency := sourceCurrency
+		if currency == "" {
+			currency = destinationCurrency
+		}
+		for i := range postings {
+			postings[i].Currency = currency
+		}
+		return postings
+	}
+
+	price := func(target PostingTarget, amount decimal.Decimal) (decimal.Decimal, string) {
+		if target.AccountID == destinationID {
+			return *converted, destinationCurrency
+		}
+		return amount, sourceCurrency
+	}
+	fxPosition := PostingTarget{LedgerCode: LedgerCodeFXPosition}
+	priced := make([]Posting, 0, 2*len(postings))
+	for _, posting := range postings {
+		debitAmount, debitCurrency := price(posting.Debit, posting.Amount)
+		creditAmount, creditCurrency := price(posting.Credit, posting.Amount)
+		if debitCurrency == creditCurrency {
+			priced = append(priced, Posting{Debit: posting.Debit, Credit: posting.Credit, Amount: debitAmount, Currency: debitCurrency})
+			continue
+		}
+		priced = append(priced,
+			Posting{Debit: posting.Debit, Credit: fxPosition, Amount: debitAmount, Currency: debitCurrency},
+			Posting{Debit: fxPosition, Credit: posting.Credit, Amount: creditAmount, Currency: creditCurrency},
+		)
+	}
+	for i := range priced {
+		priced[i].Leg = i + 1
+	}
+	return priced
+}
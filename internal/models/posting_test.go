@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePostingTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []*PostingRule
+		err   error
+	}{
+		{name: "transfer", rules: []*PostingRule{{Leg: 1, Debit: "@source", Credit: "@destination"}}},
+		{name: "transfer with fee", rules: []*PostingRule{
+			{Leg: 1, Debit: "@source", Credit: "@destination"},
+			{Leg: 2, Debit: "@source", Credit: "fee_income"},
+		}},
+		{name: "empty", err: errors.ErrValidationFailed},
+		{name: "unknown party", rules: []*PostingRule{{Leg: 1, Debit: "@payer", Credit: "@destination"}}, err: errors.ErrValidationFailed},
+		{name: "same side", rules: []*PostingRule{{Leg: 1, Debit: "suspense", Credit: "suspense"}}, err: errors.ErrValidationFailed},
+		{name: "legs out of order", rules: []*PostingRule{{Leg: 2, Debit: "@source", Credit: "@destination"}}, err: errors.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePostingTemplate(tt.rules)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestApplyPostingTemplate(t *testing.T) {
+	rules := []*PostingRule{
+		{Leg: 1, Debit: PostingPartySource, Credit: PostingPartyDestination},
+		{Leg: 2, Debit: "interest_expense", Credit: PostingPartyDestination},
+	}
+
+	postings := ApplyPostingTemplate(rules, 1, 2, decimal.NewFromInt(10))
+	assert.Equal(t, []Posting{
+		{Leg: 1, Debit: PostingTarget{AccountID: 1}, Credit: PostingTarget{AccountID: 2}, Amount: decimal.NewFromInt(10)},
+		{Leg: 2, Debit: PostingTarget{LedgerCode: "interest_expense"}, Credit: PostingTarget{AccountID: 2}, Amount: decimal.NewFromInt(10)},
+	}, postings)
+}
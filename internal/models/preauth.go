@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PreAuthorizationStatus is the lifecycle status of a pre-authorization
+type PreAuthorizationStatus string
+
+const (
+	// PreAuthorizationStatusActive means the funds are reserved on the source and may be transferred
+	PreAuthorizationStatusActive PreAuthorizationStatus = "active"
+	// PreAuthorizationStatusExecuted means the reserved funds were transferred
+	PreAuthorizationStatusExecuted PreAuthorizationStatus = "executed"
+	// PreAuthorizationStatusExpired means the pre-authorization lapsed and its reservation was released
+	PreAuthorizationStatusExpired PreAuthorizationStatus = "expired"
+)
+
+// PreAuthorization is the first step of a two-step transfer: the transfer was validated and its
+// amount reserved on the source account until ExpiresAt
+type PreAuthorization struct {
+	ID                   int64                  `json:"id"`
+	SourceAccountID      int64                  `json:"source_account_id"`
+	DestinationAccountID int64                  `json:"destination_account_id"`
+	Amount               decimal.Decimal        `json:"amount"`
+	Status               PreAuthorizationStatus `json:"status"`
+	ExpiresAt            string                 `json:"expires_at"`
+	TransactionID        int64                  `json:"transaction_id,omitempty"`
+	ResolvedAt           string                 `json:"resolved_at,omitempty"`
+	CreatedAt            string                 `json:"created_at"`
+}
+
+// IsActive checks if the reservation is still held
+func (p *PreAuthorization) IsActive() bool {
+	return p.Status == PreAuthorizationStatusActive
+}
+
+// IsExpiredAt checks if the pre-authorization can no longer be executed at now
+func (p *PreAuthorization) IsExpiredAt(now time.Time) bool {
+	expiresAt, err := time.Parse(time.RFC3339, p.ExpiresAt)
+	return err != nil || !now.Before(expiresAt)
+}
@@ -0,0 +1,19 @@
+package models
+
+// Names of the queues of background work
+const (
+	QueueOutbox             = "outbox"
+	QueueTransferJobs       = "transfer_jobs"
+	QueueScheduledTransfers = "scheduled_transfers"
+	QueueWebhookRetries     = "webhook_retries"
+)
+
+// QueueDepth is the backlog of a queue of background work: the items waiting in it, how many of
+// those are due, and how long the oldest due item has been due. A due item that keeps aging
+// means no worker is draining the queue.
+type QueueDepth struct {
+	Queue               string  `json:"queue"`
+	Depth               int64   `json:"depth"`
+	Due                 int64   `json:"due"`
+	OldestDueAgeSeconds float64 `json:"oldest_due_age_seconds"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// ScheduledTransferStatus is the lifecycle status of a scheduled transfer
+type ScheduledTransferStatus string
+
+const (
+	// ScheduledTransferStatusScheduled means the transfer is waiting for its execution time or a retry
+	ScheduledTransferStatusScheduled ScheduledTransferStatus = "scheduled"
+	// ScheduledTransferStatusExecuted means the transfer was made
+	ScheduledTransferStatusExecuted ScheduledTransferStatus = "executed"
+	// ScheduledTransferStatusFailed means every attempt failed; LastError holds the last failure
+	ScheduledTransferStatusFailed ScheduledTransferStatus = "failed"
+	// ScheduledTransferStatusCancelled means the transfer was cancelled before it was made
+	ScheduledTransferStatusCancelled ScheduledTransferStatus = "cancelled"
+)
+
+// IsValid checks if s is a known scheduled transfer status
+func (s ScheduledTransferStatus) IsValid() bool {
+	return s == ScheduledTransferStatusScheduled || s == ScheduledTransferStatusExecuted ||
+		s == ScheduledTransferStatusFailed || s == ScheduledTransferStatusCancelled
+}
+
+// ScheduledTransfer is a transfer to be made at ExecuteAt. A failed attempt is retried at
+// NextAttemptAt until the attempts run out.
+type ScheduledTransfer struct {
+	ID                   int64                   `json:"id"`
+	SourceAccountID      int64                   `json:"source_account_id"`
+	DestinationAccountID int64                   `json:"destination_account_id"`
+	Amount               decimal.Decimal         `json:"amount"`
+	ExecuteAt            string                  `json:"execute_at"`
+	Status               ScheduledTransferStatus `json:"status"`
+	Attempts             int                     `json:"attempts"`
+	NextAttemptAt        string                  `json:"next_attempt_at"`
+	LastError            string                  `json:"last_error,omitempty"`
+	TransactionID        int64                   `json:"transaction_id,omitempty"`
+	StandingOrderID      int64                   `json:"standing_order_id,omitempty"`
+	ResolvedAt           string                  `json:"resolved_at,omitempty"`
+	CreatedAt            string                  `json:"created_at"`
+}
+
+// IsScheduled checks if the transfer is still waiting to be made
+func (t *ScheduledTransfer) IsScheduled() bool {
+	return t.Status == ScheduledTransferStatusScheduled
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// MaxSplitLegs is the largest number of destinations a split transfer can pay
+const MaxSplitLegs = 500
+
+// SplitLeg is the part of a split transfer paid to one destination
+type SplitLeg struct {
+	DestinationAccountID int64
+	Amount               decimal.Decimal
+}
+
+// SplitTransfer is a single debit of a source account split across several destinations in one
+// atomic operation: either every leg is made or none is. Each leg is recorded as a transaction
+// carrying the split transfer's ID.
+type SplitTransfer struct {
+	ID              int64           `json:"id"`
+	SourceAccountID int64           `json:"source_account_id"`
+	Amount          decimal.Decimal `json:"amount"` // total of the legs
+	Legs            []*Transaction  `json:"legs"`
+	CreatedAt       string          `json:"created_at"`
+}
+
+// NewSplitTransfer validates the legs of a split transfer from sourceID and returns it with its
+// total amount and a pending transaction per leg, in the order given. A destination can only be
+// paid by one leg.
+func NewSplitTransfer(sourceID int64, legs []SplitLeg) (*SplitTransfer, error) {
+	if len(legs) == 0 || len(legs) > MaxSplitLegs {
+		return nil, fmt.Errorf("%w: a split transfer must have 1-%d legs", errors.ErrValidationFailed, MaxSplitLegs)
+	}
+
+	split := &SplitTransfer{SourceAccountID: sourceID, Amount: decimal.Zero, Legs: make([]*Transaction, 0, len(legs))}
+	seen := make(map[int64]bool, len(legs))
+	for _, leg := range legs {
+		if seen[leg.DestinationAccountID] {
+			return nil, fmt.Errorf("%w: destination account %d is paid by more than one leg", errors.ErrValidationFailed, leg.DestinationAccountID)
+		}
+		seen[leg.DestinationAccountID] = true
+
+		transaction := &Transaction{
+			SourceAccountID:      sourceID,
+			DestinationAccountID: leg.DestinationAccountID,
+			Amount:               leg.Amount,
+			Status:               TransactionStatusPending,
+		}
+		if err := transaction.Validate(); err != nil {
+			return nil, err
+		}
+		split.Legs = append(split.Legs, transaction)
+		split.Amount = split.Amount.Add(leg.Amount)
+	}
+	if err := ValidateAmountPrecision(split.Amount); err != nil {
+		return nil, err
+	}
+	return split, nil
+}
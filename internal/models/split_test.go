@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSplitTransfer(t *testing.T) {
+	split, err := NewSplitTransfer(1, []SplitLeg{
+		{DestinationAccountID: 2, Amount: decimal.RequireFromString("100.50")},
+		{DestinationAccountID: 3, Amount: decimal.RequireFromString("20")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "120.5", split.Amount.String())
+	if assert.Len(t, split.Legs, 2) {
+		assert.Equal(t, int64(1), split.Legs[0].SourceAccountID)
+		assert.Equal(t, int64(2), split.Legs[0].DestinationAccountID)
+		assert.Equal(t, TransactionStatusPending, split.Legs[0].Status)
+		assert.Equal(t, int64(3), split.Legs[1].DestinationAccountID)
+	}
+}
+
+func TestNewSplitTransfer_Invalid(t *testing.T) {
+	one := decimal.NewFromInt(1)
+	tooMany := make([]SplitLeg, MaxSplitLegs+1)
+	for i := range tooMany {
+		tooMany[i] = SplitLeg{DestinationAccountID: int64(i + 2), Amount: one}
+	}
+
+	tests := []struct {
+		name     string
+		legs     []SplitLeg
+		expected error
+	}{
+		{name: "no legs", expected: errors.ErrValidationFailed},
+		{name: "too many legs", legs: tooMany, expected: errors.ErrValidationFailed},
+		{
+			name:     "destination paid twice",
+			legs:     []SplitLeg{{DestinationAccountID: 2, Amount: one}, {DestinationAccountID: 2, Amount: one}},
+			expected: errors.ErrValidationFailed,
+		},
+		{name: "leg to the source", legs: []SplitLeg{{DestinationAccountID: 1, Amount: one}}, expected: errors.ErrSameAccount},
+		{name: "zero leg", legs: []SplitLeg{{DestinationAccountID: 2, Amount: decimal.Zero}}, expected: errors.ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSplitTransfer(1, tt.legs)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// StandingOrderFrequency is how often a standing order recurs
+type StandingOrderFrequency string
+
+const (
+	StandingOrderDaily   StandingOrderFrequency = "daily"
+	StandingOrderWeekly  StandingOrderFrequency = "weekly"
+	StandingOrderMonthly StandingOrderFrequency = "monthly"
+)
+
+// IsValid checks if the frequency is one of the supported frequencies
+func (f StandingOrderFrequency) IsValid() bool {
+	return f == StandingOrderDaily || f == StandingOrderWeekly || f == StandingOrderMonthly
+}
+
+// StandingOrderStatus is the lifecycle status of a standing order
+type StandingOrderStatus string
+
+const (
+	// StandingOrderStatusActive means occurrences are scheduled as they come due
+	StandingOrderStatusActive StandingOrderStatus = "active"
+	// StandingOrderStatusPaused means occurrences coming due are skipped until the order is resumed
+	StandingOrderStatusPaused StandingOrderStatus = "paused"
+	// StandingOrderStatusCancelled means the order was cancelled before its last occurrence
+	StandingOrderStatusCancelled StandingOrderStatus = "cancelled"
+	// StandingOrderStatusCompleted means the order reached its end date or maximum occurrences
+	StandingOrderStatusCompleted StandingOrderStatus = "completed"
+)
+
+// Recurrence is the schedule of a standing order: occurrences at Frequency from StartAt, until
+// EndAt (inclusive) if set and at most MaxOccurrences if positive
+type Recurrence struct {
+	Frequency      StandingOrderFrequency
+	StartAt        time.Time
+	EndAt          time.Time
+	MaxOccurrences int
+}
+
+// Validate checks the frequency and that the schedule has at least one occurrence
+func (r Recurrence) Validate() error {
+	if !r.Frequency.IsValid() {
+		return fmt.Errorf("%w: invalid frequency %q", errors.ErrValidationFailed, r.Frequency)
+	}
+	if r.StartAt.IsZero() {
+		return fmt.Errorf("%w: start_at is required", errors.ErrValidationFailed)
+	}
+	if !r.EndAt.IsZero() && r.EndAt.Before(r.StartAt) {
+		return fmt.Errorf("%w: end_at must not be before start_at", errors.ErrValidationFailed)
+	}
+	if r.MaxOccurrences < 0 {
+		return fmt.Errorf("%w: max_occurrences must not be negative", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// occurrence returns the nth occurrence, counting from 0 at StartAt. A monthly occurrence falls
+// on the day of month of StartAt, or on the last day of a shorter month, without drifting.
+func (r Recurrence) occurrence(n int) time.Time {
+	start := r.StartAt
+	switch r.Frequency {
+	case StandingOrderWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case StandingOrderMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		return first.AddDate(0, 0, min(start.Day(), lastDay)-1)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// OccurrenceAfter returns the first occurrence strictly after t, ignoring the end of the schedule
+func (r Recurrence) OccurrenceAfter(t time.Time) time.Time {
+	if t.Before(r.StartAt) {
+		return r.StartAt
+	}
+	// Estimate the index from below, then step forward
+	var n int
+	switch r.Frequency {
+	case StandingOrderWeekly:
+		n = int(t.Sub(r.StartAt) / (7 * 24 * time.Hour))
+	case StandingOrderMonthly:
+		n = (t.Year()-r.StartAt.Year())*12 + int(t.Month()-r.StartAt.Month())
+	default:
+		n = int(t.Sub(r.StartAt) / (24 * time.Hour))
+	}
+	n = max(n-1, 0)
+	for !r.occurrence(n).After(t) {
+		n++
+	}
+	return r.occurrence(n)
+}
+
+// Ended reports whether the schedule has no occurrence at next once made occurrences were made
+func (r Recurrence) Ended(made int, next time.Time) bool {
+	return r.MaxOccurrences > 0 && made >= r.MaxOccurrences || !r.EndAt.IsZero() && next.After(r.EndAt)
+}
+
+// StandingOrder is a transfer made on a recurring schedule. Each occurrence becomes a scheduled
+// transfer linked to the order, made and retried like any other.
+type StandingOrder struct {
+	ID                   int64                  `json:"id"`
+	SourceAccountID      int64                  `json:"source_account_id"`
+	DestinationAccountID int64                  `json:"destination_account_id"`
+	Amount               decimal.Decimal        `json:"amount"`
+	Frequency            StandingOrderFrequency `json:"frequency"`
+	StartAt              string                 `json:"start_at"`
+	EndAt                string                 `json:"end_at,omitempty"`
+	MaxOccurrences       int                    `json:"max_occurrences,omitempty"`
+	Occurrences          int                    `json:"occurrences"`
+	Status               StandingOrderStatus    `json:"status"`
+	NextRunAt            string                 `json:"next_run_at"`
+	CreatedAt            string                 `json:"created_at"`
+	UpdatedAt            string                 `json:"updated_at"`
+}
+
+// Recurrence returns the schedule of the order, evaluated in UTC
+func (o *StandingOrder) Recurrence() (Recurrence, error) {
+	recurrence := Recurrence{Frequency: o.Frequency, MaxOccurrences: o.MaxOccurrences}
+	startAt, err := time.Parse(time.RFC3339, o.StartAt)
+	if err != nil {
+		return Recurrence{}, fmt.Errorf("invalid start_at %q of standing order %d: %w", o.StartAt, o.ID, err)
+	}
+	recurrence.StartAt = startAt.UTC()
+	if o.EndAt != "" {
+		endAt, err := time.Parse(time.RFC3339, o.EndAt)
+		if err != nil {
+			return Recurrence{}, fmt.Errorf("invalid end_at %q of standing order %d: %w", o.EndAt, o.ID, err)
+		}
+		recurrence.EndAt = endAt.UTC()
+	}
+	return recurrence, nil
+}
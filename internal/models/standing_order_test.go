@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrence_OccurrenceAfter(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	daily := Recurrence{Frequency: StandingOrderDaily, StartAt: start}
+	assert.Equal(t, start, daily.OccurrenceAfter(start.Add(-time.Hour)))
+	assert.Equal(t, start.AddDate(0, 0, 1), daily.OccurrenceAfter(start))
+	assert.Equal(t, time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), daily.OccurrenceAfter(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)))
+
+	weekly := Recurrence{Frequency: StandingOrderWeekly, StartAt: start}
+	assert.Equal(t, start.AddDate(0, 0, 7), weekly.OccurrenceAfter(start))
+	assert.Equal(t, start.AddDate(0, 0, 70), weekly.OccurrenceAfter(start.AddDate(0, 0, 64)))
+
+	// Monthly occurrences stay on the 31st where the month has one
+	monthly := Recurrence{Frequency: StandingOrderMonthly, StartAt: start}
+	next := start
+	var days []int
+	for i := 0; i < 4; i++ {
+		next = monthly.OccurrenceAfter(next)
+		days = append(days, next.Day())
+	}
+	assert.Equal(t, []int{29, 31, 30, 31}, days)
+	assert.Equal(t, time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC), monthly.OccurrenceAfter(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestRecurrence_Ended(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	capped := Recurrence{Frequency: StandingOrderDaily, StartAt: start, MaxOccurrences: 3}
+	assert.False(t, capped.Ended(2, start.AddDate(0, 0, 2)))
+	assert.True(t, capped.Ended(3, start.AddDate(0, 0, 3)))
+
+	// The end date is inclusive
+	bounded := Recurrence{Frequency: StandingOrderWeekly, StartAt: start, EndAt: start.AddDate(0, 0, 14)}
+	assert.False(t, bounded.Ended(2, start.AddDate(0, 0, 14)))
+	assert.True(t, bounded.Ended(3, start.AddDate(0, 0, 21)))
+
+	open := Recurrence{Frequency: StandingOrderMonthly, StartAt: start}
+	assert.False(t, open.Ended(1000, start.AddDate(100, 0, 0)))
+}
+
+func TestRecurrence_Validate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, Recurrence{Frequency: StandingOrderDaily, StartAt: start}.Validate())
+	assert.NoError(t, Recurrence{Frequency: StandingOrderDaily, StartAt: start, EndAt: start}.Validate())
+
+	for _, invalid := range []Recurrence{
+		{Frequency: "hourly", StartAt: start},
+		{Frequency: StandingOrderDaily},
+		{Frequency: StandingOrderDaily, StartAt: start, EndAt: start.Add(-time.Second)},
+		{Frequency: StandingOrderDaily, StartAt: start, MaxOccurrences: -1},
+	} {
+		assert.ErrorIs(t, invalid.Validate(), errors.ErrValidationFailed, "%+v", invalid)
+	}
+}
+
+func TestStandingOrder_Recurrence(t *testing.T) {
+	order := &StandingOrder{Frequency: StandingOrderWeekly, StartAt: "2024-01-01T09:00:00+08:00", EndAt: "2024-06-30T00:00:00Z", MaxOccurrences: 10}
+	recurrence, err := order.Recurrence()
+	require.NoError(t, err)
+	assert.True(t, recurrence.StartAt.Equal(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)))
+	assert.True(t, recurrence.EndAt.Equal(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 10, recurrence.MaxOccurrences)
+
+	order.StartAt = "soon"
+	_, err = order.Recurrence()
+	assert.Error(t, err)
+}
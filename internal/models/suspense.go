@@ -0,0 +1,66 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// SuspenseStatus is the lifecycle status of a suspense item
+type SuspenseStatus string
+
+const (
+	// SuspenseStatusOpen means the credit is held on the suspense account awaiting an operator
+	SuspenseStatusOpen SuspenseStatus = "open"
+	// SuspenseStatusReapplied means the credit was moved on to the intended destination
+	SuspenseStatusReapplied SuspenseStatus = "reapplied"
+	// SuspenseStatusReturned means the credit was sent back to the source account
+	SuspenseStatusReturned SuspenseStatus = "returned"
+)
+
+// IsValid checks if the status is one of the supported statuses
+func (s SuspenseStatus) IsValid() bool {
+	return s == SuspenseStatusOpen || s == SuspenseStatusReapplied || s == SuspenseStatusReturned
+}
+
+// Suspense item audit actions
+const (
+	SuspenseActionSuspended = "suspended"
+	SuspenseActionReapplied = "reapplied"
+	SuspenseActionReturned  = "returned"
+)
+
+// SuspenseActorSystem is the actor recorded for credits moved to suspense during settlement
+const SuspenseActorSystem = "system"
+
+// MaxSuspenseActorLength is the longest operator identifier that can be recorded
+const MaxSuspenseActorLength = 128
+
+// SuspenseItem is a credit that could not be applied to its destination at settlement time and
+// is held on the suspense account until an operator re-applies or returns it
+type SuspenseItem struct {
+	ID                      int64            `json:"id"`
+	TransactionID           int64            `json:"transaction_id"`
+	SourceAccountID         int64            `json:"source_account_id"`
+	DestinationAccountID    int64            `json:"destination_account_id"`
+	Amount                  decimal.Decimal  `json:"amount"`
+	Reason                  string           `json:"reason"`
+	Status                  SuspenseStatus   `json:"status"`
+	ResolutionTransactionID int64            `json:"resolution_transaction_id,omitempty"`
+	ResolvedBy              string           `json:"resolved_by,omitempty"`
+	ResolvedAt              string           `json:"resolved_at,omitempty"`
+	CreatedAt               string           `json:"created_at"`
+	Events                  []*SuspenseEvent `json:"events,omitempty"`
+}
+
+// IsOpen checks if the item still awaits an operator decision
+func (i *SuspenseItem) IsOpen() bool {
+	return i.Status == SuspenseStatusOpen
+}
+
+// SuspenseEvent is an audit record of an action taken on a suspense item
+type SuspenseEvent struct {
+	ID            int64  `json:"id"`
+	ItemID        int64  `json:"item_id"`
+	Action        string `json:"action"`
+	Actor         string `json:"actor"`
+	Note          string `json:"note,omitempty"`
+	TransactionID int64  `json:"transaction_id,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// MaxTenantIDLength is the longest tenant identifier that can be stored
+const MaxTenantIDLength = 64
+
+// Tenant features that can be switched per tenant. A feature a tenant doesn't set keeps the
+// deployment-wide behavior.
+const (
+	// FeatureBlockDormantOutbound rejects transfers from dormant accounts of the tenant
+	FeatureBlockDormantOutbound = "block_dormant_outbound"
+	// FeatureReversals allows the transfers of the tenant to be reversed
+	FeatureReversals = "reversals"
+)
+
+// Tenant quotas, capped by TenantSettings.MaxAccounts and MaxDailyTransactions
+const (
+	// QuotaAccounts is the number of accounts assigned to the tenant
+	QuotaAccounts = "accounts"
+	// QuotaDailyTransactions is the number of transfers the tenant's accounts sent this business day
+	QuotaDailyTransactions = "daily_transactions"
+)
+
+// DefaultQuotaWarnPercent is the share of a quota from which its usage is warned about, for
+// tenants that don't set one
+const DefaultQuotaWarnPercent = 80
+
+// QuotaLevel is how close the usage of a quota is to its cap
+type QuotaLevel string
+
+const (
+	// QuotaLevelOK means the usage is below the warning threshold, or the quota isn't capped
+	QuotaLevelOK QuotaLevel = "ok"
+	// QuotaLevelWarning means the usage reached the warning threshold but not the cap
+	QuotaLevelWarning QuotaLevel = "warning"
+	// QuotaLevelFull means the usage reached the cap: any more is rejected
+	QuotaLevelFull QuotaLevel = "full"
+)
+
+// QuotaUsage is how much of a quota a tenant uses
+type QuotaUsage struct {
+	Used  int64      `json:"used"`
+	Limit *int64     `json:"limit,omitempty"`
+	Level QuotaLevel `json:"level"`
+}
+
+// TenantUsage is the usage of the quotas of a tenant on a business day, as of SnapshotAt
+type TenantUsage struct {
+	TenantID          string     `json:"tenant_id"`
+	BusinessDate      string     `json:"business_date"`
+	SnapshotAt        string     `json:"snapshot_at"`
+	Accounts          QuotaUsage `json:"accounts"`
+	DailyTransactions QuotaUsage `json:"daily_transactions"`
+}
+
+// tenantFeatures is the set of features a tenant can switch
+var tenantFeatures = map[string]bool{
+	FeatureBlockDormantOutbound: true,
+	FeatureReversals:            true,
+}
+
+// ValidateTenantID checks a tenant identifier is a short lowercase identifier
+func ValidateTenantID(tenantID string) error {
+	if tenantID == "" || len(tenantID) > MaxTenantIDLength {
+		return fmt.Errorf("%w: tenant id must be 1-%d characters", errors.ErrValidationFailed, MaxTenantIDLength)
+	}
+	for _, c := range tenantID {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' {
+			return fmt.Errorf("%w: tenant id may only contain a-z, 0-9, '_' and '-'", errors.ErrValidationFailed)
+		}
+	}
+	return nil
+}
+
+// TenantSettings overrides the behavior of the service for the accounts of one tenant, e.g. an
+// internal business unit. Unset settings keep the deployment-wide behavior.
+type TenantSettings struct {
+	TenantID string `json:"tenant_id"`
+	// MaxTransferAmount caps the amount of a single transfer from the tenant's accounts
+	MaxTransferAmount *decimal.Decimal `json:"max_transfer_amount,omitempty"`
+	// AllowedCurrencies restricts the currencies the tenant's accounts can transfer in; any if empty
+	AllowedCurrencies []string `json:"allowed_currencies"`
+	// Features switches features on or off for the tenant
+	Features map[string]bool `json:"features"`
+	// MaxAccounts caps the number of accounts assigned to the tenant
+	MaxAccounts *int64 `json:"max_accounts,omitempty"`
+	// MaxDailyTransactions caps the number of transfers the tenant's accounts send per business day
+	MaxDailyTransactions *int64 `json:"max_daily_transactions,omitempty"`
+	// QuotaWarnPercent is the share of a quota from which its usage is warned about;
+	// DefaultQuotaWarnPercent if unset
+	QuotaWarnPercent *int   `json:"quota_warn_percent,omitempty"`
+	UpdatedAt        string `json:"updated_at,omitempty"`
+}
+
+// Validate checks the settings can be stored
+func (s *TenantSettings) Validate() error {
+	if err := ValidateTenantID(s.TenantID); err != nil {
+		return err
+	}
+	if s.MaxTransferAmount != nil {
+		if !s.MaxTransferAmount.IsPositive() {
+			return fmt.Errorf("%w: max_transfer_amount must be positive", errors.ErrValidationFailed)
+		}
+		if err := ValidateAmountPrecision(*s.MaxTransferAmount); err != nil {
+			return err
+		}
+	}
+	for _, currency := range s.AllowedCurrencies {
+		if err := ValidateCurrency(currency); err != nil {
+			return err
+		}
+	}
+	if s.MaxAccounts != nil && *s.MaxAccounts <= 0 {
+		return fmt.Errorf("%w: max_accounts must be positive", errors.ErrValidationFailed)
+	}
+	if s.MaxDailyTransactions != nil && *s.MaxDailyTransactions <= 0 {
+		return fmt.Errorf("%w: max_daily_transactions must be positive", errors.ErrValidationFailed)
+	}
+	if s.QuotaWarnPercent != nil && (*s.QuotaWarnPercent < 1 || *s.QuotaWarnPercent > 100) {
+		return fmt.Errorf("%w: quota_warn_percent must be between 1 and 100", errors.ErrValidationFailed)
+	}
+	for feature := range s.Features {
+		if !tenantFeatures[feature] {
+			return fmt.Errorf("%w: unknown feature %q (available: %s)", errors.ErrValidationFailed, feature, TenantFeatures())
+		}
+	}
+	return nil
+}
+
+// TenantFeatures returns the features a tenant can switch, in alphabetical order
+func TenantFeatures() []string {
+	features := make([]string, 0, len(tenantFeatures))
+	for feature := range tenantFeatures {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Feature reports whether the tenant switches feature on or off; ok is false if it doesn't set it
+func (s *TenantSettings) Feature(feature string) (enabled, ok bool) {
+	if s == nil {
+		return false, false
+	}
+	enabled, ok = s.Features[feature]
+	return enabled, ok
+}
+
+// AllowsCurrency checks the tenant's accounts can transfer in currency. Accounts without a
+// currency predate currencies and are not restricted.
+func (s *TenantSettings) AllowsCurrency(currency string) bool {
+	if s == nil || len(s.AllowedCurrencies) == 0 || currency == "" {
+		return true
+	}
+	for _, allowed := range s.AllowedCurrencies {
+		if allowed == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransferAmount checks a transfer of amount from an account of the tenant is within its limit
+func (s *TenantSettings) CheckTransferAmount(accountID int64, amount decimal.Decimal) error {
+	if s == nil || s.MaxTransferAmount == nil || amount.LessThanOrEqual(*s.MaxTransferAmount) {
+		return nil
+	}
+	return errors.NewTransferLimitError(accountID, amount, *s.MaxTransferAmount)
+}
+
+// quotaLimit returns the cap of quota, or nil if the tenant doesn't cap it
+func (s *TenantSettings) quotaLimit(quota string) *int64 {
+	if s == nil {
+		return nil
+	}
+	switch quota {
+	case QuotaAccounts:
+		return s.MaxAccounts
+	case QuotaDailyTransactions:
+		return s.MaxDailyTransactions
+	}
+	return nil
+}
+
+// QuotaUsage returns the usage of quota by the tenant when used of it is taken
+func (s *TenantSettings) QuotaUsage(quota string, used int64) QuotaUsage {
+	usage := QuotaUsage{Used: used, Limit: s.quotaLimit(quota), Level: QuotaLevelOK}
+	if usage.Limit == nil {
+		return usage
+	}
+	warnPercent := DefaultQuotaWarnPercent
+	if s.QuotaWarnPercent != nil {
+		warnPercent = *s.QuotaWarnPercent
+	}
+	switch {
+	case used >= *usage.Limit:
+		usage.Level = QuotaLevelFull
+	case used*100 >= *usage.Limit*int64(warnPercent):
+		usage.Level = QuotaLevelWarning
+	}
+	return usage
+}
+
+// CheckQuota checks one more of quota, for accountID, fits the cap of the tenant when used of it
+// is already taken, and returns the usage once it is taken. If it doesn't fit, the current usage
+// is returned with ErrTenantQuotaExceeded.
+func (s *TenantSettings) CheckQuota(quota string, accountID, used int64) (QuotaUsage, error) {
+	if limit := s.quotaLimit(quota); limit != nil && used >= *limit {
+		return s.QuotaUsage(quota, used), errors.NewTenantQuotaError(accountID, quota, *limit)
+	}
+	return s.QuotaUsage(quota, used+1), nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantSettings_Validate(t *testing.T) {
+	limit := decimal.NewFromInt(1000)
+	zero := decimal.Zero
+	quota, noQuota := int64(10), int64(0)
+	percent, overPercent := 90, 101
+	tests := []struct {
+		name     string
+		settings TenantSettings
+		valid    bool
+	}{
+		{name: "empty overrides", settings: TenantSettings{TenantID: "retail"}, valid: true},
+		{name: "all settings", settings: TenantSettings{TenantID: "treasury-sg", MaxTransferAmount: &limit, AllowedCurrencies: []string{"SGD", "USD"}, Features: map[string]bool{FeatureReversals: false}}, valid: true},
+		{name: "missing tenant", settings: TenantSettings{}},
+		{name: "uppercase tenant", settings: TenantSettings{TenantID: "Retail"}},
+		{name: "non-positive limit", settings: TenantSettings{TenantID: "retail", MaxTransferAmount: &zero}},
+		{name: "unknown currency", settings: TenantSettings{TenantID: "retail", AllowedCurrencies: []string{"XXX"}}},
+		{name: "unknown feature", settings: TenantSettings{TenantID: "retail", Features: map[string]bool{"teleport": true}}},
+		{name: "quotas", settings: TenantSettings{TenantID: "retail", MaxAccounts: &quota, MaxDailyTransactions: &quota, QuotaWarnPercent: &percent}, valid: true},
+		{name: "non-positive quota", settings: TenantSettings{TenantID: "retail", MaxAccounts: &noQuota}},
+		{name: "warn percent above 100", settings: TenantSettings{TenantID: "retail", QuotaWarnPercent: &overPercent}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errors.ErrValidationFailed)
+			}
+		})
+	}
+}
+
+func TestTenantSettings_Policy(t *testing.T) {
+	limit := decimal.NewFromInt(100)
+	settings := &TenantSettings{TenantID: "retail", MaxTransferAmount: &limit, AllowedCurrencies: []string{"USD"}, Features: map[string]bool{FeatureBlockDormantOutbound: false}}
+
+	assert.NoError(t, settings.CheckTransferAmount(1, decimal.NewFromInt(100)))
+	assert.ErrorIs(t, settings.CheckTransferAmount(1, decimal.RequireFromString("100.01")), errors.ErrTransferLimitExceeded)
+	assert.True(t, settings.AllowsCurrency("USD"))
+	assert.True(t, settings.AllowsCurrency(""))
+	assert.False(t, settings.AllowsCurrency("EUR"))
+
+	enabled, ok := settings.Feature(FeatureBlockDormantOutbound)
+	assert.True(t, ok)
+	assert.False(t, enabled)
+	_, ok = settings.Feature(FeatureReversals)
+	assert.False(t, ok)
+
+	// Accounts without tenant settings are unrestricted
+	var none *TenantSettings
+	assert.NoError(t, none.CheckTransferAmount(1, decimal.NewFromInt(1_000_000)))
+	assert.True(t, none.AllowsCurrency("EUR"))
+	_, ok = none.Feature(FeatureReversals)
+	assert.False(t, ok)
+}
+
+func TestTenantSettings_CheckQuota(t *testing.T) {
+	limit := int64(10)
+	settings := &TenantSettings{TenantID: "retail", MaxDailyTransactions: &limit}
+
+	usage, err := settings.CheckQuota(QuotaDailyTransactions, 1, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaUsage{Used: 7, Limit: &limit, Level: QuotaLevelOK}, usage)
+
+	// From 80% of the cap by default, usage is warned about
+	usage, err = settings.CheckQuota(QuotaDailyTransactions, 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaLevelWarning, usage.Level)
+
+	// The transfer taking the last place fills the quota; the next is rejected
+	usage, err = settings.CheckQuota(QuotaDailyTransactions, 1, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaLevelFull, usage.Level)
+	usage, err = settings.CheckQuota(QuotaDailyTransactions, 1, 10)
+	assert.ErrorIs(t, err, errors.ErrTenantQuotaExceeded)
+	assert.Equal(t, int64(10), usage.Used)
+
+	// The warning threshold can be set per tenant
+	percent := 50
+	settings.QuotaWarnPercent = &percent
+	assert.Equal(t, QuotaLevelWarning, settings.QuotaUsage(QuotaDailyTransactions, 5).Level)
+
+	// Uncapped quotas, and tenants without settings, are never warned about or rejected
+	usage, err = settings.CheckQuota(QuotaAccounts, 1, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaUsage{Used: 1001, Level: QuotaLevelOK}, usage)
+	var none *TenantSettings
+	_, err = none.CheckQuota(QuotaAccounts, 1, 1000)
+	assert.NoError(t, err)
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/shopspring/decimal"
 )
@@ -12,6 +15,14 @@ const (
 	TransactionStatusPending  TransactionStatus = "pending"
 	TransactionStatusComplete TransactionStatus = "complete"
 	TransactionStatusFailed   TransactionStatus = "failed"
+	// TransactionStatusReversed marks a completed transaction offset by a compensating transfer;
+	// its funds moved and stay accounted for, the reversal moves them back
+	TransactionStatusReversed TransactionStatus = "reversed"
+	// TransactionStatusPendingApproval marks a transfer above the approval threshold waiting for a
+	// second caller to approve it; no funds have moved
+	TransactionStatusPendingApproval TransactionStatus = "pending_approval"
+	// TransactionStatusRejected marks a transfer whose approval was refused; no funds moved
+	TransactionStatusRejected TransactionStatus = "rejected"
 )
 
 // Transaction represents a financial transaction in the system
@@ -22,24 +33,135 @@ type Transaction struct {
 	Amount               decimal.Decimal   `json:"amount"`
 	Status               TransactionStatus `json:"status"`
 	CreatedAt            string            `json:"created_at"`
+	ValueDate            string            `json:"value_date"`
+	BusinessDate         string            `json:"business_date,omitempty"`
+	Tags                 []string          `json:"tags,omitempty"`
+	BatchItemKey         string            `json:"batch_item_key,omitempty"`
+	ExternalReference    string            `json:"external_reference,omitempty"`
+	ReversalOf           int64             `json:"reversal_of,omitempty"`
+	// ConvertedAmount is the amount credited in the destination currency of a cross-currency
+	// transfer, converted from Amount at FXRate; both are nil within one currency
+	ConvertedAmount *decimal.Decimal `json:"converted_amount,omitempty"`
+	FXRate          *decimal.Decimal `json:"fx_rate,omitempty"`
+	// FeeOf and FeeRuleID link a fee collected to the fees account to the transfer it was charged
+	// on and the rule that charged it
+	FeeOf     int64 `json:"fee_of,omitempty"`
+	FeeRuleID int64 `json:"fee_rule_id,omitempty"`
+	// SplitID is the split transfer this transaction is a leg of
+	SplitID int64 `json:"split_id,omitempty"`
+	// Fees are the fees charged on this transfer; only set on the transfer as it is made
+	Fees []Fee `json:"fees,omitempty"`
+}
+
+// Limits on transaction tags
+const (
+	MaxTagsPerTransaction = 20
+	MaxTagLength          = 64
+)
+
+// MaxExternalReferenceLength is the longest external reference that can be stored
+const MaxExternalReferenceLength = 128
+
+// ValidateTags checks transaction tags are non-empty, short and few enough to index
+func ValidateTags(tags []string) error {
+	if len(tags) > MaxTagsPerTransaction {
+		return fmt.Errorf("%w: at most %d tags are allowed", errors.ErrValidationFailed, MaxTagsPerTransaction)
+	}
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" || len(tag) > MaxTagLength {
+			return fmt.Errorf("%w: tags must be 1-%d characters", errors.ErrValidationFailed, MaxTagLength)
+		}
+	}
+	return nil
+}
+
+// TimeAxis selects which of a transaction's two timestamps a query is evaluated on
+type TimeAxis string
+
+const (
+	// TimeAxisRecorded uses created_at, the time the transaction was recorded
+	TimeAxisRecorded TimeAxis = "recorded"
+	// TimeAxisEffective uses value_date, the time the transaction takes economic effect
+	TimeAxisEffective TimeAxis = "effective"
+)
+
+// IsValid checks if the time axis is one of the supported axes
+func (a TimeAxis) IsValid() bool {
+	return a == TimeAxisRecorded || a == TimeAxisEffective
+}
+
+// BusinessDaySummary is the completed transaction count and volume booked on one business date
+type BusinessDaySummary struct {
+	BusinessDate     string          `json:"business_date"`
+	TransactionCount int64           `json:"transaction_count"`
+	Volume           decimal.Decimal `json:"volume"`
+}
+
+// Statement is an account's transactions within a period together with the opening and
+// closing balances, evaluated on a single time axis
+type Statement struct {
+	AccountID      int64           `json:"account_id"`
+	Axis           TimeAxis        `json:"axis"`
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	OpeningBalance decimal.Decimal `json:"opening_balance"`
+	ClosingBalance decimal.Decimal `json:"closing_balance"`
+	Transactions   []*Transaction  `json:"transactions"`
 }
 
 // Validate checks if the transaction is valid
 func (t *Transaction) Validate() error {
 	if t.Amount.LessThanOrEqual(decimal.Zero) {
-		return errors.ErrInvalidAmount
+		return errors.NewInvalidAmountError(t.Amount)
+	}
+	if err := ValidateAmountPrecision(t.Amount); err != nil {
+		return err
 	}
 	if t.SourceAccountID == t.DestinationAccountID {
-		return errors.ErrSameAccount
+		return errors.NewSameAccountError(t.SourceAccountID)
+	}
+	if err := ValidateTags(t.Tags); err != nil {
+		return err
+	}
+	if len(t.ExternalReference) > MaxExternalReferenceLength {
+		return fmt.Errorf("%w: external reference exceeds %d characters", errors.ErrValidationFailed, MaxExternalReferenceLength)
+	}
+	if (t.ConvertedAmount == nil) != (t.FXRate == nil) {
+		return fmt.Errorf("%w: a converted amount needs the rate it was converted at", errors.ErrValidationFailed)
+	}
+	if t.ConvertedAmount != nil {
+		if !t.ConvertedAmount.IsPositive() {
+			return errors.NewInvalidAmountError(*t.ConvertedAmount)
+		}
+		if err := ValidateAmountPrecision(*t.ConvertedAmount); err != nil {
+			return err
+		}
+		if !t.FXRate.IsPositive() {
+			return fmt.Errorf("%w: exchange rate must be positive", errors.ErrValidationFailed)
+		}
 	}
 	return nil
 }
 
+// CreditAmount returns the amount credited to the destination account: the converted amount of a
+// cross-currency transfer, otherwise the amount
+func (t *Transaction) CreditAmount() decimal.Decimal {
+	if t.ConvertedAmount != nil {
+		return *t.ConvertedAmount
+	}
+	return t.Amount
+}
+
 // IsComplete checks if the transaction is complete
 func (t *Transaction) IsComplete() bool {
 	return t.Status == TransactionStatusComplete
 }
 
+// IsSettled checks if the transaction moved funds: it completed, possibly to be reversed later
+func (t *Transaction) IsSettled() bool {
+	return t.Status == TransactionStatusComplete || t.Status == TransactionStatusReversed
+}
+
 // IsFailed checks if the transaction failed
 func (t *Transaction) IsFailed() bool {
 	return t.Status == TransactionStatusFailed
@@ -49,3 +171,8 @@ func (t *Transaction) IsFailed() bool {
 func (t *Transaction) IsPending() bool {
 	return t.Status == TransactionStatusPending
 }
+
+// IsPendingApproval checks if the transaction is waiting for approval
+func (t *Transaction) IsPendingApproval() bool {
+	return t.Status == TransactionStatusPendingApproval
+}
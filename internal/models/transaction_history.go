@@ -0,0 +1,12 @@
+package models
+
+// TransactionStatusChange is an entry of a transaction's status history. FromStatus is empty for
+// a transaction that was recorded with its first status.
+type TransactionStatusChange struct {
+	ID            int64             `json:"id"`
+	TransactionID int64             `json:"transaction_id"`
+	FromStatus    TransactionStatus `json:"from_status,omitempty"`
+	ToStatus      TransactionStatus `json:"to_status"`
+	Actor         string            `json:"actor"`
+	ChangedAt     string            `json:"changed_at"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+	"github.com/shopspring/decimal"
+)
+
+// TransferJobStatus is the lifecycle status of an asynchronously submitted transfer
+type TransferJobStatus string
+
+const (
+	// TransferJobStatusQueued means the transfer is waiting for a worker, or for a retry
+	TransferJobStatusQueued TransferJobStatus = "queued"
+	// TransferJobStatusCompleted means the transfer was made; TransactionID holds it
+	TransferJobStatusCompleted TransferJobStatus = "completed"
+	// TransferJobStatusFailed means the transfer was rejected or every attempt failed; ErrorCode
+	// and LastError hold the reason
+	TransferJobStatusFailed TransferJobStatus = "failed"
+	// TransferJobStatusCancelled means an operator cancelled the job before it was made
+	TransferJobStatusCancelled TransferJobStatus = "cancelled"
+)
+
+// IsValid checks if s is a known transfer job status
+func (s TransferJobStatus) IsValid() bool {
+	return s == TransferJobStatusQueued || s == TransferJobStatusCompleted || s == TransferJobStatusFailed || s == TransferJobStatusCancelled
+}
+
+// Transfer job history actions
+const (
+	TransferJobActionSubmitted   = "submitted"
+	TransferJobActionCompleted   = "completed"
+	TransferJobActionFailed      = "failed"
+	TransferJobActionCancelled   = "cancelled"
+	TransferJobActionRequeued    = "requeued"
+	TransferJobActionPrioritized = "prioritized"
+)
+
+// TransferJobActorSystem is the actor recorded for what workers do to a job
+const TransferJobActorSystem = "system"
+
+// MaxTransferJobReasonLength is the longest reason an operator can give for acting on a job
+const MaxTransferJobReasonLength = 500
+
+// TransferJob is a transfer submitted now and made by a background worker. A transfer the rules
+// reject fails the job; other failed attempts are retried at NextAttemptAt until the attempts run out.
+type TransferJob struct {
+	ID                   int64               `json:"id"`
+	SourceAccountID      int64               `json:"source_account_id"`
+	DestinationAccountID int64               `json:"destination_account_id"`
+	Amount               decimal.Decimal     `json:"amount"`
+	Status               TransferJobStatus   `json:"status"`
+	Priority             priority.Class      `json:"priority"`
+	Attempts             int                 `json:"attempts"`
+	NextAttemptAt        string              `json:"next_attempt_at,omitempty"`
+	TransactionID        int64               `json:"transaction_id,omitempty"`
+	ErrorCode            string              `json:"error_code,omitempty"`
+	LastError            string              `json:"last_error,omitempty"`
+	SubmittedBy          string              `json:"submitted_by"`
+	IdempotencyKey       string              `json:"idempotency_key,omitempty"`
+	Owner                string              `json:"-"` // who the idempotency key belongs to (see auth.Owner)
+	CompletedAt          string              `json:"completed_at,omitempty"`
+	CreatedAt            string              `json:"created_at"`
+	History              []*TransferJobEvent `json:"history,omitempty"`
+}
+
+// TransferJobEvent is a status change of a transfer job, or a change of its priority, with who
+// made it and why
+type TransferJobEvent struct {
+	ID         int64             `json:"id"`
+	JobID      int64             `json:"job_id"`
+	Action     string            `json:"action"`
+	FromStatus TransferJobStatus `json:"from_status,omitempty"`
+	ToStatus   TransferJobStatus `json:"to_status"`
+	Priority   priority.Class    `json:"priority"`
+	Actor      string            `json:"actor"`
+	Reason     string            `json:"reason,omitempty"`
+	CreatedAt  string            `json:"created_at"`
+}
+
+// IsQueued checks if the job is still waiting to be made
+func (j *TransferJob) IsQueued() bool {
+	return j.Status == TransferJobStatusQueued
+}
+
+// SameTransfer checks if the job moves the given amount between the same accounts, i.e. a
+// resubmission under the job's idempotency key asks for the same transfer
+func (j *TransferJob) SameTransfer(sourceID, destinationID int64, amount decimal.Decimal) bool {
+	return j.SourceAccountID == sourceID && j.DestinationAccountID == destinationID && j.Amount.Equal(amount)
+}
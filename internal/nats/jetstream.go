@@ -0,0 +1,200 @@
+// Package nats publishes events to NATS JetStream through the nats.go client. Events are
+// published to the subject "<prefix>.<event type>" and captured by a stream the publisher
+// provisions at startup, together with the durable consumers configured for downstream services.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Config configures a publisher
+type Config struct {
+	// URL is the server URL, "nats://[user:pass@]host[:port]" or "tls://...", or several separated
+	// by commas
+	URL string
+	// Name identifies the connection in the server's monitoring
+	Name string
+	// Stream is the JetStream stream capturing the events
+	Stream string
+	// SubjectPrefix is prepended to event types to form their subject
+	SubjectPrefix string
+	// Consumers are the durable pull consumers provisioned on the stream
+	Consumers []string
+	// Timeout bounds connecting and each request, including the wait for the stream's ack
+	Timeout time.Duration
+}
+
+// Publisher publishes events to a JetStream stream. It keeps one connection, which nats.go
+// reconnects after a failure and the next call replaces once nats.go gave up on it, and is safe
+// for concurrent use.
+type Publisher struct {
+	cfg Config
+
+	mu   sync.Mutex
+	conn *nats.Conn
+	js   jetstream.JetStream
+	log  *slog.Logger
+}
+
+// NewPublisher creates a publisher; the connection is opened on first use
+func NewPublisher(cfg Config) *Publisher {
+	return &Publisher{cfg: cfg, log: logger.Default()}
+}
+
+// FromConfig creates the publisher configured by the NATS_* settings
+func FromConfig(cfg *config.Config) (*Publisher, error) {
+	if err := ValidateNames(cfg.NATSStream, cfg.NATSSubjectPrefix, cfg.NATSConsumers); err != nil {
+		return nil, err
+	}
+	return NewPublisher(Config{
+		URL:           cfg.NATSURL,
+		Name:          cfg.NATSClientName,
+		Stream:        cfg.NATSStream,
+		SubjectPrefix: cfg.NATSSubjectPrefix,
+		Consumers:     cfg.NATSConsumers,
+		Timeout:       time.Duration(cfg.NATSTimeout) * time.Millisecond,
+	}), nil
+}
+
+// ValidateNames checks the stream, subject prefix and consumer names are valid in JetStream
+func ValidateNames(stream, subjectPrefix string, consumers []string) error {
+	if !validName(stream) {
+		return fmt.Errorf("%w: invalid NATS stream name %q", domainErrors.ErrValidationFailed, stream)
+	}
+	for _, token := range strings.Split(subjectPrefix, ".") {
+		if token == "" || strings.ContainsAny(token, "*> \t\r\n") {
+			return fmt.Errorf("%w: invalid NATS subject prefix %q", domainErrors.ErrValidationFailed, subjectPrefix)
+		}
+	}
+	for _, consumer := range consumers {
+		if !validName(consumer) {
+			return fmt.Errorf("%w: invalid NATS consumer name %q", domainErrors.ErrValidationFailed, consumer)
+		}
+	}
+	return nil
+}
+
+// validName reports whether name can name a stream or consumer: it ends up in API subjects,
+// so it can't hold subject separators or wildcards
+func validName(name string) bool {
+	return name != "" && len(name) <= 255 && !strings.ContainsAny(name, ".*>/\\ \t\r\n")
+}
+
+// Subject returns the subject event is published to
+func (p *Publisher) Subject(event string) string {
+	return p.cfg.SubjectPrefix + "." + event
+}
+
+// Provision creates the stream capturing every event subject unless it exists, and the durable
+// consumers on it. An existing stream is left as configured by its operator.
+func (p *Publisher) Provision(ctx context.Context) error {
+	js, err := p.jetStream()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	_, err = js.Stream(ctx, p.cfg.Stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+			Name:      p.cfg.Stream,
+			Subjects:  []string{p.cfg.SubjectPrefix + ".>"},
+			Storage:   jetstream.FileStorage,
+			Retention: jetstream.LimitsPolicy,
+		})
+		if err == nil {
+			p.log.Info("Created NATS stream", "stream", p.cfg.Stream, "subject_prefix", p.cfg.SubjectPrefix)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("nats: failed to provision stream %s: %w", p.cfg.Stream, err)
+	}
+
+	for _, durable := range p.cfg.Consumers {
+		_, err := js.CreateOrUpdateConsumer(ctx, p.cfg.Stream, jetstream.ConsumerConfig{
+			Durable:       durable,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+		})
+		if err != nil {
+			return fmt.Errorf("nats: failed to provision consumer %s: %w", durable, err)
+		}
+	}
+	p.log.Info("Provisioned NATS stream with consumers", "stream", p.cfg.Stream, "consumers", p.cfg.Consumers)
+	return nil
+}
+
+// Publish publishes event to its subject and waits for the stream to persist it
+func (p *Publisher) Publish(ctx context.Context, event string, payload interface{}) error {
+	data, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to encode %s event: %w", event, err)
+		}
+	}
+
+	js, err := p.jetStream()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	subject := p.Subject(event)
+	ack, err := js.Publish(ctx, subject, data)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrNoStreamResponse) {
+			return fmt.Errorf("nats: no stream captures %s: %w", subject, err)
+		}
+		return fmt.Errorf("nats: failed to publish %s event: %w", event, err)
+	}
+	p.log.Debug("Published event to stream", "event", event, "stream", ack.Stream, "seq", ack.Sequence)
+	return nil
+}
+
+// Close closes the connection
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.js = nil, nil
+	}
+	return nil
+}
+
+// jetStream returns the JetStream context of the connection, connecting if there is none or
+// nats.go closed the last one after running out of reconnect attempts
+func (p *Publisher) jetStream() (jetstream.JetStream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil && !p.conn.IsClosed() {
+		return p.js, nil
+	}
+	conn, err := nats.Connect(p.cfg.URL, nats.Name(p.cfg.Name), nats.Timeout(p.cfg.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to connect: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: %w", err)
+	}
+	p.conn, p.js = conn, js
+	return js, nil
+}
@@ -0,0 +1,206 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer is a NATS server with JetStream holding at most one stream
+type fakeServer struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu        sync.Mutex
+	connects  []map[string]interface{}
+	stream    map[string]interface{} // config of the created stream, nil until created
+	consumers []string
+	published map[string][]string // payloads by subject
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeServer{t: t, listener: listener, published: make(map[string][]string)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"headers\":true,\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(conn)
+	pinged := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "CONNECT":
+			var connect map[string]interface{}
+			require.NoError(s.t, json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &connect))
+			s.mu.Lock()
+			s.connects = append(s.connects, connect)
+			s.mu.Unlock()
+		case "PING":
+			fmt.Fprintf(conn, "PONG\r\n")
+		case "PUB":
+			size, _ := strconv.Atoi(fields[3])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			if !pinged {
+				// The client answers server PINGs while waiting for replies
+				fmt.Fprintf(conn, "PING\r\n")
+				pinged = true
+			}
+			reply, ok := s.handle(fields[1], payload[:size])
+			if !ok {
+				fmt.Fprintf(conn, "HMSG %s 1 16 16\r\nNATS/1.0 503\r\n\r\n\r\n", fields[2])
+				continue
+			}
+			fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(reply), reply)
+		}
+	}
+}
+
+// handle answers a request to subject, or returns false if nothing listens to it
+func (s *fakeServer) handle(subject string, payload []byte) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(subject, "$JS.API.STREAM.INFO."):
+		if s.stream == nil {
+			return `{"error":{"code":404,"err_code":10059,"description":"stream not found"}}`, true
+		}
+		return `{"config":{}}`, true
+	case strings.HasPrefix(subject, "$JS.API.STREAM.CREATE."):
+		require.NoError(s.t, json.Unmarshal(payload, &s.stream))
+		return `{"config":{}}`, true
+	case strings.HasPrefix(subject, "$JS.API.CONSUMER.CREATE."):
+		s.consumers = append(s.consumers, subject[strings.LastIndex(subject, ".")+1:])
+		return `{"name":"consumer"}`, true
+	case s.stream != nil && strings.HasPrefix(subject, "transfers."):
+		s.published[subject] = append(s.published[subject], string(payload))
+		return fmt.Sprintf(`{"stream":"TRANSFERS","seq":%d}`, len(s.published[subject])), true
+	}
+	return "", false
+}
+
+func testConfig(server *fakeServer) *config.Config {
+	return &config.Config{
+		NATSURL:           server.url(),
+		NATSClientName:    "test",
+		NATSStream:        "TRANSFERS",
+		NATSSubjectPrefix: "transfers",
+		NATSConsumers:     []string{"ledger", "notifications"},
+		NATSTimeout:       5000,
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	server := newFakeServer(t)
+	publisher, err := FromConfig(testConfig(server))
+	require.NoError(t, err)
+	defer publisher.Close()
+	ctx := context.Background()
+
+	// Nothing captures the subjects before the stream is provisioned
+	err = publisher.Publish(ctx, models.EventTransactionCompleted, json.RawMessage(`{"id": 1}`))
+	assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+
+	require.NoError(t, publisher.Provision(ctx))
+	assert.Equal(t, "TRANSFERS", server.stream["name"])
+	assert.Equal(t, []interface{}{"transfers.>"}, server.stream["subjects"])
+	assert.Equal(t, []string{"ledger", "notifications"}, server.consumers)
+
+	// Provisioning again leaves the existing stream alone
+	server.stream["name"] = "EXISTING"
+	require.NoError(t, publisher.Provision(ctx))
+	assert.Equal(t, "EXISTING", server.stream["name"])
+
+	require.NoError(t, publisher.Publish(ctx, models.EventTransactionCompleted, json.RawMessage(`{"id": 1}`)))
+	require.NoError(t, publisher.Publish(ctx, models.EventAccountCreated, &models.AccountCreated{AccountID: 7}))
+	assert.Equal(t, map[string][]string{
+		"transfers.transaction.completed": {`{"id": 1}`},
+		"transfers.account.created":       {`{"account_id":7,"initial_balance":"0","created_at":""}`},
+	}, server.published)
+
+	// One connection, which asked for no-responder statuses
+	require.Len(t, server.connects, 1)
+	assert.Equal(t, true, server.connects[0]["no_responders"])
+	assert.Equal(t, "test", server.connects[0]["name"])
+}
+
+func TestPublisher_Reconnects(t *testing.T) {
+	server := newFakeServer(t)
+	publisher, err := FromConfig(testConfig(server))
+	require.NoError(t, err)
+	defer publisher.Close()
+	ctx := context.Background()
+	require.NoError(t, publisher.Provision(ctx))
+
+	// A connection nats.go gave up on is replaced by the next call
+	publisher.conn.Close()
+	require.NoError(t, publisher.Publish(ctx, models.EventTransactionCompleted, json.RawMessage(`{"id": 2}`)))
+	assert.Len(t, server.connects, 2)
+}
+
+func TestPublisher_Timeout(t *testing.T) {
+	// A server that greets but never answers
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "INFO {\"headers\":true}\r\n")
+		io.Copy(io.Discard, conn)
+	}()
+
+	publisher := NewPublisher(Config{URL: "nats://" + listener.Addr().String(), Stream: "TRANSFERS", SubjectPrefix: "transfers", Timeout: 50 * time.Millisecond})
+	err = publisher.Publish(context.Background(), models.EventTransactionCompleted, json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
+
+func TestValidateNames(t *testing.T) {
+	assert.NoError(t, ValidateNames("TRANSFERS", "acme.transfers", []string{"ledger"}))
+	assert.ErrorIs(t, ValidateNames("TRANS.FERS", "transfers", nil), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ValidateNames("TRANSFERS", "transfers.>", nil), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ValidateNames("TRANSFERS", "transfers..x", nil), errors.ErrValidationFailed)
+	assert.ErrorIs(t, ValidateNames("TRANSFERS", "transfers", []string{"led ger"}), errors.ErrValidationFailed)
+}
@@ -0,0 +1,198 @@
+// Package outbox relays the events recorded in the outbox table to a sink, such as the webhook
+// dispatcher. Events are recorded by the database transaction that raised them, so an event is
+// relayed only if its change committed, and a message is marked published only once the sink
+// accepted it, so none is lost: every committed event is published at least once.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// livenessSubsystem is the subsystem the relay beats as
+const livenessSubsystem = "outbox_relay"
+
+// Sink publishes relayed events. The payload is the JSON the event was recorded with.
+type Sink interface {
+	Publish(ctx context.Context, event string, payload interface{}) error
+}
+
+// Stats is a snapshot of the relay counters and the current outbox backlog
+type Stats struct {
+	Runs        uint64 `json:"runs"`
+	Failures    uint64 `json:"failures"`
+	Published   uint64 `json:"published"`
+	LastRunAt   string `json:"last_run_at,omitempty"`
+	LastFailure string `json:"last_failure,omitempty"`
+	Backlog     int64  `json:"backlog"`
+}
+
+// Relay publishes unpublished outbox messages on a fixed interval, oldest first
+type Relay struct {
+	db        *sql.DB
+	outbox    repository.OutboxRepository
+	sink      Sink
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+	liveness  *metrics.Workers
+
+	mu          sync.Mutex
+	runs        uint64
+	failures    uint64
+	published   uint64
+	lastRunAt   time.Time
+	lastFailure string
+	log         *slog.Logger
+}
+
+// NewRelay creates a relay publishing to sink using the outbox settings in cfg
+func NewRelay(db *sql.DB, outbox repository.OutboxRepository, sink Sink, cfg *config.Config) *Relay {
+	return &Relay{
+		db:        db,
+		outbox:    outbox,
+		sink:      sink,
+		interval:  time.Duration(cfg.OutboxPollInterval) * time.Millisecond,
+		batchSize: cfg.OutboxBatchSize,
+		now:       time.Now,
+		log:       logger.Default(),
+	}
+}
+
+// SetClock makes the relay stamp publications with c instead of the wall clock. Call it before Run.
+func (r *Relay) SetClock(c clock.Clock) {
+	r.now = c.Now
+}
+
+// SetLiveness makes the relay beat in workers, as the "outbox_relay" subsystem, after each batch.
+// Call it before Run.
+func (r *Relay) SetLiveness(workers *metrics.Workers) {
+	r.liveness = workers
+}
+
+// Run relays the backlog immediately and then on every interval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	r.log.Info("Outbox relay started", "interval", r.interval, "batch_size", r.batchSize)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	r.liveness.Start(livenessSubsystem, 0, r.interval)
+	defer r.liveness.Stop(livenessSubsystem, 0)
+
+	for {
+		r.Drain(ctx)
+		select {
+		case <-ctx.Done():
+			r.log.Info("Outbox relay stopped")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain relays batches until the outbox is empty or a batch fails
+func (r *Relay) Drain(ctx context.Context) {
+	for {
+		n, err := r.RelayBatch(ctx, r.batchSize)
+		if err != nil {
+			r.log.Error("Outbox relay failed", "err", err)
+			return
+		}
+		if n < r.batchSize {
+			return
+		}
+	}
+}
+
+// RelayBatch publishes up to limit unpublished messages in order and returns the number
+// published. Messages are locked while they are published, so concurrent relays never publish
+// the same message; a single relay keeps the order they were recorded in. The batch stops at the
+// first message the sink refuses, which is retried on the next run. Its signature fits a sweeper
+// task.
+func (r *Relay) RelayBatch(ctx context.Context, limit int) (published int, err error) {
+	defer func() { r.record(published, err) }()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	messages, err := r.outbox.ClaimUnpublishedWithTx(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var sinkErr error
+	for _, message := range messages {
+		if sinkErr = r.sink.Publish(ctx, message.Event, message.Payload); sinkErr != nil {
+			r.log.Warn("Publishing outbox message failed", "message_id", message.ID, "event", message.Event, "err", sinkErr)
+			if err := r.outbox.RecordFailureWithTx(ctx, tx, message.ID, sinkErr.Error()); err != nil {
+				return 0, err
+			}
+			break
+		}
+		if err := r.outbox.MarkPublishedWithTx(ctx, tx, message.ID, r.now()); err != nil {
+			return 0, err
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+	if published > 0 {
+		r.log.Info("Relayed outbox messages", "published", published)
+	}
+	if sinkErr != nil {
+		return published, fmt.Errorf("failed to publish outbox message: %w", sinkErr)
+	}
+	return published, nil
+}
+
+// record updates the counters after a batch
+func (r *Relay) record(published int, err error) {
+	r.liveness.Beat(livenessSubsystem, 0)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.runs++
+	r.published += uint64(published)
+	r.lastRunAt = r.now()
+	if err != nil {
+		r.failures++
+		r.lastFailure = err.Error()
+	}
+}
+
+// Stats returns the relay counters together with the number of messages waiting
+func (r *Relay) Stats(ctx context.Context) (*Stats, error) {
+	backlog, err := r.outbox.CountUnpublished(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := &Stats{
+		Runs:        r.runs,
+		Failures:    r.failures,
+		Published:   r.published,
+		LastFailure: r.lastFailure,
+		Backlog:     backlog,
+	}
+	if !r.lastRunAt.IsZero() {
+		stats.LastRunAt = r.lastRunAt.UTC().Format(time.RFC3339)
+	}
+	return stats, nil
+}
@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records published events and refuses those in refuse
+type recordingSink struct {
+	published []string
+	refuse    map[string]bool
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event string, payload interface{}) error {
+	body := string(payload.(json.RawMessage))
+	if s.refuse[body] {
+		return fmt.Errorf("sink unavailable")
+	}
+	s.published = append(s.published, event+" "+body)
+	return nil
+}
+
+func TestRelay_RelayBatch(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	repo := repository.NewOutboxRepository(db)
+	for _, id := range []string{"1", "2", "3"} {
+		_, err := repo.CreateMessage(ctx, &models.OutboxMessage{Event: models.EventTransactionCompleted, Payload: json.RawMessage(`{"id":` + id + `}`)})
+		require.NoError(t, err)
+	}
+
+	// The batch stops at the refused message, keeping the order for the next run
+	sink := &recordingSink{refuse: map[string]bool{`{"id": 2}`: true}}
+	relay := NewRelay(db, repo, sink, &config.Config{OutboxPollInterval: 1000, OutboxBatchSize: 2})
+	published, err := relay.RelayBatch(ctx, 10)
+	assert.Error(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{`transaction.completed {"id": 1}`}, sink.published)
+
+	stats, err := relay.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Backlog)
+	assert.Equal(t, uint64(1), stats.Failures)
+	assert.Contains(t, stats.LastFailure, "sink unavailable")
+
+	var attempts int
+	var lastError string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT attempts, last_error FROM outbox WHERE payload = '{"id": 2}'`).Scan(&attempts, &lastError))
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "sink unavailable", lastError)
+
+	// Once the sink recovers, the rest is relayed in batches
+	sink.refuse = nil
+	relay.Drain(ctx)
+	assert.Equal(t, []string{`transaction.completed {"id": 1}`, `transaction.completed {"id": 2}`, `transaction.completed {"id": 3}`}, sink.published)
+
+	stats, err = relay.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Backlog)
+	assert.Equal(t, uint64(3), stats.Published)
+
+	published, err = relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+}
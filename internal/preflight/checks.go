@@ -0,0 +1,324 @@
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/middleware"
+	"github.com/khamiruf/internal_transfers_system_go/internal/blob"
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
+	"github.com/khamiruf/internal_transfers_system_go/internal/chaos"
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/database"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/events"
+	"github.com/khamiruf/internal_transfers_system_go/internal/fx"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/kafka"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/nats"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+	"github.com/khamiruf/internal_transfers_system_go/internal/region"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/velocity"
+	"github.com/khamiruf/internal_transfers_system_go/internal/warmup"
+	"github.com/shopspring/decimal"
+)
+
+// checkConfig parses every setting the way the components using it do
+func checkConfig(ctx context.Context, env *Env) ([]string, error) {
+	cfg := env.Config
+	var problems []string
+	add := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if cfg.AmountScale < 0 || cfg.AmountPrecision <= cfg.AmountScale {
+		problems = append(problems, fmt.Sprintf("AMOUNT_PRECISION %d must be greater than AMOUNT_SCALE %d, which must not be negative", cfg.AmountPrecision, cfg.AmountScale))
+	}
+	if cfg.RegionRole != string(region.RolePrimary) && cfg.RegionRole != string(region.RoleStandby) {
+		problems = append(problems, fmt.Sprintf("REGION_ROLE %q must be primary or standby", cfg.RegionRole))
+	}
+	_, err := businessday.NewCalendar(cfg.BusinessDayTimezone, cfg.BusinessDayCutoff)
+	add(err)
+	_, err = models.ParseMinimumBalances(cfg.MinimumBalances)
+	add(err)
+	_, err = fx.NewStaticRates(cfg.FXRates)
+	add(err)
+	_, err = priority.ParseWeights(cfg.TransferLaneWeights)
+	add(err)
+	_, err = priority.ParseActorClasses(cfg.TransferPriorityByActor)
+	add(err)
+	_, err = metrics.ParseSLOs(cfg.SLOTargets)
+	add(err)
+	_, err = velocity.ParseRules(cfg.VelocityRules)
+	add(err)
+	_, err = middleware.ParseAccessLogFormat(cfg.AccessLogFormat)
+	add(err)
+	if slices.Contains(cfg.Middlewares, "journal") && cfg.JournalPath == "" {
+		problems = append(problems, "MIDDLEWARES includes journal but JOURNAL_PATH is empty")
+	}
+	if cfg.WebhookMaxAttempts < 1 || cfg.WebhookRetryDelay <= 0 {
+		problems = append(problems, fmt.Sprintf("WEBHOOK_MAX_ATTEMPTS %d must be at least 1 and WEBHOOK_RETRY_DELAY_MS %d positive", cfg.WebhookMaxAttempts, cfg.WebhookRetryDelay))
+	}
+	if cfg.WorkerPollInterval <= 0 || cfg.WorkerStallGrace <= 0 {
+		problems = append(problems, fmt.Sprintf("WORKER_POLL_INTERVAL_MS %d and WORKER_STALL_GRACE_SECONDS %d must be positive", cfg.WorkerPollInterval, cfg.WorkerStallGrace))
+	}
+	if cfg.AsyncTransferWorkers < 1 || cfg.AsyncTransferMaxAttempts < 1 || cfg.AsyncTransferRetryDelay <= 0 {
+		problems = append(problems, fmt.Sprintf("ASYNC_TRANSFER_WORKERS %d and ASYNC_TRANSFER_MAX_ATTEMPTS %d must be at least 1 and ASYNC_TRANSFER_RETRY_DELAY_MS %d positive",
+			cfg.AsyncTransferWorkers, cfg.AsyncTransferMaxAttempts, cfg.AsyncTransferRetryDelay))
+	}
+	if cfg.OutboxEnabled && (cfg.OutboxPollInterval <= 0 || cfg.OutboxBatchSize <= 0) {
+		problems = append(problems, fmt.Sprintf("OUTBOX_POLL_INTERVAL_MS %d and OUTBOX_BATCH_SIZE %d must be positive", cfg.OutboxPollInterval, cfg.OutboxBatchSize))
+	}
+	add(events.ValidateBus(cfg.EventBus))
+	if cfg.EventBus != events.BusNone && !cfg.OutboxEnabled {
+		problems = append(problems, fmt.Sprintf("EVENT_BUS=%s requires OUTBOX_ENABLED; events are published to the bus by the outbox relay", cfg.EventBus))
+	}
+	switch cfg.EventBus {
+	case events.BusKafka:
+		if len(cfg.KafkaBrokers) == 0 || cfg.KafkaTimeout <= 0 {
+			problems = append(problems, fmt.Sprintf("EVENT_BUS=kafka requires KAFKA_BROKERS and a positive KAFKA_TIMEOUT_MS, not %d", cfg.KafkaTimeout))
+		}
+		add(kafka.ValidateBrokers(cfg.KafkaBrokers))
+		if !cfg.KafkaPlaintext {
+			add(kafka.ErrPlaintext)
+		}
+	case events.BusNATS:
+		if cfg.NATSTimeout <= 0 {
+			problems = append(problems, fmt.Sprintf("NATS_TIMEOUT_MS %d must be positive", cfg.NATSTimeout))
+		}
+		add(nats.ValidateNames(cfg.NATSStream, cfg.NATSSubjectPrefix, cfg.NATSConsumers))
+	}
+	_, err = kafka.ParseTopics(cfg.KafkaTopics)
+	add(err)
+	if cfg.WarmupConnections < 0 || cfg.WarmupTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("WARMUP_CONNECTIONS %d must not be negative and WARMUP_TIMEOUT_MS %d must be positive", cfg.WarmupConnections, cfg.WarmupTimeout))
+	}
+	_, err = warmup.ParseAccountIDs(cfg.WarmupHotAccounts)
+	add(err)
+	if cfg.ChaosEnabled {
+		if !cfg.SandboxMode {
+			problems = append(problems, "CHAOS_ENABLED requires SANDBOX_MODE; chaos mode must never run in production")
+		}
+		if cfg.ChaosMaxLatency < 0 {
+			problems = append(problems, fmt.Sprintf("CHAOS_MAX_LATENCY_MS %d must not be negative", cfg.ChaosMaxLatency))
+		}
+	}
+	_, err = chaos.ParseRates(cfg.ChaosFaults)
+	add(err)
+	if cfg.ApprovalThreshold != "" {
+		if threshold, err := decimal.NewFromString(cfg.ApprovalThreshold); err != nil || threshold.IsNegative() {
+			problems = append(problems, fmt.Sprintf("APPROVAL_THRESHOLD %q must be a non-negative amount", cfg.ApprovalThreshold))
+		}
+	}
+	if len(cfg.IdempotencySealingKeys) > 0 {
+		keys, err := idempotency.ParseKeys(cfg.IdempotencySealingKeys)
+		if err == nil {
+			_, err = idempotency.NewSealer(keys, cfg.IdempotencyActiveKey)
+		}
+		add(err)
+	}
+	if cfg.ArtifactStore != "" {
+		_, err = blob.Open(blob.Config{
+			Backend:         cfg.ArtifactStore,
+			Dir:             cfg.ArtifactDir,
+			Bucket:          cfg.ArtifactBucket,
+			Prefix:          cfg.ArtifactPrefix,
+			Endpoint:        cfg.ArtifactEndpoint,
+			Timeout:         time.Duration(cfg.ArtifactTimeout) * time.Millisecond,
+			Region:          cfg.ArtifactRegion,
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+			SessionToken:    cfg.AWSSessionToken,
+			AccessToken:     cfg.GCSAccessToken,
+		})
+		add(err)
+	}
+
+	systemAccounts := map[int64]string{}
+	for _, account := range configuredSystemAccounts(cfg) {
+		if other, ok := systemAccounts[account.id]; ok {
+			problems = append(problems, fmt.Sprintf("%s and %s are both account %d", other, account.setting, account.id))
+		}
+		systemAccounts[account.id] = account.setting
+	}
+	return problems, nil
+}
+
+// checkDatabase connects to the database unless the runner was given a connection, and checks
+// a primary isn't connected to a read-only server
+func checkDatabase(ctx context.Context, env *Env) ([]string, error) {
+	if env.DB == nil {
+		db, err := database.Open(ctx, env.Config)
+		if err != nil {
+			return []string{err.Error()}, nil
+		}
+		env.DB = db
+	}
+
+	var readOnly string
+	if err := env.DB.QueryRowContext(ctx, `SHOW transaction_read_only`).Scan(&readOnly); err != nil {
+		return nil, fmt.Errorf("failed to check whether the database is read-only: %w", err)
+	}
+	if readOnly == "on" && env.Config.RegionRole != string(region.RoleStandby) {
+		return []string{"the database is read-only but REGION_ROLE is " + env.Config.RegionRole}, nil
+	}
+	return nil, nil
+}
+
+// checkSchema reports the migrations that haven't been applied
+func checkSchema(ctx context.Context, env *Env) ([]string, error) {
+	missing, err := MissingMigrations(ctx, env.DB)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]string, 0, len(missing))
+	for _, migration := range missing {
+		problems = append(problems, fmt.Sprintf("migration %s is not applied (expected schema %s)", migration, ExpectedSchemaVersion()))
+	}
+	return problems, nil
+}
+
+// systemAccount is an account the service is configured to credit
+type systemAccount struct {
+	setting string
+	id      int64
+}
+
+// configuredSystemAccounts returns the system accounts set in cfg
+func configuredSystemAccounts(cfg *config.Config) []systemAccount {
+	var accounts []systemAccount
+	for _, account := range []systemAccount{
+		{"SUSPENSE_ACCOUNT_ID", cfg.SuspenseAccountID},
+		{"FEES_ACCOUNT_ID", cfg.FeesAccountID},
+		{"CLOSURE_SWEEP_ACCOUNT_ID", cfg.ClosureSweepAccountID},
+	} {
+		if account.id != 0 {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// checkSystemAccounts checks every configured system account exists and can be credited
+func checkSystemAccounts(ctx context.Context, env *Env) ([]string, error) {
+	accounts := repository.NewAccountRepository(env.DB)
+	var problems []string
+	for _, system := range configuredSystemAccounts(env.Config) {
+		account, err := accounts.GetAccount(ctx, system.id)
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			problems = append(problems, fmt.Sprintf("%s: account %d doesn't exist", system.setting, system.id))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !account.CanReceiveCredits() {
+			problems = append(problems, fmt.Sprintf("%s: account %d is %s and can't be credited", system.setting, system.id, account.Status))
+		}
+	}
+	return problems, nil
+}
+
+// checkLedgerAccounts checks the ledger accounts named by posting rules exist and are active
+func checkLedgerAccounts(ctx context.Context, env *Env) ([]string, error) {
+	rows, err := env.DB.QueryContext(ctx, `
+		SELECT r.transfer_type, r.leg, side.code, la.active
+		FROM posting_rules r
+		CROSS JOIN LATERAL (VALUES (r.debit), (r.credit)) AS side(code)
+		LEFT JOIN ledger_accounts la ON la.code = side.code
+		WHERE side.code NOT LIKE '@%' AND (la.code IS NULL OR NOT la.active)
+		ORDER BY r.transfer_type, r.leg, side.code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the ledger accounts of posting rules: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var transferType, code string
+		var leg int
+		var active sql.NullBool
+		if err := rows.Scan(&transferType, &leg, &code, &active); err != nil {
+			return nil, err
+		}
+		if active.Valid {
+			problems = append(problems, fmt.Sprintf("leg %d of %s posts to inactive ledger account %s", leg, transferType, code))
+		} else {
+			problems = append(problems, fmt.Sprintf("leg %d of %s posts to missing ledger account %s", leg, transferType, code))
+		}
+	}
+	return problems, rows.Err()
+}
+
+// checkDryRunTransfer writes a transfer between two probe accounts the way the transfer service
+// does, at serializable isolation, and rolls it all back. Only the transaction ID sequence
+// advances. A standby region's database is read-only, so the check doesn't apply there.
+func checkDryRunTransfer(ctx context.Context, env *Env) ([]string, error) {
+	if env.Config.RegionRole == string(region.RoleStandby) {
+		return nil, skipped("standby regions don't take writes")
+	}
+	calendar, err := businessday.NewCalendar(env.Config.BusinessDayTimezone, env.Config.BusinessDayCutoff)
+	if err != nil {
+		return []string{"business day calendar: " + err.Error()}, nil
+	}
+
+	tx, err := env.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return []string{"begin: " + err.Error()}, nil
+	}
+	defer tx.Rollback()
+
+	var sourceID int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(account_id), 0) + 1 FROM accounts`).Scan(&sourceID); err != nil {
+		return []string{"pick probe accounts: " + err.Error()}, nil
+	}
+	destID := sourceID + 1
+	amount := decimal.NewFromInt(1)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO accounts (account_id, balance, initial_balance)
+		VALUES ($1, $3, $3), ($2, 0, 0)
+	`, sourceID, destID, amount); err != nil {
+		return []string{"create probe accounts: " + err.Error()}, nil
+	}
+
+	accounts := repository.NewAccountRepository(env.DB)
+	transactions := repository.NewTransactionRepository(env.DB)
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"lock source", func() error { _, err := accounts.GetAccountWithTx(ctx, tx, sourceID); return err }},
+		{"lock destination", func() error { _, err := accounts.GetAccountWithTx(ctx, tx, destID); return err }},
+		{"debit source", func() error { return accounts.UpdateBalanceWithTx(ctx, tx, sourceID, decimal.Zero) }},
+		{"credit destination", func() error { return accounts.UpdateBalanceWithTx(ctx, tx, destID, amount) }},
+		{"record transaction", func() error {
+			_, err := transactions.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+				SourceAccountID:      sourceID,
+				DestinationAccountID: destID,
+				Amount:               amount,
+				Status:               models.TransactionStatusComplete,
+				BusinessDate:         calendar.Date(time.Now()),
+			})
+			return err
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			return []string{step.name + ": " + err.Error()}, nil
+		}
+	}
+	if err := tx.Rollback(); err != nil {
+		return []string{"roll back: " + err.Error()}, nil
+	}
+	return nil, nil
+}
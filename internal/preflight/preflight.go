@@ -0,0 +1,175 @@
+// Package preflight checks a deployment is ready to take traffic: its configuration parses, the
+// database is reachable and fully migrated, the system accounts it is configured with exist, and
+// a transfer can be written. Deployment pipelines run it before cutting traffic over.
+package preflight
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+)
+
+// Querier is the subset of *sql.DB and *sql.Tx used by checks
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Check is a single named readiness check. Checks needing the database are skipped when it
+// can't be reached.
+type Check struct {
+	Name        string
+	Description string
+	NeedsDB     bool
+	// Run returns one message per problem found
+	Run func(ctx context.Context, env *Env) ([]string, error)
+}
+
+// Env is what checks run against; DB is nil until the database check connected
+type Env struct {
+	Config *config.Config
+	DB     *sql.DB
+}
+
+// CheckResult is the outcome of running a Check
+type CheckResult struct {
+	Name     string
+	Problems []string
+	Skipped  string // reason the check did not run, if any
+	Err      error
+	Duration time.Duration
+}
+
+// Report is the outcome of a preflight run
+type Report struct {
+	Results []CheckResult
+}
+
+// OK reports whether no check failed. A skipped check doesn't count: checks are only skipped
+// when they don't apply or an earlier check failed.
+func (r *Report) OK() bool {
+	for _, result := range r.Results {
+		if result.Err != nil || len(result.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Write prints the report in a human readable form
+func (r *Report) Write(w io.Writer) {
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(w, "[ERROR] %s: %v\n", result.Name, result.Err)
+		case result.Skipped != "":
+			fmt.Fprintf(w, "[SKIP]  %s: %s\n", result.Name, result.Skipped)
+		case len(result.Problems) > 0:
+			fmt.Fprintf(w, "[FAIL]  %s: %d problem(s) (%s)\n", result.Name, len(result.Problems), result.Duration.Round(time.Millisecond))
+			for _, problem := range result.Problems {
+				fmt.Fprintf(w, "        - %s\n", problem)
+			}
+		default:
+			fmt.Fprintf(w, "[PASS]  %s (%s)\n", result.Name, result.Duration.Round(time.Millisecond))
+		}
+	}
+	if r.OK() {
+		fmt.Fprintln(w, "preflight passed")
+	} else {
+		fmt.Fprintln(w, "preflight failed")
+	}
+}
+
+// Runner runs readiness checks in order
+type Runner struct {
+	env    *Env
+	checks []Check
+}
+
+// NewRunner creates a runner with the default set of checks for cfg. If db is nil, the database
+// check connects with cfg and the runner closes the connection once done.
+func NewRunner(cfg *config.Config, db *sql.DB) *Runner {
+	return &Runner{env: &Env{Config: cfg, DB: db}, checks: DefaultChecks()}
+}
+
+// Run executes every check and reports the outcome; it only fails if ctx is done
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if r.env.DB == nil {
+		defer func() {
+			if r.env.DB != nil {
+				r.env.DB.Close()
+				r.env.DB = nil
+			}
+		}()
+	}
+
+	report := &Report{}
+	for _, check := range r.checks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := CheckResult{Name: check.Name}
+		if check.NeedsDB && r.env.DB == nil {
+			result.Skipped = "database unavailable"
+			report.Results = append(report.Results, result)
+			continue
+		}
+		start := time.Now()
+		result.Problems, result.Err = check.Run(ctx, r.env)
+		if reason, ok := result.Err.(skipped); ok {
+			result.Skipped, result.Err = string(reason), nil
+		}
+		result.Duration = time.Since(start)
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// skipped is returned by checks that don't apply, with the reason
+type skipped string
+
+func (s skipped) Error() string { return string(s) }
+
+// DefaultChecks returns the checks run by the preflight command
+func DefaultChecks() []Check {
+	return []Check{
+		{
+			Name:        "config",
+			Description: "every setting parses and settings that depend on each other agree",
+			Run:         checkConfig,
+		},
+		{
+			Name:        "database",
+			Description: "the database is reachable, and writable unless the region is a standby",
+			Run:         checkDatabase,
+		},
+		{
+			Name:        "schema",
+			Description: "every migration this build expects has been applied",
+			NeedsDB:     true,
+			Run:         checkSchema,
+		},
+		{
+			Name:        "system_accounts",
+			Description: "the configured suspense, fees and closure sweep accounts exist and are open",
+			NeedsDB:     true,
+			Run:         checkSystemAccounts,
+		},
+		{
+			Name:        "ledger_accounts",
+			Description: "every ledger account the posting rules refer to exists and is active",
+			NeedsDB:     true,
+			Run:         checkLedgerAccounts,
+		},
+		{
+			Name:        "dry_run_transfer",
+			Description: "a transfer between two probe accounts can be written, in a transaction that is rolled back",
+			NeedsDB:     true,
+			Run:         checkDryRunTransfer,
+		},
+	}
+}
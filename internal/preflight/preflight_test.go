@@ -0,0 +1,119 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *config.Config {
+	return &config.Config{
+		AmountPrecision:     20,
+		AmountScale:         5,
+		RegionRole:          "primary",
+		BusinessDayTimezone: "UTC",
+		BusinessDayCutoff:   "24:00",
+		AccessLogFormat:     "json",
+		WebhookMaxAttempts:  5,
+		WebhookRetryDelay:   30000,
+		WarmupTimeout:       10000,
+
+		WorkerPollInterval:       500,
+		WorkerStallGrace:         60,
+		AsyncTransferWorkers:     4,
+		AsyncTransferMaxAttempts: 5,
+		AsyncTransferRetryDelay:  1000,
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	problems, err := checkConfig(context.Background(), &Env{Config: validConfig()})
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	cfg := validConfig()
+	cfg.AmountPrecision = 5
+	cfg.RegionRole = "secondary"
+	cfg.BusinessDayCutoff = "25:00"
+	cfg.FXRates = map[string]string{"USD": "1.3"}
+	cfg.TransferLaneWeights = map[string]string{"urgent": "3"}
+	cfg.SLOTargets = map[string]string{"POST /transactions": "fast"}
+	cfg.AccessLogFormat = "xml"
+	cfg.Middlewares = []string{"auth", "journal"}
+	cfg.WebhookMaxAttempts = 0
+	cfg.AsyncTransferWorkers = 0
+	cfg.WarmupHotAccounts = []string{"hot"}
+	cfg.ChaosEnabled = true
+	cfg.EventBus = "nats"
+	cfg.NATSStream, cfg.NATSSubjectPrefix, cfg.NATSTimeout = "TRANSFERS", "transfers", 5000
+	cfg.ArtifactStore = "s3"
+	cfg.SuspenseAccountID, cfg.FeesAccountID = 9, 9
+	problems, err = checkConfig(context.Background(), &Env{Config: cfg})
+	require.NoError(t, err)
+	assert.Len(t, problems, 15)
+}
+
+func TestReport(t *testing.T) {
+	report := &Report{Results: []CheckResult{
+		{Name: "config"},
+		{Name: "dry_run_transfer", Skipped: "standby regions don't take writes"},
+	}}
+	assert.True(t, report.OK())
+
+	report.Results = append(report.Results, CheckResult{Name: "schema", Problems: []string{"migration 031_account_limits is not applied"}})
+	assert.False(t, report.OK())
+
+	var out bytes.Buffer
+	report.Write(&out)
+	assert.Contains(t, out.String(), "[SKIP]  dry_run_transfer: standby regions don't take writes")
+	assert.Contains(t, out.String(), "[FAIL]  schema: 1 problem(s)")
+	assert.Contains(t, out.String(), "        - migration 031_account_limits is not applied")
+	assert.Contains(t, out.String(), "preflight failed")
+}
+
+func TestRunner(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`INSERT INTO accounts (account_id, balance, initial_balance) VALUES (900, 0, 0)`)
+	require.NoError(t, err)
+
+	cfg := validConfig()
+	cfg.SuspenseAccountID = 900
+	report, err := NewRunner(cfg, db).Run(ctx)
+	require.NoError(t, err)
+	for _, result := range report.Results {
+		assert.NoError(t, result.Err, result.Name)
+		assert.Empty(t, result.Problems, result.Name)
+	}
+	assert.True(t, report.OK())
+
+	// The dry-run transfer leaves nothing behind
+	var accounts, transactions int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM accounts`).Scan(&accounts))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&transactions))
+	assert.Equal(t, 1, accounts)
+	assert.Equal(t, 0, transactions)
+
+	// A missing system account, a closed one and a missing migration are reported
+	cfg.FeesAccountID = 901
+	_, err = db.Exec(`UPDATE accounts SET status = 'closed' WHERE account_id = 900`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DROP TABLE balance_snapshots`)
+	require.NoError(t, err)
+	report, err = NewRunner(cfg, db).Run(ctx)
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	problems := map[string][]string{}
+	for _, result := range report.Results {
+		problems[result.Name] = result.Problems
+	}
+	assert.Equal(t, []string{"migration 026_balance_snapshots is not applied (expected schema " + ExpectedSchemaVersion() + ")"}, problems["schema"])
+	assert.Len(t, problems["system_accounts"], 2)
+}
@@ -0,0 +1,103 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+)
+
+// schemaMarker is an object a migration creates, whose presence shows the migration was applied
+type schemaMarker struct {
+	migration string
+	table     string
+	column    string // empty if the marker is the table itself
+	index     string // set instead of column for migrations that only add indexes
+}
+
+// schemaMarkers lists one marker per migration, in order. A new migration adds its marker here, so
+// a deployment can't start against a database it hasn't been applied to.
+var schemaMarkers = []schemaMarker{
+	{migration: "001_init", table: "transactions"},
+	{migration: "002_value_date", table: "transactions", column: "value_date"},
+	{migration: "003_account_initial_balance", table: "accounts", column: "initial_balance"},
+	{migration: "004_region_lease", table: "region_lease"},
+	{migration: "005_account_owner", table: "accounts", column: "owner_ref"},
+	{migration: "006_transaction_tags", table: "transactions", column: "tags"},
+	{migration: "007_webhooks", table: "webhook_deliveries"},
+	{migration: "008_idempotency", table: "transfer_batches"},
+	{migration: "009_external_reference", table: "transactions", column: "external_reference"},
+	{migration: "010_suspense", table: "suspense_item_events"},
+	{migration: "011_business_date", table: "transactions", column: "business_date"},
+	{migration: "012_account_dormancy", table: "accounts", column: "last_activity_at"},
+	{migration: "013_minimum_balance", table: "audit_log"},
+	{migration: "014_preauthorizations", table: "preauthorizations"},
+	{migration: "015_account_currency", table: "accounts", column: "currency"},
+	{migration: "016_ledger_accounts", table: "ledger_accounts"},
+	{migration: "017_posting_rules", table: "posting_rules"},
+	{migration: "018_idempotency_keys", table: "idempotency_keys"},
+	{migration: "019_transaction_account_pages", table: "transactions", index: "idx_transactions_destination_created_id"},
+	{migration: "020_ledger_entries", table: "ledger_entries"},
+	{migration: "021_transaction_reversals", table: "transactions", column: "reversal_of"},
+	{migration: "022_transaction_status_history", table: "transaction_status_history"},
+	{migration: "023_tenant_settings", table: "accounts", column: "tenant_id"},
+	{migration: "024_transaction_fx", table: "transactions", column: "fx_rate"},
+	{migration: "025_fees", table: "transactions", column: "fee_of"},
+	{migration: "026_balance_snapshots", table: "balance_snapshots"},
+	{migration: "027_split_transfers", table: "transactions", column: "split_id"},
+	{migration: "028_scheduled_transfers", table: "scheduled_transfers"},
+	{migration: "029_standing_orders", table: "scheduled_transfers", column: "standing_order_id"},
+	{migration: "030_overdraft_limit", table: "accounts", column: "overdraft_limit"},
+	{migration: "031_account_limits", table: "accounts", column: "monthly_limit"},
+	{migration: "032_idempotency_retention", table: "idempotency_keys", column: "sealed_response"},
+	{migration: "033_transfer_approvals", table: "transfer_approvals"},
+	{migration: "034_tenant_quotas", table: "tenant_settings", column: "max_daily_transactions"},
+	{migration: "035_webhook_subscriptions", table: "webhook_deliveries", column: "next_attempt_at"},
+	{migration: "036_outbox", table: "outbox"},
+	{migration: "037_system_funding", table: "system_fundings"},
+	{migration: "038_transfer_jobs", table: "transfer_jobs"},
+	{migration: "039_dead_letters", table: "dead_letters"},
+	{migration: "040_api_credentials", table: "api_credentials"},
+	{migration: "041_delegations", table: "delegations"},
+	{migration: "042_account_notes", table: "account_notes"},
+	{migration: "043_account_balance_pages", table: "accounts", index: "idx_accounts_balance_id"},
+	{migration: "044_balance_adjustments", table: "balance_adjustments"},
+	{migration: "045_credential_roles", table: "api_credentials", column: "roles"},
+	{migration: "046_credential_signing_secrets", table: "api_credentials", column: "signing_secret"},
+	{migration: "047_batch_item_keys", table: "transactions", column: "batch_item_key"},
+	{migration: "048_idempotency_key_owners", table: "idempotency_keys", column: "owner"},
+	{migration: "049_ledger_entry_legs", table: "ledger_entries", column: "leg"},
+	{migration: "050_ledger_entry_currency", table: "ledger_entries", column: "currency"},
+	{migration: "051_transfer_job_admin", table: "transfer_job_events"},
+}
+
+// ExpectedSchemaVersion is the latest migration this build expects
+func ExpectedSchemaVersion() string {
+	return schemaMarkers[len(schemaMarkers)-1].migration
+}
+
+// MissingMigrations returns the migrations whose marker isn't in the current schema, in order
+func MissingMigrations(ctx context.Context, q Querier) ([]string, error) {
+	var missing []string
+	for _, marker := range schemaMarkers {
+		var query string
+		var args []interface{}
+		switch {
+		case marker.index != "":
+			query = `SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE schemaname = current_schema() AND tablename = $1 AND indexname = $2)`
+			args = []interface{}{marker.table, marker.index}
+		case marker.column != "":
+			query = `SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`
+			args = []interface{}{marker.table, marker.column}
+		default:
+			query = `SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1)`
+			args = []interface{}{marker.table}
+		}
+		var present bool
+		if err := q.QueryRowContext(ctx, query, args...).Scan(&present); err != nil {
+			return nil, fmt.Errorf("failed to look up migration %s: %w", marker.migration, err)
+		}
+		if !present {
+			missing = append(missing, marker.migration)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,147 @@
+package priority
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWeights are the shares of freed slots handed to waiting work of each class when all of
+// them are waiting
+var DefaultWeights = map[Class]int{Critical: 8, Normal: 4, Bulk: 1}
+
+// Lanes bounds how many transfers run at once and decides who goes next when they're all taken.
+// Waiting work of each class queues in its own lane; a freed slot goes to the head of a lane
+// picked by weighted round robin, so urgent work overtakes bulk work without starving it. Bulk
+// work can additionally be capped below the total, keeping slots free for everything else. A nil
+// *Lanes admits everything at once.
+type Lanes struct {
+	mu       sync.Mutex
+	free     int
+	bulkMax  int
+	bulkBusy int
+	weights  map[Class]int
+	current  map[Class]int // smooth weighted round robin state
+	waiting  map[Class][]*waiter
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewLanes creates lanes for slots concurrent transfers, of which bulk work may take at most
+// bulkMax (all of them if bulkMax <= 0). Classes missing from weights get a weight of 1.
+func NewLanes(slots, bulkMax int, weights map[Class]int) *Lanes {
+	slots = max(slots, 1)
+	if bulkMax <= 0 || bulkMax > slots {
+		bulkMax = slots
+	}
+	l := &Lanes{
+		free:    slots,
+		bulkMax: bulkMax,
+		weights: make(map[Class]int, len(classes)),
+		current: make(map[Class]int, len(classes)),
+		waiting: make(map[Class][]*waiter, len(classes)),
+	}
+	for _, class := range classes {
+		l.weights[class] = max(weights[class], 1)
+	}
+	return l
+}
+
+// Acquire waits for a slot in the lane of the class carried by ctx, or until ctx is done. The
+// caller must call release once the transfer is done.
+func (l *Lanes) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	class := FromContext(ctx)
+	w := &waiter{ready: make(chan struct{})}
+
+	l.mu.Lock()
+	l.waiting[class] = append(l.waiting[class], w)
+	l.dispatch()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return func() { l.release(class) }, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.granted {
+			// Granted while giving up; hand the slot on
+			l.releaseLocked(class)
+		} else {
+			l.remove(class, w)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release frees a slot taken for class
+func (l *Lanes) release(class Class) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked(class)
+}
+
+func (l *Lanes) releaseLocked(class Class) {
+	l.free++
+	if class == Bulk {
+		l.bulkBusy--
+	}
+	l.dispatch()
+}
+
+// dispatch grants free slots to waiting work, picking lanes by smooth weighted round robin
+func (l *Lanes) dispatch() {
+	for l.free > 0 {
+		var next Class
+		total := 0
+		for _, class := range classes {
+			if len(l.waiting[class]) == 0 || (class == Bulk && l.bulkBusy >= l.bulkMax) {
+				continue
+			}
+			l.current[class] += l.weights[class]
+			total += l.weights[class]
+			if next == "" || l.current[class] > l.current[next] {
+				next = class
+			}
+		}
+		if next == "" {
+			return
+		}
+		l.current[next] -= total
+
+		w := l.waiting[next][0]
+		l.waiting[next] = l.waiting[next][1:]
+		l.free--
+		if next == Bulk {
+			l.bulkBusy++
+		}
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// remove drops a waiter that gave up from its lane
+func (l *Lanes) remove(class Class, w *waiter) {
+	queue := l.waiting[class]
+	for i, queued := range queue {
+		if queued == w {
+			l.waiting[class] = append(queue[:i:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Waiting returns the number of transfers waiting in the lane of class
+func (l *Lanes) Waiting(class Class) int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiting[class])
+}
@@ -0,0 +1,100 @@
+package priority
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireAsync starts waiting for a slot of class and reports on the returned channel once granted
+func acquireAsync(t *testing.T, lanes *Lanes, class Class) <-chan func() {
+	t.Helper()
+	granted := make(chan func(), 1)
+	before := lanes.Waiting(class)
+	go func() {
+		release, err := lanes.Acquire(WithClass(context.Background(), class))
+		if err == nil {
+			granted <- release
+		}
+	}()
+	require.Eventually(t, func() bool { return lanes.Waiting(class) > before || len(granted) > 0 }, time.Second, time.Millisecond)
+	return granted
+}
+
+func TestLanes_UrgentOvertakesBulk(t *testing.T) {
+	lanes := NewLanes(1, 0, DefaultWeights)
+	holder, err := lanes.Acquire(WithClass(context.Background(), Bulk))
+	require.NoError(t, err)
+
+	bulk := acquireAsync(t, lanes, Bulk)
+	critical := acquireAsync(t, lanes, Critical)
+
+	holder()
+	release := <-critical
+	assert.Equal(t, 1, lanes.Waiting(Bulk))
+	release()
+	(<-bulk)()
+}
+
+func TestLanes_BulkCap(t *testing.T) {
+	lanes := NewLanes(2, 1, DefaultWeights)
+	bulkHolder, err := lanes.Acquire(WithClass(context.Background(), Bulk))
+	require.NoError(t, err)
+
+	// The second slot is kept for non-bulk work
+	bulk := acquireAsync(t, lanes, Bulk)
+	release, err := lanes.Acquire(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, lanes.Waiting(Bulk))
+
+	release()
+	assert.Equal(t, 1, lanes.Waiting(Bulk))
+	bulkHolder()
+	(<-bulk)()
+}
+
+func TestLanes_BulkIsNotStarved(t *testing.T) {
+	lanes := NewLanes(1, 0, map[Class]int{Critical: 2, Bulk: 1})
+	holder, err := lanes.Acquire(context.Background())
+	require.NoError(t, err)
+
+	bulk := acquireAsync(t, lanes, Bulk)
+	first := acquireAsync(t, lanes, Critical)
+	second := acquireAsync(t, lanes, Critical)
+
+	// Weights of 2:1 hand the first freed slot to critical work and the next to bulk work
+	holder()
+	(<-first)()
+	release := <-bulk
+	assert.Equal(t, 1, lanes.Waiting(Critical))
+	release()
+	(<-second)()
+}
+
+func TestLanes_CancelledWaiterLeaves(t *testing.T) {
+	lanes := NewLanes(1, 0, nil)
+	holder, err := lanes.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = lanes.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, lanes.Waiting(Normal))
+
+	holder()
+	release, err := lanes.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestLanes_Nil(t *testing.T) {
+	var lanes *Lanes
+	release, err := lanes.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 0, lanes.Waiting(Bulk))
+}
@@ -0,0 +1,117 @@
+// Package priority classifies transfer work by urgency, so settlement-critical transfers aren't
+// held up behind bulk work such as batch imports. The class of a request is taken from the
+// X-Transfer-Priority header, or else from the default configured for its actor.
+package priority
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Header selects the priority class of a request
+const Header = "X-Transfer-Priority"
+
+// Class is the urgency of a piece of transfer work
+type Class string
+
+// Classes, most urgent first
+const (
+	Critical Class = "critical"
+	Normal   Class = "normal"
+	Bulk     Class = "bulk"
+)
+
+// classes lists every class, most urgent first
+var classes = []Class{Critical, Normal, Bulk}
+
+// ParseClass parses a class name, ignoring case and surrounding whitespace
+func ParseClass(name string) (Class, error) {
+	class := Class(strings.ToLower(strings.TrimSpace(name)))
+	for _, c := range classes {
+		if class == c {
+			return class, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown priority %q, must be critical, normal or bulk", errors.ErrValidationFailed, name)
+}
+
+// ParseActorClasses parses the default classes of actors, e.g. from
+// {"settlement": "critical", "importer": "bulk"}
+func ParseActorClasses(byActor map[string]string) (map[string]Class, error) {
+	parsed := make(map[string]Class, len(byActor))
+	for name, className := range byActor {
+		class, err := ParseClass(className)
+		if err != nil {
+			return nil, fmt.Errorf("priority of actor %s: %w", name, err)
+		}
+		parsed[name] = class
+	}
+	return parsed, nil
+}
+
+// ParseWeights parses the lane weights of classes, e.g. from {"critical": "8", "bulk": "1"},
+// starting from DefaultWeights for classes not given
+func ParseWeights(raw map[string]string) (map[Class]int, error) {
+	weights := make(map[Class]int, len(classes))
+	for class, weight := range DefaultWeights {
+		weights[class] = weight
+	}
+	for className, value := range raw {
+		class, err := ParseClass(className)
+		if err != nil {
+			return nil, err
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || weight < 1 {
+			return nil, fmt.Errorf("%w: weight of priority %s must be a positive integer", errors.ErrValidationFailed, class)
+		}
+		weights[class] = weight
+	}
+	return weights, nil
+}
+
+type contextKey struct{}
+
+// WithClass returns a copy of ctx carrying class
+func WithClass(ctx context.Context, class Class) context.Context {
+	return context.WithValue(ctx, contextKey{}, class)
+}
+
+// WithDefault returns a copy of ctx carrying class, unless ctx already carries one. Work that is
+// bulk by nature, such as a batch, uses it so a caller can still ask for more urgency explicitly.
+func WithDefault(ctx context.Context, class Class) context.Context {
+	if _, ok := ctx.Value(contextKey{}).(Class); ok {
+		return ctx
+	}
+	return WithClass(ctx, class)
+}
+
+// FromContext returns the class carried by ctx, or Normal if it carries none
+func FromContext(ctx context.Context) Class {
+	if class, ok := ctx.Value(contextKey{}).(Class); ok {
+		return class
+	}
+	return Normal
+}
+
+// Middleware copies the class of each request into its context: the X-Transfer-Priority header
+// if set, else the default of the request's X-Actor in byActor. A request with neither carries no
+// class. An invalid header is ignored rather than failing the request.
+func Middleware(byActor map[string]Class) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if class, err := ParseClass(r.Header.Get(Header)); err == nil {
+				r = r.WithContext(WithClass(r.Context(), class))
+			} else if class, ok := byActor[r.Header.Get(actor.Header)]; ok {
+				r = r.WithContext(WithClass(r.Context(), class))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package priority
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass(" Critical ")
+	require.NoError(t, err)
+	assert.Equal(t, Critical, class)
+
+	_, err = ParseClass("urgent")
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ParseWeights(map[string]string{"bulk": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[Class]int{Critical: 8, Normal: 4, Bulk: 2}, weights)
+
+	_, err = ParseWeights(map[string]string{"bulk": "0"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
+
+func TestWithDefault(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Normal, FromContext(ctx))
+	assert.Equal(t, Bulk, FromContext(WithDefault(ctx, Bulk)))
+	assert.Equal(t, Critical, FromContext(WithDefault(WithClass(ctx, Critical), Bulk)))
+}
+
+func TestMiddleware(t *testing.T) {
+	var seen Class
+	var explicit bool
+	handler := Middleware(map[string]Class{"importer": Bulk})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+		explicit = WithDefault(r.Context(), Bulk) == r.Context()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(Header, "critical")
+	req.Header.Set(actor.Header, "importer")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, Critical, seen)
+
+	req = httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(actor.Header, "importer")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, Bulk, seen)
+
+	req = httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(Header, "whenever")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, Normal, seen)
+	assert.False(t, explicit)
+}
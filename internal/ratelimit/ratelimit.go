@@ -0,0 +1,195 @@
+// Package ratelimit caps the request rate of each API client, so one noisy client can't overload
+// the serializable transaction path for everyone else. Each client gets a token bucket per route
+// class: reads, transfers and other writes are limited separately, so a client polling balances
+// doesn't use up its transfer allowance.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// Class groups the routes sharing a rate
+type Class string
+
+// Classes
+const (
+	Read     Class = "read"     // GET, HEAD and OPTIONS requests
+	Transfer Class = "transfer" // requests making transfers, see TransferRoutes
+	Write    Class = "write"    // every other request
+)
+
+// TransferRoutes are the ServeMux patterns of the routes that make transfers
+var TransferRoutes = []string{
+	"POST /transactions",
+	"POST /transactions/async",
+	"POST /transfers/",
+	"POST /preauthorizations/{id}/execute",
+	"POST /scheduled-transfers",
+	"POST /standing-orders",
+}
+
+// Rate is a token bucket: a client may make Burst requests at once, and PerSecond more every
+// second after that
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// ParseRates parses rates keyed by class, each "per_second/burst", e.g. from
+// {"read": "50/100", "transfer": "5/10"}. Classes not given aren't limited.
+func ParseRates(raw map[string]string) (map[Class]Rate, error) {
+	rates := make(map[Class]Rate, len(raw))
+	for name, spec := range raw {
+		class := Class(strings.ToLower(strings.TrimSpace(name)))
+		if class != Read && class != Transfer && class != Write {
+			return nil, fmt.Errorf("%w: unknown rate limit class %q, must be read, transfer or write", errors.ErrValidationFailed, name)
+		}
+		parts := strings.Split(spec, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: rate limit of %s %q must be per_second/burst", errors.ErrValidationFailed, class, spec)
+		}
+		perSecond, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil || perSecond <= 0 || math.IsInf(perSecond, 0) {
+			return nil, fmt.Errorf("%w: rate of %s %q must be a positive number", errors.ErrValidationFailed, class, parts[0])
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("%w: burst of %s %q must be a positive integer", errors.ErrValidationFailed, class, parts[1])
+		}
+		rates[class] = Rate{PerSecond: perSecond, Burst: burst}
+	}
+	return rates, nil
+}
+
+type bucketKey struct {
+	client string
+	class  Class
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time // when tokens was last brought up to date
+}
+
+// Limiter keeps a token bucket per client and class in memory. Buckets are per process: with
+// several instances behind a load balancer each enforces the rates on the requests it serves.
+type Limiter struct {
+	rates     map[Class]Rate
+	clock     clock.Clock
+	transfers *http.ServeMux
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastPrune time.Time
+}
+
+// NewLimiter creates a limiter enforcing rates, measuring time on c
+func NewLimiter(rates map[Class]Rate, c clock.Clock) *Limiter {
+	transfers := http.NewServeMux()
+	for _, pattern := range TransferRoutes {
+		transfers.HandleFunc(pattern, func(http.ResponseWriter, *http.Request) {})
+	}
+	return &Limiter{rates: rates, clock: c, transfers: transfers, buckets: make(map[bucketKey]*bucket)}
+}
+
+// ClassOf returns the route class of r
+func (l *Limiter) ClassOf(r *http.Request) Class {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return Read
+	}
+	if _, pattern := l.transfers.Handler(r); pattern != "" {
+		return Transfer
+	}
+	return Write
+}
+
+// Client identifies the client of r: the credential it authenticated with, if the auth middleware
+// ran before, or else its source IP. Unauthenticated API keys aren't trusted as a key, since a
+// client could send a new one with every request to escape its limit.
+func Client(r *http.Request) string {
+	if credential, ok := auth.FromContext(r.Context()); ok {
+		return "credential:" + credential.Name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Allow takes a token from the bucket of client for class. If the bucket is empty it returns
+// false and how long until a token is available. Classes without a rate are always allowed.
+func (l *Limiter) Allow(client string, class Class) (bool, time.Duration) {
+	rate, ok := l.rates[class]
+	if !ok {
+		return true, 0
+	}
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneLocked(now)
+	key := bucketKey{client: client, class: class}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Burst), at: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.at).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(rate.Burst), b.tokens+elapsed*rate.PerSecond)
+		b.at = now
+	}
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate.PerSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// pruneLocked forgets the buckets that have refilled completely, which behave like new ones,
+// scanning them at most once a minute so memory stays bounded by the clients recently active
+func (l *Limiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		rate := l.rates[key.class]
+		if b.tokens+now.Sub(b.at).Seconds()*rate.PerSecond >= float64(rate.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Middleware rejects a request over the rate of its client and class with 429 rate_limited and a
+// Retry-After header giving the seconds until the client may retry
+func Middleware(l *Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client, class := Client(r), l.ClassOf(r)
+			if ok, wait := l.Allow(client, class); !ok {
+				retryAfter := int(math.Ceil(wait.Seconds()))
+				logger.Default().WarnContext(r.Context(), "Rate limited", "client", client, "method", r.Method, "path", r.URL.Path, "class", class)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				response.Error(w, fmt.Errorf("%w: %s rate exceeded, retry in %ds", errors.ErrRateLimited, class, retryAfter))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/response"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock is a clock tests move by hand
+type manualClock struct{ now time.Time }
+
+func (c *manualClock) Now() time.Time { return c.now }
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(map[string]string{"read": "50/100", " Transfer ": " 0.5 / 2 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[Class]Rate{Read: {PerSecond: 50, Burst: 100}, Transfer: {PerSecond: 0.5, Burst: 2}}, rates)
+
+	for name, spec := range map[string]string{
+		"reads":    "50/100",
+		"read":     "50",
+		"write":    "0/10",
+		"transfer": "5/0",
+	} {
+		_, err := ParseRates(map[string]string{name: spec})
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, name)
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	c := &manualClock{now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	l := NewLimiter(map[Class]Rate{Transfer: {PerSecond: 2, Burst: 3}}, c)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("ip:10.0.0.1", Transfer)
+		assert.True(t, ok)
+	}
+	ok, wait := l.Allow("ip:10.0.0.1", Transfer)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other clients and unlimited classes are unaffected
+	ok, _ = l.Allow("ip:10.0.0.2", Transfer)
+	assert.True(t, ok)
+	ok, _ = l.Allow("ip:10.0.0.1", Read)
+	assert.True(t, ok)
+
+	c.now = c.now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("ip:10.0.0.1", Transfer)
+	assert.True(t, ok)
+	ok, _ = l.Allow("ip:10.0.0.1", Transfer)
+	assert.False(t, ok)
+
+	// Refilled buckets are pruned and start full again
+	c.now = c.now.Add(2 * time.Minute)
+	ok, _ = l.Allow("ip:10.0.0.3", Transfer)
+	assert.True(t, ok)
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestLimiter_ClassOf(t *testing.T) {
+	l := NewLimiter(nil, &manualClock{})
+	for _, tc := range []struct {
+		method, path string
+		class        Class
+	}{
+		{http.MethodGet, "/accounts/42", Read},
+		{http.MethodPost, "/transactions", Transfer},
+		{http.MethodPost, "/preauthorizations/7/execute", Transfer},
+		{http.MethodPost, "/accounts", Write},
+		{http.MethodPost, "/transactions/42/reverse", Write},
+	} {
+		assert.Equal(t, tc.class, l.ClassOf(httptest.NewRequest(tc.method, tc.path, nil)), tc.path)
+	}
+}
+
+func TestClient(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/accounts/42", nil)
+	req.RemoteAddr = "10.0.0.1:5123"
+	assert.Equal(t, "ip:10.0.0.1", Client(req))
+
+	req = req.WithContext(auth.WithCredential(context.Background(), &auth.Credential{Name: "payroll"}))
+	assert.Equal(t, "credential:payroll", Client(req))
+}
+
+func TestMiddleware(t *testing.T) {
+	c := &manualClock{now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)}
+	l := NewLimiter(map[Class]Rate{Transfer: {PerSecond: 0.25, Burst: 1}}, c)
+	handler := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/transactions", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/transactions", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "4", rec.Header().Get("Retry-After"))
+	var body response.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "rate_limited", body.Error.Code)
+}
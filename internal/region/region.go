@@ -0,0 +1,184 @@
+// Package region implements active-passive multi-region operation.
+//
+// Exactly one region is primary and accepts writes; standby regions serve reads from a replica.
+// The primary holds the single-row lease in region_lease. Promotion (after the database has been
+// failed over) takes the lease and bumps its epoch, and every write transaction re-checks the
+// lease, so an old primary that still reaches the promoted database is fenced off instead of
+// writing alongside the new one.
+package region
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+)
+
+// Role is the role of this instance's region
+type Role string
+
+const (
+	RolePrimary Role = "primary"
+	RoleStandby Role = "standby"
+)
+
+// Status describes the current region state
+type Status struct {
+	Region      string `json:"region"`
+	Role        Role   `json:"role"`
+	Epoch       int64  `json:"epoch"`
+	LeaseHolder string `json:"lease_holder,omitempty"`
+	LeaseEpoch  int64  `json:"lease_epoch,omitempty"`
+}
+
+// Manager tracks this instance's role and fences writes against the region lease
+type Manager struct {
+	db     *sql.DB
+	region string
+
+	mu    sync.RWMutex
+	role  Role
+	epoch int64
+	log   *slog.Logger
+}
+
+// NewManager creates a region manager in the role configured in cfg
+func NewManager(db *sql.DB, cfg *config.Config) *Manager {
+	role := RolePrimary
+	if cfg.RegionRole == string(RoleStandby) {
+		role = RoleStandby
+	}
+	return &Manager{
+		db:     db,
+		region: cfg.RegionName,
+		role:   role,
+		log:    logger.Default(),
+	}
+}
+
+// Init claims the lease when starting as primary. A primary whose lease is held by another
+// region starts demoted to standby: the other region was promoted while this one was down.
+func (m *Manager) Init(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.role == RoleStandby {
+		m.log.Info("Region starting in standby mode: writes disabled", "region", m.region)
+		return nil
+	}
+
+	var holder string
+	var epoch int64
+	err := m.db.QueryRowContext(ctx, `
+		INSERT INTO region_lease (id, region, epoch) VALUES (1, $1, 1)
+		ON CONFLICT (id) DO UPDATE SET updated_at = region_lease.updated_at
+		RETURNING region, epoch
+	`, m.region).Scan(&holder, &epoch)
+	if err != nil {
+		return fmt.Errorf("failed to read region lease: %w", err)
+	}
+
+	if holder != m.region {
+		m.log.Warn("Region lease held elsewhere: region demoted to standby", "holder", holder, "epoch", epoch, "region", m.region)
+		m.role = RoleStandby
+		return nil
+	}
+
+	m.epoch = epoch
+	m.log.Info("Region running as primary", "region", m.region, "epoch", epoch)
+	return nil
+}
+
+// Promote makes this region primary. The database must already have been promoted:
+// promotion is refused while it is still a replica in recovery.
+func (m *Manager) Promote(ctx context.Context) (*Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.log.Info("Promoting region to primary", "region", m.region)
+
+	var inRecovery bool
+	if err := m.db.QueryRowContext(ctx, `SELECT pg_is_in_recovery()`).Scan(&inRecovery); err != nil {
+		return nil, fmt.Errorf("failed to check database recovery state: %w", err)
+	}
+	if inRecovery {
+		m.log.Warn("Refusing to promote region: database is still a replica", "region", m.region)
+		return nil, fmt.Errorf("%w: database is still in recovery, fail it over first", errors.ErrReadOnly)
+	}
+
+	var epoch int64
+	err := m.db.QueryRowContext(ctx, `
+		INSERT INTO region_lease (id, region, epoch) VALUES (1, $1, 1)
+		ON CONFLICT (id) DO UPDATE SET region = EXCLUDED.region, epoch = region_lease.epoch + 1, updated_at = NOW()
+		RETURNING epoch
+	`, m.region).Scan(&epoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take region lease: %w", err)
+	}
+
+	m.role = RolePrimary
+	m.epoch = epoch
+	m.log.Info("Region promoted to primary", "region", m.region, "epoch", epoch)
+	return m.statusLocked(), nil
+}
+
+// Writable reports whether this instance currently accepts writes
+func (m *Manager) Writable() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.role == RolePrimary
+}
+
+// CheckWrite fences a write transaction: it fails unless this instance is primary and still
+// holds the lease at its epoch. The lease row is locked FOR SHARE, so a concurrent promotion
+// waits for in-flight writes to finish and every later write sees the new epoch.
+func (m *Manager) CheckWrite(ctx context.Context, tx *sql.Tx) error {
+	m.mu.RLock()
+	role, epoch := m.role, m.epoch
+	m.mu.RUnlock()
+
+	if role != RolePrimary {
+		return errors.ErrReadOnly
+	}
+
+	var holder string
+	var leaseEpoch int64
+	err := tx.QueryRowContext(ctx, `SELECT region, epoch FROM region_lease WHERE id = 1 FOR SHARE`).Scan(&holder, &leaseEpoch)
+	if err != nil {
+		return fmt.Errorf("failed to check region lease: %w", err)
+	}
+	if holder != m.region || leaseEpoch != epoch {
+		m.log.Error("Write fenced: lease held by another region, demoting to standby", "holder", holder, "lease_epoch", leaseEpoch, "region", m.region, "epoch", epoch)
+		m.demote()
+		return errors.ErrFenced
+	}
+	return nil
+}
+
+// Status returns the current region state including the lease as seen in the database
+func (m *Manager) Status(ctx context.Context) (*Status, error) {
+	m.mu.RLock()
+	status := m.statusLocked()
+	m.mu.RUnlock()
+
+	err := m.db.QueryRowContext(ctx, `SELECT region, epoch FROM region_lease WHERE id = 1`).Scan(&status.LeaseHolder, &status.LeaseEpoch)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read region lease: %w", err)
+	}
+	return status, nil
+}
+
+func (m *Manager) demote() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.role = RoleStandby
+}
+
+func (m *Manager) statusLocked() *Status {
+	return &Status{Region: m.region, Role: m.role, Epoch: m.epoch}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
@@ -18,131 +21,734 @@ var (
 )
 
 type PostgresAccountRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	log *slog.Logger
 }
 
 func NewAccountRepository(db *sql.DB) *PostgresAccountRepository {
-	return &PostgresAccountRepository{db: db}
+	return &PostgresAccountRepository{db: db, log: logger.Default()}
+}
+
+// accountColumns is the column list selected by every account read, in scanAccount order
+const accountColumns = `account_id, balance, reserved_balance, overdraft_limit, per_transaction_limit, daily_limit, monthly_limit, COALESCE(currency, ''), COALESCE(owner_ref, ''), status, last_activity_at, account_type, COALESCE(tenant_id, '')`
+
+// scanAccount scans a row selected with accountColumns
+func scanAccount(row rowScanner) (*models.Account, error) {
+	var account models.Account
+	var perTransaction, daily, monthly decimal.NullDecimal
+	var lastActivityAt time.Time
+	if err := row.Scan(&account.AccountID, &account.Balance, &account.Reserved, &account.OverdraftLimit, &perTransaction, &daily, &monthly, &account.Currency, &account.OwnerRef, &account.Status, &lastActivityAt, &account.Type, &account.TenantID); err != nil {
+		return nil, err
+	}
+	if perTransaction.Valid {
+		account.Limits.PerTransaction = &perTransaction.Decimal
+	}
+	if daily.Valid {
+		account.Limits.Daily = &daily.Decimal
+	}
+	if monthly.Valid {
+		account.Limits.Monthly = &monthly.Decimal
+	}
+	account.LastActivityAt = lastActivityAt.Format(time.RFC3339)
+	return &account, nil
 }
 
 // CreateAccount creates a new account with the given ID and initial balance
 func (r *PostgresAccountRepository) CreateAccount(ctx context.Context, accountID int64, initialBalance decimal.Decimal) error {
-	logger.Info("Creating account in database: account_id=%d, initial_balance=%s", accountID, initialBalance.String())
+	r.log.InfoContext(ctx, "Creating account in database", logger.AccountID(accountID), "initial_balance", initialBalance)
 
 	// Validate initial balance
 	if initialBalance.IsNegative() {
-		logger.Warn("Invalid initial balance for account %d: %s (negative amount)", accountID, initialBalance.String())
-		return errors.ErrInvalidAmount
+		r.log.WarnContext(ctx, "Invalid initial balance for account (negative amount)", logger.AccountID(accountID), "initial_balance", initialBalance)
+		return errors.NewInvalidAmountError(initialBalance)
 	}
 
+	// The opening balance is posted to the ledger in the same statement
 	query := `
-		INSERT INTO accounts (account_id, balance)
-		VALUES ($1, $2)
+		WITH account AS (
+			INSERT INTO accounts (account_id, balance, initial_balance)
+			VALUES ($1, $2, $2)
+			RETURNING account_id, initial_balance
+		)
+		INSERT INTO ledger_entries (account_id, entry_type, amount)
+		SELECT account_id, 'credit', initial_balance FROM account WHERE initial_balance > 0
 	`
 	_, err := r.db.ExecContext(ctx, query, accountID, initialBalance)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				logger.Warn("Account already exists in database: %d", accountID)
-				return errors.ErrAccountAlreadyExists
-			case "check_constraint_violation":
-				logger.Warn("Check constraint violation for account %d: %s", accountID, initialBalance.String())
-				return errors.ErrInvalidAmount
-			}
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating account", logger.AccountID(accountID), "err", err)
+			return errors.WithAccount(domainErr, accountID)
 		}
-		logger.Error("Database error creating account %d: %v", accountID, err)
+		r.log.ErrorContext(ctx, "Database error creating account", logger.AccountID(accountID), "err", err)
 		return fmt.Errorf("failed to create account: %w", err)
 	}
 
-	logger.Info("Successfully created account in database: account_id=%d", accountID)
+	r.log.InfoContext(ctx, "Successfully created account in database", logger.AccountID(accountID))
 	return nil
 }
 
 // GetAccount retrieves an account by its ID
 func (r *PostgresAccountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
-	logger.Info("Retrieving account from database: account_id=%d", accountID)
+	r.log.InfoContext(ctx, "Retrieving account from database", logger.AccountID(accountID))
 
 	query := `
-		SELECT account_id, balance
+		SELECT ` + accountColumns + `
 		FROM accounts
 		WHERE account_id = $1
 	`
-	var account models.Account
-	err := r.db.QueryRowContext(ctx, query, accountID).Scan(&account.AccountID, &account.Balance)
+	account, err := scanAccount(r.db.QueryRowContext(ctx, query, accountID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Warn("Account not found in database: %d", accountID)
-			return nil, errors.ErrAccountNotFound
+			r.log.WarnContext(ctx, "Account not found in database", logger.AccountID(accountID))
+			return nil, errors.NewAccountNotFoundError(accountID)
 		}
-		logger.Error("Database error retrieving account %d: %v", accountID, err)
+		r.log.ErrorContext(ctx, "Database error retrieving account", logger.AccountID(accountID), "err", err)
 		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
-	logger.Info("Successfully retrieved account from database: account_id=%d, balance=%s", accountID, account.Balance.String())
-	return &account, nil
+	r.log.InfoContext(ctx, "Successfully retrieved account from database", logger.AccountID(accountID), "balance", account.Balance)
+	return account, nil
+}
+
+// GetAccountsByOwner retrieves all accounts held by an external owner reference, ordered by account ID
+func (r *PostgresAccountRepository) GetAccountsByOwner(ctx context.Context, ownerRef string) ([]*models.Account, error) {
+	r.log.InfoContext(ctx, "Retrieving accounts for owner", "owner_ref", ownerRef)
+
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts
+		WHERE owner_ref = $1
+		ORDER BY account_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, ownerRef)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving accounts for owner", "owner_ref", ownerRef, "err", err)
+		return nil, fmt.Errorf("failed to get accounts by owner: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []*models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accounts: %w", err)
+	}
+
+	r.log.InfoContext(ctx, "Successfully retrieved accounts for owner", "count", len(accounts), "owner_ref", ownerRef)
+	return accounts, nil
+}
+
+// ListAccounts retrieves up to limit accounts matching filter, in its order, starting after cursor
+// (from the first if nil). Ordered by balance, a page walks the (balance, account_id) index.
+func (r *PostgresAccountRepository) ListAccounts(ctx context.Context, filter models.AccountListFilter, limit int, after *models.AccountCursor) (*models.AccountPage, error) {
+	r.log.InfoContext(ctx, "Listing accounts", "filter", filter, "limit", limit)
+
+	comparison, direction := ">", "ASC"
+	if filter.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	// One row more than the page tells whether there is a next page
+	args := []interface{}{limit + 1}
+	conditions := []string{"TRUE"}
+	if after != nil {
+		if filter.SortKey() == models.AccountSortBalance {
+			args = append(args, after.Balance, after.AccountID)
+			conditions = append(conditions, fmt.Sprintf("(balance, account_id) %s ($%d, $%d)", comparison, len(args)-1, len(args)))
+		} else {
+			args = append(args, after.AccountID)
+			conditions = append(conditions, fmt.Sprintf("account_id %s $%d", comparison, len(args)))
+		}
+	}
+	if filter.MinBalance != nil {
+		args = append(args, *filter.MinBalance)
+		conditions = append(conditions, fmt.Sprintf("balance >= $%d", len(args)))
+	}
+	if filter.MaxBalance != nil {
+		args = append(args, *filter.MaxBalance)
+		conditions = append(conditions, fmt.Sprintf("balance <= $%d", len(args)))
+	}
+	orderBy := "account_id " + direction
+	if filter.SortKey() == models.AccountSortBalance {
+		orderBy = "balance " + direction + ", " + orderBy
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM accounts
+		WHERE %s
+		ORDER BY %s
+		LIMIT $1
+	`, accountColumns, strings.Join(conditions, " AND "), orderBy)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing accounts", "err", err)
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	defer rows.Close()
+
+	page := &models.AccountPage{Accounts: make([]*models.Account, 0, limit)}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		if len(page.Accounts) == limit {
+			page.NextCursor = accountCursor(filter, page.Accounts[limit-1]).Encode()
+			break
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accounts: %w", err)
+	}
+	return page, nil
+}
+
+// accountCursor returns the position of account in a listing ordered by filter
+func accountCursor(filter models.AccountListFilter, account *models.Account) models.AccountCursor {
+	return models.AccountCursor{
+		Sort:       filter.SortKey(),
+		Descending: filter.Descending,
+		Balance:    account.Balance,
+		AccountID:  account.AccountID,
+	}
+}
+
+// SetOwnerRef links an account to an external owner reference; an empty ref clears it
+func (r *PostgresAccountRepository) SetOwnerRef(ctx context.Context, accountID int64, ownerRef string) error {
+	r.log.InfoContext(ctx, "Setting owner of account", logger.AccountID(accountID), "owner_ref", ownerRef)
+
+	query := `
+		UPDATE accounts
+		SET owner_ref = NULLIF($1, ''), updated_at = NOW()
+		WHERE account_id = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, ownerRef, accountID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error setting owner of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account owner: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Account not found when setting owner", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	return nil
+}
+
+// SetTenant assigns an account to a tenant; an empty tenant clears it. If the tenant caps its
+// accounts, the settings of the tenant are locked while its accounts are counted, so concurrent
+// assignments can't both take the last place.
+func (r *PostgresAccountRepository) SetTenant(ctx context.Context, accountID int64, tenantID string) error {
+	r.log.InfoContext(ctx, "Setting tenant of account", logger.AccountID(accountID), "tenant_id", tenantID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if tenantID != "" {
+		var maxAccounts sql.NullInt64
+		err := tx.QueryRowContext(ctx, `
+			SELECT max_accounts
+			FROM tenant_settings
+			WHERE tenant_id = $1
+			FOR UPDATE
+		`, tenantID).Scan(&maxAccounts)
+		if err != nil && err != sql.ErrNoRows {
+			r.log.ErrorContext(ctx, "Database error retrieving account quota of tenant", "tenant_id", tenantID, "err", err)
+			return fmt.Errorf("failed to get tenant quota: %w", err)
+		}
+		if maxAccounts.Valid {
+			var others int64
+			if err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*)
+				FROM accounts
+				WHERE tenant_id = $1 AND account_id <> $2
+			`, tenantID, accountID).Scan(&others); err != nil {
+				r.log.ErrorContext(ctx, "Database error counting accounts of tenant", "tenant_id", tenantID, "err", err)
+				return fmt.Errorf("failed to count tenant accounts: %w", err)
+			}
+			if others >= maxAccounts.Int64 {
+				r.log.WarnContext(ctx, "Tenant is at its account quota", "tenant_id", tenantID, "others", others)
+				return errors.NewTenantQuotaError(accountID, models.QuotaAccounts, maxAccounts.Int64)
+			}
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET tenant_id = NULLIF($1, ''), updated_at = NOW()
+		WHERE account_id = $2
+	`, tenantID, accountID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error setting tenant of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account tenant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Account not found when setting tenant", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing account tenant: %w", err)
+	}
+	return nil
+}
+
+// SetAccountType changes the type of an account
+func (r *PostgresAccountRepository) SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error {
+	return setAccountType(ctx, r.db, accountID, accountType)
+}
+
+// SetAccountTypeWithTx changes the type of an account within a transaction
+func (r *PostgresAccountRepository) SetAccountTypeWithTx(ctx context.Context, tx *sql.Tx, accountID int64, accountType models.AccountType) error {
+	return setAccountType(ctx, tx, accountID, accountType)
+}
+
+// execer runs statements on the database or within a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// setAccountType changes the type of an account through q
+func setAccountType(ctx context.Context, q execer, accountID int64, accountType models.AccountType) error {
+	logger.Default().InfoContext(ctx, "Setting type of account", logger.AccountID(accountID), "account_type", accountType)
+
+	result, err := q.ExecContext(ctx, `
+		UPDATE accounts
+		SET account_type = $1, updated_at = NOW()
+		WHERE account_id = $2
+	`, accountType, accountID)
+	if err != nil {
+		logger.Default().ErrorContext(ctx, "Database error setting type of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account type: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		logger.Default().WarnContext(ctx, "Account not found when setting type", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	return nil
+}
+
+// SetCurrency sets the currency of an account. An account's currency can't be changed once set;
+// setting the same currency again is a no-op.
+func (r *PostgresAccountRepository) SetCurrency(ctx context.Context, accountID int64, currency string) error {
+	r.log.InfoContext(ctx, "Setting currency of account", logger.AccountID(accountID), "currency", currency)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET currency = $1, updated_at = NOW()
+		WHERE account_id = $2 AND (currency IS NULL OR currency = $1)
+	`, currency, accountID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error setting currency of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account currency: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	account, err := r.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	r.log.WarnContext(ctx, "Account already has currency", logger.AccountID(accountID), "currency", account.Currency)
+	return fmt.Errorf("%w: account %d already has currency %s", errors.ErrValidationFailed, accountID, account.Currency)
+}
+
+// MarkDormant marks up to limit active accounts without activity since inactiveSince as dormant
+// and returns their IDs. Rows locked by in-flight transfers are skipped until the next sweep.
+func (r *PostgresAccountRepository) MarkDormant(ctx context.Context, inactiveSince time.Time, limit int) ([]int64, error) {
+	r.log.InfoContext(ctx, "Marking accounts inactive as dormant", "inactive_since", inactiveSince.Format(time.RFC3339), "limit", limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE accounts
+		SET status = $1, updated_at = NOW()
+		WHERE account_id IN (
+			SELECT account_id FROM accounts
+			WHERE status = $2 AND last_activity_at < $3
+			ORDER BY account_id
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING account_id
+	`, models.AccountStatusDormant, models.AccountStatusActive, inactiveSince, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error marking dormant accounts", "err", err)
+		return nil, fmt.Errorf("failed to mark dormant accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accountIDs []int64
+	for rows.Next() {
+		var accountID int64
+		if err := rows.Scan(&accountID); err != nil {
+			return nil, fmt.Errorf("failed to scan account ID: %w", err)
+		}
+		accountIDs = append(accountIDs, accountID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dormant accounts: %w", err)
+	}
+	return accountIDs, nil
+}
+
+// ReactivateAccount returns a dormant account to active and restarts its dormancy period.
+// Reactivating an active account is a no-op; frozen and closed accounts can't be reactivated.
+func (r *PostgresAccountRepository) ReactivateAccount(ctx context.Context, accountID int64) error {
+	r.log.InfoContext(ctx, "Reactivating account", logger.AccountID(accountID))
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET status = $1, last_activity_at = NOW(), updated_at = NOW()
+		WHERE account_id = $2 AND status = $3
+	`, models.AccountStatusActive, accountID, models.AccountStatusDormant)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error reactivating account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to reactivate account: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	account, err := r.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.Status != models.AccountStatusActive {
+		r.log.WarnContext(ctx, "Account can't be reactivated from status", logger.AccountID(accountID), "status", account.Status)
+		return errors.NewAccountNotActiveError(accountID)
+	}
+	return nil
+}
+
+// SetStatus changes the status of an account as allowed by models.Account.CheckStatusChange.
+// Unfreezing restarts the dormancy period. The account is locked so a concurrent transfer can't
+// move funds into an account being closed.
+func (r *PostgresAccountRepository) SetStatus(ctx context.Context, accountID int64, status models.AccountStatus) error {
+	r.log.InfoContext(ctx, "Setting status of account", logger.AccountID(accountID), "status", status)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.SetStatusWithTx(ctx, tx, accountID, status); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing account status: %w", err)
+	}
+	return nil
+}
+
+// SetStatusWithTx changes the status of an account within a transaction, locking the account
+func (r *PostgresAccountRepository) SetStatusWithTx(ctx context.Context, tx *sql.Tx, accountID int64, status models.AccountStatus) error {
+	account, err := scanAccount(tx.QueryRowContext(ctx, `
+		SELECT `+accountColumns+`
+		FROM accounts
+		WHERE account_id = $1
+		FOR UPDATE
+	`, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Account not found in database", logger.AccountID(accountID))
+			return errors.NewAccountNotFoundError(accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if err := account.CheckStatusChange(status); err != nil {
+		r.log.WarnContext(ctx, "Account status can't be changed", logger.AccountID(accountID), "from", account.Status, "to", status, "err", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET status = $1,
+			last_activity_at = CASE WHEN $1 = 'active' THEN NOW() ELSE last_activity_at END,
+			updated_at = NOW()
+		WHERE account_id = $2
+	`, status, accountID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error setting status of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account status: %w", err)
+	}
+	return nil
+}
+
+// SetOverdraftLimit sets how far below zero the balance of an account may go. The account is
+// locked so the limit can't be lowered below a concurrent debit.
+func (r *PostgresAccountRepository) SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error {
+	r.log.InfoContext(ctx, "Setting overdraft limit of account", logger.AccountID(accountID), "limit", limit)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	account, err := scanAccount(tx.QueryRowContext(ctx, `
+		SELECT `+accountColumns+`
+		FROM accounts
+		WHERE account_id = $1
+		FOR UPDATE
+	`, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Account not found in database", logger.AccountID(accountID))
+			return errors.NewAccountNotFoundError(accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if err := account.ValidateOverdraftLimit(limit); err != nil {
+		r.log.WarnContext(ctx, "Invalid overdraft limit for account", logger.AccountID(accountID), "err", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET overdraft_limit = $1, updated_at = NOW()
+		WHERE account_id = $2
+	`, limit, accountID); err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation setting overdraft limit of account", logger.AccountID(accountID), "err", err)
+			return errors.WithAccount(domainErr, accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error setting overdraft limit of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set overdraft limit: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing overdraft limit: %w", err)
+	}
+	return nil
+}
+
+// SetLimits replaces the outbound transfer limits of an account
+func (r *PostgresAccountRepository) SetLimits(ctx context.Context, accountID int64, limits models.AccountLimits) error {
+	r.log.InfoContext(ctx, "Setting outbound limits of account", logger.AccountID(accountID))
+
+	if err := limits.Validate(); err != nil {
+		r.log.WarnContext(ctx, "Invalid outbound limits for account", logger.AccountID(accountID), "err", err)
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET per_transaction_limit = $1, daily_limit = $2, monthly_limit = $3, updated_at = NOW()
+		WHERE account_id = $4
+	`, nullDecimal(limits.PerTransaction), nullDecimal(limits.Daily), nullDecimal(limits.Monthly), accountID)
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation setting outbound limits of account", logger.AccountID(accountID), "err", err)
+			return errors.WithAccount(domainErr, accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error setting outbound limits of account", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to set account limits: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Account not found in database", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	return nil
+}
+
+// CountAccountsByStatus returns the number of accounts in each status
+func (r *PostgresAccountRepository) CountAccountsByStatus(ctx context.Context) (map[models.AccountStatus]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM accounts GROUP BY status`)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error counting accounts by status", "err", err)
+		return nil, fmt.Errorf("failed to count accounts by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.AccountStatus]int64)
+	for rows.Next() {
+		var status models.AccountStatus
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan account count: %w", err)
+		}
+		counts[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account counts: %w", err)
+	}
+	return counts, nil
+}
+
+// CountTenantAccounts returns the number of accounts assigned to a tenant
+func (r *PostgresAccountRepository) CountTenantAccounts(ctx context.Context, tenantID string) (int64, error) {
+	return countTenantAccounts(ctx, r.db, tenantID)
+}
+
+// CountTenantAccountsWithTx returns the number of accounts assigned to a tenant within a transaction
+func (r *PostgresAccountRepository) CountTenantAccountsWithTx(ctx context.Context, tx *sql.Tx, tenantID string) (int64, error) {
+	return countTenantAccounts(ctx, tx, tenantID)
+}
+
+func countTenantAccounts(ctx context.Context, q rowQuerier, tenantID string) (int64, error) {
+	var count int64
+	if err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts WHERE tenant_id = $1`, tenantID).Scan(&count); err != nil {
+		logger.Default().ErrorContext(ctx, "Database error counting accounts of tenant", "tenant_id", tenantID, "err", err)
+		return 0, fmt.Errorf("failed to count tenant accounts: %w", err)
+	}
+	return count, nil
 }
 
 // GetAccountWithTx retrieves an account by its ID within a transaction
 func (r *PostgresAccountRepository) GetAccountWithTx(ctx context.Context, tx *sql.Tx, accountID int64) (*models.Account, error) {
-	logger.Info("Retrieving account within transaction: account_id=%d", accountID)
+	r.log.InfoContext(ctx, "Retrieving account within transaction", logger.AccountID(accountID))
 
 	query := `
-		SELECT account_id, balance
+		SELECT ` + accountColumns + `
 		FROM accounts
 		WHERE account_id = $1
 	`
-	var account models.Account
-	err := tx.QueryRowContext(ctx, query, accountID).Scan(&account.AccountID, &account.Balance)
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, accountID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Warn("Account not found in database (transaction): %d", accountID)
-			return nil, errors.ErrAccountNotFound
+			r.log.WarnContext(ctx, "Account not found in database (transaction)", logger.AccountID(accountID))
+			return nil, errors.NewAccountNotFoundError(accountID)
 		}
-		logger.Error("Database error retrieving account %d (transaction): %v", accountID, err)
+		r.log.ErrorContext(ctx, "Database error retrieving account (transaction)", logger.AccountID(accountID), "err", err)
 		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
-	logger.Info("Successfully retrieved account within transaction: account_id=%d, balance=%s", accountID, account.Balance.String())
-	return &account, nil
+	r.log.InfoContext(ctx, "Successfully retrieved account within transaction", logger.AccountID(accountID), "balance", account.Balance)
+	return account, nil
 }
 
-// UpdateBalanceWithTx updates an account's balance within a transaction
-func (r *PostgresAccountRepository) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
-	logger.Info("Updating account balance within transaction: account_id=%d, new_balance=%s", accountID, newBalance.String())
+// GetAccountsWithTx retrieves the accounts with the given IDs within a transaction, ordered by
+// ID; IDs of accounts that don't exist are skipped
+func (r *PostgresAccountRepository) GetAccountsWithTx(ctx context.Context, tx *sql.Tx, accountIDs []int64) ([]*models.Account, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+accountColumns+`
+		FROM accounts
+		WHERE account_id = ANY($1)
+		ORDER BY account_id
+	`, pq.Array(accountIDs))
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving accounts (transaction)", "count", len(accountIDs), "err", err)
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+	defer rows.Close()
 
-	// Validate new balance
-	if newBalance.IsNegative() {
-		logger.Warn("Invalid new balance for account %d: %s (negative amount)", accountID, newBalance.String())
-		return errors.ErrInvalidAmount
+	accounts := []*models.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accounts: %w", err)
 	}
+	return accounts, nil
+}
+
+// UpdateBalanceWithTx updates an account's balance within a transaction. A balance below minus
+// the account's overdraft limit violates accounts_balance_check and fails with ErrInvalidAmount.
+func (r *PostgresAccountRepository) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+	r.log.InfoContext(ctx, "Updating account balance within transaction", logger.AccountID(accountID), "new_balance", newBalance)
 
 	query := `
 		UPDATE accounts
-		SET balance = $1
+		SET balance = $1, last_activity_at = NOW()
 		WHERE account_id = $2
 	`
 	result, err := tx.ExecContext(ctx, query, newBalance, accountID)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "check_constraint_violation" {
-			logger.Warn("Check constraint violation updating account %d balance: %s", accountID, newBalance.String())
-			return errors.ErrInvalidAmount
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation updating account balance", logger.AccountID(accountID), "new_balance", newBalance, "err", err)
+			return errors.WithAccount(domainErr, accountID)
 		}
-		logger.Error("Database error updating account %d balance: %v", accountID, err)
+		r.log.ErrorContext(ctx, "Database error updating account balance", logger.AccountID(accountID), "err", err)
 		return fmt.Errorf("failed to update balance: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Failed to get rows affected for account %d: %v", accountID, err)
+		r.log.ErrorContext(ctx, "Failed to get rows affected for account", logger.AccountID(accountID), "err", err)
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		logger.Warn("No rows affected when updating account %d balance", accountID)
-		return errors.ErrAccountNotFound
+		r.log.WarnContext(ctx, "No rows affected when updating account balance", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
 	}
 
-	logger.Info("Successfully updated account balance within transaction: account_id=%d, new_balance=%s", accountID, newBalance.String())
+	r.log.InfoContext(ctx, "Successfully updated account balance within transaction", logger.AccountID(accountID), "new_balance", newBalance)
+	return nil
+}
+
+// UpdateReservedWithTx updates the amount reserved on an account by active pre-authorizations within a transaction
+func (r *PostgresAccountRepository) UpdateReservedWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newReserved decimal.Decimal) error {
+	r.log.InfoContext(ctx, "Updating reserved balance within transaction", logger.AccountID(accountID), "new_reserved_balance", newReserved)
+
+	if newReserved.IsNegative() {
+		r.log.WarnContext(ctx, "Invalid reserved balance for account (negative amount)", logger.AccountID(accountID), "new_reserved_balance", newReserved)
+		return errors.NewInvalidAmountError(newReserved)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET reserved_balance = $1, updated_at = NOW()
+		WHERE account_id = $2
+	`, newReserved, accountID)
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation updating account reserved balance", logger.AccountID(accountID), "new_reserved_balance", newReserved, "err", err)
+			return errors.WithAccount(domainErr, accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error updating account reserved balance", logger.AccountID(accountID), "err", err)
+		return fmt.Errorf("failed to update reserved balance: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "No rows affected when updating account reserved balance", logger.AccountID(accountID))
+		return errors.NewAccountNotFoundError(accountID)
+	}
 	return nil
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresAccountNoteRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewAccountNoteRepository(db *sql.DB) *PostgresAccountNoteRepository {
+	return &PostgresAccountNoteRepository{db: db, log: logger.Default()}
+}
+
+// accountNoteColumns is the column list selected by every note read, in scanAccountNote order
+const accountNoteColumns = `id, account_id, body, COALESCE(case_id, ''), author, created_at`
+
+// scanAccountNote scans a row selected with accountNoteColumns
+func scanAccountNote(row rowScanner) (*models.AccountNote, error) {
+	var note models.AccountNote
+	var createdAt time.Time
+	if err := row.Scan(&note.ID, &note.AccountID, &note.Body, &note.CaseID, &note.Author, &createdAt); err != nil {
+		return nil, err
+	}
+	note.CreatedAt = createdAt.Format(time.RFC3339)
+	return &note, nil
+}
+
+// CreateAccountNote adds a note to an account; ErrAccountNotFound if the account doesn't exist
+func (r *PostgresAccountNoteRepository) CreateAccountNote(ctx context.Context, note *models.AccountNote) (*models.AccountNote, error) {
+	r.log.InfoContext(ctx, "Adding note to account", logger.AccountID(note.AccountID), "author", note.Author, "case_id", note.CaseID)
+
+	created, err := scanAccountNote(r.db.QueryRowContext(ctx, `
+		INSERT INTO account_notes (account_id, body, case_id, author)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING `+accountNoteColumns,
+		note.AccountID, note.Body, note.CaseID, note.Author))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation adding note to account", logger.AccountID(note.AccountID), "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error adding note to account", logger.AccountID(note.AccountID), "err", err)
+		return nil, fmt.Errorf("failed to create account note: %w", err)
+	}
+	return created, nil
+}
+
+// ListAccountNotes retrieves up to limit notes of an account, newest first. A non-empty caseID
+// narrows the list to the notes linked to that case.
+func (r *PostgresAccountNoteRepository) ListAccountNotes(ctx context.Context, accountID int64, caseID string, limit int) ([]*models.AccountNote, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+accountNoteColumns+`
+		FROM account_notes
+		WHERE account_id = $1 AND ($2 = '' OR case_id = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, accountID, caseID, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing notes of account", logger.AccountID(accountID), "err", err)
+		return nil, fmt.Errorf("failed to list account notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []*models.AccountNote{}
+	for rows.Next() {
+		note, err := scanAccountNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account notes: %w", err)
+	}
+	return notes, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountNoteRepository(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountNoteRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	accountID := int64(793001)
+	require.NoError(t, accountRepo.CreateAccount(ctx, accountID, decimal.NewFromInt(100)))
+
+	first, err := repo.CreateAccountNote(ctx, &models.AccountNote{AccountID: accountID, Body: "Customer disputes transfer", CaseID: "SUP-1", Author: "ops:alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "SUP-1", first.CaseID)
+	assert.NotEmpty(t, first.CreatedAt)
+
+	second, err := repo.CreateAccountNote(ctx, &models.AccountNote{AccountID: accountID, Body: "Called back", Author: "ops:bob"})
+	require.NoError(t, err)
+	assert.Empty(t, second.CaseID)
+
+	_, err = repo.CreateAccountNote(ctx, &models.AccountNote{AccountID: accountID + 1000, Body: "No such account", Author: "ops"})
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+
+	notes, err := repo.ListAccountNotes(ctx, accountID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, second.ID, notes[0].ID)
+
+	linked, err := repo.ListAccountNotes(ctx, accountID, "SUP-1", 10)
+	require.NoError(t, err)
+	require.Len(t, linked, 1)
+	assert.Equal(t, first.ID, linked[0].ID)
+}
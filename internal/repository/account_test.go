@@ -3,14 +3,19 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
 	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAccountRepository_CreateAccount(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -53,7 +58,7 @@ func TestAccountRepository_CreateAccount(t *testing.T) {
 			err := repo.CreateAccount(ctx, tt.accountID, tt.balance)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
 				// Verify account was created
@@ -67,6 +72,8 @@ func TestAccountRepository_CreateAccount(t *testing.T) {
 }
 
 func TestAccountRepository_GetAccount(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -102,7 +109,7 @@ func TestAccountRepository_GetAccount(t *testing.T) {
 			account, err := repo.GetAccount(ctx, tt.accountID)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 				assert.Nil(t, account)
 			} else {
 				assert.NoError(t, err)
@@ -115,6 +122,8 @@ func TestAccountRepository_GetAccount(t *testing.T) {
 }
 
 func TestAccountRepository_UpdateBalanceWithTx(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -165,7 +174,7 @@ func TestAccountRepository_UpdateBalanceWithTx(t *testing.T) {
 			err = repo.UpdateBalanceWithTx(ctx, tx, tt.accountID, tt.newBalance)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
 				// Commit the transaction to persist changes
@@ -182,6 +191,8 @@ func TestAccountRepository_UpdateBalanceWithTx(t *testing.T) {
 }
 
 func TestAccountRepository_DecimalPrecision(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -242,3 +253,280 @@ func TestAccountRepository_DecimalPrecision(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountRepository_GetAccountsByOwner(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []int64{3, 1, 2} {
+		assert.NoError(t, repo.CreateAccount(ctx, id, decimal.NewFromInt(id*10)))
+	}
+	assert.NoError(t, repo.SetOwnerRef(ctx, 3, "cust-42"))
+	assert.NoError(t, repo.SetOwnerRef(ctx, 1, "cust-42"))
+	assert.NoError(t, repo.SetOwnerRef(ctx, 2, "cust-7"))
+
+	accounts, err := repo.GetAccountsByOwner(ctx, "cust-42")
+	assert.NoError(t, err)
+	if assert.Len(t, accounts, 2) {
+		assert.Equal(t, int64(1), accounts[0].AccountID)
+		assert.Equal(t, int64(3), accounts[1].AccountID)
+		assert.True(t, decimal.NewFromInt(30).Equal(accounts[1].Balance))
+		assert.Equal(t, models.AccountStatusActive, accounts[1].Status)
+		assert.Equal(t, "cust-42", accounts[1].OwnerRef)
+	}
+
+	// Clearing the owner removes the account from the listing
+	assert.NoError(t, repo.SetOwnerRef(ctx, 1, ""))
+	accounts, err = repo.GetAccountsByOwner(ctx, "cust-42")
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 1)
+
+	accounts, err = repo.GetAccountsByOwner(ctx, "nobody")
+	assert.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	err = repo.SetOwnerRef(ctx, 999, "cust-42")
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_Dormancy(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	idleID, busyID := int64(550001), int64(550002)
+	assert.NoError(t, repo.CreateAccount(ctx, idleID, decimal.NewFromInt(10)))
+	assert.NoError(t, repo.CreateAccount(ctx, busyID, decimal.NewFromInt(10)))
+	_, err := db.ExecContext(ctx, `UPDATE accounts SET last_activity_at = NOW() - INTERVAL '400 days' WHERE account_id = $1`, idleID)
+	assert.NoError(t, err)
+
+	marked, err := repo.MarkDormant(ctx, time.Now().Add(-365*24*time.Hour), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{idleID}, marked)
+
+	account, err := repo.GetAccount(ctx, idleID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AccountStatusDormant, account.Status)
+
+	counts, err := repo.CountAccountsByStatus(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), counts[models.AccountStatusDormant])
+
+	// Reactivation restarts the dormancy period and is a no-op for active accounts
+	assert.NoError(t, repo.ReactivateAccount(ctx, idleID))
+	assert.NoError(t, repo.ReactivateAccount(ctx, idleID))
+	account, err = repo.GetAccount(ctx, idleID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AccountStatusActive, account.Status)
+
+	marked, err = repo.MarkDormant(ctx, time.Now().Add(-365*24*time.Hour), 100)
+	assert.NoError(t, err)
+	assert.Empty(t, marked)
+
+	_, err = db.ExecContext(ctx, `UPDATE accounts SET status = 'closed' WHERE account_id = $1`, busyID)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, repo.ReactivateAccount(ctx, busyID), errors.ErrAccountNotActive)
+	assert.ErrorIs(t, repo.ReactivateAccount(ctx, 559999), errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_SetStatus(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	fundedID, emptyID := int64(555001), int64(555002)
+	assert.NoError(t, repo.CreateAccount(ctx, fundedID, decimal.NewFromInt(10)))
+	assert.NoError(t, repo.CreateAccount(ctx, emptyID, decimal.Zero))
+
+	// Freezing and unfreezing round-trips; freezing twice conflicts
+	assert.NoError(t, repo.SetStatus(ctx, fundedID, models.AccountStatusFrozen))
+	assert.ErrorIs(t, repo.SetStatus(ctx, fundedID, models.AccountStatusFrozen), errors.ErrAccountStatusConflict)
+	account, err := repo.GetAccount(ctx, fundedID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AccountStatusFrozen, account.Status)
+	assert.NoError(t, repo.SetStatus(ctx, fundedID, models.AccountStatusActive))
+
+	// Only an account holding no funds can be closed, and it stays closed
+	assert.ErrorIs(t, repo.SetStatus(ctx, fundedID, models.AccountStatusClosed), errors.ErrAccountStatusConflict)
+	assert.NoError(t, repo.SetStatus(ctx, emptyID, models.AccountStatusClosed))
+	assert.ErrorIs(t, repo.SetStatus(ctx, emptyID, models.AccountStatusActive), errors.ErrAccountStatusConflict)
+
+	assert.ErrorIs(t, repo.SetStatus(ctx, 555999, models.AccountStatusFrozen), errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_SetOverdraftLimit(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	accountID := int64(557001)
+	assert.NoError(t, repo.CreateAccount(ctx, accountID, decimal.Zero))
+
+	// Without a limit the balance can't go negative; with one it can, down to the limit
+	updateBalance := func(balance decimal.Decimal) error {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+		defer tx.Rollback()
+		if err := repo.UpdateBalanceWithTx(ctx, tx, accountID, balance); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	assert.ErrorIs(t, updateBalance(decimal.NewFromInt(-1)), errors.ErrInvalidAmount)
+	assert.NoError(t, repo.SetOverdraftLimit(ctx, accountID, decimal.NewFromInt(100)))
+	assert.NoError(t, updateBalance(decimal.NewFromInt(-100)))
+	assert.ErrorIs(t, updateBalance(decimal.NewFromInt(-101)), errors.ErrInvalidAmount)
+
+	account, err := repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(100).Equal(account.OverdraftLimit))
+	assert.True(t, decimal.NewFromInt(-100).Equal(account.Balance))
+
+	// The limit can't be lowered below what the account is overdrawn by
+	assert.ErrorIs(t, repo.SetOverdraftLimit(ctx, accountID, decimal.NewFromInt(50)), errors.ErrValidationFailed)
+	assert.ErrorIs(t, repo.SetOverdraftLimit(ctx, 557999, decimal.NewFromInt(50)), errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_SetLimits(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	accountID := int64(558001)
+	assert.NoError(t, repo.CreateAccount(ctx, accountID, decimal.Zero))
+
+	account, err := repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.True(t, account.Limits.IsZero())
+
+	daily, monthly := decimal.NewFromInt(500), decimal.RequireFromString("10000.5")
+	assert.NoError(t, repo.SetLimits(ctx, accountID, models.AccountLimits{Daily: &daily, Monthly: &monthly}))
+	account, err = repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.Nil(t, account.Limits.PerTransaction)
+	if assert.NotNil(t, account.Limits.Daily) && assert.NotNil(t, account.Limits.Monthly) {
+		assert.True(t, daily.Equal(*account.Limits.Daily))
+		assert.True(t, monthly.Equal(*account.Limits.Monthly))
+	}
+
+	// Setting limits replaces them all
+	assert.NoError(t, repo.SetLimits(ctx, accountID, models.AccountLimits{}))
+	account, err = repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.True(t, account.Limits.IsZero())
+
+	negative := decimal.NewFromInt(-1)
+	assert.ErrorIs(t, repo.SetLimits(ctx, accountID, models.AccountLimits{Daily: &negative}), errors.ErrValidationFailed)
+	assert.ErrorIs(t, repo.SetLimits(ctx, 558999, models.AccountLimits{}), errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_SetCurrency(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	accountID := int64(560001)
+	assert.NoError(t, repo.CreateAccount(ctx, accountID, decimal.NewFromInt(1500)))
+
+	account, err := repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.Empty(t, account.Currency)
+
+	// Setting the same currency again is a no-op; changing it is rejected
+	assert.NoError(t, repo.SetCurrency(ctx, accountID, "JPY"))
+	assert.NoError(t, repo.SetCurrency(ctx, accountID, "JPY"))
+	assert.ErrorIs(t, repo.SetCurrency(ctx, accountID, "USD"), errors.ErrValidationFailed)
+
+	account, err = repo.GetAccount(ctx, accountID)
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", account.Currency)
+
+	assert.ErrorIs(t, repo.SetCurrency(ctx, 569999, "JPY"), errors.ErrAccountNotFound)
+}
+
+func TestAccountRepository_ListAccounts(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	checkListAccounts(t, NewAccountRepository(db))
+}
+
+func TestMemoryAccountRepository_ListAccounts(t *testing.T) {
+	t.Parallel()
+
+	checkListAccounts(t, NewMemoryAccountRepository(NewMemoryStore()))
+}
+
+// checkListAccounts pages through accounts in each order and checks the balance bounds
+func checkListAccounts(t *testing.T, repo AccountRepository) {
+	ctx := context.Background()
+	for id, balance := range map[int64]int64{1: 50, 2: 10, 3: 50, 4: 0, 5: 30} {
+		require.NoError(t, repo.CreateAccount(ctx, id, decimal.NewFromInt(balance)))
+	}
+
+	// pageIDs walks every page of the listing two accounts at a time
+	pageIDs := func(filter models.AccountListFilter) []int64 {
+		var ids []int64
+		var after *models.AccountCursor
+		for {
+			page, err := repo.ListAccounts(ctx, filter, 2, after)
+			require.NoError(t, err)
+			for _, account := range page.Accounts {
+				ids = append(ids, account.AccountID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			cursor, err := models.DecodeAccountCursor(page.NextCursor)
+			require.NoError(t, err)
+			after = &cursor
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, pageIDs(models.AccountListFilter{}))
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, pageIDs(models.AccountListFilter{Descending: true}))
+	assert.Equal(t, []int64{4, 2, 5, 1, 3}, pageIDs(models.AccountListFilter{Sort: models.AccountSortBalance}))
+	assert.Equal(t, []int64{3, 1, 5, 2, 4}, pageIDs(models.AccountListFilter{Sort: models.AccountSortBalance, Descending: true}))
+
+	ten, fifty := decimal.NewFromInt(10), decimal.NewFromInt(50)
+	assert.Equal(t, []int64{2, 5, 1, 3}, pageIDs(models.AccountListFilter{Sort: models.AccountSortBalance, MinBalance: &ten, MaxBalance: &fifty}))
+
+	page, err := repo.ListAccounts(ctx, models.AccountListFilter{MinBalance: &fifty}, 10, nil)
+	require.NoError(t, err)
+	assert.Len(t, page.Accounts, 2)
+	assert.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresAdjustmentRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewAdjustmentRepository(db *sql.DB) *PostgresAdjustmentRepository {
+	return &PostgresAdjustmentRepository{db: db, log: logger.Default()}
+}
+
+// adjustmentColumns is the column list selected by every adjustment read, in scanAdjustment order
+const adjustmentColumns = `id, transaction_id, account_id, direction, amount, reason_code, note, actor, created_at`
+
+// scanAdjustment scans a row selected with adjustmentColumns
+func scanAdjustment(row rowScanner) (*models.Adjustment, error) {
+	var adjustment models.Adjustment
+	var createdAt time.Time
+	err := row.Scan(
+		&adjustment.ID,
+		&adjustment.TransactionID,
+		&adjustment.AccountID,
+		&adjustment.Direction,
+		&adjustment.Amount,
+		&adjustment.ReasonCode,
+		&adjustment.Note,
+		&adjustment.Actor,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.CreatedAt = createdAt.Format(time.RFC3339)
+	return &adjustment, nil
+}
+
+// CreateAdjustmentWithTx records an adjustment within a transaction
+func (r *PostgresAdjustmentRepository) CreateAdjustmentWithTx(ctx context.Context, tx *sql.Tx, adjustment *models.Adjustment) (*models.Adjustment, error) {
+	r.log.InfoContext(ctx, "Recording adjustment", "direction", adjustment.Direction, "amount", adjustment.Amount, logger.AccountID(adjustment.AccountID), "reason", adjustment.ReasonCode, logger.TransactionID(adjustment.TransactionID))
+
+	created, err := scanAdjustment(tx.QueryRowContext(ctx, `
+		INSERT INTO balance_adjustments (transaction_id, account_id, direction, amount, reason_code, note, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+adjustmentColumns,
+		adjustment.TransactionID, adjustment.AccountID, adjustment.Direction, adjustment.Amount, adjustment.ReasonCode, adjustment.Note, adjustment.Actor))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation recording adjustment of transaction", logger.TransactionID(adjustment.TransactionID), "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error recording adjustment of transaction", logger.TransactionID(adjustment.TransactionID), "err", err)
+		return nil, fmt.Errorf("failed to create adjustment: %w", err)
+	}
+	return created, nil
+}
+
+// ListAdjustments retrieves up to limit adjustments of an account, newest first
+func (r *PostgresAdjustmentRepository) ListAdjustments(ctx context.Context, accountID int64, limit int) ([]*models.Adjustment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+adjustmentColumns+`
+		FROM balance_adjustments
+		WHERE account_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, accountID, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing adjustments of account", logger.AccountID(accountID), "err", err)
+		return nil, fmt.Errorf("failed to list adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adjustments := []*models.Adjustment{}
+	for rows.Next() {
+		adjustment, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan adjustment: %w", err)
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating adjustments: %w", err)
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustmentRepository(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAdjustmentRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	adjustmentsID, accountID := int64(795001), int64(795002)
+	require.NoError(t, accountRepo.CreateAccount(ctx, adjustmentsID, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, accountID, decimal.Zero))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	var transactionIDs []int64
+	for _, direction := range []models.EntrySide{models.EntrySideCredit, models.EntrySideDebit} {
+		transaction, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID: adjustmentsID, DestinationAccountID: accountID, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete,
+		})
+		require.NoError(t, err)
+		transactionIDs = append(transactionIDs, transaction.ID)
+
+		created, err := repo.CreateAdjustmentWithTx(ctx, tx, &models.Adjustment{
+			TransactionID: transaction.ID,
+			AccountID:     accountID,
+			Direction:     direction,
+			Amount:        decimal.NewFromInt(10),
+			ReasonCode:    models.AdjustmentReasonCorrection,
+			Note:          "ticket 42",
+			Actor:         "ops@example.com",
+		})
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+		assert.Equal(t, direction, created.Direction)
+		assert.Equal(t, "ops@example.com", created.Actor)
+		assert.NotEmpty(t, created.CreatedAt)
+	}
+	require.NoError(t, tx.Commit())
+
+	adjustments, err := repo.ListAdjustments(ctx, accountID, 10)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 2)
+	assert.Equal(t, transactionIDs[1], adjustments[0].TransactionID)
+	assert.Equal(t, models.EntrySideDebit, adjustments[0].Direction)
+
+	adjustments, err = repo.ListAdjustments(ctx, adjustmentsID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, adjustments)
+}
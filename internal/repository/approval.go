@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresApprovalRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewApprovalRepository(db *sql.DB) *PostgresApprovalRepository {
+	return &PostgresApprovalRepository{db: db, log: logger.Default()}
+}
+
+// approvalColumns is the column list selected by every approval read, in scanApproval order
+const approvalColumns = `transaction_id, status, requested_by, COALESCE(reviewed_by, ''), COALESCE(review_note, ''), reviewed_at, created_at`
+
+// scanApproval scans a row selected with approvalColumns
+func scanApproval(row rowScanner) (*models.TransferApproval, error) {
+	var approval models.TransferApproval
+	var reviewedAt sql.NullTime
+	var createdAt time.Time
+	err := row.Scan(
+		&approval.TransactionID,
+		&approval.Status,
+		&approval.RequestedBy,
+		&approval.ReviewedBy,
+		&approval.ReviewNote,
+		&reviewedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		approval.ReviewedAt = reviewedAt.Time.Format(time.RFC3339)
+	}
+	approval.CreatedAt = createdAt.Format(time.RFC3339)
+	return &approval, nil
+}
+
+// GetApproval retrieves the approval request of a transaction; ErrApprovalNotFound if it has none
+func (r *PostgresApprovalRepository) GetApproval(ctx context.Context, transactionID int64) (*models.TransferApproval, error) {
+	return getApproval(ctx, r.db, transactionID, "")
+}
+
+// GetApprovalForUpdateWithTx retrieves the approval request of a transaction and locks it for the
+// rest of the transaction
+func (r *PostgresApprovalRepository) GetApprovalForUpdateWithTx(ctx context.Context, tx *sql.Tx, transactionID int64) (*models.TransferApproval, error) {
+	return getApproval(ctx, tx, transactionID, "FOR UPDATE")
+}
+
+// getApproval retrieves an approval request through q; lock is a locking clause or empty
+func getApproval(ctx context.Context, q rowQuerier, transactionID int64, lock string) (*models.TransferApproval, error) {
+	approval, err := scanApproval(q.QueryRowContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM transfer_approvals
+		WHERE transaction_id = $1
+		`+lock, transactionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Default().WarnContext(ctx, "Transfer approval not found", logger.TransactionID(transactionID))
+			return nil, fmt.Errorf("%w: transaction %d", errors.ErrApprovalNotFound, transactionID)
+		}
+		logger.Default().ErrorContext(ctx, "Database error retrieving approval of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to get transfer approval: %w", err)
+	}
+	return approval, nil
+}
+
+// ListApprovals lists up to limit approval requests with the given status, or of any status if it
+// is empty, oldest first
+func (r *PostgresApprovalRepository) ListApprovals(ctx context.Context, status models.ApprovalStatus, limit int) ([]*models.TransferApproval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM transfer_approvals
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, transaction_id
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing transfer approvals", "err", err)
+		return nil, fmt.Errorf("failed to list transfer approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []*models.TransferApproval{}
+	for rows.Next() {
+		approval, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer approval: %w", err)
+		}
+		approvals = append(approvals, approval)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfer approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// CreateApprovalWithTx records the pending approval request of a transaction within a transaction
+func (r *PostgresApprovalRepository) CreateApprovalWithTx(ctx context.Context, tx *sql.Tx, approval *models.TransferApproval) (*models.TransferApproval, error) {
+	r.log.InfoContext(ctx, "Requesting approval of transaction", logger.TransactionID(approval.TransactionID), "requested_by", approval.RequestedBy)
+
+	created, err := scanApproval(tx.QueryRowContext(ctx, `
+		INSERT INTO transfer_approvals (transaction_id, status, requested_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+approvalColumns,
+		approval.TransactionID, models.ApprovalStatusPending, approval.RequestedBy))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation requesting approval of transaction", logger.TransactionID(approval.TransactionID), "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error requesting approval of transaction", logger.TransactionID(approval.TransactionID), "err", err)
+		return nil, fmt.Errorf("failed to create transfer approval: %w", err)
+	}
+	return created, nil
+}
+
+// ResolveApprovalWithTx marks a pending approval request approved or rejected by reviewer within
+// a transaction; ErrApprovalResolved if it isn't pending
+func (r *PostgresApprovalRepository) ResolveApprovalWithTx(ctx context.Context, tx *sql.Tx, transactionID int64, status models.ApprovalStatus, reviewer, note string) (*models.TransferApproval, error) {
+	r.log.InfoContext(ctx, "Transfer approval resolved", logger.TransactionID(transactionID), "status", status, "reviewer", reviewer)
+
+	resolved, err := scanApproval(tx.QueryRowContext(ctx, `
+		UPDATE transfer_approvals
+		SET status = $2, reviewed_by = $3, review_note = NULLIF($4, ''), reviewed_at = NOW()
+		WHERE transaction_id = $1 AND status = $5
+		RETURNING `+approvalColumns,
+		transactionID, status, reviewer, note, models.ApprovalStatusPending))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: transfer %d", errors.ErrApprovalResolved, transactionID)
+	}
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation resolving approval of transaction", logger.TransactionID(transactionID), "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error resolving approval of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to resolve transfer approval: %w", err)
+	}
+	return resolved, nil
+}
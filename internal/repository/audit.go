@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresAuditRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db, log: logger.Default()}
+}
+
+// RecordWithTx appends an entry to the audit log within a transaction
+func (r *PostgresAuditRepository) RecordWithTx(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry) error {
+	r.log.InfoContext(ctx, "Recording audit entry", "action", entry.Action, "actor", entry.Actor, logger.AccountID(entry.AccountID), logger.TransactionID(entry.TransactionID))
+
+	details := entry.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log (action, actor, account_id, transaction_id, details)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5::jsonb)
+	`, entry.Action, entry.Actor, entry.AccountID, entry.TransactionID, string(encoded))
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording audit entry", "action", entry.Action, "err", err)
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListEntries retrieves audit entries matching filter, newest first
+func (r *PostgresAuditRepository) ListEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	r.log.InfoContext(ctx, "Listing audit entries", "action", filter.Action, logger.AccountID(filter.AccountID), "limit", filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, action, actor, COALESCE(account_id, 0), COALESCE(transaction_id, 0), details, created_at
+		FROM audit_log
+		WHERE ($1 = '' OR action = $1) AND ($2 = 0 OR account_id = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, filter.Action, filter.AccountID, filter.Limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing audit entries", "err", err)
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var details []byte
+		var createdAt time.Time
+		if err := rows.Scan(&entry.ID, &entry.Action, &entry.Actor, &entry.AccountID, &entry.TransactionID, &details, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, fmt.Errorf("invalid details on audit entry %d: %w", entry.ID, err)
+		}
+		entry.CreatedAt = createdAt.Format(time.RFC3339)
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+	return entries, nil
+}
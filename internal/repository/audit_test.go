@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository_RecordAndList(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewAuditRepository(db)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.RecordWithTx(ctx, tx, &models.AuditEntry{
+		Action:    models.AuditActionMinimumBalanceOverride,
+		Actor:     "ops@example.com",
+		AccountID: 440001,
+		Details:   map[string]string{"reason": "payroll float"},
+	}))
+	require.NoError(t, repo.RecordWithTx(ctx, tx, &models.AuditEntry{Action: "other", Actor: "ops@example.com"}))
+	require.NoError(t, tx.Commit())
+
+	// A rolled back action leaves no audit entry
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.RecordWithTx(ctx, tx, &models.AuditEntry{Action: "other", Actor: "ops@example.com", AccountID: 440001}))
+	require.NoError(t, tx.Rollback())
+
+	entries, err := repo.ListEntries(ctx, models.AuditFilter{AccountID: 440001, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.AuditActionMinimumBalanceOverride, entries[0].Action)
+	assert.Equal(t, "payroll float", entries[0].Details["reason"])
+
+	entries, err = repo.ListEntries(ctx, models.AuditFilter{Action: "other", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Zero(t, entries[0].AccountID)
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/budget"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// CacheConfig bounds the account cache
+type CacheConfig struct {
+	TTL             time.Duration // how long an entry may be served; bounds staleness
+	MaxEntries      int           // least recently used entries are evicted beyond this
+	NegativeCaching bool          // also cache "account not found" results
+}
+
+// CacheStats is a snapshot of the account cache counters
+type CacheStats struct {
+	Entries         int     `json:"entries"`
+	MaxEntries      int     `json:"max_entries"`
+	TTLMillis       int64   `json:"ttl_ms"`
+	Hits            uint64  `json:"hits"`
+	NegativeHits    uint64  `json:"negative_hits"`
+	Misses          uint64  `json:"misses"`
+	HitRatio        float64 `json:"hit_ratio"`
+	Evictions       uint64  `json:"evictions"`
+	Expirations     uint64  `json:"expirations"`
+	Invalidations   uint64  `json:"invalidations"`
+	NegativeCaching bool    `json:"negative_caching"`
+}
+
+// CachedAccountRepository is an AccountRepository that caches GetAccount results in a
+// size-bounded LRU with a TTL. Balance updates and account creation invalidate the entry;
+// transaction-scoped reads always go to the underlying repository.
+//
+// An entry invalidated by an update can be re-filled by a concurrent read before the updating
+// transaction commits, so a cached balance may be stale for up to the TTL.
+type CachedAccountRepository struct {
+	AccountRepository
+	cfg CacheConfig
+
+	mu      sync.Mutex
+	entries map[int64]*list.Element
+	lru     *list.List // front is most recently used
+
+	hits, negativeHits, misses            uint64
+	evictions, expirations, invalidations uint64
+	log                                   *slog.Logger
+}
+
+type cacheEntry struct {
+	accountID int64
+	account   *models.Account // nil for a cached "not found"
+	expires   time.Time
+}
+
+// NewCachedAccountRepository creates a caching wrapper around repo
+func NewCachedAccountRepository(repo AccountRepository, cfg CacheConfig) *CachedAccountRepository {
+	return &CachedAccountRepository{
+		AccountRepository: repo,
+		cfg:               cfg,
+		entries:           make(map[int64]*list.Element),
+		lru:               list.New(),
+		log:               logger.Default(),
+	}
+}
+
+// GetAccount retrieves an account from the cache, loading it from the underlying repository on a
+// miss. A request nearly out of its latency budget doesn't refill the cache with what it loaded.
+func (r *CachedAccountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	if account, found, ok := r.lookup(accountID); ok {
+		if !found {
+			atomic.AddUint64(&r.negativeHits, 1)
+			return nil, errors.NewAccountNotFoundError(accountID)
+		}
+		atomic.AddUint64(&r.hits, 1)
+		return account, nil
+	}
+	atomic.AddUint64(&r.misses, 1)
+
+	account, err := r.AccountRepository.GetAccount(ctx, accountID)
+	if err != nil {
+		if r.cfg.NegativeCaching && stderrors.Is(err, errors.ErrAccountNotFound) && budget.Allows(ctx, budget.WorkCacheRefresh) {
+			r.store(accountID, nil)
+		}
+		return nil, err
+	}
+	if budget.Allows(ctx, budget.WorkCacheRefresh) {
+		r.store(accountID, account)
+	}
+	return account, nil
+}
+
+// CreateAccount creates the account and drops any cached "not found" for it
+func (r *CachedAccountRepository) CreateAccount(ctx context.Context, accountID int64, initialBalance decimal.Decimal) error {
+	err := r.AccountRepository.CreateAccount(ctx, accountID, initialBalance)
+	r.Invalidate(accountID)
+	return err
+}
+
+// UpdateBalanceWithTx updates the balance and drops the cached account
+func (r *CachedAccountRepository) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+	err := r.AccountRepository.UpdateBalanceWithTx(ctx, tx, accountID, newBalance)
+	r.Invalidate(accountID)
+	return err
+}
+
+// UpdateReservedWithTx updates the reserved balance and drops the cached account
+func (r *CachedAccountRepository) UpdateReservedWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newReserved decimal.Decimal) error {
+	err := r.AccountRepository.UpdateReservedWithTx(ctx, tx, accountID, newReserved)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetOwnerRef updates the owner and drops the cached account
+func (r *CachedAccountRepository) SetOwnerRef(ctx context.Context, accountID int64, ownerRef string) error {
+	err := r.AccountRepository.SetOwnerRef(ctx, accountID, ownerRef)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetTenant assigns the tenant and drops the cached account
+func (r *CachedAccountRepository) SetTenant(ctx context.Context, accountID int64, tenantID string) error {
+	err := r.AccountRepository.SetTenant(ctx, accountID, tenantID)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetAccountType changes the type and drops the cached account
+func (r *CachedAccountRepository) SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error {
+	err := r.AccountRepository.SetAccountType(ctx, accountID, accountType)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetAccountTypeWithTx changes the type within a transaction and drops the cached account
+func (r *CachedAccountRepository) SetAccountTypeWithTx(ctx context.Context, tx *sql.Tx, accountID int64, accountType models.AccountType) error {
+	err := r.AccountRepository.SetAccountTypeWithTx(ctx, tx, accountID, accountType)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetCurrency sets the currency and drops the cached account
+func (r *CachedAccountRepository) SetCurrency(ctx context.Context, accountID int64, currency string) error {
+	err := r.AccountRepository.SetCurrency(ctx, accountID, currency)
+	r.Invalidate(accountID)
+	return err
+}
+
+// MarkDormant marks accounts dormant and drops them from the cache
+func (r *CachedAccountRepository) MarkDormant(ctx context.Context, inactiveSince time.Time, limit int) ([]int64, error) {
+	accountIDs, err := r.AccountRepository.MarkDormant(ctx, inactiveSince, limit)
+	for _, accountID := range accountIDs {
+		r.Invalidate(accountID)
+	}
+	return accountIDs, err
+}
+
+// ReactivateAccount reactivates the account and drops the cached account
+func (r *CachedAccountRepository) ReactivateAccount(ctx context.Context, accountID int64) error {
+	err := r.AccountRepository.ReactivateAccount(ctx, accountID)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetStatus changes the status of the account and drops the cached account
+func (r *CachedAccountRepository) SetStatus(ctx context.Context, accountID int64, status models.AccountStatus) error {
+	err := r.AccountRepository.SetStatus(ctx, accountID, status)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetStatusWithTx changes the status of the account and drops the cached account
+func (r *CachedAccountRepository) SetStatusWithTx(ctx context.Context, tx *sql.Tx, accountID int64, status models.AccountStatus) error {
+	err := r.AccountRepository.SetStatusWithTx(ctx, tx, accountID, status)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetOverdraftLimit sets the overdraft limit and drops the cached account
+func (r *CachedAccountRepository) SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error {
+	err := r.AccountRepository.SetOverdraftLimit(ctx, accountID, limit)
+	r.Invalidate(accountID)
+	return err
+}
+
+// SetLimits sets the outbound limits and drops the cached account
+func (r *CachedAccountRepository) SetLimits(ctx context.Context, accountID int64, limits models.AccountLimits) error {
+	err := r.AccountRepository.SetLimits(ctx, accountID, limits)
+	r.Invalidate(accountID)
+	return err
+}
+
+// Warm loads the given accounts into the cache and returns the number loaded. Accounts that
+// don't exist are skipped.
+func (r *CachedAccountRepository) Warm(ctx context.Context, accountIDs []int64) (int, error) {
+	loaded := 0
+	for _, accountID := range accountIDs {
+		account, err := r.AccountRepository.GetAccount(ctx, accountID)
+		if stderrors.Is(err, errors.ErrAccountNotFound) {
+			r.log.WarnContext(ctx, "Not warming account into the cache, it doesn't exist", logger.AccountID(accountID))
+			continue
+		}
+		if err != nil {
+			return loaded, err
+		}
+		r.store(accountID, account)
+		loaded++
+	}
+	return loaded, nil
+}
+
+// Invalidate removes an account from the cache
+func (r *CachedAccountRepository) Invalidate(accountID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if elem, ok := r.entries[accountID]; ok {
+		r.removeLocked(elem)
+		r.invalidations++
+	}
+}
+
+// Stats returns a snapshot of the cache counters
+func (r *CachedAccountRepository) Stats() CacheStats {
+	r.mu.Lock()
+	stats := CacheStats{
+		Entries:         r.lru.Len(),
+		MaxEntries:      r.cfg.MaxEntries,
+		TTLMillis:       r.cfg.TTL.Milliseconds(),
+		Evictions:       r.evictions,
+		Expirations:     r.expirations,
+		Invalidations:   r.invalidations,
+		NegativeCaching: r.cfg.NegativeCaching,
+	}
+	r.mu.Unlock()
+
+	stats.Hits = atomic.LoadUint64(&r.hits)
+	stats.NegativeHits = atomic.LoadUint64(&r.negativeHits)
+	stats.Misses = atomic.LoadUint64(&r.misses)
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	return stats
+}
+
+// lookup returns a copy of the cached account; ok is false on a miss or an expired entry
+func (r *CachedAccountRepository) lookup(accountID int64) (account *models.Account, found bool, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, exists := r.entries[accountID]
+	if !exists {
+		return nil, false, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		r.removeLocked(elem)
+		r.expirations++
+		return nil, false, false
+	}
+	r.lru.MoveToFront(elem)
+	if entry.account == nil {
+		return nil, false, true
+	}
+	copied := *entry.account
+	return &copied, true, true
+}
+
+func (r *CachedAccountRepository) store(accountID int64, account *models.Account) {
+	if r.cfg.MaxEntries <= 0 || r.cfg.TTL <= 0 {
+		return
+	}
+	var copied *models.Account
+	if account != nil {
+		c := *account
+		copied = &c
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expires := time.Now().Add(r.cfg.TTL)
+	if elem, ok := r.entries[accountID]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.account, entry.expires = copied, expires
+		r.lru.MoveToFront(elem)
+		return
+	}
+
+	r.entries[accountID] = r.lru.PushFront(&cacheEntry{accountID: accountID, account: copied, expires: expires})
+	for r.lru.Len() > r.cfg.MaxEntries {
+		r.removeLocked(r.lru.Back())
+		r.evictions++
+	}
+}
+
+func (r *CachedAccountRepository) removeLocked(elem *list.Element) {
+	r.lru.Remove(elem)
+	delete(r.entries, elem.Value.(*cacheEntry).accountID)
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedAccountRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hits after first read and invalidates on update", func(t *testing.T) {
+		repo := NewCachedAccountRepository(NewMemoryAccountRepository(NewMemoryStore()), CacheConfig{TTL: time.Minute, MaxEntries: 10})
+		require.NoError(t, repo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+
+		_, err := repo.GetAccount(ctx, 1)
+		require.NoError(t, err)
+		_, err = repo.GetAccount(ctx, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.UpdateBalanceWithTx(ctx, nil, 1, decimal.NewFromInt(40)))
+		account, err := repo.GetAccount(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, account.Balance.Equal(decimal.NewFromInt(40)))
+
+		stats := repo.Stats()
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Equal(t, uint64(2), stats.Misses)
+		assert.Equal(t, uint64(1), stats.Invalidations)
+		assert.InDelta(t, 1.0/3.0, stats.HitRatio, 0.001)
+	})
+
+	t.Run("evicts least recently used beyond max entries", func(t *testing.T) {
+		repo := NewCachedAccountRepository(NewMemoryAccountRepository(NewMemoryStore()), CacheConfig{TTL: time.Minute, MaxEntries: 2})
+		for id := int64(1); id <= 3; id++ {
+			require.NoError(t, repo.CreateAccount(ctx, id, decimal.NewFromInt(10)))
+		}
+		for _, id := range []int64{1, 2, 1, 3} {
+			_, err := repo.GetAccount(ctx, id)
+			require.NoError(t, err)
+		}
+
+		stats := repo.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, uint64(1), stats.Evictions)
+
+		_, err := repo.GetAccount(ctx, 1) // still cached, 2 was evicted
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), repo.Stats().Hits)
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		repo := NewCachedAccountRepository(NewMemoryAccountRepository(NewMemoryStore()), CacheConfig{TTL: 10 * time.Millisecond, MaxEntries: 10})
+		require.NoError(t, repo.CreateAccount(ctx, 1, decimal.NewFromInt(10)))
+
+		_, err := repo.GetAccount(ctx, 1)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		_, err = repo.GetAccount(ctx, 1)
+		require.NoError(t, err)
+
+		stats := repo.Stats()
+		assert.Equal(t, uint64(0), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Expirations)
+	})
+
+	t.Run("caches not found only when enabled", func(t *testing.T) {
+		for _, negative := range []bool{false, true} {
+			repo := NewCachedAccountRepository(NewMemoryAccountRepository(NewMemoryStore()), CacheConfig{TTL: time.Minute, MaxEntries: 10, NegativeCaching: negative})
+
+			_, err := repo.GetAccount(ctx, 7)
+			assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+			_, err = repo.GetAccount(ctx, 7)
+			assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+
+			if negative {
+				assert.Equal(t, uint64(1), repo.Stats().NegativeHits)
+			} else {
+				assert.Equal(t, uint64(0), repo.Stats().NegativeHits)
+			}
+
+			// Creating the account drops the cached miss
+			require.NoError(t, repo.CreateAccount(ctx, 7, decimal.NewFromInt(5)))
+			_, err = repo.GetAccount(ctx, 7)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("warms hot accounts", func(t *testing.T) {
+		repo := NewCachedAccountRepository(NewMemoryAccountRepository(NewMemoryStore()), CacheConfig{TTL: time.Minute, MaxEntries: 10})
+		require.NoError(t, repo.CreateAccount(ctx, 1, decimal.NewFromInt(10)))
+		require.NoError(t, repo.CreateAccount(ctx, 2, decimal.NewFromInt(20)))
+
+		loaded, err := repo.Warm(ctx, []int64{1, 2, 3})
+		require.NoError(t, err)
+		assert.Equal(t, 2, loaded)
+
+		// Warmed accounts are served from the cache
+		_, err = repo.GetAccount(ctx, 2)
+		require.NoError(t, err)
+		stats := repo.Stats()
+		assert.Equal(t, 2, stats.Entries)
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Zero(t, stats.Misses)
+	})
+}
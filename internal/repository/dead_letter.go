@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresDeadLetterRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewDeadLetterRepository(db *sql.DB) *PostgresDeadLetterRepository {
+	return &PostgresDeadLetterRepository{db: db, log: logger.Default()}
+}
+
+// deadLetterColumns is the column list selected by every dead letter read, in scanDeadLetter order
+const deadLetterColumns = `id, kind, reference_id, attempts, last_error, payload, created_at`
+
+// scanDeadLetter scans a row selected with deadLetterColumns
+func scanDeadLetter(row rowScanner) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	var payload []byte
+	var createdAt time.Time
+	err := row.Scan(&letter.ID, &letter.Kind, &letter.ReferenceID, &letter.Attempts, &letter.LastError, &payload, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	letter.Payload = payload
+	letter.CreatedAt = createdAt.Format(time.RFC3339)
+	return &letter, nil
+}
+
+// CreateDeadLetter records work given up on outside any transaction
+func (r *PostgresDeadLetterRepository) CreateDeadLetter(ctx context.Context, letter *models.DeadLetter) (*models.DeadLetter, error) {
+	return createDeadLetter(ctx, r.db, letter)
+}
+
+// CreateDeadLetterWithTx records work given up on within the transaction that gave up on it
+func (r *PostgresDeadLetterRepository) CreateDeadLetterWithTx(ctx context.Context, tx *sql.Tx, letter *models.DeadLetter) (*models.DeadLetter, error) {
+	return createDeadLetter(ctx, tx, letter)
+}
+
+// createDeadLetter records a dead letter through q. Work that was already dead-lettered keeps its
+// first record, which is returned.
+func createDeadLetter(ctx context.Context, q rowQuerier, letter *models.DeadLetter) (*models.DeadLetter, error) {
+	logger.Default().WarnContext(ctx, "Dead-lettering work", "kind", letter.Kind, "reference_id", letter.ReferenceID, "attempts", letter.Attempts, "last_error", letter.LastError)
+
+	created, err := scanDeadLetter(q.QueryRowContext(ctx, `
+		INSERT INTO dead_letters (kind, reference_id, attempts, last_error, payload)
+		VALUES ($1, $2, $3, $4, $5::jsonb)
+		ON CONFLICT (kind, reference_id) DO NOTHING
+		RETURNING `+deadLetterColumns,
+		letter.Kind, letter.ReferenceID, letter.Attempts, letter.LastError, string(letter.Payload)))
+	if err == sql.ErrNoRows {
+		created, err = scanDeadLetter(q.QueryRowContext(ctx, `
+			SELECT `+deadLetterColumns+`
+			FROM dead_letters
+			WHERE kind = $1 AND reference_id = $2
+		`, letter.Kind, letter.ReferenceID))
+	}
+	if err != nil {
+		logger.Default().ErrorContext(ctx, "Database error dead-lettering", "kind", letter.Kind, "reference_id", letter.ReferenceID, "err", err)
+		return nil, fmt.Errorf("failed to create dead letter: %w", err)
+	}
+	return created, nil
+}
+
+// ListDeadLetters retrieves up to limit dead letters of the given kind, or of any kind if it is
+// empty, newest first
+func (r *PostgresDeadLetterRepository) ListDeadLetters(ctx context.Context, kind models.DeadLetterKind, limit int) ([]*models.DeadLetter, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters
+		WHERE $1 = '' OR kind = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, kind, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing dead letters", "err", err)
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	letters := []*models.DeadLetter{}
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		letters = append(letters, letter)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dead letters: %w", err)
+	}
+	return letters, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterRepository(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewDeadLetterRepository(db)
+	ctx := context.Background()
+
+	letter := &models.DeadLetter{
+		Kind:        models.DeadLetterKindScheduledTransfer,
+		ReferenceID: 7,
+		Attempts:    5,
+		LastError:   "insufficient balance",
+		Payload:     json.RawMessage(`{"id": 7}`),
+	}
+	first, err := repo.CreateDeadLetter(ctx, letter)
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.JSONEq(t, `{"id": 7}`, string(first.Payload))
+
+	// The same work is dead-lettered once
+	letter.Attempts, letter.LastError = 6, "later"
+	again, err := repo.CreateDeadLetter(ctx, letter)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 5, again.Attempts)
+
+	_, err = repo.CreateDeadLetter(ctx, &models.DeadLetter{
+		Kind: models.DeadLetterKindWebhookDelivery, ReferenceID: 7, Attempts: 3, Payload: json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	all, err := repo.ListDeadLetters(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, models.DeadLetterKindWebhookDelivery, all[0].Kind, "newest first")
+	scheduled, err := repo.ListDeadLetters(ctx, models.DeadLetterKindScheduledTransfer, 10)
+	require.NoError(t, err)
+	require.Len(t, scheduled, 1)
+	assert.Equal(t, first.ID, scheduled[0].ID)
+}
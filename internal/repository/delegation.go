@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+type PostgresDelegationRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewDelegationRepository(db *sql.DB) *PostgresDelegationRepository {
+	return &PostgresDelegationRepository{db: db, log: logger.Default()}
+}
+
+// delegationColumns is the column list selected by every delegation read, in scanDelegation order
+const delegationColumns = `id, grantor, grantee, COALESCE(source_account_id, 0), max_amount, expires_at, created_by,
+	revoked_at, created_at`
+
+// scanDelegation scans a row selected with delegationColumns
+func scanDelegation(row rowScanner) (*models.Delegation, error) {
+	var delegation models.Delegation
+	var maxAmount decimal.NullDecimal
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&delegation.ID,
+		&delegation.Grantor,
+		&delegation.Grantee,
+		&delegation.SourceAccountID,
+		&maxAmount,
+		&delegation.ExpiresAt,
+		&delegation.CreatedBy,
+		&revokedAt,
+		&delegation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if maxAmount.Valid {
+		delegation.MaxAmount = &maxAmount.Decimal
+	}
+	if revokedAt.Valid {
+		delegation.RevokedAt = &revokedAt.Time
+	}
+	return &delegation, nil
+}
+
+// CreateDelegation records a delegation from its grantor to its grantee
+func (r *PostgresDelegationRepository) CreateDelegation(ctx context.Context, delegation *models.Delegation) (*models.Delegation, error) {
+	r.log.InfoContext(ctx, "Creating delegation", "grantor", delegation.Grantor, "grantee", delegation.Grantee, "source_account_id", delegation.SourceAccountID, "expires_at", delegation.ExpiresAt.Format(time.RFC3339))
+
+	created, err := scanDelegation(r.db.QueryRowContext(ctx, `
+		INSERT INTO delegations (grantor, grantee, source_account_id, max_amount, expires_at, created_by)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6)
+		RETURNING `+delegationColumns,
+		delegation.Grantor, delegation.Grantee, delegation.SourceAccountID, nullDecimal(delegation.MaxAmount), delegation.ExpiresAt,
+		delegation.CreatedBy))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating delegation", "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error creating delegation", "err", err)
+		return nil, fmt.Errorf("failed to create delegation: %w", err)
+	}
+	return created, nil
+}
+
+// GetDelegation retrieves a delegation by its ID
+func (r *PostgresDelegationRepository) GetDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error) {
+	delegation, err := scanDelegation(r.db.QueryRowContext(ctx, `
+		SELECT `+delegationColumns+` FROM delegations WHERE id = $1
+	`, delegationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Delegation not found", "delegation_id", delegationID)
+			return nil, fmt.Errorf("%w: id %d", errors.ErrDelegationNotFound, delegationID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving delegation", "delegation_id", delegationID, "err", err)
+		return nil, fmt.Errorf("failed to get delegation: %w", err)
+	}
+	return delegation, nil
+}
+
+// ListDelegations retrieves up to limit delegations, revoked and expired ones included, newest
+// first. A non-empty grantor or grantee narrows the list to its delegations.
+func (r *PostgresDelegationRepository) ListDelegations(ctx context.Context, grantor, grantee string, limit int) ([]*models.Delegation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+delegationColumns+`
+		FROM delegations
+		WHERE ($1 = '' OR grantor = $1) AND ($2 = '' OR grantee = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`, grantor, grantee, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing delegations", "err", err)
+		return nil, fmt.Errorf("failed to list delegations: %w", err)
+	}
+	defer rows.Close()
+
+	delegations := []*models.Delegation{}
+	for rows.Next() {
+		delegation, err := scanDelegation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delegation: %w", err)
+		}
+		delegations = append(delegations, delegation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delegations: %w", err)
+	}
+	return delegations, nil
+}
+
+// RevokeDelegation revokes a delegation at now; revoking it again keeps the first revocation
+func (r *PostgresDelegationRepository) RevokeDelegation(ctx context.Context, delegationID int64, now time.Time) (*models.Delegation, error) {
+	r.log.InfoContext(ctx, "Revoking delegation", "delegation_id", delegationID)
+
+	delegation, err := scanDelegation(r.db.QueryRowContext(ctx, `
+		UPDATE delegations SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE id = $1
+		RETURNING `+delegationColumns,
+		delegationID, now))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %d", errors.ErrDelegationNotFound, delegationID)
+		}
+		r.log.ErrorContext(ctx, "Database error revoking delegation", "delegation_id", delegationID, "err", err)
+		return nil, fmt.Errorf("failed to revoke delegation: %w", err)
+	}
+	return delegation, nil
+}
+
+// FindActiveDelegationsWithTx retrieves the delegations from grantor to grantee active at now,
+// oldest first. They are share-locked, so a concurrent revocation waits for the transfer using
+// them.
+func (r *PostgresDelegationRepository) FindActiveDelegationsWithTx(ctx context.Context, tx *sql.Tx, grantor, grantee string, now time.Time) ([]*models.Delegation, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+delegationColumns+`
+		FROM delegations
+		WHERE grantor = $1 AND grantee = $2 AND revoked_at IS NULL AND expires_at > $3
+		ORDER BY id
+		FOR SHARE
+	`, grantor, grantee, now)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error finding delegations", "grantor", grantor, "grantee", grantee, "err", err)
+		return nil, fmt.Errorf("failed to find delegations: %w", err)
+	}
+	defer rows.Close()
+
+	var delegations []*models.Delegation
+	for rows.Next() {
+		delegation, err := scanDelegation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delegation: %w", err)
+		}
+		delegations = append(delegations, delegation)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating delegations: %w", err)
+	}
+	return delegations, nil
+}
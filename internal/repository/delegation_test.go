@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegationRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewDelegationRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID := int64(792001)
+	require.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromInt(100)))
+	maxAmount := decimal.NewFromInt(50)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	capped, err := repo.CreateDelegation(ctx, &models.Delegation{
+		Grantor: "cust-1", Grantee: "assistant", SourceAccountID: sourceID, MaxAmount: &maxAmount,
+		ExpiresAt: now.Add(time.Hour), CreatedBy: "ops",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sourceID, capped.SourceAccountID)
+	require.NotNil(t, capped.MaxAmount)
+	assert.True(t, maxAmount.Equal(*capped.MaxAmount))
+	assert.Nil(t, capped.RevokedAt)
+
+	open, err := repo.CreateDelegation(ctx, &models.Delegation{
+		Grantor: "cust-1", Grantee: "assistant", ExpiresAt: now.Add(time.Hour), CreatedBy: "ops",
+	})
+	require.NoError(t, err)
+	assert.Zero(t, open.SourceAccountID)
+	assert.Nil(t, open.MaxAmount)
+
+	_, err = repo.CreateDelegation(ctx, &models.Delegation{
+		Grantor: "cust-1", Grantee: "assistant", SourceAccountID: sourceID + 1000,
+		ExpiresAt: now.Add(time.Hour), CreatedBy: "ops",
+	})
+	assert.ErrorIs(t, err, errors.ErrSourceAccountNotFound)
+
+	_, err = repo.GetDelegation(ctx, open.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrDelegationNotFound)
+
+	listed, err := repo.ListDelegations(ctx, "cust-1", "", 10)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Equal(t, open.ID, listed[0].ID)
+
+	revoked, err := repo.RevokeDelegation(ctx, open.ID, now)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	again, err := repo.RevokeDelegation(ctx, open.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt))
+
+	// Only the unrevoked, unexpired delegation is active
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	active, err := repo.FindActiveDelegationsWithTx(ctx, tx, "cust-1", "assistant", now)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, capped.ID, active[0].ID)
+
+	active, err = repo.FindActiveDelegationsWithTx(ctx, tx, "cust-1", "assistant", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	require.NoError(t, tx.Rollback())
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// PostgresFeeRuleRepository implements FeeRuleRepository
+type PostgresFeeRuleRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewFeeRuleRepository creates a new fee rule repository
+func NewFeeRuleRepository(db *sql.DB) *PostgresFeeRuleRepository {
+	return &PostgresFeeRuleRepository{db: db, log: logger.Default()}
+}
+
+// feeRuleColumns is the column list selected by every fee rule read, in scanFeeRule order
+const feeRuleColumns = `id, COALESCE(account_id, 0), fee_type, value, created_at`
+
+// scanFeeRule scans a row selected with feeRuleColumns
+func scanFeeRule(row rowScanner) (*models.FeeRule, error) {
+	var rule models.FeeRule
+	var createdAt time.Time
+	if err := row.Scan(&rule.ID, &rule.AccountID, &rule.Type, &rule.Value, &createdAt); err != nil {
+		return nil, err
+	}
+	rule.CreatedAt = createdAt.Format(time.RFC3339)
+	return &rule, nil
+}
+
+// CreateFeeRule stores a new fee rule
+func (r *PostgresFeeRuleRepository) CreateFeeRule(ctx context.Context, rule *models.FeeRule) (*models.FeeRule, error) {
+	r.log.InfoContext(ctx, "Creating fee rule", "type", rule.Type, "value", rule.Value, logger.AccountID(rule.AccountID))
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := scanFeeRule(r.db.QueryRowContext(ctx, `
+		INSERT INTO fee_rules (account_id, fee_type, value)
+		VALUES (NULLIF($1, 0), $2, $3)
+		RETURNING `+feeRuleColumns,
+		rule.AccountID, rule.Type, rule.Value))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating fee rule for account", logger.AccountID(rule.AccountID), "err", err)
+			return nil, errors.WithAccount(domainErr, rule.AccountID)
+		}
+		r.log.ErrorContext(ctx, "Database error creating fee rule", "err", err)
+		return nil, fmt.Errorf("failed to create fee rule: %w", err)
+	}
+	return created, nil
+}
+
+// ListFeeRules retrieves every fee rule, global rules first, then by account and ID
+func (r *PostgresFeeRuleRepository) ListFeeRules(ctx context.Context) ([]*models.FeeRule, error) {
+	return r.queryFeeRules(ctx, `
+		SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		ORDER BY account_id NULLS FIRST, id
+	`)
+}
+
+// GetFeeRulesForAccount retrieves the rules of an account, or the global rules if it has none
+func (r *PostgresFeeRuleRepository) GetFeeRulesForAccount(ctx context.Context, accountID int64) ([]*models.FeeRule, error) {
+	rules, err := r.queryFeeRules(ctx, `
+		SELECT `+feeRuleColumns+`
+		FROM fee_rules
+		WHERE account_id = $1 OR account_id IS NULL
+		ORDER BY id
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make([]*models.FeeRule, 0, len(rules))
+	global := make([]*models.FeeRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.IsGlobal() {
+			global = append(global, rule)
+		} else {
+			own = append(own, rule)
+		}
+	}
+	if len(own) > 0 {
+		return own, nil
+	}
+	return global, nil
+}
+
+// DeleteFeeRule removes a fee rule
+func (r *PostgresFeeRuleRepository) DeleteFeeRule(ctx context.Context, id int64) error {
+	r.log.InfoContext(ctx, "Deleting fee rule", "rule_id", id)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM fee_rules WHERE id = $1`, id)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error deleting fee rule", "rule_id", id, "err", err)
+		return fmt.Errorf("failed to delete fee rule: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: fee rule %d", errors.ErrFeeRuleNotFound, id)
+	}
+	return nil
+}
+
+func (r *PostgresFeeRuleRepository) queryFeeRules(ctx context.Context, query string, args ...interface{}) ([]*models.FeeRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving fee rules", "err", err)
+		return nil, fmt.Errorf("failed to get fee rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.FeeRule{}
+	for rows.Next() {
+		rule, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fee rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fee rules: %w", err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeRuleRepository(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewFeeRuleRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.Zero))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	global, err := repo.CreateFeeRule(ctx, &models.FeeRule{Type: models.FeeTypeFlat, Value: decimal.NewFromInt(1)})
+	require.NoError(t, err)
+	assert.True(t, global.IsGlobal())
+	own, err := repo.CreateFeeRule(ctx, &models.FeeRule{AccountID: 1, Type: models.FeeTypePercentage, Value: decimal.RequireFromString("0.5")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), own.AccountID)
+
+	_, err = repo.CreateFeeRule(ctx, &models.FeeRule{AccountID: 99, Type: models.FeeTypeFlat, Value: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+	_, err = repo.CreateFeeRule(ctx, &models.FeeRule{Type: models.FeeTypeFlat, Value: decimal.Zero})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	rules, err := repo.ListFeeRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, global.ID, rules[0].ID)
+
+	// An account's own rules replace the global ones
+	rules, err = repo.GetFeeRulesForAccount(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, own.ID, rules[0].ID)
+
+	rules, err = repo.GetFeeRulesForAccount(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, global.ID, rules[0].ID)
+
+	require.NoError(t, repo.DeleteFeeRule(ctx, own.ID))
+	assert.ErrorIs(t, repo.DeleteFeeRule(ctx, own.ID), errors.ErrFeeRuleNotFound)
+	rules, err = repo.GetFeeRulesForAccount(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, global.ID, rules[0].ID)
+}
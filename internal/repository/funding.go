@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresFundingRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewFundingRepository(db *sql.DB) *PostgresFundingRepository {
+	return &PostgresFundingRepository{db: db, log: logger.Default()}
+}
+
+// floatAccountQuery selects the float account of the currency $1, or of every currency if it is
+// empty, with its balance and funding totals, in scanFloatAccount order
+const floatAccountQuery = `
+	SELECT f.currency, f.account_id, a.balance,
+		COALESCE(SUM(sf.amount) FILTER (WHERE sf.direction = 'fund'), 0),
+		COALESCE(SUM(sf.amount) FILTER (WHERE sf.direction = 'defund'), 0),
+		f.created_at
+	FROM system_float_accounts f
+	JOIN accounts a ON a.account_id = f.account_id
+	LEFT JOIN system_fundings sf ON sf.currency = f.currency
+	WHERE $1 = '' OR f.currency = $1
+	GROUP BY f.currency, f.account_id, a.balance, f.created_at
+	ORDER BY f.currency`
+
+// scanFloatAccount scans a row selected by floatAccountQuery
+func scanFloatAccount(row rowScanner) (*models.FloatAccount, error) {
+	var float models.FloatAccount
+	var createdAt time.Time
+	if err := row.Scan(&float.Currency, &float.AccountID, &float.Balance, &float.Funded, &float.Defunded, &createdAt); err != nil {
+		return nil, err
+	}
+	float.NetFunded = float.Funded.Sub(float.Defunded)
+	float.CreatedAt = createdAt.Format(time.RFC3339)
+	return &float, nil
+}
+
+// fundingColumns is the column list selected by every funding read, in scanFunding order
+const fundingColumns = `id, transaction_id, currency, direction, account_id, amount, reference, actor, created_at`
+
+// scanFunding scans a row selected with fundingColumns
+func scanFunding(row rowScanner) (*models.Funding, error) {
+	var funding models.Funding
+	var createdAt time.Time
+	err := row.Scan(
+		&funding.ID,
+		&funding.TransactionID,
+		&funding.Currency,
+		&funding.Direction,
+		&funding.AccountID,
+		&funding.Amount,
+		&funding.Reference,
+		&funding.Actor,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	funding.CreatedAt = createdAt.Format(time.RFC3339)
+	return &funding, nil
+}
+
+// ListFloatAccounts retrieves the float account of every currency with its balance and funding
+// totals, ordered by currency
+func (r *PostgresFundingRepository) ListFloatAccounts(ctx context.Context) ([]*models.FloatAccount, error) {
+	rows, err := r.db.QueryContext(ctx, floatAccountQuery, "")
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing system float accounts", "err", err)
+		return nil, fmt.Errorf("failed to list system float accounts: %w", err)
+	}
+	defer rows.Close()
+
+	floats := []*models.FloatAccount{}
+	for rows.Next() {
+		float, err := scanFloatAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan system float account: %w", err)
+		}
+		floats = append(floats, float)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating system float accounts: %w", err)
+	}
+	return floats, nil
+}
+
+// GetFloatAccount retrieves the float account of a currency with its balance and funding totals;
+// ErrFloatAccountNotFound if the currency has none
+func (r *PostgresFundingRepository) GetFloatAccount(ctx context.Context, currency string) (*models.FloatAccount, error) {
+	return getFloatAccount(ctx, r.db, currency)
+}
+
+// GetFloatAccountWithTx retrieves the float account of a currency within a transaction
+func (r *PostgresFundingRepository) GetFloatAccountWithTx(ctx context.Context, tx *sql.Tx, currency string) (*models.FloatAccount, error) {
+	return getFloatAccount(ctx, tx, currency)
+}
+
+// getFloatAccount retrieves the float account of a currency through q
+func getFloatAccount(ctx context.Context, q rowQuerier, currency string) (*models.FloatAccount, error) {
+	float, err := scanFloatAccount(q.QueryRowContext(ctx, floatAccountQuery, currency))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Default().WarnContext(ctx, "System float account not found", "currency", currency)
+			return nil, fmt.Errorf("%w: currency %s", errors.ErrFloatAccountNotFound, currency)
+		}
+		logger.Default().ErrorContext(ctx, "Database error retrieving system float account", "currency", currency, "err", err)
+		return nil, fmt.Errorf("failed to get system float account: %w", err)
+	}
+	return float, nil
+}
+
+// CreateFloatAccountWithTx designates an account as the float account of a currency within a
+// transaction. The account must have no transactions, so its whole history is funding history;
+// ErrFloatAccountExists if the currency already has a float account.
+func (r *PostgresFundingRepository) CreateFloatAccountWithTx(ctx context.Context, tx *sql.Tx, currency string, accountID int64) error {
+	r.log.InfoContext(ctx, "Designating account as a system float account", logger.AccountID(accountID), "currency", currency)
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO system_float_accounts (currency, account_id)
+		SELECT $1, $2
+		WHERE NOT EXISTS (
+			SELECT 1 FROM transactions WHERE source_account_id = $2 OR destination_account_id = $2
+		)
+	`, currency, accountID)
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation designating account as a float account", logger.AccountID(accountID), "currency", currency, "err", err)
+			return domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error designating account as a float account", logger.AccountID(accountID), "currency", currency, "err", err)
+		return fmt.Errorf("failed to create system float account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Account has transactions, not designating it as a float account", logger.AccountID(accountID), "currency", currency)
+		return fmt.Errorf("%w: account %d already has transactions", errors.ErrValidationFailed, accountID)
+	}
+	return nil
+}
+
+// CreateFundingWithTx records a funding within a transaction
+func (r *PostgresFundingRepository) CreateFundingWithTx(ctx context.Context, tx *sql.Tx, funding *models.Funding) (*models.Funding, error) {
+	r.log.InfoContext(ctx, "Recording funding", "direction", funding.Direction, "amount", funding.Amount, "currency", funding.Currency, logger.AccountID(funding.AccountID), logger.TransactionID(funding.TransactionID))
+
+	created, err := scanFunding(tx.QueryRowContext(ctx, `
+		INSERT INTO system_fundings (transaction_id, currency, direction, account_id, amount, reference, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+fundingColumns,
+		funding.TransactionID, funding.Currency, funding.Direction, funding.AccountID, funding.Amount, funding.Reference, funding.Actor))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation recording funding of transaction", logger.TransactionID(funding.TransactionID), "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error recording funding of transaction", logger.TransactionID(funding.TransactionID), "err", err)
+		return nil, fmt.Errorf("failed to create funding: %w", err)
+	}
+	return created, nil
+}
+
+// ListFundings retrieves up to limit fundings of a currency, newest first
+func (r *PostgresFundingRepository) ListFundings(ctx context.Context, currency string, limit int) ([]*models.Funding, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+fundingColumns+`
+		FROM system_fundings
+		WHERE currency = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, currency, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing fundings", "currency", currency, "err", err)
+		return nil, fmt.Errorf("failed to list fundings: %w", err)
+	}
+	defer rows.Close()
+
+	fundings := []*models.Funding{}
+	for rows.Next() {
+		funding, err := scanFunding(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan funding: %w", err)
+		}
+		fundings = append(fundings, funding)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fundings: %w", err)
+	}
+	return fundings, nil
+}
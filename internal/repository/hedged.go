@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// HedgedAccountRepository is an AccountRepository whose GetAccount hedges slow primary reads
+// with a second read against a replica. All other methods go to the primary.
+//
+// The primary query starts immediately; if it hasn't answered after the hedge delay, the same
+// query is sent to the replica and whichever succeeds first wins. A "not found" from the replica
+// is not trusted (the account may not have replicated yet), so it waits for the primary instead.
+type HedgedAccountRepository struct {
+	AccountRepository
+	replica AccountRepository
+	delay   time.Duration
+	log     *slog.Logger
+}
+
+// NewHedgedAccountRepository creates a repository hedging primary reads against replica after delay
+func NewHedgedAccountRepository(primary, replica AccountRepository, delay time.Duration) *HedgedAccountRepository {
+	return &HedgedAccountRepository{
+		AccountRepository: primary,
+		replica:           replica,
+		delay:             delay,
+		log:               logger.Default(),
+	}
+}
+
+type accountResult struct {
+	account *models.Account
+	err     error
+	replica bool
+}
+
+// GetAccount retrieves an account from whichever of primary and replica answers first
+func (r *HedgedAccountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // abandons the slower query
+
+	results := make(chan accountResult, 2)
+	go func() {
+		account, err := r.AccountRepository.GetAccount(ctx, accountID)
+		results <- accountResult{account: account, err: err}
+	}()
+
+	timer := time.NewTimer(r.delay)
+	defer timer.Stop()
+
+	pending := 1
+	hedged := false
+	var primaryErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				if logger.Enabled(slog.LevelDebug) {
+					r.log.DebugContext(ctx, "Hedging read of account to replica", logger.AccountID(accountID), "delay", r.delay)
+				}
+				go func() {
+					account, err := r.replica.GetAccount(ctx, accountID)
+					results <- accountResult{account: account, err: err, replica: true}
+				}()
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				if res.replica {
+					if logger.Enabled(slog.LevelDebug) {
+						r.log.DebugContext(ctx, "Hedged read of account answered by replica", logger.AccountID(accountID))
+					}
+				}
+				return res.account, nil
+			}
+			if !res.replica {
+				// Domain errors from the primary are authoritative
+				var domainErr *errors.Error
+				if stderrors.As(res.err, &domainErr) {
+					return nil, res.err
+				}
+				primaryErr = res.err
+				if !hedged {
+					// Primary failed outright: go to the replica now rather than after the delay
+					hedged = true
+					pending++
+					go func() {
+						account, err := r.replica.GetAccount(ctx, accountID)
+						results <- accountResult{account: account, err: err, replica: true}
+					}()
+				}
+			} else {
+				if logger.Enabled(slog.LevelDebug) {
+					r.log.DebugContext(ctx, "Hedged replica read of account failed", logger.AccountID(accountID), "err", res.err)
+				}
+			}
+		}
+	}
+
+	if primaryErr != nil {
+		return nil, primaryErr
+	}
+	return nil, errors.NewAccountNotFoundError(accountID)
+}
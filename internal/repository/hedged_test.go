@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowAccountRepository delays or fails GetAccount on top of an in-memory repository
+type slowAccountRepository struct {
+	AccountRepository
+	delay time.Duration
+	err   error
+}
+
+func (r *slowAccountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.AccountRepository.GetAccount(ctx, accountID)
+}
+
+func TestHedgedAccountRepository_GetAccount(t *testing.T) {
+	ctx := context.Background()
+	primaryStore, replicaStore := NewMemoryStore(), NewMemoryStore()
+	require.NoError(t, NewMemoryAccountRepository(primaryStore).CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, NewMemoryAccountRepository(replicaStore).CreateAccount(ctx, 1, decimal.NewFromInt(90)))
+
+	tests := []struct {
+		name           string
+		primaryDelay   time.Duration
+		primaryErr     error
+		replicaDelay   time.Duration
+		replicaErr     error
+		accountID      int64
+		expectedErr    error
+		expectedAmount int64
+	}{
+		{name: "fast primary", primaryDelay: 0, replicaDelay: 0, accountID: 1, expectedAmount: 100},
+		{name: "slow primary hedged to replica", primaryDelay: 500 * time.Millisecond, replicaDelay: 0, accountID: 1, expectedAmount: 90},
+		{name: "slow replica loses", primaryDelay: 30 * time.Millisecond, replicaDelay: 500 * time.Millisecond, accountID: 1, expectedAmount: 100},
+		{name: "failed primary falls back to replica", primaryErr: fmt.Errorf("connection reset"), accountID: 1, expectedAmount: 90},
+		{name: "failed replica falls back to primary", primaryDelay: 50 * time.Millisecond, replicaErr: fmt.Errorf("connection reset"), accountID: 1, expectedAmount: 100},
+		{name: "not found on primary is authoritative", accountID: 2, expectedErr: errors.ErrAccountNotFound},
+		{name: "not found on replica waits for primary", primaryDelay: 50 * time.Millisecond, replicaErr: errors.NewAccountNotFoundError(1), accountID: 1, expectedAmount: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewHedgedAccountRepository(
+				&slowAccountRepository{AccountRepository: NewMemoryAccountRepository(primaryStore), delay: tt.primaryDelay, err: tt.primaryErr},
+				&slowAccountRepository{AccountRepository: NewMemoryAccountRepository(replicaStore), delay: tt.replicaDelay, err: tt.replicaErr},
+				10*time.Millisecond,
+			)
+
+			account, err := repo.GetAccount(ctx, tt.accountID)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, account.Balance.Equal(decimal.NewFromInt(tt.expectedAmount)), "balance %s", account.Balance)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresIdempotencyRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewIdempotencyRepository(db *sql.DB) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{db: db, log: logger.Default()}
+}
+
+// GetIdempotencyRecord retrieves the record stored under an idempotency key of owner, whether or
+// not it expired; ErrTransactionNotFound if owner hasn't used the key or its record was deleted
+func (r *PostgresIdempotencyRepository) GetIdempotencyRecord(ctx context.Context, owner, key string) (*models.IdempotencyRecord, error) {
+	record := models.IdempotencyRecord{Owner: owner, Key: key}
+	var response, sealedResponse []byte
+	var expiresAt sql.NullTime
+	var createdAt time.Time
+	err := r.db.QueryRowContext(ctx, `
+		SELECT fingerprint, transaction_id, response, sealed_response, expires_at, created_at
+		FROM idempotency_keys
+		WHERE owner = $1 AND idempotency_key = $2
+	`, owner, key).Scan(&record.Fingerprint, &record.TransactionID, &response, &sealedResponse, &expiresAt, &createdAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: idempotency key %q", errors.ErrTransactionNotFound, key)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving idempotency key", "key", key, "err", err)
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	record.Response, record.Sealed = response, sealedResponse != nil
+	if record.Sealed {
+		record.Response = sealedResponse
+	}
+	if expiresAt.Valid {
+		record.ExpiresAt = &expiresAt.Time
+	}
+	record.CreatedAt = createdAt.Format(time.RFC3339)
+	return &record, nil
+}
+
+// CreateIdempotencyRecordWithTx stores the outcome of a transfer under its owner's idempotency key
+// within the transaction that made the transfer, replacing a record of the key that expired by now.
+// ErrDuplicateIdempotencyKey if a concurrent request stored the key first or its record is live.
+func (r *PostgresIdempotencyRepository) CreateIdempotencyRecordWithTx(ctx context.Context, tx *sql.Tx, record *models.IdempotencyRecord, now time.Time) error {
+	r.log.InfoContext(ctx, "Storing idempotency key for transaction", "key", record.Key, logger.TransactionID(record.TransactionID))
+
+	var response, sealedResponse interface{}
+	if record.Sealed {
+		sealedResponse = record.Response
+	} else {
+		response = string(record.Response)
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (owner, idempotency_key, fingerprint, transaction_id, response, sealed_response, expires_at)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6, $7)
+		ON CONFLICT (owner, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+			transaction_id = EXCLUDED.transaction_id,
+			response = EXCLUDED.response,
+			sealed_response = EXCLUDED.sealed_response,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW()
+		WHERE idempotency_keys.expires_at <= $8
+	`, record.Owner, record.Key, record.Fingerprint, record.TransactionID, response, sealedResponse, record.ExpiresAt, now)
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation storing idempotency key", "key", record.Key, "err", err)
+			return domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error storing idempotency key", "key", record.Key, "err", err)
+		return fmt.Errorf("failed to store idempotency record: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Idempotency key is already in use", "key", record.Key)
+		return fmt.Errorf("%w: key %q", errors.ErrDuplicateIdempotencyKey, record.Key)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords deletes up to limit records that expired by now and returns
+// how many it deleted
+func (r *PostgresIdempotencyRepository) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time, limit int) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE (owner, idempotency_key) IN (
+			SELECT owner, idempotency_key
+			FROM idempotency_keys
+			WHERE expires_at <= $1
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`, now, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error deleting expired idempotency keys", "err", err)
+		return 0, fmt.Errorf("failed to delete expired idempotency records: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if deleted > 0 {
+		r.log.InfoContext(ctx, "Deleted expired idempotency keys", "deleted", deleted)
+	}
+	return int(deleted), nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository_CreateAndGet(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewIdempotencyRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(800001), int64(800002)
+	for _, id := range []int64{sourceID, destID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	amount := decimal.NewFromInt(10)
+
+	_, err := repo.GetIdempotencyRecord(ctx, "", "retry-1")
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	created, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount, Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	record := &models.IdempotencyRecord{
+		Key:           "retry-1",
+		Fingerprint:   models.TransferFingerprint(sourceID, destID, amount),
+		TransactionID: created.ID,
+		Response:      []byte(`{"id": 1}`),
+	}
+	require.NoError(t, repo.CreateIdempotencyRecordWithTx(ctx, tx, record, time.Now()))
+	require.NoError(t, tx.Commit())
+
+	stored, err := repo.GetIdempotencyRecord(ctx, "", "retry-1")
+	require.NoError(t, err)
+	assert.Equal(t, record.Fingerprint, stored.Fingerprint)
+	assert.Equal(t, created.ID, stored.TransactionID)
+	assert.JSONEq(t, `{"id": 1}`, string(stored.Response))
+
+	// Another owner's key of the same name is a different key
+	_, err = repo.GetIdempotencyRecord(ctx, "7", "retry-1")
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	owned := *record
+	owned.Owner = "7"
+	require.NoError(t, repo.CreateIdempotencyRecordWithTx(ctx, tx, &owned, time.Now()))
+	require.NoError(t, tx.Commit())
+	stored, err = repo.GetIdempotencyRecord(ctx, "7", "retry-1")
+	require.NoError(t, err)
+	assert.Equal(t, "7", stored.Owner)
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	assert.ErrorIs(t, repo.CreateIdempotencyRecordWithTx(ctx, tx, record, time.Now()), errors.ErrDuplicateIdempotencyKey)
+}
+
+func TestIdempotencyRepository_Expiry(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewIdempotencyRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(800011), int64(800012)
+	for _, id := range []int64{sourceID, destID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	amount := decimal.NewFromInt(10)
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	store := func(record *models.IdempotencyRecord, at time.Time) error {
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		defer tx.Rollback()
+		created, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount, Status: models.TransactionStatusComplete,
+		})
+		require.NoError(t, err)
+		record.TransactionID = created.ID
+		if err := repo.CreateIdempotencyRecordWithTx(ctx, tx, record, at); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	sealed := &models.IdempotencyRecord{
+		Key:         "retry-ttl",
+		Fingerprint: models.TransferFingerprint(sourceID, destID, amount),
+		Response:    []byte{1, 2, 3},
+		Sealed:      true,
+		ExpiresAt:   &expiresAt,
+	}
+	require.NoError(t, store(sealed, now))
+
+	stored, err := repo.GetIdempotencyRecord(ctx, "", "retry-ttl")
+	require.NoError(t, err)
+	assert.True(t, stored.Sealed)
+	assert.Equal(t, []byte{1, 2, 3}, stored.Response)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.WithinDuration(t, expiresAt, *stored.ExpiresAt, time.Millisecond)
+
+	// A live record can't be replaced, an expired one can
+	replacement := &models.IdempotencyRecord{
+		Key:         "retry-ttl",
+		Fingerprint: models.TransferFingerprint(destID, sourceID, amount),
+		Response:    []byte(`{"id": 2}`),
+	}
+	assert.ErrorIs(t, store(replacement, now), errors.ErrDuplicateIdempotencyKey)
+	require.NoError(t, store(replacement, expiresAt))
+
+	stored, err = repo.GetIdempotencyRecord(ctx, "", "retry-ttl")
+	require.NoError(t, err)
+	assert.False(t, stored.Sealed)
+	assert.Nil(t, stored.ExpiresAt)
+	assert.Equal(t, replacement.TransactionID, stored.TransactionID)
+
+	expired := &models.IdempotencyRecord{
+		Key:         "retry-purge",
+		Fingerprint: models.TransferFingerprint(sourceID, destID, amount),
+		Response:    []byte(`{"id": 3}`),
+		ExpiresAt:   &now,
+	}
+	require.NoError(t, store(expired, now))
+
+	deleted, err := repo.DeleteExpiredIdempotencyRecords(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = repo.GetIdempotencyRecord(ctx, "", "retry-purge")
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+	_, err = repo.GetIdempotencyRecord(ctx, "", "retry-ttl")
+	assert.NoError(t, err)
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
 	"github.com/shopspring/decimal"
 )
 
@@ -23,15 +25,85 @@ type AccountRepository interface {
 	// This is a standalone operation for reading account data
 	GetAccount(ctx context.Context, accountID int64) (*models.Account, error)
 
+	// GetAccountsByOwner retrieves all accounts held by an external owner reference, ordered by account ID
+	GetAccountsByOwner(ctx context.Context, ownerRef string) ([]*models.Account, error)
+
+	// ListAccounts retrieves up to limit accounts matching filter, in its order, starting after
+	// cursor (from the first if nil)
+	ListAccounts(ctx context.Context, filter models.AccountListFilter, limit int, after *models.AccountCursor) (*models.AccountPage, error)
+
+	// SetOwnerRef links an account to an external owner reference; an empty ref clears it
+	SetOwnerRef(ctx context.Context, accountID int64, ownerRef string) error
+
+	// SetTenant assigns an account to a tenant, whose settings then apply to it; an empty tenant
+	// clears it. It fails with ErrTenantQuotaExceeded if the tenant already has its maximum of accounts.
+	SetTenant(ctx context.Context, accountID int64, tenantID string) error
+
+	// SetAccountType changes the type of an account
+	SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error
+
+	// SetCurrency sets the ISO 4217 currency of an account; it can't be changed once set
+	SetCurrency(ctx context.Context, accountID int64, currency string) error
+
+	// MarkDormant marks up to limit active accounts without activity since inactiveSince as dormant
+	// and returns their IDs
+	MarkDormant(ctx context.Context, inactiveSince time.Time, limit int) ([]int64, error)
+
+	// ReactivateAccount returns a dormant account to active; a no-op for active accounts
+	ReactivateAccount(ctx context.Context, accountID int64) error
+
+	// SetStatus freezes, unfreezes or closes an account; see models.Account.CheckStatusChange
+	SetStatus(ctx context.Context, accountID int64, status models.AccountStatus) error
+
+	// SetOverdraftLimit sets how far below zero the balance of an account may go; it fails with
+	// ErrValidationFailed if the account is already overdrawn by more than limit
+	SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error
+
+	// SetLimits replaces the outbound transfer limits of an account
+	SetLimits(ctx context.Context, accountID int64, limits models.AccountLimits) error
+
+	// CountAccountsByStatus returns the number of accounts in each status
+	CountAccountsByStatus(ctx context.Context) (map[models.AccountStatus]int64, error)
+
+	// CountTenantAccounts returns the number of accounts assigned to a tenant
+	CountTenantAccounts(ctx context.Context, tenantID string) (int64, error)
+
+	// CountTenantAccountsWithTx returns the number of accounts assigned to a tenant within a transaction
+	CountTenantAccountsWithTx(ctx context.Context, tx *sql.Tx, tenantID string) (int64, error)
+
 	// Transaction-aware methods - used within database transactions for atomic operations
 
 	// GetAccountWithTx retrieves an account by its ID within a transaction
 	// Used when account data is needed as part of a larger atomic operation
 	GetAccountWithTx(ctx context.Context, tx *sql.Tx, accountID int64) (*models.Account, error)
 
+	// GetAccountsWithTx retrieves the accounts with the given IDs within a transaction, ordered by
+	// ID; IDs of accounts that don't exist are skipped
+	GetAccountsWithTx(ctx context.Context, tx *sql.Tx, accountIDs []int64) ([]*models.Account, error)
+
 	// UpdateBalanceWithTx updates an account's balance within a transaction
 	// Used for balance updates that must be atomic (e.g., during transfers)
 	UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error
+
+	// SetStatusWithTx changes the status of an account within a transaction, locking the account
+	SetStatusWithTx(ctx context.Context, tx *sql.Tx, accountID int64, status models.AccountStatus) error
+
+	// SetAccountTypeWithTx changes the type of an account within a transaction
+	SetAccountTypeWithTx(ctx context.Context, tx *sql.Tx, accountID int64, accountType models.AccountType) error
+
+	// UpdateReservedWithTx updates the amount reserved on an account by active pre-authorizations
+	// within a transaction
+	UpdateReservedWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newReserved decimal.Decimal) error
+}
+
+// AccountNoteRepository defines the interface for the operator notes on accounts
+type AccountNoteRepository interface {
+	// CreateAccountNote adds a note to an account; ErrAccountNotFound if the account doesn't exist
+	CreateAccountNote(ctx context.Context, note *models.AccountNote) (*models.AccountNote, error)
+
+	// ListAccountNotes retrieves up to limit notes of an account, newest first, narrowed to a
+	// support case if caseID isn't empty
+	ListAccountNotes(ctx context.Context, accountID int64, caseID string, limit int) ([]*models.AccountNote, error)
 }
 
 // TransactionRepository defines the interface for transaction-related database operations
@@ -44,10 +116,488 @@ type TransactionRepository interface {
 	// This is a standalone read operation that doesn't require transaction context
 	GetTransactionsByAccount(ctx context.Context, accountID int64) ([]*models.Transaction, error)
 
+	// GetTransactionsByAccountPage retrieves up to limit of an account's transactions matching
+	// filter, ordered by (created_at, id) newest first and starting after the cursor (from the
+	// newest if nil)
+	GetTransactionsByAccountPage(ctx context.Context, accountID int64, filter models.TransactionFilter, limit int, after *models.TransactionCursor) (*models.TransactionPage, error)
+
+	// GetTransactionByID retrieves a transaction by its ID; ErrTransactionNotFound if there is none
+	GetTransactionByID(ctx context.Context, transactionID int64) (*models.Transaction, error)
+
+	// GetFeeTransactions retrieves the fees collected for a transaction, oldest first
+	GetFeeTransactions(ctx context.Context, transactionID int64) ([]*models.Transaction, error)
+
+	// MarkTransactionReversedWithTx marks a completed transaction as reversed within a database
+	// transaction; ErrTransactionNotReversible if it isn't complete or is itself a reversal
+	MarkTransactionReversedWithTx(ctx context.Context, tx *sql.Tx, transactionID int64) (*models.Transaction, error)
+
+	// ResolvePendingApprovalWithTx records the outcome of a transaction held in pending_approval
+	// within a database transaction: its status, and for a completed transfer the destination
+	// credited, business date and conversion. ErrApprovalResolved if it isn't pending approval.
+	ResolvePendingApprovalWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error)
+
+	// GetStatusHistory retrieves a transaction's status changes, oldest first
+	GetStatusHistory(ctx context.Context, transactionID int64) ([]*models.TransactionStatusChange, error)
+
+	// AddStatusChangeWithTx appends an entry to a transaction's status history within a transaction
+	AddStatusChangeWithTx(ctx context.Context, tx *sql.Tx, change *models.TransactionStatusChange) error
+
+	// GetTransactionsByAccountInPeriod retrieves an account's transactions in [from, to) on the given time axis
+	// (recording time or value date), oldest first. Used to build statements.
+	GetTransactionsByAccountInPeriod(ctx context.Context, accountID int64, from, to time.Time, axis models.TimeAxis) ([]*models.Transaction, error)
+
+	// GetBalanceAsOf computes an account's balance at the given instant on the given time axis
+	GetBalanceAsOf(ctx context.Context, accountID int64, at time.Time, axis models.TimeAxis) (decimal.Decimal, error)
+
+	// CreateBalanceSnapshots records the balance as of asOf of up to limit accounts without a
+	// snapshot at that instant and returns the number recorded
+	CreateBalanceSnapshots(ctx context.Context, asOf time.Time, limit int) (int, error)
+
+	// SearchTransactionsByTag retrieves up to limit transactions carrying tag recorded in [from, to),
+	// newest first. A zero from or to leaves that end of the range open.
+	SearchTransactionsByTag(ctx context.Context, tag string, from, to time.Time, limit int) ([]*models.Transaction, error)
+
+	// GetTransactionsByBusinessDate retrieves up to limit transactions booked on a business date, oldest first
+	GetTransactionsByBusinessDate(ctx context.Context, businessDate string, limit int) ([]*models.Transaction, error)
+
+	// ExportTransactions retrieves up to limit transactions of scope recorded before until with an ID
+	// above afterID, by ID
+	ExportTransactions(ctx context.Context, scope models.ExportScope, until time.Time, afterID int64, limit int) ([]*models.Transaction, error)
+
+	// GetBusinessDaySummaries sums the completed transactions booked on each business date in [from, to],
+	// oldest first. Dates without transactions are omitted.
+	GetBusinessDaySummaries(ctx context.Context, from, to string) ([]*models.BusinessDaySummary, error)
+
+	// SetTransactionTags replaces the tags of a transaction
+	SetTransactionTags(ctx context.Context, transactionID int64, tags []string) error
+
+	// GetTransactionByBatchItemKey retrieves the transaction recorded for a batch item (see
+	// models.Batch.ItemKey); ErrTransactionNotFound if there is none
+	GetTransactionByBatchItemKey(ctx context.Context, key string) (*models.Transaction, error)
+
+	// GetTransactionByExternalReference retrieves the transaction recorded with an external reference;
+	// ErrTransactionNotFound if there is none
+	GetTransactionByExternalReference(ctx context.Context, reference string) (*models.Transaction, error)
+
+	// RegisterBatch records a batch under its owner and key, or returns the batch the owner already
+	// registered under the key
+	RegisterBatch(ctx context.Context, batch *models.Batch) (*models.Batch, error)
+
+	// GetSplitTransfer retrieves a split transfer with its legs in the order they were made;
+	// ErrSplitTransferNotFound if there is none
+	GetSplitTransfer(ctx context.Context, splitID int64) (*models.SplitTransfer, error)
+
 	// Transaction-aware methods - used within database transactions for atomic operations
 
+	// GetTransactionByBatchItemKeyWithTx retrieves the transaction recorded for a batch item within
+	// a transaction; ErrTransactionNotFound if there is none
+	GetTransactionByBatchItemKeyWithTx(ctx context.Context, tx *sql.Tx, key string) (*models.Transaction, error)
+
+	// GetTransactionByExternalReferenceWithTx retrieves the transaction recorded with an external reference
+	// within a transaction; ErrTransactionNotFound if there is none
+	GetTransactionByExternalReferenceWithTx(ctx context.Context, tx *sql.Tx, reference string) (*models.Transaction, error)
+
 	// CreateTransactionWithTx creates a transaction record within a database transaction
 	// Used when recording transactions as part of a larger atomic operation (e.g., during transfers)
 	// Returns the created transaction with the generated ID and timestamp
 	CreateTransactionWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error)
+
+	// CreateSplitTransferWithTx records a split transfer within a database transaction and returns
+	// it with its ID; its legs are recorded with CreateTransactionWithTx in the same transaction
+	CreateSplitTransferWithTx(ctx context.Context, tx *sql.Tx, split *models.SplitTransfer) (*models.SplitTransfer, error)
+
+	// SumOutboundWithTx returns the total amount of the complete transfers from an account
+	// recorded on or after the business date since, excluding fees, within a database transaction
+	SumOutboundWithTx(ctx context.Context, tx *sql.Tx, accountID int64, since string) (decimal.Decimal, error)
+
+	// CountTenantOutboundWithTx returns the number of complete transfers from the accounts of a
+	// tenant recorded on the business date, excluding fees, within a database transaction
+	CountTenantOutboundWithTx(ctx context.Context, tx *sql.Tx, tenantID, businessDate string) (int64, error)
+}
+
+// SuspenseRepository defines the interface for suspense item database operations.
+// Items are created and resolved within the transfer that moves their money.
+type SuspenseRepository interface {
+	// GetItem retrieves a suspense item together with its audit trail
+	GetItem(ctx context.Context, itemID int64) (*models.SuspenseItem, error)
+
+	// ListItems retrieves up to limit suspense items with the given status (any if empty), oldest first
+	ListItems(ctx context.Context, status models.SuspenseStatus, limit int) ([]*models.SuspenseItem, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateItemWithTx records a credit moved to the suspense account within a transaction
+	CreateItemWithTx(ctx context.Context, tx *sql.Tx, item *models.SuspenseItem) (*models.SuspenseItem, error)
+
+	// GetItemForUpdateWithTx retrieves a suspense item and locks it for the rest of the transaction
+	GetItemForUpdateWithTx(ctx context.Context, tx *sql.Tx, itemID int64) (*models.SuspenseItem, error)
+
+	// ResolveItemWithTx marks a suspense item re-applied or returned by the given transaction
+	ResolveItemWithTx(ctx context.Context, tx *sql.Tx, itemID int64, status models.SuspenseStatus, transactionID int64, actor string) error
+
+	// AddEventWithTx appends an entry to a suspense item's audit trail
+	AddEventWithTx(ctx context.Context, tx *sql.Tx, event *models.SuspenseEvent) error
+}
+
+// PreAuthorizationRepository defines the interface for pre-authorization database operations.
+// Pre-authorizations are created and resolved within the transaction that reserves or releases
+// their funds.
+type PreAuthorizationRepository interface {
+	// GetPreAuthorization retrieves a pre-authorization by its ID
+	GetPreAuthorization(ctx context.Context, preAuthID int64) (*models.PreAuthorization, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreatePreAuthorizationWithTx records an active pre-authorization within a transaction
+	CreatePreAuthorizationWithTx(ctx context.Context, tx *sql.Tx, preAuth *models.PreAuthorization) (*models.PreAuthorization, error)
+
+	// GetPreAuthorizationForUpdateWithTx retrieves a pre-authorization and locks it for the rest of the transaction
+	GetPreAuthorizationForUpdateWithTx(ctx context.Context, tx *sql.Tx, preAuthID int64) (*models.PreAuthorization, error)
+
+	// ListExpiredForUpdateWithTx retrieves and locks up to limit active pre-authorizations that expired
+	// before now, oldest first. Rows locked by a concurrent execution are skipped.
+	ListExpiredForUpdateWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*models.PreAuthorization, error)
+
+	// ResolvePreAuthorizationWithTx marks a pre-authorization executed (by the given transaction) or expired
+	ResolvePreAuthorizationWithTx(ctx context.Context, tx *sql.Tx, preAuthID int64, status models.PreAuthorizationStatus, transactionID int64) error
+}
+
+// ApprovalRepository defines the interface for the maker-checker requests of transfers held for
+// approval. Requests are created and resolved within the transaction that records or makes the
+// transfer.
+type ApprovalRepository interface {
+	// GetApproval retrieves the approval request of a transaction
+	GetApproval(ctx context.Context, transactionID int64) (*models.TransferApproval, error)
+
+	// ListApprovals lists up to limit approval requests with the given status, or of any status if it is empty
+	ListApprovals(ctx context.Context, status models.ApprovalStatus, limit int) ([]*models.TransferApproval, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateApprovalWithTx records the pending approval request of a transaction within a transaction
+	CreateApprovalWithTx(ctx context.Context, tx *sql.Tx, approval *models.TransferApproval) (*models.TransferApproval, error)
+
+	// GetApprovalForUpdateWithTx retrieves the approval request of a transaction and locks it for the rest of the transaction
+	GetApprovalForUpdateWithTx(ctx context.Context, tx *sql.Tx, transactionID int64) (*models.TransferApproval, error)
+
+	// ResolveApprovalWithTx marks a pending approval request approved or rejected by reviewer
+	ResolveApprovalWithTx(ctx context.Context, tx *sql.Tx, transactionID int64, status models.ApprovalStatus, reviewer, note string) (*models.TransferApproval, error)
+}
+
+// FundingRepository defines the interface for the system float accounts and the history of the
+// money funded into and defunded out of the system through them
+type FundingRepository interface {
+	// ListFloatAccounts retrieves the float account of every currency with its balance and funding totals
+	ListFloatAccounts(ctx context.Context) ([]*models.FloatAccount, error)
+
+	// GetFloatAccount retrieves the float account of a currency; ErrFloatAccountNotFound if it has none
+	GetFloatAccount(ctx context.Context, currency string) (*models.FloatAccount, error)
+
+	// ListFundings retrieves up to limit fundings of a currency, newest first
+	ListFundings(ctx context.Context, currency string, limit int) ([]*models.Funding, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// GetFloatAccountWithTx retrieves the float account of a currency within a transaction
+	GetFloatAccountWithTx(ctx context.Context, tx *sql.Tx, currency string) (*models.FloatAccount, error)
+
+	// CreateFloatAccountWithTx designates an account without transactions as the float account of a currency
+	CreateFloatAccountWithTx(ctx context.Context, tx *sql.Tx, currency string, accountID int64) error
+
+	// CreateFundingWithTx records a funding within a transaction
+	CreateFundingWithTx(ctx context.Context, tx *sql.Tx, funding *models.Funding) (*models.Funding, error)
+}
+
+// ScheduledTransferRepository defines the interface for scheduled transfer database operations
+type ScheduledTransferRepository interface {
+	// CreateScheduledTransfer records a transfer to be made at its ExecuteAt
+	CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error)
+
+	// GetScheduledTransfer retrieves a scheduled transfer by its ID
+	GetScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error)
+
+	// ListScheduledTransfers lists up to limit scheduled transfers with the given status, or of any status if it is empty
+	ListScheduledTransfers(ctx context.Context, status models.ScheduledTransferStatus, limit int) ([]*models.ScheduledTransfer, error)
+
+	// ListStandingOrderOccurrences lists up to limit of the scheduled transfers made for a standing order, latest first
+	ListStandingOrderOccurrences(ctx context.Context, orderID int64, limit int) ([]*models.ScheduledTransfer, error)
+
+	// CancelScheduledTransfer marks a waiting scheduled transfer cancelled; ErrScheduledTransferResolved if it isn't waiting
+	CancelScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error)
+
+	// RecordFailure counts a failed attempt, scheduling a retry at nextAttemptAt or failing the transfer after maxAttempts
+	RecordFailure(ctx context.Context, transferID int64, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.ScheduledTransfer, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateScheduledTransferWithTx records a transfer to be made at its ExecuteAt within a transaction
+	CreateScheduledTransferWithTx(ctx context.Context, tx *sql.Tx, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error)
+
+	// CancelStandingOrderOccurrencesWithTx cancels the waiting scheduled transfers of a standing order and returns how many
+	CancelStandingOrderOccurrencesWithTx(ctx context.Context, tx *sql.Tx, orderID int64) (int, error)
+
+	// ClaimDueWithTx retrieves and locks the scheduled transfer due the longest at now, or nil if none is due.
+	// Rows locked by a concurrent execution are skipped.
+	ClaimDueWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*models.ScheduledTransfer, error)
+
+	// MarkExecutedWithTx marks a scheduled transfer made by the given transaction
+	MarkExecutedWithTx(ctx context.Context, tx *sql.Tx, transferID, transactionID int64) error
+
+	// RecordFailureWithTx counts a failed attempt within a transaction, scheduling a retry at nextAttemptAt
+	// or failing the transfer after maxAttempts
+	RecordFailureWithTx(ctx context.Context, tx *sql.Tx, transferID int64, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.ScheduledTransfer, error)
+}
+
+// StandingOrderRepository defines the interface for standing order database operations
+type StandingOrderRepository interface {
+	// CreateStandingOrder records an active standing order whose first occurrence is at its StartAt
+	CreateStandingOrder(ctx context.Context, order *models.StandingOrder) (*models.StandingOrder, error)
+
+	// GetStandingOrder retrieves a standing order by its ID
+	GetStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// GetStandingOrderForUpdateWithTx retrieves a standing order and locks it for the rest of the transaction
+	GetStandingOrderForUpdateWithTx(ctx context.Context, tx *sql.Tx, orderID int64) (*models.StandingOrder, error)
+
+	// ListDueForUpdateWithTx retrieves and locks up to limit active standing orders due at now, longest due first.
+	// Rows locked by a concurrent sweep are skipped.
+	ListDueForUpdateWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*models.StandingOrder, error)
+
+	// UpdateStandingOrderWithTx saves the status, occurrence count and next occurrence of a standing order
+	UpdateStandingOrderWithTx(ctx context.Context, tx *sql.Tx, order *models.StandingOrder) (*models.StandingOrder, error)
+}
+
+// LedgerAccountRepository defines the interface for the chart of accounts of the ledger
+type LedgerAccountRepository interface {
+	// CreateLedgerAccount adds an active account to the chart; ErrLedgerAccountExists if the code is taken
+	CreateLedgerAccount(ctx context.Context, account *models.LedgerAccount) (*models.LedgerAccount, error)
+
+	// GetLedgerAccount retrieves a ledger account by its code
+	GetLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error)
+
+	// ListLedgerAccounts retrieves the ledger accounts matching filter, ordered by code
+	ListLedgerAccounts(ctx context.Context, filter models.LedgerAccountFilter) ([]*models.LedgerAccount, error)
+
+	// UpdateLedgerAccount changes the name and backing account of a ledger account; the type and
+	// normal side can't be changed
+	UpdateLedgerAccount(ctx context.Context, code, name string, accountID int64) (*models.LedgerAccount, error)
+
+	// SetLedgerAccountActive activates or deactivates a ledger account
+	SetLedgerAccountActive(ctx context.Context, code string, active bool) error
+}
+
+// TenantRepository defines the interface for the settings overrides of tenants
+type TenantRepository interface {
+	// GetTenantSettings retrieves the settings of a tenant; ErrTenantNotFound if it has none
+	GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error)
+
+	// ListTenantSettings retrieves the settings of every tenant, ordered by tenant ID
+	ListTenantSettings(ctx context.Context) ([]*models.TenantSettings, error)
+
+	// PutTenantSettings creates or replaces the settings of a tenant
+	PutTenantSettings(ctx context.Context, settings *models.TenantSettings) (*models.TenantSettings, error)
+
+	// DeleteTenantSettings removes the settings of a tenant, returning its accounts to the
+	// deployment-wide behavior; ErrTenantNotFound if it has none
+	DeleteTenantSettings(ctx context.Context, tenantID string) error
+}
+
+// FeeRuleRepository defines the interface for the fee rules charged on transfers
+type FeeRuleRepository interface {
+	// CreateFeeRule stores a new fee rule
+	CreateFeeRule(ctx context.Context, rule *models.FeeRule) (*models.FeeRule, error)
+
+	// ListFeeRules retrieves every fee rule, global rules first, then by account and ID
+	ListFeeRules(ctx context.Context) ([]*models.FeeRule, error)
+
+	// GetFeeRulesForAccount retrieves the rules that apply to transfers from an account: its own
+	// rules, or the global rules if it has none
+	GetFeeRulesForAccount(ctx context.Context, accountID int64) ([]*models.FeeRule, error)
+
+	// DeleteFeeRule removes a fee rule; ErrFeeRuleNotFound if it doesn't exist
+	DeleteFeeRule(ctx context.Context, id int64) error
+}
+
+// PostingRuleRepository defines the interface for the posting templates of transfer types
+type PostingRuleRepository interface {
+	// ListPostingRules retrieves the posting rules of a transfer type (all types if empty), ordered by
+	// transfer type and leg
+	ListPostingRules(ctx context.Context, transferType models.TransferType) ([]*models.PostingRule, error)
+
+	// ReplacePostingRules atomically replaces the posting template of a transfer type
+	ReplacePostingRules(ctx context.Context, transferType models.TransferType, rules []*models.PostingRule) error
+}
+
+// LedgerRepository defines the interface for the entries of the double-entry ledger
+type LedgerRepository interface {
+	// RecordEntriesWithTx records the balanced entries of one posting within a database transaction
+	RecordEntriesWithTx(ctx context.Context, tx *sql.Tx, entries []*models.LedgerEntry) error
+
+	// GetEntriesByTransaction retrieves the entries posted for a transaction by leg, debits first
+	GetEntriesByTransaction(ctx context.Context, transactionID int64) ([]*models.LedgerEntry, error)
+
+	// GetLedgerBalance derives an account's balance from its entries
+	GetLedgerBalance(ctx context.Context, accountID int64) (*models.LedgerBalance, error)
+}
+
+// IdempotencyRepository defines the interface for the idempotency keys of single transfers
+type IdempotencyRepository interface {
+	// GetIdempotencyRecord retrieves the record stored under an idempotency key of owner, expired
+	// or not; ErrTransactionNotFound if owner hasn't used the key
+	GetIdempotencyRecord(ctx context.Context, owner, key string) (*models.IdempotencyRecord, error)
+
+	// DeleteExpiredIdempotencyRecords deletes up to limit records that expired by now and returns
+	// how many it deleted
+	DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time, limit int) (int, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateIdempotencyRecordWithTx stores the outcome of a transfer under its owner and key within
+	// the transaction that made the transfer, replacing a record of the key that expired by now;
+	// ErrDuplicateIdempotencyKey if the owner's key is taken
+	CreateIdempotencyRecordWithTx(ctx context.Context, tx *sql.Tx, record *models.IdempotencyRecord, now time.Time) error
+}
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+	// ListEntries retrieves audit entries matching filter, newest first
+	ListEntries(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// RecordWithTx appends an entry within a transaction, so it is kept only if the audited action commits
+	RecordWithTx(ctx context.Context, tx *sql.Tx, entry *models.AuditEntry) error
+}
+
+// OutboxRepository defines the interface for the outbox of events waiting to be published
+type OutboxRepository interface {
+	// CreateMessage records an event outside any transaction, for events about changes that
+	// were not committed
+	CreateMessage(ctx context.Context, message *models.OutboxMessage) (*models.OutboxMessage, error)
+
+	// CountUnpublished returns the number of messages waiting to be published
+	CountUnpublished(ctx context.Context) (int64, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateMessageWithTx records an event within the transaction that raised it, so it is kept
+	// only if that transaction commits
+	CreateMessageWithTx(ctx context.Context, tx *sql.Tx, message *models.OutboxMessage) (*models.OutboxMessage, error)
+
+	// ClaimUnpublishedWithTx locks up to limit unpublished messages, oldest first, skipping those
+	// another transaction has locked
+	ClaimUnpublishedWithTx(ctx context.Context, tx *sql.Tx, limit int) ([]*models.OutboxMessage, error)
+
+	// MarkPublishedWithTx marks a message published at the given time
+	MarkPublishedWithTx(ctx context.Context, tx *sql.Tx, messageID int64, publishedAt time.Time) error
+
+	// RecordFailureWithTx counts a failed attempt to publish a message and keeps its error
+	RecordFailureWithTx(ctx context.Context, tx *sql.Tx, messageID int64, reason string) error
+}
+
+// TransferJobRepository defines the interface for asynchronous transfer job database operations
+type TransferJobRepository interface {
+	// CreateTransferJob queues a job to be attempted from now on and returns it and true, or the job
+	// its owner already queued under its idempotency key and false
+	CreateTransferJob(ctx context.Context, job *models.TransferJob, now time.Time) (*models.TransferJob, bool, error)
+
+	// GetTransferJob retrieves a transfer job by its ID
+	GetTransferJob(ctx context.Context, jobID int64) (*models.TransferJob, error)
+
+	// GetHistory retrieves the history of a transfer job, oldest first
+	GetHistory(ctx context.Context, jobID int64) ([]*models.TransferJobEvent, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// ClaimQueuedWithTx retrieves and locks the most urgent queued job ready the longest at now, or nil
+	// if none is ready. Jobs locked by another worker are skipped.
+	ClaimQueuedWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*models.TransferJob, error)
+
+	// MarkCompletedWithTx marks a transfer job made by the given transaction
+	MarkCompletedWithTx(ctx context.Context, tx *sql.Tx, jobID, transactionID int64) error
+
+	// RecordFailureWithTx counts a failed attempt, keeping its error code and message, and retries the
+	// job at nextAttemptAt or fails it after maxAttempts
+	RecordFailureWithTx(ctx context.Context, tx *sql.Tx, jobID int64, code, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.TransferJob, error)
+
+	// GetTransferJobForUpdateWithTx retrieves and locks a transfer job, waiting for an attempt on it to end
+	GetTransferJobForUpdateWithTx(ctx context.Context, tx *sql.Tx, jobID int64) (*models.TransferJob, error)
+
+	// CancelWithTx marks a transfer job cancelled
+	CancelWithTx(ctx context.Context, tx *sql.Tx, jobID int64) (*models.TransferJob, error)
+
+	// RequeueWithTx queues a transfer job again, to be attempted from now on with all its attempts
+	RequeueWithTx(ctx context.Context, tx *sql.Tx, jobID int64, now time.Time) (*models.TransferJob, error)
+
+	// SetPriorityWithTx changes the priority a transfer job is claimed in
+	SetPriorityWithTx(ctx context.Context, tx *sql.Tx, jobID int64, class priority.Class) (*models.TransferJob, error)
+
+	// AddEventWithTx appends an entry to the history of a transfer job
+	AddEventWithTx(ctx context.Context, tx *sql.Tx, event *models.TransferJobEvent) error
+}
+
+// DeadLetterRepository defines the interface for dead letter database operations
+type DeadLetterRepository interface {
+	// CreateDeadLetter records work given up on; work already dead-lettered keeps its first record
+	CreateDeadLetter(ctx context.Context, letter *models.DeadLetter) (*models.DeadLetter, error)
+
+	// ListDeadLetters retrieves up to limit dead letters of the given kind, or of any kind if it is empty, newest first
+	ListDeadLetters(ctx context.Context, kind models.DeadLetterKind, limit int) ([]*models.DeadLetter, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateDeadLetterWithTx records work given up on within the transaction that gave up on it
+	CreateDeadLetterWithTx(ctx context.Context, tx *sql.Tx, letter *models.DeadLetter) (*models.DeadLetter, error)
+}
+
+// DelegationRepository defines the interface for the delegations of transfers on behalf of owners
+type DelegationRepository interface {
+	// CreateDelegation records a delegation from its grantor to its grantee
+	CreateDelegation(ctx context.Context, delegation *models.Delegation) (*models.Delegation, error)
+
+	// GetDelegation retrieves a delegation by its ID
+	GetDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error)
+
+	// ListDelegations retrieves up to limit delegations, newest first, narrowed to a grantor and
+	// grantee if they aren't empty
+	ListDelegations(ctx context.Context, grantor, grantee string, limit int) ([]*models.Delegation, error)
+
+	// RevokeDelegation revokes a delegation at now; revoking it again keeps the first revocation
+	RevokeDelegation(ctx context.Context, delegationID int64, now time.Time) (*models.Delegation, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// FindActiveDelegationsWithTx retrieves and share-locks the delegations from grantor to grantee
+	// active at now, oldest first
+	FindActiveDelegationsWithTx(ctx context.Context, tx *sql.Tx, grantor, grantee string, now time.Time) ([]*models.Delegation, error)
+}
+
+// QueueRepository defines the interface for measuring the queues of background work
+type QueueRepository interface {
+	// QueueDepths returns the backlog of every queue of background work at now
+	QueueDepths(ctx context.Context, now time.Time) ([]*models.QueueDepth, error)
+}
+
+// AdjustmentRepository defines the interface for the manual balance adjustments made by operators
+type AdjustmentRepository interface {
+	// ListAdjustments retrieves up to limit adjustments of an account, newest first
+	ListAdjustments(ctx context.Context, accountID int64, limit int) ([]*models.Adjustment, error)
+
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// CreateAdjustmentWithTx records an adjustment with the transfer that moved it
+	CreateAdjustmentWithTx(ctx context.Context, tx *sql.Tx, adjustment *models.Adjustment) (*models.Adjustment, error)
+}
+
+// LockingShadowRepository defines the interface for the redesigned transfer path, which locks
+// account rows and updates balances conditionally instead of relying on serializable isolation,
+// run in dry run beside the current path to validate it before cutover
+type LockingShadowRepository interface {
+	// Transaction-aware methods - used within database transactions for atomic operations
+
+	// DryRunTransferWithTx moves debit out of the source and credit into the destination on the
+	// row-locking path within tx, then undoes it; the transaction keeps only what it did before
+	DryRunTransferWithTx(ctx context.Context, tx *sql.Tx, sourceID, destID int64, debit, credit decimal.Decimal) (*models.ShadowTransfer, error)
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresLedgerRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewLedgerRepository(db *sql.DB) *PostgresLedgerRepository {
+	return &PostgresLedgerRepository{db: db, log: logger.Default()}
+}
+
+// RecordEntriesWithTx records the entries of one posting within a database transaction, setting
+// their IDs and creation times. The entries must balance; a transaction can only be posted once.
+func (r *PostgresLedgerRepository) RecordEntriesWithTx(ctx context.Context, tx *sql.Tx, entries []*models.LedgerEntry) error {
+	if err := models.ValidateBalancedEntries(entries); err != nil {
+		r.log.WarnContext(ctx, "Rejected unbalanced ledger posting", "err", err)
+		return err
+	}
+
+	for _, entry := range entries {
+		var createdAt time.Time
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO ledger_entries (transaction_id, leg, account_id, ledger_code, entry_type, transfer_type, amount, currency)
+			VALUES (NULLIF($1, 0), GREATEST($2, 1), NULLIF($3, 0), NULLIF($4, ''), $5, COALESCE(NULLIF($6, ''), 'standard'), $7, NULLIF($8, ''))
+			RETURNING id, leg, transfer_type, created_at
+		`, entry.TransactionID, entry.Leg, entry.AccountID, entry.LedgerCode, entry.Side, entry.TransferType, entry.Amount, entry.Currency).Scan(&entry.ID, &entry.Leg, &entry.TransferType, &createdAt)
+		if err != nil {
+			if domainErr := translatePgError(err); domainErr != nil {
+				r.log.WarnContext(ctx, "Constraint violation posting entry of transaction", "side", entry.Side, logger.TransactionID(entry.TransactionID), "err", err)
+				return fmt.Errorf("%w: transaction %d, account %d", domainErr, entry.TransactionID, entry.AccountID)
+			}
+			r.log.ErrorContext(ctx, "Database error posting entry of transaction", "side", entry.Side, logger.TransactionID(entry.TransactionID), "err", err)
+			return fmt.Errorf("failed to record ledger entry: %w", err)
+		}
+		entry.CreatedAt = createdAt.Format(time.RFC3339)
+	}
+	return nil
+}
+
+// GetEntriesByTransaction retrieves the entries posted for a transaction by leg, debits first
+func (r *PostgresLedgerRepository) GetEntriesByTransaction(ctx context.Context, transactionID int64) ([]*models.LedgerEntry, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM transactions WHERE id = $1)`, transactionID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up transaction: %w", err)
+	}
+	if !exists {
+		return nil, errors.NewTransactionNotFoundError(transactionID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(transaction_id, 0), leg, COALESCE(account_id, 0), COALESCE(ledger_code, ''), entry_type, transfer_type, amount, COALESCE(currency, ''), created_at
+		FROM ledger_entries
+		WHERE transaction_id = $1
+		ORDER BY leg, entry_type DESC, id
+	`, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving ledger entries of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.LedgerEntry{}
+	for rows.Next() {
+		var entry models.LedgerEntry
+		var createdAt time.Time
+		if err := rows.Scan(&entry.ID, &entry.TransactionID, &entry.Leg, &entry.AccountID, &entry.LedgerCode, &entry.Side, &entry.TransferType, &entry.Amount, &entry.Currency, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		entry.CreatedAt = createdAt.Format(time.RFC3339)
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger entries: %w", err)
+	}
+	return entries, nil
+}
+
+// GetLedgerBalance derives an account's balance from its entries: its credits (including the
+// opening balance) less its debits
+func (r *PostgresLedgerRepository) GetLedgerBalance(ctx context.Context, accountID int64) (*models.LedgerBalance, error) {
+	balance := models.LedgerBalance{AccountID: accountID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'credit'), 0),
+			COALESCE(SUM(e.amount) FILTER (WHERE e.entry_type = 'debit'), 0)
+		FROM accounts a
+		LEFT JOIN ledger_entries e ON e.account_id = a.account_id
+		WHERE a.account_id = $1
+		GROUP BY a.account_id
+	`, accountID).Scan(&balance.Credits, &balance.Debits)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id %d", errors.ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error deriving ledger balance of account", logger.AccountID(accountID), "err", err)
+		return nil, fmt.Errorf("failed to get ledger balance: %w", err)
+	}
+	balance.Balance = balance.Credits.Sub(balance.Debits)
+	return &balance, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresLedgerAccountRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewLedgerAccountRepository(db *sql.DB) *PostgresLedgerAccountRepository {
+	return &PostgresLedgerAccountRepository{db: db, log: logger.Default()}
+}
+
+// ledgerAccountColumns is the column list selected by every ledger account read, in scanLedgerAccount order
+const ledgerAccountColumns = `code, name, account_type, normal_side, COALESCE(account_id, 0), active, created_at, updated_at`
+
+// scanLedgerAccount scans a row selected with ledgerAccountColumns
+func scanLedgerAccount(row rowScanner) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&account.Code,
+		&account.Name,
+		&account.Type,
+		&account.NormalSide,
+		&account.AccountID,
+		&account.Active,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	account.CreatedAt = createdAt.Format(time.RFC3339)
+	account.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &account, nil
+}
+
+// CreateLedgerAccount adds an active account to the chart of accounts
+func (r *PostgresLedgerAccountRepository) CreateLedgerAccount(ctx context.Context, account *models.LedgerAccount) (*models.LedgerAccount, error) {
+	r.log.InfoContext(ctx, "Creating ledger account", "code", account.Code, "type", account.Type, "normal_side", account.NormalSide, logger.AccountID(account.AccountID))
+
+	created, err := scanLedgerAccount(r.db.QueryRowContext(ctx, `
+		INSERT INTO ledger_accounts (code, name, account_type, normal_side, account_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+		RETURNING `+ledgerAccountColumns,
+		account.Code, account.Name, account.Type, account.NormalSide, account.AccountID))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating ledger account", "code", account.Code, "err", err)
+			return nil, fmt.Errorf("%w: code %s", domainErr, account.Code)
+		}
+		r.log.ErrorContext(ctx, "Database error creating ledger account", "code", account.Code, "err", err)
+		return nil, fmt.Errorf("failed to create ledger account: %w", err)
+	}
+	return created, nil
+}
+
+// GetLedgerAccount retrieves a ledger account by its code
+func (r *PostgresLedgerAccountRepository) GetLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error) {
+	account, err := scanLedgerAccount(r.db.QueryRowContext(ctx, `
+		SELECT `+ledgerAccountColumns+`
+		FROM ledger_accounts
+		WHERE code = $1
+	`, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Ledger account not found", "code", code)
+			return nil, fmt.Errorf("%w: code %s", errors.ErrLedgerAccountNotFound, code)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving ledger account", "code", code, "err", err)
+		return nil, fmt.Errorf("failed to get ledger account: %w", err)
+	}
+	return account, nil
+}
+
+// ListLedgerAccounts retrieves the ledger accounts matching filter, ordered by code
+func (r *PostgresLedgerAccountRepository) ListLedgerAccounts(ctx context.Context, filter models.LedgerAccountFilter) ([]*models.LedgerAccount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+ledgerAccountColumns+`
+		FROM ledger_accounts
+		WHERE ($1 = '' OR account_type = $1) AND ($2 OR active)
+		ORDER BY code
+	`, filter.Type, filter.IncludeInactive)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing ledger accounts", "err", err)
+		return nil, fmt.Errorf("failed to list ledger accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []*models.LedgerAccount{}
+	for rows.Next() {
+		account, err := scanLedgerAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// UpdateLedgerAccount changes the name and backing account of a ledger account. The type and normal
+// side are fixed at creation since existing postings were validated against them.
+func (r *PostgresLedgerAccountRepository) UpdateLedgerAccount(ctx context.Context, code, name string, accountID int64) (*models.LedgerAccount, error) {
+	r.log.InfoContext(ctx, "Updating ledger account", "code", code, "name", name, logger.AccountID(accountID))
+
+	updated, err := scanLedgerAccount(r.db.QueryRowContext(ctx, `
+		UPDATE ledger_accounts
+		SET name = $2, account_id = NULLIF($3, 0), updated_at = NOW()
+		WHERE code = $1
+		RETURNING `+ledgerAccountColumns,
+		code, name, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: code %s", errors.ErrLedgerAccountNotFound, code)
+		}
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation updating ledger account", "code", code, "err", err)
+			return nil, fmt.Errorf("%w: id %d", domainErr, accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error updating ledger account", "code", code, "err", err)
+		return nil, fmt.Errorf("failed to update ledger account: %w", err)
+	}
+	return updated, nil
+}
+
+// SetLedgerAccountActive activates or deactivates a ledger account
+func (r *PostgresLedgerAccountRepository) SetLedgerAccountActive(ctx context.Context, code string, active bool) error {
+	r.log.InfoContext(ctx, "Setting ledger account active", "code", code, "active", active)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE ledger_accounts
+		SET active = $2, updated_at = NOW()
+		WHERE code = $1
+	`, code, active)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error updating ledger account", "code", code, "err", err)
+		return fmt.Errorf("failed to update ledger account: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: code %s", errors.ErrLedgerAccountNotFound, code)
+	}
+	return nil
+}
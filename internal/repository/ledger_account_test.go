@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerAccountRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewLedgerAccountRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.CreateAccount(ctx, 790001, decimal.Zero))
+
+	created, err := repo.CreateLedgerAccount(ctx, &models.LedgerAccount{
+		Code: "test_fee_income", Name: "Test fee income", Type: models.LedgerAccountTypeIncome,
+		NormalSide: models.EntrySideCredit, AccountID: 790001,
+	})
+	require.NoError(t, err)
+	assert.True(t, created.Active)
+	assert.Equal(t, int64(790001), created.AccountID)
+
+	_, err = repo.CreateLedgerAccount(ctx, created)
+	assert.ErrorIs(t, err, errors.ErrLedgerAccountExists)
+
+	_, err = repo.CreateLedgerAccount(ctx, &models.LedgerAccount{
+		Code: "test_orphan", Name: "Orphan", Type: models.LedgerAccountTypeAsset,
+		NormalSide: models.EntrySideDebit, AccountID: 790999,
+	})
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+
+	// Only the name and backing account change
+	updated, err := repo.UpdateLedgerAccount(ctx, created.Code, "Renamed", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	assert.Zero(t, updated.AccountID)
+	assert.Equal(t, models.LedgerAccountTypeIncome, updated.Type)
+
+	// Deactivated accounts are only listed on request
+	require.NoError(t, repo.SetLedgerAccountActive(ctx, created.Code, false))
+	active, err := repo.ListLedgerAccounts(ctx, models.LedgerAccountFilter{Type: models.LedgerAccountTypeIncome})
+	require.NoError(t, err)
+	for _, account := range active {
+		assert.NotEqual(t, created.Code, account.Code)
+	}
+	all, err := repo.ListLedgerAccounts(ctx, models.LedgerAccountFilter{Type: models.LedgerAccountTypeIncome, IncludeInactive: true})
+	require.NoError(t, err)
+	assert.Len(t, all, len(active)+1)
+
+	_, err = repo.GetLedgerAccount(ctx, "test_missing")
+	assert.ErrorIs(t, err, errors.ErrLedgerAccountNotFound)
+	assert.ErrorIs(t, repo.SetLedgerAccountActive(ctx, "test_missing", true), errors.ErrLedgerAccountNotFound)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerRepository_PostTransfer(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewLedgerRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(791001), int64(791002)
+	require.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, destID, decimal.Zero))
+
+	// Opening balances are posted at creation
+	balance, err := repo.GetLedgerBalance(ctx, sourceID)
+	require.NoError(t, err)
+	assert.True(t, balance.Balance.Equal(decimal.NewFromInt(100)))
+	balance, err = repo.GetLedgerBalance(ctx, destID)
+	require.NoError(t, err)
+	assert.True(t, balance.Balance.IsZero())
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	created, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: decimal.RequireFromString("40.5"), Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	entries := transferEntries(created, models.TransferTypeStandard)
+	require.NoError(t, repo.RecordEntriesWithTx(ctx, tx, entries))
+	assert.NotZero(t, entries[0].ID)
+	assert.Equal(t, 1, entries[0].Leg)
+
+	// A transaction is posted at most once
+	err = repo.RecordEntriesWithTx(ctx, tx, transferEntries(created, models.TransferTypeStandard))
+	assert.ErrorIs(t, err, errors.ErrTransactionAlreadyPosted)
+	require.NoError(t, tx.Rollback())
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	created, err = transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: decimal.RequireFromString("40.5"), Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	// Two legs through a ledger account kept in the ledger only
+	require.NoError(t, repo.RecordEntriesWithTx(ctx, tx, models.PostingEntries(created.ID, models.TransferTypeAdjustment,
+		models.ApplyPostingTemplate([]*models.PostingRule{
+			{Leg: 1, Debit: models.PostingPartySource, Credit: "suspense"},
+			{Leg: 2, Debit: "suspense", Credit: models.PostingPartyDestination},
+		}, sourceID, destID, created.Amount))))
+	require.NoError(t, tx.Commit())
+
+	posted, err := repo.GetEntriesByTransaction(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, posted, 4)
+	assert.Equal(t, models.EntrySideDebit, posted[0].Side)
+	assert.Equal(t, models.TransferTypeAdjustment, posted[0].TransferType)
+	assert.Equal(t, sourceID, posted[0].AccountID)
+	assert.Equal(t, models.EntrySideCredit, posted[1].Side)
+	assert.Equal(t, "suspense", posted[1].LedgerCode)
+	assert.Zero(t, posted[1].AccountID)
+	assert.Equal(t, 2, posted[2].Leg)
+	assert.Equal(t, "suspense", posted[2].LedgerCode)
+	assert.Equal(t, models.EntrySideCredit, posted[3].Side)
+	assert.Equal(t, destID, posted[3].AccountID)
+
+	balance, err = repo.GetLedgerBalance(ctx, sourceID)
+	require.NoError(t, err)
+	assert.True(t, balance.Balance.Equal(decimal.RequireFromString("59.5")), balance.Balance.String())
+	balance, err = repo.GetLedgerBalance(ctx, destID)
+	require.NoError(t, err)
+	assert.True(t, balance.Credits.Equal(decimal.RequireFromString("40.5")))
+
+	_, err = repo.GetEntriesByTransaction(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+	_, err = repo.GetLedgerBalance(ctx, 791999)
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+}
+
+// transferEntries returns the entries of a transfer posted from its source to its destination
+func transferEntries(transaction *models.Transaction, transferType models.TransferType) []*models.LedgerEntry {
+	return models.PostingEntries(transaction.ID, transferType, models.ApplyPostingTemplate([]*models.PostingRule{
+		{Leg: 1, Debit: models.PostingPartySource, Credit: models.PostingPartyDestination},
+	}, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount))
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// PostgresLockingShadowRepository runs the redesigned transfer path in dry run. Instead of reading
+// both accounts and writing back balances computed in Go, relying on serializable isolation to
+// reject conflicting transfers, it locks both account rows FOR UPDATE in account ID order and
+// moves the money with updates that check the available balance themselves.
+type PostgresLockingShadowRepository struct {
+	log *slog.Logger
+}
+
+func NewLockingShadowRepository() *PostgresLockingShadowRepository {
+	return &PostgresLockingShadowRepository{log: logger.Default()}
+}
+
+// DryRunTransferWithTx moves debit out of the source and credit into the destination on the
+// row-locking path within a savepoint of tx, and rolls back to it, releasing its locks, whatever
+// the outcome. An available balance plus overdraft limit below debit fails with
+// ErrInsufficientBalance, a missing account with ErrSourceAccountNotFound or
+// ErrDestinationAccountNotFound.
+func (r *PostgresLockingShadowRepository) DryRunTransferWithTx(ctx context.Context, tx *sql.Tx, sourceID, destID int64, debit, credit decimal.Decimal) (_ *models.ShadowTransfer, err error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT locking_shadow"); err != nil {
+		return nil, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	defer func() {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT locking_shadow"); rbErr != nil && err == nil {
+			err = fmt.Errorf("failed to roll back to savepoint: %w", rbErr)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT account_id
+		FROM accounts
+		WHERE account_id IN ($1, $2)
+		ORDER BY account_id
+		FOR UPDATE
+	`, sourceID, destID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error locking accounts (locking shadow)", "source_account_id", sourceID, "destination_account_id", destID, "err", err)
+		return nil, fmt.Errorf("failed to lock accounts: %w", err)
+	}
+	locked := make(map[int64]bool, 2)
+	for rows.Next() {
+		var accountID int64
+		if err := rows.Scan(&accountID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		locked[accountID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accounts: %w", err)
+	}
+	if !locked[sourceID] {
+		return nil, errors.NewSourceAccountNotFoundError(sourceID)
+	}
+
+	shadow := &models.ShadowTransfer{}
+	var available decimal.Decimal
+	err = tx.QueryRowContext(ctx, `
+		UPDATE accounts
+		SET balance = balance - $1, last_activity_at = NOW()
+		WHERE account_id = $2 AND balance - reserved_balance + overdraft_limit >= $1
+		RETURNING balance
+	`, debit, sourceID).Scan(&shadow.SourceBalance)
+	if err == sql.ErrNoRows {
+		if err := tx.QueryRowContext(ctx, `SELECT balance - reserved_balance FROM accounts WHERE account_id = $1`, sourceID).Scan(&available); err != nil {
+			return nil, fmt.Errorf("failed to get available balance: %w", err)
+		}
+		return nil, errors.NewInsufficientBalanceError(sourceID, debit, available)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error debiting account (locking shadow)", "source_account_id", sourceID, "err", err)
+		return nil, fmt.Errorf("failed to debit account: %w", err)
+	}
+
+	if !locked[destID] {
+		return nil, errors.NewDestinationAccountNotFoundError(destID)
+	}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE accounts
+		SET balance = balance + $1, last_activity_at = NOW()
+		WHERE account_id = $2
+		RETURNING balance
+	`, credit, destID).Scan(&shadow.DestinationBalance)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error crediting account (locking shadow)", "destination_account_id", destID, "err", err)
+		return nil, fmt.Errorf("failed to credit account: %w", err)
+	}
+	return shadow, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockingShadowRepository_DryRunTransferWithTx(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewLockingShadowRepository()
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(794001), int64(794002)
+	require.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, destID, decimal.NewFromInt(10)))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	shadow, err := repo.DryRunTransferWithTx(ctx, tx, sourceID, destID, decimal.NewFromInt(30), decimal.NewFromInt(30))
+	require.NoError(t, err)
+	assert.True(t, shadow.SourceBalance.Equal(decimal.NewFromInt(70)))
+	assert.True(t, shadow.DestinationBalance.Equal(decimal.NewFromInt(40)))
+
+	// The dry run is undone and the transaction stays usable
+	source, err := accountRepo.GetAccountWithTx(ctx, tx, sourceID)
+	require.NoError(t, err)
+	assert.True(t, source.Balance.Equal(decimal.NewFromInt(100)))
+
+	_, err = repo.DryRunTransferWithTx(ctx, tx, sourceID, destID, decimal.NewFromInt(101), decimal.NewFromInt(101))
+	assert.ErrorIs(t, err, errors.ErrInsufficientBalance)
+
+	_, err = repo.DryRunTransferWithTx(ctx, tx, sourceID, destID+1000, decimal.NewFromInt(30), decimal.NewFromInt(30))
+	assert.ErrorIs(t, err, errors.ErrDestinationAccountNotFound)
+
+	_, err = repo.DryRunTransferWithTx(ctx, tx, sourceID+1000, destID, decimal.NewFromInt(30), decimal.NewFromInt(30))
+	assert.ErrorIs(t, err, errors.ErrSourceAccountNotFound)
+
+	dest, err := accountRepo.GetAccountWithTx(ctx, tx, destID)
+	require.NoError(t, err)
+	assert.True(t, dest.Balance.Equal(decimal.NewFromInt(10)))
+}
@@ -0,0 +1,923 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// MemoryStore holds the state shared by the in-memory repositories
+//
+// The in-memory repositories implement the same interfaces as the Postgres ones for tests and
+// local experiments. The *sql.Tx arguments of the WithTx methods are ignored: every call is
+// applied immediately, so a rolled-back database transaction is not undone.
+type MemoryStore struct {
+	mu           sync.Mutex
+	accounts     map[int64]*models.Account
+	transactions []*models.Transaction
+	history      []*models.TransactionStatusChange
+	batches      map[[2]string]*models.Batch // by owner and key
+	splits       map[int64]*models.SplitTransfer
+	nextTxID     int64
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts: make(map[int64]*models.Account),
+		batches:  make(map[[2]string]*models.Batch),
+		splits:   make(map[int64]*models.SplitTransfer),
+		nextTxID: 1,
+	}
+}
+
+// MemoryAccountRepository is an in-memory AccountRepository
+type MemoryAccountRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryAccountRepository creates an in-memory account repository backed by store
+func NewMemoryAccountRepository(store *MemoryStore) *MemoryAccountRepository {
+	return &MemoryAccountRepository{store: store}
+}
+
+// CreateAccount creates a new account with the given ID and initial balance
+func (r *MemoryAccountRepository) CreateAccount(ctx context.Context, accountID int64, initialBalance decimal.Decimal) error {
+	if initialBalance.IsNegative() {
+		return errors.NewInvalidAmountError(initialBalance)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.accounts[accountID]; exists {
+		return errors.NewAccountAlreadyExistsError(accountID)
+	}
+	now := time.Now().Format(time.RFC3339)
+	r.store.accounts[accountID] = &models.Account{
+		AccountID:      accountID,
+		Balance:        initialBalance,
+		Status:         models.AccountStatusActive,
+		Type:           models.AccountTypeStandard,
+		LastActivityAt: now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	return nil
+}
+
+// GetAccount retrieves a copy of an account by its ID
+func (r *MemoryAccountRepository) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return nil, errors.NewAccountNotFoundError(accountID)
+	}
+	copied := *account
+	return &copied, nil
+}
+
+// GetAccountsByOwner retrieves copies of all accounts held by an owner, ordered by account ID
+func (r *MemoryAccountRepository) GetAccountsByOwner(ctx context.Context, ownerRef string) ([]*models.Account, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	accounts := []*models.Account{}
+	for _, account := range r.store.accounts {
+		if account.OwnerRef == ownerRef {
+			copied := *account
+			accounts = append(accounts, &copied)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+	return accounts, nil
+}
+
+// ListAccounts retrieves copies of up to limit accounts matching filter, in its order, starting
+// after cursor (from the first if nil)
+func (r *MemoryAccountRepository) ListAccounts(ctx context.Context, filter models.AccountListFilter, limit int, after *models.AccountCursor) (*models.AccountPage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// before reports whether a precedes b in the listing's order
+	before := func(a, b models.AccountCursor) bool {
+		cmp := 0
+		if filter.SortKey() == models.AccountSortBalance {
+			cmp = a.Balance.Cmp(b.Balance)
+		}
+		if cmp == 0 && a.AccountID != b.AccountID {
+			cmp = -1
+			if a.AccountID > b.AccountID {
+				cmp = 1
+			}
+		}
+		if filter.Descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+
+	var matched []*models.Account
+	for _, account := range r.store.accounts {
+		if !filter.Matches(account) || after != nil && !before(*after, accountCursor(filter, account)) {
+			continue
+		}
+		copied := *account
+		matched = append(matched, &copied)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return before(accountCursor(filter, matched[i]), accountCursor(filter, matched[j]))
+	})
+
+	page := &models.AccountPage{Accounts: matched}
+	if len(matched) > limit {
+		page.Accounts = matched[:limit]
+		page.NextCursor = accountCursor(filter, matched[limit-1]).Encode()
+	}
+	if page.Accounts == nil {
+		page.Accounts = []*models.Account{}
+	}
+	return page, nil
+}
+
+// SetOwnerRef links an account to an external owner reference; an empty ref clears it
+func (r *MemoryAccountRepository) SetOwnerRef(ctx context.Context, accountID int64, ownerRef string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	account.OwnerRef = ownerRef
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// SetTenant assigns an account to a tenant; an empty tenant clears it
+func (r *MemoryAccountRepository) SetTenant(ctx context.Context, accountID int64, tenantID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	account.TenantID = tenantID
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// CountTenantAccounts returns the number of accounts assigned to a tenant
+func (r *MemoryAccountRepository) CountTenantAccounts(ctx context.Context, tenantID string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, account := range r.store.accounts {
+		if account.TenantID == tenantID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountTenantAccountsWithTx returns the number of accounts assigned to a tenant; tx is ignored
+func (r *MemoryAccountRepository) CountTenantAccountsWithTx(ctx context.Context, tx *sql.Tx, tenantID string) (int64, error) {
+	return r.CountTenantAccounts(ctx, tenantID)
+}
+
+// GetAccountWithTx retrieves an account by its ID; tx is ignored
+func (r *MemoryAccountRepository) GetAccountWithTx(ctx context.Context, tx *sql.Tx, accountID int64) (*models.Account, error) {
+	return r.GetAccount(ctx, accountID)
+}
+
+// GetAccountsWithTx retrieves the accounts with the given IDs, ordered by ID; IDs of accounts
+// that don't exist are skipped and tx is ignored
+func (r *MemoryAccountRepository) GetAccountsWithTx(ctx context.Context, tx *sql.Tx, accountIDs []int64) ([]*models.Account, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	accounts := []*models.Account{}
+	seen := make(map[int64]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if account, exists := r.store.accounts[accountID]; exists && !seen[accountID] {
+			seen[accountID] = true
+			copied := *account
+			accounts = append(accounts, &copied)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+	return accounts, nil
+}
+
+// UpdateBalanceWithTx updates an account's balance, which may not go below minus its overdraft
+// limit; tx is ignored
+func (r *MemoryAccountRepository) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	if newBalance.LessThan(account.OverdraftLimit.Neg()) {
+		return errors.NewInvalidAmountError(newBalance)
+	}
+	account.Balance = newBalance
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	account.LastActivityAt = account.UpdatedAt
+	return nil
+}
+
+// UpdateReservedWithTx updates the amount reserved on an account; tx is ignored
+func (r *MemoryAccountRepository) UpdateReservedWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newReserved decimal.Decimal) error {
+	if newReserved.IsNegative() {
+		return errors.NewInvalidAmountError(newReserved)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	account.Reserved = newReserved
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// SetAccountType changes the type of an account
+func (r *MemoryAccountRepository) SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	account.Type = accountType
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// SetAccountTypeWithTx changes the type of an account; tx is ignored
+func (r *MemoryAccountRepository) SetAccountTypeWithTx(ctx context.Context, tx *sql.Tx, accountID int64, accountType models.AccountType) error {
+	return r.SetAccountType(ctx, accountID, accountType)
+}
+
+// SetCurrency sets the currency of an account; it can't be changed once set
+func (r *MemoryAccountRepository) SetCurrency(ctx context.Context, accountID int64, currency string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	if account.Currency != "" && account.Currency != currency {
+		return fmt.Errorf("%w: account %d already has currency %s", errors.ErrValidationFailed, accountID, account.Currency)
+	}
+	account.Currency = currency
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// MarkDormant marks up to limit active accounts without activity since inactiveSince as dormant
+func (r *MemoryAccountRepository) MarkDormant(ctx context.Context, inactiveSince time.Time, limit int) ([]int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var accountIDs []int64
+	for accountID, account := range r.store.accounts {
+		lastActivity, err := time.Parse(time.RFC3339, account.LastActivityAt)
+		if account.Status != models.AccountStatusActive || err != nil || !lastActivity.Before(inactiveSince) {
+			continue
+		}
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+	if len(accountIDs) > limit {
+		accountIDs = accountIDs[:limit]
+	}
+	for _, accountID := range accountIDs {
+		r.store.accounts[accountID].Status = models.AccountStatusDormant
+	}
+	return accountIDs, nil
+}
+
+// ReactivateAccount returns a dormant account to active; a no-op for active accounts
+func (r *MemoryAccountRepository) ReactivateAccount(ctx context.Context, accountID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	switch account.Status {
+	case models.AccountStatusActive:
+		return nil
+	case models.AccountStatusDormant:
+		account.Status = models.AccountStatusActive
+		account.LastActivityAt = time.Now().Format(time.RFC3339)
+		account.UpdatedAt = account.LastActivityAt
+		return nil
+	default:
+		return errors.NewAccountNotActiveError(accountID)
+	}
+}
+
+// SetStatus changes the status of an account as allowed by models.Account.CheckStatusChange
+func (r *MemoryAccountRepository) SetStatus(ctx context.Context, accountID int64, status models.AccountStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	if err := account.CheckStatusChange(status); err != nil {
+		return err
+	}
+	account.Status = status
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	if status == models.AccountStatusActive {
+		account.LastActivityAt = account.UpdatedAt
+	}
+	return nil
+}
+
+// SetStatusWithTx changes the status of an account; tx is ignored
+func (r *MemoryAccountRepository) SetStatusWithTx(ctx context.Context, tx *sql.Tx, accountID int64, status models.AccountStatus) error {
+	return r.SetStatus(ctx, accountID, status)
+}
+
+// SetOverdraftLimit sets how far below zero the balance of an account may go
+func (r *MemoryAccountRepository) SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	if err := account.ValidateOverdraftLimit(limit); err != nil {
+		return err
+	}
+	account.OverdraftLimit = limit
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// SetLimits replaces the outbound transfer limits of an account
+func (r *MemoryAccountRepository) SetLimits(ctx context.Context, accountID int64, limits models.AccountLimits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return errors.NewAccountNotFoundError(accountID)
+	}
+	account.Limits = limits
+	account.UpdatedAt = time.Now().Format(time.RFC3339)
+	return nil
+}
+
+// CountAccountsByStatus returns the number of accounts in each status
+func (r *MemoryAccountRepository) CountAccountsByStatus(ctx context.Context) (map[models.AccountStatus]int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	counts := make(map[models.AccountStatus]int64)
+	for _, account := range r.store.accounts {
+		counts[account.Status]++
+	}
+	return counts, nil
+}
+
+// MemoryTransactionRepository is an in-memory TransactionRepository
+type MemoryTransactionRepository struct {
+	store *MemoryStore
+}
+
+// NewMemoryTransactionRepository creates an in-memory transaction repository backed by store
+func NewMemoryTransactionRepository(store *MemoryStore) *MemoryTransactionRepository {
+	return &MemoryTransactionRepository{store: store}
+}
+
+// GetTransactionsByAccount retrieves all transactions for a given account, newest first
+func (r *MemoryTransactionRepository) GetTransactionsByAccount(ctx context.Context, accountID int64) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var transactions []*models.Transaction
+	for i := len(r.store.transactions) - 1; i >= 0; i-- {
+		tx := r.store.transactions[i]
+		if tx.SourceAccountID == accountID || tx.DestinationAccountID == accountID {
+			copied := *tx
+			transactions = append(transactions, &copied)
+		}
+	}
+	return transactions, nil
+}
+
+// GetTransactionsByAccountPage retrieves up to limit of an account's transactions matching filter,
+// ordered by (created_at, id) newest first and starting after the cursor (from the newest if nil)
+func (r *MemoryTransactionRepository) GetTransactionsByAccountPage(ctx context.Context, accountID int64, filter models.TransactionFilter, limit int, after *models.TransactionCursor) (*models.TransactionPage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var matched []*models.Transaction
+	for _, tx := range r.store.transactions {
+		if tx.SourceAccountID != accountID && tx.DestinationAccountID != accountID {
+			continue
+		}
+		if after != nil && !memoryBeforeCursor(tx, *after) {
+			continue
+		}
+		if !filter.Matches(accountID, tx, memoryAxisTime(tx, models.TimeAxisRecorded)) {
+			continue
+		}
+		copied := *tx
+		matched = append(matched, &copied)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		ti, tj := memoryAxisTime(matched[i], models.TimeAxisRecorded), memoryAxisTime(matched[j], models.TimeAxisRecorded)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	page := &models.TransactionPage{Transactions: matched}
+	if len(matched) > limit {
+		page.Transactions = matched[:limit]
+		last := matched[limit-1]
+		page.NextCursor = models.TransactionCursor{CreatedAt: memoryAxisTime(last, models.TimeAxisRecorded), ID: last.ID}.Encode()
+	}
+	if page.Transactions == nil {
+		page.Transactions = []*models.Transaction{}
+	}
+	return page, nil
+}
+
+// memoryBeforeCursor reports whether tx sorts after the cursor in newest-first (created_at, id) order
+func memoryBeforeCursor(tx *models.Transaction, cursor models.TransactionCursor) bool {
+	if createdAt := memoryAxisTime(tx, models.TimeAxisRecorded); !createdAt.Equal(cursor.CreatedAt) {
+		return createdAt.Before(cursor.CreatedAt)
+	}
+	return tx.ID < cursor.ID
+}
+
+// GetTransactionsByAccountInPeriod retrieves an account's transactions in [from, to) on the given time axis, oldest first
+func (r *MemoryTransactionRepository) GetTransactionsByAccountInPeriod(ctx context.Context, accountID int64, from, to time.Time, axis models.TimeAxis) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var transactions []*models.Transaction
+	for _, tx := range r.store.transactions {
+		if tx.SourceAccountID != accountID && tx.DestinationAccountID != accountID {
+			continue
+		}
+		at := memoryAxisTime(tx, axis)
+		if !at.Before(from) && at.Before(to) {
+			copied := *tx
+			transactions = append(transactions, &copied)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return memoryAxisTime(transactions[i], axis).Before(memoryAxisTime(transactions[j], axis))
+	})
+	return transactions, nil
+}
+
+// GetBalanceAsOf computes an account's balance at the given instant on the given time axis
+func (r *MemoryTransactionRepository) GetBalanceAsOf(ctx context.Context, accountID int64, at time.Time, axis models.TimeAxis) (decimal.Decimal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	account, exists := r.store.accounts[accountID]
+	if !exists {
+		return decimal.Zero, errors.NewAccountNotFoundError(accountID)
+	}
+	balance := account.Balance
+	for _, tx := range r.store.transactions {
+		if !tx.IsSettled() || !memoryAxisTime(tx, axis).After(at) {
+			continue
+		}
+		if tx.DestinationAccountID == accountID {
+			balance = balance.Sub(tx.CreditAmount())
+		} else if tx.SourceAccountID == accountID {
+			balance = balance.Add(tx.Amount)
+		}
+	}
+	return balance, nil
+}
+
+// CreateBalanceSnapshots records nothing: the memory store computes historical balances from its
+// full history
+func (r *MemoryTransactionRepository) CreateBalanceSnapshots(ctx context.Context, asOf time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+// SearchTransactionsByTag retrieves up to limit transactions carrying tag recorded in [from, to), newest first
+func (r *MemoryTransactionRepository) SearchTransactionsByTag(ctx context.Context, tag string, from, to time.Time, limit int) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var transactions []*models.Transaction
+	for i := len(r.store.transactions) - 1; i >= 0 && len(transactions) < limit; i-- {
+		tx := r.store.transactions[i]
+		at := memoryAxisTime(tx, models.TimeAxisRecorded)
+		if (!from.IsZero() && at.Before(from)) || (!to.IsZero() && !at.Before(to)) {
+			continue
+		}
+		for _, t := range tx.Tags {
+			if t == tag {
+				copied := *tx
+				transactions = append(transactions, &copied)
+				break
+			}
+		}
+	}
+	return transactions, nil
+}
+
+// GetTransactionsByBusinessDate retrieves up to limit transactions booked on a business date, oldest first
+func (r *MemoryTransactionRepository) GetTransactionsByBusinessDate(ctx context.Context, businessDate string, limit int) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var transactions []*models.Transaction
+	for _, tx := range r.store.transactions {
+		if len(transactions) == limit {
+			break
+		}
+		if tx.BusinessDate == businessDate {
+			copied := *tx
+			transactions = append(transactions, &copied)
+		}
+	}
+	return transactions, nil
+}
+
+// SumOutboundWithTx returns the total amount of the complete transfers from an account recorded
+// on or after the business date since, excluding fees; tx is ignored
+func (r *MemoryTransactionRepository) SumOutboundWithTx(ctx context.Context, tx *sql.Tx, accountID int64, since string) (decimal.Decimal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	total := decimal.Zero
+	for _, transaction := range r.store.transactions {
+		if transaction.SourceAccountID == accountID && transaction.IsComplete() && transaction.FeeOf == 0 && transaction.BusinessDate >= since {
+			total = total.Add(transaction.Amount)
+		}
+	}
+	return total, nil
+}
+
+// CountTenantOutboundWithTx returns the number of complete transfers from the accounts of a
+// tenant recorded on the business date, excluding fees; tx is ignored
+func (r *MemoryTransactionRepository) CountTenantOutboundWithTx(ctx context.Context, tx *sql.Tx, tenantID, businessDate string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, transaction := range r.store.transactions {
+		source, ok := r.store.accounts[transaction.SourceAccountID]
+		if ok && source.TenantID == tenantID && transaction.IsComplete() && transaction.FeeOf == 0 && transaction.BusinessDate == businessDate {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ExportTransactions retrieves up to limit transactions of scope recorded before until with an ID
+// above afterID, by ID
+func (r *MemoryTransactionRepository) ExportTransactions(ctx context.Context, scope models.ExportScope, until time.Time, afterID int64, limit int) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	inScope := func(accountID int64) bool {
+		if scope.TenantID == "" {
+			return accountID == scope.AccountID
+		}
+		account, ok := r.store.accounts[accountID]
+		return ok && account.TenantID == scope.TenantID
+	}
+	transactions := []*models.Transaction{}
+	for _, tx := range r.store.transactions {
+		if len(transactions) == limit {
+			break
+		}
+		if tx.ID <= afterID || !memoryAxisTime(tx, models.TimeAxisRecorded).Before(until) {
+			continue
+		}
+		if inScope(tx.SourceAccountID) || inScope(tx.DestinationAccountID) {
+			copied := *tx
+			transactions = append(transactions, &copied)
+		}
+	}
+	return transactions, nil
+}
+
+// GetBusinessDaySummaries sums the completed transactions booked on each business date in [from, to], oldest first
+func (r *MemoryTransactionRepository) GetBusinessDaySummaries(ctx context.Context, from, to string) ([]*models.BusinessDaySummary, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	byDate := make(map[string]*models.BusinessDaySummary)
+	for _, tx := range r.store.transactions {
+		// Business dates are fixed-width, so they compare lexically
+		if !tx.IsSettled() || tx.BusinessDate < from || tx.BusinessDate > to {
+			continue
+		}
+		summary, exists := byDate[tx.BusinessDate]
+		if !exists {
+			summary = &models.BusinessDaySummary{BusinessDate: tx.BusinessDate, Volume: decimal.Zero}
+			byDate[tx.BusinessDate] = summary
+		}
+		summary.TransactionCount++
+		summary.Volume = summary.Volume.Add(tx.Amount)
+	}
+
+	summaries := make([]*models.BusinessDaySummary, 0, len(byDate))
+	for _, summary := range byDate {
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].BusinessDate < summaries[j].BusinessDate })
+	return summaries, nil
+}
+
+// SetTransactionTags replaces the tags of a transaction
+func (r *MemoryTransactionRepository) SetTransactionTags(ctx context.Context, transactionID int64, tags []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, tx := range r.store.transactions {
+		if tx.ID == transactionID {
+			tx.Tags = append([]string(nil), tags...)
+			return nil
+		}
+	}
+	return errors.NewTransactionNotFoundError(transactionID)
+}
+
+// RegisterBatch records a batch under its owner and key, or returns the batch the owner already
+// registered under the key
+func (r *MemoryTransactionRepository) RegisterBatch(ctx context.Context, batch *models.Batch) (*models.Batch, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := [2]string{batch.Owner, batch.Key}
+	registered, exists := r.store.batches[key]
+	if !exists {
+		copied := *batch
+		registered = &copied
+		r.store.batches[key] = registered
+	}
+	result := *registered
+	return &result, nil
+}
+
+// CreateSplitTransferWithTx records a split transfer and returns it with its ID; tx is ignored
+func (r *MemoryTransactionRepository) CreateSplitTransferWithTx(ctx context.Context, tx *sql.Tx, split *models.SplitTransfer) (*models.SplitTransfer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.accounts[split.SourceAccountID]; !exists {
+		return nil, errors.NewSourceAccountNotFoundError(split.SourceAccountID)
+	}
+	created := &models.SplitTransfer{
+		ID:              int64(len(r.store.splits) + 1),
+		SourceAccountID: split.SourceAccountID,
+		Amount:          split.Amount,
+		CreatedAt:       time.Now().Format(time.RFC3339),
+	}
+	r.store.splits[created.ID] = created
+	result := *created
+	return &result, nil
+}
+
+// GetSplitTransfer retrieves a split transfer with its legs in the order they were made
+func (r *MemoryTransactionRepository) GetSplitTransfer(ctx context.Context, splitID int64) (*models.SplitTransfer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	split, exists := r.store.splits[splitID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", errors.ErrSplitTransferNotFound, splitID)
+	}
+	result := *split
+	result.Legs = []*models.Transaction{}
+	for _, tx := range r.store.transactions {
+		if tx.SplitID == splitID {
+			copied := *tx
+			result.Legs = append(result.Legs, &copied)
+		}
+	}
+	return &result, nil
+}
+
+// GetTransactionByID retrieves a transaction by its ID
+func (r *MemoryTransactionRepository) GetTransactionByID(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	transaction, err := r.findCopy(func(tx *models.Transaction) bool { return tx.ID == transactionID })
+	if err != nil {
+		return nil, errors.NewTransactionNotFoundError(transactionID)
+	}
+	return transaction, nil
+}
+
+// GetFeeTransactions retrieves the fees collected for a transaction, oldest first
+func (r *MemoryTransactionRepository) GetFeeTransactions(ctx context.Context, transactionID int64) ([]*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transactions := []*models.Transaction{}
+	for _, tx := range r.store.transactions {
+		if tx.FeeOf == transactionID {
+			copied := *tx
+			transactions = append(transactions, &copied)
+		}
+	}
+	return transactions, nil
+}
+
+// MarkTransactionReversedWithTx marks a completed transaction as reversed; tx is ignored
+func (r *MemoryTransactionRepository) MarkTransactionReversedWithTx(ctx context.Context, tx *sql.Tx, transactionID int64) (*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	found := r.find(func(tx *models.Transaction) bool { return tx.ID == transactionID })
+	switch {
+	case found == nil:
+		return nil, errors.NewTransactionNotFoundError(transactionID)
+	case found.ReversalOf != 0:
+		return nil, fmt.Errorf("%w: transaction %d is the reversal of transaction %d", errors.ErrTransactionNotReversible, transactionID, found.ReversalOf)
+	case !found.IsComplete():
+		return nil, fmt.Errorf("%w: transaction %d is %s", errors.ErrTransactionNotReversible, transactionID, found.Status)
+	}
+	found.Status = models.TransactionStatusReversed
+	copied := *found
+	return &copied, nil
+}
+
+// ResolvePendingApprovalWithTx records the outcome of a transaction pending approval; tx is ignored
+func (r *MemoryTransactionRepository) ResolvePendingApprovalWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	found := r.find(func(tx *models.Transaction) bool { return tx.ID == transaction.ID })
+	switch {
+	case found == nil:
+		return nil, errors.NewTransactionNotFoundError(transaction.ID)
+	case !found.IsPendingApproval():
+		return nil, fmt.Errorf("%w: transaction %d is not pending approval", errors.ErrApprovalResolved, transaction.ID)
+	}
+	found.Status = transaction.Status
+	found.DestinationAccountID = transaction.DestinationAccountID
+	found.BusinessDate = transaction.BusinessDate
+	found.ConvertedAmount, found.FXRate = transaction.ConvertedAmount, transaction.FXRate
+	copied := *found
+	return &copied, nil
+}
+
+// AddStatusChangeWithTx appends an entry to a transaction's status history; tx is ignored
+func (r *MemoryTransactionRepository) AddStatusChangeWithTx(ctx context.Context, tx *sql.Tx, change *models.TransactionStatusChange) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.find(func(tx *models.Transaction) bool { return tx.ID == change.TransactionID }) == nil {
+		return errors.NewTransactionNotFoundError(change.TransactionID)
+	}
+	recorded := *change
+	recorded.ID = int64(len(r.store.history) + 1)
+	recorded.ChangedAt = time.Now().Format(time.RFC3339)
+	r.store.history = append(r.store.history, &recorded)
+	return nil
+}
+
+// GetStatusHistory retrieves a transaction's status changes, oldest first
+func (r *MemoryTransactionRepository) GetStatusHistory(ctx context.Context, transactionID int64) ([]*models.TransactionStatusChange, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var history []*models.TransactionStatusChange
+	for _, change := range r.store.history {
+		if change.TransactionID == transactionID {
+			copied := *change
+			history = append(history, &copied)
+		}
+	}
+	return history, nil
+}
+
+// GetTransactionByBatchItemKey retrieves the transaction recorded for a batch item
+func (r *MemoryTransactionRepository) GetTransactionByBatchItemKey(ctx context.Context, key string) (*models.Transaction, error) {
+	return r.findCopy(func(tx *models.Transaction) bool { return tx.BatchItemKey == key })
+}
+
+// GetTransactionByBatchItemKeyWithTx retrieves the transaction recorded for a batch item; tx is ignored
+func (r *MemoryTransactionRepository) GetTransactionByBatchItemKeyWithTx(ctx context.Context, tx *sql.Tx, key string) (*models.Transaction, error) {
+	return r.GetTransactionByBatchItemKey(ctx, key)
+}
+
+// GetTransactionByExternalReference retrieves the transaction recorded with an external reference
+func (r *MemoryTransactionRepository) GetTransactionByExternalReference(ctx context.Context, reference string) (*models.Transaction, error) {
+	return r.findCopy(func(tx *models.Transaction) bool { return tx.ExternalReference == reference })
+}
+
+// GetTransactionByExternalReferenceWithTx retrieves the transaction recorded with an external reference; tx is ignored
+func (r *MemoryTransactionRepository) GetTransactionByExternalReferenceWithTx(ctx context.Context, tx *sql.Tx, reference string) (*models.Transaction, error) {
+	return r.GetTransactionByExternalReference(ctx, reference)
+}
+
+// findCopy returns a copy of the first transaction matching, or ErrTransactionNotFound
+func (r *MemoryTransactionRepository) findCopy(match func(*models.Transaction) bool) (*models.Transaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	found := r.find(match)
+	if found == nil {
+		return nil, errors.ErrTransactionNotFound
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (r *MemoryTransactionRepository) find(match func(*models.Transaction) bool) *models.Transaction {
+	for _, tx := range r.store.transactions {
+		if match(tx) {
+			return tx
+		}
+	}
+	return nil
+}
+
+// CreateTransactionWithTx records a transaction; tx is ignored
+func (r *MemoryTransactionRepository) CreateTransactionWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.accounts[transaction.SourceAccountID]; !exists {
+		return nil, errors.NewSourceAccountNotFoundError(transaction.SourceAccountID)
+	}
+	if _, exists := r.store.accounts[transaction.DestinationAccountID]; !exists {
+		return nil, errors.NewDestinationAccountNotFoundError(transaction.DestinationAccountID)
+	}
+	if key := transaction.BatchItemKey; key != "" && r.find(func(tx *models.Transaction) bool { return tx.BatchItemKey == key }) != nil {
+		return nil, errors.ErrDuplicateIdempotencyKey
+	}
+	if ref := transaction.ExternalReference; ref != "" && r.find(func(tx *models.Transaction) bool { return tx.ExternalReference == ref }) != nil {
+		return nil, errors.ErrDuplicateExternalReference
+	}
+	if id := transaction.ReversalOf; id != 0 && r.find(func(tx *models.Transaction) bool { return tx.ReversalOf == id }) != nil {
+		return nil, errors.ErrTransactionNotReversible
+	}
+
+	now := time.Now()
+	created := *transaction
+	created.ID = r.store.nextTxID
+	created.CreatedAt = now.Format(time.RFC3339)
+	if created.ValueDate == "" {
+		created.ValueDate = created.CreatedAt
+	}
+	if created.BusinessDate == "" {
+		created.BusinessDate = businessday.UTC().Date(now)
+	}
+	r.store.nextTxID++
+	r.store.transactions = append(r.store.transactions, &created)
+
+	result := created
+	return &result, nil
+}
+
+// memoryAxisTime returns the timestamp of tx on the given axis
+func memoryAxisTime(tx *models.Transaction, axis models.TimeAxis) time.Time {
+	value := tx.CreatedAt
+	if axis == models.TimeAxisEffective {
+		value = tx.ValueDate
+	}
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{db: db}
+}
+
+// outboxColumns is the column list selected by every outbox read, in scanOutboxMessage order
+const outboxColumns = `id, event, payload, attempts, last_error, created_at, published_at`
+
+// scanOutboxMessage scans a row selected with outboxColumns
+func scanOutboxMessage(row rowScanner) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	var payload []byte
+	var createdAt time.Time
+	var publishedAt sql.NullTime
+	err := row.Scan(&message.ID, &message.Event, &payload, &message.Attempts, &message.LastError, &createdAt, &publishedAt)
+	if err != nil {
+		return nil, err
+	}
+	message.Payload = payload
+	message.CreatedAt = createdAt.Format(time.RFC3339)
+	if publishedAt.Valid {
+		message.PublishedAt = publishedAt.Time.Format(time.RFC3339)
+	}
+	return &message, nil
+}
+
+// CreateMessage records an event outside any transaction
+func (r *PostgresOutboxRepository) CreateMessage(ctx context.Context, message *models.OutboxMessage) (*models.OutboxMessage, error) {
+	return createOutboxMessage(ctx, r.db, message)
+}
+
+// CreateMessageWithTx records an event within the transaction that raised it
+func (r *PostgresOutboxRepository) CreateMessageWithTx(ctx context.Context, tx *sql.Tx, message *models.OutboxMessage) (*models.OutboxMessage, error) {
+	return createOutboxMessage(ctx, tx, message)
+}
+
+// createOutboxMessage records an event through q
+func createOutboxMessage(ctx context.Context, q rowQuerier, message *models.OutboxMessage) (*models.OutboxMessage, error) {
+	created, err := scanOutboxMessage(q.QueryRowContext(ctx, `
+		INSERT INTO outbox (event, payload) VALUES ($1, $2::jsonb)
+		RETURNING `+outboxColumns,
+		message.Event, string(message.Payload),
+	))
+	if err != nil {
+		logger.Default().ErrorContext(ctx, "Database error recording event in the outbox", "event", message.Event, "err", err)
+		return nil, fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	return created, nil
+}
+
+// CountUnpublished returns the number of messages waiting to be published
+func (r *PostgresOutboxRepository) CountUnpublished(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM outbox WHERE published_at IS NULL`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unpublished outbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// ClaimUnpublishedWithTx locks up to limit unpublished messages, oldest first, skipping those
+// another transaction has locked
+func (r *PostgresOutboxRepository) ClaimUnpublishedWithTx(ctx context.Context, tx *sql.Tx, limit int) ([]*models.OutboxMessage, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+outboxColumns+`
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*models.OutboxMessage{}
+	for rows.Next() {
+		message, err := scanOutboxMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkPublishedWithTx marks a message published at the given time
+func (r *PostgresOutboxRepository) MarkPublishedWithTx(ctx context.Context, tx *sql.Tx, messageID int64, publishedAt time.Time) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox SET published_at = $2 WHERE id = $1`, messageID, publishedAt); err != nil {
+		return fmt.Errorf("failed to mark outbox message %d published: %w", messageID, err)
+	}
+	return nil
+}
+
+// RecordFailureWithTx counts a failed attempt to publish a message and keeps its error
+func (r *PostgresOutboxRepository) RecordFailureWithTx(ctx context.Context, tx *sql.Tx, messageID int64, reason string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, messageID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record failure of outbox message %d: %w", messageID, err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	stderrors "errors"
+	"reflect"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/lib/pq"
+)
+
+// SQLSTATE codes translated into domain errors.
+// Codes are used instead of driver-specific condition names so the mapping survives a driver swap.
+const (
+	// foreign_key_violation: a referenced row doesn't exist, or is still referenced
+	sqlStateForeignKeyViolation = "23503"
+	// unique_violation: a row with the same key already exists
+	sqlStateUniqueViolation = "23505"
+	// check_violation: a value is out of the range a CHECK constraint allows
+	sqlStateCheckViolation = "23514"
+	// read_only_sql_transaction: raised by a hot standby replica on any write
+	sqlStateReadOnlyTransaction = "25006"
+)
+
+// constraintErrors maps named schema constraints to the domain error they enforce.
+// A violated constraint that isn't listed falls back to the generic error of its SQLSTATE in
+// sqlStateErrors, so it surfaces as a conflict or bad input rather than as an error about some
+// other entity.
+var constraintErrors = map[string]error{
+	"accounts_pkey":                                   errors.ErrAccountAlreadyExists,
+	"accounts_balance_check":                          errors.ErrInvalidAmount,
+	"accounts_reserved_balance_check":                 errors.ErrInvalidAmount,
+	"accounts_overdraft_limit_check":                  errors.ErrInvalidAmount,
+	"accounts_per_transaction_limit_check":            errors.ErrInvalidAmount,
+	"accounts_daily_limit_check":                      errors.ErrInvalidAmount,
+	"accounts_monthly_limit_check":                    errors.ErrInvalidAmount,
+	"transactions_amount_check":                       errors.ErrInvalidAmount,
+	"transactions_converted_amount_check":             errors.ErrInvalidAmount,
+	"transactions_fx_rate_check":                      errors.ErrValidationFailed,
+	"fee_rules_account_id_fkey":                       errors.ErrAccountNotFound,
+	"fee_rules_fee_type_check":                        errors.ErrValidationFailed,
+	"fee_rules_value_check":                           errors.ErrInvalidAmount,
+	"transactions_source_account_id_fkey":             errors.ErrSourceAccountNotFound,
+	"transactions_destination_account_id_fkey":        errors.ErrDestinationAccountNotFound,
+	"transactions_batch_item_key_key":                 errors.ErrDuplicateIdempotencyKey,
+	"transfer_batches_pkey":                           errors.ErrDuplicateIdempotencyKey,
+	"idempotency_keys_pkey":                           errors.ErrDuplicateIdempotencyKey,
+	"idempotency_keys_transaction_id_fkey":            errors.ErrTransactionNotFound,
+	"idempotency_keys_response_check":                 errors.ErrValidationFailed,
+	"transactions_reversal_of_key":                    errors.ErrTransactionNotReversible,
+	"transactions_reversal_of_fkey":                   errors.ErrTransactionNotFound,
+	"transactions_external_reference_key":             errors.ErrDuplicateExternalReference,
+	"transactions_fee_of_fkey":                        errors.ErrTransactionNotFound,
+	"transactions_fee_rule_id_fkey":                   errors.ErrFeeRuleNotFound,
+	"transactions_split_id_fkey":                      errors.ErrSplitTransferNotFound,
+	"transaction_status_history_transaction_id_fkey":  errors.ErrTransactionNotFound,
+	"webhook_deliveries_webhook_id_fkey":              errors.ErrWebhookNotFound,
+	"webhook_deliveries_retry_of_fkey":                errors.ErrDeliveryNotFound,
+	"suspense_items_transaction_id_fkey":              errors.ErrTransactionNotFound,
+	"suspense_items_resolution_transaction_id_fkey":   errors.ErrTransactionNotFound,
+	"suspense_items_source_account_id_fkey":           errors.ErrSourceAccountNotFound,
+	"suspense_items_destination_account_id_fkey":      errors.ErrDestinationAccountNotFound,
+	"suspense_items_amount_check":                     errors.ErrInvalidAmount,
+	"suspense_item_events_item_id_fkey":               errors.ErrSuspenseItemNotFound,
+	"suspense_item_events_transaction_id_fkey":        errors.ErrTransactionNotFound,
+	"preauthorizations_source_account_id_fkey":        errors.ErrSourceAccountNotFound,
+	"preauthorizations_destination_account_id_fkey":   errors.ErrDestinationAccountNotFound,
+	"preauthorizations_amount_check":                  errors.ErrInvalidAmount,
+	"preauthorizations_transaction_id_fkey":           errors.ErrTransactionNotFound,
+	"posting_rules_pkey":                              errors.ErrValidationFailed,
+	"posting_rules_leg_check":                         errors.ErrValidationFailed,
+	"posting_rules_check":                             errors.ErrValidationFailed,
+	"tenant_settings_max_transfer_amount_check":       errors.ErrInvalidAmount,
+	"tenant_settings_max_accounts_check":              errors.ErrValidationFailed,
+	"tenant_settings_max_daily_transactions_check":    errors.ErrValidationFailed,
+	"tenant_settings_quota_warn_percent_check":        errors.ErrValidationFailed,
+	"balance_snapshots_account_id_fkey":               errors.ErrAccountNotFound,
+	"balance_snapshots_account_id_as_of_key":          errors.ErrSnapshotExists,
+	"split_transfers_amount_check":                    errors.ErrInvalidAmount,
+	"split_transfers_leg_count_check":                 errors.ErrValidationFailed,
+	"split_transfers_source_account_id_fkey":          errors.ErrSourceAccountNotFound,
+	"scheduled_transfers_amount_check":                errors.ErrInvalidAmount,
+	"scheduled_transfers_source_account_id_fkey":      errors.ErrSourceAccountNotFound,
+	"scheduled_transfers_destination_account_id_fkey": errors.ErrDestinationAccountNotFound,
+	"scheduled_transfers_transaction_id_fkey":         errors.ErrTransactionNotFound,
+	"scheduled_transfers_standing_order_id_fkey":      errors.ErrStandingOrderNotFound,
+	"idx_scheduled_transfers_standing_order":          errors.ErrOccurrenceScheduled,
+	"standing_orders_amount_check":                    errors.ErrInvalidAmount,
+	"standing_orders_max_occurrences_check":           errors.ErrValidationFailed,
+	"standing_orders_source_account_id_fkey":          errors.ErrSourceAccountNotFound,
+	"standing_orders_destination_account_id_fkey":     errors.ErrDestinationAccountNotFound,
+	"ledger_accounts_pkey":                            errors.ErrLedgerAccountExists,
+	"ledger_accounts_account_id_fkey":                 errors.ErrAccountNotFound,
+	"ledger_entries_amount_check":                     errors.ErrInvalidAmount,
+	"ledger_entries_entry_type_check":                 errors.ErrValidationFailed,
+	"ledger_entries_account_id_fkey":                  errors.ErrAccountNotFound,
+	"ledger_entries_transaction_id_fkey":              errors.ErrTransactionNotFound,
+	"ledger_entries_transaction_id_entry_type_key":    errors.ErrTransactionAlreadyPosted,
+	"ledger_entries_transaction_leg_key":              errors.ErrTransactionAlreadyPosted,
+	"ledger_entries_ledger_code_fkey":                 errors.ErrLedgerAccountNotFound,
+	"transfer_approvals_pkey":                         errors.ErrApprovalExists,
+	"transfer_approvals_transaction_id_fkey":          errors.ErrTransactionNotFound,
+	"transfer_approvals_four_eyes":                    errors.ErrSelfApproval,
+	"system_float_accounts_pkey":                      errors.ErrFloatAccountExists,
+	"system_float_accounts_account_id_key":            errors.ErrFloatAccountExists,
+	"system_float_accounts_account_id_fkey":           errors.ErrAccountNotFound,
+	"system_fundings_amount_check":                    errors.ErrInvalidAmount,
+	"system_fundings_direction_check":                 errors.ErrValidationFailed,
+	"system_fundings_transaction_id_key":              errors.ErrTransactionAlreadyPosted,
+	"system_fundings_transaction_id_fkey":             errors.ErrTransactionNotFound,
+	"system_fundings_currency_fkey":                   errors.ErrFloatAccountNotFound,
+	"system_fundings_account_id_fkey":                 errors.ErrAccountNotFound,
+	"transfer_jobs_amount_check":                      errors.ErrInvalidAmount,
+	"transfer_jobs_status_check":                      errors.ErrValidationFailed,
+	"transfer_jobs_transaction_id_fkey":               errors.ErrTransactionNotFound,
+	"transfer_jobs_idempotency_key_key":               errors.ErrDuplicateIdempotencyKey,
+	"dead_letters_kind_check":                         errors.ErrValidationFailed,
+	"dead_letters_kind_reference_key":                 errors.ErrDeadLetterExists,
+	"api_credentials_name_key":                        errors.ErrCredentialExists,
+	"api_credentials_key_hash_key":                    errors.ErrCredentialExists,
+	"delegations_max_amount_check":                    errors.ErrInvalidAmount,
+	"delegations_source_account_id_fkey":              errors.ErrSourceAccountNotFound,
+	"account_notes_account_id_fkey":                   errors.ErrAccountNotFound,
+	"balance_adjustments_amount_check":                errors.ErrInvalidAmount,
+	"balance_adjustments_direction_check":             errors.ErrValidationFailed,
+	"balance_adjustments_account_id_fkey":             errors.ErrAccountNotFound,
+	"balance_adjustments_transaction_id_key":          errors.ErrTransactionAlreadyPosted,
+	"balance_adjustments_transaction_id_fkey":         errors.ErrTransactionNotFound,
+}
+
+// sqlStateErrors maps SQLSTATE codes to their domain error when no listed constraint is named.
+// Integrity violations get a generic error, as their precise meaning depends on the constraint.
+var sqlStateErrors = map[string]error{
+	sqlStateForeignKeyViolation: errors.ErrReferenceNotFound,
+	sqlStateUniqueViolation:     errors.ErrDuplicateRecord,
+	sqlStateCheckViolation:      errors.ErrValidationFailed,
+	sqlStateReadOnlyTransaction: errors.ErrReadOnly,
+}
+
+// sqlStateError is implemented by driver errors exposing the Postgres SQLSTATE code
+// (lib/pq's *pq.Error and pgx's *pgconn.PgError both do)
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// translatePgError maps a Postgres error to its domain error: that of the violated constraint if
+// it's listed, otherwise that of its SQLSTATE. It returns nil if neither is known, in which case
+// callers should treat it as an unexpected database error.
+func translatePgError(err error) error {
+	var stateErr sqlStateError
+	if !stderrors.As(err, &stateErr) {
+		return nil
+	}
+
+	if domainErr, ok := constraintErrors[constraintName(err)]; ok {
+		return domainErr
+	}
+	return sqlStateErrors[stateErr.SQLState()]
+}
+
+// constraintName returns the name of the violated constraint, or "" if the driver doesn't report
+// one. lib/pq reports it in Constraint and pgx's *pgconn.PgError in ConstraintName; the latter is
+// read by field name so pgx needn't be a dependency to translate its errors.
+func constraintName(err error) string {
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	var stateErr sqlStateError
+	if !stderrors.As(err, &stateErr) {
+		return ""
+	}
+	v := reflect.Indirect(reflect.ValueOf(stateErr))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if field := v.FieldByName("ConstraintName"); field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
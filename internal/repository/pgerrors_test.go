@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// pgError has the shape of pgx's *pgconn.PgError
+type pgError struct {
+	Code           string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string    { return "pgx error " + e.Code }
+func (e *pgError) SQLState() string { return e.Code }
+
+func TestTranslatePgError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedError error
+	}{
+		{
+			name:          "duplicate account primary key",
+			err:           &pq.Error{Code: "23505", Constraint: "accounts_pkey"},
+			expectedError: errors.ErrAccountAlreadyExists,
+		},
+		{
+			name:          "negative account balance",
+			err:           &pq.Error{Code: "23514", Constraint: "accounts_balance_check"},
+			expectedError: errors.ErrInvalidAmount,
+		},
+		{
+			name:          "missing source account",
+			err:           &pq.Error{Code: "23503", Constraint: "transactions_source_account_id_fkey"},
+			expectedError: errors.ErrSourceAccountNotFound,
+		},
+		{
+			name:          "missing destination account",
+			err:           &pq.Error{Code: "23503", Constraint: "transactions_destination_account_id_fkey"},
+			expectedError: errors.ErrDestinationAccountNotFound,
+		},
+		{
+			name:          "duplicate idempotency key",
+			err:           &pq.Error{Code: "23505", Constraint: "transactions_batch_item_key_key"},
+			expectedError: errors.ErrDuplicateIdempotencyKey,
+		},
+		{
+			name:          "duplicate external reference",
+			err:           &pq.Error{Code: "23505", Constraint: "transactions_external_reference_key"},
+			expectedError: errors.ErrDuplicateExternalReference,
+		},
+		{
+			name:          "duplicate credential name",
+			err:           &pq.Error{Code: "23505", Constraint: "api_credentials_name_key"},
+			expectedError: errors.ErrCredentialExists,
+		},
+		{
+			name:          "standing order occurrence scheduled twice",
+			err:           &pq.Error{Code: "23505", Constraint: "idx_scheduled_transfers_standing_order"},
+			expectedError: errors.ErrOccurrenceScheduled,
+		},
+		{
+			name:          "unknown foreign key falls back to its sqlstate",
+			err:           &pq.Error{Code: "23503", Constraint: "unknown_fkey"},
+			expectedError: errors.ErrReferenceNotFound,
+		},
+		{
+			name:          "unknown unique constraint falls back to its sqlstate",
+			err:           &pq.Error{Code: "23505", Constraint: "unknown_key"},
+			expectedError: errors.ErrDuplicateRecord,
+		},
+		{
+			name:          "unknown check constraint falls back to its sqlstate",
+			err:           &pq.Error{Code: "23514", Constraint: "unknown_check"},
+			expectedError: errors.ErrValidationFailed,
+		},
+		{
+			name:          "write on a read-only replica",
+			err:           &pq.Error{Code: "25006"},
+			expectedError: errors.ErrReadOnly,
+		},
+		{
+			name:          "wrapped driver error",
+			err:           fmt.Errorf("exec: %w", &pq.Error{Code: "23505", Constraint: "accounts_pkey"}),
+			expectedError: errors.ErrAccountAlreadyExists,
+		},
+		{
+			name:          "pgx error names its constraint",
+			err:           &pgError{Code: "23503", ConstraintName: "transactions_source_account_id_fkey"},
+			expectedError: errors.ErrSourceAccountNotFound,
+		},
+		{
+			name:          "pgx error of an unknown constraint",
+			err:           &pgError{Code: "23505", ConstraintName: "unknown_key"},
+			expectedError: errors.ErrDuplicateRecord,
+		},
+		{
+			name:          "unrelated sqlstate",
+			err:           &pq.Error{Code: "40001"},
+			expectedError: nil,
+		},
+		{
+			name:          "non-driver error",
+			err:           fmt.Errorf("connection reset"),
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedError, translatePgError(tt.err))
+		})
+	}
+}
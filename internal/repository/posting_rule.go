@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresPostingRuleRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewPostingRuleRepository(db *sql.DB) *PostgresPostingRuleRepository {
+	return &PostgresPostingRuleRepository{db: db, log: logger.Default()}
+}
+
+// ListPostingRules retrieves the posting rules of a transfer type (all types if empty), ordered by
+// transfer type and leg
+func (r *PostgresPostingRuleRepository) ListPostingRules(ctx context.Context, transferType models.TransferType) ([]*models.PostingRule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT transfer_type, leg, debit, credit, description
+		FROM posting_rules
+		WHERE $1 = '' OR transfer_type = $1
+		ORDER BY transfer_type, leg
+	`, transferType)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing posting rules", "err", err)
+		return nil, fmt.Errorf("failed to list posting rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.PostingRule{}
+	for rows.Next() {
+		var rule models.PostingRule
+		if err := rows.Scan(&rule.TransferType, &rule.Leg, &rule.Debit, &rule.Credit, &rule.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan posting rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posting rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ReplacePostingRules atomically replaces the posting template of a transfer type with rules
+func (r *PostgresPostingRuleRepository) ReplacePostingRules(ctx context.Context, transferType models.TransferType, rules []*models.PostingRule) error {
+	r.log.InfoContext(ctx, "Replacing posting rules of transfer type", "transfer_type", transferType, "count", len(rules))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM posting_rules WHERE transfer_type = $1`, transferType); err != nil {
+		r.log.ErrorContext(ctx, "Database error clearing posting rules", "transfer_type", transferType, "err", err)
+		return fmt.Errorf("failed to clear posting rules: %w", err)
+	}
+	for _, rule := range rules {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO posting_rules (transfer_type, leg, debit, credit, description)
+			VALUES ($1, $2, $3, $4, $5)
+		`, transferType, rule.Leg, rule.Debit, rule.Credit, rule.Description)
+		if err != nil {
+			r.log.ErrorContext(ctx, "Database error inserting posting rule leg", "leg", rule.Leg, "transfer_type", transferType, "err", err)
+			return fmt.Errorf("failed to insert posting rule: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing posting rules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostingRuleRepository_ReplacePostingRules(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+	defer db.Exec(`DELETE FROM posting_rules WHERE transfer_type = 'test_cashback'`)
+
+	repo := NewPostingRuleRepository(db)
+	ctx := context.Background()
+
+	// The migration seeds the standard template
+	standard, err := repo.ListPostingRules(ctx, models.TransferTypeStandard)
+	require.NoError(t, err)
+	require.Len(t, standard, 1)
+	assert.Equal(t, models.PostingPartySource, standard[0].Debit)
+	assert.Equal(t, models.PostingPartyDestination, standard[0].Credit)
+
+	cashback := models.TransferType("test_cashback")
+	require.NoError(t, repo.ReplacePostingRules(ctx, cashback, []*models.PostingRule{
+		{Leg: 1, Debit: "@source", Credit: "@destination"},
+		{Leg: 2, Debit: "fee_income", Credit: "@source", Description: "Cashback"},
+	}))
+	require.NoError(t, repo.ReplacePostingRules(ctx, cashback, []*models.PostingRule{
+		{Leg: 1, Debit: "fee_income", Credit: "@destination"},
+	}))
+
+	rules, err := repo.ListPostingRules(ctx, cashback)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, cashback, rules[0].TransferType)
+	assert.Equal(t, "fee_income", rules[0].Debit)
+
+	all, err := repo.ListPostingRules(ctx, "")
+	require.NoError(t, err)
+	assert.Greater(t, len(all), len(rules))
+}
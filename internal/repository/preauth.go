@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresPreAuthorizationRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewPreAuthorizationRepository(db *sql.DB) *PostgresPreAuthorizationRepository {
+	return &PostgresPreAuthorizationRepository{db: db, log: logger.Default()}
+}
+
+// preAuthColumns is the column list selected by every pre-authorization read, in scanPreAuth order
+const preAuthColumns = `id, source_account_id, destination_account_id, amount, status, expires_at,
+	COALESCE(transaction_id, 0), resolved_at, created_at`
+
+// scanPreAuth scans a row selected with preAuthColumns
+func scanPreAuth(row rowScanner) (*models.PreAuthorization, error) {
+	var preAuth models.PreAuthorization
+	var expiresAt, createdAt time.Time
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&preAuth.ID,
+		&preAuth.SourceAccountID,
+		&preAuth.DestinationAccountID,
+		&preAuth.Amount,
+		&preAuth.Status,
+		&expiresAt,
+		&preAuth.TransactionID,
+		&resolvedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	preAuth.ExpiresAt = expiresAt.Format(time.RFC3339)
+	if resolvedAt.Valid {
+		preAuth.ResolvedAt = resolvedAt.Time.Format(time.RFC3339)
+	}
+	preAuth.CreatedAt = createdAt.Format(time.RFC3339)
+	return &preAuth, nil
+}
+
+// CreatePreAuthorizationWithTx records an active pre-authorization within a transaction
+func (r *PostgresPreAuthorizationRepository) CreatePreAuthorizationWithTx(ctx context.Context, tx *sql.Tx, preAuth *models.PreAuthorization) (*models.PreAuthorization, error) {
+	r.log.InfoContext(ctx, "Creating pre-authorization", "source_account_id", preAuth.SourceAccountID, "destination_account_id", preAuth.DestinationAccountID, "amount", preAuth.Amount, "expires_at", preAuth.ExpiresAt)
+
+	created, err := scanPreAuth(tx.QueryRowContext(ctx, `
+		INSERT INTO preauthorizations (source_account_id, destination_account_id, amount, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+preAuthColumns,
+		preAuth.SourceAccountID, preAuth.DestinationAccountID, preAuth.Amount, models.PreAuthorizationStatusActive, preAuth.ExpiresAt))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating pre-authorization", "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error creating pre-authorization", "err", err)
+		return nil, fmt.Errorf("failed to create pre-authorization: %w", err)
+	}
+	return created, nil
+}
+
+// GetPreAuthorization retrieves a pre-authorization by its ID
+func (r *PostgresPreAuthorizationRepository) GetPreAuthorization(ctx context.Context, preAuthID int64) (*models.PreAuthorization, error) {
+	return getPreAuth(ctx, r.db, preAuthID, "")
+}
+
+// GetPreAuthorizationForUpdateWithTx retrieves a pre-authorization and locks it for the rest of the transaction
+func (r *PostgresPreAuthorizationRepository) GetPreAuthorizationForUpdateWithTx(ctx context.Context, tx *sql.Tx, preAuthID int64) (*models.PreAuthorization, error) {
+	return getPreAuth(ctx, tx, preAuthID, "FOR UPDATE")
+}
+
+// getPreAuth retrieves a pre-authorization by ID through q, appending lock to the query
+func getPreAuth(ctx context.Context, q rowQuerier, preAuthID int64, lock string) (*models.PreAuthorization, error) {
+	preAuth, err := scanPreAuth(q.QueryRowContext(ctx, `
+		SELECT `+preAuthColumns+`
+		FROM preauthorizations
+		WHERE id = $1
+		`+lock, preAuthID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Default().WarnContext(ctx, "Pre-authorization not found", "pre_auth_id", preAuthID)
+			return nil, fmt.Errorf("%w: id %d", errors.ErrPreAuthorizationNotFound, preAuthID)
+		}
+		logger.Default().ErrorContext(ctx, "Database error retrieving pre-authorization", "pre_auth_id", preAuthID, "err", err)
+		return nil, fmt.Errorf("failed to get pre-authorization: %w", err)
+	}
+	return preAuth, nil
+}
+
+// ListExpiredForUpdateWithTx retrieves and locks up to limit active pre-authorizations that expired
+// before now, oldest first. Rows locked by a concurrent execution are skipped until the next sweep.
+func (r *PostgresPreAuthorizationRepository) ListExpiredForUpdateWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*models.PreAuthorization, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+preAuthColumns+`
+		FROM preauthorizations
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY expires_at, id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, models.PreAuthorizationStatusActive, now, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing expired pre-authorizations", "err", err)
+		return nil, fmt.Errorf("failed to list expired pre-authorizations: %w", err)
+	}
+	defer rows.Close()
+
+	var preAuths []*models.PreAuthorization
+	for rows.Next() {
+		preAuth, err := scanPreAuth(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pre-authorization: %w", err)
+		}
+		preAuths = append(preAuths, preAuth)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pre-authorizations: %w", err)
+	}
+	return preAuths, nil
+}
+
+// ResolvePreAuthorizationWithTx marks a pre-authorization executed (by the given transaction) or expired
+func (r *PostgresPreAuthorizationRepository) ResolvePreAuthorizationWithTx(ctx context.Context, tx *sql.Tx, preAuthID int64, status models.PreAuthorizationStatus, transactionID int64) error {
+	r.log.InfoContext(ctx, "Resolving pre-authorization", "pre_auth_id", preAuthID, "status", status, logger.TransactionID(transactionID))
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE preauthorizations
+		SET status = $2, transaction_id = NULLIF($3, 0), resolved_at = NOW()
+		WHERE id = $1
+	`, preAuthID, status, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error resolving pre-authorization", "pre_auth_id", preAuthID, "err", err)
+		return fmt.Errorf("failed to resolve pre-authorization: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: id %d", errors.ErrPreAuthorizationNotFound, preAuthID)
+	}
+	return nil
+}
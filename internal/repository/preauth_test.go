@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreAuthorizationRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewPreAuthorizationRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(780001), int64(780002)
+	for _, id := range []int64{sourceID, destID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	amount := decimal.NewFromInt(30)
+
+	// Reserve the amount and record one pre-authorization that is still valid and one that expired
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, accountRepo.UpdateReservedWithTx(ctx, tx, sourceID, amount.Add(amount)))
+	valid, err := repo.CreatePreAuthorizationWithTx(ctx, tx, &models.PreAuthorization{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount,
+		ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	expired, err := repo.CreatePreAuthorizationWithTx(ctx, tx, &models.PreAuthorization{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount,
+		ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	assert.True(t, valid.IsActive())
+	assert.True(t, valid.Amount.Equal(amount))
+
+	source, err := accountRepo.GetAccount(ctx, sourceID)
+	require.NoError(t, err)
+	assert.True(t, source.Reserved.Equal(decimal.NewFromInt(60)))
+	assert.True(t, source.AvailableBalance().Equal(decimal.NewFromInt(40)))
+
+	// Only the lapsed pre-authorization is picked up by the sweeper
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	lapsed, err := repo.ListExpiredForUpdateWithTx(ctx, tx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, lapsed, 1)
+	assert.Equal(t, expired.ID, lapsed[0].ID)
+	require.NoError(t, repo.ResolvePreAuthorizationWithTx(ctx, tx, expired.ID, models.PreAuthorizationStatusExpired, 0))
+	require.NoError(t, tx.Commit())
+
+	resolved, err := repo.GetPreAuthorization(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PreAuthorizationStatusExpired, resolved.Status)
+	assert.Zero(t, resolved.TransactionID)
+	assert.NotEmpty(t, resolved.ResolvedAt)
+
+	_, err = repo.GetPreAuthorization(ctx, expired.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrPreAuthorizationNotFound)
+
+	// A negative reservation is rejected before reaching the database
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	assert.ErrorIs(t, accountRepo.UpdateReservedWithTx(ctx, tx, sourceID, decimal.NewFromInt(-1)), errors.ErrInvalidAmount)
+}
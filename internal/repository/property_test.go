@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"pgregory.net/rapid"
+)
+
+// transferStep is one generated transfer between two of the generated accounts
+type transferStep struct {
+	source      int
+	destination int
+	amount      decimal.Decimal
+}
+
+// applyTransfer performs a transfer through the repositories the same way the transaction
+// service does. It returns false if the transfer was rejected.
+func applyTransfer(ctx context.Context, db *sql.DB, accounts AccountRepository, transactions TransactionRepository, source, destination int64, amount decimal.Decimal) (bool, error) {
+	var tx *sql.Tx
+	if db != nil {
+		var err error
+		tx, err = db.BeginTx(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		defer tx.Rollback()
+	}
+
+	sourceAccount, err := accounts.GetAccountWithTx(ctx, tx, source)
+	if err != nil {
+		return false, err
+	}
+	if !sourceAccount.HasSufficientBalance(amount) {
+		return false, nil
+	}
+	destAccount, err := accounts.GetAccountWithTx(ctx, tx, destination)
+	if err != nil {
+		return false, err
+	}
+	if err := accounts.UpdateBalanceWithTx(ctx, tx, source, sourceAccount.Balance.Sub(amount)); err != nil {
+		return false, err
+	}
+	if err := accounts.UpdateBalanceWithTx(ctx, tx, destination, destAccount.Balance.Add(amount)); err != nil {
+		return false, err
+	}
+	_, err = transactions.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID:      source,
+		DestinationAccountID: destination,
+		Amount:               amount,
+		Status:               models.TransactionStatusComplete,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkBalanceConservation generates accounts and a sequence of transfers, applies them and
+// asserts that the total balance is constant, no balance drops below zero and every accepted
+// transfer appears in the history of both accounts involved
+func checkBalanceConservation(t *rapid.T, db *sql.DB, accounts AccountRepository, transactions TransactionRepository) {
+	ctx := context.Background()
+
+	numAccounts := rapid.IntRange(2, 5).Draw(t, "accounts")
+	ids := make([]int64, numAccounts)
+	total := decimal.Zero
+	for i := range ids {
+		ids[i] = int64(500000 + i)
+		balance := decimal.New(rapid.Int64Range(0, 100000).Draw(t, "balance_cents"), -2)
+		if err := accounts.CreateAccount(ctx, ids[i], balance); err != nil {
+			t.Fatalf("failed to create account %d: %v", ids[i], err)
+		}
+		total = total.Add(balance)
+	}
+
+	steps := rapid.SliceOfN(rapid.Custom(func(t *rapid.T) transferStep {
+		source := rapid.IntRange(0, numAccounts-1).Draw(t, "source")
+		offset := rapid.IntRange(1, numAccounts-1).Draw(t, "offset")
+		return transferStep{
+			source:      source,
+			destination: (source + offset) % numAccounts,
+			amount:      decimal.New(rapid.Int64Range(1, 50000).Draw(t, "amount_cents"), -2),
+		}
+	}), 0, 30).Draw(t, "transfers")
+
+	accepted := make(map[int64]int)
+	for _, step := range steps {
+		ok, err := applyTransfer(ctx, db, accounts, transactions, ids[step.source], ids[step.destination], step.amount)
+		if err != nil {
+			t.Fatalf("transfer %d -> %d of %s failed: %v", ids[step.source], ids[step.destination], step.amount, err)
+		}
+		if ok {
+			accepted[ids[step.source]]++
+			accepted[ids[step.destination]]++
+		}
+	}
+
+	sum := decimal.Zero
+	for _, id := range ids {
+		account, err := accounts.GetAccount(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get account %d: %v", id, err)
+		}
+		if account.Balance.IsNegative() {
+			t.Fatalf("account %d has negative balance %s", id, account.Balance)
+		}
+		sum = sum.Add(account.Balance)
+
+		history, err := transactions.GetTransactionsByAccount(ctx, id)
+		if err != nil {
+			t.Fatalf("failed to get history of account %d: %v", id, err)
+		}
+		if len(history) != accepted[id] {
+			t.Fatalf("account %d history has %d transactions, expected %d", id, len(history), accepted[id])
+		}
+	}
+	if !sum.Equal(total) {
+		t.Fatalf("total balance changed from %s to %s", total, sum)
+	}
+}
+
+func TestProperty_BalanceConservation_Memory(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		store := NewMemoryStore()
+		checkBalanceConservation(t, nil, NewMemoryAccountRepository(store), NewMemoryTransactionRepository(store))
+	})
+}
+
+func TestProperty_BalanceConservation_Postgres(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	rapid.Check(t, func(rt *rapid.T) {
+		// Every generated case starts from empty tables
+		if _, err := db.Exec("TRUNCATE TABLE transactions, accounts CASCADE"); err != nil {
+			rt.Fatalf("failed to reset tables: %v", err)
+		}
+		checkBalanceConservation(rt, db, NewAccountRepository(db), NewTransactionRepository(db))
+	})
+}
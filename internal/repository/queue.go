@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresQueueRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewQueueRepository(db *sql.DB) *PostgresQueueRepository {
+	return &PostgresQueueRepository{db: db, log: logger.Default()}
+}
+
+// QueueDepths returns the backlog of every queue of background work at now: unpublished outbox
+// messages, which are due as soon as they are recorded, queued transfer jobs, scheduled transfers
+// still to be made, and failed webhook deliveries waiting for a retry
+func (r *PostgresQueueRepository) QueueDepths(ctx context.Context, now time.Time) ([]*models.QueueDepth, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT $2::text, COUNT(*), COUNT(*),
+			COALESCE(EXTRACT(EPOCH FROM $1::timestamptz - MIN(created_at)), 0)::float8
+		FROM outbox
+		WHERE published_at IS NULL
+		UNION ALL
+		SELECT $3::text, COUNT(*), COUNT(*) FILTER (WHERE next_attempt_at <= $1),
+			COALESCE(EXTRACT(EPOCH FROM $1::timestamptz - MIN(next_attempt_at) FILTER (WHERE next_attempt_at <= $1)), 0)::float8
+		FROM transfer_jobs
+		WHERE status = $6
+		UNION ALL
+		SELECT $4::text, COUNT(*), COUNT(*) FILTER (WHERE next_attempt_at <= $1),
+			COALESCE(EXTRACT(EPOCH FROM $1::timestamptz - MIN(next_attempt_at) FILTER (WHERE next_attempt_at <= $1)), 0)::float8
+		FROM scheduled_transfers
+		WHERE status = $7
+		UNION ALL
+		SELECT $5::text, COUNT(*), COUNT(*) FILTER (WHERE d.next_attempt_at <= $1),
+			COALESCE(EXTRACT(EPOCH FROM $1::timestamptz - MIN(d.next_attempt_at) FILTER (WHERE d.next_attempt_at <= $1)), 0)::float8
+		FROM webhook_deliveries d
+		WHERE d.next_attempt_at IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries r WHERE r.retry_of = d.id)
+	`, now, models.QueueOutbox, models.QueueTransferJobs, models.QueueScheduledTransfers, models.QueueWebhookRetries,
+		models.TransferJobStatusQueued, models.ScheduledTransferStatusScheduled)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error measuring queue depths", "err", err)
+		return nil, fmt.Errorf("failed to measure queue depths: %w", err)
+	}
+	defer rows.Close()
+
+	depths := []*models.QueueDepth{}
+	for rows.Next() {
+		var depth models.QueueDepth
+		if err := rows.Scan(&depth.Queue, &depth.Depth, &depth.Due, &depth.OldestDueAgeSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan queue depth: %w", err)
+		}
+		depths = append(depths, &depth)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue depths: %w", err)
+	}
+	return depths, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueRepository_QueueDepths(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewQueueRepository(db)
+	ctx := context.Background()
+
+	_, err := NewOutboxRepository(db).CreateMessage(ctx, &models.OutboxMessage{Event: "account.created", Payload: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+
+	depths, err := repo.QueueDepths(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	byQueue := make(map[string]*models.QueueDepth, len(depths))
+	for _, depth := range depths {
+		byQueue[depth.Queue] = depth
+	}
+	assert.Len(t, byQueue, 4, "every queue is reported, empty or not")
+
+	outbox := byQueue[models.QueueOutbox]
+	require.NotNil(t, outbox)
+	assert.GreaterOrEqual(t, outbox.Depth, int64(1))
+	assert.Equal(t, outbox.Depth, outbox.Due)
+	assert.GreaterOrEqual(t, outbox.OldestDueAgeSeconds, 59.0)
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresScheduledTransferRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewScheduledTransferRepository(db *sql.DB) *PostgresScheduledTransferRepository {
+	return &PostgresScheduledTransferRepository{db: db, log: logger.Default()}
+}
+
+// scheduledColumns is the column list selected by every scheduled transfer read, in scanScheduled order
+const scheduledColumns = `id, source_account_id, destination_account_id, amount, execute_at, status, attempts,
+	next_attempt_at, COALESCE(last_error, ''), COALESCE(transaction_id, 0), COALESCE(standing_order_id, 0),
+	resolved_at, created_at`
+
+// scanScheduled scans a row selected with scheduledColumns
+func scanScheduled(row rowScanner) (*models.ScheduledTransfer, error) {
+	var transfer models.ScheduledTransfer
+	var executeAt, nextAttemptAt, createdAt time.Time
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.SourceAccountID,
+		&transfer.DestinationAccountID,
+		&transfer.Amount,
+		&executeAt,
+		&transfer.Status,
+		&transfer.Attempts,
+		&nextAttemptAt,
+		&transfer.LastError,
+		&transfer.TransactionID,
+		&transfer.StandingOrderID,
+		&resolvedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	transfer.ExecuteAt = executeAt.Format(time.RFC3339)
+	transfer.NextAttemptAt = nextAttemptAt.Format(time.RFC3339)
+	if resolvedAt.Valid {
+		transfer.ResolvedAt = resolvedAt.Time.Format(time.RFC3339)
+	}
+	transfer.CreatedAt = createdAt.Format(time.RFC3339)
+	return &transfer, nil
+}
+
+// CreateScheduledTransfer records a transfer to be made at its ExecuteAt
+func (r *PostgresScheduledTransferRepository) CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	return createScheduled(ctx, r.db, transfer)
+}
+
+// CreateScheduledTransferWithTx records a transfer to be made at its ExecuteAt within a transaction
+func (r *PostgresScheduledTransferRepository) CreateScheduledTransferWithTx(ctx context.Context, tx *sql.Tx, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	return createScheduled(ctx, tx, transfer)
+}
+
+// createScheduled records a scheduled transfer through q
+func createScheduled(ctx context.Context, q rowQuerier, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	logger.Default().InfoContext(ctx, "Creating scheduled transfer", "source_account_id", transfer.SourceAccountID, "destination_account_id", transfer.DestinationAccountID, "amount", transfer.Amount, "execute_at", transfer.ExecuteAt, "standing_order_id", transfer.StandingOrderID)
+
+	created, err := scanScheduled(q.QueryRowContext(ctx, `
+		INSERT INTO scheduled_transfers (source_account_id, destination_account_id, amount, execute_at, status, next_attempt_at, standing_order_id)
+		VALUES ($1, $2, $3, $4, $5, $4, NULLIF($6, 0))
+		RETURNING `+scheduledColumns,
+		transfer.SourceAccountID, transfer.DestinationAccountID, transfer.Amount, transfer.ExecuteAt, models.ScheduledTransferStatusScheduled,
+		transfer.StandingOrderID))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			logger.Default().WarnContext(ctx, "Constraint violation creating scheduled transfer", "err", err)
+			return nil, domainErr
+		}
+		logger.Default().ErrorContext(ctx, "Database error creating scheduled transfer", "err", err)
+		return nil, fmt.Errorf("failed to create scheduled transfer: %w", err)
+	}
+	return created, nil
+}
+
+// GetScheduledTransfer retrieves a scheduled transfer by its ID
+func (r *PostgresScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error) {
+	transfer, err := scanScheduled(r.db.QueryRowContext(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM scheduled_transfers
+		WHERE id = $1
+	`, transferID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Scheduled transfer not found", "transfer_id", transferID)
+			return nil, fmt.Errorf("%w: id %d", errors.ErrScheduledTransferNotFound, transferID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving scheduled transfer", "transfer_id", transferID, "err", err)
+		return nil, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// ListScheduledTransfers lists up to limit scheduled transfers with the given status, or of any
+// status if it is empty, by execution time
+func (r *PostgresScheduledTransferRepository) ListScheduledTransfers(ctx context.Context, status models.ScheduledTransferStatus, limit int) ([]*models.ScheduledTransfer, error) {
+	transfers, err := r.queryScheduled(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM scheduled_transfers
+		WHERE $1 = '' OR status = $1
+		ORDER BY execute_at, id
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing scheduled transfers", "err", err)
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// ListStandingOrderOccurrences lists up to limit of the scheduled transfers made for a standing
+// order, latest occurrence first
+func (r *PostgresScheduledTransferRepository) ListStandingOrderOccurrences(ctx context.Context, orderID int64, limit int) ([]*models.ScheduledTransfer, error) {
+	transfers, err := r.queryScheduled(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM scheduled_transfers
+		WHERE standing_order_id = $1
+		ORDER BY execute_at DESC, id DESC
+		LIMIT $2
+	`, orderID, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing occurrences of standing order", "order_id", orderID, "err", err)
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// queryScheduled runs a query selecting scheduledColumns and scans every row
+func (r *PostgresScheduledTransferRepository) queryScheduled(ctx context.Context, query string, args ...interface{}) ([]*models.ScheduledTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []*models.ScheduledTransfer{}
+	for rows.Next() {
+		transfer, err := scanScheduled(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled transfer: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// CancelScheduledTransfer marks a scheduled transfer cancelled. Only a transfer still waiting to
+// be made can be cancelled; ErrScheduledTransferResolved otherwise.
+func (r *PostgresScheduledTransferRepository) CancelScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error) {
+	r.log.InfoContext(ctx, "Cancelling scheduled transfer", "transfer_id", transferID)
+
+	cancelled, err := scanScheduled(r.db.QueryRowContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = $2, resolved_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING `+scheduledColumns,
+		transferID, models.ScheduledTransferStatusCancelled, models.ScheduledTransferStatusScheduled))
+	if err == sql.ErrNoRows {
+		transfer, err := r.GetScheduledTransfer(ctx, transferID)
+		if err != nil {
+			return nil, err
+		}
+		r.log.WarnContext(ctx, "Scheduled transfer is already resolved", "transfer_id", transferID, "status", transfer.Status)
+		return nil, fmt.Errorf("%w: scheduled transfer %d is %s", errors.ErrScheduledTransferResolved, transferID, transfer.Status)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error cancelling scheduled transfer", "transfer_id", transferID, "err", err)
+		return nil, fmt.Errorf("failed to cancel scheduled transfer: %w", err)
+	}
+	return cancelled, nil
+}
+
+// CancelStandingOrderOccurrencesWithTx cancels the scheduled transfers of a standing order that
+// are still waiting to be made, e.g. retries of a failed occurrence, and returns how many
+func (r *PostgresScheduledTransferRepository) CancelStandingOrderOccurrencesWithTx(ctx context.Context, tx *sql.Tx, orderID int64) (int, error) {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = $2, resolved_at = NOW()
+		WHERE standing_order_id = $1 AND status = $3
+	`, orderID, models.ScheduledTransferStatusCancelled, models.ScheduledTransferStatusScheduled)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error cancelling occurrences of standing order", "order_id", orderID, "err", err)
+		return 0, fmt.Errorf("failed to cancel standing order occurrences: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cancelled occurrences: %w", err)
+	}
+	return int(n), nil
+}
+
+// ClaimDueWithTx retrieves and locks the scheduled transfer that has been due the longest at now,
+// or returns nil if none is due. Rows locked by a concurrent execution are skipped.
+func (r *PostgresScheduledTransferRepository) ClaimDueWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*models.ScheduledTransfer, error) {
+	transfer, err := scanScheduled(tx.QueryRowContext(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM scheduled_transfers
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, models.ScheduledTransferStatusScheduled, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error claiming due scheduled transfer", "err", err)
+		return nil, fmt.Errorf("failed to claim due scheduled transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// MarkExecutedWithTx marks a scheduled transfer made by the given transaction
+func (r *PostgresScheduledTransferRepository) MarkExecutedWithTx(ctx context.Context, tx *sql.Tx, transferID, transactionID int64) error {
+	r.log.InfoContext(ctx, "Scheduled transfer executed by transaction", "transfer_id", transferID, logger.TransactionID(transactionID))
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = $2, attempts = attempts + 1, last_error = NULL, transaction_id = $3, resolved_at = NOW()
+		WHERE id = $1
+	`, transferID, models.ScheduledTransferStatusExecuted, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error resolving scheduled transfer", "transfer_id", transferID, "err", err)
+		return fmt.Errorf("failed to resolve scheduled transfer: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: id %d", errors.ErrScheduledTransferNotFound, transferID)
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt of a scheduled transfer and keeps its error. The
+// transfer is retried at nextAttemptAt, or marked failed once it was attempted maxAttempts times.
+// A transfer resolved meanwhile is left alone.
+func (r *PostgresScheduledTransferRepository) RecordFailure(ctx context.Context, transferID int64, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.ScheduledTransfer, error) {
+	return r.recordFailure(ctx, r.db, transferID, attemptErr, nextAttemptAt, maxAttempts)
+}
+
+// RecordFailureWithTx counts a failed attempt of a scheduled transfer within a transaction, e.g.
+// the one dead-lettering it once it failed
+func (r *PostgresScheduledTransferRepository) RecordFailureWithTx(ctx context.Context, tx *sql.Tx, transferID int64, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.ScheduledTransfer, error) {
+	return r.recordFailure(ctx, tx, transferID, attemptErr, nextAttemptAt, maxAttempts)
+}
+
+// recordFailure counts a failed attempt of a scheduled transfer through q
+func (r *PostgresScheduledTransferRepository) recordFailure(ctx context.Context, q rowQuerier, transferID int64, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.ScheduledTransfer, error) {
+	transfer, err := scanScheduled(q.QueryRowContext(ctx, `
+		UPDATE scheduled_transfers
+		SET attempts = attempts + 1,
+			last_error = $2,
+			next_attempt_at = $3,
+			status = CASE WHEN attempts + 1 >= $4 THEN $5 ELSE status END,
+			resolved_at = CASE WHEN attempts + 1 >= $4 THEN NOW() END
+		WHERE id = $1 AND status = $6
+		RETURNING `+scheduledColumns,
+		transferID, attemptErr, nextAttemptAt, maxAttempts, models.ScheduledTransferStatusFailed, models.ScheduledTransferStatusScheduled))
+	if err == sql.ErrNoRows {
+		return r.GetScheduledTransfer(ctx, transferID)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording failure of scheduled transfer", "transfer_id", transferID, "err", err)
+		return nil, fmt.Errorf("failed to record scheduled transfer failure: %w", err)
+	}
+	return transfer, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTransferRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewScheduledTransferRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(790001), int64(790002)
+	for _, id := range []int64{sourceID, destID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	amount := decimal.NewFromInt(30)
+	now := time.Now()
+
+	schedule := func(executeAt time.Time) *models.ScheduledTransfer {
+		created, err := repo.CreateScheduledTransfer(ctx, &models.ScheduledTransfer{
+			SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount,
+			ExecuteAt: executeAt.Format(time.RFC3339),
+		})
+		require.NoError(t, err)
+		return created
+	}
+	due := schedule(now.Add(-time.Minute))
+	later := schedule(now.Add(time.Hour))
+	assert.True(t, due.IsScheduled())
+	assert.Equal(t, due.ExecuteAt, due.NextAttemptAt)
+	assert.Zero(t, due.Attempts)
+
+	_, err := repo.CreateScheduledTransfer(ctx, &models.ScheduledTransfer{
+		SourceAccountID: sourceID, DestinationAccountID: destID + 100, Amount: amount,
+		ExecuteAt: now.Format(time.RFC3339),
+	})
+	assert.ErrorIs(t, err, errors.ErrDestinationAccountNotFound)
+
+	// Only the due transfer is claimed; a failed attempt pushes it back
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	claimed, err := repo.ClaimDueWithTx(ctx, tx, now)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, due.ID, claimed.ID)
+	require.NoError(t, tx.Rollback())
+
+	retried, err := repo.RecordFailure(ctx, due.ID, "insufficient balance", now.Add(time.Minute), 2)
+	require.NoError(t, err)
+	assert.True(t, retried.IsScheduled())
+	assert.Equal(t, 1, retried.Attempts)
+	assert.Equal(t, "insufficient balance", retried.LastError)
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	claimed, err = repo.ClaimDueWithTx(ctx, tx, now)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Once due again it is claimed and made
+	claimed, err = repo.ClaimDueWithTx(ctx, tx, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	made, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount, Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkExecutedWithTx(ctx, tx, claimed.ID, made.ID))
+	require.NoError(t, tx.Commit())
+
+	executed, err := repo.GetScheduledTransfer(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledTransferStatusExecuted, executed.Status)
+	assert.Equal(t, made.ID, executed.TransactionID)
+	assert.Equal(t, 2, executed.Attempts)
+	assert.Empty(t, executed.LastError)
+	assert.NotEmpty(t, executed.ResolvedAt)
+
+	// A resolved transfer can't be cancelled, and a late failure doesn't touch it
+	_, err = repo.CancelScheduledTransfer(ctx, due.ID)
+	assert.ErrorIs(t, err, errors.ErrScheduledTransferResolved)
+	unchanged, err := repo.RecordFailure(ctx, due.ID, "late", now, 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledTransferStatusExecuted, unchanged.Status)
+
+	// The last allowed attempt fails the transfer
+	failed, err := repo.RecordFailure(ctx, later.ID, "account is not active", now.Add(time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledTransferStatusFailed, failed.Status)
+	assert.NotEmpty(t, failed.ResolvedAt)
+
+	waiting := schedule(now.Add(time.Hour))
+	cancelled, err := repo.CancelScheduledTransfer(ctx, waiting.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledTransferStatusCancelled, cancelled.Status)
+
+	listed, err := repo.ListScheduledTransfers(ctx, models.ScheduledTransferStatusFailed, 10)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, later.ID, listed[0].ID)
+	all, err := repo.ListScheduledTransfers(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	_, err = repo.GetScheduledTransfer(ctx, waiting.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrScheduledTransferNotFound)
+	_, err = repo.CancelScheduledTransfer(ctx, waiting.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrScheduledTransferNotFound)
+}
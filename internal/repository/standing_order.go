@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresStandingOrderRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewStandingOrderRepository(db *sql.DB) *PostgresStandingOrderRepository {
+	return &PostgresStandingOrderRepository{db: db, log: logger.Default()}
+}
+
+// standingOrderColumns is the column list selected by every standing order read, in scanStandingOrder order
+const standingOrderColumns = `id, source_account_id, destination_account_id, amount, frequency, start_at, end_at,
+	COALESCE(max_occurrences, 0), occurrences, status, next_run_at, created_at, updated_at`
+
+// scanStandingOrder scans a row selected with standingOrderColumns
+func scanStandingOrder(row rowScanner) (*models.StandingOrder, error) {
+	var order models.StandingOrder
+	var startAt, nextRunAt, createdAt, updatedAt time.Time
+	var endAt sql.NullTime
+	err := row.Scan(
+		&order.ID,
+		&order.SourceAccountID,
+		&order.DestinationAccountID,
+		&order.Amount,
+		&order.Frequency,
+		&startAt,
+		&endAt,
+		&order.MaxOccurrences,
+		&order.Occurrences,
+		&order.Status,
+		&nextRunAt,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	order.StartAt = startAt.Format(time.RFC3339)
+	if endAt.Valid {
+		order.EndAt = endAt.Time.Format(time.RFC3339)
+	}
+	order.NextRunAt = nextRunAt.Format(time.RFC3339)
+	order.CreatedAt = createdAt.Format(time.RFC3339)
+	order.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &order, nil
+}
+
+// CreateStandingOrder records an active standing order whose first occurrence is at its StartAt
+func (r *PostgresStandingOrderRepository) CreateStandingOrder(ctx context.Context, order *models.StandingOrder) (*models.StandingOrder, error) {
+	r.log.InfoContext(ctx, "Creating standing order", "source_account_id", order.SourceAccountID, "destination_account_id", order.DestinationAccountID, "amount", order.Amount, "frequency", order.Frequency, "start_at", order.StartAt)
+
+	created, err := scanStandingOrder(r.db.QueryRowContext(ctx, `
+		INSERT INTO standing_orders (source_account_id, destination_account_id, amount, frequency, start_at, end_at,
+			max_occurrences, status, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::timestamptz, NULLIF($7, 0), $8, $5)
+		RETURNING `+standingOrderColumns,
+		order.SourceAccountID, order.DestinationAccountID, order.Amount, order.Frequency, order.StartAt, order.EndAt,
+		order.MaxOccurrences, models.StandingOrderStatusActive))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating standing order", "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error creating standing order", "err", err)
+		return nil, fmt.Errorf("failed to create standing order: %w", err)
+	}
+	return created, nil
+}
+
+// GetStandingOrder retrieves a standing order by its ID
+func (r *PostgresStandingOrderRepository) GetStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error) {
+	return getStandingOrder(ctx, r.db, orderID, "")
+}
+
+// GetStandingOrderForUpdateWithTx retrieves a standing order and locks it for the rest of the transaction
+func (r *PostgresStandingOrderRepository) GetStandingOrderForUpdateWithTx(ctx context.Context, tx *sql.Tx, orderID int64) (*models.StandingOrder, error) {
+	return getStandingOrder(ctx, tx, orderID, "FOR UPDATE")
+}
+
+// getStandingOrder retrieves a standing order by ID through q, appending lock to the query
+func getStandingOrder(ctx context.Context, q rowQuerier, orderID int64, lock string) (*models.StandingOrder, error) {
+	order, err := scanStandingOrder(q.QueryRowContext(ctx, `
+		SELECT `+standingOrderColumns+`
+		FROM standing_orders
+		WHERE id = $1
+		`+lock, orderID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Default().WarnContext(ctx, "Standing order not found", "order_id", orderID)
+			return nil, fmt.Errorf("%w: id %d", errors.ErrStandingOrderNotFound, orderID)
+		}
+		logger.Default().ErrorContext(ctx, "Database error retrieving standing order", "order_id", orderID, "err", err)
+		return nil, fmt.Errorf("failed to get standing order: %w", err)
+	}
+	return order, nil
+}
+
+// ListDueForUpdateWithTx retrieves and locks up to limit active standing orders whose next
+// occurrence is due at now, longest due first. Rows locked by a concurrent sweep are skipped.
+func (r *PostgresStandingOrderRepository) ListDueForUpdateWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*models.StandingOrder, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+standingOrderColumns+`
+		FROM standing_orders
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at, id
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`, models.StandingOrderStatusActive, now, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing due standing orders", "err", err)
+		return nil, fmt.Errorf("failed to list due standing orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*models.StandingOrder
+	for rows.Next() {
+		order, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan standing order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating standing orders: %w", err)
+	}
+	return orders, nil
+}
+
+// UpdateStandingOrderWithTx saves the status, occurrence count and next occurrence of a standing order
+func (r *PostgresStandingOrderRepository) UpdateStandingOrderWithTx(ctx context.Context, tx *sql.Tx, order *models.StandingOrder) (*models.StandingOrder, error) {
+	r.log.InfoContext(ctx, "Updating standing order", "order_id", order.ID, "status", order.Status, "occurrences", order.Occurrences, "next_run_at", order.NextRunAt)
+
+	updated, err := scanStandingOrder(tx.QueryRowContext(ctx, `
+		UPDATE standing_orders
+		SET status = $2, occurrences = $3, next_run_at = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+standingOrderColumns,
+		order.ID, order.Status, order.Occurrences, order.NextRunAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: id %d", errors.ErrStandingOrderNotFound, order.ID)
+		}
+		r.log.ErrorContext(ctx, "Database error updating standing order", "order_id", order.ID, "err", err)
+		return nil, fmt.Errorf("failed to update standing order: %w", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandingOrderRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewStandingOrderRepository(db)
+	scheduledRepo := NewScheduledTransferRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(791001), int64(791002)
+	for _, id := range []int64{sourceID, destID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	startAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	order, err := repo.CreateStandingOrder(ctx, &models.StandingOrder{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: decimal.NewFromInt(10),
+		Frequency: models.StandingOrderDaily, StartAt: startAt.Format(time.RFC3339), MaxOccurrences: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.StandingOrderStatusActive, order.Status)
+	assert.Equal(t, order.StartAt, order.NextRunAt)
+	assert.Empty(t, order.EndAt)
+	assert.Equal(t, 2, order.MaxOccurrences)
+
+	_, err = repo.GetStandingOrder(ctx, order.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrStandingOrderNotFound)
+
+	// The due order is listed and its occurrence scheduled under it
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	due, err := repo.ListDueForUpdateWithTx(ctx, tx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, order.ID, due[0].ID)
+
+	occurrence, err := scheduledRepo.CreateScheduledTransferWithTx(ctx, tx, &models.ScheduledTransfer{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: order.Amount,
+		ExecuteAt: order.NextRunAt, StandingOrderID: order.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, order.ID, occurrence.StandingOrderID)
+
+	order.Occurrences = 1
+	order.NextRunAt = startAt.AddDate(0, 0, 1).Format(time.RFC3339)
+	updated, err := repo.UpdateStandingOrderWithTx(ctx, tx, order)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Occurrences)
+	require.NoError(t, tx.Commit())
+
+	// The next occurrence is tomorrow, so nothing is due now
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	due, err = repo.ListDueForUpdateWithTx(ctx, tx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	require.NoError(t, tx.Rollback())
+
+	occurrences, err := scheduledRepo.ListStandingOrderOccurrences(ctx, order.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, occurrences, 1)
+	assert.Equal(t, occurrence.ID, occurrences[0].ID)
+
+	// Cancelling the order's waiting occurrences leaves none scheduled
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	cancelled, err := scheduledRepo.CancelStandingOrderOccurrencesWithTx(ctx, tx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cancelled)
+	require.NoError(t, tx.Commit())
+
+	got, err := scheduledRepo.GetScheduledTransfer(ctx, occurrence.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScheduledTransferStatusCancelled, got.Status)
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+type PostgresSuspenseRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewSuspenseRepository(db *sql.DB) *PostgresSuspenseRepository {
+	return &PostgresSuspenseRepository{db: db, log: logger.Default()}
+}
+
+// suspenseItemColumns is the column list selected by every suspense item read, in scanSuspenseItem order
+const suspenseItemColumns = `id, transaction_id, source_account_id, destination_account_id, amount, reason, status,
+	COALESCE(resolution_transaction_id, 0), COALESCE(resolved_by, ''), resolved_at, created_at`
+
+// scanSuspenseItem scans a row selected with suspenseItemColumns
+func scanSuspenseItem(row rowScanner) (*models.SuspenseItem, error) {
+	var item models.SuspenseItem
+	var resolvedAt sql.NullTime
+	var createdAt time.Time
+	err := row.Scan(
+		&item.ID,
+		&item.TransactionID,
+		&item.SourceAccountID,
+		&item.DestinationAccountID,
+		&item.Amount,
+		&item.Reason,
+		&item.Status,
+		&item.ResolutionTransactionID,
+		&item.ResolvedBy,
+		&resolvedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		item.ResolvedAt = resolvedAt.Time.Format(time.RFC3339)
+	}
+	item.CreatedAt = createdAt.Format(time.RFC3339)
+	return &item, nil
+}
+
+// CreateItemWithTx records a credit moved to the suspense account within a transaction
+func (r *PostgresSuspenseRepository) CreateItemWithTx(ctx context.Context, tx *sql.Tx, item *models.SuspenseItem) (*models.SuspenseItem, error) {
+	r.log.InfoContext(ctx, "Creating suspense item", logger.TransactionID(item.TransactionID), "destination_account_id", item.DestinationAccountID, "amount", item.Amount)
+
+	created, err := scanSuspenseItem(tx.QueryRowContext(ctx, `
+		INSERT INTO suspense_items (transaction_id, source_account_id, destination_account_id, amount, reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+suspenseItemColumns,
+		item.TransactionID, item.SourceAccountID, item.DestinationAccountID, item.Amount, item.Reason, models.SuspenseStatusOpen))
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error creating suspense item for transaction", logger.TransactionID(item.TransactionID), "err", err)
+		return nil, fmt.Errorf("failed to create suspense item: %w", err)
+	}
+	return created, nil
+}
+
+// GetItem retrieves a suspense item together with its audit trail
+func (r *PostgresSuspenseRepository) GetItem(ctx context.Context, itemID int64) (*models.SuspenseItem, error) {
+	item, err := getSuspenseItem(ctx, r.db, itemID, "")
+	if err != nil {
+		return nil, err
+	}
+	if item.Events, err = r.GetEvents(ctx, itemID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetItemForUpdateWithTx retrieves a suspense item and locks it for the rest of the transaction
+func (r *PostgresSuspenseRepository) GetItemForUpdateWithTx(ctx context.Context, tx *sql.Tx, itemID int64) (*models.SuspenseItem, error) {
+	return getSuspenseItem(ctx, tx, itemID, "FOR UPDATE")
+}
+
+// getSuspenseItem retrieves a suspense item by ID through q, appending lock to the query
+func getSuspenseItem(ctx context.Context, q rowQuerier, itemID int64, lock string) (*models.SuspenseItem, error) {
+	item, err := scanSuspenseItem(q.QueryRowContext(ctx, `
+		SELECT `+suspenseItemColumns+`
+		FROM suspense_items
+		WHERE id = $1
+		`+lock, itemID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Default().WarnContext(ctx, "Suspense item not found", "item_id", itemID)
+			return nil, fmt.Errorf("%w: id %d", errors.ErrSuspenseItemNotFound, itemID)
+		}
+		logger.Default().ErrorContext(ctx, "Database error retrieving suspense item", "item_id", itemID, "err", err)
+		return nil, fmt.Errorf("failed to get suspense item: %w", err)
+	}
+	return item, nil
+}
+
+// ListItems retrieves up to limit suspense items with the given status, oldest first.
+// An empty status lists items of every status.
+func (r *PostgresSuspenseRepository) ListItems(ctx context.Context, status models.SuspenseStatus, limit int) ([]*models.SuspenseItem, error) {
+	r.log.InfoContext(ctx, "Listing suspense items", "status", status, "limit", limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+suspenseItemColumns+`
+		FROM suspense_items
+		WHERE $1 = '' OR status = $1
+		ORDER BY id
+		LIMIT $2
+	`, string(status), limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing suspense items", "err", err)
+		return nil, fmt.Errorf("failed to list suspense items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*models.SuspenseItem{}
+	for rows.Next() {
+		item, err := scanSuspenseItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan suspense item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suspense items: %w", err)
+	}
+	return items, nil
+}
+
+// ResolveItemWithTx marks a suspense item re-applied or returned by the given transaction within a transaction
+func (r *PostgresSuspenseRepository) ResolveItemWithTx(ctx context.Context, tx *sql.Tx, itemID int64, status models.SuspenseStatus, transactionID int64, actor string) error {
+	r.log.InfoContext(ctx, "Resolving suspense item", "item_id", itemID, "status", status, logger.TransactionID(transactionID), "actor", actor)
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE suspense_items
+		SET status = $2, resolution_transaction_id = $3, resolved_by = $4, resolved_at = NOW()
+		WHERE id = $1
+	`, itemID, status, transactionID, actor)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error resolving suspense item", "item_id", itemID, "err", err)
+		return fmt.Errorf("failed to resolve suspense item: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: id %d", errors.ErrSuspenseItemNotFound, itemID)
+	}
+	return nil
+}
+
+// AddEventWithTx appends an entry to a suspense item's audit trail within a transaction
+func (r *PostgresSuspenseRepository) AddEventWithTx(ctx context.Context, tx *sql.Tx, event *models.SuspenseEvent) error {
+	var transactionID interface{}
+	if event.TransactionID != 0 {
+		transactionID = event.TransactionID
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO suspense_item_events (item_id, action, actor, note, transaction_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, event.ItemID, event.Action, event.Actor, event.Note, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording event for suspense item", "action", event.Action, "item_id", event.ItemID, "err", err)
+		return fmt.Errorf("failed to record suspense item event: %w", err)
+	}
+	return nil
+}
+
+// GetEvents retrieves a suspense item's audit trail, oldest first
+func (r *PostgresSuspenseRepository) GetEvents(ctx context.Context, itemID int64) ([]*models.SuspenseEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, item_id, action, actor, note, COALESCE(transaction_id, 0), created_at
+		FROM suspense_item_events
+		WHERE item_id = $1
+		ORDER BY id
+	`, itemID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving events of suspense item", "item_id", itemID, "err", err)
+		return nil, fmt.Errorf("failed to get suspense item events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.SuspenseEvent
+	for rows.Next() {
+		var event models.SuspenseEvent
+		var createdAt time.Time
+		if err := rows.Scan(&event.ID, &event.ItemID, &event.Action, &event.Actor, &event.Note, &event.TransactionID, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan suspense item event: %w", err)
+		}
+		event.CreatedAt = createdAt.Format(time.RFC3339)
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suspense item events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuspenseRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewSuspenseRepository(db)
+	accountRepo := NewAccountRepository(db)
+	transactionRepo := NewTransactionRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID, suspenseID := int64(770001), int64(770002), int64(770009)
+	for _, id := range []int64{sourceID, destID, suspenseID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(100)))
+	}
+	amount := decimal.NewFromInt(25)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	parked, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: suspenseID, Amount: amount, Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	item, err := repo.CreateItemWithTx(ctx, tx, &models.SuspenseItem{
+		TransactionID: parked.ID, SourceAccountID: sourceID, DestinationAccountID: destID, Amount: amount, Reason: "frozen",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddEventWithTx(ctx, tx, &models.SuspenseEvent{
+		ItemID: item.ID, Action: models.SuspenseActionSuspended, Actor: models.SuspenseActorSystem, TransactionID: parked.ID,
+	}))
+	require.NoError(t, tx.Commit())
+
+	assert.Equal(t, models.SuspenseStatusOpen, item.Status)
+	assert.True(t, item.Amount.Equal(amount))
+
+	open, err := repo.ListItems(ctx, models.SuspenseStatusOpen, 10)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, item.ID, open[0].ID)
+
+	// Resolve the item under a row lock
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	locked, err := repo.GetItemForUpdateWithTx(ctx, tx, item.ID)
+	require.NoError(t, err)
+	assert.True(t, locked.IsOpen())
+	reapplied, err := transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: suspenseID, DestinationAccountID: destID, Amount: amount, Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.ResolveItemWithTx(ctx, tx, item.ID, models.SuspenseStatusReapplied, reapplied.ID, "ops@example.com"))
+	require.NoError(t, repo.AddEventWithTx(ctx, tx, &models.SuspenseEvent{
+		ItemID: item.ID, Action: models.SuspenseActionReapplied, Actor: "ops@example.com", Note: "account unfrozen", TransactionID: reapplied.ID,
+	}))
+	require.NoError(t, tx.Commit())
+
+	resolved, err := repo.GetItem(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SuspenseStatusReapplied, resolved.Status)
+	assert.Equal(t, reapplied.ID, resolved.ResolutionTransactionID)
+	assert.Equal(t, "ops@example.com", resolved.ResolvedBy)
+	assert.NotEmpty(t, resolved.ResolvedAt)
+	require.Len(t, resolved.Events, 2)
+	assert.Equal(t, models.SuspenseActionSuspended, resolved.Events[0].Action)
+	assert.Equal(t, models.SuspenseActionReapplied, resolved.Events[1].Action)
+	assert.Equal(t, "account unfrozen", resolved.Events[1].Note)
+
+	open, err = repo.ListItems(ctx, models.SuspenseStatusOpen, 10)
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	_, err = repo.GetItem(ctx, item.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrSuspenseItemNotFound)
+}
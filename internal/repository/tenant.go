@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// PostgresTenantRepository implements TenantRepository
+type PostgresTenantRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewTenantRepository creates a new tenant settings repository
+func NewTenantRepository(db *sql.DB) *PostgresTenantRepository {
+	return &PostgresTenantRepository{db: db, log: logger.Default()}
+}
+
+// tenantSettingsColumns is the column list selected by every tenant settings read, in
+// scanTenantSettings order
+const tenantSettingsColumns = `tenant_id, max_transfer_amount, allowed_currencies, features, max_accounts, max_daily_transactions, quota_warn_percent, updated_at`
+
+// scanTenantSettings scans a row selected with tenantSettingsColumns
+func scanTenantSettings(row rowScanner) (*models.TenantSettings, error) {
+	var settings models.TenantSettings
+	var maxTransferAmount decimal.NullDecimal
+	var currencies, features []byte
+	var maxAccounts, maxDailyTransactions sql.NullInt64
+	var quotaWarnPercent sql.NullInt32
+	var updatedAt time.Time
+	if err := row.Scan(&settings.TenantID, &maxTransferAmount, &currencies, &features,
+		&maxAccounts, &maxDailyTransactions, &quotaWarnPercent, &updatedAt); err != nil {
+		return nil, err
+	}
+	if maxTransferAmount.Valid {
+		settings.MaxTransferAmount = &maxTransferAmount.Decimal
+	}
+	if maxAccounts.Valid {
+		settings.MaxAccounts = &maxAccounts.Int64
+	}
+	if maxDailyTransactions.Valid {
+		settings.MaxDailyTransactions = &maxDailyTransactions.Int64
+	}
+	if quotaWarnPercent.Valid {
+		percent := int(quotaWarnPercent.Int32)
+		settings.QuotaWarnPercent = &percent
+	}
+	if err := json.Unmarshal(currencies, &settings.AllowedCurrencies); err != nil {
+		return nil, fmt.Errorf("invalid allowed currencies of tenant %s: %w", settings.TenantID, err)
+	}
+	if err := json.Unmarshal(features, &settings.Features); err != nil {
+		return nil, fmt.Errorf("invalid features of tenant %s: %w", settings.TenantID, err)
+	}
+	settings.UpdatedAt = updatedAt.Format(time.RFC3339)
+	return &settings, nil
+}
+
+// GetTenantSettings retrieves the settings of a tenant
+func (r *PostgresTenantRepository) GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	settings, err := scanTenantSettings(r.db.QueryRowContext(ctx, `
+		SELECT `+tenantSettingsColumns+`
+		FROM tenant_settings
+		WHERE tenant_id = $1
+	`, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: tenant %s", errors.ErrTenantNotFound, tenantID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving settings of tenant", "tenant_id", tenantID, "err", err)
+		return nil, fmt.Errorf("failed to get tenant settings: %w", err)
+	}
+	return settings, nil
+}
+
+// ListTenantSettings retrieves the settings of every tenant, ordered by tenant ID
+func (r *PostgresTenantRepository) ListTenantSettings(ctx context.Context) ([]*models.TenantSettings, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+tenantSettingsColumns+`
+		FROM tenant_settings
+		ORDER BY tenant_id
+	`)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error listing tenant settings", "err", err)
+		return nil, fmt.Errorf("failed to list tenant settings: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*models.TenantSettings{}
+	for rows.Next() {
+		settings, err := scanTenantSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tenant settings: %w", err)
+		}
+		tenants = append(tenants, settings)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant settings: %w", err)
+	}
+	return tenants, nil
+}
+
+// PutTenantSettings creates or replaces the settings of a tenant
+func (r *PostgresTenantRepository) PutTenantSettings(ctx context.Context, settings *models.TenantSettings) (*models.TenantSettings, error) {
+	r.log.InfoContext(ctx, "Storing settings of tenant", "tenant_id", settings.TenantID)
+
+	currencies := settings.AllowedCurrencies
+	if currencies == nil {
+		currencies = []string{}
+	}
+	encodedCurrencies, err := json.Marshal(currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allowed currencies: %w", err)
+	}
+	features := settings.Features
+	if features == nil {
+		features = map[string]bool{}
+	}
+	encodedFeatures, err := json.Marshal(features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode features: %w", err)
+	}
+	var maxTransferAmount decimal.NullDecimal
+	if settings.MaxTransferAmount != nil {
+		maxTransferAmount = decimal.NewNullDecimal(*settings.MaxTransferAmount)
+	}
+	var maxAccounts, maxDailyTransactions, quotaWarnPercent sql.NullInt64
+	if settings.MaxAccounts != nil {
+		maxAccounts = sql.NullInt64{Int64: *settings.MaxAccounts, Valid: true}
+	}
+	if settings.MaxDailyTransactions != nil {
+		maxDailyTransactions = sql.NullInt64{Int64: *settings.MaxDailyTransactions, Valid: true}
+	}
+	if settings.QuotaWarnPercent != nil {
+		quotaWarnPercent = sql.NullInt64{Int64: int64(*settings.QuotaWarnPercent), Valid: true}
+	}
+
+	stored, err := scanTenantSettings(r.db.QueryRowContext(ctx, `
+		INSERT INTO tenant_settings (tenant_id, max_transfer_amount, allowed_currencies, features,
+			max_accounts, max_daily_transactions, quota_warn_percent)
+		VALUES ($1, $2, $3::jsonb, $4::jsonb, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET max_transfer_amount = EXCLUDED.max_transfer_amount,
+			allowed_currencies = EXCLUDED.allowed_currencies,
+			features = EXCLUDED.features,
+			max_accounts = EXCLUDED.max_accounts,
+			max_daily_transactions = EXCLUDED.max_daily_transactions,
+			quota_warn_percent = EXCLUDED.quota_warn_percent,
+			updated_at = NOW()
+		RETURNING `+tenantSettingsColumns,
+		settings.TenantID, maxTransferAmount, string(encodedCurrencies), string(encodedFeatures),
+		maxAccounts, maxDailyTransactions, quotaWarnPercent))
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation storing settings of tenant", "tenant_id", settings.TenantID, "err", err)
+			return nil, fmt.Errorf("%w: tenant %s", domainErr, settings.TenantID)
+		}
+		r.log.ErrorContext(ctx, "Database error storing settings of tenant", "tenant_id", settings.TenantID, "err", err)
+		return nil, fmt.Errorf("failed to store tenant settings: %w", err)
+	}
+	return stored, nil
+}
+
+// DeleteTenantSettings removes the settings of a tenant
+func (r *PostgresTenantRepository) DeleteTenantSettings(ctx context.Context, tenantID string) error {
+	r.log.InfoContext(ctx, "Deleting settings of tenant", "tenant_id", tenantID)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tenant_settings WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error deleting settings of tenant", "tenant_id", tenantID, "err", err)
+		return fmt.Errorf("failed to delete tenant settings: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: tenant %s", errors.ErrTenantNotFound, tenantID)
+	}
+	return nil
+}
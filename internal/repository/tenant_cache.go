@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// CachedTenantRepository is a TenantRepository that caches GetTenantSettings results, including
+// "not found", for a TTL. Tenants are few and their settings are read by every transfer but
+// rarely change, so entries are kept until they expire rather than bounded by an LRU. Writes
+// through the cache invalidate the tenant's entry; writes by other instances are seen once the
+// entry expires.
+type CachedTenantRepository struct {
+	TenantRepository
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]tenantCacheEntry
+}
+
+type tenantCacheEntry struct {
+	settings *models.TenantSettings // nil for a cached "not found"
+	expires  time.Time
+}
+
+// NewCachedTenantRepository creates a caching wrapper around repo
+func NewCachedTenantRepository(repo TenantRepository, ttl time.Duration) *CachedTenantRepository {
+	return &CachedTenantRepository{
+		TenantRepository: repo,
+		ttl:              ttl,
+		entries:          make(map[string]tenantCacheEntry),
+	}
+}
+
+// GetTenantSettings retrieves the settings of a tenant from the cache, loading them from the
+// underlying repository on a miss
+func (r *CachedTenantRepository) GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[tenantID]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		if entry.settings == nil {
+			return nil, errors.ErrTenantNotFound
+		}
+		return copyTenantSettings(entry.settings), nil
+	}
+
+	settings, err := r.TenantRepository.GetTenantSettings(ctx, tenantID)
+	if err != nil && !stderrors.Is(err, errors.ErrTenantNotFound) {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.entries[tenantID] = tenantCacheEntry{settings: settings, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return copyTenantSettings(settings), nil
+}
+
+// PutTenantSettings stores the settings and drops the cached entry
+func (r *CachedTenantRepository) PutTenantSettings(ctx context.Context, settings *models.TenantSettings) (*models.TenantSettings, error) {
+	stored, err := r.TenantRepository.PutTenantSettings(ctx, settings)
+	r.Invalidate(settings.TenantID)
+	return stored, err
+}
+
+// DeleteTenantSettings removes the settings and drops the cached entry
+func (r *CachedTenantRepository) DeleteTenantSettings(ctx context.Context, tenantID string) error {
+	err := r.TenantRepository.DeleteTenantSettings(ctx, tenantID)
+	r.Invalidate(tenantID)
+	return err
+}
+
+// Invalidate removes a tenant from the cache
+func (r *CachedTenantRepository) Invalidate(tenantID string) {
+	r.mu.Lock()
+	delete(r.entries, tenantID)
+	r.mu.Unlock()
+}
+
+// copyTenantSettings copies settings so callers can't modify the cached entry
+func copyTenantSettings(settings *models.TenantSettings) *models.TenantSettings {
+	copied := *settings
+	copied.AllowedCurrencies = append([]string(nil), settings.AllowedCurrencies...)
+	copied.Features = make(map[string]bool, len(settings.Features))
+	for feature, enabled := range settings.Features {
+		copied.Features[feature] = enabled
+	}
+	return &copied
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTenantRepository is an in-memory TenantRepository counting reads
+type countingTenantRepository struct {
+	TenantRepository
+	settings map[string]*models.TenantSettings
+	reads    int
+}
+
+func (r *countingTenantRepository) GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	r.reads++
+	settings, ok := r.settings[tenantID]
+	if !ok {
+		return nil, fmt.Errorf("%w: tenant %s", errors.ErrTenantNotFound, tenantID)
+	}
+	return settings, nil
+}
+
+func (r *countingTenantRepository) PutTenantSettings(ctx context.Context, settings *models.TenantSettings) (*models.TenantSettings, error) {
+	r.settings[settings.TenantID] = settings
+	return settings, nil
+}
+
+func TestCachedTenantRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caches settings and not found until invalidated", func(t *testing.T) {
+		underlying := &countingTenantRepository{settings: map[string]*models.TenantSettings{
+			"retail": {TenantID: "retail", AllowedCurrencies: []string{"USD"}},
+		}}
+		repo := NewCachedTenantRepository(underlying, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			settings, err := repo.GetTenantSettings(ctx, "retail")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"USD"}, settings.AllowedCurrencies)
+			_, err = repo.GetTenantSettings(ctx, "treasury")
+			assert.ErrorIs(t, err, errors.ErrTenantNotFound)
+		}
+		assert.Equal(t, 2, underlying.reads)
+
+		_, err := repo.PutTenantSettings(ctx, &models.TenantSettings{TenantID: "treasury", AllowedCurrencies: []string{"SGD"}})
+		require.NoError(t, err)
+		settings, err := repo.GetTenantSettings(ctx, "treasury")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SGD"}, settings.AllowedCurrencies)
+		assert.Equal(t, 3, underlying.reads)
+	})
+
+	t.Run("callers can't modify cached settings", func(t *testing.T) {
+		underlying := &countingTenantRepository{settings: map[string]*models.TenantSettings{
+			"retail": {TenantID: "retail", Features: map[string]bool{models.FeatureReversals: true}},
+		}}
+		repo := NewCachedTenantRepository(underlying, time.Minute)
+
+		settings, err := repo.GetTenantSettings(ctx, "retail")
+		require.NoError(t, err)
+		settings.Features[models.FeatureReversals] = false
+		settings, err = repo.GetTenantSettings(ctx, "retail")
+		require.NoError(t, err)
+		assert.True(t, settings.Features[models.FeatureReversals])
+	})
+
+	t.Run("expires entries after the TTL", func(t *testing.T) {
+		underlying := &countingTenantRepository{settings: map[string]*models.TenantSettings{"retail": {TenantID: "retail"}}}
+		repo := NewCachedTenantRepository(underlying, 10*time.Millisecond)
+
+		_, err := repo.GetTenantSettings(ctx, "retail")
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		_, err = repo.GetTenantSettings(ctx, "retail")
+		require.NoError(t, err)
+		assert.Equal(t, 2, underlying.reads)
+	})
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantRepository(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTenantRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.GetTenantSettings(ctx, "retail")
+	assert.ErrorIs(t, err, errors.ErrTenantNotFound)
+
+	limit := decimal.NewFromInt(500)
+	stored, err := repo.PutTenantSettings(ctx, &models.TenantSettings{
+		TenantID:          "retail",
+		MaxTransferAmount: &limit,
+		AllowedCurrencies: []string{"USD"},
+		Features:          map[string]bool{models.FeatureReversals: false},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, stored.MaxTransferAmount)
+	assert.True(t, stored.MaxTransferAmount.Equal(limit))
+
+	// Putting again replaces every setting
+	stored, err = repo.PutTenantSettings(ctx, &models.TenantSettings{TenantID: "retail"})
+	require.NoError(t, err)
+	assert.Nil(t, stored.MaxTransferAmount)
+	assert.Empty(t, stored.AllowedCurrencies)
+	assert.Empty(t, stored.Features)
+
+	_, err = repo.PutTenantSettings(ctx, &models.TenantSettings{TenantID: "treasury", AllowedCurrencies: []string{"SGD"}})
+	require.NoError(t, err)
+	tenants, err := repo.ListTenantSettings(ctx)
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+	assert.Equal(t, "retail", tenants[0].TenantID)
+	assert.Equal(t, []string{"SGD"}, tenants[1].AllowedCurrencies)
+
+	// Accounts are assigned to tenants
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.Zero))
+	require.NoError(t, accountRepo.SetTenant(ctx, 1, "treasury"))
+	account, err := accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "treasury", account.TenantID)
+	require.NoError(t, accountRepo.SetTenant(ctx, 1, ""))
+	account, err = accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, account.TenantID)
+	assert.ErrorIs(t, accountRepo.SetTenant(ctx, 404, "treasury"), errors.ErrAccountNotFound)
+
+	// Assigning accounts stops at the account quota of the tenant
+	maxAccounts, maxDaily, percent := int64(1), int64(50), 90
+	stored, err = repo.PutTenantSettings(ctx, &models.TenantSettings{
+		TenantID: "treasury", MaxAccounts: &maxAccounts, MaxDailyTransactions: &maxDaily, QuotaWarnPercent: &percent,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &maxAccounts, stored.MaxAccounts)
+	assert.Equal(t, &maxDaily, stored.MaxDailyTransactions)
+	assert.Equal(t, &percent, stored.QuotaWarnPercent)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	require.NoError(t, accountRepo.SetTenant(ctx, 1, "treasury"))
+	require.NoError(t, accountRepo.SetTenant(ctx, 1, "treasury"), "reassigning an account doesn't count it twice")
+	assert.ErrorIs(t, accountRepo.SetTenant(ctx, 2, "treasury"), errors.ErrTenantQuotaExceeded)
+	count, err := accountRepo.CountTenantAccounts(ctx, "treasury")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	require.NoError(t, repo.DeleteTenantSettings(ctx, "treasury"))
+	assert.ErrorIs(t, repo.DeleteTenantSettings(ctx, "treasury"), errors.ErrTenantNotFound)
+}
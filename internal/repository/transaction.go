@@ -3,122 +3,895 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
-	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 type PostgresTransactionRepository struct {
-	db *sql.DB
+	db  *sql.DB
+	log *slog.Logger
 }
 
 func NewTransactionRepository(db *sql.DB) *PostgresTransactionRepository {
-	return &PostgresTransactionRepository{db: db}
+	return &PostgresTransactionRepository{db: db, log: logger.Default()}
+}
+
+// transactionColumns is the column list selected by every transaction read, in scanTransaction order
+const transactionColumns = `id, source_account_id, destination_account_id, amount, status, created_at, value_date, tags, COALESCE(batch_item_key, ''), COALESCE(external_reference, ''), business_date, COALESCE(reversal_of, 0), converted_amount, fx_rate, COALESCE(fee_of, 0), COALESCE(fee_rule_id, 0), COALESCE(split_id, 0)`
+
+// settledStatuses are the statuses of transactions whose funds moved. A reversed transaction keeps
+// its effect; its compensating transfer is a separate completed transaction.
+const settledStatuses = `('complete', 'reversed')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction scans a row selected with transactionColumns
+func scanTransaction(row rowScanner) (*models.Transaction, error) {
+	var tx models.Transaction
+	var createdAt, valueDate, businessDate time.Time
+	var tags []byte
+	var convertedAmount, fxRate decimal.NullDecimal
+	err := row.Scan(
+		&tx.ID,
+		&tx.SourceAccountID,
+		&tx.DestinationAccountID,
+		&tx.Amount,
+		&tx.Status,
+		&createdAt,
+		&valueDate,
+		&tags,
+		&tx.BatchItemKey,
+		&tx.ExternalReference,
+		&businessDate,
+		&tx.ReversalOf,
+		&convertedAmount,
+		&fxRate,
+		&tx.FeeOf,
+		&tx.FeeRuleID,
+		&tx.SplitID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if convertedAmount.Valid {
+		tx.ConvertedAmount = &convertedAmount.Decimal
+	}
+	if fxRate.Valid {
+		tx.FXRate = &fxRate.Decimal
+	}
+	if err := json.Unmarshal(tags, &tx.Tags); err != nil {
+		return nil, fmt.Errorf("invalid tags on transaction %d: %w", tx.ID, err)
+	}
+	tx.CreatedAt = createdAt.Format(time.RFC3339)
+	tx.ValueDate = valueDate.Format(time.RFC3339)
+	tx.BusinessDate = businessDate.Format(businessday.DateLayout)
+	return &tx, nil
+}
+
+// nullDecimal converts an optional amount to a nullable column value
+func nullDecimal(d *decimal.Decimal) decimal.NullDecimal {
+	if d == nil {
+		return decimal.NullDecimal{}
+	}
+	return decimal.NewNullDecimal(*d)
+}
+
+// axisColumn returns the timestamp column backing a time axis
+func axisColumn(axis models.TimeAxis) string {
+	if axis == models.TimeAxisEffective {
+		return "value_date"
+	}
+	return "created_at"
 }
 
 func (r *PostgresTransactionRepository) GetTransactionsByAccount(ctx context.Context, accountID int64) ([]*models.Transaction, error) {
-	logger.Info("Retrieving transactions for account: %d", accountID)
+	r.log.InfoContext(ctx, "Retrieving transactions for account", logger.AccountID(accountID))
 
 	query := `
-		SELECT id, source_account_id, destination_account_id, amount, status, created_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE source_account_id = $1 OR destination_account_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, accountID)
+	transactions, err := r.queryTransactions(ctx, query, accountID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving transactions for account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+
+	r.log.InfoContext(ctx, "Successfully retrieved transactions for account", "count", len(transactions), logger.AccountID(accountID))
+	return transactions, nil
+}
+
+// GetTransactionsByAccountPage retrieves up to limit of an account's transactions matching filter,
+// ordered by (created_at, id) newest first and starting after cursor (from the newest if nil).
+// Each side of the transfer is read through its own index and the two are merged, so a page costs
+// O(limit) regardless of the size of the account's history.
+func (r *PostgresTransactionRepository) GetTransactionsByAccountPage(ctx context.Context, accountID int64, filter models.TransactionFilter, limit int, after *models.TransactionCursor) (*models.TransactionPage, error) {
+	r.log.InfoContext(ctx, "Retrieving page of transactions for account", logger.AccountID(accountID), "filter", filter, "limit", limit)
+
+	// One row more than the page tells whether there is a next page
+	args := []interface{}{accountID, limit + 1}
+	conditions := ""
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		conditions += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	if filter.MinAmount != nil {
+		args = append(args, *filter.MinAmount)
+		conditions += fmt.Sprintf(" AND amount >= $%d", len(args))
+	}
+	if filter.MaxAmount != nil {
+		args = append(args, *filter.MaxAmount)
+		conditions += fmt.Sprintf(" AND amount <= $%d", len(args))
+	}
+
+	var sides []string
+	if filter.Direction != models.TransactionDirectionIncoming {
+		sides = append(sides, fmt.Sprintf(`(SELECT %s, created_at AS cursor_created_at FROM transactions
+			WHERE source_account_id = $1%s ORDER BY created_at DESC, id DESC LIMIT $2)`, transactionColumns, conditions))
+	}
+	if filter.Direction != models.TransactionDirectionOutgoing {
+		sides = append(sides, fmt.Sprintf(`(SELECT %s, created_at AS cursor_created_at FROM transactions
+			WHERE destination_account_id = $1%s ORDER BY created_at DESC, id DESC LIMIT $2)`, transactionColumns, conditions))
+	}
+	query := fmt.Sprintf(`
+		SELECT * FROM (%s) page
+		ORDER BY cursor_created_at DESC, id DESC
+		LIMIT $2
+	`, strings.Join(sides, " UNION ALL "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving transactions for account", logger.AccountID(accountID), "err", err)
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	defer rows.Close()
+
+	page := &models.TransactionPage{Transactions: make([]*models.Transaction, 0, limit)}
+	var last models.TransactionCursor
+	for rows.Next() {
+		var cursor models.TransactionCursor
+		tx, err := scanTransaction(cursorScanner{rows, &cursor.CreatedAt})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		if len(page.Transactions) == limit {
+			page.NextCursor = last.Encode()
+			break
+		}
+		cursor.ID = tx.ID
+		page.Transactions = append(page.Transactions, tx)
+		last = cursor
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transactions: %w", err)
+	}
+	return page, nil
+}
+
+// cursorScanner scans a row selected with transactionColumns followed by the full-precision
+// created_at a cursor is built from; scanTransaction only keeps it to the second
+type cursorScanner struct {
+	row       rowScanner
+	createdAt *time.Time
+}
+
+func (s cursorScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.createdAt)...)
+}
+
+// GetTransactionsByAccountInPeriod retrieves an account's transactions in [from, to) on the given time axis,
+// oldest first
+func (r *PostgresTransactionRepository) GetTransactionsByAccountInPeriod(ctx context.Context, accountID int64, from, to time.Time, axis models.TimeAxis) ([]*models.Transaction, error) {
+	r.log.InfoContext(ctx, "Retrieving transactions for account in period", logger.AccountID(accountID), "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339), "axis", axis)
+
+	column := axisColumn(axis)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE (source_account_id = $1 OR destination_account_id = $1)
+			AND %s >= $2 AND %s < $3
+		ORDER BY %s, id
+	`, transactionColumns, column, column, column)
+
+	transactions, err := r.queryTransactions(ctx, query, accountID, from, to)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving transactions for account in period", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+
+	r.log.InfoContext(ctx, "Successfully retrieved transactions for account in period", "count", len(transactions), logger.AccountID(accountID))
+	return transactions, nil
+}
+
+// balanceMovement is the signed effect of transaction t on the balance of account a: the
+// converted amount credited to the destination, the amount debited from the source
+const balanceMovement = `CASE WHEN t.destination_account_id = a.account_id THEN COALESCE(t.converted_amount, t.amount) ELSE -t.amount END`
+
+// entryMovement is the signed effect of ledger entry e on the balance of its account
+const entryMovement = `CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE -e.amount END`
+
+// GetBalanceAsOf computes an account's balance at the given instant on the given time axis. On
+// the recorded axis it starts from the latest balance snapshot at or before the instant and
+// replays the account's ledger entries posted since; otherwise, or without such a snapshot, it
+// backs out the settled transactions that took place after the instant from the current balance.
+// Snapshot, entries, current balance and transactions are read in a single statement, so they
+// are consistent.
+func (r *PostgresTransactionRepository) GetBalanceAsOf(ctx context.Context, accountID int64, at time.Time, axis models.TimeAxis) (decimal.Decimal, error) {
+	r.log.InfoContext(ctx, "Computing balance of account", "at", at.Format(time.RFC3339), "axis", axis, logger.AccountID(accountID))
+
+	column := axisColumn(axis)
+	fromSnapshot := "NULL"
+	if axis == models.TimeAxisRecorded {
+		fromSnapshot = fmt.Sprintf(`(
+			SELECT s.balance + COALESCE((
+				SELECT SUM(%s)
+				FROM ledger_entries e
+				WHERE e.account_id = a.account_id
+					AND e.created_at > s.as_of AND e.created_at <= $2
+			), 0)
+			FROM balance_snapshots s
+			WHERE s.account_id = a.account_id AND s.as_of <= $2
+			ORDER BY s.as_of DESC
+			LIMIT 1
+		)`, entryMovement)
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%[1]s, a.balance - COALESCE((
+			SELECT SUM(%[2]s)
+			FROM transactions t
+			WHERE (t.source_account_id = a.account_id OR t.destination_account_id = a.account_id)
+				AND t.status IN %[3]s
+				AND t.%[4]s > $2
+		), 0))
+		FROM accounts a
+		WHERE a.account_id = $1
+	`, fromSnapshot, balanceMovement, settledStatuses, column)
+
+	var balance decimal.Decimal
+	err := r.db.QueryRowContext(ctx, query, accountID, at).Scan(&balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Account not found computing balance", logger.AccountID(accountID))
+			return decimal.Zero, errors.NewAccountNotFoundError(accountID)
+		}
+		r.log.ErrorContext(ctx, "Database error computing balance of account", logger.AccountID(accountID), "err", err)
+		return decimal.Zero, fmt.Errorf("failed to compute balance: %w", err)
+	}
+
+	r.log.InfoContext(ctx, "Successfully computed balance of account", "at", at.Format(time.RFC3339), logger.AccountID(accountID), "balance", balance)
+	return balance, nil
+}
+
+// CreateBalanceSnapshots records the balance as of asOf of up to limit accounts that don't have a
+// snapshot at that instant yet, and returns the number recorded. asOf must lie far enough in the
+// past that every transfer recorded before it has committed.
+func (r *PostgresTransactionRepository) CreateBalanceSnapshots(ctx context.Context, asOf time.Time, limit int) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO balance_snapshots (account_id, as_of, balance)
+		SELECT a.account_id, $1, a.balance - COALESCE((
+			SELECT SUM(%s)
+			FROM transactions t
+			WHERE (t.source_account_id = a.account_id OR t.destination_account_id = a.account_id)
+				AND t.status IN %s
+				AND t.created_at > $1
+		), 0)
+		FROM accounts a
+		WHERE a.created_at <= $1
+			AND NOT EXISTS (SELECT 1 FROM balance_snapshots s WHERE s.account_id = a.account_id AND s.as_of = $1)
+		ORDER BY a.account_id
+		LIMIT $2
+		ON CONFLICT (account_id, as_of) DO NOTHING
+	`, balanceMovement, settledStatuses)
+
+	result, err := r.db.ExecContext(ctx, query, asOf, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error creating balance snapshots", "as_of", asOf.Format(time.RFC3339), "err", err)
+		return 0, fmt.Errorf("failed to create balance snapshots: %w", err)
+	}
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create balance snapshots: %w", err)
+	}
+	if created > 0 {
+		r.log.InfoContext(ctx, "Created balance snapshots", "created", created, "as_of", asOf.Format(time.RFC3339))
+	}
+	return int(created), nil
+}
+
+// SearchTransactionsByTag retrieves transactions carrying tag recorded in [from, to), newest first.
+// A zero from or to leaves that end of the range open. The tags containment test is served by
+// the GIN index on tags.
+func (r *PostgresTransactionRepository) SearchTransactionsByTag(ctx context.Context, tag string, from, to time.Time, limit int) ([]*models.Transaction, error) {
+	r.log.InfoContext(ctx, "Searching transactions by tag", "tag", tag, "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339), "limit", limit)
+
+	contains, err := json.Marshal([]string{tag})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tag: %w", err)
+	}
+
+	args := []interface{}{string(contains), limit}
+	conditions := "tags @> $1::jsonb"
+	if !from.IsZero() {
+		args = append(args, from)
+		conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !to.IsZero() {
+		args = append(args, to)
+		conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, transactionColumns, conditions)
+
+	transactions, err := r.queryTransactions(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error searching transactions by tag", "tag", tag, "err", err)
+		return nil, err
+	}
+
+	r.log.InfoContext(ctx, "Found transactions tagged", "count", len(transactions), "tag", tag)
+	return transactions, nil
+}
+
+// GetTransactionsByBusinessDate retrieves up to limit transactions booked on a business date, oldest first
+func (r *PostgresTransactionRepository) GetTransactionsByBusinessDate(ctx context.Context, businessDate string, limit int) ([]*models.Transaction, error) {
+	r.log.InfoContext(ctx, "Retrieving transactions booked on business date", "business_date", businessDate, "limit", limit)
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE business_date = $1
+		ORDER BY id
+		LIMIT $2
+	`
+	transactions, err := r.queryTransactions(ctx, query, businessDate, limit)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving transactions for business date", "business_date", businessDate, "err", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// SumOutboundWithTx returns the total amount of the complete transfers from an account recorded
+// on or after the business date since, excluding fees, within a database transaction. Transfers
+// made earlier in tx count too.
+func (r *PostgresTransactionRepository) SumOutboundWithTx(ctx context.Context, tx *sql.Tx, accountID int64, since string) (decimal.Decimal, error) {
+	var total decimal.Decimal
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE source_account_id = $1 AND business_date >= $2 AND status = $3 AND fee_of IS NULL
+	`, accountID, since, models.TransactionStatusComplete).Scan(&total)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error summing outbound transfers of account", logger.AccountID(accountID), "since", since, "err", err)
+		return decimal.Zero, fmt.Errorf("failed to sum outbound transfers: %w", err)
+	}
+	return total, nil
+}
+
+// CountTenantOutboundWithTx returns the number of complete transfers from the accounts of a tenant
+// recorded on the business date, excluding fees, within a database transaction. Transfers made
+// earlier in tx count too.
+func (r *PostgresTransactionRepository) CountTenantOutboundWithTx(ctx context.Context, tx *sql.Tx, tenantID, businessDate string) (int64, error) {
+	var count int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM transactions t
+		JOIN accounts a ON a.account_id = t.source_account_id
+		WHERE a.tenant_id = $1 AND t.business_date = $2 AND t.status = $3 AND t.fee_of IS NULL
+	`, tenantID, businessDate, models.TransactionStatusComplete).Scan(&count)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error counting outbound transfers of tenant", "tenant_id", tenantID, "business_date", businessDate, "err", err)
+		return 0, fmt.Errorf("failed to count tenant outbound transfers: %w", err)
+	}
+	return count, nil
+}
+
+// ExportTransactions retrieves up to limit transactions of scope recorded before until with an ID
+// above afterID, by ID. A transfer between two accounts of the scope is returned once.
+func (r *PostgresTransactionRepository) ExportTransactions(ctx context.Context, scope models.ExportScope, until time.Time, afterID int64, limit int) ([]*models.Transaction, error) {
+	var query string
+	var args []interface{}
+	if scope.TenantID != "" {
+		query = `
+			SELECT ` + transactionColumns + `
+			FROM transactions
+			WHERE id > $2 AND created_at < $3
+				AND (source_account_id IN (SELECT account_id FROM accounts WHERE tenant_id = $1)
+					OR destination_account_id IN (SELECT account_id FROM accounts WHERE tenant_id = $1))
+			ORDER BY id
+			LIMIT $4
+		`
+		args = []interface{}{scope.TenantID, afterID, until, limit}
+	} else {
+		query = `
+			SELECT ` + transactionColumns + `
+			FROM transactions
+			WHERE id > $2 AND created_at < $3 AND (source_account_id = $1 OR destination_account_id = $1)
+			ORDER BY id
+			LIMIT $4
+		`
+		args = []interface{}{scope.AccountID, afterID, until, limit}
+	}
+
+	transactions, err := r.queryTransactions(ctx, query, args...)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error exporting transactions", "scope", scope, "after_id", afterID, "err", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetBusinessDaySummaries sums the completed transactions booked on each business date in [from, to],
+// oldest first. Dates without transactions are omitted.
+func (r *PostgresTransactionRepository) GetBusinessDaySummaries(ctx context.Context, from, to string) ([]*models.BusinessDaySummary, error) {
+	r.log.InfoContext(ctx, "Summarizing business dates", "from", from, "to", to)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT business_date, COUNT(*), COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE business_date BETWEEN $1 AND $2 AND status IN `+settledStatuses+`
+		GROUP BY business_date
+		ORDER BY business_date
+	`, from, to)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error summarizing business dates", "from", from, "to", to, "err", err)
+		return nil, fmt.Errorf("failed to summarize business dates: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := []*models.BusinessDaySummary{}
+	for rows.Next() {
+		var summary models.BusinessDaySummary
+		var businessDate time.Time
+		if err := rows.Scan(&businessDate, &summary.TransactionCount, &summary.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan business date summary: %w", err)
+		}
+		summary.BusinessDate = businessDate.Format(businessday.DateLayout)
+		summaries = append(summaries, &summary)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating business date summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// SetTransactionTags replaces the tags of a transaction
+func (r *PostgresTransactionRepository) SetTransactionTags(ctx context.Context, transactionID int64, tags []string) error {
+	r.log.InfoContext(ctx, "Setting tags of transaction", logger.TransactionID(transactionID), "tags", tags)
+
+	encoded, err := marshalTags(tags)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE transactions SET tags = $1::jsonb WHERE id = $2`, encoded, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error setting tags of transaction", logger.TransactionID(transactionID), "err", err)
+		return fmt.Errorf("failed to set transaction tags: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		r.log.WarnContext(ctx, "Transaction not found when setting tags", logger.TransactionID(transactionID))
+		return errors.NewTransactionNotFoundError(transactionID)
+	}
+	return nil
+}
+
+// GetTransactionByID retrieves a transaction by its ID
+func (r *PostgresTransactionRepository) GetTransactionByID(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	transaction, err := scanTransaction(r.db.QueryRowContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE id = $1
+	`, transactionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Transaction not found", logger.TransactionID(transactionID))
+			return nil, errors.NewTransactionNotFoundError(transactionID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// GetFeeTransactions retrieves the fees collected for a transaction, oldest first
+func (r *PostgresTransactionRepository) GetFeeTransactions(ctx context.Context, transactionID int64) ([]*models.Transaction, error) {
+	transactions, err := r.queryTransactions(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE fee_of = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving fees of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// CreateSplitTransferWithTx records a split transfer within a database transaction and returns
+// it with its ID; its legs are recorded separately with CreateTransactionWithTx
+func (r *PostgresTransactionRepository) CreateSplitTransferWithTx(ctx context.Context, tx *sql.Tx, split *models.SplitTransfer) (*models.SplitTransfer, error) {
+	r.log.InfoContext(ctx, "Recording split transfer", "source_account_id", split.SourceAccountID, "amount", split.Amount, "legs", len(split.Legs))
+
+	created := &models.SplitTransfer{SourceAccountID: split.SourceAccountID, Amount: split.Amount}
+	var createdAt time.Time
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO split_transfers (source_account_id, amount, leg_count)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, split.SourceAccountID, split.Amount, len(split.Legs)).Scan(&created.ID, &createdAt)
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation recording split transfer", "source_account_id", split.SourceAccountID, "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error recording split transfer", "source_account_id", split.SourceAccountID, "err", err)
+		return nil, fmt.Errorf("failed to record split transfer: %w", err)
+	}
+	created.CreatedAt = createdAt.Format(time.RFC3339)
+	return created, nil
+}
+
+// GetSplitTransfer retrieves a split transfer with its legs in the order they were made
+func (r *PostgresTransactionRepository) GetSplitTransfer(ctx context.Context, splitID int64) (*models.SplitTransfer, error) {
+	split := &models.SplitTransfer{ID: splitID}
+	var createdAt time.Time
+	err := r.db.QueryRowContext(ctx, `
+		SELECT source_account_id, amount, created_at FROM split_transfers WHERE id = $1
+	`, splitID).Scan(&split.SourceAccountID, &split.Amount, &createdAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Split transfer not found", "split_id", splitID)
+			return nil, fmt.Errorf("%w: %d", errors.ErrSplitTransferNotFound, splitID)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving split transfer", "split_id", splitID, "err", err)
+		return nil, fmt.Errorf("failed to retrieve split transfer: %w", err)
+	}
+	split.CreatedAt = createdAt.Format(time.RFC3339)
+
+	if split.Legs, err = r.queryTransactions(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE split_id = $1
+		ORDER BY id
+	`, splitID); err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving legs of split transfer", "split_id", splitID, "err", err)
+		return nil, err
+	}
+	return split, nil
+}
+
+// MarkTransactionReversedWithTx marks a completed transaction as reversed within a database
+// transaction and returns it. Transactions that aren't complete, and reversals themselves, can't
+// be reversed.
+func (r *PostgresTransactionRepository) MarkTransactionReversedWithTx(ctx context.Context, tx *sql.Tx, transactionID int64) (*models.Transaction, error) {
+	r.log.InfoContext(ctx, "Marking transaction as reversed", logger.TransactionID(transactionID))
+
+	transaction, err := scanTransaction(tx.QueryRowContext(ctx, `
+		UPDATE transactions
+		SET status = $2
+		WHERE id = $1 AND status = $3 AND reversal_of IS NULL
+		RETURNING `+transactionColumns,
+		transactionID, models.TransactionStatusReversed, models.TransactionStatusComplete))
+	if err == nil {
+		return transaction, nil
+	}
+	if err != sql.ErrNoRows {
+		r.log.ErrorContext(ctx, "Database error marking transaction as reversed", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to mark transaction reversed: %w", err)
+	}
+
+	// Nothing was updated: tell a missing transaction from one that can't be reversed
+	var status models.TransactionStatus
+	var reversalOf int64
+	err = tx.QueryRowContext(ctx, `SELECT status, COALESCE(reversal_of, 0) FROM transactions WHERE id = $1`, transactionID).Scan(&status, &reversalOf)
+	if err == sql.ErrNoRows {
+		r.log.WarnContext(ctx, "Transaction not found", logger.TransactionID(transactionID))
+		return nil, errors.NewTransactionNotFoundError(transactionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if reversalOf != 0 {
+		return nil, fmt.Errorf("%w: transaction %d is the reversal of transaction %d", errors.ErrTransactionNotReversible, transactionID, reversalOf)
+	}
+	return nil, fmt.Errorf("%w: transaction %d is %s", errors.ErrTransactionNotReversible, transactionID, status)
+}
+
+// ResolvePendingApprovalWithTx records the outcome of a transaction held in pending_approval
+// within a database transaction and returns it. A completed transfer also records the
+// destination credited, its business date and its conversion, as they were when it was made.
+func (r *PostgresTransactionRepository) ResolvePendingApprovalWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
+	r.log.InfoContext(ctx, "Resolving transaction pending approval", logger.TransactionID(transaction.ID), "status", transaction.Status)
+
+	if _, err := businessday.ParseDate(transaction.BusinessDate); err != nil {
+		return nil, fmt.Errorf("%w: invalid business date %q", errors.ErrValidationFailed, transaction.BusinessDate)
+	}
+	resolved, err := scanTransaction(tx.QueryRowContext(ctx, `
+		UPDATE transactions
+		SET status = $2, destination_account_id = $3, business_date = $4, converted_amount = $5, fx_rate = $6
+		WHERE id = $1 AND status = $7
+		RETURNING `+transactionColumns,
+		transaction.ID, transaction.Status, transaction.DestinationAccountID, transaction.BusinessDate,
+		nullDecimal(transaction.ConvertedAmount), nullDecimal(transaction.FXRate), models.TransactionStatusPendingApproval))
+	if err == sql.ErrNoRows {
+		r.log.WarnContext(ctx, "Transaction is not pending approval", logger.TransactionID(transaction.ID))
+		return nil, fmt.Errorf("%w: transaction %d is not pending approval", errors.ErrApprovalResolved, transaction.ID)
+	}
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error resolving transaction", logger.TransactionID(transaction.ID), "err", err)
+		return nil, fmt.Errorf("failed to resolve transaction pending approval: %w", err)
+	}
+	return resolved, nil
+}
+
+// AddStatusChangeWithTx appends an entry to a transaction's status history within a transaction
+func (r *PostgresTransactionRepository) AddStatusChangeWithTx(ctx context.Context, tx *sql.Tx, change *models.TransactionStatusChange) error {
+	var fromStatus interface{}
+	if change.FromStatus != "" {
+		fromStatus = change.FromStatus
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor)
+		VALUES ($1, $2, $3, $4)
+	`, change.TransactionID, fromStatus, change.ToStatus, change.Actor)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording status change of transaction", logger.TransactionID(change.TransactionID), "to_status", change.ToStatus, "err", err)
+		return fmt.Errorf("failed to record transaction status change: %w", err)
+	}
+	return nil
+}
+
+// GetStatusHistory retrieves a transaction's status changes, oldest first
+func (r *PostgresTransactionRepository) GetStatusHistory(ctx context.Context, transactionID int64) ([]*models.TransactionStatusChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transaction_id, COALESCE(from_status, ''), to_status, actor, created_at
+		FROM transaction_status_history
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving status history of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, fmt.Errorf("failed to get transaction status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*models.TransactionStatusChange
+	for rows.Next() {
+		var change models.TransactionStatusChange
+		var changedAt time.Time
+		if err := rows.Scan(&change.ID, &change.TransactionID, &change.FromStatus, &change.ToStatus, &change.Actor, &changedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction status change: %w", err)
+		}
+		change.ChangedAt = changedAt.Format(time.RFC3339)
+		history = append(history, &change)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction status history: %w", err)
+	}
+	return history, nil
+}
+
+// GetTransactionByBatchItemKey retrieves the transaction recorded for a batch item
+func (r *PostgresTransactionRepository) GetTransactionByBatchItemKey(ctx context.Context, key string) (*models.Transaction, error) {
+	return getTransactionByUniqueColumn(ctx, r.db, "batch_item_key", key)
+}
+
+// GetTransactionByBatchItemKeyWithTx retrieves the transaction recorded for a batch item within a
+// transaction, so the lookup and a following insert see the same snapshot
+func (r *PostgresTransactionRepository) GetTransactionByBatchItemKeyWithTx(ctx context.Context, tx *sql.Tx, key string) (*models.Transaction, error) {
+	return getTransactionByUniqueColumn(ctx, tx, "batch_item_key", key)
+}
+
+// GetTransactionByExternalReference retrieves the transaction recorded with an external reference
+func (r *PostgresTransactionRepository) GetTransactionByExternalReference(ctx context.Context, reference string) (*models.Transaction, error) {
+	return getTransactionByUniqueColumn(ctx, r.db, "external_reference", reference)
+}
+
+// GetTransactionByExternalReferenceWithTx retrieves the transaction recorded with an external reference
+// within a transaction
+func (r *PostgresTransactionRepository) GetTransactionByExternalReferenceWithTx(ctx context.Context, tx *sql.Tx, reference string) (*models.Transaction, error) {
+	return getTransactionByUniqueColumn(ctx, tx, "external_reference", reference)
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getTransactionByUniqueColumn looks up a transaction by one of its uniquely indexed columns.
+// column is never user input.
+func getTransactionByUniqueColumn(ctx context.Context, q rowQuerier, column, value string) (*models.Transaction, error) {
+	logger.Default().InfoContext(ctx, "Looking up transaction", "column", column, "value", value)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE %s = $1
+	`, transactionColumns, column)
+	transaction, err := scanTransaction(q.QueryRowContext(ctx, query, value))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s=%s", errors.ErrTransactionNotFound, column, value)
+		}
+		logger.Default().ErrorContext(ctx, "Database error looking up transaction", "column", column, "value", value, "err", err)
+		return nil, fmt.Errorf("failed to get transaction by %s: %w", column, err)
+	}
+	return transaction, nil
+}
+
+// RegisterBatch records a batch under its owner and key, or returns the batch the owner already
+// registered under the key
+func (r *PostgresTransactionRepository) RegisterBatch(ctx context.Context, batch *models.Batch) (*models.Batch, error) {
+	r.log.InfoContext(ctx, "Registering transfer batch items", "key", batch.Key, "item_count", batch.ItemCount)
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO transfer_batches (owner, batch_key, fingerprint, item_count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (owner, batch_key) DO NOTHING
+	`, batch.Owner, batch.Key, batch.Fingerprint, batch.ItemCount)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error registering batch", "key", batch.Key, "err", err)
+		return nil, fmt.Errorf("failed to register batch: %w", err)
+	}
+
+	registered := models.Batch{Owner: batch.Owner, Key: batch.Key}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT fingerprint, item_count FROM transfer_batches WHERE owner = $1 AND batch_key = $2
+	`, batch.Owner, batch.Key).Scan(&registered.Fingerprint, &registered.ItemCount)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error reading batch", "key", batch.Key, "err", err)
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+	return &registered, nil
+}
+
+// marshalTags encodes tags for the JSONB tags column; nil is stored as an empty array.
+// The result is a string: lib/pq would send a []byte as bytea.
+func marshalTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	encoded, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tags: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// queryTransactions runs a query selecting transactionColumns and scans every row
+func (r *PostgresTransactionRepository) queryTransactions(ctx context.Context, query string, args ...interface{}) ([]*models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		logger.Error("Database error retrieving transactions for account %d: %v", accountID, err)
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
 	defer rows.Close()
 
 	var transactions []*models.Transaction
 	for rows.Next() {
-		var tx models.Transaction
-		var createdAt time.Time
-		err := rows.Scan(
-			&tx.ID,
-			&tx.SourceAccountID,
-			&tx.DestinationAccountID,
-			&tx.Amount,
-			&tx.Status,
-			&createdAt,
-		)
+		tx, err := scanTransaction(rows)
 		if err != nil {
-			logger.Error("Failed to scan transaction for account %d: %v", accountID, err)
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
 		}
-		tx.CreatedAt = createdAt.Format(time.RFC3339)
-		transactions = append(transactions, &tx)
+		transactions = append(transactions, tx)
 	}
 
 	if err = rows.Err(); err != nil {
-		logger.Error("Error iterating transactions for account %d: %v", accountID, err)
 		return nil, fmt.Errorf("error iterating transactions: %w", err)
 	}
-
-	logger.Info("Successfully retrieved %d transactions for account %d", len(transactions), accountID)
 	return transactions, nil
 }
 
 // CreateTransactionWithTx creates a transaction record within a database transaction
 func (r *PostgresTransactionRepository) CreateTransactionWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
-	logger.Info("Creating transaction record in database: source=%d, destination=%d, amount=%s, status=%s",
-		transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String(), transaction.Status)
+	r.log.InfoContext(ctx, "Creating transaction record in database", "source_account_id", transaction.SourceAccountID, "destination_account_id", transaction.DestinationAccountID, "amount", transaction.Amount, "status", transaction.Status)
 
 	// Validate transaction
 	if err := transaction.Validate(); err != nil {
-		logger.Warn("Transaction validation failed: %v", err)
+		r.log.WarnContext(ctx, "Transaction validation failed", "err", err)
+		return nil, err
+	}
+
+	// The value date defaults to the recording time; back-dated corrections set it explicitly
+	createdAt := time.Now()
+	valueDate := createdAt
+	if transaction.ValueDate != "" {
+		parsed, err := time.Parse(time.RFC3339, transaction.ValueDate)
+		if err != nil {
+			r.log.WarnContext(ctx, "Invalid value date for transaction", "value_date", transaction.ValueDate)
+			return nil, fmt.Errorf("%w: invalid value date %q", errors.ErrValidationFailed, transaction.ValueDate)
+		}
+		valueDate = parsed
+	}
+
+	// The business date is stamped by the service from its calendar; default to the UTC date
+	businessDate := transaction.BusinessDate
+	if businessDate == "" {
+		businessDate = businessday.UTC().Date(createdAt)
+	} else if _, err := businessday.ParseDate(businessDate); err != nil {
+		r.log.WarnContext(ctx, "Invalid business date for transaction", "business_date", businessDate)
+		return nil, fmt.Errorf("%w: invalid business date %q", errors.ErrValidationFailed, businessDate)
+	}
+
+	tags, err := marshalTags(transaction.Tags)
+	if err != nil {
 		return nil, err
 	}
 
 	query := `
-		INSERT INTO transactions (source_account_id, destination_account_id, amount, status, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, source_account_id, destination_account_id, amount, status, created_at
-	`
+		INSERT INTO transactions (source_account_id, destination_account_id, amount, status, created_at, value_date, tags, batch_item_key, external_reference, business_date, reversal_of, converted_amount, fx_rate, fee_of, fee_rule_id, split_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, NULLIF($8, ''), NULLIF($9, ''), $10, NULLIF($11, 0), $12, $13, NULLIF($14, 0), NULLIF($15, 0), NULLIF($16, 0))
+		RETURNING ` + transactionColumns
 
-	var createdTx models.Transaction
-	var createdAt time.Time
-	err := tx.QueryRowContext(ctx, query,
+	createdTx, err := scanTransaction(tx.QueryRowContext(ctx, query,
 		transaction.SourceAccountID,
 		transaction.DestinationAccountID,
 		transaction.Amount,
 		transaction.Status,
-		time.Now(),
-	).Scan(
-		&createdTx.ID,
-		&createdTx.SourceAccountID,
-		&createdTx.DestinationAccountID,
-		&createdTx.Amount,
-		&createdTx.Status,
-		&createdAt,
-	)
+		createdAt,
+		valueDate,
+		tags,
+		transaction.BatchItemKey,
+		transaction.ExternalReference,
+		businessDate,
+		transaction.ReversalOf,
+		nullDecimal(transaction.ConvertedAmount),
+		nullDecimal(transaction.FXRate),
+		transaction.FeeOf,
+		transaction.FeeRuleID,
+		transaction.SplitID,
+	))
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation":
-				logger.Warn("Foreign key violation creating transaction: source=%d, destination=%d",
-					transaction.SourceAccountID, transaction.DestinationAccountID)
-				return nil, errors.ErrAccountNotFound
-			case "check_constraint_violation":
-				logger.Warn("Check constraint violation creating transaction: amount=%s", transaction.Amount.String())
-				return nil, errors.ErrInvalidAmount
-			}
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation creating transaction", "source_account_id", transaction.SourceAccountID, "destination_account_id", transaction.DestinationAccountID, "amount", transaction.Amount, "err", err)
+			return nil, domainErr
 		}
-		logger.Error("Database error creating transaction: %v", err)
+		r.log.ErrorContext(ctx, "Database error creating transaction", "err", err)
 		return nil, fmt.Errorf("failed to record transaction: %w", err)
 	}
 
-	createdTx.CreatedAt = createdAt.Format(time.RFC3339)
-
-	logger.Info("Successfully created transaction record in database: id=%d, source=%d, destination=%d, amount=%s",
-		createdTx.ID, createdTx.SourceAccountID, createdTx.DestinationAccountID, createdTx.Amount.String())
-	return &createdTx, nil
+	r.log.InfoContext(ctx, "Successfully created transaction record in database", "id", createdTx.ID, "source_account_id", createdTx.SourceAccountID, "destination_account_id", createdTx.DestinationAccountID, "amount", createdTx.Amount)
+	return createdTx, nil
 }
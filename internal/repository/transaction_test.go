@@ -3,15 +3,19 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
 	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransactionRepository_CreateTransactionWithTx(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -75,7 +79,7 @@ func TestTransactionRepository_CreateTransactionWithTx(t *testing.T) {
 			createdTx, err := repo.CreateTransactionWithTx(ctx, tx, tt.transaction)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Equal(t, tt.expectedError, err)
+				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, createdTx)
@@ -96,6 +100,8 @@ func TestTransactionRepository_CreateTransactionWithTx(t *testing.T) {
 }
 
 func TestTransactionRepository_GetTransactionsByAccount(t *testing.T) {
+	t.Parallel()
+
 	db := testutil.NewTestDB(t)
 	testutil.SetupTestDB(t, db)
 	defer testutil.CleanupTestDB(t, db)
@@ -154,3 +160,732 @@ func TestTransactionRepository_GetTransactionsByAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionRepository_GetTransactionsByAccountPage(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	accountID, otherID := int64(777781), int64(777782)
+	require.NoError(t, accountRepo.CreateAccount(ctx, accountID, decimal.NewFromInt(1000)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, otherID, decimal.NewFromInt(1000)))
+
+	// Transfers in both directions, all in one database transaction so they share created_at and
+	// the id alone decides their order
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		source, dest := accountID, otherID
+		if i%2 == 1 {
+			source, dest = otherID, accountID
+		}
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{SourceAccountID: source, DestinationAccountID: dest, Amount: decimal.NewFromInt(1)})
+		require.NoError(t, err)
+		ids = append([]int64{created.ID}, ids...)
+	}
+	require.NoError(t, tx.Commit())
+
+	var (
+		seen  []int64
+		after *models.TransactionCursor
+		pages int
+	)
+	for {
+		page, err := repo.GetTransactionsByAccountPage(ctx, accountID, models.TransactionFilter{}, 2, after)
+		require.NoError(t, err)
+		pages++
+		for _, tx := range page.Transactions {
+			seen = append(seen, tx.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor, err := models.DecodeTransactionCursor(page.NextCursor)
+		require.NoError(t, err)
+		after = &cursor
+	}
+	assert.Equal(t, ids, seen, "newest first, each transaction exactly once")
+	assert.Equal(t, 3, pages)
+
+	page, err := repo.GetTransactionsByAccountPage(ctx, int64(999999), models.TransactionFilter{}, 2, nil)
+	require.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	assert.Empty(t, page.NextCursor)
+
+	t.Run("filters", func(t *testing.T) {
+		two := decimal.NewFromInt(2)
+		tests := []struct {
+			name     string
+			filter   models.TransactionFilter
+			expected []int64
+		}{
+			{name: "outgoing", filter: models.TransactionFilter{Direction: models.TransactionDirectionOutgoing}, expected: []int64{ids[0], ids[2], ids[4]}},
+			{name: "incoming", filter: models.TransactionFilter{Direction: models.TransactionDirectionIncoming}, expected: []int64{ids[1], ids[3]}},
+			{name: "status", filter: models.TransactionFilter{Status: models.TransactionStatusFailed}, expected: nil},
+			{name: "min amount", filter: models.TransactionFilter{MinAmount: &two}, expected: nil},
+			{name: "max amount", filter: models.TransactionFilter{MaxAmount: &two}, expected: ids},
+			{name: "window", filter: models.TransactionFilter{To: time.Now().Add(-time.Hour)}, expected: nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := repo.GetTransactionsByAccountPage(ctx, accountID, tt.filter, 10, nil)
+				require.NoError(t, err)
+				var got []int64
+				for _, tx := range page.Transactions {
+					got = append(got, tx.ID)
+				}
+				assert.Equal(t, tt.expected, got)
+			})
+		}
+	})
+}
+
+func TestTransactionRepository_GetBalanceAsOf(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID := int64(666666)
+	destID := int64(666667)
+	err := accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromFloat(1000.00))
+	assert.NoError(t, err)
+	err = accountRepo.CreateAccount(ctx, destID, decimal.Zero)
+	assert.NoError(t, err)
+
+	// Record a back-dated transfer: effective a week ago, recorded now
+	valueDate := time.Now().Add(-7 * 24 * time.Hour).UTC().Truncate(time.Second)
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	_, err = repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID:      sourceID,
+		DestinationAccountID: destID,
+		Amount:               decimal.NewFromFloat(100.00),
+		Status:               models.TransactionStatusComplete,
+		ValueDate:            valueDate.Format(time.RFC3339),
+	})
+	assert.NoError(t, err)
+	err = accountRepo.UpdateBalanceWithTx(ctx, tx, destID, decimal.NewFromFloat(100.00))
+	assert.NoError(t, err)
+	err = tx.Commit()
+	assert.NoError(t, err)
+
+	threeDaysAgo := time.Now().Add(-3 * 24 * time.Hour)
+
+	tests := []struct {
+		name            string
+		axis            models.TimeAxis
+		expectedBalance decimal.Decimal
+	}{
+		{
+			name:            "recorded axis excludes transfer recorded after the instant",
+			axis:            models.TimeAxisRecorded,
+			expectedBalance: decimal.Zero,
+		},
+		{
+			name:            "effective axis includes back-dated transfer",
+			axis:            models.TimeAxisEffective,
+			expectedBalance: decimal.NewFromFloat(100.00),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance, err := repo.GetBalanceAsOf(ctx, destID, threeDaysAgo, tt.axis)
+			assert.NoError(t, err)
+			assert.True(t, tt.expectedBalance.Equal(balance), "expected %s, got %s", tt.expectedBalance, balance)
+		})
+	}
+
+	_, err = repo.GetBalanceAsOf(ctx, 999999, threeDaysAgo, models.TimeAxisRecorded)
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+}
+
+func TestTransactionRepository_BalanceSnapshots(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	ledgerRepo := NewLedgerRepository(db)
+	sourceID := int64(676767)
+	destID := int64(676768)
+	assert.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromFloat(1000.00)))
+	assert.NoError(t, accountRepo.CreateAccount(ctx, destID, decimal.Zero))
+
+	transfer := func(amount decimal.Decimal) {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID:      sourceID,
+			DestinationAccountID: destID,
+			Amount:               amount,
+			Status:               models.TransactionStatusComplete,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, ledgerRepo.RecordEntriesWithTx(ctx, tx, transferEntries(created, models.TransferTypeStandard)))
+		assert.NoError(t, accountRepo.UpdateBalanceWithTx(ctx, tx, sourceID, amount.Neg()))
+		assert.NoError(t, accountRepo.UpdateBalanceWithTx(ctx, tx, destID, amount))
+		assert.NoError(t, tx.Commit())
+	}
+
+	transfer(decimal.NewFromFloat(100.00))
+	asOf := time.Now()
+	transfer(decimal.NewFromFloat(50.00))
+
+	created, err := repo.CreateBalanceSnapshots(ctx, asOf, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+	created, err = repo.CreateBalanceSnapshots(ctx, asOf, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, created)
+
+	var snapshot decimal.Decimal
+	err = db.QueryRowContext(ctx, "SELECT balance FROM balance_snapshots WHERE account_id = $1", destID).Scan(&snapshot)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(100.00).Equal(snapshot), "expected snapshot of 100, got %s", snapshot)
+
+	// Skew the snapshot to tell which queries start from it
+	_, err = db.ExecContext(ctx, "UPDATE balance_snapshots SET balance = balance + 1 WHERE account_id = $1", destID)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		at              time.Time
+		axis            models.TimeAxis
+		expectedBalance decimal.Decimal
+	}{
+		{
+			name:            "recorded axis replays the entries posted after the snapshot",
+			at:              time.Now().Add(time.Hour),
+			axis:            models.TimeAxisRecorded,
+			expectedBalance: decimal.NewFromFloat(151.00),
+		},
+		{
+			name:            "effective axis ignores snapshots",
+			at:              time.Now().Add(time.Hour),
+			axis:            models.TimeAxisEffective,
+			expectedBalance: decimal.NewFromFloat(150.00),
+		},
+		{
+			name:            "instant before every snapshot backs out from the current balance",
+			at:              asOf.Add(-time.Hour),
+			axis:            models.TimeAxisRecorded,
+			expectedBalance: decimal.Zero,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance, err := repo.GetBalanceAsOf(ctx, destID, tt.at, tt.axis)
+			assert.NoError(t, err)
+			assert.True(t, tt.expectedBalance.Equal(balance), "expected %s, got %s", tt.expectedBalance, balance)
+		})
+	}
+}
+
+func TestTransactionRepository_SplitTransfer(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	for _, id := range []int64{686868, 686869, 686870} {
+		assert.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromFloat(1000.00)))
+	}
+	split, err := models.NewSplitTransfer(686868, []models.SplitLeg{
+		{DestinationAccountID: 686869, Amount: decimal.NewFromFloat(100.00)},
+		{DestinationAccountID: 686870, Amount: decimal.NewFromFloat(25.00)},
+	})
+	assert.NoError(t, err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	created, err := repo.CreateSplitTransferWithTx(ctx, tx, split)
+	assert.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	for _, leg := range split.Legs {
+		leg.SplitID = created.ID
+		leg.Status = models.TransactionStatusComplete
+		_, err := repo.CreateTransactionWithTx(ctx, tx, leg)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tx.Commit())
+
+	found, err := repo.GetSplitTransfer(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(686868), found.SourceAccountID)
+	assert.True(t, decimal.NewFromFloat(125.00).Equal(found.Amount))
+	if assert.Len(t, found.Legs, 2) {
+		assert.Equal(t, int64(686869), found.Legs[0].DestinationAccountID)
+		assert.Equal(t, created.ID, found.Legs[1].SplitID)
+	}
+
+	_, err = repo.GetSplitTransfer(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrSplitTransferNotFound)
+}
+
+func TestTransactionRepository_ExportTransactions(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	acmeA, acmeB, other := int64(686801), int64(686802), int64(686803)
+	for _, id := range []int64{acmeA, acmeB, other} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.NewFromInt(1000)))
+	}
+	require.NoError(t, accountRepo.SetTenant(ctx, acmeA, "acme"))
+	require.NoError(t, accountRepo.SetTenant(ctx, acmeB, "acme"))
+
+	var ids []int64
+	for _, pair := range [][2]int64{{acmeA, acmeB}, {acmeA, other}, {other, acmeB}, {other, acmeA}} {
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID: pair[0], DestinationAccountID: pair[1], Amount: decimal.NewFromInt(1), Status: models.TransactionStatusComplete,
+		})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		ids = append(ids, created.ID)
+	}
+	until := time.Now().Add(time.Minute)
+
+	exportIDs := func(scope models.ExportScope, afterID int64, limit int) []int64 {
+		transactions, err := repo.ExportTransactions(ctx, scope, until, afterID, limit)
+		require.NoError(t, err)
+		exported := []int64{}
+		for _, tx := range transactions {
+			exported = append(exported, tx.ID)
+		}
+		return exported
+	}
+
+	// A transfer within the tenant is exported once
+	assert.Equal(t, ids, exportIDs(models.ExportScope{TenantID: "acme"}, 0, 10))
+	assert.Equal(t, ids[:2], exportIDs(models.ExportScope{TenantID: "acme"}, 0, 2))
+	assert.Equal(t, ids[2:], exportIDs(models.ExportScope{TenantID: "acme"}, ids[1], 10))
+	assert.Equal(t, []int64{ids[1], ids[2], ids[3]}, exportIDs(models.ExportScope{AccountID: other}, 0, 10))
+	assert.Empty(t, exportIDs(models.ExportScope{TenantID: "globex"}, 0, 10))
+
+	// Transactions recorded after the end of the export are left out
+	transactions, err := repo.ExportTransactions(ctx, models.ExportScope{AccountID: acmeA}, time.Now().Add(-time.Hour), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestTransactionRepository_SearchTransactionsByTag(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	assert.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromFloat(1000.00)))
+	assert.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	var ids []int64
+	for _, tags := range [][]string{{"payroll", "cc-100"}, {"cc-100"}, {"payroll"}} {
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromFloat(10.00),
+			Status:               models.TransactionStatusComplete,
+			Tags:                 tags,
+		})
+		assert.NoError(t, err)
+		ids = append(ids, created.ID)
+	}
+	assert.NoError(t, tx.Commit())
+
+	transactions, err := repo.SearchTransactionsByTag(ctx, "payroll", time.Time{}, time.Time{}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	for _, transaction := range transactions {
+		assert.Contains(t, transaction.Tags, "payroll")
+	}
+
+	transactions, err = repo.SearchTransactionsByTag(ctx, "payroll", time.Now().Add(time.Hour), time.Time{}, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	// Retagging moves the transaction into the search results
+	assert.NoError(t, repo.SetTransactionTags(ctx, ids[1], []string{"payroll"}))
+	transactions, err = repo.SearchTransactionsByTag(ctx, "payroll", time.Time{}, time.Time{}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 3)
+
+	err = repo.SetTransactionTags(ctx, 999999, []string{"payroll"})
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+}
+
+func TestTransactionRepository_BatchItemKey(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	assert.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromFloat(1000.00)))
+	assert.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	transaction := &models.Transaction{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromFloat(10.00),
+		Status:               models.TransactionStatusComplete,
+		BatchItemKey:         (&models.Batch{Key: "b1"}).ItemKey("item-1"),
+		ExternalReference:    "PO-1001",
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	_, err = repo.GetTransactionByBatchItemKeyWithTx(ctx, tx, transaction.BatchItemKey)
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+	created, err := repo.CreateTransactionWithTx(ctx, tx, transaction)
+	assert.NoError(t, err)
+	found, err := repo.GetTransactionByBatchItemKeyWithTx(ctx, tx, transaction.BatchItemKey)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	found, err = repo.GetTransactionByExternalReferenceWithTx(ctx, tx, "PO-1001")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.NoError(t, tx.Commit())
+
+	found, err = repo.GetTransactionByBatchItemKey(ctx, transaction.BatchItemKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "PO-1001", found.ExternalReference)
+	found, err = repo.GetTransactionByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusComplete, found.Status)
+	assert.Equal(t, "PO-1001", found.ExternalReference)
+	_, err = repo.GetTransactionByID(ctx, created.ID+1000)
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+	found, err = repo.GetTransactionByExternalReference(ctx, "PO-1001")
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.BatchItemKey, found.BatchItemKey)
+	_, err = repo.GetTransactionByExternalReference(ctx, "PO-404")
+	assert.ErrorIs(t, err, errors.ErrTransactionNotFound)
+
+	// The unique index rejects recording the same key twice
+	tx, err = db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	_, err = repo.CreateTransactionWithTx(ctx, tx, transaction)
+	assert.ErrorIs(t, err, errors.ErrDuplicateIdempotencyKey)
+	assert.NoError(t, tx.Rollback())
+
+	// ... and the same external reference under a different key
+	tx, err = db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	again := *transaction
+	again.BatchItemKey = "other-key"
+	_, err = repo.CreateTransactionWithTx(ctx, tx, &again)
+	assert.ErrorIs(t, err, errors.ErrDuplicateExternalReference)
+	assert.NoError(t, tx.Rollback())
+
+	// A batch key keeps the fingerprint it was first registered with
+	registered, err := repo.RegisterBatch(ctx, &models.Batch{Key: "b1", Fingerprint: "aaa", ItemCount: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", registered.Fingerprint)
+	registered, err = repo.RegisterBatch(ctx, &models.Batch{Key: "b1", Fingerprint: "bbb", ItemCount: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", registered.Fingerprint)
+	assert.Equal(t, 1, registered.ItemCount)
+}
+
+func TestTransactionRepository_BusinessDate(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(660001), int64(660002)
+	assert.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromInt(1000)))
+	assert.NoError(t, accountRepo.CreateAccount(ctx, destID, decimal.NewFromInt(0)))
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+	for _, booking := range []struct {
+		businessDate string
+		amount       int64
+	}{
+		{"2024-03-01", 10},
+		{"2024-03-01", 15},
+		{"2024-03-04", 7},
+		{"", 1}, // defaults to today's UTC date
+	} {
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID:      sourceID,
+			DestinationAccountID: destID,
+			Amount:               decimal.NewFromInt(booking.amount),
+			Status:               models.TransactionStatusComplete,
+			BusinessDate:         booking.businessDate,
+		})
+		assert.NoError(t, err)
+		if booking.businessDate == "" {
+			assert.Equal(t, time.Now().UTC().Format("2006-01-02"), created.BusinessDate)
+		} else {
+			assert.Equal(t, booking.businessDate, created.BusinessDate)
+		}
+	}
+	_, err = repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: sourceID, DestinationAccountID: destID, Amount: decimal.NewFromInt(1), BusinessDate: "01/03/2024",
+	})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	assert.NoError(t, tx.Commit())
+
+	booked, err := repo.GetTransactionsByBusinessDate(ctx, "2024-03-01", 10)
+	assert.NoError(t, err)
+	assert.Len(t, booked, 2)
+
+	summaries, err := repo.GetBusinessDaySummaries(ctx, "2024-03-01", "2024-03-31")
+	assert.NoError(t, err)
+	if assert.Len(t, summaries, 2) {
+		assert.Equal(t, "2024-03-01", summaries[0].BusinessDate)
+		assert.Equal(t, int64(2), summaries[0].TransactionCount)
+		assert.True(t, decimal.NewFromInt(25).Equal(summaries[0].Volume))
+		assert.Equal(t, "2024-03-04", summaries[1].BusinessDate)
+	}
+}
+
+func TestTransactionRepository_SumOutboundWithTx(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	sourceID, destID := int64(661001), int64(661002)
+	require.NoError(t, accountRepo.CreateAccount(ctx, sourceID, decimal.NewFromInt(1000)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, destID, decimal.Zero))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	record := func(from, to int64, businessDate string, amount int64, status models.TransactionStatus, feeOf int64) *models.Transaction {
+		created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID:      from,
+			DestinationAccountID: to,
+			Amount:               decimal.NewFromInt(amount),
+			Status:               status,
+			BusinessDate:         businessDate,
+			FeeOf:                feeOf,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	transfer := record(sourceID, destID, "2024-03-05", 40, models.TransactionStatusComplete, 0)
+	record(sourceID, destID, "2024-03-04", 25, models.TransactionStatusComplete, 0)
+	record(sourceID, destID, "2024-02-28", 100, models.TransactionStatusComplete, 0)
+	record(sourceID, destID, "2024-03-05", 60, models.TransactionStatusReversed, 0)
+	record(sourceID, destID, "2024-03-05", 2, models.TransactionStatusComplete, transfer.ID) // a fee
+	record(destID, sourceID, "2024-03-05", 5, models.TransactionStatusComplete, 0)           // inbound
+
+	for since, expected := range map[string]int64{"2024-03-05": 40, "2024-03-01": 65, "2024-02-01": 165, "2024-04-01": 0} {
+		total, err := repo.SumOutboundWithTx(ctx, tx, sourceID, since)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(expected).Equal(total), "since %s: got %s", since, total)
+	}
+}
+
+func TestTransactionRepository_MarkTransactionReversedWithTx(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromFloat(1000.00)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	original, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	reversed, err := repo.MarkTransactionReversedWithTx(ctx, tx, original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusReversed, reversed.Status)
+	reversal, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete, ReversalOf: original.ID,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	found, err := repo.GetTransactionByID(ctx, reversal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, original.ID, found.ReversalOf)
+
+	tests := []struct {
+		name          string
+		transactionID int64
+		expectedError error
+	}{
+		{name: "already reversed", transactionID: original.ID, expectedError: errors.ErrTransactionNotReversible},
+		{name: "reversal itself", transactionID: reversal.ID, expectedError: errors.ErrTransactionNotReversible},
+		{name: "missing transaction", transactionID: reversal.ID + 1000, expectedError: errors.ErrTransactionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := db.BeginTx(ctx, nil)
+			require.NoError(t, err)
+			defer tx.Rollback()
+			_, err = repo.MarkTransactionReversedWithTx(ctx, tx, tt.transactionID)
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+
+	// The unique index rejects a second reversal of the same transaction
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete, ReversalOf: original.ID,
+	})
+	assert.ErrorIs(t, err, errors.ErrTransactionNotReversible)
+	assert.NoError(t, tx.Rollback())
+}
+
+func TestTransactionRepository_StatusHistory(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromFloat(1000.00)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.AddStatusChangeWithTx(ctx, tx, &models.TransactionStatusChange{
+		TransactionID: created.ID, FromStatus: models.TransactionStatusPending, ToStatus: models.TransactionStatusComplete, Actor: "system",
+	}))
+	require.NoError(t, repo.AddStatusChangeWithTx(ctx, tx, &models.TransactionStatusChange{
+		TransactionID: created.ID, FromStatus: models.TransactionStatusComplete, ToStatus: models.TransactionStatusReversed, Actor: "ops:alice",
+	}))
+	require.NoError(t, tx.Commit())
+
+	history, err := repo.GetStatusHistory(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.TransactionStatusPending, history[0].FromStatus)
+	assert.Equal(t, models.TransactionStatusComplete, history[0].ToStatus)
+	assert.Equal(t, "ops:alice", history[1].Actor)
+	assert.Equal(t, models.TransactionStatusReversed, history[1].ToStatus)
+	assert.NotEmpty(t, history[1].ChangedAt)
+
+	history, err = repo.GetStatusHistory(ctx, created.ID+1000)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestTransactionRepository_CrossCurrency(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	repo := NewTransactionRepository(db)
+	accountRepo := NewAccountRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromFloat(1000.00)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	converted, rate := decimal.NewFromInt(135), decimal.RequireFromString("1.35")
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	created, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), Status: models.TransactionStatusComplete,
+		ConvertedAmount: &converted, FXRate: &rate,
+	})
+	require.NoError(t, err)
+	plain, err := repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	found, err := repo.GetTransactionByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.ConvertedAmount)
+	require.NotNil(t, found.FXRate)
+	assert.True(t, converted.Equal(*found.ConvertedAmount))
+	assert.True(t, rate.Equal(*found.FXRate))
+	assert.True(t, converted.Equal(found.CreditAmount()))
+
+	found, err = repo.GetTransactionByID(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.ConvertedAmount)
+	assert.Nil(t, found.FXRate)
+
+	// A converted amount without its rate is rejected
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = repo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+		SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10), Status: models.TransactionStatusComplete,
+		ConvertedAmount: &converted,
+	})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
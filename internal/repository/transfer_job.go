@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+)
+
+type PostgresTransferJobRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewTransferJobRepository(db *sql.DB) *PostgresTransferJobRepository {
+	return &PostgresTransferJobRepository{db: db, log: logger.Default()}
+}
+
+// transferJobColumns is the column list selected by every transfer job read, in scanTransferJob order
+const transferJobColumns = `id, source_account_id, destination_account_id, amount, status, priority, attempts, next_attempt_at,
+	COALESCE(transaction_id, 0), COALESCE(error_code, ''), COALESCE(last_error, ''), submitted_by,
+	COALESCE(idempotency_key, ''), owner, completed_at, created_at`
+
+// scanTransferJob scans a row selected with transferJobColumns
+func scanTransferJob(row rowScanner) (*models.TransferJob, error) {
+	var job models.TransferJob
+	var nextAttemptAt, createdAt time.Time
+	var completedAt sql.NullTime
+	err := row.Scan(
+		&job.ID,
+		&job.SourceAccountID,
+		&job.DestinationAccountID,
+		&job.Amount,
+		&job.Status,
+		&job.Priority,
+		&job.Attempts,
+		&nextAttemptAt,
+		&job.TransactionID,
+		&job.ErrorCode,
+		&job.LastError,
+		&job.SubmittedBy,
+		&job.IdempotencyKey,
+		&job.Owner,
+		&completedAt,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsQueued() {
+		job.NextAttemptAt = nextAttemptAt.Format(time.RFC3339)
+	}
+	if completedAt.Valid {
+		job.CompletedAt = completedAt.Time.Format(time.RFC3339)
+	}
+	job.CreatedAt = createdAt.Format(time.RFC3339)
+	return &job, nil
+}
+
+// CreateTransferJob queues a transfer job to be attempted from now on, starting its history, and
+// returns it, and true. A job submitted under an idempotency key its owner already used isn't
+// queued again: the existing job is returned, and false.
+func (r *PostgresTransferJobRepository) CreateTransferJob(ctx context.Context, job *models.TransferJob, now time.Time) (*models.TransferJob, bool, error) {
+	r.log.InfoContext(ctx, "Queueing transfer job", "source_account_id", job.SourceAccountID, "destination_account_id", job.DestinationAccountID, "amount", job.Amount, "priority", job.Priority, "submitted_by", job.SubmittedBy)
+
+	created, err := scanTransferJob(r.db.QueryRowContext(ctx, `
+		WITH job AS (
+			INSERT INTO transfer_jobs (source_account_id, destination_account_id, amount, status, priority, next_attempt_at, submitted_by, idempotency_key, owner)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)
+			ON CONFLICT (owner, idempotency_key) DO NOTHING
+			RETURNING *
+		), event AS (
+			INSERT INTO transfer_job_events (job_id, action, to_status, priority, actor)
+			SELECT id, $10, status, priority, submitted_by FROM job
+		)
+		SELECT `+transferJobColumns+` FROM job`,
+		job.SourceAccountID, job.DestinationAccountID, job.Amount, models.TransferJobStatusQueued, job.Priority, now, job.SubmittedBy, job.IdempotencyKey, job.Owner,
+		models.TransferJobActionSubmitted))
+	if err == sql.ErrNoRows {
+		existing, err := r.getTransferJob(ctx, `owner = $1 AND idempotency_key = $2`, job.Owner, job.IdempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation queueing transfer job", "err", err)
+			return nil, false, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error queueing transfer job", "err", err)
+		return nil, false, fmt.Errorf("failed to create transfer job: %w", err)
+	}
+	return created, true, nil
+}
+
+// GetTransferJob retrieves a transfer job by its ID
+func (r *PostgresTransferJobRepository) GetTransferJob(ctx context.Context, jobID int64) (*models.TransferJob, error) {
+	return r.getTransferJob(ctx, `id = $1`, jobID)
+}
+
+// getTransferJob retrieves the transfer job matching the condition on args
+func (r *PostgresTransferJobRepository) getTransferJob(ctx context.Context, condition string, args ...interface{}) (*models.TransferJob, error) {
+	job, err := scanTransferJob(r.db.QueryRowContext(ctx, `
+		SELECT `+transferJobColumns+`
+		FROM transfer_jobs
+		WHERE `+condition, args...))
+	if err != nil {
+		arg := args[len(args)-1]
+		if err == sql.ErrNoRows {
+			r.log.WarnContext(ctx, "Transfer job not found", "arg", arg)
+			return nil, fmt.Errorf("%w: %v", errors.ErrTransferJobNotFound, arg)
+		}
+		r.log.ErrorContext(ctx, "Database error retrieving transfer job", "arg", arg, "err", err)
+		return nil, fmt.Errorf("failed to get transfer job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimQueuedWithTx retrieves and locks the most urgent queued transfer job ready at now, the one
+// that has waited the longest among those, or returns nil if none is ready. Jobs locked by another
+// worker are skipped.
+func (r *PostgresTransferJobRepository) ClaimQueuedWithTx(ctx context.Context, tx *sql.Tx, now time.Time) (*models.TransferJob, error) {
+	job, err := scanTransferJob(tx.QueryRowContext(ctx, `
+		SELECT `+transferJobColumns+`
+		FROM transfer_jobs
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY CASE priority WHEN 'critical' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END, next_attempt_at, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`, models.TransferJobStatusQueued, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error claiming queued transfer job", "err", err)
+		return nil, fmt.Errorf("failed to claim queued transfer job: %w", err)
+	}
+	return job, nil
+}
+
+// MarkCompletedWithTx marks a transfer job made by the given transaction
+func (r *PostgresTransferJobRepository) MarkCompletedWithTx(ctx context.Context, tx *sql.Tx, jobID, transactionID int64) error {
+	r.log.InfoContext(ctx, "Transfer job completed by transaction", "job_id", jobID, logger.TransactionID(transactionID))
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE transfer_jobs
+		SET status = $2, attempts = attempts + 1, error_code = NULL, last_error = NULL, transaction_id = $3, completed_at = NOW()
+		WHERE id = $1
+	`, jobID, models.TransferJobStatusCompleted, transactionID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error completing transfer job", "job_id", jobID, "err", err)
+		return fmt.Errorf("failed to complete transfer job: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: id %d", errors.ErrTransferJobNotFound, jobID)
+	}
+	return nil
+}
+
+// RecordFailureWithTx counts a failed attempt of a queued transfer job within a transaction and
+// keeps its error code and message. The job is retried at nextAttemptAt, or marked failed once it
+// was attempted maxAttempts times; a maxAttempts of 1 fails it at once. A job resolved meanwhile
+// is left alone.
+func (r *PostgresTransferJobRepository) RecordFailureWithTx(ctx context.Context, tx *sql.Tx, jobID int64, code, attemptErr string, nextAttemptAt time.Time, maxAttempts int) (*models.TransferJob, error) {
+	job, err := scanTransferJob(tx.QueryRowContext(ctx, `
+		UPDATE transfer_jobs
+		SET attempts = attempts + 1,
+			error_code = $2,
+			last_error = $3,
+			next_attempt_at = $4,
+			status = CASE WHEN attempts + 1 >= $5 THEN $6 ELSE status END,
+			completed_at = CASE WHEN attempts + 1 >= $5 THEN NOW() END
+		WHERE id = $1 AND status = $7
+		RETURNING `+transferJobColumns,
+		jobID, code, attemptErr, nextAttemptAt, maxAttempts, models.TransferJobStatusFailed, models.TransferJobStatusQueued))
+	if err == sql.ErrNoRows {
+		return r.GetTransferJob(ctx, jobID)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording failure of transfer job", "job_id", jobID, "err", err)
+		return nil, fmt.Errorf("failed to record transfer job failure: %w", err)
+	}
+	return job, nil
+}
+
+// GetTransferJobForUpdateWithTx retrieves and locks a transfer job within a transaction. A job a
+// worker is attempting stays locked until the attempt is over.
+func (r *PostgresTransferJobRepository) GetTransferJobForUpdateWithTx(ctx context.Context, tx *sql.Tx, jobID int64) (*models.TransferJob, error) {
+	job, err := scanTransferJob(tx.QueryRowContext(ctx, `
+		SELECT `+transferJobColumns+`
+		FROM transfer_jobs
+		WHERE id = $1
+		FOR UPDATE
+	`, jobID))
+	if err == sql.ErrNoRows {
+		r.log.WarnContext(ctx, "Transfer job not found", "job_id", jobID)
+		return nil, fmt.Errorf("%w: %d", errors.ErrTransferJobNotFound, jobID)
+	}
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error locking transfer job", "job_id", jobID, "err", err)
+		return nil, fmt.Errorf("failed to lock transfer job: %w", err)
+	}
+	return job, nil
+}
+
+// CancelWithTx marks a transfer job cancelled within a transaction and returns it
+func (r *PostgresTransferJobRepository) CancelWithTx(ctx context.Context, tx *sql.Tx, jobID int64) (*models.TransferJob, error) {
+	return r.updateWithTx(ctx, tx, jobID, `status = $2, completed_at = NOW()`, models.TransferJobStatusCancelled)
+}
+
+// RequeueWithTx queues a transfer job again within a transaction, to be attempted from now on
+// with all its attempts, and returns it
+func (r *PostgresTransferJobRepository) RequeueWithTx(ctx context.Context, tx *sql.Tx, jobID int64, now time.Time) (*models.TransferJob, error) {
+	return r.updateWithTx(ctx, tx, jobID, `status = $2, attempts = 0, next_attempt_at = $3, error_code = NULL, last_error = NULL, completed_at = NULL`,
+		models.TransferJobStatusQueued, now)
+}
+
+// SetPriorityWithTx changes the priority of a transfer job within a transaction and returns it
+func (r *PostgresTransferJobRepository) SetPriorityWithTx(ctx context.Context, tx *sql.Tx, jobID int64, class priority.Class) (*models.TransferJob, error) {
+	return r.updateWithTx(ctx, tx, jobID, `priority = $2`, class)
+}
+
+// updateWithTx applies the SET clause assignments, whose parameters are args from $2 on, to a
+// transfer job within a transaction and returns it
+func (r *PostgresTransferJobRepository) updateWithTx(ctx context.Context, tx *sql.Tx, jobID int64, assignments string, args ...interface{}) (*models.TransferJob, error) {
+	job, err := scanTransferJob(tx.QueryRowContext(ctx, `
+		UPDATE transfer_jobs
+		SET `+assignments+`
+		WHERE id = $1
+		RETURNING `+transferJobColumns, append([]interface{}{jobID}, args...)...))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %d", errors.ErrTransferJobNotFound, jobID)
+	}
+	if err != nil {
+		if domainErr := translatePgError(err); domainErr != nil {
+			r.log.WarnContext(ctx, "Constraint violation updating transfer job", "job_id", jobID, "err", err)
+			return nil, domainErr
+		}
+		r.log.ErrorContext(ctx, "Database error updating transfer job", "job_id", jobID, "err", err)
+		return nil, fmt.Errorf("failed to update transfer job: %w", err)
+	}
+	return job, nil
+}
+
+// AddEventWithTx appends an entry to the history of a transfer job within a transaction
+func (r *PostgresTransferJobRepository) AddEventWithTx(ctx context.Context, tx *sql.Tx, event *models.TransferJobEvent) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transfer_job_events (job_id, action, from_status, to_status, priority, actor, reason)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)
+	`, event.JobID, event.Action, event.FromStatus, event.ToStatus, event.Priority, event.Actor, event.Reason)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error recording event of transfer job", "action", event.Action, "job_id", event.JobID, "err", err)
+		return fmt.Errorf("failed to record transfer job event: %w", err)
+	}
+	return nil
+}
+
+// GetHistory retrieves the history of a transfer job, oldest first
+func (r *PostgresTransferJobRepository) GetHistory(ctx context.Context, jobID int64) ([]*models.TransferJobEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, job_id, action, COALESCE(from_status, ''), to_status, priority, actor, reason, created_at
+		FROM transfer_job_events
+		WHERE job_id = $1
+		ORDER BY id
+	`, jobID)
+	if err != nil {
+		r.log.ErrorContext(ctx, "Database error retrieving history of transfer job", "job_id", jobID, "err", err)
+		return nil, fmt.Errorf("failed to get transfer job history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.TransferJobEvent
+	for rows.Next() {
+		var event models.TransferJobEvent
+		var createdAt time.Time
+		if err := rows.Scan(&event.ID, &event.JobID, &event.Action, &event.FromStatus, &event.ToStatus, &event.Priority, &event.Actor, &event.Reason, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer job event: %w", err)
+		}
+		event.CreatedAt = createdAt.Format(time.RFC3339)
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfer job events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// primedStatements are statements of the transfer path. Preparing them on a fresh connection
+// makes its server process load the catalog entries of the tables and indexes they touch, which
+// the first request on the connection would otherwise wait for.
+var primedStatements = []string{
+	`SELECT ` + accountColumns + ` FROM accounts WHERE account_id = $1`,
+	`UPDATE accounts SET balance = $1, last_activity_at = NOW() WHERE account_id = $2`,
+	`SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1`,
+	`SELECT ` + transactionColumns + ` FROM transactions WHERE batch_item_key = $1`,
+	`SELECT tenant_id FROM tenant_settings WHERE tenant_id = $1`,
+}
+
+// PrimeConn prepares the statements of the transfer path on conn and returns the number prepared.
+// The statements are closed again; only the server-side caches they filled remain.
+func PrimeConn(ctx context.Context, conn *sql.Conn) (int, error) {
+	for i, query := range primedStatements {
+		stmt, err := conn.PrepareContext(ctx, query)
+		if err != nil {
+			return i, fmt.Errorf("failed to prime statement: %w", err)
+		}
+		stmt.Close()
+	}
+	return len(primedStatements), nil
+}
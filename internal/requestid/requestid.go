@@ -0,0 +1,68 @@
+// Package requestid gives every request an identifier, taken from the X-Request-ID header or
+// generated, and carries it from the HTTP request into log lines and back to the client in
+// responses, so a customer report can be correlated with the server logs of its request.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header carries the identifier of a request, both on the request and on its response
+const Header = "X-Request-ID"
+
+// MaxLength bounds the length of an identifier accepted from a client
+const MaxLength = 128
+
+type contextKey struct{}
+
+// New generates a random request identifier
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// WithID returns a copy of ctx carrying a request identifier; an empty id leaves ctx unchanged
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request identifier carried by ctx, or "" if it has none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Valid reports whether a client-supplied identifier can be accepted: non-empty, at most
+// MaxLength long and made of printable ASCII other than spaces, so it can't forge log lines
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware accepts the Header of each request if it is Valid, or generates a new identifier
+// otherwise, carries it in the request context and sets it on the response before the handler
+// runs, so every response, error responses included, echoes it
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !Valid(id) {
+			id = New()
+		}
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	req.Header.Set(Header, "req-42")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "req-42", seen)
+	assert.Equal(t, "req-42", rec.Header().Get(Header))
+
+	// A missing or unacceptable identifier is replaced by a generated one
+	for _, id := range []string{"", "two words", "line\nbreak", strings.Repeat("a", MaxLength+1)} {
+		req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+		req.Header.Set(Header, id)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Len(t, seen, 32, id)
+		assert.NotEqual(t, id, seen)
+		assert.Equal(t, seen, rec.Header().Get(Header))
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.NotEqual(t, New(), New())
+	assert.True(t, Valid(New()))
+}
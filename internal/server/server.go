@@ -0,0 +1,48 @@
+// Package server builds the HTTP server with the connection tuning from config
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// New creates an HTTP server for handler listening on the configured port.
+//
+// The read header timeout bounds slow-loris clients; the write timeout bounds the whole
+// handler, so it must exceed the slowest legitimate request. With HTTP/2 enabled the server
+// also accepts cleartext HTTP/2 (prior knowledge or Upgrade), which internal callers behind a
+// TLS-terminating proxy use to multiplex requests over few connections.
+func New(cfg *config.Config, handler http.Handler) *http.Server {
+	idleTimeout := ms(cfg.HTTPIdleTimeout)
+
+	if cfg.HTTP2Enabled {
+		handler = h2c.NewHandler(handler, &http2.Server{
+			MaxConcurrentStreams: uint32(cfg.HTTP2MaxStreams),
+			IdleTimeout:          idleTimeout,
+		})
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.ServerPort),
+		Handler:           handler,
+		ReadTimeout:       ms(cfg.HTTPReadTimeout),
+		ReadHeaderTimeout: ms(cfg.HTTPReadHeaderTimeout),
+		WriteTimeout:      ms(cfg.HTTPWriteTimeout),
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+	}
+	srv.SetKeepAlivesEnabled(cfg.HTTPKeepAlives)
+
+	logger.Default().Info("HTTP server configured", "read_timeout", srv.ReadTimeout, "read_header_timeout", srv.ReadHeaderTimeout, "write_timeout", srv.WriteTimeout, "idle_timeout", srv.IdleTimeout, "max_header_bytes", srv.MaxHeaderBytes, "keep_alives", cfg.HTTPKeepAlives, "http2", cfg.HTTP2Enabled)
+	return srv
+}
+
+func ms(v int) time.Duration {
+	return time.Duration(v) * time.Millisecond
+}
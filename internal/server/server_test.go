@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		ServerPort:            8080,
+		HTTPReadTimeout:       5000,
+		HTTPReadHeaderTimeout: 2000,
+		HTTPWriteTimeout:      10000,
+		HTTPIdleTimeout:       120000,
+		HTTPMaxHeaderBytes:    4096,
+		HTTPKeepAlives:        true,
+		HTTP2MaxStreams:       100,
+	}
+}
+
+func TestNew_AppliesTuning(t *testing.T) {
+	srv := New(testConfig(), http.NotFoundHandler())
+
+	assert.Equal(t, ":8080", srv.Addr)
+	assert.Equal(t, 5*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 2*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 10*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, srv.IdleTimeout)
+	assert.Equal(t, 4096, srv.MaxHeaderBytes)
+}
+
+func TestNew_HTTP2Cleartext(t *testing.T) {
+	cfg := testConfig()
+	cfg.HTTP2Enabled = true
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	ts := httptest.NewUnstartedServer(New(cfg, handler).Handler)
+	ts.Start()
+	defer ts.Close()
+
+	// Prior-knowledge h2c client
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	// HTTP/1.1 clients keep working
+	resp1, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp1.Body.Close()
+	assert.Equal(t, 1, resp1.ProtoMajor)
+}
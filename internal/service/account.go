@@ -2,55 +2,411 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/budget"
 	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
 	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
 	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
 )
 
 // accountService implements the AccountService interface
 type accountService struct {
-	repo repository.AccountRepository
+	repo         repository.AccountRepository
+	tenants      repository.TenantRepository
+	quotaMetrics *metrics.Quotas
+	events       EventPublisher
+	outbox       repository.OutboxRepository
+	notes        repository.AccountNoteRepository
+	log          *slog.Logger
+}
+
+// AccountServiceOption configures optional behavior of the account service
+type AccountServiceOption func(*accountService)
+
+// WithAccountQuotas warns when assigning an account takes its tenant past the warning threshold
+// of its account quota, and records the usage of account quotas in m. The cap itself is enforced
+// by the repository either way.
+func WithAccountQuotas(tenants repository.TenantRepository, m *metrics.Quotas) AccountServiceOption {
+	return func(s *accountService) {
+		s.tenants = tenants
+		s.quotaMetrics = m
+	}
+}
+
+// WithAccountEventPublisher publishes an account.created event for every account created
+func WithAccountEventPublisher(publisher EventPublisher) AccountServiceOption {
+	return func(s *accountService) {
+		s.events = publisher
+	}
+}
+
+// WithAccountOutbox records account.created events in the outbox instead of publishing them, to
+// be published by the outbox relay. The account is created outside a database transaction the
+// event could join, so the event is recorded right after; it takes precedence over
+// WithAccountEventPublisher.
+func WithAccountOutbox(outbox repository.OutboxRepository) AccountServiceOption {
+	return func(s *accountService) {
+		s.outbox = outbox
+	}
+}
+
+// WithAccountLogger logs through l instead of the package-level logger
+func WithAccountLogger(l *slog.Logger) AccountServiceOption {
+	return func(s *accountService) {
+		s.log = l
+	}
 }
 
 // NewAccountService creates a new account service instance
-func NewAccountService(repo repository.AccountRepository) AccountService {
-	return &accountService{
+func NewAccountService(repo repository.AccountRepository, opts ...AccountServiceOption) AccountService {
+	s := &accountService{
 		repo: repo,
+		log:  logger.Default(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateAccount creates a new account with validation
 func (s *accountService) CreateAccount(ctx context.Context, req *dto.CreateAccountRequest) error {
-	logger.Info("Creating account with ID: %d, initial balance: %s", req.AccountID, req.InitialBalance.String())
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Creating account", logger.AccountID(req.AccountID), "initial_balance", req.InitialBalance.String())
 
 	if req.InitialBalance.IsNegative() {
-		logger.Warn("Invalid initial balance for account %d: %s (negative amount)", req.AccountID, req.InitialBalance.String())
-		return errors.ErrInvalidAmount
+		s.log.WarnContext(ctx, "Invalid initial balance: negative amount", logger.AccountID(req.AccountID), "initial_balance", req.InitialBalance.String())
+		return errors.NewInvalidAmountError(req.InitialBalance)
+	}
+
+	if err := models.ValidateAmountPrecision(req.InitialBalance); err != nil {
+		s.log.WarnContext(ctx, "Invalid initial balance: exceeds supported precision", logger.AccountID(req.AccountID), "initial_balance", req.InitialBalance.String())
+		return err
 	}
 
 	err := s.repo.CreateAccount(ctx, req.AccountID, req.InitialBalance)
 	if err != nil {
-		logger.Error("Failed to create account %d: %v", req.AccountID, err)
+		s.log.ErrorContext(ctx, "Failed to create account", logger.AccountID(req.AccountID), "err", err)
 		return err
 	}
 
-	logger.Info("Successfully created account %d with initial balance %s", req.AccountID, req.InitialBalance.String())
+	s.log.InfoContext(ctx, "Created account", logger.AccountID(req.AccountID), "initial_balance", req.InitialBalance.String())
+	s.publishAccountCreated(ctx, req)
 	return nil
 }
 
+// publishAccountCreated publishes the account.created event of a created account. Publishing is
+// best effort: a failure is logged and doesn't undo the account. Without an outbox, publishing
+// is skipped when the request's latency budget is nearly exhausted.
+func (s *accountService) publishAccountCreated(ctx context.Context, req *dto.CreateAccountRequest) {
+	event := &models.AccountCreated{
+		AccountID:      req.AccountID,
+		InitialBalance: req.InitialBalance,
+		CreatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	switch {
+	case s.outbox != nil:
+		payload, err := json.Marshal(event)
+		if err == nil {
+			_, err = s.outbox.CreateMessage(ctx, &models.OutboxMessage{Event: models.EventAccountCreated, Payload: payload})
+		}
+		if err != nil {
+			s.log.ErrorContext(ctx, "Failed to record event in the outbox", "event", models.EventAccountCreated, "err", err)
+		}
+	case s.events != nil && budget.Allows(ctx, budget.WorkEventPublish):
+		if err := s.events.Publish(ctx, models.EventAccountCreated, event); err != nil {
+			s.log.ErrorContext(ctx, "Failed to publish event", "event", models.EventAccountCreated, "err", err)
+		}
+	}
+}
+
 // GetAccount retrieves an account by its ID
 func (s *accountService) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
-	logger.Info("Retrieving account: %d", accountID)
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Retrieving account", logger.AccountID(accountID))
 
 	account, err := s.repo.GetAccount(ctx, accountID)
 	if err != nil {
-		logger.Error("Failed to retrieve account %d: %v", accountID, err)
+		s.log.ErrorContext(ctx, "Failed to retrieve account", logger.AccountID(accountID), "err", err)
 		return nil, err
 	}
 
-	logger.Info("Successfully retrieved account %d with balance %s", accountID, account.Balance.String())
+	s.log.InfoContext(ctx, "Retrieved account", logger.AccountID(accountID), "balance", account.Balance.String())
 	return account, nil
 }
+
+// ListAccountsByOwner retrieves all accounts held by an external owner reference
+func (s *accountService) ListAccountsByOwner(ctx context.Context, ownerRef string) ([]*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Listing accounts of owner", "owner_ref", ownerRef)
+
+	if err := validateOwnerRef(ownerRef); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.repo.GetAccountsByOwner(ctx, ownerRef)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to list accounts of owner", "owner_ref", ownerRef, "err", err)
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// ListAccounts retrieves one page of the accounts matching filter, in its order, continuing from
+// cursor (from the first if empty), for operational review. limit defaults to 100 and is capped at
+// 1000. A cursor only continues a listing in the order it was issued for.
+func (s *accountService) ListAccounts(ctx context.Context, filter models.AccountListFilter, limit int, cursor string) (*models.AccountPage, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Listing accounts", "filter", fmt.Sprintf("%+v", filter), "limit", limit)
+
+	if err := filter.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid account filter", "err", err)
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = models.DefaultAccountPageSize
+	} else if limit > models.MaxAccountPageSize {
+		limit = models.MaxAccountPageSize
+	}
+	var after *models.AccountCursor
+	if cursor != "" {
+		decoded, err := models.DecodeAccountCursor(cursor)
+		if err != nil {
+			s.log.WarnContext(ctx, "Invalid account cursor", "cursor", cursor)
+			return nil, err
+		}
+		if !decoded.Continues(filter) {
+			return nil, fmt.Errorf("%w: cursor was issued for another sort order", errors.ErrValidationFailed)
+		}
+		after = &decoded
+	}
+
+	page, err := s.repo.ListAccounts(ctx, filter, limit, after)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to list accounts", "err", err)
+		return nil, err
+	}
+	return page, nil
+}
+
+// SetAccountOwner links an account to an external owner reference; an empty ref clears it
+func (s *accountService) SetAccountOwner(ctx context.Context, accountID int64, ownerRef string) error {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Setting account owner", logger.AccountID(accountID), "owner_ref", ownerRef)
+
+	if ownerRef != "" {
+		if err := validateOwnerRef(ownerRef); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.SetOwnerRef(ctx, accountID, ownerRef); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set account owner", logger.AccountID(accountID), "err", err)
+		return err
+	}
+	return nil
+}
+
+// SetAccountTenant assigns an account to a tenant, whose settings then apply to its transfers;
+// an empty tenant clears it
+func (s *accountService) SetAccountTenant(ctx context.Context, accountID int64, tenantID string) error {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Setting account tenant", logger.AccountID(accountID), "tenant_id", tenantID)
+
+	if tenantID != "" {
+		if err := models.ValidateTenantID(tenantID); err != nil {
+			s.log.WarnContext(ctx, "Invalid tenant", logger.AccountID(accountID), "tenant_id", tenantID)
+			return err
+		}
+	}
+
+	if err := s.repo.SetTenant(ctx, accountID, tenantID); err != nil {
+		if stderrors.Is(err, errors.ErrTenantQuotaExceeded) {
+			s.observeAccountQuota(ctx, tenantID, err)
+		}
+		s.log.ErrorContext(ctx, "Failed to set account tenant", logger.AccountID(accountID), "err", err)
+		return err
+	}
+	s.observeAccountQuota(ctx, tenantID, nil)
+	return nil
+}
+
+// observeAccountQuota logs and records the usage of the account quota of a tenant after an
+// account was assigned to it, or rejected with err. Failing to read the usage is only logged.
+func (s *accountService) observeAccountQuota(ctx context.Context, tenantID string, err error) {
+	if s.tenants == nil || tenantID == "" {
+		return
+	}
+	settings, lookupErr := s.tenants.GetTenantSettings(ctx, tenantID)
+	if lookupErr != nil || settings.MaxAccounts == nil {
+		return
+	}
+	accounts, lookupErr := s.repo.CountTenantAccounts(ctx, tenantID)
+	if lookupErr != nil {
+		s.log.WarnContext(ctx, "Failed to count accounts of tenant", "tenant_id", tenantID, "err", lookupErr)
+		return
+	}
+	observeQuota(s.quotaMetrics, tenantID, models.QuotaAccounts, settings.QuotaUsage(models.QuotaAccounts, accounts), err)
+}
+
+// ReactivateAccount returns a dormant account to active so it can send transfers again
+func (s *accountService) ReactivateAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Reactivating account", logger.AccountID(accountID))
+
+	if err := s.repo.ReactivateAccount(ctx, accountID); err != nil {
+		s.log.ErrorContext(ctx, "Failed to reactivate account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return s.repo.GetAccount(ctx, accountID)
+}
+
+// SetAccountStatus freezes, unfreezes or closes an account. A frozen account can neither send
+// nor receive transfers; only an account holding no funds can be closed.
+func (s *accountService) SetAccountStatus(ctx context.Context, accountID int64, status models.AccountStatus) (*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeOperations); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Setting account status", logger.AccountID(accountID), "status", status)
+
+	if err := s.repo.SetStatus(ctx, accountID, status); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set account status", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return s.repo.GetAccount(ctx, accountID)
+}
+
+// SetOverdraftLimit lets an account's balance go below zero down to minus limit; a zero limit
+// restores the non-negative balance rule. The limit can't be set below what the account is
+// already overdrawn by.
+func (s *accountService) SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) (*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Setting overdraft limit", logger.AccountID(accountID), "overdraft_limit", limit.String())
+
+	if err := s.repo.SetOverdraftLimit(ctx, accountID, limit); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set overdraft limit", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return s.repo.GetAccount(ctx, accountID)
+}
+
+// SetAccountLimits replaces the outbound transfer limits of an account; a nil limit doesn't apply
+func (s *accountService) SetAccountLimits(ctx context.Context, accountID int64, limits models.AccountLimits) (*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Setting outbound limits", logger.AccountID(accountID))
+
+	if err := s.repo.SetLimits(ctx, accountID, limits); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set outbound limits", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return s.repo.GetAccount(ctx, accountID)
+}
+
+// SetAccountType changes the type of an account, which selects its minimum balance requirement
+func (s *accountService) SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Setting account type", logger.AccountID(accountID), "account_type", accountType)
+
+	if err := models.ValidateAccountType(accountType); err != nil {
+		s.log.WarnContext(ctx, "Invalid account type", logger.AccountID(accountID), "account_type", accountType)
+		return err
+	}
+
+	account, err := s.repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.IsSystemFloat() {
+		s.log.WarnContext(ctx, "Refusing to change the type of a system float account", logger.AccountID(accountID))
+		return errors.WithAccount(errors.ErrSystemFloatAccount, accountID)
+	}
+
+	if err := s.repo.SetAccountType(ctx, accountID, accountType); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set account type", logger.AccountID(accountID), "err", err)
+		return err
+	}
+	return nil
+}
+
+// SetAccountCurrency sets the ISO 4217 currency of an account. The current balance must be a
+// whole number of minor units of the currency, and a currency can't be changed once set.
+func (s *accountService) SetAccountCurrency(ctx context.Context, accountID int64, currency string) (*models.Account, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Setting account currency", logger.AccountID(accountID), "currency", currency)
+
+	if err := models.ValidateCurrency(currency); err != nil {
+		s.log.WarnContext(ctx, "Invalid currency", logger.AccountID(accountID), "currency", currency)
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateAmountForCurrency(account.Balance, currency); err != nil {
+		s.log.WarnContext(ctx, "Balance is not representable in the currency", logger.AccountID(accountID), "currency", currency, "balance", account.Balance.String())
+		return nil, errors.WithAccount(err, accountID)
+	}
+
+	if err := s.repo.SetCurrency(ctx, accountID, currency); err != nil {
+		s.log.ErrorContext(ctx, "Failed to set account currency", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return s.repo.GetAccount(ctx, accountID)
+}
+
+// validateOwnerRef checks an owner reference is non-empty and fits the owner_ref column
+func validateOwnerRef(ownerRef string) error {
+	if strings.TrimSpace(ownerRef) == "" {
+		return fmt.Errorf("%w: owner reference must not be empty", errors.ErrValidationFailed)
+	}
+	if len(ownerRef) > models.MaxOwnerRefLength {
+		return fmt.Errorf("%w: owner reference exceeds %d characters", errors.ErrValidationFailed, models.MaxOwnerRefLength)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// checkAccountLimits checks a transfer of amount fits the outbound limits of source. The daily
+// and monthly totals are summed within tx, so they include transfers made earlier in it, such as
+// the other legs of a split; the serializable isolation of tx keeps concurrent transfers from
+// both squeezing under a limit.
+func (s *transactionService) checkAccountLimits(ctx context.Context, tx *sql.Tx, source *models.Account, amount decimal.Decimal) error {
+	limits := source.Limits
+	if limits.IsZero() {
+		return nil
+	}
+
+	var sentToday, sentThisMonth decimal.Decimal
+	if limits.Daily != nil || limits.Monthly != nil {
+		today := s.calendar.Date(s.now())
+		day, err := businessday.ParseDate(today)
+		if err != nil {
+			return err
+		}
+		monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC).Format(businessday.DateLayout)
+
+		if limits.Monthly != nil {
+			if sentThisMonth, err = s.transactionRepo.SumOutboundWithTx(ctx, tx, source.AccountID, monthStart); err != nil {
+				s.log.ErrorContext(ctx, "Failed to sum outbound transfers of account", logger.AccountID(source.AccountID), "err", err)
+				return err
+			}
+		}
+		if limits.Daily != nil {
+			if sentToday, err = s.transactionRepo.SumOutboundWithTx(ctx, tx, source.AccountID, today); err != nil {
+				s.log.ErrorContext(ctx, "Failed to sum outbound transfers of account", logger.AccountID(source.AccountID), "err", err)
+				return err
+			}
+		}
+	}
+
+	if err := limits.CheckOutbound(source.AccountID, amount, sentToday, sentThisMonth); err != nil {
+		s.log.WarnContext(ctx, "Transfer exceeds the outbound limits of account", "amount", amount, logger.AccountID(source.AccountID))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+const (
+	defaultAccountNoteLimit = 100
+	maxAccountNoteLimit     = 1000
+)
+
+// WithAccountNotes keeps operator notes on accounts in repo
+func WithAccountNotes(repo repository.AccountNoteRepository) AccountServiceOption {
+	return func(s *accountService) {
+		s.notes = repo
+	}
+}
+
+// notesRepo returns the note store, or an error if account notes are disabled
+func (s *accountService) notesRepo() (repository.AccountNoteRepository, error) {
+	if s.notes == nil {
+		return nil, fmt.Errorf("%w: account notes are not enabled", errors.ErrValidationFailed)
+	}
+	return s.notes, nil
+}
+
+// AddAccountNote adds a note by the actor of ctx to an account, optionally linked to an external
+// support case
+func (s *accountService) AddAccountNote(ctx context.Context, accountID int64, body, caseID string) (*models.AccountNote, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsWrite); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.notesRepo()
+	if err != nil {
+		return nil, err
+	}
+	note := &models.AccountNote{AccountID: accountID, Body: body, CaseID: caseID, Author: actor.FromContext(ctx)}
+	if err := note.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid account note", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return repo.CreateAccountNote(ctx, note)
+}
+
+// ListAccountNotes lists up to limit notes of an account, newest first, narrowed to a support
+// case if caseID isn't empty. limit defaults to 100 and is capped at 1000.
+func (s *accountService) ListAccountNotes(ctx context.Context, accountID int64, caseID string, limit int) ([]*models.AccountNote, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.notesRepo()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultAccountNoteLimit
+	} else if limit > maxAccountNoteLimit {
+		limit = maxAccountNoteLimit
+	}
+	return repo.ListAccountNotes(ctx, accountID, caseID, limit)
+}
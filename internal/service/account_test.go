@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/requestid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAccounts serves accounts from memory
+type stubAccounts struct {
+	repository.AccountRepository
+	accounts map[int64]*models.Account
+}
+
+func (r *stubAccounts) GetAccount(ctx context.Context, accountID int64) (*models.Account, error) {
+	if account, ok := r.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, domainErrors.NewAccountNotFoundError(accountID)
+}
+
+func TestAccountService_LogsStructuredFields(t *testing.T) {
+	var out bytes.Buffer
+	repo := &stubAccounts{accounts: map[int64]*models.Account{7: {AccountID: 7, Balance: decimal.NewFromInt(100)}}}
+	svc := NewAccountService(repo, WithAccountLogger(logger.New(&logger.Config{Level: config.INFO, Format: logger.FormatJSON, Output: &out})))
+
+	ctx := requestid.WithID(context.Background(), "req-42")
+	_, err := svc.GetAccount(ctx, 7)
+	require.NoError(t, err)
+	_, err = svc.GetAccount(ctx, 8)
+	require.Error(t, err)
+
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var record map[string]interface{}
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 4)
+	for _, record := range records {
+		assert.Equal(t, "req-42", record[logger.KeyRequestID])
+	}
+	assert.Equal(t, "Retrieved account", records[1]["msg"])
+	assert.EqualValues(t, 7, records[1][logger.KeyAccountID])
+	assert.Equal(t, "100", records[1]["balance"])
+	assert.Equal(t, "ERROR", records[3]["level"])
+	assert.EqualValues(t, 8, records[3][logger.KeyAccountID])
+}
+
+func TestAccountService_RedactsLoggedAccounts(t *testing.T) {
+	var out bytes.Buffer
+	redaction, err := logger.NewRedaction(true, true, "secret")
+	require.NoError(t, err)
+	repo := &stubAccounts{accounts: map[int64]*models.Account{4815162342: {AccountID: 4815162342, Balance: decimal.RequireFromString("9876.54")}}}
+	svc := NewAccountService(repo, WithAccountLogger(logger.New(&logger.Config{Level: config.INFO, Format: logger.FormatJSON, Output: &out, Redaction: redaction})))
+
+	_, err = svc.GetAccount(context.Background(), 4815162342)
+	require.NoError(t, err)
+	_, err = svc.GetAccount(context.Background(), 2718281828)
+	require.Error(t, err)
+
+	assert.NotContains(t, out.String(), "4815162342")
+	assert.NotContains(t, out.String(), "2718281828")
+	assert.NotContains(t, out.String(), "9876.54")
+	assert.Contains(t, out.String(), redaction.HashAccountID(4815162342))
+	assert.Contains(t, out.String(), "account_id="+redaction.HashAccountID(2718281828))
+}
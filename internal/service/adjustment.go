@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// BalanceAdjustmentTag tags the transfers of manual balance adjustments
+const BalanceAdjustmentTag = "balance-adjustment"
+
+// Bounds on the number of adjustments listed
+const (
+	defaultAdjustmentLimit = 100
+	maxAdjustmentLimit     = 1000
+)
+
+// adjustmentConfig is the store of balance adjustments, the account they are moved from or to,
+// and the audit trail they are recorded in
+type adjustmentConfig struct {
+	repo      repository.AdjustmentRepository
+	accountID int64
+	audit     repository.AuditRepository
+}
+
+// WithBalanceAdjustments lets operators credit and debit accounts outside normal transfers. An
+// adjustment is a transfer between the account and the adjustments account accountID: a credit
+// is paid from it and a debit into it, so adjustments don't create money and the adjustments
+// account must be funded like any other. Every adjustment is recorded in repo and, if audit isn't
+// nil, in the audit log.
+func WithBalanceAdjustments(repo repository.AdjustmentRepository, accountID int64, audit repository.AuditRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.adjustments = &adjustmentConfig{repo: repo, accountID: accountID, audit: audit}
+	}
+}
+
+// adjustmentRepo returns the adjustment store, or an error if adjustments are disabled
+func (s *transactionService) adjustmentRepo() (repository.AdjustmentRepository, error) {
+	if s.adjustments == nil {
+		return nil, fmt.Errorf("%w: balance adjustments are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.adjustments.repo, nil
+}
+
+// AdjustBalance credits or debits an account by a manual adjustment, made by the actor of ctx,
+// which must be identified. The transfer to or from the adjustments account is charged no fees
+// and isn't held for approval; the status, limits and minimum balance of a debited account don't
+// apply, but a debit can't take it below its available balance and overdraft. Both accounts must
+// keep the same currency. The adjustment is posted to the ledger as an adjustment and appears in
+// the account's transaction history.
+func (s *transactionService) AdjustBalance(ctx context.Context, adjustment *models.Adjustment) (*models.Adjustment, error) {
+	if err := auth.Require(ctx, auth.ScopeOperations); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Adjusting account", logger.AccountID(adjustment.AccountID), "direction", adjustment.Direction, "amount", adjustment.Amount, "reason", adjustment.ReasonCode)
+
+	repo, err := s.adjustmentRepo()
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Actor = actor.FromContext(ctx); adjustment.Actor == actor.System {
+		s.log.WarnContext(ctx, "Refusing adjustment of account without an identified actor", logger.AccountID(adjustment.AccountID))
+		return nil, fmt.Errorf("%w: adjustments require the %s header", domainErrors.ErrValidationFailed, actor.Header)
+	}
+	if err := adjustment.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid adjustment", "err", err)
+		return nil, err
+	}
+	if adjustment.AccountID == s.adjustments.accountID {
+		return nil, fmt.Errorf("%w: account %d is the adjustments account", domainErrors.ErrValidationFailed, adjustment.AccountID)
+	}
+
+	var recorded *models.Adjustment
+	err = s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			account, err := s.accountRepo.GetAccountWithTx(ctx, tx, adjustment.AccountID)
+			if err != nil {
+				return err
+			}
+			adjustments, err := s.accountRepo.GetAccountWithTx(ctx, tx, s.adjustments.accountID)
+			if err != nil {
+				return err
+			}
+			if account.Currency != adjustments.Currency {
+				s.log.WarnContext(ctx, "Adjustment crosses currencies with the adjustments account", logger.AccountID(account.AccountID),
+					"currency", account.Currency, "adjustments_account_id", adjustments.AccountID, "adjustments_currency", adjustments.Currency)
+				return fmt.Errorf("%w: account %d keeps %q, the adjustments account %q",
+					domainErrors.ErrValidationFailed, account.AccountID, account.Currency, adjustments.Currency)
+			}
+
+			transaction := &models.Transaction{
+				SourceAccountID:      adjustments.AccountID,
+				DestinationAccountID: account.AccountID,
+				Amount:               adjustment.Amount,
+				Status:               models.TransactionStatusPending,
+				Tags:                 []string{BalanceAdjustmentTag},
+			}
+			if adjustment.Direction == models.EntrySideDebit {
+				transaction.SourceAccountID, transaction.DestinationAccountID = account.AccountID, adjustments.AccountID
+			}
+			if err := transaction.Validate(); err != nil {
+				return err
+			}
+
+			made, err := s.transferWithTx(ctx, tx, transaction, transferOptions{adjustment: true})
+			if err != nil {
+				return err
+			}
+			adjustment.TransactionID = made.ID
+			if recorded, err = repo.CreateAdjustmentWithTx(ctx, tx, adjustment); err != nil {
+				return err
+			}
+			return s.recordAdjustmentWithTx(ctx, tx, recorded)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Recorded adjustment", "direction", recorded.Direction, "adjustment_id", recorded.ID, "amount", recorded.Amount, logger.AccountID(recorded.AccountID), logger.TransactionID(recorded.TransactionID))
+	return recorded, nil
+}
+
+// recordAdjustmentWithTx records adjustment in the audit log, if one is configured
+func (s *transactionService) recordAdjustmentWithTx(ctx context.Context, tx *sql.Tx, adjustment *models.Adjustment) error {
+	if s.adjustments.audit == nil {
+		return nil
+	}
+	details := map[string]string{
+		"direction":   string(adjustment.Direction),
+		"amount":      adjustment.Amount.String(),
+		"reason_code": string(adjustment.ReasonCode),
+	}
+	if adjustment.Note != "" {
+		details["note"] = adjustment.Note
+	}
+	return s.adjustments.audit.RecordWithTx(ctx, tx, &models.AuditEntry{
+		Action:        models.AuditActionBalanceAdjustment,
+		Actor:         adjustment.Actor,
+		AccountID:     adjustment.AccountID,
+		TransactionID: adjustment.TransactionID,
+		Details:       details,
+	})
+}
+
+// ListAdjustments retrieves the adjustments of an account, newest first. limit defaults to 100
+// and is capped at 1000.
+func (s *transactionService) ListAdjustments(ctx context.Context, accountID int64, limit int) ([]*models.Adjustment, error) {
+	if err := auth.Require(ctx, auth.ScopeOperations); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.adjustmentRepo()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultAdjustmentLimit
+	} else if limit > maxAdjustmentLimit {
+		limit = maxAdjustmentLimit
+	}
+	return repo.ListAdjustments(ctx, accountID, limit)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultApprovalLimit = 100
+	maxApprovalLimit     = 1000
+)
+
+// approvalConfig is the store of approval requests and the amount above which transfers need one
+type approvalConfig struct {
+	repo      repository.ApprovalRepository
+	threshold decimal.Decimal
+}
+
+// WithApprovals holds transfers created through CreateTransaction for an amount above threshold
+// until a second caller approves them. A held transfer is recorded in pending_approval without
+// moving funds; approving it makes the transfer, with every check of a transfer made then, and
+// rejecting it marks it rejected. Callers are told apart by their actor.
+func WithApprovals(repo repository.ApprovalRepository, threshold decimal.Decimal) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.approvals = &approvalConfig{repo: repo, threshold: threshold}
+	}
+}
+
+// approvalRepo returns the approval store, or an error if approvals are disabled
+func (s *transactionService) approvalRepo() (repository.ApprovalRepository, error) {
+	if s.approvals == nil {
+		return nil, fmt.Errorf("%w: transfer approvals are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.approvals.repo, nil
+}
+
+// requiresApproval checks if a transfer is above the approval threshold
+func (s *transactionService) requiresApproval(transaction *models.Transaction) bool {
+	return s.approvals != nil && transaction.Amount.GreaterThan(s.approvals.threshold)
+}
+
+// holdForApprovalWithTx records a validated transfer in pending_approval within tx, together with
+// its approval request naming the actor of ctx as the requester. No funds move; the accounts are
+// only checked to exist, every other check runs when the transfer is approved.
+func (s *transactionService) holdForApprovalWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Transaction, error) {
+	requestedBy := actor.FromContext(ctx)
+	if len(requestedBy) > models.MaxAuditActorLength {
+		return nil, fmt.Errorf("%w: actor must be at most %d characters", domainErrors.ErrValidationFailed, models.MaxAuditActorLength)
+	}
+	if _, err := s.accountRepo.GetAccountWithTx(ctx, tx, transaction.SourceAccountID); err != nil {
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			return nil, domainErrors.NewSourceAccountNotFoundError(transaction.SourceAccountID)
+		}
+		return nil, err
+	}
+	if _, err := s.accountRepo.GetAccountWithTx(ctx, tx, transaction.DestinationAccountID); err != nil {
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			return nil, domainErrors.NewDestinationAccountNotFoundError(transaction.DestinationAccountID)
+		}
+		return nil, err
+	}
+
+	held := *transaction
+	held.Status = models.TransactionStatusPendingApproval
+	held.BusinessDate = s.calendar.Date(s.now())
+	created, err := s.transactionRepo.CreateTransactionWithTx(ctx, tx, &held)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to record transfer pending approval", "err", err)
+		return nil, err
+	}
+	if err := s.recordStatusChangeWithTx(ctx, tx, created.ID, transaction.Status, created.Status); err != nil {
+		return nil, err
+	}
+	if _, err := s.approvals.repo.CreateApprovalWithTx(ctx, tx, &models.TransferApproval{
+		TransactionID: created.ID,
+		RequestedBy:   requestedBy,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Transfer held for approval", logger.TransactionID(created.ID), "amount", created.Amount, "source_account_id", created.SourceAccountID, "requested_by", requestedBy)
+	return created, nil
+}
+
+// reviewWithTx locks the approval request of a transaction and checks the actor of ctx may
+// review it. It returns the reviewer and the transaction held for approval.
+func (s *transactionService) reviewWithTx(ctx context.Context, tx *sql.Tx, repo repository.ApprovalRepository, transactionID int64, note string) (string, *models.Transaction, error) {
+	reviewer := actor.FromContext(ctx)
+	approval, err := repo.GetApprovalForUpdateWithTx(ctx, tx, transactionID)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := approval.CheckReview(reviewer, note); err != nil {
+		s.log.WarnContext(ctx, "Review of transfer refused", logger.TransactionID(transactionID), "reviewer", reviewer, "err", err)
+		return "", nil, err
+	}
+	held, err := s.transactionRepo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		return "", nil, err
+	}
+	return reviewer, held, nil
+}
+
+// ApproveTransfer approves a transfer held for approval on behalf of the actor of ctx, who must
+// not be its requester, and makes it. The transfer passes every check of a transfer made now; if
+// one fails, nothing changes and the transfer stays pending approval.
+func (s *transactionService) ApproveTransfer(ctx context.Context, transactionID int64, note string) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Approving transfer", logger.TransactionID(transactionID))
+
+	repo, err := s.approvalRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var made *models.Transaction
+	err = s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			reviewer, held, err := s.reviewWithTx(ctx, tx, repo, transactionID, note)
+			if err != nil {
+				return err
+			}
+			if made, err = s.transferWithTx(ctx, tx, held, transferOptions{chargeFees: true, approved: true}); err != nil {
+				return err
+			}
+			_, err = repo.ResolveApprovalWithTx(ctx, tx, transactionID, models.ApprovalStatusApproved, reviewer, note)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Transfer approved and made", logger.TransactionID(transactionID))
+	return made, nil
+}
+
+// RejectTransfer refuses a transfer held for approval on behalf of the actor of ctx, who must not
+// be its requester. The transfer is marked rejected and no funds move.
+func (s *transactionService) RejectTransfer(ctx context.Context, transactionID int64, note string) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Rejecting transfer", logger.TransactionID(transactionID))
+
+	repo, err := s.approvalRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected *models.Transaction
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		reviewer, held, err := s.reviewWithTx(ctx, tx, repo, transactionID, note)
+		if err != nil {
+			return err
+		}
+		outcome := *held
+		outcome.Status = models.TransactionStatusRejected
+		if rejected, err = s.transactionRepo.ResolvePendingApprovalWithTx(ctx, tx, &outcome); err != nil {
+			return err
+		}
+		if err := s.recordStatusChangeWithTx(ctx, tx, transactionID, held.Status, rejected.Status); err != nil {
+			return err
+		}
+		_, err = repo.ResolveApprovalWithTx(ctx, tx, transactionID, models.ApprovalStatusRejected, reviewer, note)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Transfer rejected", logger.TransactionID(transactionID))
+	return rejected, nil
+}
+
+// GetTransferApproval retrieves the approval request of a transaction
+func (s *transactionService) GetTransferApproval(ctx context.Context, transactionID int64) (*models.TransferApproval, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.approvalRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetApproval(ctx, transactionID)
+}
+
+// ListTransferApprovals lists up to limit approval requests with the given status, or of any
+// status if it is empty, oldest first
+func (s *transactionService) ListTransferApprovals(ctx context.Context, status models.ApprovalStatus, limit int) ([]*models.TransferApproval, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.approvalRepo()
+	if err != nil {
+		return nil, err
+	}
+	if status != "" && !status.IsValid() {
+		s.log.WarnContext(ctx, "Invalid approval status", "status", status)
+		return nil, fmt.Errorf("%w: invalid status %q", domainErrors.ErrValidationFailed, status)
+	}
+	if limit <= 0 {
+		limit = defaultApprovalLimit
+	} else if limit > maxApprovalLimit {
+		limit = maxApprovalLimit
+	}
+	return repo.ListApprovals(ctx, status, limit)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferApprovals(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(1000)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	transactionRepo := repository.NewTransactionRepository(db)
+	svc := NewTransactionService(transactionRepo, accountRepo, db,
+		WithApprovals(repository.NewApprovalRepository(db), decimal.NewFromInt(100)))
+
+	maker := actor.WithActor(ctx, "maker")
+	checker := actor.WithActor(ctx, "checker")
+	balance := func(id int64) decimal.Decimal {
+		account, err := accountRepo.GetAccount(ctx, id)
+		require.NoError(t, err)
+		return account.Balance
+	}
+	status := func(id int64) models.TransactionStatus {
+		transaction, err := transactionRepo.GetTransactionByID(ctx, id)
+		require.NoError(t, err)
+		return transaction.Status
+	}
+
+	// A transfer within the threshold is made straight away
+	small, err := svc.CreateTransaction(maker, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100)})
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusComplete, status(small.ID))
+
+	// A larger one is held without moving funds
+	held, err := svc.CreateTransaction(maker, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(300)})
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusPendingApproval, status(held.ID))
+	assert.True(t, decimal.NewFromInt(900).Equal(balance(1)), "expected 900, got %s", balance(1))
+
+	approval, err := svc.GetTransferApproval(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusPending, approval.Status)
+	assert.Equal(t, "maker", approval.RequestedBy)
+	pending, err := svc.ListTransferApprovals(ctx, models.ApprovalStatusPending, 0)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// The maker can't approve their own transfer
+	_, err = svc.ApproveTransfer(maker, held.ID, "")
+	assert.ErrorIs(t, err, errors.ErrSelfApproval)
+
+	made, err := svc.ApproveTransfer(checker, held.ID, "checked the invoice")
+	require.NoError(t, err)
+	assert.Equal(t, held.ID, made.ID)
+	assert.Equal(t, models.TransactionStatusComplete, made.Status)
+	assert.True(t, decimal.NewFromInt(600).Equal(balance(1)), "expected 600, got %s", balance(1))
+	assert.True(t, decimal.NewFromInt(400).Equal(balance(2)), "expected 400, got %s", balance(2))
+
+	approval, err = svc.GetTransferApproval(ctx, held.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusApproved, approval.Status)
+	assert.Equal(t, "checker", approval.ReviewedBy)
+	assert.Equal(t, "checked the invoice", approval.ReviewNote)
+
+	// A resolved request can't be reviewed again
+	_, err = svc.RejectTransfer(checker, held.ID, "")
+	assert.ErrorIs(t, err, errors.ErrApprovalResolved)
+
+	// A rejected transfer never moves funds
+	held, err = svc.CreateTransaction(maker, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(200)})
+	require.NoError(t, err)
+	rejected, err := svc.RejectTransfer(checker, held.ID, "not expected")
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusRejected, rejected.Status)
+	assert.True(t, decimal.NewFromInt(600).Equal(balance(1)), "expected 600, got %s", balance(1))
+
+	_, err = svc.GetTransferApproval(ctx, small.ID)
+	assert.ErrorIs(t, err, errors.ErrApprovalNotFound)
+}
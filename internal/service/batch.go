@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+)
+
+// CreateTransferBatch executes a batch of transfers under a batch-level idempotency key.
+//
+// Each item is its own database transaction, recorded under a batch item key derived from the
+// batch key and the item key (models.Batch.ItemKey). Resubmitting a batch (e.g. after a timeout)
+// replays the items that were already made and only executes the rest, so a retried batch
+// resumes where it left off. Reusing a batch key for different items is rejected with
+// ErrIdempotencyConflict. Batch keys belong to the caller (auth.Owner), so callers picking the
+// same key don't share a batch.
+//
+// Items wait in the bulk priority lane unless the request asked for another class.
+//
+// An item whose destination is frozen or closed is credited to the suspense account, when one is
+// configured, rather than failed.
+func (s *transactionService) CreateTransferBatch(ctx context.Context, batchKey string, items []models.BatchItem) (*models.BatchResult, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Processing transfer batch items", "batch_key", batchKey, "count", len(items))
+	ctx = priority.WithDefault(ctx, priority.Bulk)
+
+	batch, err := models.NewBatch(batchKey, items)
+	if err != nil {
+		s.log.WarnContext(ctx, "Invalid transfer batch", "batch_key", batchKey, "err", err)
+		return nil, err
+	}
+	batch.Owner = auth.Owner(ctx)
+
+	registered, err := s.transactionRepo.RegisterBatch(ctx, batch)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to register transfer batch", "batch_key", batchKey, "err", err)
+		return nil, err
+	}
+	if registered.Fingerprint != batch.Fingerprint {
+		s.log.WarnContext(ctx, "Transfer batch key reused with different items", "batch_key", batchKey)
+		return nil, fmt.Errorf("%w: batch %s", domainErrors.ErrIdempotencyConflict, batchKey)
+	}
+
+	result := &models.BatchResult{BatchKey: batchKey, Items: make([]*models.BatchItemResult, 0, len(items))}
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			// Items not reached are made by the next submission of the batch
+			return nil, err
+		}
+		result.Items = append(result.Items, s.transferBatchItem(ctx, batch, item))
+	}
+
+	counts := result.Counts()
+	s.log.InfoContext(ctx, "Transfer batch done", "batch_key", batchKey, "created", counts[models.BatchItemCreated], "replayed", counts[models.BatchItemReplayed], "failed", counts[models.BatchItemFailed])
+	return result, nil
+}
+
+// transferBatchItem executes one batch item, or replays it if it was made by an earlier submission
+func (s *transactionService) transferBatchItem(ctx context.Context, batch *models.Batch, item models.BatchItem) *models.BatchItemResult {
+	key := batch.ItemKey(item.Key)
+	transaction := &models.Transaction{
+		SourceAccountID:      item.SourceAccountID,
+		DestinationAccountID: item.DestinationAccountID,
+		Amount:               item.Amount,
+		Status:               models.TransactionStatusPending,
+		BatchItemKey:         key,
+	}
+	if err := transaction.Validate(); err != nil {
+		return &models.BatchItemResult{Key: item.Key, Status: models.BatchItemFailed, Err: err}
+	}
+
+	status := models.BatchItemCreated
+	var recorded *models.Transaction
+	err := s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			existing, err := s.transactionRepo.GetTransactionByBatchItemKeyWithTx(ctx, tx, key)
+			if err == nil {
+				status, recorded = models.BatchItemReplayed, existing
+				return nil
+			}
+			if !errors.Is(err, domainErrors.ErrTransactionNotFound) {
+				return err
+			}
+			recorded, err = s.transferWithTx(ctx, tx, transaction, transferOptions{allowSuspense: true})
+			return err
+		})
+	})
+
+	// A concurrent submission of the same batch recorded the item first
+	if errors.Is(err, domainErrors.ErrDuplicateIdempotencyKey) {
+		s.log.InfoContext(ctx, "Batch item was recorded concurrently, replaying", "batch_key", batch.Key, "item_key", item.Key)
+		err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+			var err error
+			status = models.BatchItemReplayed
+			recorded, err = s.transactionRepo.GetTransactionByBatchItemKeyWithTx(ctx, tx, key)
+			return err
+		})
+	}
+
+	if err != nil {
+		s.log.WarnContext(ctx, "Batch item failed", "batch_key", batch.Key, "item_key", item.Key, "err", err)
+		return &models.BatchItemResult{Key: item.Key, Status: models.BatchItemFailed, Err: err}
+	}
+	return &models.BatchItemResult{Key: item.Key, Status: status, Transaction: recorded}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// maxReportDays bounds the number of business dates a report may span
+const maxReportDays = 366
+
+// WithBusinessCalendar stamps transactions with business dates from calendar instead of UTC calendar dates
+func WithBusinessCalendar(calendar *businessday.Calendar) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.calendar = calendar
+	}
+}
+
+// ListTransactionsByBusinessDate retrieves the transactions booked on a business date, oldest first.
+// limit defaults to 100 and is capped at 1000.
+func (s *transactionService) ListTransactionsByBusinessDate(ctx context.Context, businessDate string, limit int) ([]*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeReportsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Listing transactions booked on business date", "business_date", businessDate, "limit", limit)
+
+	if _, err := businessday.ParseDate(businessDate); err != nil {
+		s.log.WarnContext(ctx, "Invalid business date", "business_date", businessDate)
+		return nil, fmt.Errorf("%w: invalid business date %q, expected YYYY-MM-DD", domainErrors.ErrValidationFailed, businessDate)
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	} else if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	transactions, err := s.transactionRepo.GetTransactionsByBusinessDate(ctx, businessDate, limit)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to list transactions for business date", "business_date", businessDate, "err", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetBusinessDayReport returns the completed transaction count and volume of each business date
+// in [from, to], bucketed by business date rather than by recording time
+func (s *transactionService) GetBusinessDayReport(ctx context.Context, from, to string) ([]*models.BusinessDaySummary, error) {
+	if err := auth.Require(ctx, auth.ScopeReportsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Building business day report", "from", from, "to", to)
+
+	fromDate, err := businessday.ParseDate(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid from %q, expected YYYY-MM-DD", domainErrors.ErrValidationFailed, from)
+	}
+	toDate, err := businessday.ParseDate(to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid to %q, expected YYYY-MM-DD", domainErrors.ErrValidationFailed, to)
+	}
+	if toDate.Before(fromDate) || toDate.Sub(fromDate).Hours()/24 >= maxReportDays {
+		s.log.WarnContext(ctx, "Invalid business day report range", "from", from, "to", to)
+		return nil, fmt.Errorf("%w: to must not be before from and the range may span at most %d days", domainErrors.ErrValidationFailed, maxReportDays)
+	}
+
+	summaries, err := s.transactionRepo.GetBusinessDaySummaries(ctx, from, to)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to build business day report", "err", err)
+		return nil, err
+	}
+	return summaries, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stageAccounts runs hooks as a transfer reads and updates accounts
+type stageAccounts struct {
+	repository.AccountRepository
+	beforeGet, afterUpdate func()
+}
+
+func (r *stageAccounts) GetAccountWithTx(ctx context.Context, tx *sql.Tx, accountID int64) (*models.Account, error) {
+	if r.beforeGet != nil {
+		r.beforeGet()
+	}
+	return r.AccountRepository.GetAccountWithTx(ctx, tx, accountID)
+}
+
+func (r *stageAccounts) UpdateBalanceWithTx(ctx context.Context, tx *sql.Tx, accountID int64, newBalance decimal.Decimal) error {
+	err := r.AccountRepository.UpdateBalanceWithTx(ctx, tx, accountID, newBalance)
+	if r.afterUpdate != nil {
+		r.afterUpdate()
+	}
+	return err
+}
+
+// stageIdempotency runs a hook once the idempotency record, the last write of a transfer, is stored
+type stageIdempotency struct {
+	repository.IdempotencyRepository
+	afterCreate func()
+}
+
+func (r *stageIdempotency) CreateIdempotencyRecordWithTx(ctx context.Context, tx *sql.Tx, record *models.IdempotencyRecord, now time.Time) error {
+	err := r.IdempotencyRepository.CreateIdempotencyRecordWithTx(ctx, tx, record, now)
+	if r.afterCreate != nil {
+		r.afterCreate()
+	}
+	return err
+}
+
+func TestCreateTransaction_ContextCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		// stage cancels the request at one point of the transfer
+		stage     func(cancel func(), accounts *stageAccounts, keys *stageIdempotency)
+		committed bool
+	}{
+		{
+			name:  "before the database transaction starts",
+			stage: func(cancel func(), _ *stageAccounts, _ *stageIdempotency) { cancel() },
+		},
+		{
+			name: "while reading the source account",
+			stage: func(cancel func(), accounts *stageAccounts, _ *stageIdempotency) {
+				accounts.beforeGet = cancel
+			},
+		},
+		{
+			name: "after updating the source balance",
+			stage: func(cancel func(), accounts *stageAccounts, _ *stageIdempotency) {
+				accounts.afterUpdate = cancel
+			},
+		},
+		{
+			name: "after the last write, before commit",
+			stage: func(cancel func(), _ *stageAccounts, keys *stageIdempotency) {
+				keys.afterCreate = cancel
+			},
+			committed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := testutil.NewTestDB(t)
+			testutil.SetupTestDB(t, db)
+			defer testutil.CleanupTestDB(t, db)
+
+			accountRepo := repository.NewAccountRepository(db)
+			require.NoError(t, accountRepo.CreateAccount(context.Background(), 1, decimal.NewFromInt(100)))
+			require.NoError(t, accountRepo.CreateAccount(context.Background(), 2, decimal.Zero))
+
+			accounts := &stageAccounts{AccountRepository: accountRepo}
+			keys := &stageIdempotency{IdempotencyRepository: repository.NewIdempotencyRepository(db)}
+			svc := NewTransactionService(repository.NewTransactionRepository(db), accounts, db, WithIdempotencyKeys(keys))
+
+			ctx, cancel := context.WithCancel(idempotency.WithKey(context.Background(), "cancel-1"))
+			defer cancel()
+			tt.stage(cancel, accounts, keys)
+			req := &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(40)}
+
+			resp, err := svc.CreateTransaction(ctx, req)
+			accounts.beforeGet, accounts.afterUpdate, keys.afterCreate = nil, nil, nil
+
+			// The error reports the outcome: the transfer either committed in full or not at all
+			source, getErr := accountRepo.GetAccount(context.Background(), 1)
+			require.NoError(t, getErr)
+			found, lookupErr := svc.GetTransactionByIdempotencyKey(context.Background(), "cancel-1")
+			if tt.committed {
+				require.NoError(t, err)
+				assert.True(t, decimal.NewFromInt(60).Equal(source.Balance), "expected 60, got %s", source.Balance)
+				require.NoError(t, lookupErr)
+				assert.Equal(t, resp.ID, found.ID)
+			} else {
+				assert.ErrorIs(t, err, context.Canceled)
+				assert.True(t, decimal.NewFromInt(100).Equal(source.Balance), "expected 100, got %s", source.Balance)
+				assert.ErrorIs(t, lookupErr, domainErrors.ErrTransactionNotFound)
+			}
+
+			// A retry with the same key makes the transfer exactly once
+			retried, err := svc.CreateTransaction(idempotency.WithKey(context.Background(), "cancel-1"), req)
+			require.NoError(t, err)
+			if tt.committed {
+				assert.Equal(t, resp.ID, retried.ID)
+			}
+			source, err = accountRepo.GetAccount(context.Background(), 1)
+			require.NoError(t, err)
+			assert.True(t, decimal.NewFromInt(60).Equal(source.Balance), "expected 60, got %s", source.Balance)
+		})
+	}
+}
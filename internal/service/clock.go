@@ -0,0 +1,21 @@
+package service
+
+import (
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+)
+
+// WithClock makes the service decide business dates, value date checks and pre-authorization
+// expiry by c instead of the wall clock, e.g. a clock.Virtual in a sandbox. Recording timestamps
+// such as created_at are still taken from the database clock.
+func WithClock(c clock.Clock) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.clock = c
+	}
+}
+
+// now returns the current time of the service's clock
+func (s *transactionService) now() time.Time {
+	return s.clock.Now()
+}
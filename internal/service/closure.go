@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// ClosureSweepTag tags the transfers sweeping the residual balance of force-closed accounts
+const ClosureSweepTag = "account-closure"
+
+// WithClosureSweepAccount sets the account that receives the residual balance of force-closed
+// accounts when the request doesn't designate one
+func WithClosureSweepAccount(accountID int64) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.closureSweepAccountID = accountID
+	}
+}
+
+// CloseAccount closes an account for good; its row and history are kept and it can no longer
+// send or receive transfers. Without force the account must hold no funds. With force a residual
+// balance is first swept to sweepTo, or to the closure sweep account if sweepTo is 0, in the same
+// database transaction; the sweep ignores the status, tenant limit and minimum balance of the
+// account. Funds reserved by pre-authorizations are never swept.
+func (s *transactionService) CloseAccount(ctx context.Context, accountID int64, force bool, sweepTo int64) (*models.AccountClosure, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Closing account", logger.AccountID(accountID), "force", force, "sweep_account_id", sweepTo)
+
+	if s.isSuspenseAccount(accountID) || (s.fees != nil && s.fees.accountID == accountID) {
+		s.log.WarnContext(ctx, "Refusing to close internal account", logger.AccountID(accountID))
+		return nil, fmt.Errorf("%w: account %d is an internal account and can't be closed", domainErrors.ErrAccountStatusConflict, accountID)
+	}
+	if force && sweepTo == 0 {
+		sweepTo = s.closureSweepAccountID
+	}
+	if force && sweepTo == 0 {
+		return nil, fmt.Errorf("%w: sweep_to is required to force-close without a closure sweep account", domainErrors.ErrValidationFailed)
+	}
+	if force && sweepTo == accountID {
+		return nil, fmt.Errorf("%w: an account can't be swept to itself", domainErrors.ErrValidationFailed)
+	}
+
+	closure := &models.AccountClosure{}
+	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
+		account, err := s.accountRepo.GetAccountWithTx(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+		if account.IsSystemFloat() {
+			s.log.WarnContext(ctx, "Refusing to close system float account", logger.AccountID(accountID))
+			return domainErrors.WithAccount(domainErrors.ErrSystemFloatAccount, accountID)
+		}
+		if force && account.Balance.IsPositive() && account.Status != models.AccountStatusClosed {
+			if !account.Reserved.IsZero() {
+				s.log.WarnContext(ctx, "Account has a reserved balance, not force-closing", logger.AccountID(accountID), "reserved_balance", account.Reserved)
+				return fmt.Errorf("%w: account %d has %s reserved by pre-authorizations", domainErrors.ErrAccountStatusConflict, accountID, account.Reserved.String())
+			}
+			sweep := &models.Transaction{
+				SourceAccountID:      accountID,
+				DestinationAccountID: sweepTo,
+				Amount:               account.Balance,
+				Status:               models.TransactionStatusPending,
+				Tags:                 []string{ClosureSweepTag},
+			}
+			if err := sweep.Validate(); err != nil {
+				return err
+			}
+			if closure.Sweep, err = s.transferWithTx(ctx, tx, sweep, transferOptions{closing: true}); err != nil {
+				s.log.WarnContext(ctx, "Failed to sweep account", logger.AccountID(accountID), "sweep_account_id", sweepTo, "err", err)
+				return err
+			}
+		}
+		return s.accountRepo.SetStatusWithTx(ctx, tx, accountID, models.AccountStatusClosed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if closure.Account, err = s.accountRepo.GetAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if closure.Sweep != nil {
+		s.log.InfoContext(ctx, "Closed account and swept its balance", logger.AccountID(accountID), "amount", closure.Sweep.Amount, "sweep_account_id", sweepTo, logger.TransactionID(closure.Sweep.ID))
+	} else {
+		s.log.InfoContext(ctx, "Closed account", logger.AccountID(accountID))
+	}
+	return closure, nil
+}
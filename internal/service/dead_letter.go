@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// Bounds on the number of dead letters listed
+const (
+	defaultDeadLetterLimit = 100
+	maxDeadLetterLimit     = 1000
+)
+
+// WithDeadLetters records the transfer jobs and scheduled transfers that failed in repo, in the
+// transaction that failed them
+func WithDeadLetters(repo repository.DeadLetterRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.deadLetters = repo
+	}
+}
+
+// deadLetterWithTx records work given up on within tx, keeping work as its payload. It does
+// nothing without a dead letter store.
+func (s *transactionService) deadLetterWithTx(ctx context.Context, tx *sql.Tx, kind models.DeadLetterKind, referenceID int64, attempts int, lastError string, work interface{}) error {
+	if s.deadLetters == nil {
+		return nil
+	}
+	payload, err := json.Marshal(work)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter payload: %w", err)
+	}
+	_, err = s.deadLetters.CreateDeadLetterWithTx(ctx, tx, &models.DeadLetter{
+		Kind:        kind,
+		ReferenceID: referenceID,
+		Attempts:    attempts,
+		LastError:   lastError,
+		Payload:     payload,
+	})
+	return err
+}
+
+// ListDeadLetters lists up to limit dead letters of the given kind, or of any kind if it is
+// empty, newest first. limit defaults to 100 and is capped at 1000.
+func (s *transactionService) ListDeadLetters(ctx context.Context, kind models.DeadLetterKind, limit int) ([]*models.DeadLetter, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if s.deadLetters == nil {
+		return nil, fmt.Errorf("%w: dead letters are not enabled", domainErrors.ErrValidationFailed)
+	}
+	if kind != "" && !kind.IsValid() {
+		s.log.WarnContext(ctx, "Invalid dead letter kind", "kind", kind)
+		return nil, fmt.Errorf("%w: invalid kind %q", domainErrors.ErrValidationFailed, kind)
+	}
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	} else if limit > maxDeadLetterLimit {
+		limit = maxDeadLetterLimit
+	}
+	return s.deadLetters.ListDeadLetters(ctx, kind, limit)
+}
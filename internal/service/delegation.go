@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+const (
+	defaultDelegationLimit = 100
+	maxDelegationLimit     = 1000
+)
+
+// delegationConfig is the store of delegations and the audit trail of the transfers made under them
+type delegationConfig struct {
+	repo  repository.DelegationRepository
+	audit repository.AuditRepository
+}
+
+// WithDelegations lets a caller transfer on behalf of an account owner, named by the
+// X-On-Behalf-Of header, when an active delegation from the owner to the caller's actor covers
+// the transfer. Every transfer made under a delegation is recorded in audit. Without it, requests
+// on behalf of someone else are rejected.
+func WithDelegations(repo repository.DelegationRepository, audit repository.AuditRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.delegations = &delegationConfig{repo: repo, audit: audit}
+	}
+}
+
+// delegationRepo returns the delegation store, or an error if delegations are disabled
+func (s *transactionService) delegationRepo() (repository.DelegationRepository, error) {
+	if s.delegations == nil {
+		return nil, fmt.Errorf("%w: delegations are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.delegations.repo, nil
+}
+
+// CreateDelegation grants delegation, recording the actor of ctx as its creator
+func (s *transactionService) CreateDelegation(ctx context.Context, delegation *models.Delegation) (*models.Delegation, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.delegationRepo()
+	if err != nil {
+		return nil, err
+	}
+	if err := delegation.Validate(s.now()); err != nil {
+		s.log.WarnContext(ctx, "Delegation validation failed", "err", err)
+		return nil, err
+	}
+	delegation.CreatedBy = actor.FromContext(ctx)
+	if len(delegation.CreatedBy) > models.MaxAuditActorLength {
+		return nil, fmt.Errorf("%w: actor must be at most %d characters", domainErrors.ErrValidationFailed, models.MaxAuditActorLength)
+	}
+
+	created, err := repo.CreateDelegation(ctx, delegation)
+	if err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "Delegation granted", "delegation_id", created.ID, "created_by", created.CreatedBy, "grantor", created.Grantor, "grantee", created.Grantee, "expires_at", created.ExpiresAt)
+	return created, nil
+}
+
+// GetDelegation retrieves a delegation
+func (s *transactionService) GetDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.delegationRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetDelegation(ctx, delegationID)
+}
+
+// ListDelegations lists up to limit delegations, newest first, narrowed to a grantor and grantee
+// if they aren't empty. limit defaults to 100 and is capped at 1000.
+func (s *transactionService) ListDelegations(ctx context.Context, grantor, grantee string, limit int) ([]*models.Delegation, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.delegationRepo()
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDelegationLimit
+	} else if limit > maxDelegationLimit {
+		limit = maxDelegationLimit
+	}
+	return repo.ListDelegations(ctx, grantor, grantee, limit)
+}
+
+// RevokeDelegation revokes a delegation, so no further transfers can be made under it
+func (s *transactionService) RevokeDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.delegationRepo()
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := repo.RevokeDelegation(ctx, delegationID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "Revoked delegation", "delegation_id", revoked.ID, "grantor", revoked.Grantor, "grantee", revoked.Grantee, "actor", actor.FromContext(ctx))
+	return revoked, nil
+}
+
+// authorizeDelegationWithTx returns the delegation a transfer made on behalf of someone else is
+// made under, or nil if ctx acts for its actor alone. The transfer is denied unless an active
+// delegation from the principal of ctx to its actor covers its source and amount.
+func (s *transactionService) authorizeDelegationWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) (*models.Delegation, error) {
+	grantor := actor.OnBehalfOf(ctx)
+	if grantor == "" {
+		return nil, nil
+	}
+	repo, err := s.delegationRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.accountRepo.GetAccountWithTx(ctx, tx, transaction.SourceAccountID)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			return nil, domainErrors.NewSourceAccountNotFoundError(transaction.SourceAccountID)
+		}
+		return nil, err
+	}
+
+	grantee := actor.FromContext(ctx)
+	delegations, err := repo.FindActiveDelegationsWithTx(ctx, tx, grantor, grantee, s.now())
+	if err != nil {
+		return nil, err
+	}
+	for _, delegation := range delegations {
+		if delegation.Permits(source, transaction.Amount) {
+			return delegation, nil
+		}
+	}
+	s.log.WarnContext(ctx, "No delegation covers the transfer", "grantor", grantor, "grantee", grantee, "amount", transaction.Amount, "source_account_id", transaction.SourceAccountID)
+	return nil, fmt.Errorf("%w: %s can't transfer %s from account %d on behalf of %s",
+		domainErrors.ErrDelegationDenied, grantee, transaction.Amount.String(), transaction.SourceAccountID, grantor)
+}
+
+// recordDelegatedTransferWithTx records in audit that created was made under delegation. It
+// does nothing for a transfer made without one.
+func (s *transactionService) recordDelegatedTransferWithTx(ctx context.Context, tx *sql.Tx, delegation *models.Delegation, created *models.Transaction) error {
+	if delegation == nil || s.delegations.audit == nil {
+		return nil
+	}
+	return s.delegations.audit.RecordWithTx(ctx, tx, &models.AuditEntry{
+		Action:        models.AuditActionDelegatedTransfer,
+		Actor:         delegation.Grantee,
+		AccountID:     created.SourceAccountID,
+		TransactionID: created.ID,
+		Details: map[string]string{
+			"grantor":       delegation.Grantor,
+			"delegation_id": strconv.FormatInt(delegation.ID, 10),
+			"amount":        created.Amount.String(),
+		},
+	})
+}
+
+// rejectDeferredOnBehalfOf rejects work on behalf of someone else that would run after the
+// request, since its delegation is only checked while the caller is present
+func rejectDeferredOnBehalfOf(ctx context.Context) error {
+	if actor.OnBehalfOf(ctx) != "" {
+		return fmt.Errorf("%w: transfers on behalf of %s must be made immediately", domainErrors.ErrValidationFailed, actor.OnBehalfOf(ctx))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegatedTransfers(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(1000)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	require.NoError(t, accountRepo.SetOwnerRef(ctx, 1, "cust-1"))
+	audit := repository.NewAuditRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	svc := NewTransactionService(transactionRepo, accountRepo, db,
+		WithDelegations(repository.NewDelegationRepository(db), audit))
+
+	assistant := actor.WithOnBehalfOf(actor.WithActor(ctx, "assistant"), "cust-1")
+	transfer := func(ctx context.Context, amount int64) (*dto.TransactionResponse, error) {
+		return svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(amount)})
+	}
+
+	// Without a delegation the assistant can't act for the owner
+	_, err := transfer(assistant, 10)
+	assert.ErrorIs(t, err, errors.ErrDelegationDenied)
+
+	maxAmount := decimal.NewFromInt(100)
+	delegation, err := svc.CreateDelegation(actor.WithActor(ctx, "ops"), &models.Delegation{
+		Grantor: "cust-1", Grantee: "assistant", SourceAccountID: 1, MaxAmount: &maxAmount,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ops", delegation.CreatedBy)
+
+	made, err := transfer(assistant, 100)
+	require.NoError(t, err)
+	recorded, err := transactionRepo.GetTransactionByID(ctx, made.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionStatusComplete, recorded.Status)
+
+	// Above the cap, or for an owner that doesn't own the source, the transfer is denied
+	_, err = transfer(assistant, 101)
+	assert.ErrorIs(t, err, errors.ErrDelegationDenied)
+	_, err = transfer(actor.WithOnBehalfOf(actor.WithActor(ctx, "assistant"), "cust-2"), 10)
+	assert.ErrorIs(t, err, errors.ErrDelegationDenied)
+
+	entries, err := audit.ListEntries(ctx, models.AuditFilter{Action: models.AuditActionDelegatedTransfer})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "assistant", entries[0].Actor)
+	assert.Equal(t, made.ID, entries[0].TransactionID)
+	assert.Equal(t, "cust-1", entries[0].Details["grantor"])
+
+	// Work that would run after the request can't be made on behalf of someone else
+	_, err = svc.ScheduleTransfer(assistant, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)}, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	_, err = svc.RevokeDelegation(ctx, delegation.ID)
+	require.NoError(t, err)
+	_, err = transfer(assistant, 10)
+	assert.ErrorIs(t, err, errors.ErrDelegationDenied)
+}
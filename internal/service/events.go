@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/budget"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// WithEventPublisher publishes events about transfers and about pending work that changed state
+// outside a client request, such as pre-authorizations expired by the sweeper, so initiators can
+// react to them
+func WithEventPublisher(publisher EventPublisher) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.events = publisher
+	}
+}
+
+// WithOutbox records events in the outbox instead of publishing them, to be published by the
+// outbox relay. Events about committed changes are recorded within the database transaction that
+// made the change, so none is lost if the process stops after the commit and none is recorded for
+// a change that rolled back. It takes precedence over WithEventPublisher.
+func WithOutbox(outbox repository.OutboxRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.outbox = outbox
+	}
+}
+
+// pendingEvent is an event raised within a database transaction, published once it ends
+type pendingEvent struct {
+	event   string
+	payload interface{}
+	// always publishes the event when the transaction rolls back too
+	always bool
+}
+
+// publishEvent publishes an event after the change it describes ended, or records it in the
+// outbox outside any database transaction. Publishing is best effort: a failure is logged and
+// doesn't undo the change. Without an outbox, publishing is skipped when the request's latency
+// budget is nearly exhausted, and stops at its deadline.
+func (s *transactionService) publishEvent(ctx context.Context, event string, payload interface{}) {
+	if s.outbox != nil {
+		if _, err := s.createOutboxMessage(ctx, nil, event, payload); err != nil {
+			s.log.ErrorContext(ctx, "Failed to record event in the outbox", "event", event, "err", err)
+		}
+		return
+	}
+	if s.events == nil || !budget.Allows(ctx, budget.WorkEventPublish) {
+		return
+	}
+	ctx, cancel := budget.Bound(ctx)
+	defer cancel()
+	if err := s.events.Publish(ctx, event, payload); err != nil {
+		s.log.ErrorContext(ctx, "Failed to publish event", "event", event, "err", err)
+	}
+}
+
+// createOutboxMessage records an event in the outbox, within tx unless it is nil
+func (s *transactionService) createOutboxMessage(ctx context.Context, tx *sql.Tx, event string, payload interface{}) (*models.OutboxMessage, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+	message := &models.OutboxMessage{Event: event, Payload: encoded}
+	if tx == nil {
+		return s.outbox.CreateMessage(ctx, message)
+	}
+	return s.outbox.CreateMessageWithTx(ctx, tx, message)
+}
+
+// raiseEventWithTx raises an event about a change made within tx, published only if tx commits.
+// With an outbox the event is recorded in it within tx; an error then fails the change.
+func (s *transactionService) raiseEventWithTx(ctx context.Context, tx *sql.Tx, event string, payload interface{}) error {
+	if s.outbox != nil {
+		_, err := s.createOutboxMessage(ctx, tx, event, payload)
+		return err
+	}
+	s.queueEventWithTx(tx, event, payload, false)
+	return nil
+}
+
+// queueEventWithTx raises an event within tx. withTransaction publishes it once tx commits, or
+// even if tx rolls back when always is set; it is dropped otherwise.
+func (s *transactionService) queueEventWithTx(tx *sql.Tx, event string, payload interface{}, always bool) {
+	if s.events == nil && s.outbox == nil {
+		return
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	if s.pendingEvents == nil {
+		s.pendingEvents = make(map[*sql.Tx][]pendingEvent)
+	}
+	s.pendingEvents[tx] = append(s.pendingEvents[tx], pendingEvent{event: event, payload: payload, always: always})
+}
+
+// takeEventsWithTx removes and returns the events raised within tx
+func (s *transactionService) takeEventsWithTx(tx *sql.Tx) []pendingEvent {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	events := s.pendingEvents[tx]
+	delete(s.pendingEvents, tx)
+	return events
+}
+
+// publishEventsWithTx publishes the events raised within tx once it ended, committed or not
+func (s *transactionService) publishEventsWithTx(ctx context.Context, tx *sql.Tx, committed bool) {
+	for _, pending := range s.takeEventsWithTx(tx) {
+		if committed || pending.always {
+			s.publishEvent(ctx, pending.event, pending.payload)
+		}
+	}
+}
+
+// queueTransferFailureWithTx raises a transaction.failed event for a transfer refused within tx
+// with err, published once tx ended. Only refusals by a business rule are published, not
+// failures of the system.
+func (s *transactionService) queueTransferFailureWithTx(tx *sql.Tx, transaction *models.Transaction, err error) {
+	var refusal *domainErrors.Error
+	if !errors.As(err, &refusal) {
+		return
+	}
+	s.queueEventWithTx(tx, models.EventTransactionFailed, &models.TransferFailure{
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+		Code:                 domainErrors.Code(err),
+		Message:              err.Error(),
+		Details:              domainErrors.Details(err),
+		FailedAt:             s.now().UTC().Format(time.RFC3339),
+	}, true)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher records the events published to it
+type recordingPublisher struct {
+	mu       sync.Mutex
+	events   []string
+	payloads []interface{}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event string, payload interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+func TestTransactionEvents(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	publisher := &recordingPublisher{}
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db, WithEventPublisher(publisher))
+
+	made, err := svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(40)})
+	require.NoError(t, err)
+	require.Equal(t, []string{models.EventTransactionCompleted}, publisher.events)
+	completed := publisher.payloads[0].(*models.Transaction)
+	assert.Equal(t, made.ID, completed.ID)
+	assert.Equal(t, models.TransactionStatusComplete, completed.Status)
+
+	// A refused transfer is published although nothing was committed
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(500)})
+	require.Error(t, err)
+	require.Equal(t, []string{models.EventTransactionCompleted, models.EventTransactionFailed}, publisher.events)
+	failure := publisher.payloads[1].(*models.TransferFailure)
+	assert.Equal(t, "insufficient_balance", failure.Code)
+	assert.Equal(t, int64(1), failure.SourceAccountID)
+	assert.True(t, decimal.NewFromInt(500).Equal(failure.Amount))
+
+	// Legs of a split that rolled back are not published as completed
+	_, err = svc.CreateSplitTransfer(ctx, 2, []models.SplitLeg{
+		{DestinationAccountID: 1, Amount: decimal.NewFromInt(10)},
+		{DestinationAccountID: 99, Amount: decimal.NewFromInt(10)},
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{models.EventTransactionCompleted, models.EventTransactionFailed, models.EventTransactionFailed}, publisher.events)
+}
+
+func TestTransactionEvents_Outbox(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	publisher := &recordingPublisher{}
+	outboxRepo := repository.NewOutboxRepository(db)
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithEventPublisher(publisher), WithOutbox(outboxRepo))
+
+	recorded := func() []string {
+		rows, err := db.QueryContext(ctx, `SELECT event FROM outbox ORDER BY id`)
+		require.NoError(t, err)
+		defer rows.Close()
+		var events []string
+		for rows.Next() {
+			var event string
+			require.NoError(t, rows.Scan(&event))
+			events = append(events, event)
+		}
+		require.NoError(t, rows.Err())
+		return events
+	}
+
+	_, err := svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(40)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.EventTransactionCompleted}, recorded())
+
+	// The completed leg of a rolled-back split leaves nothing in the outbox
+	_, err = svc.CreateSplitTransfer(ctx, 2, []models.SplitLeg{
+		{DestinationAccountID: 1, Amount: decimal.NewFromInt(10)},
+		{DestinationAccountID: 99, Amount: decimal.NewFromInt(10)},
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{models.EventTransactionCompleted, models.EventTransactionFailed}, recorded())
+
+	// Nothing is published directly; the relay does that
+	assert.Empty(t, publisher.events)
+	backlog, err := outboxRepo.CountUnpublished(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), backlog)
+}
+
+func TestAccountEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	svc := NewAccountService(repository.NewMemoryAccountRepository(repository.NewMemoryStore()), WithAccountEventPublisher(publisher))
+
+	require.NoError(t, svc.CreateAccount(ctx, &dto.CreateAccountRequest{AccountID: 7, InitialBalance: decimal.NewFromInt(10)}))
+	require.Equal(t, []string{models.EventAccountCreated}, publisher.events)
+	created := publisher.payloads[0].(*models.AccountCreated)
+	assert.Equal(t, int64(7), created.AccountID)
+	assert.True(t, decimal.NewFromInt(10).Equal(created.InitialBalance))
+
+	// Nothing is published for an account that wasn't created
+	assert.Error(t, svc.CreateAccount(ctx, &dto.CreateAccountRequest{AccountID: 7, InitialBalance: decimal.NewFromInt(10)}))
+	assert.Len(t, publisher.events, 1)
+}
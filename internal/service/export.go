@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/export"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// Bounds on the number of transactions of one export chunk
+const (
+	defaultExportChunkSize = 5000
+	maxExportChunkSize     = 20000
+)
+
+// exportBatchSize is the number of transactions read per query of an export
+const exportBatchSize = 500
+
+// exportSettleDelay is how far an export stays behind the present, so every transaction it could
+// skip by ID has committed before the export passes it
+const exportSettleDelay = 5 * time.Minute
+
+// WithExportThrottle paces history exports through throttle, shared by every export of the
+// service. Without it exports read as fast as the database serves them.
+func WithExportThrottle(throttle *export.Throttle) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.exportThrottle = throttle
+	}
+}
+
+// ExportTransactions exports one chunk of up to chunkSize transactions of scope, oldest first,
+// passing each to emit with the resume token that continues the export after it. An empty
+// resumeToken starts an export covering the history recorded until shortly before now; a token
+// only resumes an export of the same scope. Reads are paced by the export throttle.
+func (s *transactionService) ExportTransactions(ctx context.Context, scope models.ExportScope, resumeToken string, chunkSize int, emit func(tx *models.Transaction, resumeToken string) error) (*models.ExportChunk, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultExportChunkSize
+	} else if chunkSize > maxExportChunkSize {
+		chunkSize = maxExportChunkSize
+	}
+
+	// Recording timestamps are on the database clock, so the export ends by the wall clock
+	cursor := models.ExportCursor{Scope: scope, Until: time.Now().Add(-exportSettleDelay).UTC()}
+	if resumeToken != "" {
+		decoded, err := models.DecodeExportCursor(resumeToken)
+		if err != nil {
+			s.log.WarnContext(ctx, "Invalid resume token for export", "scope", scope, "resume_token", resumeToken)
+			return nil, err
+		}
+		if decoded.Scope != scope {
+			s.log.WarnContext(ctx, "Resume token used for an export of another scope", "token_scope", decoded.Scope, "scope", scope)
+			return nil, fmt.Errorf("%w: resume token belongs to an export of another scope", domainErrors.ErrValidationFailed)
+		}
+		cursor = decoded
+	}
+	if scope.AccountID != 0 {
+		if _, err := s.accountRepo.GetAccount(ctx, scope.AccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := s.exportThrottle.Acquire()
+	if err != nil {
+		s.log.WarnContext(ctx, "Export throttled", "scope", scope, "err", err)
+		return nil, err
+	}
+	defer release()
+
+	chunk := &models.ExportChunk{}
+	for chunk.Count < chunkSize {
+		batch := min(exportBatchSize, chunkSize-chunk.Count)
+		if err := s.exportThrottle.Wait(ctx, batch); err != nil {
+			return nil, err
+		}
+		transactions, err := s.transactionRepo.ExportTransactions(ctx, scope, cursor.Until, cursor.AfterID, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range transactions {
+			cursor.AfterID = tx.ID
+			if err := emit(tx, cursor.Encode()); err != nil {
+				return nil, err
+			}
+			chunk.Count++
+		}
+		if len(transactions) < batch {
+			chunk.Complete = true
+			break
+		}
+	}
+	chunk.ResumeToken = cursor.Encode()
+
+	s.log.InfoContext(ctx, "Exported transactions", "count", chunk.Count, "scope", scope, "after_id", cursor.AfterID, "complete", chunk.Complete)
+	return chunk, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// feeService implements the FeeService interface
+type feeService struct {
+	repo repository.FeeRuleRepository
+	log  *slog.Logger
+}
+
+// NewFeeService creates a new fee rule service instance
+func NewFeeService(repo repository.FeeRuleRepository) FeeService {
+	return &feeService{repo: repo, log: logger.Default()}
+}
+
+// CreateFeeRule validates and stores a new fee rule
+func (s *feeService) CreateFeeRule(ctx context.Context, req *dto.CreateFeeRuleRequest) (*models.FeeRule, error) {
+	value, err := decimal.NewFromString(req.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: value %q is not a decimal number", errors.ErrValidationFailed, req.Value)
+	}
+	rule := &models.FeeRule{AccountID: req.AccountID, Type: models.FeeType(req.Type), Value: value}
+	if err := rule.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid fee rule for account", logger.AccountID(req.AccountID), "err", err)
+		return nil, err
+	}
+	return s.repo.CreateFeeRule(ctx, rule)
+}
+
+// ListFeeRules retrieves every fee rule, global rules first
+func (s *feeService) ListFeeRules(ctx context.Context) ([]*models.FeeRule, error) {
+	return s.repo.ListFeeRules(ctx)
+}
+
+// DeleteFeeRule removes a fee rule
+func (s *feeService) DeleteFeeRule(ctx context.Context, id int64) error {
+	return s.repo.DeleteFeeRule(ctx, id)
+}
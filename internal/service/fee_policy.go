@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// feeConfig is the policy deciding fees and the account collecting them
+type feeConfig struct {
+	policy    FeePolicy
+	accountID int64
+}
+
+// WithFees charges the fees decided by policy on transfers made through CreateTransaction and
+// collects them on feesAccountID. Transfers from or to the fees account are free.
+func WithFees(policy FeePolicy, feesAccountID int64) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.fees = &feeConfig{policy: policy, accountID: feesAccountID}
+	}
+}
+
+// ruleFeePolicy charges the fee rules that apply to the source account
+type ruleFeePolicy struct {
+	rules repository.FeeRuleRepository
+}
+
+// NewRuleFeePolicy creates a FeePolicy charging the fee rules of the source account, or the global
+// rules if it has none. A percentage fee that rounds to zero isn't charged.
+func NewRuleFeePolicy(rules repository.FeeRuleRepository) FeePolicy {
+	return &ruleFeePolicy{rules: rules}
+}
+
+// Fees computes the fee of every rule that applies to source
+func (p *ruleFeePolicy) Fees(ctx context.Context, transaction *models.Transaction, source *models.Account) ([]models.Fee, error) {
+	rules, err := p.rules.GetFeeRulesForAccount(ctx, source.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	var fees []models.Fee
+	for _, rule := range rules {
+		if amount := rule.Fee(transaction.Amount, source.Currency); amount.IsPositive() {
+			fees = append(fees, models.Fee{RuleID: rule.ID, Amount: amount})
+		}
+	}
+	return fees, nil
+}
+
+// feesFor returns the fees charged on a transfer of transaction from source, if opts charge fees
+func (s *transactionService) feesFor(ctx context.Context, transaction *models.Transaction, source *models.Account, opts transferOptions) ([]models.Fee, error) {
+	if !opts.chargeFees || s.fees == nil ||
+		transaction.SourceAccountID == s.fees.accountID || transaction.DestinationAccountID == s.fees.accountID {
+		return nil, nil
+	}
+	fees, err := s.fees.policy.Fees(ctx, transaction, source)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to evaluate fees of transfer", logger.AccountID(source.AccountID), "err", err)
+		return nil, err
+	}
+	for _, fee := range fees {
+		if !fee.Amount.IsPositive() {
+			return nil, domainErrors.NewInvalidAmountError(fee.Amount)
+		}
+		if err := models.ValidateAmountPrecision(fee.Amount); err != nil {
+			return nil, err
+		}
+		if err := s.checkAmountForCurrency(ctx, source, fee.Amount); err != nil {
+			return nil, err
+		}
+	}
+	return fees, nil
+}
+
+// collectFeesWithTx records each fee as a completed transfer from the source to the fees account,
+// linked to transfer, and credits the fees account with their total. The source must already
+// have been debited. It returns the fees with the transactions that collected them.
+func (s *transactionService) collectFeesWithTx(ctx context.Context, tx *sql.Tx, transfer *models.Transaction, source *models.Account, fees []models.Fee) ([]models.Fee, error) {
+	feesAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, s.fees.accountID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve fees account", logger.AccountID(s.fees.accountID), "err", err)
+		return nil, fmt.Errorf("fees account %d: %w", s.fees.accountID, err)
+	}
+	if !feesAccount.CanReceiveCredits() {
+		s.log.WarnContext(ctx, "Fees account is not active", logger.AccountID(feesAccount.AccountID), "status", feesAccount.Status)
+		return nil, domainErrors.NewAccountNotActiveError(feesAccount.AccountID)
+	}
+	if isCrossCurrency(source, feesAccount) {
+		s.log.WarnContext(ctx, "Fees account can't collect fees in another currency", logger.AccountID(feesAccount.AccountID),
+			"currency", feesAccount.Currency, "fee_currency", source.Currency)
+		return nil, domainErrors.NewCurrencyNotAllowedError(feesAccount.AccountID, source.Currency)
+	}
+
+	total := models.TotalFees(fees)
+	s.log.InfoContext(ctx, "Collecting fees of transaction", "fee", total, logger.TransactionID(transfer.ID), logger.AccountID(feesAccount.AccountID))
+	if err := s.accountRepo.UpdateBalanceWithTx(ctx, tx, feesAccount.AccountID, feesAccount.Balance.Add(total)); err != nil {
+		s.log.ErrorContext(ctx, "Failed to update fees account balance", logger.AccountID(feesAccount.AccountID), "err", err)
+		return nil, err
+	}
+
+	collected := make([]models.Fee, 0, len(fees))
+	for _, fee := range fees {
+		created, err := s.transactionRepo.CreateTransactionWithTx(ctx, tx, &models.Transaction{
+			SourceAccountID:      source.AccountID,
+			DestinationAccountID: feesAccount.AccountID,
+			Amount:               fee.Amount,
+			Status:               models.TransactionStatusComplete,
+			ValueDate:            transfer.ValueDate,
+			BusinessDate:         transfer.BusinessDate,
+			FeeOf:                transfer.ID,
+			FeeRuleID:            fee.RuleID,
+		})
+		if err != nil {
+			s.log.ErrorContext(ctx, "Failed to record fee of transaction", logger.TransactionID(transfer.ID), "err", err)
+			return nil, err
+		}
+		if err := s.recordStatusChangeWithTx(ctx, tx, created.ID, models.TransactionStatusPending, created.Status); err != nil {
+			return nil, err
+		}
+		if err := s.postTransferWithTx(ctx, tx, created, models.TransferTypeFee, source, feesAccount); err != nil {
+			return nil, err
+		}
+		fee.TransactionID = created.ID
+		collected = append(collected, fee)
+	}
+	return collected, nil
+}
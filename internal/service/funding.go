@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// SystemFundingTag tags the transfers funding and defunding the system
+const SystemFundingTag = "system-funding"
+
+// Bounds on the number of fundings listed
+const (
+	defaultFundingLimit = 100
+	maxFundingLimit     = 1000
+)
+
+// WithSystemFunding enables system float accounts: money enters the system by a transfer from the
+// float account of its currency and leaves it by a transfer back, recorded in repo, so the money in
+// the system is accounted for by its funding history
+func WithSystemFunding(repo repository.FundingRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.funding = repo
+	}
+}
+
+// fundingRepo returns the funding store, or an error if system funding isn't enabled
+func (s *transactionService) fundingRepo() (repository.FundingRepository, error) {
+	if s.funding == nil {
+		return nil, fmt.Errorf("%w: system funding is not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.funding, nil
+}
+
+// DesignateFloatAccount makes an account the system float account of a currency. The account must
+// keep the currency and have no transactions yet; it can't be an internal account. Designating the
+// current float account again is a no-op; a currency's float account can't be replaced.
+func (s *transactionService) DesignateFloatAccount(ctx context.Context, currency string, accountID int64) (*models.FloatAccount, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Designating account as a system float account", logger.AccountID(accountID), "currency", currency)
+
+	repo, err := s.fundingRepo()
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if s.isSuspenseAccount(accountID) || (s.fees != nil && s.fees.accountID == accountID) {
+		s.log.WarnContext(ctx, "Refusing to designate internal account as a float account", logger.AccountID(accountID))
+		return nil, fmt.Errorf("%w: account %d is an internal account", domainErrors.ErrValidationFailed, accountID)
+	}
+
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		current, err := repo.GetFloatAccountWithTx(ctx, tx, currency)
+		if err == nil {
+			if current.AccountID == accountID {
+				return nil
+			}
+			return fmt.Errorf("%w: account %d is the float account of %s", domainErrors.ErrFloatAccountExists, current.AccountID, currency)
+		}
+		if !errors.Is(err, domainErrors.ErrFloatAccountNotFound) {
+			return err
+		}
+
+		account, err := s.accountRepo.GetAccountWithTx(ctx, tx, accountID)
+		if err != nil {
+			return err
+		}
+		if account.Currency != currency {
+			s.log.WarnContext(ctx, "Account keeps another currency than its float", logger.AccountID(accountID), "account_currency", account.Currency, "currency", currency)
+			return fmt.Errorf("%w: account %d doesn't keep %s", domainErrors.ErrValidationFailed, accountID, currency)
+		}
+		if err := repo.CreateFloatAccountWithTx(ctx, tx, currency, accountID); err != nil {
+			return err
+		}
+		return s.accountRepo.SetAccountTypeWithTx(ctx, tx, accountID, models.AccountTypeSystemFloat)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetFloatAccount(ctx, currency)
+}
+
+// ListFloatAccounts retrieves the float account of every currency with its balance and the totals
+// funded and defunded through it
+func (s *transactionService) ListFloatAccounts(ctx context.Context) ([]*models.FloatAccount, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.fundingRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.ListFloatAccounts(ctx)
+}
+
+// RecordFunding funds the system with money for an account, transferred from the float account of
+// the funding's currency, or defunds it by transferring money from the account back to the float.
+// The account must keep the currency. Funding transfers are charged no fees and aren't held for
+// approval; a defunding is debited from the account under the usual rules. The funding is recorded
+// with its transfer and the actor of ctx.
+func (s *transactionService) RecordFunding(ctx context.Context, funding *models.Funding) (*models.Funding, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Recording funding", "direction", funding.Direction, "amount", funding.Amount, "currency", funding.Currency, logger.AccountID(funding.AccountID))
+
+	repo, err := s.fundingRepo()
+	if err != nil {
+		return nil, err
+	}
+	funding.Actor = actor.FromContext(ctx)
+	if err := funding.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid funding", "err", err)
+		return nil, err
+	}
+
+	var recorded *models.Funding
+	err = s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			float, err := repo.GetFloatAccountWithTx(ctx, tx, funding.Currency)
+			if err != nil {
+				return err
+			}
+			transaction := &models.Transaction{
+				SourceAccountID:      float.AccountID,
+				DestinationAccountID: funding.AccountID,
+				Amount:               funding.Amount,
+				Status:               models.TransactionStatusPending,
+				Tags:                 []string{SystemFundingTag},
+			}
+			if funding.Direction == models.FundingDirectionDefund {
+				transaction.SourceAccountID, transaction.DestinationAccountID = funding.AccountID, float.AccountID
+			}
+			if err := transaction.Validate(); err != nil {
+				return err
+			}
+
+			made, err := s.transferWithTx(ctx, tx, transaction, transferOptions{funding: true})
+			if err != nil {
+				return err
+			}
+			funding.TransactionID = made.ID
+			recorded, err = repo.CreateFundingWithTx(ctx, tx, funding)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Recorded funding", "direction", recorded.Direction, "s_id", recorded.ID, "amount", recorded.Amount, "currency", recorded.Currency, logger.AccountID(recorded.AccountID), logger.TransactionID(recorded.TransactionID))
+	return recorded, nil
+}
+
+// ListFundings retrieves the funding history of a currency, newest first. limit defaults to 100
+// and is capped at 1000.
+func (s *transactionService) ListFundings(ctx context.Context, currency string, limit int) ([]*models.Funding, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.fundingRepo()
+	if err != nil {
+		return nil, err
+	}
+	if err := models.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultFundingLimit
+	} else if limit > maxFundingLimit {
+		limit = maxFundingLimit
+	}
+	return repo.ListFundings(ctx, currency, limit)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/khamiruf/internal_transfers_system_go/internal/verify"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemFunding(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := actor.WithActor(context.Background(), "treasury")
+	accountRepo := repository.NewAccountRepository(db)
+	floatID, customerID, otherID, euroID := int64(1), int64(2), int64(3), int64(4)
+	for _, id := range []int64{floatID, customerID, otherID, euroID} {
+		require.NoError(t, accountRepo.CreateAccount(ctx, id, decimal.Zero))
+	}
+	for _, id := range []int64{floatID, customerID, otherID} {
+		require.NoError(t, accountRepo.SetCurrency(ctx, id, "USD"))
+	}
+	require.NoError(t, accountRepo.SetCurrency(ctx, euroID, "EUR"))
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithSystemFunding(repository.NewFundingRepository(db)))
+	balance := func(id int64) decimal.Decimal {
+		account, err := accountRepo.GetAccount(ctx, id)
+		require.NoError(t, err)
+		return account.Balance
+	}
+
+	// The float must keep the currency, and a currency has one float
+	_, err := svc.DesignateFloatAccount(ctx, "USD", euroID)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	float, err := svc.DesignateFloatAccount(ctx, "USD", floatID)
+	require.NoError(t, err)
+	assert.Equal(t, floatID, float.AccountID)
+	_, err = svc.DesignateFloatAccount(ctx, "USD", floatID)
+	assert.NoError(t, err, "designating the same float again is a no-op")
+	_, err = svc.DesignateFloatAccount(ctx, "USD", otherID)
+	assert.ErrorIs(t, err, errors.ErrFloatAccountExists)
+
+	// Funding takes the float negative
+	funded, err := svc.RecordFunding(ctx, &models.Funding{Currency: "USD", Direction: models.FundingDirectionFund, AccountID: customerID, Amount: decimal.NewFromInt(1000), Reference: "wire-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "treasury", funded.Actor)
+	assert.True(t, decimal.NewFromInt(-1000).Equal(balance(floatID)), "expected -1000, got %s", balance(floatID))
+	assert.True(t, decimal.NewFromInt(1000).Equal(balance(customerID)))
+
+	// Money in the system moves as usual, but never to or from the float outside funding
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: customerID, DestinationAccountID: otherID, Amount: decimal.NewFromInt(300)})
+	require.NoError(t, err)
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: floatID, DestinationAccountID: otherID, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrSystemFloatAccount)
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: otherID, DestinationAccountID: floatID, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrSystemFloatAccount)
+	_, err = svc.CloseAccount(ctx, floatID, false, 0)
+	assert.ErrorIs(t, err, errors.ErrSystemFloatAccount)
+
+	// Defunding is debited under the usual rules
+	_, err = svc.RecordFunding(ctx, &models.Funding{Currency: "USD", Direction: models.FundingDirectionDefund, AccountID: otherID, Amount: decimal.NewFromInt(500)})
+	assert.ErrorIs(t, err, errors.ErrInsufficientBalance)
+	_, err = svc.RecordFunding(ctx, &models.Funding{Currency: "USD", Direction: models.FundingDirectionDefund, AccountID: otherID, Amount: decimal.NewFromInt(200)})
+	require.NoError(t, err)
+	_, err = svc.RecordFunding(ctx, &models.Funding{Currency: "EUR", Direction: models.FundingDirectionFund, AccountID: euroID, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrFloatAccountNotFound)
+
+	floats, err := svc.ListFloatAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, floats, 1)
+	assert.True(t, decimal.NewFromInt(1000).Equal(floats[0].Funded))
+	assert.True(t, decimal.NewFromInt(200).Equal(floats[0].Defunded))
+	assert.True(t, decimal.NewFromInt(800).Equal(floats[0].NetFunded))
+	assert.True(t, floats[0].Balance.Equal(floats[0].NetFunded.Neg()))
+
+	fundings, err := svc.ListFundings(ctx, "USD", 0)
+	require.NoError(t, err)
+	require.Len(t, fundings, 2)
+	assert.Equal(t, models.FundingDirectionDefund, fundings[0].Direction)
+	assert.Equal(t, "wire-1", fundings[1].Reference)
+
+	// The books reconcile, the negative float included
+	report, err := verify.NewChecker(db).Run(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+}
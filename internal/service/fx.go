@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/fx"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+)
+
+// WithFXRates allows transfers between accounts in different currencies, converting the amount at
+// the rate quoted by rates. Without it such transfers are rejected with ErrFXRateUnavailable.
+func WithFXRates(rates fx.RateProvider) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.rates = rates
+	}
+}
+
+// fxConversion is the conversion of a cross-currency transfer
+type fxConversion struct {
+	rate      decimal.Decimal
+	converted decimal.Decimal
+}
+
+// isCrossCurrency reports whether a transfer from source to dest converts between currencies.
+// An account without a currency takes whatever it is sent.
+func isCrossCurrency(source, dest *models.Account) bool {
+	return source.Currency != "" && dest.Currency != "" && source.Currency != dest.Currency
+}
+
+// convert converts amount from the currency of source to that of dest at the quoted rate, or
+// returns pinned if the conversion is fixed already
+func (s *transactionService) convert(ctx context.Context, source, dest *models.Account, amount decimal.Decimal, pinned *fxConversion) (*fxConversion, error) {
+	if pinned != nil {
+		return pinned, nil
+	}
+	if s.rates == nil {
+		s.log.WarnContext(ctx, "No rate provider for cross-currency transfer", "source_currency", source.Currency, "destination_currency", dest.Currency)
+		return nil, fmt.Errorf("%w: no rate from %s to %s", domainErrors.ErrFXRateUnavailable, source.Currency, dest.Currency)
+	}
+
+	rate, err := s.rates.Rate(ctx, source.Currency, dest.Currency)
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to quote rate", "source_currency", source.Currency, "destination_currency", dest.Currency, "err", err)
+		return nil, err
+	}
+	rate = rate.Round(fx.RateScale)
+	converted := fx.Convert(amount, rate, dest.Currency)
+	if !converted.IsPositive() {
+		s.log.WarnContext(ctx, "Amount converts to a non-positive amount", "amount", amount, "source_currency", source.Currency,
+			"converted_amount", converted, "destination_currency", dest.Currency)
+		return nil, domainErrors.WithAccount(domainErrors.NewInvalidAmountError(converted), dest.AccountID)
+	}
+
+	s.log.InfoContext(ctx, "Converted amount", "amount", amount, "source_currency", source.Currency,
+		"converted_amount", converted, "destination_currency", dest.Currency, "rate", rate.String())
+	return &fxConversion{rate: rate, converted: converted}, nil
+}
+
+// reversalConversion fixes the conversion of the transfer reversing original so the original
+// source gets back exactly what it sent, or returns nil if original is within one currency
+func reversalConversion(original *models.Transaction) *fxConversion {
+	if original.FXRate == nil {
+		return nil
+	}
+	return &fxConversion{rate: fx.Inverse(*original.FXRate), converted: original.Amount}
+}
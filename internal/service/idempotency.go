@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// WithIdempotencyKeys makes CreateTransaction honour the idempotency key carried by the request
+// context (see package idempotency): the response of the first request is stored under the key
+// together with the transfer, and retries with the same key get that response back without
+// moving money again. Reusing a key for a different transfer is rejected with ErrIdempotencyConflict.
+// Keys belong to the caller (auth.Owner): another caller using the same key makes its own transfer.
+func WithIdempotencyKeys(repo repository.IdempotencyRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.idempotency = repo
+	}
+}
+
+// WithIdempotencySealer encrypts and authenticates the responses stored under idempotency keys
+// with sealer, so the database doesn't hold them in plaintext. Responses stored before sealing
+// was enabled are still replayed.
+func WithIdempotencySealer(sealer *idempotency.Sealer) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.idempotencySealer = sealer
+	}
+}
+
+// WithIdempotencyTTL makes the records of idempotency keys expire ttl after they were stored:
+// a key can then be reused for a new transfer, and PurgeExpiredIdempotencyRecords deletes the
+// stored response. Without it records are kept.
+func WithIdempotencyTTL(ttl time.Duration) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// liveIdempotencyRecord returns the record stored under key by the owner of ctx (auth.Owner), or
+// nil if the owner hasn't used the key or its record expired
+func (s *transactionService) liveIdempotencyRecord(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	record, err := s.idempotency.GetIdempotencyRecord(ctx, auth.Owner(ctx), key)
+	if errors.Is(err, domainErrors.ErrTransactionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Expired(s.now()) {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// replayIdempotentTransaction returns the response stored under key. found is false if the key
+// hasn't been used yet or its record expired.
+func (s *transactionService) replayIdempotentTransaction(ctx context.Context, key, fingerprint string) (resp *dto.TransactionResponse, found bool, err error) {
+	record, err := s.liveIdempotencyRecord(ctx, key)
+	if err != nil || record == nil {
+		return nil, false, err
+	}
+	if record.Fingerprint != fingerprint {
+		s.log.WarnContext(ctx, "Idempotency key reused for a different transfer", "key", key)
+		return nil, true, fmt.Errorf("%w: key %q", domainErrors.ErrIdempotencyConflict, key)
+	}
+
+	payload := record.Response
+	if record.Sealed {
+		if s.idempotencySealer == nil {
+			return nil, true, fmt.Errorf("response stored under idempotency key %q is sealed but no sealer is configured", key)
+		}
+		if payload, err = s.idempotencySealer.Open(record.Response, record.SealingContext()); err != nil {
+			s.log.ErrorContext(ctx, "Response stored under idempotency key doesn't unseal", "key", key, "err", err)
+			return nil, true, fmt.Errorf("response stored under idempotency key %q: %w", key, err)
+		}
+	}
+
+	var stored dto.TransactionResponse
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return nil, true, fmt.Errorf("invalid response stored under idempotency key %q: %w", key, err)
+	}
+	s.log.InfoContext(ctx, "Replaying transaction for idempotency key", logger.TransactionID(record.TransactionID), "key", key)
+	return &stored, true, nil
+}
+
+// createIdempotentTransaction makes the transfer and stores its response under key in the same
+// database transaction, or replays the response of an earlier request with the same key
+func (s *transactionService) createIdempotentTransaction(ctx context.Context, key string, transaction *models.Transaction) (*dto.TransactionResponse, error) {
+	if err := models.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	fingerprint := models.TransferFingerprint(transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount)
+
+	if resp, found, err := s.replayIdempotentTransaction(ctx, key, fingerprint); found || err != nil {
+		return resp, err
+	}
+
+	if err := transaction.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Transaction validation failed", "err", err)
+		s.metrics.Record("", err)
+		return nil, err
+	}
+
+	var resp *dto.TransactionResponse
+	err := s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			createdTx, err := s.transferWithTx(ctx, tx, transaction, transferOptions{chargeFees: true, approvable: true})
+			if err != nil {
+				return err
+			}
+			resp = toTransactionResponse(createdTx)
+			encoded, err := json.Marshal(resp)
+			if err != nil {
+				return fmt.Errorf("failed to encode response: %w", err)
+			}
+			record := &models.IdempotencyRecord{
+				Owner:         auth.Owner(ctx),
+				Key:           key,
+				Fingerprint:   fingerprint,
+				TransactionID: createdTx.ID,
+				Response:      encoded,
+			}
+			if err := s.sealIdempotencyRecord(record); err != nil {
+				return err
+			}
+			return s.idempotency.CreateIdempotencyRecordWithTx(ctx, tx, record, s.now())
+		})
+	})
+	if errors.Is(err, domainErrors.ErrDuplicateIdempotencyKey) {
+		// A concurrent request with the same key committed first; our transfer was rolled back
+		if resp, found, replayErr := s.replayIdempotentTransaction(ctx, key, fingerprint); found || replayErr != nil {
+			return resp, replayErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sealIdempotencyRecord sets the expiry of a new record and seals its response, if configured
+func (s *transactionService) sealIdempotencyRecord(record *models.IdempotencyRecord) error {
+	if s.idempotencyTTL > 0 {
+		expiresAt := s.now().Add(s.idempotencyTTL)
+		record.ExpiresAt = &expiresAt
+	}
+	if s.idempotencySealer == nil {
+		return nil
+	}
+	sealed, err := s.idempotencySealer.Seal(record.Response, record.SealingContext())
+	if err != nil {
+		return fmt.Errorf("failed to seal response: %w", err)
+	}
+	record.Response, record.Sealed = sealed, true
+	return nil
+}
+
+// PurgeExpiredIdempotencyRecords deletes up to limit idempotency records that expired on the
+// service clock, with their stored responses, and returns how many it deleted. It is meant to
+// run as a sweeper task.
+func (s *transactionService) PurgeExpiredIdempotencyRecords(ctx context.Context, limit int) (int, error) {
+	if s.idempotency == nil || s.idempotencyTTL <= 0 {
+		return 0, nil
+	}
+	return s.idempotency.DeleteExpiredIdempotencyRecords(ctx, s.now(), limit)
+}
+
+// GetTransactionByIdempotencyKey returns the transfer made under the idempotency key of a single
+// transfer request, so a client that lost the response (e.g. by disconnecting mid-request) can
+// learn whether its transfer was made. Only the caller's own keys (auth.Owner) are looked up, so a
+// caller guessing another's key learns nothing: ErrTransactionNotFound means the caller made no
+// transfer under the key.
+func (s *transactionService) GetTransactionByIdempotencyKey(ctx context.Context, key string) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+
+	if s.idempotency == nil {
+		return nil, fmt.Errorf("%w: idempotency key %q", domainErrors.ErrTransactionNotFound, key)
+	}
+	record, err := s.liveIdempotencyRecord(ctx, key)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to look up idempotency key", "key", key, "err", err)
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("%w: idempotency key %q", domainErrors.ErrTransactionNotFound, key)
+	}
+	return s.GetTransaction(ctx, record.TransactionID)
+}
+
+// GetBatchItemTransaction returns the transfer made for an item of a batch, so a client that lost
+// the response of a batch can learn which of its items were made. Like idempotency keys, only the
+// caller's own batches are looked up: ErrTransactionNotFound means the caller's item wasn't made.
+func (s *transactionService) GetBatchItemTransaction(ctx context.Context, batchKey, itemKey string) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateIdempotencyKey(batchKey); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateIdempotencyKey(itemKey); err != nil {
+		return nil, err
+	}
+
+	batch := &models.Batch{Owner: auth.Owner(ctx), Key: batchKey}
+	transaction, err := s.transactionRepo.GetTransactionByBatchItemKey(ctx, batch.ItemKey(itemKey))
+	if err != nil {
+		if !errors.Is(err, domainErrors.ErrTransactionNotFound) {
+			s.log.ErrorContext(ctx, "Failed to look up batch item", "batch_key", batchKey, "item_key", itemKey, "err", err)
+		}
+		return nil, err
+	}
+	return transaction, nil
+}
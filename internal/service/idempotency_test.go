@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransaction_SealedIdempotencyKeyExpires(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+
+	sealer, err := idempotency.NewSealer(map[string][]byte{"k1": bytes.Repeat([]byte{7}, 32)}, "k1")
+	require.NoError(t, err)
+	keys := repository.NewIdempotencyRepository(db)
+	virtual := clock.NewVirtual()
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithIdempotencyKeys(keys), WithIdempotencySealer(sealer), WithIdempotencyTTL(time.Hour), WithClock(virtual))
+
+	keyed := idempotency.WithKey(ctx, "sealed-1")
+	req := &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)}
+	first, err := svc.CreateTransaction(keyed, req)
+	require.NoError(t, err)
+
+	record, err := keys.GetIdempotencyRecord(ctx, "", "sealed-1")
+	require.NoError(t, err)
+	assert.True(t, record.Sealed)
+	assert.NotContains(t, string(record.Response), `"amount"`)
+
+	// A retry within the TTL replays the sealed response
+	retried, err := svc.CreateTransaction(keyed, req)
+	require.NoError(t, err)
+	assert.Equal(t, first, retried)
+
+	// Once expired, the key is unused again and its record can be purged
+	_, err = virtual.Advance(time.Hour)
+	require.NoError(t, err)
+	_, err = svc.GetTransactionByIdempotencyKey(ctx, "sealed-1")
+	assert.Error(t, err)
+	deleted, err := svc.PurgeExpiredIdempotencyRecords(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	second, err := svc.CreateTransaction(keyed, req)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+	source, err := accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(80).Equal(source.Balance), "expected 80, got %s", source.Balance)
+}
+
+func TestCreateTransaction_IdempotencyKeysBelongToCaller(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithIdempotencyKeys(repository.NewIdempotencyRepository(db)))
+
+	payroll := idempotency.WithKey(auth.WithCredential(ctx, &auth.Credential{ID: 1, Name: "payroll"}), "run-1")
+	billing := idempotency.WithKey(auth.WithCredential(ctx, &auth.Credential{ID: 2, Name: "billing"}), "run-1")
+
+	first, err := svc.CreateTransaction(payroll, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+
+	// The same key from another caller is its own transfer, even a different one
+	other, err := svc.CreateTransaction(billing, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(20)})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID)
+
+	retried, err := svc.CreateTransaction(payroll, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	assert.Equal(t, first, retried)
+
+	source, err := accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(70).Equal(source.Balance), "expected 70, got %s", source.Balance)
+}
+
+func TestGetTransactionByIdempotencyKey_OnlyOwnKeys(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithIdempotencyKeys(repository.NewIdempotencyRepository(db)))
+
+	payroll := auth.WithCredential(ctx, &auth.Credential{ID: 1, Name: "payroll", Scopes: []auth.Scope{auth.ScopeAdmin}})
+	reader := auth.WithCredential(ctx, &auth.Credential{ID: 2, Name: "reader", Scopes: []auth.Scope{auth.ScopeAccountsRead}})
+
+	made, err := svc.CreateTransaction(idempotency.WithKey(payroll, "run-1"),
+		&dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	_, err = svc.CreateTransferBatch(payroll, "batch-1", []models.BatchItem{
+		{Key: "row-1", SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5)},
+	})
+	require.NoError(t, err)
+
+	found, err := svc.GetTransactionByIdempotencyKey(payroll, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, made.ID, found.ID)
+	_, err = svc.GetBatchItemTransaction(payroll, "batch-1", "row-1")
+	require.NoError(t, err)
+
+	// Another caller guessing the keys finds nothing
+	_, err = svc.GetTransactionByIdempotencyKey(reader, "run-1")
+	assert.ErrorIs(t, err, domainErrors.ErrTransactionNotFound)
+	_, err = svc.GetBatchItemTransaction(reader, "batch-1", "row-1")
+	assert.ErrorIs(t, err, domainErrors.ErrTransactionNotFound)
+}
@@ -2,18 +2,147 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+	"github.com/shopspring/decimal"
 )
 
 // AccountService defines the interface for account-related operations
 type AccountService interface {
 	CreateAccount(ctx context.Context, req *dto.CreateAccountRequest) error
 	GetAccount(ctx context.Context, accountID int64) (*models.Account, error)
+	ListAccountsByOwner(ctx context.Context, ownerRef string) ([]*models.Account, error)
+	ListAccounts(ctx context.Context, filter models.AccountListFilter, limit int, cursor string) (*models.AccountPage, error)
+	SetAccountOwner(ctx context.Context, accountID int64, ownerRef string) error
+	ReactivateAccount(ctx context.Context, accountID int64) (*models.Account, error)
+	SetAccountStatus(ctx context.Context, accountID int64, status models.AccountStatus) (*models.Account, error)
+	SetOverdraftLimit(ctx context.Context, accountID int64, limit decimal.Decimal) (*models.Account, error)
+	SetAccountLimits(ctx context.Context, accountID int64, limits models.AccountLimits) (*models.Account, error)
+	SetAccountType(ctx context.Context, accountID int64, accountType models.AccountType) error
+	SetAccountCurrency(ctx context.Context, accountID int64, currency string) (*models.Account, error)
+	SetAccountTenant(ctx context.Context, accountID int64, tenantID string) error
+	AddAccountNote(ctx context.Context, accountID int64, body, caseID string) (*models.AccountNote, error)
+	ListAccountNotes(ctx context.Context, accountID int64, caseID string, limit int) ([]*models.AccountNote, error)
+}
+
+// TenantService defines the interface for managing the settings overrides of tenants
+type TenantService interface {
+	GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error)
+	ListTenantSettings(ctx context.Context) ([]*models.TenantSettings, error)
+	PutTenantSettings(ctx context.Context, tenantID string, req *dto.PutTenantSettingsRequest) (*models.TenantSettings, error)
+	DeleteTenantSettings(ctx context.Context, tenantID string) error
+}
+
+// FeeService defines the interface for managing fee rules
+type FeeService interface {
+	CreateFeeRule(ctx context.Context, req *dto.CreateFeeRuleRequest) (*models.FeeRule, error)
+	ListFeeRules(ctx context.Context) ([]*models.FeeRule, error)
+	DeleteFeeRule(ctx context.Context, id int64) error
+}
+
+// WriteFence guards write transactions, e.g. against writing from a standby or fenced-off region
+type WriteFence interface {
+	// CheckWrite is called at the start of every write transaction and aborts it by returning an error
+	CheckWrite(ctx context.Context, tx *sql.Tx) error
+}
+
+// EventPublisher delivers events to interested parties, e.g. the webhook dispatcher
+type EventPublisher interface {
+	Publish(ctx context.Context, event string, payload interface{}) error
+}
+
+// FeePolicy decides the fees charged on a transfer. Fees are paid by the source account in its
+// currency on top of the amount.
+type FeePolicy interface {
+	Fees(ctx context.Context, transaction *models.Transaction, source *models.Account) ([]models.Fee, error)
 }
 
 // TransactionService defines the interface for transaction-related operations
 type TransactionService interface {
 	CreateTransaction(ctx context.Context, req *dto.CreateTransactionRequest) (*dto.TransactionResponse, error)
+	CreateBackdatedTransaction(ctx context.Context, req *dto.CreateTransactionRequest, valueDate time.Time) (*dto.TransactionResponse, error)
+	CreateAdminTransaction(ctx context.Context, req *dto.CreateTransactionRequest, override *models.MinimumBalanceOverride) (*models.Transaction, error)
+	GetTransaction(ctx context.Context, transactionID int64) (*models.Transaction, error)
+	GetTransactionByIdempotencyKey(ctx context.Context, key string) (*models.Transaction, error)
+	GetBatchItemTransaction(ctx context.Context, batchKey, itemKey string) (*models.Transaction, error)
+	ListAccountTransactions(ctx context.Context, accountID int64, filter models.TransactionFilter, limit int, cursor string) (*models.TransactionPage, error)
+	GetStatement(ctx context.Context, accountID int64, from, to time.Time, axis models.TimeAxis) (*models.Statement, error)
+	GetBalanceAsOf(ctx context.Context, accountID int64, at time.Time, axis models.TimeAxis) (decimal.Decimal, error)
+	GetAccountBalances(ctx context.Context, accountIDs []int64) ([]*models.Account, time.Time, error)
+	SearchTransactionsByTag(ctx context.Context, tag string, from, to time.Time, limit int) ([]*models.Transaction, error)
+	TagTransaction(ctx context.Context, transactionID int64, tags []string) error
+	ReverseTransaction(ctx context.Context, transactionID int64) (*models.Transaction, error)
+	GetTransactionHistory(ctx context.Context, transactionID int64) ([]*models.TransactionStatusChange, error)
+	ExportTransactions(ctx context.Context, scope models.ExportScope, resumeToken string, chunkSize int, emit func(tx *models.Transaction, resumeToken string) error) (*models.ExportChunk, error)
+	ListTransactionsByBusinessDate(ctx context.Context, businessDate string, limit int) ([]*models.Transaction, error)
+	GetBusinessDayReport(ctx context.Context, from, to string) ([]*models.BusinessDaySummary, error)
+	CreateTransferBatch(ctx context.Context, batchKey string, items []models.BatchItem) (*models.BatchResult, error)
+	CreateSplitTransfer(ctx context.Context, sourceID int64, legs []models.SplitLeg) (*models.SplitTransfer, error)
+	GetSplitTransfer(ctx context.Context, splitID int64) (*models.SplitTransfer, error)
+	ListSuspenseItems(ctx context.Context, status models.SuspenseStatus, limit int) ([]*models.SuspenseItem, error)
+	GetSuspenseItem(ctx context.Context, itemID int64) (*models.SuspenseItem, error)
+	ReapplySuspenseItem(ctx context.Context, itemID int64, actor, note string) (*models.SuspenseItem, error)
+	ReturnSuspenseItem(ctx context.Context, itemID int64, actor, note string) (*models.SuspenseItem, error)
+	PreAuthorizeTransfer(ctx context.Context, req *dto.CreateTransactionRequest) (*models.PreAuthorization, error)
+	GetPreAuthorization(ctx context.Context, preAuthID int64) (*models.PreAuthorization, error)
+	ExecutePreAuthorization(ctx context.Context, preAuthID int64) (*models.Transaction, error)
+	ExpirePreAuthorizations(ctx context.Context, limit int) (int, error)
+	// PurgeExpiredIdempotencyRecords deletes up to limit expired idempotency records; a sweeper task
+	PurgeExpiredIdempotencyRecords(ctx context.Context, limit int) (int, error)
+	GetTenantUsage(ctx context.Context, tenantID string) (*models.TenantUsage, error)
+	ApproveTransfer(ctx context.Context, transactionID int64, note string) (*models.Transaction, error)
+	RejectTransfer(ctx context.Context, transactionID int64, note string) (*models.Transaction, error)
+	GetTransferApproval(ctx context.Context, transactionID int64) (*models.TransferApproval, error)
+	ListTransferApprovals(ctx context.Context, status models.ApprovalStatus, limit int) ([]*models.TransferApproval, error)
+	ScheduleTransfer(ctx context.Context, req *dto.CreateTransactionRequest, executeAt time.Time) (*models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, status models.ScheduledTransferStatus, limit int) ([]*models.ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error)
+	ExecuteDueScheduledTransfers(ctx context.Context, limit int) (int, error)
+	CreateStandingOrder(ctx context.Context, req *dto.CreateTransactionRequest, recurrence models.Recurrence) (*models.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error)
+	ListStandingOrderOccurrences(ctx context.Context, orderID int64, limit int) ([]*models.ScheduledTransfer, error)
+	PauseStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error)
+	ResumeStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error)
+	ScheduleStandingOrders(ctx context.Context, limit int) (int, error)
+	CloseAccount(ctx context.Context, accountID int64, force bool, sweepTo int64) (*models.AccountClosure, error)
+	SnapshotBalances(ctx context.Context, limit int) (int, error)
+	DesignateFloatAccount(ctx context.Context, currency string, accountID int64) (*models.FloatAccount, error)
+	ListFloatAccounts(ctx context.Context) ([]*models.FloatAccount, error)
+	RecordFunding(ctx context.Context, funding *models.Funding) (*models.Funding, error)
+	ListFundings(ctx context.Context, currency string, limit int) ([]*models.Funding, error)
+	AdjustBalance(ctx context.Context, adjustment *models.Adjustment) (*models.Adjustment, error)
+	ListAdjustments(ctx context.Context, accountID int64, limit int) ([]*models.Adjustment, error)
+	SubmitTransferJob(ctx context.Context, req *dto.CreateTransactionRequest) (*models.TransferJob, error)
+	GetTransferJob(ctx context.Context, jobID int64) (*models.TransferJob, error)
+	ExecuteQueuedTransferJobs(ctx context.Context, limit int) (int, error)
+	CancelTransferJob(ctx context.Context, jobID int64, reason string) (*models.TransferJob, error)
+	RequeueTransferJob(ctx context.Context, jobID int64, reason string) (*models.TransferJob, error)
+	SetTransferJobPriority(ctx context.Context, jobID int64, class priority.Class, reason string) (*models.TransferJob, error)
+	ListDeadLetters(ctx context.Context, kind models.DeadLetterKind, limit int) ([]*models.DeadLetter, error)
+	CreateDelegation(ctx context.Context, delegation *models.Delegation) (*models.Delegation, error)
+	GetDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error)
+	ListDelegations(ctx context.Context, grantor, grantee string, limit int) ([]*models.Delegation, error)
+	RevokeDelegation(ctx context.Context, delegationID int64) (*models.Delegation, error)
+}
+
+// LedgerService defines the interface for managing the chart of accounts of the ledger and the
+// posting rules of transfer types
+type LedgerService interface {
+	CreateLedgerAccount(ctx context.Context, req *dto.CreateLedgerAccountRequest) (*models.LedgerAccount, error)
+	GetLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error)
+	ListLedgerAccounts(ctx context.Context, filter models.LedgerAccountFilter) ([]*models.LedgerAccount, error)
+	UpdateLedgerAccount(ctx context.Context, code string, req *dto.UpdateLedgerAccountRequest) (*models.LedgerAccount, error)
+	DeactivateLedgerAccount(ctx context.Context, code string) error
+	GetPostableLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error)
+	ListPostingRules(ctx context.Context, transferType models.TransferType) ([]*models.PostingRule, error)
+	SetPostingRules(ctx context.Context, transferType models.TransferType, rules []*models.PostingRule) ([]*models.PostingRule, error)
+	PlanPostings(ctx context.Context, transferType models.TransferType, transaction *models.Transaction, sourceCurrency, destinationCurrency string) ([]models.Posting, error)
+	GetTransactionEntries(ctx context.Context, transactionID int64) ([]*models.LedgerEntry, error)
+	GetLedgerBalance(ctx context.Context, accountID int64) (*models.LedgerBalance, error)
 }
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// ledgerService implements the LedgerService interface
+type ledgerService struct {
+	accounts repository.LedgerAccountRepository
+	rules    repository.PostingRuleRepository
+	entries  repository.LedgerRepository
+	log      *slog.Logger
+}
+
+// NewLedgerService creates a new ledger service instance
+func NewLedgerService(accounts repository.LedgerAccountRepository, rules repository.PostingRuleRepository, entries repository.LedgerRepository) LedgerService {
+	return &ledgerService{accounts: accounts, rules: rules, entries: entries, log: logger.Default()}
+}
+
+// CreateLedgerAccount validates and adds an account to the chart of accounts
+func (s *ledgerService) CreateLedgerAccount(ctx context.Context, req *dto.CreateLedgerAccountRequest) (*models.LedgerAccount, error) {
+	account := &models.LedgerAccount{
+		Code:       strings.TrimSpace(req.Code),
+		Name:       strings.TrimSpace(req.Name),
+		Type:       models.LedgerAccountType(req.AccountType),
+		NormalSide: models.EntrySide(req.NormalSide),
+		AccountID:  req.AccountID,
+	}
+	if err := account.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid ledger account", "code", account.Code, "err", err)
+		return nil, err
+	}
+	if account.AccountID < 0 {
+		return nil, fmt.Errorf("%w: invalid account_id", errors.ErrValidationFailed)
+	}
+	if account.NormalSide != account.Type.NormalSide() {
+		s.log.InfoContext(ctx, "Ledger account is a contra account with normal side", "code", account.Code, "type", account.Type, "normal_side", account.NormalSide)
+	}
+	return s.accounts.CreateLedgerAccount(ctx, account)
+}
+
+// GetLedgerAccount retrieves a ledger account by its code
+func (s *ledgerService) GetLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error) {
+	return s.accounts.GetLedgerAccount(ctx, code)
+}
+
+// ListLedgerAccounts retrieves the ledger accounts matching filter, ordered by code
+func (s *ledgerService) ListLedgerAccounts(ctx context.Context, filter models.LedgerAccountFilter) ([]*models.LedgerAccount, error) {
+	if filter.Type != "" && !filter.Type.IsValid() {
+		return nil, fmt.Errorf("%w: invalid ledger account type %q", errors.ErrValidationFailed, filter.Type)
+	}
+	return s.accounts.ListLedgerAccounts(ctx, filter)
+}
+
+// UpdateLedgerAccount renames a ledger account and changes its backing account
+func (s *ledgerService) UpdateLedgerAccount(ctx context.Context, code string, req *dto.UpdateLedgerAccountRequest) (*models.LedgerAccount, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > models.MaxLedgerAccountNameLength {
+		return nil, fmt.Errorf("%w: ledger account name must be 1-%d characters", errors.ErrValidationFailed, models.MaxLedgerAccountNameLength)
+	}
+	if req.AccountID < 0 {
+		return nil, fmt.Errorf("%w: invalid account_id", errors.ErrValidationFailed)
+	}
+	return s.accounts.UpdateLedgerAccount(ctx, code, name, req.AccountID)
+}
+
+// DeactivateLedgerAccount stops further postings to a ledger account; its history is kept
+func (s *ledgerService) DeactivateLedgerAccount(ctx context.Context, code string) error {
+	s.log.InfoContext(ctx, "Deactivating ledger account", "code", code)
+	return s.accounts.SetLedgerAccountActive(ctx, code, false)
+}
+
+// GetPostableLedgerAccount retrieves a ledger account that postings can be made against, i.e. one
+// that exists in the chart and is active. Postings must be validated through it.
+func (s *ledgerService) GetPostableLedgerAccount(ctx context.Context, code string) (*models.LedgerAccount, error) {
+	account, err := s.accounts.GetLedgerAccount(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Active {
+		s.log.WarnContext(ctx, "Posting to inactive ledger account", "code", code)
+		return nil, fmt.Errorf("%w: code %s", errors.ErrLedgerAccountInactive, code)
+	}
+	return account, nil
+}
+
+// ListPostingRules retrieves the posting rules of a transfer type, or of all types if empty
+func (s *ledgerService) ListPostingRules(ctx context.Context, transferType models.TransferType) ([]*models.PostingRule, error) {
+	if transferType != "" {
+		if err := models.ValidateTransferType(transferType); err != nil {
+			return nil, err
+		}
+	}
+	return s.rules.ListPostingRules(ctx, transferType)
+}
+
+// SetPostingRules replaces the posting template of a transfer type. Every ledger account the
+// template refers to must be in the chart and active, so a configured flow can always be posted.
+func (s *ledgerService) SetPostingRules(ctx context.Context, transferType models.TransferType, rules []*models.PostingRule) ([]*models.PostingRule, error) {
+	s.log.InfoContext(ctx, "Setting posting rules of transfer type", "transfer_type", transferType, "count", len(rules))
+
+	if err := models.ValidateTransferType(transferType); err != nil {
+		return nil, err
+	}
+	if err := models.ValidatePostingTemplate(rules); err != nil {
+		s.log.WarnContext(ctx, "Invalid posting template", "transfer_type", transferType, "err", err)
+		return nil, err
+	}
+	for _, rule := range rules {
+		rule.TransferType = transferType
+		for _, side := range []string{rule.Debit, rule.Credit} {
+			if models.IsParty(side) {
+				continue
+			}
+			if _, err := s.GetPostableLedgerAccount(ctx, side); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.rules.ReplacePostingRules(ctx, transferType, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// PlanPostings applies the posting template of a transfer type to a transfer between accounts in
+// sourceCurrency and destinationCurrency, converting the legs of a cross-currency transfer through
+// fx_position, checking the ledger accounts it posts to are still active and resolving them to
+// their backing accounts
+func (s *ledgerService) PlanPostings(ctx context.Context, transferType models.TransferType, transaction *models.Transaction, sourceCurrency, destinationCurrency string) ([]models.Posting, error) {
+	rules, err := s.rules.ListPostingRules(ctx, transferType)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		s.log.WarnContext(ctx, "No posting rules configured for transfer type", "transfer_type", transferType)
+		return nil, fmt.Errorf("%w: transfer type %s", errors.ErrPostingRulesNotFound, transferType)
+	}
+
+	postings := models.ApplyPostingTemplate(rules, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount)
+	if err := s.resolvePostings(ctx, postings); err != nil {
+		return nil, err
+	}
+	postings = models.ConvertPostings(postings, transaction.DestinationAccountID, sourceCurrency, destinationCurrency, transaction.ConvertedAmount)
+	if transaction.ConvertedAmount != nil {
+		// The legs split through fx_position post to it
+		if err := s.resolvePostings(ctx, postings); err != nil {
+			return nil, err
+		}
+	}
+	return postings, nil
+}
+
+// resolvePostings checks the ledger accounts postings are made against are active and sets their
+// backing accounts
+func (s *ledgerService) resolvePostings(ctx context.Context, postings []models.Posting) error {
+	for i := range postings {
+		for _, target := range []*models.PostingTarget{&postings[i].Debit, &postings[i].Credit} {
+			if target.LedgerCode == "" {
+				continue
+			}
+			account, err := s.GetPostableLedgerAccount(ctx, target.LedgerCode)
+			if err != nil {
+				return err
+			}
+			target.AccountID = account.AccountID
+		}
+	}
+	return nil
+}
+
+// GetTransactionEntries retrieves the ledger entries posted for a transaction by leg, debits first
+func (s *ledgerService) GetTransactionEntries(ctx context.Context, transactionID int64) ([]*models.LedgerEntry, error) {
+	return s.entries.GetEntriesByTransaction(ctx, transactionID)
+}
+
+// GetLedgerBalance derives an account's balance from its ledger entries
+func (s *ledgerService) GetLedgerBalance(ctx context.Context, accountID int64) (*models.LedgerBalance, error) {
+	return s.entries.GetLedgerBalance(ctx, accountID)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// WithLedgerEntries posts every completed transfer to the double-entry ledger, in the same
+// database transaction that moves the balances, by the posting template of its transfer type
+// planned by postings
+func WithLedgerEntries(postings LedgerService, ledger repository.LedgerRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.postings = postings
+		s.ledger = ledger
+	}
+}
+
+// postTransferWithTx records the entries of a completed transfer of a transfer type from source to
+// dest within tx, a debit and a credit per leg of its posting template. A template that doesn't
+// move the balances the transfer moved is rejected, so entries and balances can't diverge.
+func (s *transactionService) postTransferWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction, transferType models.TransferType, source, dest *models.Account) error {
+	if s.ledger == nil {
+		return nil
+	}
+	postings, err := s.postings.PlanPostings(ctx, transferType, transaction, source.Currency, dest.Currency)
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to plan the postings of transaction", logger.TransactionID(transaction.ID), "transfer_type", transferType, "err", err)
+		return err
+	}
+	entries := models.PostingEntries(transaction.ID, transferType, postings)
+	if err := models.CheckPostedBalances(entries, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount, transaction.CreditAmount()); err != nil {
+		s.log.WarnContext(ctx, "Posting template doesn't match transaction", logger.TransactionID(transaction.ID), "transfer_type", transferType, "err", err)
+		return err
+	}
+	if err := s.ledger.RecordEntriesWithTx(ctx, tx, entries); err != nil {
+		s.log.ErrorContext(ctx, "Failed to post transaction to the ledger", logger.TransactionID(transaction.ID), "err", err)
+		return err
+	}
+	return nil
+}
+
+// postingType returns the transfer type a transfer made with opts is posted under
+func postingType(transaction *models.Transaction, opts transferOptions) models.TransferType {
+	switch {
+	case opts.adjustment:
+		return models.TransferTypeAdjustment
+	case transaction.ReversalOf != 0:
+		return models.TransferTypeReversal
+	}
+	return models.TransferTypeStandard
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/fx"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/khamiruf/internal_transfers_system_go/internal/verify"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerPosting(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	entries := repository.NewLedgerRepository(db)
+	ledger := NewLedgerService(repository.NewLedgerAccountRepository(db), repository.NewPostingRuleRepository(db), entries)
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db, WithLedgerEntries(ledger, entries))
+	transfer := func() (*dto.TransactionResponse, error) {
+		return svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	}
+
+	// The default template posts straight from the source to the destination
+	made, err := transfer()
+	require.NoError(t, err)
+	posted, err := ledger.GetTransactionEntries(ctx, made.ID)
+	require.NoError(t, err)
+	require.Len(t, posted, 2)
+	assert.Equal(t, int64(1), posted[0].AccountID)
+	assert.Equal(t, int64(2), posted[1].AccountID)
+
+	// A template through customer_deposits records its legs
+	_, err = ledger.SetPostingRules(ctx, models.TransferTypeStandard, []*models.PostingRule{
+		{Leg: 1, Debit: models.PostingPartySource, Credit: "customer_deposits"},
+		{Leg: 2, Debit: "customer_deposits", Credit: models.PostingPartyDestination},
+	})
+	require.NoError(t, err)
+	made, err = transfer()
+	require.NoError(t, err)
+	posted, err = ledger.GetTransactionEntries(ctx, made.ID)
+	require.NoError(t, err)
+	require.Len(t, posted, 4)
+	assert.Equal(t, int64(1), posted[0].AccountID)
+	assert.Equal(t, "customer_deposits", posted[1].LedgerCode)
+	assert.Equal(t, 2, posted[2].Leg)
+	assert.Equal(t, "customer_deposits", posted[2].LedgerCode)
+	assert.Equal(t, int64(2), posted[3].AccountID)
+
+	// A template that doesn't move the transfer's balances rolls the transfer back
+	_, err = ledger.SetPostingRules(ctx, models.TransferTypeStandard, []*models.PostingRule{
+		{Leg: 1, Debit: models.PostingPartySource, Credit: "suspense"},
+	})
+	require.NoError(t, err)
+	_, err = transfer()
+	assert.ErrorIs(t, err, errors.ErrPostingMismatch)
+	account, err := accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(80).Equal(account.Balance), "expected 80, got %s", account.Balance)
+
+	report, err := verify.NewChecker(db).Run(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
+
+func TestLedgerPosting_ChartAccounts(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	entries := repository.NewLedgerRepository(db)
+	ledger := NewLedgerService(repository.NewLedgerAccountRepository(db), repository.NewPostingRuleRepository(db), entries)
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db, WithLedgerEntries(ledger, entries))
+	transfer := func() (*dto.TransactionResponse, error) {
+		return svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	}
+	_, err := ledger.SetPostingRules(ctx, models.TransferTypeStandard, []*models.PostingRule{
+		{Leg: 1, Debit: models.PostingPartySource, Credit: "fee_income"},
+	})
+	require.NoError(t, err)
+
+	// A ledger account is posted against the account backing it
+	_, err = transfer()
+	assert.ErrorIs(t, err, errors.ErrPostingMismatch, "fee_income isn't backed yet")
+	_, err = ledger.UpdateLedgerAccount(ctx, "fee_income", &dto.UpdateLedgerAccountRequest{Name: "Fee income", AccountID: 2})
+	require.NoError(t, err)
+	made, err := transfer()
+	require.NoError(t, err)
+	posted, err := ledger.GetTransactionEntries(ctx, made.ID)
+	require.NoError(t, err)
+	require.Len(t, posted, 2)
+	assert.Equal(t, "fee_income", posted[1].LedgerCode)
+	assert.Equal(t, int64(2), posted[1].AccountID)
+
+	// Transfers posting to a deactivated ledger account are rejected
+	require.NoError(t, ledger.DeactivateLedgerAccount(ctx, "fee_income"))
+	_, err = transfer()
+	assert.ErrorIs(t, err, errors.ErrLedgerAccountInactive)
+	account, err := accountRepo.GetAccount(ctx, 2)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(account.Balance), "expected 10, got %s", account.Balance)
+}
+
+func TestLedgerPosting_CrossCurrency(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	require.NoError(t, accountRepo.SetCurrency(ctx, 1, "USD"))
+	require.NoError(t, accountRepo.SetCurrency(ctx, 2, "EUR"))
+	rates, err := fx.NewStaticRates(map[string]string{"USD/EUR": "0.9"})
+	require.NoError(t, err)
+	entries := repository.NewLedgerRepository(db)
+	ledger := NewLedgerService(repository.NewLedgerAccountRepository(db), repository.NewPostingRuleRepository(db), entries)
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db, WithLedgerEntries(ledger, entries), WithFXRates(rates))
+
+	// The leg is split through fx_position, so each currency balances
+	made, err := svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	posted, err := ledger.GetTransactionEntries(ctx, made.ID)
+	require.NoError(t, err)
+	require.Len(t, posted, 4)
+	for i, want := range []struct {
+		leg        int
+		accountID  int64
+		ledgerCode string
+		amount     string
+		currency   string
+	}{
+		{1, 1, "", "10", "USD"},
+		{1, 0, models.LedgerCodeFXPosition, "10", "USD"},
+		{2, 0, models.LedgerCodeFXPosition, "9", "EUR"},
+		{2, 2, "", "9", "EUR"},
+	} {
+		assert.Equal(t, want.leg, posted[i].Leg, i)
+		assert.Equal(t, want.accountID, posted[i].AccountID, i)
+		assert.Equal(t, want.ledgerCode, posted[i].LedgerCode, i)
+		assert.True(t, decimal.RequireFromString(want.amount).Equal(posted[i].Amount), "entry %d: expected %s, got %s", i, want.amount, posted[i].Amount)
+		assert.Equal(t, want.currency, posted[i].Currency, i)
+	}
+
+	// The reversal converts back through fx_position too
+	_, err = svc.ReverseTransaction(ctx, made.ID)
+	require.NoError(t, err)
+
+	report, err := verify.NewChecker(db).Run(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
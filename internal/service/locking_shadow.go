@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// lockingShadowConfig configures the shadow run of the row-locking transfer path
+type lockingShadowConfig struct {
+	repo    repository.LockingShadowRepository
+	metrics *metrics.LockingShadow
+}
+
+// WithLockingShadow shadow-runs every transfer on the row-locking path of repo in dry run, within
+// the transfer's own database transaction so both paths see the same balances, and compares
+// the outcomes, logging and counting in m where they diverge. The current path stays the one
+// that moves money; the shadow only adds its row locks, held until its dry run is undone.
+func WithLockingShadow(repo repository.LockingShadowRepository, m *metrics.LockingShadow) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.lockingShadow = &lockingShadowConfig{repo: repo, metrics: m}
+	}
+}
+
+// shadowRun is the outcome of a transfer on the row-locking path, to be compared with the
+// outcome of the current path once it is known
+type shadowRun struct {
+	cfg                 *lockingShadowConfig
+	sourceID, destID    int64
+	transfer            *models.ShadowTransfer
+	err                 error
+	sourceBalance       decimal.Decimal
+	destBalance         decimal.Decimal
+	expected            bool // the current path computed the balances above
+	destBalanceExpected bool // the credit landed unconverted on the requested destination
+	log                 *slog.Logger
+}
+
+// shadowTransferWithTx dry-runs a transfer of debit out of sourceID and amount into destID on the
+// row-locking path within tx. It returns nil if no shadow is configured; the float account
+// funding the system, which may go negative, isn't shadowed either.
+func (s *transactionService) shadowTransferWithTx(ctx context.Context, tx *sql.Tx, sourceID, destID int64, debit, amount decimal.Decimal, fromFloat bool) *shadowRun {
+	if s.lockingShadow == nil || fromFloat {
+		return nil
+	}
+	transfer, err := s.lockingShadow.repo.DryRunTransferWithTx(ctx, tx, sourceID, destID, debit, amount)
+	return &shadowRun{cfg: s.lockingShadow, sourceID: sourceID, destID: destID, transfer: transfer, err: err, log: s.log}
+}
+
+// expect records the balances the current path is about to write. The destination balance is
+// only compared if the credit went unconverted to the requested destination, as it did on the
+// row-locking path.
+func (r *shadowRun) expect(sourceBalance, destBalance decimal.Decimal, compareDest bool) {
+	if r == nil {
+		return
+	}
+	r.sourceBalance, r.destBalance = sourceBalance, destBalance
+	r.expected, r.destBalanceExpected = true, compareDest
+}
+
+// compare compares the outcome err of the current path with the shadow's. Only outcomes the
+// row-locking path decides are compared: acceptance, insufficient balance and a missing
+// destination. A shadow that failed for another reason can't be compared.
+func (r *shadowRun) compare(err error) {
+	if r == nil {
+		return
+	}
+	shadowCode := ""
+	if r.err != nil {
+		shadowCode = domainErrors.Code(r.err)
+	}
+	if !shadowDecides(shadowCode) {
+		r.log.Warn("Locking shadow of transfer failed", "source_account_id", r.sourceID, "destination_account_id", r.destID, "err", r.err)
+		r.cfg.metrics.Uncompared(true)
+		return
+	}
+	code := ""
+	if err != nil {
+		code = domainErrors.Code(err)
+	}
+	if !shadowDecides(code) || (err == nil && !r.expected) {
+		r.cfg.metrics.Uncompared(false)
+		return
+	}
+
+	switch {
+	case code != shadowCode:
+		r.log.Warn("Locking shadow diverged on outcome", "source_account_id", r.sourceID, "destination_account_id", r.destID,
+			"current_outcome", outcomeName(code), "shadow_outcome", outcomeName(shadowCode))
+		r.cfg.metrics.Diverge(metrics.DivergenceOutcome)
+	case err == nil && (!r.transfer.SourceBalance.Equal(r.sourceBalance) ||
+		(r.destBalanceExpected && !r.transfer.DestinationBalance.Equal(r.destBalance))):
+		r.log.Warn("Locking shadow diverged on balances", "source_account_id", r.sourceID, "destination_account_id", r.destID,
+			"source_balance", r.sourceBalance, "destination_balance", r.destBalance,
+			"shadow_source_balance", r.transfer.SourceBalance, "shadow_destination_balance", r.transfer.DestinationBalance)
+		r.cfg.metrics.Diverge(metrics.DivergenceBalance)
+	default:
+		r.cfg.metrics.Match()
+	}
+}
+
+// shadowDecides reports whether code is an outcome the row-locking path decides
+func shadowDecides(code string) bool {
+	switch code {
+	case "", "insufficient_balance", "destination_account_not_found":
+		return true
+	}
+	return false
+}
+
+// outcomeName names an outcome code for the divergence log
+func outcomeName(code string) string {
+	if code == "" {
+		return "accepted"
+	}
+	return "rejected with " + code
+}
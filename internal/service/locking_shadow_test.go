@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowRun_Compare(t *testing.T) {
+	m := metrics.NewLockingShadow()
+	cfg := &lockingShadowConfig{metrics: m}
+	accepted := func(source, dest int64) *shadowRun {
+		return &shadowRun{cfg: cfg, log: logger.Default(), sourceID: 1, destID: 2, transfer: &models.ShadowTransfer{
+			SourceBalance:      decimal.NewFromInt(source),
+			DestinationBalance: decimal.NewFromInt(dest),
+		}}
+	}
+	insufficient := domainErrors.NewInsufficientBalanceError(1, decimal.NewFromInt(50), decimal.NewFromInt(20))
+
+	// Both accept with the same balances
+	run := accepted(70, 40)
+	run.expect(decimal.NewFromInt(70), decimal.NewFromInt(40), true)
+	run.compare(nil)
+
+	// Both reject for insufficient balance
+	(&shadowRun{cfg: cfg, log: logger.Default(), err: insufficient}).compare(insufficient)
+
+	// Different balances; the destination is only compared if the credit wasn't redirected
+	run = accepted(70, 40)
+	run.expect(decimal.NewFromInt(70), decimal.NewFromInt(41), true)
+	run.compare(nil)
+	run = accepted(70, 40)
+	run.expect(decimal.NewFromInt(70), decimal.NewFromInt(41), false)
+	run.compare(nil)
+
+	// One path accepts, the other rejects
+	accepted(70, 40).compare(insufficient)
+
+	// Rejections the row-locking path doesn't decide, and a failed dry run
+	accepted(70, 40).compare(domainErrors.NewAccountFrozenError(2))
+	(&shadowRun{cfg: cfg, log: logger.Default(), err: errors.New("connection reset")}).compare(nil)
+
+	var none *shadowRun
+	none.expect(decimal.Zero, decimal.Zero, true)
+	none.compare(nil)
+
+	assert.Equal(t, metrics.LockingShadowStats{
+		Compared:    5,
+		Matched:     3,
+		Diverged:    2,
+		Divergences: map[string]uint64{metrics.DivergenceOutcome: 1, metrics.DivergenceBalance: 1},
+		Uncompared:  2,
+		Failed:      1,
+	}, m.Stats())
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// WithMinimumBalances enforces a minimum maintained balance per account type. This is separate
+// from the insufficient balance check: a transfer may not take the source below the minimum of
+// its type, even when the balance covers the amount. Overrides are recorded in audit.
+func WithMinimumBalances(minimums map[models.AccountType]decimal.Decimal, audit repository.AuditRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.minimumBalances = minimums
+		s.audit = audit
+	}
+}
+
+// checkMinimumBalance checks the source keeps the minimum balance of its account type after
+// sending amount; funds reserved by pre-authorizations don't count towards it. It reports whether override had to be used to allow the transfer.
+func (s *transactionService) checkMinimumBalance(source *models.Account, amount decimal.Decimal, override *models.MinimumBalanceOverride) (bool, error) {
+	minimum, ok := s.minimumBalances[source.Type]
+	if !ok || !source.AvailableBalance().Sub(amount).LessThan(minimum) {
+		return false, nil
+	}
+	if override == nil {
+		s.log.Warn("Minimum balance breach", logger.AccountID(source.AccountID), "type", source.Type, "balance", source.AvailableBalance(), "amount", amount, "minimum", minimum)
+		return false, domainErrors.NewMinimumBalanceError(source.AccountID, amount, source.AvailableBalance(), minimum)
+	}
+	s.log.Warn("Minimum balance of account overridden", logger.AccountID(source.AccountID), "actor", override.Actor)
+	return true, nil
+}
+
+// recordMinimumBalanceOverrideWithTx records in audit that created took source below its minimum balance
+func (s *transactionService) recordMinimumBalanceOverrideWithTx(ctx context.Context, tx *sql.Tx, created *models.Transaction, source *models.Account, override *models.MinimumBalanceOverride) error {
+	return s.audit.RecordWithTx(ctx, tx, &models.AuditEntry{
+		Action:        models.AuditActionMinimumBalanceOverride,
+		Actor:         override.Actor,
+		AccountID:     source.AccountID,
+		TransactionID: created.ID,
+		Details: map[string]string{
+			"account_type":      string(source.Type),
+			"minimum_balance":   s.minimumBalances[source.Type].String(),
+			"resulting_balance": source.Balance.Sub(created.Amount).String(),
+			"reason":            override.Reason,
+		},
+	})
+}
+
+// CreateAdminTransaction processes a transaction on behalf of an operator. A non-nil override
+// allows the transfer to take the source account below the minimum balance of its type; it only
+// applies when it is needed and is then recorded in audit with the operator and reason.
+func (s *transactionService) CreateAdminTransaction(ctx context.Context, req *dto.CreateTransactionRequest, override *models.MinimumBalanceOverride) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if override != nil {
+		s.log.InfoContext(ctx, "Processing transaction with minimum balance override", "actor", override.Actor)
+		if strings.TrimSpace(override.Actor) == "" || len(override.Actor) > models.MaxAuditActorLength {
+			return nil, fmt.Errorf("%w: actor must be 1-%d characters", domainErrors.ErrValidationFailed, models.MaxAuditActorLength)
+		}
+		if strings.TrimSpace(override.Reason) == "" {
+			return nil, fmt.Errorf("%w: a reason is required to override the minimum balance", domainErrors.ErrValidationFailed)
+		}
+	}
+
+	transaction := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Status:               models.TransactionStatusPending,
+	}
+	return s.transfer(ctx, transaction, transferOptions{minimumBalanceOverride: override})
+}
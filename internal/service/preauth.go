@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// preAuthConfig is the store of pre-authorizations and how long they stay valid
+type preAuthConfig struct {
+	repo     repository.PreAuthorizationRepository
+	validity time.Duration
+}
+
+// WithPreAuthorizations enables two-step transfers: a pre-authorization reserves the amount on the
+// source for validity, and executing it before then transfers the reserved funds. Reserved funds
+// can't be spent by other transfers; the sweeper releases them once the pre-authorization expires.
+func WithPreAuthorizations(repo repository.PreAuthorizationRepository, validity time.Duration) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.preAuth = &preAuthConfig{repo: repo, validity: validity}
+	}
+}
+
+// preAuthRepo returns the pre-authorization store, or an error if pre-authorizations are disabled
+func (s *transactionService) preAuthRepo() (repository.PreAuthorizationRepository, error) {
+	if s.preAuth == nil {
+		return nil, fmt.Errorf("%w: pre-authorizations are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.preAuth.repo, nil
+}
+
+// PreAuthorizeTransfer validates a transfer as if it were executed now and reserves its amount on
+// the source account. The returned pre-authorization must be executed before it expires.
+func (s *transactionService) PreAuthorizeTransfer(ctx context.Context, req *dto.CreateTransactionRequest) (*models.PreAuthorization, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+	if err := rejectDeferredOnBehalfOf(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Pre-authorizing transfer", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount)
+
+	repo, err := s.preAuthRepo()
+	if err != nil {
+		return nil, err
+	}
+	transaction := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Status:               models.TransactionStatusPending,
+	}
+	if err := transaction.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Pre-authorization validation failed", "err", err)
+		return nil, err
+	}
+
+	var created *models.PreAuthorization
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		sourceID, destID, amount := req.SourceAccountID, req.DestinationAccountID, req.Amount
+
+		sourceAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, sourceID)
+		if err != nil {
+			if errors.Is(err, domainErrors.ErrAccountNotFound) {
+				return domainErrors.NewSourceAccountNotFoundError(sourceID)
+			}
+			return err
+		}
+		if sourceAccount.IsFrozen() {
+			s.log.WarnContext(ctx, "Source account is frozen", "source_account_id", sourceID)
+			return domainErrors.NewAccountFrozenError(sourceID)
+		}
+		if s.blockDormant && sourceAccount.IsDormant() && !s.isSuspenseAccount(sourceID) {
+			s.log.WarnContext(ctx, "Source account is dormant", "source_account_id", sourceID)
+			return domainErrors.NewAccountDormantError(sourceID)
+		}
+		if err := s.checkAmountForCurrency(ctx, sourceAccount, amount); err != nil {
+			return err
+		}
+		if !sourceAccount.HasSufficientBalance(amount) {
+			s.log.WarnContext(ctx, "Insufficient available balance to pre-authorize", "source_account_id", sourceID, "available_balance", sourceAccount.AvailableBalance(), "required_amount", amount)
+			return domainErrors.NewInsufficientBalanceError(sourceID, amount, sourceAccount.AvailableBalance())
+		}
+		if _, err := s.checkMinimumBalance(sourceAccount, amount, nil); err != nil {
+			return err
+		}
+
+		destAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, destID)
+		if err != nil {
+			if errors.Is(err, domainErrors.ErrAccountNotFound) {
+				return domainErrors.NewDestinationAccountNotFoundError(destID)
+			}
+			return err
+		}
+		if !destAccount.CanReceiveCredits() {
+			s.log.WarnContext(ctx, "Destination account is not active", "destination_account_id", destID, "status", destAccount.Status)
+			if destAccount.IsFrozen() {
+				return domainErrors.NewAccountFrozenError(destID)
+			}
+			return domainErrors.NewAccountNotActiveError(destID)
+		}
+		if !isCrossCurrency(sourceAccount, destAccount) {
+			// A cross-currency capture is converted to the destination's minor unit
+			if err := s.checkAmountForCurrency(ctx, destAccount, amount); err != nil {
+				return err
+			}
+		}
+
+		if err := s.accountRepo.UpdateReservedWithTx(ctx, tx, sourceID, sourceAccount.Reserved.Add(amount)); err != nil {
+			return err
+		}
+		created, err = repo.CreatePreAuthorizationWithTx(ctx, tx, &models.PreAuthorization{
+			SourceAccountID:      sourceID,
+			DestinationAccountID: destID,
+			Amount:               amount,
+			ExpiresAt:            s.now().Add(s.preAuth.validity).Format(time.RFC3339),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Pre-authorization created", "authorization_id", created.ID, "source_account_id", created.SourceAccountID, "amount", created.Amount, "expires_at", created.ExpiresAt)
+	return created, nil
+}
+
+// GetPreAuthorization retrieves a pre-authorization by its ID
+func (s *transactionService) GetPreAuthorization(ctx context.Context, preAuthID int64) (*models.PreAuthorization, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.preAuthRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetPreAuthorization(ctx, preAuthID)
+}
+
+// ExecutePreAuthorization releases the reservation of an active, unexpired pre-authorization and
+// transfers its amount in the same database transaction. The pre-authorization is locked, so it
+// is executed at most once; a failed transfer leaves it active.
+func (s *transactionService) ExecutePreAuthorization(ctx context.Context, preAuthID int64) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Executing pre-authorization", "pre_auth_id", preAuthID)
+
+	repo, err := s.preAuthRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var createdTx *models.Transaction
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		preAuth, err := repo.GetPreAuthorizationForUpdateWithTx(ctx, tx, preAuthID)
+		if err != nil {
+			return err
+		}
+		if !preAuth.IsActive() {
+			s.log.WarnContext(ctx, "Pre-authorization is already resolved", "pre_auth_id", preAuthID, "status", preAuth.Status)
+			return fmt.Errorf("%w: pre-authorization %d is %s", domainErrors.ErrPreAuthorizationNotActive, preAuthID, preAuth.Status)
+		}
+		if preAuth.IsExpiredAt(s.now()) {
+			s.log.WarnContext(ctx, "Pre-authorization expired", "pre_auth_id", preAuthID, "expires_at", preAuth.ExpiresAt)
+			return fmt.Errorf("%w: pre-authorization %d expired at %s", domainErrors.ErrPreAuthorizationNotActive, preAuthID, preAuth.ExpiresAt)
+		}
+
+		if err := s.releaseReservationWithTx(ctx, tx, preAuth); err != nil {
+			return err
+		}
+		transaction := &models.Transaction{
+			SourceAccountID:      preAuth.SourceAccountID,
+			DestinationAccountID: preAuth.DestinationAccountID,
+			Amount:               preAuth.Amount,
+			Status:               models.TransactionStatusPending,
+		}
+		if err := transaction.Validate(); err != nil {
+			return err
+		}
+		if createdTx, err = s.transferWithTx(ctx, tx, transaction, transferOptions{}); err != nil {
+			return err
+		}
+		return repo.ResolvePreAuthorizationWithTx(ctx, tx, preAuthID, models.PreAuthorizationStatusExecuted, createdTx.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Pre-authorization executed by transaction", "pre_auth_id", preAuthID, logger.TransactionID(createdTx.ID))
+	return createdTx, nil
+}
+
+// ExpirePreAuthorizations releases the reservations of up to limit active pre-authorizations past
+// their expiry and marks them expired. It returns the number expired. A
+// preauthorization.expired event is published for each once the batch is committed.
+func (s *transactionService) ExpirePreAuthorizations(ctx context.Context, limit int) (int, error) {
+	repo, err := s.preAuthRepo()
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []*models.PreAuthorization
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		preAuths, err := repo.ListExpiredForUpdateWithTx(ctx, tx, s.now(), limit)
+		if err != nil {
+			return err
+		}
+		resolvedAt := s.now().Format(time.RFC3339)
+		for _, preAuth := range preAuths {
+			if err := s.releaseReservationWithTx(ctx, tx, preAuth); err != nil {
+				return err
+			}
+			if err := repo.ResolvePreAuthorizationWithTx(ctx, tx, preAuth.ID, models.PreAuthorizationStatusExpired, 0); err != nil {
+				return err
+			}
+			preAuth.Status, preAuth.ResolvedAt = models.PreAuthorizationStatusExpired, resolvedAt
+			if err := s.raiseEventWithTx(ctx, tx, models.EventPreAuthorizationExpired, preAuth); err != nil {
+				return err
+			}
+		}
+		expired = preAuths
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(expired) > 0 {
+		s.log.InfoContext(ctx, "Expired pre-authorizations", "count", len(expired))
+	}
+	return len(expired), nil
+}
+
+// releaseReservationWithTx returns the amount reserved by preAuth to the available balance of its source
+func (s *transactionService) releaseReservationWithTx(ctx context.Context, tx *sql.Tx, preAuth *models.PreAuthorization) error {
+	source, err := s.accountRepo.GetAccountWithTx(ctx, tx, preAuth.SourceAccountID)
+	if err != nil {
+		return err
+	}
+	reserved := source.Reserved.Sub(preAuth.Amount)
+	if reserved.IsNegative() {
+		s.log.WarnContext(ctx, "Reserved balance of account is below pre-authorization amount", logger.AccountID(source.AccountID), "authorization_id", preAuth.ID, "reserved_balance", source.Reserved.String(), "amount", preAuth.Amount)
+		reserved = decimal.Zero
+	}
+	return s.accountRepo.UpdateReservedWithTx(ctx, tx, source.AccountID, reserved)
+}
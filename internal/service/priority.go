@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+)
+
+// WithPriorityLanes bounds the transfers the service makes at once by lanes. Each transfer waits
+// for a slot in the lane of the priority class of its context; batch items default to the bulk
+// lane, so a large import can't hold up settlement-critical transfers.
+func WithPriorityLanes(lanes *priority.Lanes) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.lanes = lanes
+	}
+}
+
+// inLane runs fn once the priority lanes admit the transfer ctx is for
+func (s *transactionService) inLane(ctx context.Context, fn func() error) error {
+	release, err := s.lanes.Acquire(ctx)
+	if err != nil {
+		s.log.WarnContext(ctx, "Gave up waiting for a transfer slot", "priority", priority.FromContext(ctx), "err", err)
+		return err
+	}
+	defer release()
+	return fn()
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// ReverseTransaction reverses a completed transfer with a compensating transfer of the same amount
+// from the original destination back to the original source. A cross-currency transfer is reversed
+// at the inverse of its original rate: the destination returns what it was credited and the
+// source gets back exactly what it sent. The original is marked reversed and
+// the compensating transaction records it in ReversalOf; both happen in one database transaction,
+// so a transfer is reversed at most once. The compensating transfer goes through the same checks
+// as any other, so it fails with insufficient balance if the original destination has since
+// spent the money.
+func (s *transactionService) ReverseTransaction(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Reversing transaction", logger.TransactionID(transactionID))
+
+	var reversal *models.Transaction
+	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
+		original, err := s.transactionRepo.MarkTransactionReversedWithTx(ctx, tx, transactionID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkReversalsAllowedWithTx(ctx, tx, original); err != nil {
+			return err
+		}
+		if err := s.recordStatusChangeWithTx(ctx, tx, original.ID, models.TransactionStatusComplete, original.Status); err != nil {
+			return err
+		}
+		compensating := &models.Transaction{
+			SourceAccountID:      original.DestinationAccountID,
+			DestinationAccountID: original.SourceAccountID,
+			Amount:               original.CreditAmount(),
+			Status:               models.TransactionStatusPending,
+			ReversalOf:           original.ID,
+		}
+		if err := compensating.Validate(); err != nil {
+			return err
+		}
+		reversal, err = s.transferWithTx(ctx, tx, compensating, transferOptions{conversion: reversalConversion(original)})
+		return err
+	})
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to reverse transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Reversed transaction", logger.TransactionID(transactionID), "reversal_id", reversal.ID)
+	return reversal, nil
+}
+
+// checkReversalsAllowedWithTx checks the tenant of the original source account doesn't switch
+// reversals off
+func (s *transactionService) checkReversalsAllowedWithTx(ctx context.Context, tx *sql.Tx, original *models.Transaction) error {
+	source, err := s.accountRepo.GetAccountWithTx(ctx, tx, original.SourceAccountID)
+	if err != nil {
+		return err
+	}
+	settings, err := s.tenantSettings(ctx, source)
+	if err != nil {
+		return err
+	}
+	if enabled, ok := settings.Feature(models.FeatureReversals); ok && !enabled {
+		s.log.WarnContext(ctx, "Tenant doesn't allow reversals", "tenant_id", source.TenantID, logger.TransactionID(original.ID))
+		return fmt.Errorf("%w: tenant %s doesn't allow reversals", domainErrors.ErrTransactionNotReversible, source.TenantID)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/worker"
+)
+
+// maxScheduledRetryDelay caps the backoff between attempts of a scheduled transfer
+const maxScheduledRetryDelay = 24 * time.Hour
+
+// Bounds on the number of scheduled transfers listed
+const (
+	defaultScheduledLimit = 100
+	maxScheduledLimit     = 1000
+)
+
+// scheduledConfig is the store of scheduled transfers and how failed attempts are retried
+type scheduledConfig struct {
+	repo   repository.ScheduledTransferRepository
+	policy worker.Policy
+}
+
+// WithScheduledTransfers enables transfers made at a later time. Due transfers are made by
+// ExecuteDueScheduledTransfers; a failed attempt is retried after retryDelay, doubling with each
+// further failure up to a day, and the transfer is marked failed, and dead-lettered, after
+// maxAttempts attempts.
+func WithScheduledTransfers(repo repository.ScheduledTransferRepository, maxAttempts int, retryDelay time.Duration) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.scheduled = &scheduledConfig{repo: repo, policy: worker.NewPolicy(maxAttempts, retryDelay, maxScheduledRetryDelay)}
+	}
+}
+
+// scheduledRepo returns the scheduled transfer store, or an error if scheduled transfers are disabled
+func (s *transactionService) scheduledRepo() (repository.ScheduledTransferRepository, error) {
+	if s.scheduled == nil {
+		return nil, fmt.Errorf("%w: scheduled transfers are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.scheduled.repo, nil
+}
+
+// ScheduleTransfer records a transfer to be made at executeAt, which must be in the future.
+// Balances are only checked when the transfer is made.
+func (s *transactionService) ScheduleTransfer(ctx context.Context, req *dto.CreateTransactionRequest, executeAt time.Time) (*models.ScheduledTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+	if err := rejectDeferredOnBehalfOf(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Scheduling transfer", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount, "execute_at", executeAt.Format(time.RFC3339))
+
+	repo, err := s.scheduledRepo()
+	if err != nil {
+		return nil, err
+	}
+	transaction := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Status:               models.TransactionStatusPending,
+	}
+	if err := transaction.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Scheduled transfer validation failed", "err", err)
+		return nil, err
+	}
+	if now := s.now(); !executeAt.After(now) {
+		s.log.WarnContext(ctx, "Scheduled transfer execution time is not in the future", "execute_at", executeAt.Format(time.RFC3339), "now", now.Format(time.RFC3339))
+		return nil, fmt.Errorf("%w: execute_at must be in the future", domainErrors.ErrValidationFailed)
+	}
+
+	return repo.CreateScheduledTransfer(ctx, &models.ScheduledTransfer{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		ExecuteAt:            executeAt.Format(time.RFC3339),
+	})
+}
+
+// GetScheduledTransfer retrieves a scheduled transfer by its ID
+func (s *transactionService) GetScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.scheduledRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetScheduledTransfer(ctx, transferID)
+}
+
+// ListScheduledTransfers lists up to limit scheduled transfers with the given status, or of any
+// status if it is empty, by execution time
+func (s *transactionService) ListScheduledTransfers(ctx context.Context, status models.ScheduledTransferStatus, limit int) ([]*models.ScheduledTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.scheduledRepo()
+	if err != nil {
+		return nil, err
+	}
+	if status != "" && !status.IsValid() {
+		s.log.WarnContext(ctx, "Invalid scheduled transfer status", "status", status)
+		return nil, fmt.Errorf("%w: invalid status %q", domainErrors.ErrValidationFailed, status)
+	}
+	if limit <= 0 {
+		limit = defaultScheduledLimit
+	} else if limit > maxScheduledLimit {
+		limit = maxScheduledLimit
+	}
+	return repo.ListScheduledTransfers(ctx, status, limit)
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer that hasn't been made yet. A transfer
+// being executed at the same time is locked, so the cancellation waits and then fails.
+func (s *transactionService) CancelScheduledTransfer(ctx context.Context, transferID int64) (*models.ScheduledTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.scheduledRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.CancelScheduledTransfer(ctx, transferID)
+}
+
+// ExecuteDueScheduledTransfers makes up to limit scheduled transfers that are due on the service
+// clock and returns the number attempted. Each is made in its own database transaction like any
+// other transfer, fees included; a rejected attempt is recorded on the scheduled transfer and
+// retried later, so one failing transfer doesn't hold up the rest.
+func (s *transactionService) ExecuteDueScheduledTransfers(ctx context.Context, limit int) (int, error) {
+	repo, err := s.scheduledRepo()
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for attempted < limit {
+		var claimed *models.ScheduledTransfer
+		err := s.inLane(ctx, func() error {
+			return s.withTransaction(ctx, func(tx *sql.Tx) error {
+				var err error
+				if claimed, err = repo.ClaimDueWithTx(ctx, tx, s.now()); err != nil || claimed == nil {
+					return err
+				}
+				transaction := &models.Transaction{
+					SourceAccountID:      claimed.SourceAccountID,
+					DestinationAccountID: claimed.DestinationAccountID,
+					Amount:               claimed.Amount,
+					Status:               models.TransactionStatusPending,
+				}
+				if err := transaction.Validate(); err != nil {
+					return err
+				}
+				made, err := s.transferWithTx(ctx, tx, transaction, transferOptions{chargeFees: true})
+				if err != nil {
+					return err
+				}
+				return repo.MarkExecutedWithTx(ctx, tx, claimed.ID, made.ID)
+			})
+		})
+		if claimed == nil {
+			// Nothing left to claim, or the claim itself failed
+			return attempted, err
+		}
+		attempted++
+		if err != nil {
+			if err := s.recordScheduledFailure(ctx, claimed, err); err != nil {
+				return attempted, err
+			}
+		}
+	}
+	return attempted, nil
+}
+
+// recordScheduledFailure records a failed attempt of transfer and schedules its retry, or
+// dead-letters the transfer once its attempts ran out
+func (s *transactionService) recordScheduledFailure(ctx context.Context, transfer *models.ScheduledTransfer, attemptErr error) error {
+	policy := s.scheduled.policy
+	nextAttemptAt := s.now().Add(policy.Backoff(transfer.Attempts + 1))
+
+	var updated *models.ScheduledTransfer
+	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		updated, err = s.scheduled.repo.RecordFailureWithTx(ctx, tx, transfer.ID, attemptErr.Error(), nextAttemptAt, policy.MaxAttempts)
+		if err != nil || updated.Status != models.ScheduledTransferStatusFailed {
+			return err
+		}
+		return s.deadLetterWithTx(ctx, tx, models.DeadLetterKindScheduledTransfer, updated.ID, updated.Attempts, updated.LastError, updated)
+	})
+	if err != nil {
+		return err
+	}
+	if updated.Status == models.ScheduledTransferStatusFailed {
+		s.log.ErrorContext(ctx, "Scheduled transfer failed, giving up", logger.TransactionID(transfer.ID), "attempts", updated.Attempts, "err", attemptErr)
+	} else {
+		s.log.WarnContext(ctx, "Scheduled transfer attempt failed, retrying", logger.TransactionID(transfer.ID), "attempts", updated.Attempts, "next_attempt_at", updated.NextAttemptAt, "err", attemptErr)
+	}
+	return nil
+}
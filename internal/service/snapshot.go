@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// maxSnapshotAccounts bounds the number of accounts read in one snapshot
+const maxSnapshotAccounts = 100
+
+// withSnapshot runs fn in a read-only REPEATABLE READ transaction, so every read it makes sees
+// the database as of one instant, and returns that instant. PostgreSQL takes the snapshot at
+// the first statement, which reads the time so the two coincide.
+func (s *transactionService) withSnapshot(ctx context.Context, fn func(*sql.Tx) error) (time.Time, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to start snapshot transaction", "err", err)
+		return time.Time{}, fmt.Errorf("error starting snapshot transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var at time.Time
+	if err := tx.QueryRowContext(ctx, `SELECT clock_timestamp()`).Scan(&at); err != nil {
+		s.log.ErrorContext(ctx, "Failed to read snapshot time", "err", err)
+		return time.Time{}, fmt.Errorf("failed to read snapshot time: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return time.Time{}, err
+	}
+	return at, nil
+}
+
+// GetAccountBalances reads the given accounts, ordered by ID, from a single snapshot so their
+// balances reflect one point in time, and returns the time of the snapshot. It fails with
+// ErrAccountNotFound for the first account that doesn't exist.
+func (s *transactionService) GetAccountBalances(ctx context.Context, accountIDs []int64) ([]*models.Account, time.Time, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.log.InfoContext(ctx, "Reading balances of accounts from a snapshot", "count", len(accountIDs))
+
+	if len(accountIDs) == 0 || len(accountIDs) > maxSnapshotAccounts {
+		return nil, time.Time{}, fmt.Errorf("%w: between 1 and %d account ids are required", domainErrors.ErrValidationFailed, maxSnapshotAccounts)
+	}
+
+	var accounts []*models.Account
+	at, err := s.withSnapshot(ctx, func(tx *sql.Tx) error {
+		var err error
+		accounts, err = s.accountRepo.GetAccountsWithTx(ctx, tx, accountIDs)
+		return err
+	})
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to read account balances", "err", err)
+		return nil, time.Time{}, err
+	}
+
+	found := make(map[int64]bool, len(accounts))
+	for _, account := range accounts {
+		found[account.AccountID] = true
+	}
+	for _, accountID := range accountIDs {
+		if !found[accountID] {
+			s.log.WarnContext(ctx, "Account not found reading balances", logger.AccountID(accountID))
+			return nil, time.Time{}, domainErrors.NewAccountNotFoundError(accountID)
+		}
+	}
+	return accounts, at, nil
+}
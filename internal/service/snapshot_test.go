@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountBalances(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.NewFromInt(20)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(10)))
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db)
+
+	before := time.Now().Add(-time.Minute)
+	accounts, at, err := svc.GetAccountBalances(ctx, []int64{2, 1, 2})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, int64(1), accounts[0].AccountID)
+	assert.True(t, decimal.NewFromInt(10).Equal(accounts[0].Balance))
+	assert.True(t, decimal.NewFromInt(20).Equal(accounts[1].Balance))
+	assert.True(t, at.After(before), "snapshot time %s should be recent", at)
+
+	_, _, err = svc.GetAccountBalances(ctx, []int64{1, 404})
+	assert.ErrorIs(t, err, errors.ErrAccountNotFound)
+	_, _, err = svc.GetAccountBalances(ctx, nil)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// CreateSplitTransfer debits sourceID once and pays every leg in a single database transaction.
+// Each leg is checked and made like a transfer of its own, fees included, against the balance
+// left by the legs before it; if any leg is rejected none of them is made.
+func (s *transactionService) CreateSplitTransfer(ctx context.Context, sourceID int64, legs []models.SplitLeg) (*models.SplitTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Processing split transfer legs", "source_account_id", sourceID, "count", len(legs))
+
+	split, err := models.NewSplitTransfer(sourceID, legs)
+	if err != nil {
+		s.log.WarnContext(ctx, "Invalid split transfer", "source_account_id", sourceID, "err", err)
+		s.metrics.Record("", err)
+		return nil, err
+	}
+
+	var created *models.SplitTransfer
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		parent, err := s.transactionRepo.CreateSplitTransferWithTx(ctx, tx, split)
+		if err != nil {
+			return err
+		}
+		parent.Legs = make([]*models.Transaction, 0, len(split.Legs))
+		for _, leg := range split.Legs {
+			leg.SplitID = parent.ID
+			made, err := s.transferWithTx(ctx, tx, leg, transferOptions{chargeFees: true})
+			if err != nil {
+				s.log.WarnContext(ctx, "Leg of split transfer rejected", "destination_account_id", leg.DestinationAccountID, "source_account_id", sourceID, "err", err)
+				return err
+			}
+			parent.Legs = append(parent.Legs, made)
+		}
+		created = parent
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Split transfer completed", logger.TransactionID(created.ID), "source_account_id", sourceID, "amount", created.Amount, "legs", len(created.Legs))
+	return created, nil
+}
+
+// GetSplitTransfer retrieves a split transfer with its legs
+func (s *transactionService) GetSplitTransfer(ctx context.Context, splitID int64) (*models.SplitTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	split, err := s.transactionRepo.GetSplitTransfer(ctx, splitID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve split transfer", "split_id", splitID, "err", err)
+		return nil, err
+	}
+	return split, nil
+}
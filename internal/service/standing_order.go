@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// WithStandingOrders enables recurring transfers. ScheduleStandingOrders turns each occurrence
+// that comes due into a scheduled transfer linked to its order, so it needs WithScheduledTransfers
+// too; run it before ExecuteDueScheduledTransfers in a sweep so occurrences are made right away.
+func WithStandingOrders(repo repository.StandingOrderRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.standingOrders = repo
+	}
+}
+
+// standingOrderRepo returns the standing order store, or an error if standing orders are disabled
+func (s *transactionService) standingOrderRepo() (repository.StandingOrderRepository, error) {
+	if s.standingOrders == nil || s.scheduled == nil {
+		return nil, fmt.Errorf("%w: standing orders are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.standingOrders, nil
+}
+
+// CreateStandingOrder records an active standing order transferring the amount of req on the
+// schedule of recurrence, whose first occurrence must be in the future. Balances are only
+// checked when each occurrence is made.
+func (s *transactionService) CreateStandingOrder(ctx context.Context, req *dto.CreateTransactionRequest, recurrence models.Recurrence) (*models.StandingOrder, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+	if err := rejectDeferredOnBehalfOf(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Creating standing order", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount, "frequency", recurrence.Frequency)
+
+	repo, err := s.standingOrderRepo()
+	if err != nil {
+		return nil, err
+	}
+	transaction := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Status:               models.TransactionStatusPending,
+	}
+	if err := transaction.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Standing order validation failed", "err", err)
+		return nil, err
+	}
+	if err := recurrence.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid standing order schedule", "err", err)
+		return nil, err
+	}
+	if now := s.now(); !recurrence.StartAt.After(now) {
+		s.log.WarnContext(ctx, "Standing order start is not in the future", "start_at", recurrence.StartAt.Format(time.RFC3339), "now", now.Format(time.RFC3339))
+		return nil, fmt.Errorf("%w: start_at must be in the future", domainErrors.ErrValidationFailed)
+	}
+
+	order := &models.StandingOrder{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Frequency:            recurrence.Frequency,
+		StartAt:              recurrence.StartAt.UTC().Format(time.RFC3339),
+		MaxOccurrences:       recurrence.MaxOccurrences,
+	}
+	if !recurrence.EndAt.IsZero() {
+		order.EndAt = recurrence.EndAt.UTC().Format(time.RFC3339)
+	}
+	return repo.CreateStandingOrder(ctx, order)
+}
+
+// GetStandingOrder retrieves a standing order by its ID
+func (s *transactionService) GetStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.standingOrderRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetStandingOrder(ctx, orderID)
+}
+
+// ListStandingOrderOccurrences lists up to limit of the scheduled transfers made for a standing
+// order, latest occurrence first. A made occurrence carries the ID of its transaction.
+func (s *transactionService) ListStandingOrderOccurrences(ctx context.Context, orderID int64, limit int) ([]*models.ScheduledTransfer, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.standingOrderRepo()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := repo.GetStandingOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultScheduledLimit
+	} else if limit > maxScheduledLimit {
+		limit = maxScheduledLimit
+	}
+	return s.scheduled.repo.ListStandingOrderOccurrences(ctx, orderID, limit)
+}
+
+// PauseStandingOrder stops an active standing order from scheduling occurrences. Occurrences
+// already scheduled, such as retries of a failed one, are still made.
+func (s *transactionService) PauseStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	return s.changeStandingOrder(ctx, orderID, func(tx *sql.Tx, order *models.StandingOrder) error {
+		if order.Status != models.StandingOrderStatusActive {
+			return standingOrderStatusError(order, "paused")
+		}
+		order.Status = models.StandingOrderStatusPaused
+		return nil
+	})
+}
+
+// ResumeStandingOrder reactivates a paused standing order. Occurrences that came due while it was
+// paused are skipped; the next is the first at or after now, and an order with none left completes.
+func (s *transactionService) ResumeStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	return s.changeStandingOrder(ctx, orderID, func(tx *sql.Tx, order *models.StandingOrder) error {
+		if order.Status != models.StandingOrderStatusPaused {
+			return standingOrderStatusError(order, "resumed")
+		}
+		recurrence, err := order.Recurrence()
+		if err != nil {
+			return err
+		}
+		next, err := time.Parse(time.RFC3339, order.NextRunAt)
+		if err != nil {
+			return fmt.Errorf("invalid next_run_at %q of standing order %d: %w", order.NextRunAt, order.ID, err)
+		}
+		if now := s.now(); next.Before(now) {
+			next = recurrence.OccurrenceAfter(now.Add(-time.Second))
+		}
+		order.Status, order.NextRunAt = models.StandingOrderStatusActive, next.Format(time.RFC3339)
+		if recurrence.Ended(order.Occurrences, next) {
+			order.Status = models.StandingOrderStatusCompleted
+		}
+		return nil
+	})
+}
+
+// CancelStandingOrder ends an active or paused standing order and cancels its occurrences that
+// are still waiting to be made
+func (s *transactionService) CancelStandingOrder(ctx context.Context, orderID int64) (*models.StandingOrder, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	return s.changeStandingOrder(ctx, orderID, func(tx *sql.Tx, order *models.StandingOrder) error {
+		if order.Status != models.StandingOrderStatusActive && order.Status != models.StandingOrderStatusPaused {
+			return standingOrderStatusError(order, "cancelled")
+		}
+		order.Status = models.StandingOrderStatusCancelled
+		cancelled, err := s.scheduled.repo.CancelStandingOrderOccurrencesWithTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if cancelled > 0 {
+			s.log.InfoContext(ctx, "Cancelled waiting occurrences of standing order", "cancelled", cancelled, "order_id", orderID)
+		}
+		return nil
+	})
+}
+
+// changeStandingOrder locks a standing order, lets change modify it and saves it
+func (s *transactionService) changeStandingOrder(ctx context.Context, orderID int64, change func(tx *sql.Tx, order *models.StandingOrder) error) (*models.StandingOrder, error) {
+	repo, err := s.standingOrderRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *models.StandingOrder
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		order, err := repo.GetStandingOrderForUpdateWithTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := change(tx, order); err != nil {
+			return err
+		}
+		updated, err = repo.UpdateStandingOrderWithTx(ctx, tx, order)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "Standing order status changed", "order_id", orderID, "status", updated.Status)
+	return updated, nil
+}
+
+// standingOrderStatusError reports that order can't be changed as intended in its current status
+func standingOrderStatusError(order *models.StandingOrder, intended string) error {
+	logger.Default().Warn("Standing order can't change status", "order_id", order.ID, "status", order.Status, "intended", intended)
+	return fmt.Errorf("%w: standing order %d is %s and can't be %s", domainErrors.ErrStandingOrderStatus, order.ID, order.Status, intended)
+}
+
+// ScheduleStandingOrders schedules the next occurrence of up to limit active standing orders that
+// are due on the service clock and returns the number scheduled. An order whose schedule is
+// exhausted completes. An order that fell behind, e.g. while the sweeper was down, is due again
+// right away, so repeated sweeps catch up on every missed occurrence.
+func (s *transactionService) ScheduleStandingOrders(ctx context.Context, limit int) (int, error) {
+	repo, err := s.standingOrderRepo()
+	if err != nil {
+		return 0, err
+	}
+
+	scheduled := 0
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		orders, err := repo.ListDueForUpdateWithTx(ctx, tx, s.now(), limit)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			recurrence, err := order.Recurrence()
+			if err != nil {
+				return err
+			}
+			runAt, err := time.Parse(time.RFC3339, order.NextRunAt)
+			if err != nil {
+				return fmt.Errorf("invalid next_run_at %q of standing order %d: %w", order.NextRunAt, order.ID, err)
+			}
+			if _, err := s.scheduled.repo.CreateScheduledTransferWithTx(ctx, tx, &models.ScheduledTransfer{
+				SourceAccountID:      order.SourceAccountID,
+				DestinationAccountID: order.DestinationAccountID,
+				Amount:               order.Amount,
+				ExecuteAt:            order.NextRunAt,
+				StandingOrderID:      order.ID,
+			}); err != nil {
+				return err
+			}
+
+			next := recurrence.OccurrenceAfter(runAt)
+			order.Occurrences++
+			order.NextRunAt = next.Format(time.RFC3339)
+			if recurrence.Ended(order.Occurrences, next) {
+				order.Status = models.StandingOrderStatusCompleted
+				s.log.InfoContext(ctx, "Standing order completed", "order_id", order.ID, "occurrences", order.Occurrences)
+			}
+			if _, err := repo.UpdateStandingOrderWithTx(ctx, tx, order); err != nil {
+				return err
+			}
+		}
+		scheduled = len(orders)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if scheduled > 0 {
+		s.log.InfoContext(ctx, "Scheduled occurrences of standing orders", "scheduled", scheduled)
+	}
+	return scheduled, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// recordStatusChangeWithTx appends a status change of a transaction to its history within tx,
+// attributed to the actor of the request in ctx
+func (s *transactionService) recordStatusChangeWithTx(ctx context.Context, tx *sql.Tx, transactionID int64, from, to models.TransactionStatus) error {
+	changedBy := actor.FromContext(ctx)
+	if len(changedBy) > models.MaxAuditActorLength {
+		s.log.WarnContext(ctx, "Actor of transaction status change is too long", logger.TransactionID(transactionID), "count", len(changedBy))
+		return fmt.Errorf("%w: actor must be at most %d characters", domainErrors.ErrValidationFailed, models.MaxAuditActorLength)
+	}
+
+	s.log.InfoContext(ctx, "Transaction status changed", logger.TransactionID(transactionID), "from", from, "to", to, "changed_by", changedBy)
+	return s.transactionRepo.AddStatusChangeWithTx(ctx, tx, &models.TransactionStatusChange{
+		TransactionID: transactionID,
+		FromStatus:    from,
+		ToStatus:      to,
+		Actor:         changedBy,
+	})
+}
+
+// GetTransactionHistory retrieves the status changes of a transaction, oldest first
+func (s *transactionService) GetTransactionHistory(ctx context.Context, transactionID int64) ([]*models.TransactionStatusChange, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Retrieving status history of transaction", logger.TransactionID(transactionID))
+
+	if _, err := s.transactionRepo.GetTransactionByID(ctx, transactionID); err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, err
+	}
+
+	history, err := s.transactionRepo.GetStatusHistory(ctx, transactionID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve status history of transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, err
+	}
+	return history, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// Bounds on the number of suspense items listed
+const (
+	defaultSuspenseLimit = 100
+	maxSuspenseLimit     = 1000
+)
+
+// suspenseConfig is the suspense account and the store of the items held on it
+type suspenseConfig struct {
+	accountID int64
+	repo      repository.SuspenseRepository
+}
+
+// WithSuspenseAccount parks credits whose destination is frozen or closed at settlement time on
+// accountID instead of failing them. Only asynchronous (batch) settlement uses the suspense account;
+// synchronous transfers to such accounts are rejected so the caller sees the failure.
+func WithSuspenseAccount(accountID int64, repo repository.SuspenseRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.suspense = &suspenseConfig{accountID: accountID, repo: repo}
+	}
+}
+
+// isSuspenseAccount checks if accountID is the configured suspense account
+func (s *transactionService) isSuspenseAccount(accountID int64) bool {
+	return s.suspense != nil && s.suspense.accountID == accountID
+}
+
+// suspenseAccountWithTx retrieves the suspense account within tx
+func (s *transactionService) suspenseAccountWithTx(ctx context.Context, tx *sql.Tx) (*models.Account, error) {
+	account, err := s.accountRepo.GetAccountWithTx(ctx, tx, s.suspense.accountID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve suspense account", logger.AccountID(s.suspense.accountID), "err", err)
+		return nil, fmt.Errorf("suspense account %d: %w", s.suspense.accountID, err)
+	}
+	return account, nil
+}
+
+// recordSuspenseItemWithTx records a credit parked on the suspense account and its audit entry
+func (s *transactionService) recordSuspenseItemWithTx(ctx context.Context, tx *sql.Tx, item *models.SuspenseItem) error {
+	created, err := s.suspense.repo.CreateItemWithTx(ctx, tx, item)
+	if err != nil {
+		return err
+	}
+	return s.suspense.repo.AddEventWithTx(ctx, tx, &models.SuspenseEvent{
+		ItemID:        created.ID,
+		Action:        models.SuspenseActionSuspended,
+		Actor:         models.SuspenseActorSystem,
+		Note:          item.Reason,
+		TransactionID: item.TransactionID,
+	})
+}
+
+// suspenseRepo returns the suspense item store, or an error if no suspense account is configured
+func (s *transactionService) suspenseRepo() (repository.SuspenseRepository, error) {
+	if s.suspense == nil {
+		return nil, fmt.Errorf("%w: no suspense account is configured", domainErrors.ErrValidationFailed)
+	}
+	return s.suspense.repo, nil
+}
+
+// ListSuspenseItems retrieves suspense items with the given status (any if empty), oldest first.
+// limit defaults to 100 and is capped at 1000.
+func (s *transactionService) ListSuspenseItems(ctx context.Context, status models.SuspenseStatus, limit int) ([]*models.SuspenseItem, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.suspenseRepo()
+	if err != nil {
+		return nil, err
+	}
+	if status != "" && !status.IsValid() {
+		s.log.WarnContext(ctx, "Invalid suspense status", "status", status)
+		return nil, fmt.Errorf("%w: invalid status %q", domainErrors.ErrValidationFailed, status)
+	}
+	if limit <= 0 {
+		limit = defaultSuspenseLimit
+	} else if limit > maxSuspenseLimit {
+		limit = maxSuspenseLimit
+	}
+
+	items, err := repo.ListItems(ctx, status, limit)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to list suspense items", "err", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetSuspenseItem retrieves a suspense item together with its audit trail
+func (s *transactionService) GetSuspenseItem(ctx context.Context, itemID int64) (*models.SuspenseItem, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.suspenseRepo()
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetItem(ctx, itemID)
+}
+
+// ReapplySuspenseItem moves a suspended credit on to its intended destination, which must be
+// able to receive credits again
+func (s *transactionService) ReapplySuspenseItem(ctx context.Context, itemID int64, actor, note string) (*models.SuspenseItem, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	return s.resolveSuspenseItem(ctx, itemID, models.SuspenseStatusReapplied, actor, note)
+}
+
+// ReturnSuspenseItem sends a suspended credit back to the account it came from
+func (s *transactionService) ReturnSuspenseItem(ctx context.Context, itemID int64, actor, note string) (*models.SuspenseItem, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	return s.resolveSuspenseItem(ctx, itemID, models.SuspenseStatusReturned, actor, note)
+}
+
+// resolveSuspenseItem transfers a suspended credit off the suspense account, to its intended
+// destination when re-applying or to its source when returning, and records the outcome and the
+// operator in the item's audit trail. The item is locked so it is resolved exactly once.
+func (s *transactionService) resolveSuspenseItem(ctx context.Context, itemID int64, status models.SuspenseStatus, actor, note string) (*models.SuspenseItem, error) {
+	s.log.InfoContext(ctx, "Resolving suspense item", "item_id", itemID, "status", status, "actor", actor)
+
+	repo, err := s.suspenseRepo()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(actor) == "" || len(actor) > models.MaxSuspenseActorLength {
+		s.log.WarnContext(ctx, "Invalid actor for suspense item", "item_id", itemID, "actor", actor)
+		return nil, fmt.Errorf("%w: actor must be 1-%d characters", domainErrors.ErrValidationFailed, models.MaxSuspenseActorLength)
+	}
+
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		item, err := repo.GetItemForUpdateWithTx(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if !item.IsOpen() {
+			s.log.WarnContext(ctx, "Suspense item is already resolved", "item_id", itemID, "status", item.Status)
+			return fmt.Errorf("%w: item %d is %s", domainErrors.ErrSuspenseItemResolved, itemID, item.Status)
+		}
+
+		target, action := item.DestinationAccountID, models.SuspenseActionReapplied
+		if status == models.SuspenseStatusReturned {
+			target, action = item.SourceAccountID, models.SuspenseActionReturned
+		}
+		transaction := &models.Transaction{
+			SourceAccountID:      s.suspense.accountID,
+			DestinationAccountID: target,
+			Amount:               item.Amount,
+			Status:               models.TransactionStatusPending,
+		}
+		if err := transaction.Validate(); err != nil {
+			return err
+		}
+		created, err := s.transferWithTx(ctx, tx, transaction, transferOptions{})
+		if err != nil {
+			return err
+		}
+
+		if err := repo.ResolveItemWithTx(ctx, tx, itemID, status, created.ID, actor); err != nil {
+			return err
+		}
+		return repo.AddEventWithTx(ctx, tx, &models.SuspenseEvent{
+			ItemID:        itemID,
+			Action:        action,
+			Actor:         actor,
+			Note:          note,
+			TransactionID: created.ID,
+		})
+	})
+	if err != nil {
+		if !errors.Is(err, domainErrors.ErrSuspenseItemResolved) {
+			s.log.ErrorContext(ctx, "Failed to resolve suspense item", "item_id", itemID, "err", err)
+		}
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Suspense item resolved", "item_id", itemID, "status", status, "actor", actor)
+	return repo.GetItem(ctx, itemID)
+}
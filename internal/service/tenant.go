@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// tenantService implements the TenantService interface
+type tenantService struct {
+	repo repository.TenantRepository
+	log  *slog.Logger
+}
+
+// NewTenantService creates a new tenant service instance. repo should be the same
+// repository.CachedTenantRepository the transaction service reads, so changes made here are
+// seen by the next transfer.
+func NewTenantService(repo repository.TenantRepository) TenantService {
+	return &tenantService{repo: repo, log: logger.Default()}
+}
+
+// GetTenantSettings retrieves the settings of a tenant
+func (s *tenantService) GetTenantSettings(ctx context.Context, tenantID string) (*models.TenantSettings, error) {
+	if err := models.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTenantSettings(ctx, tenantID)
+}
+
+// ListTenantSettings retrieves the settings of every tenant, ordered by tenant ID
+func (s *tenantService) ListTenantSettings(ctx context.Context) ([]*models.TenantSettings, error) {
+	return s.repo.ListTenantSettings(ctx)
+}
+
+// PutTenantSettings validates and creates or replaces the settings of a tenant
+func (s *tenantService) PutTenantSettings(ctx context.Context, tenantID string, req *dto.PutTenantSettingsRequest) (*models.TenantSettings, error) {
+	settings := &models.TenantSettings{
+		TenantID:             tenantID,
+		AllowedCurrencies:    req.AllowedCurrencies,
+		Features:             req.Features,
+		MaxAccounts:          req.MaxAccounts,
+		MaxDailyTransactions: req.MaxDailyTransactions,
+		QuotaWarnPercent:     req.QuotaWarnPercent,
+	}
+	if req.MaxTransferAmount != "" {
+		limit, err := decimal.NewFromString(req.MaxTransferAmount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: max_transfer_amount %q is not a decimal number", errors.ErrValidationFailed, req.MaxTransferAmount)
+		}
+		settings.MaxTransferAmount = &limit
+	}
+	if err := settings.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid settings for tenant", "tenant_id", tenantID, "err", err)
+		return nil, err
+	}
+	return s.repo.PutTenantSettings(ctx, settings)
+}
+
+// DeleteTenantSettings removes the settings of a tenant, returning its accounts to the
+// deployment-wide behavior
+func (s *tenantService) DeleteTenantSettings(ctx context.Context, tenantID string) error {
+	if err := models.ValidateTenantID(tenantID); err != nil {
+		return err
+	}
+	return s.repo.DeleteTenantSettings(ctx, tenantID)
+}
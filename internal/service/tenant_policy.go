@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+)
+
+// WithTenantSettings applies the settings of the tenant an account belongs to on top of the
+// deployment-wide behavior: the tenant's transfer limit and currency policy, and its features.
+// tenants is read on every transfer, so it should be a repository.CachedTenantRepository.
+func WithTenantSettings(tenants repository.TenantRepository) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.tenants = tenants
+	}
+}
+
+// tenantSettings returns the settings of the tenant of account, or nil if it has no tenant or
+// the tenant has no settings
+func (s *transactionService) tenantSettings(ctx context.Context, account *models.Account) (*models.TenantSettings, error) {
+	if s.tenants == nil || account.TenantID == "" {
+		return nil, nil
+	}
+	settings, err := s.tenants.GetTenantSettings(ctx, account.TenantID)
+	if errors.Is(err, domainErrors.ErrTenantNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve settings of tenant", "tenant_id", account.TenantID, "err", err)
+		return nil, err
+	}
+	return settings, nil
+}
+
+// checkSourceTenant checks a transfer of amount from source is within the limit and currency
+// policy of its tenant
+func (s *transactionService) checkSourceTenant(source *models.Account, settings *models.TenantSettings, amount decimal.Decimal) error {
+	if err := settings.CheckTransferAmount(source.AccountID, amount); err != nil {
+		s.log.Warn("Transfer exceeds the limit of tenant", "amount", amount, logger.AccountID(source.AccountID), "tenant_id", source.TenantID)
+		return err
+	}
+	return checkTenantCurrency(source, settings)
+}
+
+// checkTenantCurrency checks the tenant of account allows transfers in its currency
+func checkTenantCurrency(account *models.Account, settings *models.TenantSettings) error {
+	if !settings.AllowsCurrency(account.Currency) {
+		logger.Default().Warn("Tenant doesn't allow the currency of account", "tenant_id", account.TenantID, "currency", account.Currency, logger.AccountID(account.AccountID))
+		return domainErrors.NewCurrencyNotAllowedError(account.AccountID, account.Currency)
+	}
+	return nil
+}
+
+// dormantOutboundBlocked reports whether transfers from dormant accounts of the tenant are
+// rejected; tenants that don't set the feature follow WithDormantOutboundBlocked
+func (s *transactionService) dormantOutboundBlocked(settings *models.TenantSettings) bool {
+	if blocked, ok := settings.Feature(models.FeatureBlockDormantOutbound); ok {
+		return blocked
+	}
+	return s.blockDormant
+}
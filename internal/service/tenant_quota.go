@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// WithQuotaMetrics records in m the usage of the daily transaction quotas of tenants, as observed
+// by their transfers
+func WithQuotaMetrics(m *metrics.Quotas) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.quotaMetrics = m
+	}
+}
+
+// checkTenantQuotaWithTx checks a transfer from source fits the daily transaction quota of its
+// tenant. Transfers are counted within tx, so the serializable isolation of tx keeps concurrent
+// transfers from both taking the last place.
+func (s *transactionService) checkTenantQuotaWithTx(ctx context.Context, tx *sql.Tx, source *models.Account, settings *models.TenantSettings) error {
+	if settings == nil || settings.MaxDailyTransactions == nil {
+		return nil
+	}
+	sent, err := s.transactionRepo.CountTenantOutboundWithTx(ctx, tx, source.TenantID, s.calendar.Date(s.now()))
+	if err != nil {
+		return err
+	}
+	usage, err := settings.CheckQuota(models.QuotaDailyTransactions, source.AccountID, sent)
+	observeQuota(s.quotaMetrics, source.TenantID, models.QuotaDailyTransactions, usage, err)
+	return err
+}
+
+// observeQuota logs the usage of a capped quota of a tenant once it reaches the warning threshold
+// and records it in m; err is the rejection of the assignment or transfer, if any
+func observeQuota(m *metrics.Quotas, tenantID, quota string, usage models.QuotaUsage, err error) {
+	if usage.Limit == nil {
+		return
+	}
+	if err != nil {
+		logger.Default().Warn("Tenant is at its quota", "tenant_id", tenantID, "quota", quota, "limit", *usage.Limit, "err", err)
+		m.Reject(tenantID, quota, usage.Used, *usage.Limit)
+		return
+	}
+	warning := usage.Level != models.QuotaLevelOK
+	if warning {
+		logger.Default().Warn("Tenant quota usage", "tenant_id", tenantID, "used", usage.Used, "quota", quota, "limit", *usage.Limit)
+	}
+	m.Observe(tenantID, quota, usage.Used, *usage.Limit, warning)
+}
+
+// GetTenantUsage returns how much of its quotas a tenant uses on the current business day, with
+// accounts and transfers counted from a single snapshot. A tenant without settings or caps has
+// its usage reported uncapped.
+func (s *transactionService) GetTenantUsage(ctx context.Context, tenantID string) (*models.TenantUsage, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateTenantID(tenantID); err != nil {
+		return nil, err
+	}
+	var settings *models.TenantSettings
+	if s.tenants != nil {
+		var err error
+		settings, err = s.tenants.GetTenantSettings(ctx, tenantID)
+		if err != nil && !errors.Is(err, domainErrors.ErrTenantNotFound) {
+			s.log.ErrorContext(ctx, "Failed to retrieve settings of tenant", "tenant_id", tenantID, "err", err)
+			return nil, err
+		}
+	}
+
+	today := s.calendar.Date(s.now())
+	var accounts, sent int64
+	at, err := s.withSnapshot(ctx, func(tx *sql.Tx) error {
+		var err error
+		if accounts, err = s.accountRepo.CountTenantAccountsWithTx(ctx, tx, tenantID); err != nil {
+			return err
+		}
+		sent, err = s.transactionRepo.CountTenantOutboundWithTx(ctx, tx, tenantID, today)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TenantUsage{
+		TenantID:          tenantID,
+		BusinessDate:      today,
+		SnapshotAt:        at.UTC().Format(time.RFC3339Nano),
+		Accounts:          settings.QuotaUsage(models.QuotaAccounts, accounts),
+		DailyTransactions: settings.QuotaUsage(models.QuotaDailyTransactions, sent),
+	}, nil
+}
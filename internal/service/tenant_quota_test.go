@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransaction_DailyTransactionQuota(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(db)
+	tenants := repository.NewTenantRepository(db)
+	maxDaily := int64(2)
+	_, err := tenants.PutTenantSettings(ctx, &models.TenantSettings{TenantID: "retail", MaxDailyTransactions: &maxDaily})
+	require.NoError(t, err)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	require.NoError(t, accountRepo.SetTenant(ctx, 1, "retail"))
+
+	quotas := metrics.NewQuotas()
+	svc := NewTransactionService(repository.NewTransactionRepository(db), accountRepo, db,
+		WithTenantSettings(tenants), WithQuotaMetrics(quotas))
+	req := &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(10)}
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.CreateTransaction(ctx, req)
+		require.NoError(t, err)
+	}
+	_, err = svc.CreateTransaction(ctx, req)
+	assert.ErrorIs(t, err, errors.ErrTenantQuotaExceeded)
+
+	// Transfers into the tenant don't count against it
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(5)})
+	require.NoError(t, err)
+
+	usage, err := svc.GetTenantUsage(ctx, "retail")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Accounts.Used)
+	assert.Nil(t, usage.Accounts.Limit)
+	assert.Equal(t, models.QuotaUsage{Used: 2, Limit: &maxDaily, Level: models.QuotaLevelFull}, usage.DailyTransactions)
+
+	assert.Equal(t, []metrics.TenantQuotaStats{
+		{TenantID: "retail", Quota: models.QuotaDailyTransactions, Used: 2, Limit: 2, Warnings: 1, Rejections: 1},
+	}, quotas.Stats().Quotas)
+}
@@ -5,12 +5,25 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	"github.com/khamiruf/internal_transfers_system_go/internal/businessday"
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
 	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/export"
+	"github.com/khamiruf/internal_transfers_system_go/internal/fx"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
 	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
 	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
 	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/velocity"
+	"github.com/shopspring/decimal"
 )
 
 // transactionService implements the TransactionService interface
@@ -18,164 +31,765 @@ type transactionService struct {
 	transactionRepo repository.TransactionRepository
 	accountRepo     repository.AccountRepository
 	db              *sql.DB
+	writeFence      WriteFence
+	suspense        *suspenseConfig
+	calendar        *businessday.Calendar
+	blockDormant    bool
+	minimumBalances map[models.AccountType]decimal.Decimal
+	audit           repository.AuditRepository
+	preAuth         *preAuthConfig
+	scheduled       *scheduledConfig
+	standingOrders  repository.StandingOrderRepository
+	idempotency     repository.IdempotencyRepository
+	events          EventPublisher
+	outbox          repository.OutboxRepository
+	postings        LedgerService
+	ledger          repository.LedgerRepository
+	tenants         repository.TenantRepository
+	metrics         *metrics.Transfers
+	quotaMetrics    *metrics.Quotas
+	rates           fx.RateProvider
+	fees            *feeConfig
+	clock           clock.Clock
+	log             *slog.Logger
+	exportThrottle  *export.Throttle
+	lanes           *priority.Lanes
+	velocity        *velocity.Tracker
+	approvals       *approvalConfig
+	funding         repository.FundingRepository
+	transferJobs    *transferJobConfig
+	deadLetters     repository.DeadLetterRepository
+	delegations     *delegationConfig
+	lockingShadow   *lockingShadowConfig
+	adjustments     *adjustmentConfig
+
+	idempotencySealer *idempotency.Sealer
+	idempotencyTTL    time.Duration
+
+	snapshotInterval      time.Duration
+	closureSweepAccountID int64
+
+	// pendingEvents are the events raised within open database transactions
+	pendingMu     sync.Mutex
+	pendingEvents map[*sql.Tx][]pendingEvent
+}
+
+// TransactionServiceOption configures optional collaborators of the transaction service
+type TransactionServiceOption func(*transactionService)
+
+// WithWriteFence makes every write transaction consult fence before doing any work
+func WithWriteFence(fence WriteFence) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.writeFence = fence
+	}
+}
+
+// WithDormantOutboundBlocked rejects transfers from dormant accounts until they are reactivated.
+// The suspense account is exempt so parked credits can always be resolved.
+func WithDormantOutboundBlocked() TransactionServiceOption {
+	return func(s *transactionService) {
+		s.blockDormant = true
+	}
+}
+
+// WithLogger logs through l instead of the package-level logger
+func WithLogger(l *slog.Logger) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.log = l
+	}
 }
 
 // NewTransactionService creates a new transaction service instance
-func NewTransactionService(transactionRepo repository.TransactionRepository, accountRepo repository.AccountRepository, db *sql.DB) TransactionService {
-	return &transactionService{
+func NewTransactionService(transactionRepo repository.TransactionRepository, accountRepo repository.AccountRepository, db *sql.DB, opts ...TransactionServiceOption) TransactionService {
+	s := &transactionService{
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
 		db:              db,
+		calendar:        businessday.UTC(),
+		clock:           clock.System,
+		log:             logger.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// withTransaction executes a function within a database transaction
-func (s *transactionService) withTransaction(ctx context.Context, fn func(*sql.Tx) error) error {
-	logger.Info("Starting database transaction")
+// withTransaction executes a function within a database transaction.
+//
+// The outcome is always the one the returned error reports: nil means committed, anything else
+// rolled back. A transaction isn't started once ctx is done. After that the transaction itself is
+// detached from ctx, so a client disconnecting can't make database/sql roll it back behind fn's
+// back or interrupt COMMIT after it was sent. Statements fn runs with ctx still fail once it is
+// cancelled, which rolls the transaction back; a client that went away learns what happened by
+// retrying with its idempotency key or looking the key up.
+func (s *transactionService) withTransaction(ctx context.Context, fn func(*sql.Tx) error) (err error) {
+	if err := ctx.Err(); err != nil {
+		s.log.WarnContext(ctx, "Not starting database transaction, request is done", "err", err)
+		return err
+	}
+	requestCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+	defer func() {
+		if requestCtx.Err() != nil {
+			outcome := "committed"
+			if err != nil {
+				outcome = "rolled back"
+			}
+			s.log.WarnContext(ctx, "Request was cancelled during the database transaction", "outcome", outcome, "err", err)
+		}
+	}()
+
+	s.log.InfoContext(ctx, "Starting database transaction")
 
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelSerializable,
 	})
 	if err != nil {
-		logger.Error("Failed to start transaction: %v", err)
+		s.log.ErrorContext(ctx, "Failed to start transaction", "err", err)
 		return fmt.Errorf("error starting transaction: %w", err)
 	}
 
 	defer func() {
 		if p := recover(); p != nil {
-			logger.Error("Transaction panic occurred: %v", p)
+			s.log.ErrorContext(ctx, "Transaction panic occurred", "panic", p)
 			tx.Rollback()
+			s.takeEventsWithTx(tx)
 			panic(p) // re-throw panic after rollback
 		}
 	}()
 
+	if s.writeFence != nil {
+		if err := s.writeFence.CheckWrite(ctx, tx); err != nil {
+			s.log.WarnContext(ctx, "Write rejected by fence, rolling back", "err", err)
+			tx.Rollback()
+			return err
+		}
+	}
+
 	if err := fn(tx); err != nil {
-		logger.Error("Transaction failed, rolling back: %v", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			logger.Error("Failed to rollback transaction: %v", rbErr)
+		s.log.ErrorContext(ctx, "Transaction failed, rolling back", "err", err)
+		rbErr := tx.Rollback()
+		s.publishEventsWithTx(ctx, tx, false)
+		if rbErr != nil {
+			s.log.ErrorContext(ctx, "Failed to roll back transaction", "err", rbErr)
 			return fmt.Errorf("error rolling back transaction: %v (original error: %w)", rbErr, err)
 		}
 		return err
 	}
 
 	if err := tx.Commit(); err != nil {
-		logger.Error("Failed to commit transaction: %v", err)
+		s.log.ErrorContext(ctx, "Failed to commit transaction", "err", err)
+		s.publishEventsWithTx(ctx, tx, false)
 		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	logger.Info("Transaction committed successfully")
+	s.log.InfoContext(ctx, "Transaction committed")
+	s.publishEventsWithTx(ctx, tx, true)
 	return nil
 }
 
 // CreateTransaction processes a transaction between two accounts
 func (s *transactionService) CreateTransaction(ctx context.Context, req *dto.CreateTransactionRequest) (*dto.TransactionResponse, error) {
-	logger.Info("Processing transaction: source=%d, destination=%d, amount=%s",
-		req.SourceAccountID, req.DestinationAccountID, req.Amount.String())
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+
+	return s.createTransaction(ctx, req, time.Time{})
+}
+
+// CreateBackdatedTransaction processes a correcting transaction whose value date lies in the past.
+// Balances move now; the value date only affects queries evaluated on the effective time axis.
+func (s *transactionService) CreateBackdatedTransaction(ctx context.Context, req *dto.CreateTransactionRequest, valueDate time.Time) (*dto.TransactionResponse, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	if valueDate.IsZero() || valueDate.After(s.now()) {
+		s.log.WarnContext(ctx, "Invalid value date for back-dated transaction", "value_date", valueDate.Format(time.RFC3339))
+		return nil, fmt.Errorf("%w: value date must be in the past", domainErrors.ErrValidationFailed)
+	}
+	return s.createTransaction(ctx, req, valueDate)
+}
 
+// createTransaction processes a transaction between two accounts, recording valueDate as its
+// value date (the recording time if zero)
+func (s *transactionService) createTransaction(ctx context.Context, req *dto.CreateTransactionRequest, valueDate time.Time) (*dto.TransactionResponse, error) {
 	transaction := &models.Transaction{
 		SourceAccountID:      req.SourceAccountID,
 		DestinationAccountID: req.DestinationAccountID,
 		Amount:               req.Amount,
 		Status:               models.TransactionStatusPending,
 	}
+	if !valueDate.IsZero() {
+		transaction.ValueDate = valueDate.Format(time.RFC3339)
+	}
+
+	if key := idempotency.KeyFromContext(ctx); key != "" && s.idempotency != nil {
+		return s.createIdempotentTransaction(ctx, key, transaction)
+	}
+
+	createdTx, err := s.transfer(ctx, transaction, transferOptions{chargeFees: true, approvable: true})
+	if err != nil {
+		return nil, err
+	}
+	return toTransactionResponse(createdTx), nil
+}
+
+// toTransactionResponse converts a recorded transaction to its response DTO
+func toTransactionResponse(transaction *models.Transaction) *dto.TransactionResponse {
+	return &dto.TransactionResponse{
+		ID:                   transaction.ID,
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+		CreatedAt:            transaction.CreatedAt,
+	}
+}
+
+// transferOptions adjust the rules a single transfer is executed under
+type transferOptions struct {
+	// allowSuspense credits the suspense account when the destination can't receive credits
+	allowSuspense bool
+	// minimumBalanceOverride lets the source go below the minimum balance of its account type
+	minimumBalanceOverride *models.MinimumBalanceOverride
+	// conversion fixes the conversion of a cross-currency transfer instead of quoting a rate
+	conversion *fxConversion
+	// chargeFees charges the fees of the configured fee policy on the source
+	chargeFees bool
+	// closing sweeps the balance of a source being closed: its status, the transfer limit of its
+	// tenant, its own outbound limits and the minimum balance of its type don't apply
+	closing bool
+	// approvable holds the transfer for approval instead if it is above the approval threshold
+	approvable bool
+	// approved makes a transfer held in pending_approval, completing its recorded transaction
+	approved bool
+	// funding moves money between the system float account of a currency and an account in the
+	// same currency. A float source may go negative and its status, limits and minimum balance
+	// don't apply; without funding a transfer from or to a float account is rejected.
+	funding bool
+	// adjustment is a manual balance adjustment between an account and the adjustments account.
+	// The status, limits and minimum balance of the source don't apply, but its balance does.
+	adjustment bool
+}
+
+// transfer validates a pending transaction and executes it in its own database transaction
+func (s *transactionService) transfer(ctx context.Context, transaction *models.Transaction, opts transferOptions) (*models.Transaction, error) {
+	s.log.InfoContext(ctx, "Processing transaction",
+		"source_account_id", transaction.SourceAccountID, "destination_account_id", transaction.DestinationAccountID, "amount", transaction.Amount.String())
 
 	if err := transaction.Validate(); err != nil {
-		logger.Warn("Transaction validation failed: %v", err)
+		s.log.WarnContext(ctx, "Transaction validation failed", "err", err)
+		s.metrics.Record("", err)
 		return nil, err
 	}
 
-	var createdTransaction *dto.TransactionResponse
-	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
-		// Get source account
-		logger.Info("Retrieving source account: %d", req.SourceAccountID)
-		sourceAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, req.SourceAccountID)
-		if err != nil {
-			if errors.Is(err, domainErrors.ErrAccountNotFound) {
-				logger.Warn("Source account not found: %d", req.SourceAccountID)
-				return domainErrors.ErrSourceAccountNotFound
-			}
-			logger.Error("Failed to retrieve source account %d: %v", req.SourceAccountID, err)
+	var createdTx *models.Transaction
+	err := s.inLane(ctx, func() error {
+		return s.withTransaction(ctx, func(tx *sql.Tx) error {
+			var err error
+			createdTx, err = s.transferWithTx(ctx, tx, transaction, opts)
 			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdTx, nil
+}
+
+// transferWithTx moves the amount between the accounts and records the completed transaction
+// within tx. transaction must already be validated. A debit from a frozen account is rejected. A
+// credit to a frozen or closed account is rejected too, unless opts.allowSuspense is set and a
+// suspense account is configured, in which case the credit lands on the suspense account and is
+// recorded as a suspense item.
+func (s *transactionService) transferWithTx(ctx context.Context, tx *sql.Tx, transaction *models.Transaction, opts transferOptions) (_ *models.Transaction, err error) {
+	sourceID, destID, amount := transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount
+
+	delegation, err := s.authorizeDelegationWithTx(ctx, tx, transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.approvable && s.requiresApproval(transaction) {
+		held, err := s.holdForApprovalWithTx(ctx, tx, transaction)
+		if err != nil {
+			return nil, err
 		}
+		return held, s.recordDelegatedTransferWithTx(ctx, tx, delegation, held)
+	}
 
-		logger.Info("Source account %d current balance: %s", req.SourceAccountID, sourceAccount.Balance.String())
+	var tenantID, currency string
+	var flagged []velocity.Breach
+	defer func() {
+		s.metrics.Record(tenantID, err)
+		if err == nil {
+			s.metrics.AddVolume(currency, amount)
+		} else {
+			s.queueTransferFailureWithTx(tx, transaction, err)
+		}
+	}()
 
-		// Check sufficient balance
-		if !sourceAccount.HasSufficientBalance(req.Amount) {
-			logger.Warn("Insufficient balance: account=%d, current_balance=%s, required_amount=%s",
-				req.SourceAccountID, sourceAccount.Balance.String(), req.Amount.String())
-			return domainErrors.ErrInsufficientBalance
+	// Get source account
+	s.log.InfoContext(ctx, "Retrieving source account", logger.AccountID(sourceID))
+	sourceAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, sourceID)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			s.log.WarnContext(ctx, "Source account not found", logger.AccountID(sourceID))
+			return nil, domainErrors.NewSourceAccountNotFoundError(sourceID)
 		}
+		s.log.ErrorContext(ctx, "Failed to retrieve source account", logger.AccountID(sourceID), "err", err)
+		return nil, err
+	}
 
-		// Get destination account
-		logger.Info("Retrieving destination account: %d", req.DestinationAccountID)
-		destAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, req.DestinationAccountID)
-		if err != nil {
-			if errors.Is(err, domainErrors.ErrAccountNotFound) {
-				logger.Warn("Destination account not found: %d", req.DestinationAccountID)
-				return domainErrors.ErrDestinationAccountNotFound
+	s.log.InfoContext(ctx, "Source account balance", logger.AccountID(sourceID), "balance", sourceAccount.Balance.String())
+	tenantID, currency = sourceAccount.TenantID, sourceAccount.Currency
+
+	if sourceAccount.IsSystemFloat() && !opts.funding {
+		s.log.WarnContext(ctx, "Source account is a system float account", logger.AccountID(sourceID))
+		return nil, domainErrors.WithAccount(domainErrors.ErrSystemFloatAccount, sourceID)
+	}
+	fromFloat := opts.funding && sourceAccount.IsSystemFloat()
+
+	sourceTenant, err := s.tenantSettings(ctx, sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.closing || opts.adjustment || fromFloat {
+		if err := checkTenantCurrency(sourceAccount, sourceTenant); err != nil {
+			return nil, err
+		}
+	} else {
+		if sourceAccount.IsFrozen() {
+			s.log.WarnContext(ctx, "Source account is frozen", logger.AccountID(sourceID))
+			return nil, domainErrors.NewAccountFrozenError(sourceID)
+		}
+
+		if s.dormantOutboundBlocked(sourceTenant) && sourceAccount.IsDormant() && !s.isSuspenseAccount(sourceID) {
+			s.log.WarnContext(ctx, "Source account is dormant", logger.AccountID(sourceID))
+			return nil, domainErrors.NewAccountDormantError(sourceID)
+		}
+
+		if err := s.checkSourceTenant(sourceAccount, sourceTenant, amount); err != nil {
+			return nil, err
+		}
+
+		if err := s.checkAccountLimits(ctx, tx, sourceAccount, amount); err != nil {
+			return nil, err
+		}
+
+		if err := s.checkTenantQuotaWithTx(ctx, tx, sourceAccount, sourceTenant); err != nil {
+			return nil, err
+		}
+
+		var cancel func()
+		if flagged, cancel, err = s.admitVelocity(sourceAccount, amount); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				cancel()
 			}
-			logger.Error("Failed to retrieve destination account %d: %v", req.DestinationAccountID, err)
-			return err
+		}()
+	}
+
+	if err := s.checkAmountForCurrency(ctx, sourceAccount, amount); err != nil {
+		return nil, err
+	}
+
+	// The source pays any fees on top of the amount
+	fees, err := s.feesFor(ctx, transaction, sourceAccount, opts)
+	if err != nil {
+		return nil, err
+	}
+	debit := amount.Add(models.TotalFees(fees))
+
+	shadow := s.shadowTransferWithTx(ctx, tx, sourceID, destID, debit, amount, fromFloat)
+	defer func() { shadow.compare(err) }()
+
+	// Check sufficient balance; the float account funding the system goes negative instead
+	if !fromFloat && !sourceAccount.HasSufficientBalance(debit) {
+		s.log.WarnContext(ctx, "Insufficient balance", logger.AccountID(sourceID), "balance", sourceAccount.Balance.String(),
+			"available_balance", sourceAccount.AvailableBalance().String(), "required_amount", debit.String())
+		return nil, domainErrors.NewInsufficientBalanceError(sourceID, debit, sourceAccount.AvailableBalance())
+	}
+
+	var overridden bool
+	if !opts.closing && !opts.adjustment && !fromFloat {
+		if overridden, err = s.checkMinimumBalance(sourceAccount, debit, opts.minimumBalanceOverride); err != nil {
+			return nil, err
 		}
+	}
+
+	// Get destination account
+	s.log.InfoContext(ctx, "Retrieving destination account", logger.AccountID(destID))
+	destAccount, err := s.accountRepo.GetAccountWithTx(ctx, tx, destID)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrAccountNotFound) {
+			s.log.WarnContext(ctx, "Destination account not found", logger.AccountID(destID))
+			return nil, domainErrors.NewDestinationAccountNotFoundError(destID)
+		}
+		s.log.ErrorContext(ctx, "Failed to retrieve destination account", logger.AccountID(destID), "err", err)
+		return nil, err
+	}
 
-		logger.Info("Destination account %d current balance: %s", req.DestinationAccountID, destAccount.Balance.String())
+	if destAccount.IsSystemFloat() && !opts.funding {
+		s.log.WarnContext(ctx, "Destination account is a system float account", logger.AccountID(destID))
+		return nil, domainErrors.WithAccount(domainErrors.ErrSystemFloatAccount, destID)
+	}
+	if opts.funding && sourceAccount.Currency != destAccount.Currency {
+		s.log.WarnContext(ctx, "Funding crosses currencies", "source_account_id", sourceID, "source_currency", sourceAccount.Currency,
+			"destination_account_id", destID, "destination_currency", destAccount.Currency)
+		return nil, fmt.Errorf("%w: funding can't convert between %q of account %d and %q of account %d",
+			domainErrors.ErrValidationFailed, sourceAccount.Currency, sourceID, destAccount.Currency, destID)
+	}
 
-		// Calculate new balances
-		sourceNewBalance := sourceAccount.Balance.Sub(req.Amount)
-		destNewBalance := destAccount.Balance.Add(req.Amount)
+	if !isCrossCurrency(sourceAccount, destAccount) {
+		if err := s.checkAmountForCurrency(ctx, destAccount, amount); err != nil {
+			return nil, err
+		}
+	}
 
-		logger.Info("Updating source account %d balance: %s -> %s",
-			req.SourceAccountID, sourceAccount.Balance.String(), sourceNewBalance.String())
+	destTenant, err := s.tenantSettings(ctx, destAccount)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenantCurrency(destAccount, destTenant); err != nil {
+		return nil, err
+	}
 
-		// Update source account balance
-		err = s.accountRepo.UpdateBalanceWithTx(ctx, tx, req.SourceAccountID, sourceNewBalance)
-		if err != nil {
-			logger.Error("Failed to update source account %d balance: %v", req.SourceAccountID, err)
-			return err
+	var suspended *models.SuspenseItem
+	if !destAccount.CanReceiveCredits() {
+		if !opts.allowSuspense || s.suspense == nil {
+			s.log.WarnContext(ctx, "Destination account can't receive credits", logger.AccountID(destID), "status", destAccount.Status)
+			if destAccount.IsFrozen() {
+				return nil, domainErrors.NewAccountFrozenError(destID)
+			}
+			return nil, domainErrors.NewAccountNotActiveError(destID)
+		}
+		suspended = &models.SuspenseItem{
+			SourceAccountID:      sourceID,
+			DestinationAccountID: destID,
+			Amount:               amount,
+			Reason:               fmt.Sprintf("destination account %d is %s", destID, destAccount.Status),
+		}
+		s.log.WarnContext(ctx, "Destination account can't receive credits, crediting the suspense account", logger.AccountID(destID), "status", destAccount.Status, "suspense_account_id", s.suspense.accountID)
+		if destAccount, err = s.suspenseAccountWithTx(ctx, tx); err != nil {
+			return nil, err
 		}
+		destID = destAccount.AccountID
+	}
 
-		logger.Info("Updating destination account %d balance: %s -> %s",
-			req.DestinationAccountID, destAccount.Balance.String(), destNewBalance.String())
+	s.log.InfoContext(ctx, "Destination account balance", logger.AccountID(destID), "balance", destAccount.Balance.String())
 
-		// Update destination account balance
-		err = s.accountRepo.UpdateBalanceWithTx(ctx, tx, req.DestinationAccountID, destNewBalance)
-		if err != nil {
-			logger.Error("Failed to update destination account %d balance: %v", req.DestinationAccountID, err)
-			return err
+	// Convert the amount credited if the destination keeps another currency
+	credit := amount
+	var conversion *fxConversion
+	if isCrossCurrency(sourceAccount, destAccount) {
+		if conversion, err = s.convert(ctx, sourceAccount, destAccount, amount, opts.conversion); err != nil {
+			return nil, err
+		}
+		credit = conversion.converted
+		if suspended != nil {
+			suspended.Amount = credit
 		}
+	}
 
-		// Mark transaction as complete
-		transaction.Status = models.TransactionStatusComplete
+	// Calculate new balances
+	sourceNewBalance := sourceAccount.Balance.Sub(debit)
+	destNewBalance := destAccount.Balance.Add(credit)
+	shadow.expect(sourceNewBalance, destNewBalance, suspended == nil && conversion == nil)
 
-		logger.Info("Recording transaction: source=%d, destination=%d, amount=%s, status=%s",
-			transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount.String(), transaction.Status)
+	s.log.InfoContext(ctx, "Updating source account balance",
+		logger.AccountID(sourceID), "balance", sourceAccount.Balance.String(), "new_balance", sourceNewBalance.String())
 
-		// Record the transaction and get the created transaction with ID
-		createdTx, err := s.transactionRepo.CreateTransactionWithTx(ctx, tx, transaction)
-		if err != nil {
-			logger.Error("Failed to record transaction: %v", err)
-			return err
+	// Update source account balance
+	if err := s.accountRepo.UpdateBalanceWithTx(ctx, tx, sourceID, sourceNewBalance); err != nil {
+		s.log.ErrorContext(ctx, "Failed to update source account balance", logger.AccountID(sourceID), "err", err)
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Updating destination account balance",
+		logger.AccountID(destID), "balance", destAccount.Balance.String(), "new_balance", destNewBalance.String())
+
+	// Update destination account balance
+	if err := s.accountRepo.UpdateBalanceWithTx(ctx, tx, destID, destNewBalance); err != nil {
+		s.log.ErrorContext(ctx, "Failed to update destination account balance", logger.AccountID(destID), "err", err)
+		return nil, err
+	}
+
+	// Mark transaction as complete
+	completed := *transaction
+	completed.DestinationAccountID = destID
+	completed.Status = models.TransactionStatusComplete
+	completed.BusinessDate = s.calendar.Date(s.now())
+	if conversion != nil {
+		completed.ConvertedAmount = &conversion.converted
+		completed.FXRate = &conversion.rate
+	}
+
+	s.log.InfoContext(ctx, "Recording transaction",
+		"source_account_id", sourceID, "destination_account_id", destID, "amount", amount.String(), "status", completed.Status)
+
+	// Record the transaction and get the created transaction with ID; a transfer held for
+	// approval was recorded when it was requested
+	var createdTx *models.Transaction
+	if opts.approved {
+		createdTx, err = s.transactionRepo.ResolvePendingApprovalWithTx(ctx, tx, &completed)
+	} else {
+		createdTx, err = s.transactionRepo.CreateTransactionWithTx(ctx, tx, &completed)
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to record transaction", "err", err)
+		return nil, err
+	}
+
+	if err := s.recordStatusChangeWithTx(ctx, tx, createdTx.ID, transaction.Status, createdTx.Status); err != nil {
+		return nil, err
+	}
+
+	if err := s.postTransferWithTx(ctx, tx, createdTx, postingType(transaction, opts), sourceAccount, destAccount); err != nil {
+		return nil, err
+	}
+
+	if len(fees) > 0 {
+		if createdTx.Fees, err = s.collectFeesWithTx(ctx, tx, createdTx, sourceAccount, fees); err != nil {
+			return nil, err
 		}
+	}
+
+	if overridden {
+		if err := s.recordMinimumBalanceOverrideWithTx(ctx, tx, createdTx, sourceAccount, opts.minimumBalanceOverride); err != nil {
+			return nil, err
+		}
+	}
 
-		// Convert to response DTO
-		createdTransaction = &dto.TransactionResponse{
-			ID:                   createdTx.ID,
-			SourceAccountID:      createdTx.SourceAccountID,
-			DestinationAccountID: createdTx.DestinationAccountID,
-			Amount:               createdTx.Amount,
-			CreatedAt:            createdTx.CreatedAt,
+	if len(flagged) > 0 {
+		if err := s.recordVelocityFlagsWithTx(ctx, tx, createdTx, flagged); err != nil {
+			return nil, err
 		}
+	}
 
-		logger.Info("Transaction completed successfully: id=%d, source=%d, destination=%d, amount=%s",
-			createdTx.ID, req.SourceAccountID, req.DestinationAccountID, req.Amount.String())
+	if err := s.recordDelegatedTransferWithTx(ctx, tx, delegation, createdTx); err != nil {
+		return nil, err
+	}
 
-		return nil
-	})
+	if suspended != nil {
+		suspended.TransactionID = createdTx.ID
+		if err := s.recordSuspenseItemWithTx(ctx, tx, suspended); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.raiseEventWithTx(ctx, tx, models.EventTransactionCompleted, createdTx); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Transaction completed", logger.TransactionID(createdTx.ID),
+		"source_account_id", sourceID, "destination_account_id", destID, "amount", amount.String())
+	return createdTx, nil
+}
+
+// checkAmountForCurrency checks amount is a whole number of minor units of the account's currency
+func (s *transactionService) checkAmountForCurrency(ctx context.Context, account *models.Account, amount decimal.Decimal) error {
+	if err := models.ValidateAmountForCurrency(amount, account.Currency); err != nil {
+		s.log.WarnContext(ctx, "Amount is not representable in the currency of account", "amount", amount, "currency", account.Currency, logger.AccountID(account.AccountID))
+		return domainErrors.WithAccount(err, account.AccountID)
+	}
+	return nil
+}
+
+// GetStatement builds an account statement for [from, to) on the given time axis
+func (s *transactionService) GetStatement(ctx context.Context, accountID int64, from, to time.Time, axis models.TimeAxis) (*models.Statement, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Building statement for account", logger.AccountID(accountID), "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339), "axis", axis)
+
+	if !axis.IsValid() || !from.Before(to) {
+		s.log.WarnContext(ctx, "Invalid statement parameters for account", logger.AccountID(accountID), "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339), "axis", axis)
+		return nil, domainErrors.ErrValidationFailed
+	}
+
+	openingBalance, err := s.transactionRepo.GetBalanceAsOf(ctx, accountID, from, axis)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to compute opening balance for account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
 
+	transactions, err := s.transactionRepo.GetTransactionsByAccountInPeriod(ctx, accountID, from, to, axis)
 	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve statement transactions for account", logger.AccountID(accountID), "err", err)
 		return nil, err
 	}
 
-	return createdTransaction, nil
+	// Derive the closing balance from the listed transactions so the statement always adds up
+	closingBalance := openingBalance
+	for _, tx := range transactions {
+		if !tx.IsSettled() {
+			continue
+		}
+		if tx.DestinationAccountID == accountID {
+			closingBalance = closingBalance.Add(tx.CreditAmount())
+		} else {
+			closingBalance = closingBalance.Sub(tx.Amount)
+		}
+	}
+
+	s.log.InfoContext(ctx, "Successfully built statement for account", logger.AccountID(accountID), "count", len(transactions), "opening_balance", openingBalance, "closing_balance", closingBalance)
+	return &models.Statement{
+		AccountID:      accountID,
+		Axis:           axis,
+		From:           from.Format(time.RFC3339),
+		To:             to.Format(time.RFC3339),
+		OpeningBalance: openingBalance,
+		ClosingBalance: closingBalance,
+		Transactions:   transactions,
+	}, nil
+}
+
+// GetBalanceAsOf returns an account's balance at the given instant on the given time axis
+func (s *transactionService) GetBalanceAsOf(ctx context.Context, accountID int64, at time.Time, axis models.TimeAxis) (decimal.Decimal, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return decimal.Zero, err
+	}
+
+	s.log.InfoContext(ctx, "Retrieving balance of account", "at", at.Format(time.RFC3339), "axis", axis, logger.AccountID(accountID))
+
+	if !axis.IsValid() {
+		s.log.WarnContext(ctx, "Invalid time axis for balance query", "axis", axis)
+		return decimal.Zero, domainErrors.ErrValidationFailed
+	}
+
+	balance, err := s.transactionRepo.GetBalanceAsOf(ctx, accountID, at, axis)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve balance of account", logger.AccountID(accountID), "err", err)
+		return decimal.Zero, err
+	}
+	return balance, nil
+}
+
+// Bounds on the number of transactions returned by a tag search
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// GetTransaction retrieves a transaction by its ID, e.g. to poll the status of a transfer
+func (s *transactionService) GetTransaction(ctx context.Context, transactionID int64) (*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Retrieving transaction", logger.TransactionID(transactionID))
+
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve transaction", logger.TransactionID(transactionID), "err", err)
+		return nil, err
+	}
+
+	feeTransactions, err := s.transactionRepo.GetFeeTransactions(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(feeTransactions) > 0 {
+		transaction.Fees = models.FeesFromTransactions(feeTransactions)
+	}
+	return transaction, nil
+}
+
+// SearchTransactionsByTag retrieves transactions carrying tag recorded in [from, to), newest first.
+// A zero from or to leaves that end open; limit defaults to 100 and is capped at 1000.
+func (s *transactionService) SearchTransactionsByTag(ctx context.Context, tag string, from, to time.Time, limit int) ([]*models.Transaction, error) {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Searching transactions by tag", "tag", tag, "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339), "limit", limit)
+
+	if err := models.ValidateTags([]string{tag}); err != nil {
+		s.log.WarnContext(ctx, "Invalid search tag", "tag", tag)
+		return nil, err
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		s.log.WarnContext(ctx, "Invalid search range", "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339))
+		return nil, fmt.Errorf("%w: from must be before to", domainErrors.ErrValidationFailed)
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	} else if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	transactions, err := s.transactionRepo.SearchTransactionsByTag(ctx, tag, from, to, limit)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to search transactions by tag", "tag", tag, "err", err)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListAccountTransactions retrieves one page of an account's transactions matching filter, newest
+// first, continuing from cursor (from the newest if empty). limit defaults to 50 and is capped at
+// 500. A cursor is only meaningful with the filter it was issued under.
+func (s *transactionService) ListAccountTransactions(ctx context.Context, accountID int64, filter models.TransactionFilter, limit int, cursor string) (*models.TransactionPage, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Listing transactions for account", logger.AccountID(accountID), "filter", filter, "limit", limit)
+
+	if err := filter.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Invalid transaction filter for account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = models.DefaultTransactionPageSize
+	} else if limit > models.MaxTransactionPageSize {
+		limit = models.MaxTransactionPageSize
+	}
+	var after *models.TransactionCursor
+	if cursor != "" {
+		decoded, err := models.DecodeTransactionCursor(cursor)
+		if err != nil {
+			s.log.WarnContext(ctx, "Invalid cursor for account", logger.AccountID(accountID), "cursor", cursor)
+			return nil, err
+		}
+		after = &decoded
+	}
+
+	if _, err := s.accountRepo.GetAccount(ctx, accountID); err != nil {
+		s.log.ErrorContext(ctx, "Failed to retrieve account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+
+	page, err := s.transactionRepo.GetTransactionsByAccountPage(ctx, accountID, filter, limit, after)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to list transactions for account", logger.AccountID(accountID), "err", err)
+		return nil, err
+	}
+	return page, nil
+}
+
+// TagTransaction replaces the tags of a recorded transaction
+func (s *transactionService) TagTransaction(ctx context.Context, transactionID int64, tags []string) error {
+	if err := auth.Require(ctx, auth.ScopeAdmin); err != nil {
+		return err
+	}
+
+	s.log.InfoContext(ctx, "Tagging transaction", logger.TransactionID(transactionID), "tags", tags)
+
+	if err := models.ValidateTags(tags); err != nil {
+		s.log.WarnContext(ctx, "Invalid tags for transaction", logger.TransactionID(transactionID), "err", err)
+		return err
+	}
+
+	if err := s.transactionRepo.SetTransactionTags(ctx, transactionID, tags); err != nil {
+		s.log.ErrorContext(ctx, "Failed to tag transaction", logger.TransactionID(transactionID), "err", err)
+		return err
+	}
+	return nil
 }
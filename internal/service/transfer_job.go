@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/auth"
+	domainErrors "github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/worker"
+)
+
+// maxTransferJobRetryDelay caps the backoff between attempts of a transfer job
+const maxTransferJobRetryDelay = time.Hour
+
+// transferJobConfig is the queue of asynchronously submitted transfers and how failed attempts
+// are retried
+type transferJobConfig struct {
+	repo   repository.TransferJobRepository
+	policy worker.Policy
+}
+
+// WithTransferJobs enables asynchronous transfer submission. Submitted transfers are queued in
+// repo and made by ExecuteQueuedTransferJobs; a transfer the rules reject fails its job at once,
+// while other failures are retried after retryDelay, doubling with each further failure up to an
+// hour, until maxAttempts attempts were made. Failed jobs are dead-lettered.
+func WithTransferJobs(repo repository.TransferJobRepository, maxAttempts int, retryDelay time.Duration) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.transferJobs = &transferJobConfig{repo: repo, policy: worker.NewPolicy(maxAttempts, retryDelay, maxTransferJobRetryDelay)}
+	}
+}
+
+// transferJobRepo returns the transfer job queue, or an error if asynchronous transfers are disabled
+func (s *transactionService) transferJobRepo() (repository.TransferJobRepository, error) {
+	if s.transferJobs == nil {
+		return nil, fmt.Errorf("%w: asynchronous transfers are not enabled", domainErrors.ErrValidationFailed)
+	}
+	return s.transferJobs.repo, nil
+}
+
+// SubmitTransferJob queues a transfer to be made by a background worker on behalf of the actor of
+// ctx and returns its job, to be claimed in the priority class of ctx. Only the request itself is
+// validated; balances and the other transfer rules are checked when the transfer is made. A submission carrying the idempotency key of an
+// earlier job returns that job instead of queueing another, or ErrIdempotencyConflict if it asks
+// for a different transfer. Job keys are separate from those of synchronous transfers and, like
+// them, belong to the caller (auth.Owner).
+func (s *transactionService) SubmitTransferJob(ctx context.Context, req *dto.CreateTransactionRequest) (*models.TransferJob, error) {
+	if err := auth.Require(ctx, auth.ScopeTransfersCreate); err != nil {
+		return nil, err
+	}
+	if err := rejectDeferredOnBehalfOf(ctx); err != nil {
+		return nil, err
+	}
+
+	s.log.InfoContext(ctx, "Submitting transfer job", "source_account_id", req.SourceAccountID, "destination_account_id", req.DestinationAccountID, "amount", req.Amount)
+
+	repo, err := s.transferJobRepo()
+	if err != nil {
+		return nil, err
+	}
+	transaction := &models.Transaction{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Status:               models.TransactionStatusPending,
+	}
+	if err := transaction.Validate(); err != nil {
+		s.log.WarnContext(ctx, "Transfer job validation failed", "err", err)
+		return nil, err
+	}
+	key := idempotency.KeyFromContext(ctx)
+	if key != "" {
+		if err := models.ValidateIdempotencyKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	job, created, err := repo.CreateTransferJob(ctx, &models.TransferJob{
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Priority:             priority.FromContext(ctx),
+		SubmittedBy:          actor.FromContext(ctx),
+		IdempotencyKey:       key,
+		Owner:                auth.Owner(ctx),
+	}, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		if !job.SameTransfer(req.SourceAccountID, req.DestinationAccountID, req.Amount) {
+			s.log.WarnContext(ctx, "Idempotency key reused for a different transfer job", "key", key)
+			return nil, fmt.Errorf("%w: key %q", domainErrors.ErrIdempotencyConflict, key)
+		}
+		s.log.InfoContext(ctx, "Transfer job already submitted under idempotency key", "job_id", job.ID, "key", key)
+	}
+	return job, nil
+}
+
+// GetTransferJob retrieves a transfer job by its ID, with its history
+func (s *transactionService) GetTransferJob(ctx context.Context, jobID int64) (*models.TransferJob, error) {
+	if err := auth.Require(ctx, auth.ScopeAccountsRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.transferJobRepo()
+	if err != nil {
+		return nil, err
+	}
+	job, err := repo.GetTransferJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.History, err = repo.GetHistory(ctx, jobID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ExecuteQueuedTransferJobs makes up to limit queued transfer jobs that are ready on the service
+// clock and returns the number attempted. Each transfer is made in its own database transaction
+// like a synchronous one, as the actor that submitted it, fees and approvals included, and its job
+// is completed in the same transaction. Concurrent callers claim different jobs, so any number of
+// workers can call it.
+func (s *transactionService) ExecuteQueuedTransferJobs(ctx context.Context, limit int) (int, error) {
+	repo, err := s.transferJobRepo()
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for attempted < limit {
+		var claimed *models.TransferJob
+		err := s.inLane(ctx, func() error {
+			return s.withTransaction(ctx, func(tx *sql.Tx) error {
+				var err error
+				if claimed, err = repo.ClaimQueuedWithTx(ctx, tx, s.now()); err != nil || claimed == nil {
+					return err
+				}
+				transaction := &models.Transaction{
+					SourceAccountID:      claimed.SourceAccountID,
+					DestinationAccountID: claimed.DestinationAccountID,
+					Amount:               claimed.Amount,
+					Status:               models.TransactionStatusPending,
+				}
+				if err := transaction.Validate(); err != nil {
+					return err
+				}
+				jobCtx := actor.WithActor(ctx, claimed.SubmittedBy)
+				made, err := s.transferWithTx(jobCtx, tx, transaction, transferOptions{chargeFees: true, approvable: true})
+				if err != nil {
+					return err
+				}
+				if err := repo.MarkCompletedWithTx(ctx, tx, claimed.ID, made.ID); err != nil {
+					return err
+				}
+				return repo.AddEventWithTx(ctx, tx, &models.TransferJobEvent{
+					JobID:      claimed.ID,
+					Action:     models.TransferJobActionCompleted,
+					FromStatus: claimed.Status,
+					ToStatus:   models.TransferJobStatusCompleted,
+					Priority:   claimed.Priority,
+					Actor:      models.TransferJobActorSystem,
+				})
+			})
+		})
+		if claimed == nil {
+			// Nothing left to claim, or the claim itself failed
+			return attempted, err
+		}
+		attempted++
+		if err != nil {
+			if err := s.recordTransferJobFailure(ctx, claimed, err); err != nil {
+				return attempted, err
+			}
+		}
+	}
+	return attempted, nil
+}
+
+// retryableTransferJobError checks if a failed attempt may succeed when retried: the transfer
+// rules rejecting it are final, but the database or this region failing it may not be
+func retryableTransferJobError(err error) bool {
+	switch domainErrors.Code(err) {
+	case "internal_error", "database_error", "read_only", "write_fenced":
+		return true
+	}
+	return false
+}
+
+// recordTransferJobFailure records a failed attempt of job, failing and dead-lettering it if the
+// transfer was rejected or its attempts ran out, and scheduling its retry otherwise
+func (s *transactionService) recordTransferJobFailure(ctx context.Context, job *models.TransferJob, attemptErr error) error {
+	policy := s.transferJobs.policy
+	maxAttempts := policy.MaxAttempts
+	if !retryableTransferJobError(attemptErr) {
+		maxAttempts = 1
+	}
+	nextAttemptAt := s.now().Add(policy.Backoff(job.Attempts + 1))
+
+	var updated *models.TransferJob
+	err := s.withTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		updated, err = s.transferJobs.repo.RecordFailureWithTx(ctx, tx, job.ID, domainErrors.Code(attemptErr), attemptErr.Error(), nextAttemptAt, maxAttempts)
+		if err != nil || updated.Status != models.TransferJobStatusFailed {
+			return err
+		}
+		err = s.transferJobs.repo.AddEventWithTx(ctx, tx, &models.TransferJobEvent{
+			JobID:      updated.ID,
+			Action:     models.TransferJobActionFailed,
+			FromStatus: job.Status,
+			ToStatus:   updated.Status,
+			Priority:   updated.Priority,
+			Actor:      models.TransferJobActorSystem,
+			Reason:     updated.LastError,
+		})
+		if err != nil {
+			return err
+		}
+		return s.deadLetterWithTx(ctx, tx, models.DeadLetterKindTransferJob, updated.ID, updated.Attempts, updated.LastError, updated)
+	})
+	if err != nil {
+		return err
+	}
+	if updated.Status == models.TransferJobStatusFailed {
+		s.log.ErrorContext(ctx, "Transfer job failed, giving up", "job_id", job.ID, "attempts", updated.Attempts, "err", attemptErr)
+	} else {
+		s.log.WarnContext(ctx, "Transfer job attempt failed, retrying", "job_id", job.ID, "attempts", updated.Attempts, "next_attempt_at", updated.NextAttemptAt, "err", attemptErr)
+	}
+	return nil
+}
+
+// CancelTransferJob cancels a transfer job still queued, so it is never made, and records the
+// operator of ctx and reason in its history
+func (s *transactionService) CancelTransferJob(ctx context.Context, jobID int64, reason string) (*models.TransferJob, error) {
+	return s.actOnTransferJob(ctx, jobID, models.TransferJobActionCancelled, reason, func(tx *sql.Tx, repo repository.TransferJobRepository, job *models.TransferJob) (*models.TransferJob, error) {
+		if !job.IsQueued() {
+			return nil, fmt.Errorf("%w: job %d is %s", domainErrors.ErrTransferJobResolved, jobID, job.Status)
+		}
+		return repo.CancelWithTx(ctx, tx, jobID)
+	})
+}
+
+// RequeueTransferJob queues a transfer job that failed, or is waiting to retry, to be attempted
+// again at once with all its attempts, and records the operator of ctx and reason in its history.
+// An attempt runs in the transaction that claims the job, so a job is never left half-made: one a
+// worker is attempting is requeued once the attempt ends, if it failed.
+func (s *transactionService) RequeueTransferJob(ctx context.Context, jobID int64, reason string) (*models.TransferJob, error) {
+	return s.actOnTransferJob(ctx, jobID, models.TransferJobActionRequeued, reason, func(tx *sql.Tx, repo repository.TransferJobRepository, job *models.TransferJob) (*models.TransferJob, error) {
+		if !job.IsQueued() && job.Status != models.TransferJobStatusFailed {
+			return nil, fmt.Errorf("%w: job %d is %s", domainErrors.ErrTransferJobResolved, jobID, job.Status)
+		}
+		return repo.RequeueWithTx(ctx, tx, jobID, s.now())
+	})
+}
+
+// SetTransferJobPriority changes the priority class a queued transfer job is claimed in; workers
+// claim the most urgent ready job first. The operator of ctx and reason are recorded in its history.
+func (s *transactionService) SetTransferJobPriority(ctx context.Context, jobID int64, class priority.Class, reason string) (*models.TransferJob, error) {
+	class, err := priority.ParseClass(string(class))
+	if err != nil {
+		return nil, err
+	}
+	return s.actOnTransferJob(ctx, jobID, models.TransferJobActionPrioritized, reason, func(tx *sql.Tx, repo repository.TransferJobRepository, job *models.TransferJob) (*models.TransferJob, error) {
+		if !job.IsQueued() {
+			return nil, fmt.Errorf("%w: job %d is %s", domainErrors.ErrTransferJobResolved, jobID, job.Status)
+		}
+		return repo.SetPriorityWithTx(ctx, tx, jobID, class)
+	})
+}
+
+// actOnTransferJob applies an operator action to a locked transfer job with act and records it in
+// the job's history in the same transaction
+func (s *transactionService) actOnTransferJob(ctx context.Context, jobID int64, action, reason string, act func(tx *sql.Tx, repo repository.TransferJobRepository, job *models.TransferJob) (*models.TransferJob, error)) (*models.TransferJob, error) {
+	if err := auth.Require(ctx, auth.ScopeOperations); err != nil {
+		return nil, err
+	}
+	operator := actor.FromContext(ctx)
+	s.log.InfoContext(ctx, "Acting on transfer job", "job_id", jobID, "action", action, "actor", operator)
+
+	repo, err := s.transferJobRepo()
+	if err != nil {
+		return nil, err
+	}
+	if len(operator) > models.MaxAuditActorLength {
+		return nil, fmt.Errorf("%w: actor must be at most %d characters", domainErrors.ErrValidationFailed, models.MaxAuditActorLength)
+	}
+	if len(reason) > models.MaxTransferJobReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", domainErrors.ErrValidationFailed, models.MaxTransferJobReasonLength)
+	}
+
+	var updated *models.TransferJob
+	err = s.withTransaction(ctx, func(tx *sql.Tx) error {
+		job, err := repo.GetTransferJobForUpdateWithTx(ctx, tx, jobID)
+		if err != nil {
+			return err
+		}
+		if updated, err = act(tx, repo, job); err != nil {
+			return err
+		}
+		return repo.AddEventWithTx(ctx, tx, &models.TransferJobEvent{
+			JobID:      jobID,
+			Action:     action,
+			FromStatus: job.Status,
+			ToStatus:   updated.Status,
+			Priority:   updated.Priority,
+			Actor:      operator,
+			Reason:     reason,
+		})
+	})
+	if err != nil {
+		s.log.WarnContext(ctx, "Failed to act on transfer job", "job_id", jobID, "action", action, "err", err)
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "Acted on transfer job", "job_id", jobID, "action", action, "status", updated.Status, "priority", updated.Priority)
+	return s.GetTransferJob(ctx, jobID)
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/priority"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferJobs(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := actor.WithActor(context.Background(), "importer")
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	transactionRepo := repository.NewTransactionRepository(db)
+
+	disabled := NewTransactionService(transactionRepo, accountRepo, db)
+	_, err := disabled.SubmitTransferJob(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	svc := NewTransactionService(transactionRepo, accountRepo, db,
+		WithTransferJobs(repository.NewTransferJobRepository(db), 3, time.Second),
+		WithDeadLetters(repository.NewDeadLetterRepository(db)))
+
+	// Submission only queues the transfer; resubmitting under the same key returns the same job
+	keyed := idempotency.WithKey(ctx, "import-1")
+	job, err := svc.SubmitTransferJob(keyed, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(40)})
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusQueued, job.Status)
+	assert.Equal(t, "importer", job.SubmittedBy)
+	again, err := svc.SubmitTransferJob(keyed, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(40)})
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, again.ID)
+	_, err = svc.SubmitTransferJob(keyed, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(41)})
+	assert.ErrorIs(t, err, errors.ErrIdempotencyConflict)
+	_, err = svc.SubmitTransferJob(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 1, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, errors.ErrSameAccount)
+
+	// Balances are only checked when the transfer is made
+	overdrawn, err := svc.SubmitTransferJob(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(1000)})
+	require.NoError(t, err)
+	account, err := accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(100).Equal(account.Balance))
+
+	attempted, err := svc.ExecuteQueuedTransferJobs(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+
+	done, err := svc.GetTransferJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusCompleted, done.Status)
+	assert.Equal(t, 1, done.Attempts)
+	assert.NotZero(t, done.CompletedAt)
+	made, err := transactionRepo.GetTransactionByID(ctx, done.TransactionID)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(40).Equal(made.Amount))
+	account, err = accountRepo.GetAccount(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(60).Equal(account.Balance))
+
+	// A transfer the rules reject fails its job without retries
+	failed, err := svc.GetTransferJob(ctx, overdrawn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusFailed, failed.Status)
+	assert.Equal(t, "insufficient_balance", failed.ErrorCode)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Zero(t, failed.TransactionID)
+	dead, err := svc.ListDeadLetters(ctx, models.DeadLetterKindTransferJob, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, overdrawn.ID, dead[0].ReferenceID)
+	assert.Equal(t, 1, dead[0].Attempts)
+	assert.Contains(t, string(dead[0].Payload), `"error_code":"insufficient_balance"`)
+	_, err = svc.ListDeadLetters(ctx, "outbox", 0)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	attempted, err = svc.ExecuteQueuedTransferJobs(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+
+	_, err = svc.GetTransferJob(ctx, overdrawn.ID+100)
+	assert.ErrorIs(t, err, errors.ErrTransferJobNotFound)
+}
+
+func TestTransferJobAdmin(t *testing.T) {
+	t.Parallel()
+
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx := actor.WithActor(context.Background(), "importer")
+	ops := actor.WithActor(context.Background(), "ops")
+	accountRepo := repository.NewAccountRepository(db)
+	require.NoError(t, accountRepo.CreateAccount(ctx, 1, decimal.NewFromInt(100)))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 2, decimal.Zero))
+	require.NoError(t, accountRepo.CreateAccount(ctx, 3, decimal.NewFromInt(1000)))
+	transactionRepo := repository.NewTransactionRepository(db)
+	svc := NewTransactionService(transactionRepo, accountRepo, db,
+		WithTransferJobs(repository.NewTransferJobRepository(db), 3, time.Second),
+		WithDeadLetters(repository.NewDeadLetterRepository(db)))
+	submit := func(ctx context.Context, amount int64) *models.TransferJob {
+		job, err := svc.SubmitTransferJob(ctx, &dto.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(amount)})
+		require.NoError(t, err)
+		return job
+	}
+
+	// Jobs are submitted in the priority of their request
+	bulk := submit(priority.WithClass(ctx, priority.Bulk), 10)
+	assert.Equal(t, priority.Bulk, bulk.Priority)
+	cancelled := submit(ctx, 20)
+	assert.Equal(t, priority.Normal, cancelled.Priority)
+	overdrawn := submit(ctx, 1000)
+
+	// A queued job can be cancelled, once
+	job, err := svc.CancelTransferJob(ops, cancelled.ID, "sent twice")
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusCancelled, job.Status)
+	require.Len(t, job.History, 2)
+	assert.Equal(t, models.TransferJobActionSubmitted, job.History[0].Action)
+	assert.Equal(t, "importer", job.History[0].Actor)
+	assert.Equal(t, &models.TransferJobEvent{
+		ID: job.History[1].ID, JobID: cancelled.ID, Action: models.TransferJobActionCancelled,
+		FromStatus: models.TransferJobStatusQueued, ToStatus: models.TransferJobStatusCancelled,
+		Priority: priority.Normal, Actor: "ops", Reason: "sent twice", CreatedAt: job.History[1].CreatedAt,
+	}, job.History[1])
+	_, err = svc.CancelTransferJob(ops, cancelled.ID, "")
+	assert.ErrorIs(t, err, errors.ErrTransferJobResolved)
+
+	// A bumped job is claimed first
+	job, err = svc.SetTransferJobPriority(ops, bulk.ID, priority.Critical, "payroll cut-off")
+	require.NoError(t, err)
+	assert.Equal(t, priority.Critical, job.Priority)
+	_, err = svc.SetTransferJobPriority(ops, bulk.ID, "urgent", "")
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	attempted, err := svc.ExecuteQueuedTransferJobs(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	job, err = svc.GetTransferJob(ctx, bulk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusCompleted, job.Status)
+	assert.Equal(t, models.TransferJobActionCompleted, job.History[len(job.History)-1].Action)
+	_, err = svc.SetTransferJobPriority(ops, bulk.ID, priority.Bulk, "")
+	assert.ErrorIs(t, err, errors.ErrTransferJobResolved)
+	_, err = svc.RequeueTransferJob(ops, bulk.ID, "")
+	assert.ErrorIs(t, err, errors.ErrTransferJobResolved)
+
+	// A failed job is requeued with all its attempts and made once it can be
+	attempted, err = svc.ExecuteQueuedTransferJobs(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	job, err = svc.GetTransferJob(ctx, overdrawn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusFailed, job.Status)
+	_, err = svc.CreateTransaction(ctx, &dto.CreateTransactionRequest{SourceAccountID: 3, DestinationAccountID: 1, Amount: decimal.NewFromInt(1000)})
+	require.NoError(t, err)
+	job, err = svc.RequeueTransferJob(ops, overdrawn.ID, "topped up")
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusQueued, job.Status)
+	assert.Zero(t, job.Attempts)
+	assert.Empty(t, job.ErrorCode)
+	attempted, err = svc.ExecuteQueuedTransferJobs(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	job, err = svc.GetTransferJob(ctx, overdrawn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferJobStatusCompleted, job.Status)
+	var actions []string
+	for _, event := range job.History {
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{models.TransferJobActionSubmitted, models.TransferJobActionFailed, models.TransferJobActionRequeued, models.TransferJobActionCompleted}, actions)
+
+	_, err = svc.CancelTransferJob(ops, overdrawn.ID+100, "")
+	assert.ErrorIs(t, err, errors.ErrTransferJobNotFound)
+}
+
+func TestRetryableTransferJobError(t *testing.T) {
+	assert.True(t, retryableTransferJobError(context.DeadlineExceeded))
+	assert.True(t, retryableTransferJobError(errors.ErrFenced))
+	assert.False(t, retryableTransferJobError(errors.NewInsufficientBalanceError(1, decimal.NewFromInt(2), decimal.NewFromInt(1))))
+	assert.False(t, retryableTransferJobError(errors.NewAccountNotFoundError(1)))
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// WithTransferMetrics counts accepted and rejected transfers in m, by error code and by the
+// tenant of the source account
+func WithTransferMetrics(m *metrics.Transfers) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.metrics = m
+	}
+}
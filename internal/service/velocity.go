@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/actor"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/velocity"
+	"github.com/shopspring/decimal"
+)
+
+// WithVelocityChecks checks every outbound transfer against the sliding window rules of tracker.
+// Transfers breaching a reject rule fail; transfers breaching a flag rule go through and, if an
+// audit log is configured, are recorded in it for review.
+func WithVelocityChecks(tracker *velocity.Tracker) TransactionServiceOption {
+	return func(s *transactionService) {
+		s.velocity = tracker
+	}
+}
+
+// admitVelocity counts a transfer of amount from source against the velocity rules. cancel
+// uncounts it and must be called if the transfer fails. A transfer that is rolled back after
+// transferWithTx returned, such as a split leg whose sibling failed, stays counted, which errs
+// on the side of the rules.
+func (s *transactionService) admitVelocity(source *models.Account, amount decimal.Decimal) (flagged []velocity.Breach, cancel func(), err error) {
+	flagged, cancel, err = s.velocity.Admit(source.AccountID, amount, s.now())
+	if err != nil {
+		s.log.Warn("Transfer exceeds the velocity limits of account", "amount", amount, logger.AccountID(source.AccountID))
+		return nil, nil, err
+	}
+	for _, breach := range flagged {
+		s.log.Warn("Transfer flagged by velocity rule", "amount", amount, logger.AccountID(source.AccountID), "breach", breach)
+	}
+	return flagged, cancel, nil
+}
+
+// recordVelocityFlagsWithTx records in audit the velocity rules created was flagged by
+func (s *transactionService) recordVelocityFlagsWithTx(ctx context.Context, tx *sql.Tx, created *models.Transaction, flagged []velocity.Breach) error {
+	if s.audit == nil {
+		return nil
+	}
+	for _, breach := range flagged {
+		err := s.audit.RecordWithTx(ctx, tx, &models.AuditEntry{
+			Action:        models.AuditActionVelocityFlag,
+			Actor:         actor.FromContext(ctx),
+			AccountID:     created.SourceAccountID,
+			TransactionID: created.ID,
+			Details: map[string]string{
+				"rule":   breach.Rule.Name,
+				"window": breach.Rule.Window.String(),
+				"count":  strconv.Itoa(breach.Count),
+				"volume": breach.Volume.String(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package simulator runs randomized concurrent transfers against a real database and verifies
+// the balance invariants afterwards. It is used by the soak tests and for manual correctness runs.
+package simulator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/api/dto"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/idempotency"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/shopspring/decimal"
+)
+
+// Config describes a simulation run
+type Config struct {
+	// FirstAccountID is the ID of the first simulated account; accounts are numbered consecutively
+	FirstAccountID int64
+	// Accounts is the size of the hot account set
+	Accounts int
+	// InitialBalance is the starting balance of every account
+	InitialBalance decimal.Decimal
+	// Transfers is the total number of transfers attempted
+	Transfers int
+	// Concurrency is the number of goroutines submitting transfers
+	Concurrency int
+	// MaxAmount bounds the random transfer amount; amounts have two decimal places
+	MaxAmount decimal.Decimal
+	// Seed seeds the random generator so failing runs can be reproduced
+	Seed int64
+	// MaxRetries retries a transfer failing with an internal error up to this many times, under
+	// the same idempotency key, as a client would after a dropped connection. With 0 transfers
+	// are submitted once and without a key.
+	MaxRetries int
+}
+
+// DefaultConfig returns a configuration producing heavy contention on a small account set
+func DefaultConfig() Config {
+	return Config{
+		FirstAccountID: 900000000,
+		Accounts:       8,
+		InitialBalance: decimal.NewFromInt(1000),
+		Transfers:      2000,
+		Concurrency:    32,
+		MaxAmount:      decimal.NewFromInt(250),
+		Seed:           time.Now().UnixNano(),
+	}
+}
+
+// Result summarises a simulation run
+type Result struct {
+	Attempted int
+	Succeeded int
+	// Rejected counts failed transfers by domain error code (serialization failures
+	// and other database errors are reported as "internal_error")
+	Rejected map[string]int
+	// Retries counts the resubmissions of transfers that failed with an internal error
+	Retries  int
+	Duration time.Duration
+}
+
+// Simulator drives transfers through the TransactionService
+type Simulator struct {
+	db          *sql.DB
+	accountRepo repository.AccountRepository
+	service     service.TransactionService
+	cfg         Config
+	log         *slog.Logger
+}
+
+// New creates a simulator
+func New(db *sql.DB, accountRepo repository.AccountRepository, transactionService service.TransactionService, cfg Config) *Simulator {
+	return &Simulator{
+		db:          db,
+		accountRepo: accountRepo,
+		service:     transactionService,
+		cfg:         cfg,
+		log:         logger.Default(),
+	}
+}
+
+// accountIDs returns the IDs of the simulated accounts
+func (s *Simulator) accountIDs() []int64 {
+	ids := make([]int64, s.cfg.Accounts)
+	for i := range ids {
+		ids[i] = s.cfg.FirstAccountID + int64(i)
+	}
+	return ids
+}
+
+// Setup creates the simulated accounts with their initial balance
+func (s *Simulator) Setup(ctx context.Context) error {
+	for _, id := range s.accountIDs() {
+		if err := s.accountRepo.CreateAccount(ctx, id, s.cfg.InitialBalance); err != nil {
+			return fmt.Errorf("failed to create simulated account %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Run submits the configured number of random transfers concurrently
+func (s *Simulator) Run(ctx context.Context) (*Result, error) {
+	s.log.Info("Starting transfer simulation", "accounts", s.cfg.Accounts, "transfers", s.cfg.Transfers, "concurrency", s.cfg.Concurrency, "seed", s.cfg.Seed)
+
+	// Generate the workload up front so it depends only on the seed, not on scheduling
+	rng := rand.New(rand.NewSource(s.cfg.Seed))
+	ids := s.accountIDs()
+	maxCents := s.cfg.MaxAmount.Mul(decimal.NewFromInt(100)).IntPart()
+	requests := make(chan submission, s.cfg.Transfers)
+	for i := 0; i < s.cfg.Transfers; i++ {
+		source := rng.Intn(len(ids))
+		destination := (source + 1 + rng.Intn(len(ids)-1)) % len(ids)
+		sub := submission{req: &dto.CreateTransactionRequest{
+			SourceAccountID:      ids[source],
+			DestinationAccountID: ids[destination],
+			Amount:               decimal.New(1+rng.Int63n(maxCents), -2),
+		}}
+		if s.cfg.MaxRetries > 0 {
+			sub.key = fmt.Sprintf("sim-%d-%d", s.cfg.Seed, i)
+		}
+		requests <- sub
+	}
+	close(requests)
+
+	result := &Result{Rejected: make(map[string]int)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for w := 0; w < s.cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sub := range requests {
+				if ctx.Err() != nil {
+					return
+				}
+				retries, err := s.submit(ctx, sub)
+
+				mu.Lock()
+				result.Attempted++
+				result.Retries += retries
+				if err != nil {
+					result.Rejected[errors.Code(err)]++
+				} else {
+					result.Succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	result.Duration = time.Since(start)
+
+	s.log.Info("Transfer simulation finished", "attempted", result.Attempted, "succeeded", result.Succeeded, "rejected", result.Rejected, "retries", result.Retries, "duration", result.Duration)
+	return result, ctx.Err()
+}
+
+// submission is a transfer of the workload with the idempotency key it is retried under
+type submission struct {
+	req *dto.CreateTransactionRequest
+	key string
+}
+
+// submit makes a transfer, retrying internal errors up to MaxRetries times, and returns the
+// number of retries and the error of the last attempt
+func (s *Simulator) submit(ctx context.Context, sub submission) (int, error) {
+	ctx = idempotency.WithKey(ctx, sub.key)
+	for retries := 0; ; retries++ {
+		_, err := s.service.CreateTransaction(ctx, sub.req)
+		if err == nil || retries >= s.cfg.MaxRetries || errors.Code(err) != "internal_error" || ctx.Err() != nil {
+			return retries, err
+		}
+	}
+}
+
+// Verify checks the invariants that must hold after a run and returns every violation found:
+//   - total money across the simulated accounts is conserved
+//   - no balance is negative
+//   - each balance equals its initial balance plus the net of its completed transactions
+//     (a lost update would make the two diverge)
+//   - every successful transfer, and nothing else, was recorded
+func (s *Simulator) Verify(ctx context.Context, result *Result) ([]string, error) {
+	var violations []string
+	ids := s.accountIDs()
+
+	total := decimal.Zero
+	for _, id := range ids {
+		account, err := s.accountRepo.GetAccount(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read simulated account %d: %w", id, err)
+		}
+		total = total.Add(account.Balance)
+
+		if account.Balance.IsNegative() {
+			violations = append(violations, fmt.Sprintf("account %d has negative balance %s", id, account.Balance))
+		}
+
+		var net decimal.Decimal
+		err = s.db.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(CASE WHEN destination_account_id = $1 THEN amount ELSE -amount END), 0)
+			FROM transactions
+			WHERE (source_account_id = $1 OR destination_account_id = $1) AND status = $2
+		`, id, models.TransactionStatusComplete).Scan(&net)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sum transactions of account %d: %w", id, err)
+		}
+		if expected := s.cfg.InitialBalance.Add(net); !expected.Equal(account.Balance) {
+			violations = append(violations, fmt.Sprintf("account %d balance %s does not match transaction history (expected %s)",
+				id, account.Balance, expected))
+		}
+	}
+
+	expectedTotal := s.cfg.InitialBalance.Mul(decimal.NewFromInt(int64(len(ids))))
+	if !total.Equal(expectedTotal) {
+		violations = append(violations, fmt.Sprintf("total balance %s differs from funded total %s", total, expectedTotal))
+	}
+
+	var recorded int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM transactions
+		WHERE source_account_id BETWEEN $1 AND $2 AND status = $3
+	`, ids[0], ids[len(ids)-1], models.TransactionStatusComplete).Scan(&recorded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recorded transactions: %w", err)
+	}
+	if recorded != result.Succeeded {
+		violations = append(violations, fmt.Sprintf("%d transactions recorded but %d transfers succeeded", recorded, result.Succeeded))
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,106 @@
+//go:build soak
+
+package simulator
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/chaos"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/khamiruf/internal_transfers_system_go/internal/service"
+	"github.com/khamiruf/internal_transfers_system_go/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrentTransfers_Invariants is a soak test: run with `make test-soak`.
+// SOAK_TRANSFERS and SOAK_SEED override the workload size and seed.
+func TestConcurrentTransfers_Invariants(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	cfg := DefaultConfig()
+	if v, err := strconv.Atoi(os.Getenv("SOAK_TRANSFERS")); err == nil {
+		cfg.Transfers = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv("SOAK_SEED"), 10, 64); err == nil {
+		cfg.Seed = v
+	}
+	t.Logf("seed=%d transfers=%d", cfg.Seed, cfg.Transfers)
+
+	accountRepo := repository.NewAccountRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	sim := New(db, accountRepo, service.NewTransactionService(transactionRepo, accountRepo, db), cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	require.NoError(t, sim.Setup(ctx))
+
+	result, err := sim.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Transfers, result.Attempted)
+	assert.Positive(t, result.Succeeded, "no transfer succeeded: %v", result.Rejected)
+
+	violations, err := sim.Verify(ctx, result)
+	require.NoError(t, err)
+	assert.Empty(t, violations, "invariant violations (seed=%d)", cfg.Seed)
+}
+
+// TestConcurrentTransfers_Chaos runs the soak workload with faults injected into the repository
+// calls of every transfer. Transfers failing with a dropped connection or a serialization failure
+// are retried under their idempotency key, and the invariants must still hold afterwards.
+// CHAOS_SEED overrides the seed of the injected faults.
+func TestConcurrentTransfers_Chaos(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.SetupTestDB(t, db)
+	defer testutil.CleanupTestDB(t, db)
+
+	cfg := DefaultConfig()
+	cfg.Transfers = 500
+	cfg.MaxRetries = 10
+	if v, err := strconv.Atoi(os.Getenv("SOAK_TRANSFERS")); err == nil {
+		cfg.Transfers = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv("SOAK_SEED"), 10, 64); err == nil {
+		cfg.Seed = v
+	}
+	chaosSeed := cfg.Seed
+	if v, err := strconv.ParseInt(os.Getenv("CHAOS_SEED"), 10, 64); err == nil {
+		chaosSeed = v
+	}
+	t.Logf("seed=%d chaos_seed=%d transfers=%d", cfg.Seed, chaosSeed, cfg.Transfers)
+
+	injector := chaos.NewInjector(chaos.Rates{
+		chaos.FaultLatency:       0.1,
+		chaos.FaultDrop:          0.02,
+		chaos.FaultSerialization: 0.02,
+	}, 20*time.Millisecond, chaosSeed)
+	accountRepo := repository.NewAccountRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	svc := service.NewTransactionService(chaos.WrapTransactions(transactionRepo, injector), chaos.WrapAccounts(accountRepo, injector), db,
+		service.WithIdempotencyKeys(repository.NewIdempotencyRepository(db)))
+	sim := New(db, accountRepo, svc, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	require.NoError(t, sim.Setup(ctx))
+
+	result, err := sim.Run(ctx)
+	require.NoError(t, err)
+	stats := injector.Stats()
+	t.Logf("retries=%d rejected=%v injected=%v", result.Retries, result.Rejected, stats.Injected)
+	assert.Equal(t, cfg.Transfers, result.Attempted)
+	assert.Positive(t, result.Succeeded, "no transfer succeeded: %v", result.Rejected)
+	assert.Positive(t, stats.Injected[chaos.FaultDrop]+stats.Injected[chaos.FaultSerialization], "no failure was injected")
+
+	violations, err := sim.Verify(ctx, result)
+	require.NoError(t, err)
+	assert.Empty(t, violations, "invariant violations (seed=%d, chaos_seed=%d)", cfg.Seed, chaosSeed)
+}
@@ -0,0 +1,155 @@
+// Package sweeper periodically cleans up time-limited work that lapsed, such as expired
+// pre-authorizations. Each task sweeps in batches until it runs out of expired items.
+package sweeper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// livenessSubsystem is the subsystem the sweeper beats as
+const livenessSubsystem = "sweeper"
+
+// SweepFunc cleans up at most limit expired items and returns the number it handled
+type SweepFunc func(ctx context.Context, limit int) (int, error)
+
+// Task is one kind of expired work swept on every run
+type Task struct {
+	Name  string
+	Sweep SweepFunc
+}
+
+// TaskStats is a snapshot of the counters of one task
+type TaskStats struct {
+	Runs        uint64 `json:"runs"`
+	Failures    uint64 `json:"failures"`
+	Swept       uint64 `json:"swept"`
+	LastRunAt   string `json:"last_run_at,omitempty"`
+	LastSwept   int    `json:"last_swept"`
+	LastFailure string `json:"last_failure,omitempty"`
+}
+
+// Sweeper runs its tasks on a fixed interval
+type Sweeper struct {
+	tasks     []Task
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+	liveness  *metrics.Workers
+
+	mu    sync.Mutex
+	stats map[string]*TaskStats
+	log   *slog.Logger
+}
+
+// New creates a sweeper running tasks every interval, batchSize items at a time
+func New(interval time.Duration, batchSize int, tasks ...Task) *Sweeper {
+	stats := make(map[string]*TaskStats, len(tasks))
+	for _, task := range tasks {
+		stats[task.Name] = &TaskStats{}
+	}
+	return &Sweeper{
+		tasks:     tasks,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+		stats:     stats,
+		log:       logger.Default(),
+	}
+}
+
+// SetClock makes the sweeper stamp its runs with c instead of the wall clock. Call it before Run.
+func (s *Sweeper) SetClock(c clock.Clock) {
+	s.now = c.Now
+}
+
+// SetLiveness makes the sweeper beat in workers, as the "sweeper" subsystem, after each batch.
+// Call it before Run.
+func (s *Sweeper) SetLiveness(workers *metrics.Workers) {
+	s.liveness = workers
+}
+
+// Run sweeps immediately and then on every interval until ctx is cancelled
+func (s *Sweeper) Run(ctx context.Context) error {
+	s.log.Info("Sweeper started", "tasks", len(s.tasks), "interval", s.interval, "batch_size", s.batchSize)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	s.liveness.Start(livenessSubsystem, 0, s.interval)
+	defer s.liveness.Stop(livenessSubsystem, 0)
+
+	for {
+		s.SweepAll(ctx)
+		select {
+		case <-ctx.Done():
+			s.log.Info("Sweeper stopped")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepAll runs every task once. A failing task doesn't stop the others.
+func (s *Sweeper) SweepAll(ctx context.Context) {
+	for _, task := range s.tasks {
+		if _, err := s.sweep(ctx, task); err != nil {
+			s.log.Error("Sweep failed", "name", task.Name, "err", err)
+		}
+	}
+}
+
+// sweep runs task in batches until a batch comes back short, and returns the number of items swept
+func (s *Sweeper) sweep(ctx context.Context, task Task) (int, error) {
+	swept := 0
+	for {
+		n, err := task.Sweep(ctx, s.batchSize)
+		s.liveness.Beat(livenessSubsystem, 0)
+		swept += n
+		if err != nil {
+			s.record(task.Name, swept, err)
+			return swept, err
+		}
+		if n < s.batchSize {
+			break
+		}
+	}
+
+	if swept > 0 {
+		s.log.Info("Sweep finished", "swept", swept, "name", task.Name)
+	}
+	s.record(task.Name, swept, nil)
+	return swept, nil
+}
+
+// record updates the counters of a task after a sweep
+func (s *Sweeper) record(name string, swept int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats[name]
+	stats.Runs++
+	if err != nil {
+		stats.Failures++
+		stats.LastFailure = err.Error()
+	}
+	stats.Swept += uint64(swept)
+	stats.LastRunAt = s.now().Format(time.RFC3339)
+	stats.LastSwept = swept
+}
+
+// Stats returns the counters of every task, keyed by task name
+func (s *Sweeper) Stats() map[string]TaskStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]TaskStats, len(s.stats))
+	for name, taskStats := range s.stats {
+		stats[name] = *taskStats
+	}
+	return stats
+}
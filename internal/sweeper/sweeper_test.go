@@ -0,0 +1,52 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweeper_SweepAll(t *testing.T) {
+	ctx := context.Background()
+
+	// 5 expired items swept 2 at a time take 3 batches
+	remaining := 5
+	var batches int
+	expire := func(ctx context.Context, limit int) (int, error) {
+		batches++
+		n := limit
+		if remaining < n {
+			n = remaining
+		}
+		remaining -= n
+		return n, nil
+	}
+	failing := func(ctx context.Context, limit int) (int, error) {
+		return 0, errors.New("database unavailable")
+	}
+
+	s := New(0, 2, Task{Name: "preauthorizations", Sweep: expire}, Task{Name: "broken", Sweep: failing})
+	s.SweepAll(ctx)
+
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, 3, batches)
+
+	stats := s.Stats()
+	assert.Equal(t, uint64(1), stats["preauthorizations"].Runs)
+	assert.Equal(t, uint64(5), stats["preauthorizations"].Swept)
+	assert.Equal(t, 5, stats["preauthorizations"].LastSwept)
+	assert.Zero(t, stats["preauthorizations"].Failures)
+
+	// The failing task is recorded without stopping the others
+	assert.Equal(t, uint64(1), stats["broken"].Failures)
+	assert.Equal(t, "database unavailable", stats["broken"].LastFailure)
+
+	// Nothing left to sweep on the next run
+	s.SweepAll(ctx)
+	stats = s.Stats()
+	assert.Equal(t, uint64(2), stats["preauthorizations"].Runs)
+	assert.Equal(t, uint64(5), stats["preauthorizations"].Swept)
+	assert.Equal(t, 0, stats["preauthorizations"].LastSwept)
+}
@@ -0,0 +1,157 @@
+// Package tracecontext propagates W3C Trace Context: it continues the trace of the traceparent
+// header of an incoming request, or starts one, carries it through the request context into log
+// lines, and passes it on to the services the request calls, so a transfer can be followed
+// across the systems it touches.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Headers of the W3C Trace Context recommendation
+const (
+	Header      = "traceparent"
+	StateHeader = "tracestate"
+)
+
+// maxStateLength is the longest tracestate passed on; longer ones are dropped rather than cut
+// mid-entry
+const maxStateLength = 512
+
+// flagSampled is the trace flag asking the services along the trace to record it
+const flagSampled = 0x01
+
+// SpanContext identifies a span: the trace it belongs to, its own ID and the trace flags
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	// State is the vendor-specific tracestate passed along with the trace, unparsed
+	State string
+}
+
+// Valid reports whether sc identifies a span; the all-zero IDs are invalid
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the caller asked for the trace to be recorded
+func (sc SpanContext) Sampled() bool {
+	return sc.Flags&flagSampled != 0
+}
+
+// TraceIDString returns the trace ID as 32 hex characters
+func (sc SpanContext) TraceIDString() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDString returns the span ID as 16 hex characters
+func (sc SpanContext) SpanIDString() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// String formats sc as a traceparent header value
+func (sc SpanContext) String() string {
+	return "00-" + sc.TraceIDString() + "-" + sc.SpanIDString() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// Child returns a new span of the same trace, with the same flags and state
+func (sc SpanContext) Child() SpanContext {
+	child := sc
+	rand.Read(child.SpanID[:])
+	return child
+}
+
+// New starts a new sampled trace
+func New() SpanContext {
+	var sc SpanContext
+	rand.Read(sc.TraceID[:])
+	rand.Read(sc.SpanID[:])
+	sc.Flags = flagSampled
+	return sc
+}
+
+// Parse parses a traceparent header value. Version 00 must have exactly four fields; later
+// versions may append fields, which are ignored, as the recommendation asks. IDs must be lowercase
+// hex and not all zero.
+func Parse(value string) (SpanContext, bool) {
+	var sc SpanContext
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if !lowerHex(parts[0]) || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, false
+	}
+	var flags [1]byte
+	if !decode(sc.TraceID[:], parts[1]) || !decode(sc.SpanID[:], parts[2]) || !decode(flags[:], parts[3]) {
+		return sc, false
+	}
+	sc.Flags = flags[0]
+	return sc, sc.Valid()
+}
+
+func decode(dst []byte, s string) bool {
+	if !lowerHex(s) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+func lowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type contextKey struct{}
+
+// WithSpanContext returns a copy of ctx carrying sc
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, sc)
+}
+
+// FromContext returns the span context carried by ctx; it isn't Valid if ctx carries none
+func FromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(contextKey{}).(SpanContext)
+	return sc
+}
+
+// Inject sets the traceparent and tracestate headers of an outgoing request to a child span of
+// the span of ctx, if it carries one
+func Inject(ctx context.Context, header http.Header) {
+	sc := FromContext(ctx)
+	if !sc.Valid() {
+		return
+	}
+	header.Set(Header, sc.Child().String())
+	if sc.State != "" {
+		header.Set(StateHeader, sc.State)
+	}
+}
+
+// Middleware gives each request a span: a child of the traceparent header if it parses, with its
+// tracestate, or the root of a new trace otherwise, so requests from untraced callers can still
+// be followed downstream
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc, ok := Parse(r.Header.Get(Header))
+		if ok {
+			sc = sc.Child()
+			if state := strings.Join(r.Header.Values(StateHeader), ","); len(state) <= maxStateLength {
+				sc.State = state
+			}
+		} else {
+			sc = New()
+		}
+		next.ServeHTTP(w, r.WithContext(WithSpanContext(r.Context(), sc)))
+	})
+}
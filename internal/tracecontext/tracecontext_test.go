@@ -0,0 +1,82 @@
+package tracecontext
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParse(t *testing.T) {
+	sc, ok := Parse(parent)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceIDString())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanIDString())
+	assert.True(t, sc.Sampled())
+	assert.Equal(t, parent, sc.String())
+
+	// Later versions may append fields
+	_, ok = Parse("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.True(t, ok)
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+	} {
+		_, ok := Parse(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(Header, parent)
+	req.Header.Add(StateHeader, "vendor=a")
+	req.Header.Add(StateHeader, "other=b")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceIDString())
+	assert.NotEqual(t, "00f067aa0ba902b7", got.SpanIDString())
+	assert.Equal(t, "vendor=a,other=b", got.State)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(Header, "garbage")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, got.Valid())
+	assert.True(t, got.Sampled())
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceIDString())
+	assert.Empty(t, got.State)
+}
+
+func TestInject(t *testing.T) {
+	header := http.Header{}
+	Inject(context.Background(), header)
+	assert.Empty(t, header)
+
+	sc, _ := Parse(parent)
+	sc.State = "vendor=a"
+	Inject(WithSpanContext(context.Background(), sc), header)
+
+	child, ok := Parse(header.Get(Header))
+	require.True(t, ok)
+	assert.Equal(t, sc.TraceID, child.TraceID)
+	assert.NotEqual(t, sc.SpanID, child.SpanID)
+	assert.Equal(t, "vendor=a", header.Get(StateHeader))
+}
@@ -0,0 +1,209 @@
+// Package velocity tracks how many transfers each account sends, and how much, over sliding
+// windows, so bursts typical of a compromised account or a runaway integration are caught even
+// when every single transfer is within the account's limits. Each rule either rejects transfers
+// that would breach it or lets them through flagged for review.
+package velocity
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+)
+
+// Action is what happens to a transfer that breaches a rule
+type Action string
+
+// Actions
+const (
+	Reject Action = "reject"
+	Flag   Action = "flag"
+)
+
+// Rule caps the transfers an account sends within Window: at most MaxCount transfers and at most
+// MaxVolume in total. A zero cap doesn't apply.
+type Rule struct {
+	Name      string
+	Window    time.Duration
+	MaxCount  int
+	MaxVolume decimal.Decimal
+	Action    Action
+}
+
+// ParseRules parses rules keyed by name, each "window/max_count/max_volume/action", e.g. from
+// {"burst": "5m/10/0/reject", "hourly": "1h/0/50000/flag"}. Rules are returned sorted by name.
+func ParseRules(raw map[string]string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(raw))
+	for name, spec := range raw {
+		rule, err := parseRule(name, spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+func parseRule(name, spec string) (Rule, error) {
+	invalid := func(reason string) (Rule, error) {
+		return Rule{}, fmt.Errorf("%w: velocity rule %s %q: %s", errors.ErrValidationFailed, name, spec, reason)
+	}
+	parts := strings.Split(spec, "/")
+	if len(parts) != 4 {
+		return invalid("must be window/max_count/max_volume/action")
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	rule := Rule{Name: name, Action: Action(strings.ToLower(parts[3]))}
+	var err error
+	if rule.Window, err = time.ParseDuration(parts[0]); err != nil || rule.Window <= 0 {
+		return invalid("window must be a positive duration")
+	}
+	if rule.MaxCount, err = strconv.Atoi(parts[1]); err != nil || rule.MaxCount < 0 {
+		return invalid("max_count must be a non-negative integer")
+	}
+	if rule.MaxVolume, err = decimal.NewFromString(parts[2]); err != nil || rule.MaxVolume.IsNegative() {
+		return invalid("max_volume must be a non-negative amount")
+	}
+	if rule.MaxCount == 0 && rule.MaxVolume.IsZero() {
+		return invalid("at least one of max_count and max_volume must be set")
+	}
+	if rule.Action != Reject && rule.Action != Flag {
+		return invalid("action must be reject or flag")
+	}
+	return rule, nil
+}
+
+// Breach is a rule a transfer would break, with the count and volume the account would reach
+// within the rule's window
+type Breach struct {
+	Rule   Rule
+	Count  int
+	Volume decimal.Decimal
+}
+
+// String describes the breach for logs and audit entries
+func (b Breach) String() string {
+	return fmt.Sprintf("%s: %d transfers totalling %s within %s", b.Rule.Name, b.Count, b.Volume.String(), b.Rule.Window)
+}
+
+// event is a transfer admitted by the tracker
+type event struct {
+	seq    uint64
+	at     time.Time
+	amount decimal.Decimal
+}
+
+// Tracker keeps the recent transfers of each account in memory and checks new ones against the
+// rules. Counts are per process: with several instances each enforces the rules on the transfers
+// it makes. A nil *Tracker admits everything.
+type Tracker struct {
+	mu        sync.Mutex
+	rules     []Rule
+	horizon   time.Duration // longest window; older events no longer count
+	events    map[int64][]event
+	seq       uint64
+	lastPrune time.Time
+}
+
+// NewTracker creates a tracker enforcing rules
+func NewTracker(rules []Rule) *Tracker {
+	t := &Tracker{rules: rules, events: make(map[int64][]event)}
+	for _, rule := range rules {
+		t.horizon = max(t.horizon, rule.Window)
+	}
+	return t
+}
+
+// Admit checks a transfer of amount from accountID made at now against the rules. If a reject
+// rule would be breached the transfer isn't counted and ErrVelocityExceeded is returned.
+// Otherwise the transfer is counted and the flag rules it breaches are returned; cancel uncounts
+// it, for a transfer that failed after being admitted.
+func (t *Tracker) Admit(accountID int64, amount decimal.Decimal, now time.Time) (flagged []Breach, cancel func(), err error) {
+	if t == nil || len(t.rules) == 0 {
+		return nil, func() {}, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+	events := t.events[accountID]
+	for _, rule := range t.rules {
+		breach, ok := check(rule, events, amount, now)
+		if !ok {
+			continue
+		}
+		if rule.Action == Reject {
+			limit := decimal.NewFromInt(int64(rule.MaxCount))
+			if !rule.MaxVolume.IsZero() && breach.Volume.GreaterThan(rule.MaxVolume) {
+				limit = rule.MaxVolume
+			}
+			return nil, nil, errors.NewVelocityExceededError(accountID, amount, limit)
+		}
+		flagged = append(flagged, breach)
+	}
+
+	t.seq++
+	seq := t.seq
+	t.events[accountID] = append(events, event{seq: seq, at: now, amount: amount})
+	return flagged, func() { t.cancel(accountID, seq) }, nil
+}
+
+// check reports whether counting a transfer of amount at now would breach rule
+func check(rule Rule, events []event, amount decimal.Decimal, now time.Time) (Breach, bool) {
+	breach := Breach{Rule: rule, Count: 1, Volume: amount}
+	since := now.Add(-rule.Window)
+	for _, e := range events {
+		if e.at.After(since) {
+			breach.Count++
+			breach.Volume = breach.Volume.Add(e.amount)
+		}
+	}
+	exceeded := (rule.MaxCount > 0 && breach.Count > rule.MaxCount) ||
+		(!rule.MaxVolume.IsZero() && breach.Volume.GreaterThan(rule.MaxVolume))
+	return breach, exceeded
+}
+
+// cancel uncounts an admitted transfer
+func (t *Tracker) cancel(accountID int64, seq uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.events[accountID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].seq == seq {
+			t.events[accountID] = append(events[:i], events[i+1:]...)
+			return
+		}
+	}
+}
+
+// pruneLocked forgets events older than the longest window, scanning every account at most once
+// per window so memory stays bounded by the accounts active within it
+func (t *Tracker) pruneLocked(now time.Time) {
+	if now.Sub(t.lastPrune) < t.horizon {
+		return
+	}
+	t.lastPrune = now
+	since := now.Add(-t.horizon)
+	for accountID, events := range t.events {
+		kept := events[:0]
+		for _, e := range events {
+			if e.at.After(since) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(t.events, accountID)
+		} else {
+			t.events[accountID] = kept
+		}
+	}
+}
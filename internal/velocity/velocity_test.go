@@ -0,0 +1,100 @@
+package velocity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"hourly": "1h/0/50000/FLAG", "burst": " 5m / 10 / 0 / reject "})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "burst", rules[0].Name)
+	assert.Equal(t, 5*time.Minute, rules[0].Window)
+	assert.Equal(t, 10, rules[0].MaxCount)
+	assert.True(t, rules[0].MaxVolume.IsZero())
+	assert.Equal(t, Reject, rules[0].Action)
+	assert.Equal(t, Flag, rules[1].Action)
+	assert.True(t, decimal.NewFromInt(50000).Equal(rules[1].MaxVolume))
+
+	for _, spec := range []string{
+		"5m/10/0",
+		"0s/10/0/reject",
+		"5m/-1/0/reject",
+		"5m/10/abc/reject",
+		"5m/0/0/reject",
+		"5m/10/0/block",
+	} {
+		_, err := ParseRules(map[string]string{"bad": spec})
+		assert.ErrorIs(t, err, errors.ErrValidationFailed, spec)
+	}
+}
+
+func TestTracker_Admit(t *testing.T) {
+	tracker := NewTracker([]Rule{
+		{Name: "burst", Window: 5 * time.Minute, MaxCount: 3, Action: Reject},
+		{Name: "volume", Window: time.Hour, MaxVolume: decimal.NewFromInt(100), Action: Flag},
+	})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ten := decimal.NewFromInt(10)
+
+	for i := 0; i < 3; i++ {
+		flagged, _, err := tracker.Admit(1, ten, now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		assert.Empty(t, flagged)
+	}
+
+	// The fourth transfer within five minutes is rejected, other accounts are unaffected
+	_, _, err := tracker.Admit(1, ten, now.Add(time.Minute))
+	assert.ErrorIs(t, err, errors.ErrVelocityExceeded)
+	_, _, err = tracker.Admit(2, ten, now.Add(time.Minute))
+	assert.NoError(t, err)
+
+	// Once the window slides past, transfers are admitted again and the hourly volume is flagged
+	flagged, _, err := tracker.Admit(1, decimal.NewFromInt(80), now.Add(6*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, flagged, 1)
+	assert.Equal(t, "volume", flagged[0].Rule.Name)
+	assert.Equal(t, 4, flagged[0].Count)
+	assert.True(t, decimal.NewFromInt(110).Equal(flagged[0].Volume))
+}
+
+func TestTracker_Cancel(t *testing.T) {
+	tracker := NewTracker([]Rule{{Name: "burst", Window: time.Minute, MaxCount: 1, Action: Reject}})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	_, cancel, err := tracker.Admit(1, decimal.NewFromInt(10), now)
+	require.NoError(t, err)
+	_, _, err = tracker.Admit(1, decimal.NewFromInt(10), now)
+	assert.ErrorIs(t, err, errors.ErrVelocityExceeded)
+
+	cancel()
+	_, _, err = tracker.Admit(1, decimal.NewFromInt(10), now)
+	assert.NoError(t, err)
+}
+
+func TestTracker_Prune(t *testing.T) {
+	tracker := NewTracker([]Rule{{Name: "burst", Window: time.Minute, MaxCount: 5, Action: Reject}})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for id := int64(1); id <= 3; id++ {
+		_, _, err := tracker.Admit(id, decimal.NewFromInt(1), now)
+		require.NoError(t, err)
+	}
+	_, _, err := tracker.Admit(4, decimal.NewFromInt(1), now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, tracker.events, 1)
+}
+
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+	flagged, cancel, err := tracker.Admit(1, decimal.NewFromInt(1), time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, flagged)
+	cancel()
+}
@@ -0,0 +1,384 @@
+// Package verify checks global invariants of the transfers database
+package verify
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxViolationsPerCheck caps the rows a single check reports so a badly broken database
+// still produces a readable report
+const maxViolationsPerCheck = 100
+
+// Querier is the subset of *sql.Tx used by checks
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Check is a single named invariant
+type Check struct {
+	Name        string
+	Description string
+	// Run returns one message per violation found
+	Run func(ctx context.Context, q Querier) ([]string, error)
+}
+
+// CheckResult is the outcome of running a Check
+type CheckResult struct {
+	Name       string
+	Violations []string
+	Skipped    string // reason the check did not apply, if any
+	Err        error
+	Duration   time.Duration
+}
+
+// Report is the outcome of a verification run
+type Report struct {
+	Results []CheckResult
+}
+
+// OK reports whether every check ran and found no violation
+func (r *Report) OK() bool {
+	for _, result := range r.Results {
+		if result.Err != nil || len(result.Violations) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Write prints the report in a human readable form
+func (r *Report) Write(w io.Writer) {
+	for _, result := range r.Results {
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(w, "[ERROR] %s: %v\n", result.Name, result.Err)
+		case result.Skipped != "":
+			fmt.Fprintf(w, "[SKIP]  %s: %s\n", result.Name, result.Skipped)
+		case len(result.Violations) > 0:
+			fmt.Fprintf(w, "[FAIL]  %s: %d violation(s) (%s)\n", result.Name, len(result.Violations), result.Duration.Round(time.Millisecond))
+			for _, violation := range result.Violations {
+				fmt.Fprintf(w, "        - %s\n", violation)
+			}
+		default:
+			fmt.Fprintf(w, "[PASS]  %s (%s)\n", result.Name, result.Duration.Round(time.Millisecond))
+		}
+	}
+}
+
+// Checker runs invariant checks against a database
+type Checker struct {
+	db     *sql.DB
+	checks []Check
+}
+
+// NewChecker creates a checker with the default set of checks
+func NewChecker(db *sql.DB) *Checker {
+	return &Checker{db: db, checks: DefaultChecks()}
+}
+
+// Run executes every check inside a single REPEATABLE READ read-only transaction, so all
+// checks see the same snapshot even while the system keeps processing transfers
+func (c *Checker) Run(ctx context.Context) (*Report, error) {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error starting snapshot transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &Report{}
+	for _, check := range c.checks {
+		start := time.Now()
+		result := CheckResult{Name: check.Name}
+
+		// A failing query aborts the transaction; the savepoint keeps later checks runnable
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT verify_check"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		result.Violations, result.Err = check.Run(ctx, tx)
+		if result.Err != nil && result.Err != errSkipped {
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT verify_check"); err != nil {
+				return nil, fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+		}
+		if result.Err == errSkipped {
+			result.Err = nil
+			result.Skipped = "not applicable to this schema"
+		}
+		result.Duration = time.Since(start)
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// errSkipped is returned by checks whose tables don't exist in the schema
+var errSkipped = fmt.Errorf("check skipped")
+
+// DefaultChecks returns the invariants checked by the verify command
+func DefaultChecks() []Check {
+	return []Check{
+		{
+			Name:        "total_balance",
+			Description: "sum of balances equals the initial funding of all accounts plus the conversion differences of cross-currency transfers",
+			Run:         checkTotalBalance,
+		},
+		{
+			Name:        "account_history",
+			Description: "each balance equals its initial balance plus the net of its completed transactions",
+			Run:         checkAccountHistory,
+		},
+		{
+			Name:        "negative_balance",
+			Description: "no balance but a system float's is below minus its overdraft limit",
+			Run:         checkNegativeBalances,
+		},
+		{
+			Name:        "orphan_transactions",
+			Description: "every transaction references existing accounts",
+			Run:         checkOrphanTransactions,
+		},
+		{
+			Name:        "transaction_status",
+			Description: "every transaction has a known status",
+			Run:         checkTransactionStatuses,
+		},
+		{
+			Name:        "ledger_entries",
+			Description: "every completed transaction has ledger entries balanced in each currency that move its amount off its source and its credited amount onto its destination",
+			Run:         checkLedgerEntries,
+		},
+		{
+			Name:        "ledger_balance",
+			Description: "each balance equals the credits less the debits of its ledger entries",
+			Run:         checkLedgerBalances,
+		},
+		{
+			Name:        "system_funding",
+			Description: "each system float balance reconciles with its funding history, and only fundings move money through floats",
+			Run:         checkSystemFunding,
+		},
+	}
+}
+
+func checkTotalBalance(ctx context.Context, q Querier) ([]string, error) {
+	// A cross-currency transfer debits and credits different numbers of units, so it changes the
+	// total by the difference
+	var total, funded decimal.Decimal
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COALESCE(SUM(balance), 0) FROM accounts),
+			(SELECT COALESCE(SUM(initial_balance), 0) FROM accounts) +
+			(SELECT COALESCE(SUM(converted_amount - amount), 0) FROM transactions
+			 WHERE converted_amount IS NOT NULL AND status IN ('complete', 'reversed'))
+	`).Scan(&total, &funded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum balances: %w", err)
+	}
+	if !total.Equal(funded) {
+		return []string{fmt.Sprintf("total balance %s differs from total funding %s by %s", total, funded, total.Sub(funded))}, nil
+	}
+	return nil, nil
+}
+
+func checkAccountHistory(ctx context.Context, q Querier) ([]string, error) {
+	return queryViolations(ctx, q, `
+		SELECT format('account %s: balance %s, expected %s from history', a.account_id, a.balance, a.initial_balance + COALESCE(h.net, 0))
+		FROM accounts a
+		LEFT JOIN (
+			SELECT account_id, SUM(delta) AS net
+			FROM (
+				SELECT destination_account_id AS account_id, COALESCE(converted_amount, amount) AS delta FROM transactions WHERE status IN ('complete', 'reversed')
+				UNION ALL
+				SELECT source_account_id, -amount FROM transactions WHERE status IN ('complete', 'reversed')
+			) deltas
+			GROUP BY account_id
+		) h ON h.account_id = a.account_id
+		WHERE a.balance <> a.initial_balance + COALESCE(h.net, 0)
+		ORDER BY a.account_id
+		LIMIT $1
+	`)
+}
+
+func checkNegativeBalances(ctx context.Context, q Querier) ([]string, error) {
+	// System float accounts go negative by the money funded into the system
+	exempt := ""
+	floats, err := tableExists(ctx, q, "system_float_accounts")
+	if err != nil {
+		return nil, err
+	}
+	if floats {
+		exempt = "AND account_id NOT IN (SELECT account_id FROM system_float_accounts)"
+	}
+
+	return queryViolations(ctx, q, `
+		SELECT format('account %s has balance %s, beyond its overdraft limit %s', account_id, balance, overdraft_limit)
+		FROM accounts
+		WHERE balance < -overdraft_limit `+exempt+`
+		ORDER BY account_id
+		LIMIT $1
+	`)
+}
+
+func checkOrphanTransactions(ctx context.Context, q Querier) ([]string, error) {
+	return queryViolations(ctx, q, `
+		SELECT format('transaction %s references missing account(s): source=%s, destination=%s', t.id, t.source_account_id, t.destination_account_id)
+		FROM transactions t
+		LEFT JOIN accounts s ON s.account_id = t.source_account_id
+		LEFT JOIN accounts d ON d.account_id = t.destination_account_id
+		WHERE s.account_id IS NULL OR d.account_id IS NULL
+		ORDER BY t.id
+		LIMIT $1
+	`)
+}
+
+func checkTransactionStatuses(ctx context.Context, q Querier) ([]string, error) {
+	return queryViolations(ctx, q, `
+		SELECT format('transaction %s has unknown status %L', id, status)
+		FROM transactions
+		WHERE status NOT IN ('pending', 'complete', 'failed', 'reversed', 'pending_approval', 'rejected')
+		ORDER BY id
+		LIMIT $1
+	`)
+}
+
+func checkLedgerEntries(ctx context.Context, q Querier) ([]string, error) {
+	exists, err := tableExists(ctx, q, "ledger_entries")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errSkipped
+	}
+
+	return queryViolations(ctx, q, `
+		SELECT format('transaction %s: debits %s / credits %s%s', transaction_id, debits, credits,
+			CASE WHEN currency <> '' THEN ' in ' || currency ELSE '' END)
+		FROM (
+			SELECT transaction_id, COALESCE(currency, '') AS currency,
+				COALESCE(SUM(amount) FILTER (WHERE entry_type = 'debit'), 0) AS debits,
+				COALESCE(SUM(amount) FILTER (WHERE entry_type = 'credit'), 0) AS credits
+			FROM ledger_entries
+			WHERE transaction_id IS NOT NULL
+			GROUP BY transaction_id, COALESCE(currency, '')
+		) c
+		WHERE debits <> credits
+		UNION ALL
+		SELECT format('transaction %s: moves %s on source %s and %s on destination %s, amount %s, credited %s', t.id,
+			COALESCE(SUM(CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE -e.amount END) FILTER (WHERE e.account_id = t.source_account_id), 0),
+			t.source_account_id,
+			COALESCE(SUM(CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE -e.amount END) FILTER (WHERE e.account_id = t.destination_account_id), 0),
+			t.destination_account_id, t.amount, COALESCE(t.converted_amount, t.amount))
+		FROM transactions t
+		LEFT JOIN ledger_entries e ON e.transaction_id = t.id
+		WHERE t.status IN ('complete', 'reversed')
+		GROUP BY t.id
+		HAVING COUNT(e.id) = 0
+			OR COALESCE(SUM(CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE -e.amount END) FILTER (WHERE e.account_id = t.source_account_id), 0) <> -t.amount
+			OR COALESCE(SUM(CASE WHEN e.entry_type = 'credit' THEN e.amount ELSE -e.amount END) FILTER (WHERE e.account_id = t.destination_account_id), 0) <> COALESCE(t.converted_amount, t.amount)
+		UNION ALL
+		SELECT format('ledger entry %s references missing transaction %s', e.id, e.transaction_id)
+		FROM ledger_entries e
+		LEFT JOIN transactions t ON t.id = e.transaction_id
+		WHERE e.transaction_id IS NOT NULL AND t.id IS NULL
+		LIMIT $1
+	`)
+}
+
+func checkLedgerBalances(ctx context.Context, q Querier) ([]string, error) {
+	exists, err := tableExists(ctx, q, "ledger_entries")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errSkipped
+	}
+
+	return queryViolations(ctx, q, `
+		SELECT format('account %s: balance %s, ledger balance %s', a.account_id, a.balance, COALESCE(l.net, 0))
+		FROM accounts a
+		LEFT JOIN (
+			SELECT account_id, SUM(CASE WHEN entry_type = 'credit' THEN amount ELSE -amount END) AS net
+			FROM ledger_entries
+			WHERE account_id IS NOT NULL
+			GROUP BY account_id
+		) l ON l.account_id = a.account_id
+		WHERE a.balance <> COALESCE(l.net, 0)
+		ORDER BY a.account_id
+		LIMIT $1
+	`)
+}
+
+func checkSystemFunding(ctx context.Context, q Querier) ([]string, error) {
+	exists, err := tableExists(ctx, q, "system_fundings")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errSkipped
+	}
+
+	return queryViolations(ctx, q, `
+		SELECT format('system float %s (account %s): balance %s, expected %s from funding history',
+			f.currency, a.account_id, a.balance, a.initial_balance - COALESCE(h.net, 0))
+		FROM system_float_accounts f
+		JOIN accounts a ON a.account_id = f.account_id
+		LEFT JOIN (
+			SELECT currency, SUM(CASE WHEN direction = 'fund' THEN amount ELSE -amount END) AS net
+			FROM system_fundings
+			GROUP BY currency
+		) h ON h.currency = f.currency
+		WHERE a.balance <> a.initial_balance - COALESCE(h.net, 0)
+		UNION ALL
+		SELECT format('transaction %s moves %s through system float account %s without a funding record', t.id, t.amount, f.account_id)
+		FROM transactions t
+		JOIN system_float_accounts f ON f.account_id IN (t.source_account_id, t.destination_account_id)
+		LEFT JOIN system_fundings sf ON sf.transaction_id = t.id
+		WHERE t.status IN ('complete', 'reversed') AND sf.id IS NULL
+		UNION ALL
+		SELECT format('funding %s (%s %s of %s for account %s) doesn''t match its transaction %s', sf.id, sf.direction, sf.amount, sf.currency, sf.account_id, t.id)
+		FROM system_fundings sf
+		JOIN system_float_accounts f ON f.currency = sf.currency
+		JOIN transactions t ON t.id = sf.transaction_id
+		WHERE t.status <> 'complete' OR t.amount <> sf.amount
+			OR (sf.direction = 'fund' AND (t.source_account_id <> f.account_id OR t.destination_account_id <> sf.account_id))
+			OR (sf.direction = 'defund' AND (t.source_account_id <> sf.account_id OR t.destination_account_id <> f.account_id))
+		LIMIT $1
+	`)
+}
+
+// queryViolations runs a query returning one violation message per row
+func queryViolations(ctx context.Context, q Querier, query string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, maxViolationsPerCheck)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run check: %w", err)
+	}
+	defer rows.Close()
+
+	var violations []string
+	for rows.Next() {
+		var violation string
+		if err := rows.Scan(&violation); err != nil {
+			return nil, fmt.Errorf("failed to scan violation: %w", err)
+		}
+		violations = append(violations, violation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating violations: %w", err)
+	}
+	return violations, nil
+}
+
+// tableExists reports whether a table is present in the current schema search path
+func tableExists(ctx context.Context, q Querier, table string) (bool, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return exists, nil
+}
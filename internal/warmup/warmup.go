@@ -0,0 +1,132 @@
+// Package warmup prepares a freshly started instance before it reports ready, so the first
+// requests after a deploy don't pay for opening database connections, loading the server's
+// catalog caches and filling the account cache.
+package warmup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+)
+
+// Report describes a completed warm-up
+type Report struct {
+	Connections      int    `json:"connections"`
+	PrimedStatements int    `json:"primed_statements"`
+	CachedAccounts   int    `json:"cached_accounts"`
+	DurationMs       int64  `json:"duration_ms"`
+	CompletedAt      string `json:"completed_at"`
+}
+
+// State tracks whether the instance finished warming up. The zero value is not ready.
+type State struct {
+	mu     sync.Mutex
+	report *Report
+}
+
+// Ready returns the warm-up report once warm-up completed, or false before
+func (s *State) Ready() (*Report, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.report, s.report != nil
+}
+
+// markReady records the report of the completed warm-up
+func (s *State) markReady(report *Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report = report
+}
+
+// ParseAccountIDs parses the configured hot account IDs
+func ParseAccountIDs(values []string) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: invalid hot account ID %q", errors.ErrValidationFailed, value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Run warms up the instance using the warm-up settings in cfg and marks state ready. It opens
+// WARMUP_CONNECTIONS connections at once, so the pool keeps them idle for the first requests,
+// primes the statements of the transfer path on each unless session features are disabled, and
+// loads the hot accounts into cache, which may be nil if the account cache is disabled. A failing
+// warm-up leaves state not ready.
+func Run(ctx context.Context, db *sql.DB, cache *repository.CachedAccountRepository, cfg *config.Config, state *State) (*Report, error) {
+	hotAccounts, err := ParseAccountIDs(cfg.WarmupHotAccounts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.WarmupTimeout)*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	report := &Report{}
+	if report.Connections, report.PrimedStatements, err = warmConnections(ctx, db, cfg); err != nil {
+		return nil, err
+	}
+	if cache != nil && len(hotAccounts) > 0 {
+		if report.CachedAccounts, err = cache.Warm(ctx, hotAccounts); err != nil {
+			return nil, fmt.Errorf("failed to warm the account cache: %w", err)
+		}
+	}
+	report.DurationMs = time.Since(start).Milliseconds()
+	report.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+
+	logger.Default().Info("Warm-up completed", "duration_ms", report.DurationMs, "connections", report.Connections, "primed_statements", report.PrimedStatements, "cached_accounts", report.CachedAccounts)
+	state.markReady(report)
+	return report, nil
+}
+
+// warmConnections holds the configured number of connections at once, priming each, and then
+// returns them to the pool. It returns the number of connections opened and statements primed.
+func warmConnections(ctx context.Context, db *sql.DB, cfg *config.Config) (int, int, error) {
+	n := cfg.WarmupConnections
+	if n > cfg.MaxIdleConns {
+		// Connections beyond the idle cap would be closed as soon as they are released
+		logger.Default().Warn("WARMUP_CONNECTIONS exceeds MAX_IDLE_CONNECTIONS, warming the idle cap", "warmup_connections", n, "max_idle_conns", cfg.MaxIdleConns)
+		n = cfg.MaxIdleConns
+	}
+
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	primed := 0
+	for len(conns) < n {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return len(conns), primed, fmt.Errorf("failed to open connection %d: %w", len(conns)+1, err)
+		}
+		conns = append(conns, conn)
+		if err := conn.PingContext(ctx); err != nil {
+			return len(conns), primed, fmt.Errorf("failed to ping connection %d: %w", len(conns), err)
+		}
+		if !cfg.SessionFeaturesEnabled() {
+			continue
+		}
+		statements, err := repository.PrimeConn(ctx, conn)
+		primed += statements
+		if err != nil {
+			return len(conns), primed, err
+		}
+	}
+	return len(conns), primed, nil
+}
@@ -0,0 +1,50 @@
+package warmup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/config"
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/repository"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountIDs(t *testing.T) {
+	ids, err := ParseAccountIDs([]string{"1", " 42 "})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 42}, ids)
+
+	_, err = ParseAccountIDs([]string{"1", "hot"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = ParseAccountIDs([]string{"0"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	cache := repository.NewCachedAccountRepository(repository.NewMemoryAccountRepository(repository.NewMemoryStore()), repository.CacheConfig{TTL: time.Minute, MaxEntries: 10})
+	require.NoError(t, cache.CreateAccount(ctx, 1, decimal.NewFromInt(10)))
+
+	var state State
+	_, ready := state.Ready()
+	assert.False(t, ready)
+
+	// A bad hot account list fails before anything is warmed
+	_, err := Run(ctx, nil, cache, &config.Config{WarmupHotAccounts: []string{"x"}, WarmupTimeout: 1000}, &state)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, ready = state.Ready()
+	assert.False(t, ready)
+
+	report, err := Run(ctx, nil, cache, &config.Config{WarmupHotAccounts: []string{"1", "2"}, WarmupTimeout: 1000}, &state)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.CachedAccounts)
+	assert.Zero(t, report.Connections)
+
+	got, ready := state.Ready()
+	assert.True(t, ready)
+	assert.Equal(t, report, got)
+}
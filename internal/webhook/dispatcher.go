@@ -0,0 +1,414 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/khamiruf/internal_transfers_system_go/internal/tracecontext"
+	"github.com/khamiruf/internal_transfers_system_go/internal/worker"
+)
+
+// Headers set on every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderRetryOf   = "X-Webhook-Retry-Of" // set on redrives to the ID of the attempt being retried
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is hex(HMAC-SHA256(secret, timestamp + "." + body)), set when the webhook has a secret
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// EventTest is the event sent by a test-fire
+const EventTest = "webhook.test"
+
+// maxEventLength is the longest event type the delivery log can record
+const maxEventLength = 64
+
+// Update changes a webhook; nil fields are left unchanged and an empty EventTypes subscribes the
+// webhook to every event
+type Update struct {
+	URL        *string
+	Secret     *string
+	Active     *bool
+	EventTypes *[]string
+}
+
+// maxErrorBody caps how much of a failed response body is kept in the delivery log
+const maxErrorBody = 512
+
+// maxRetryDelay caps the backoff between automatic retries of a delivery
+const maxRetryDelay = time.Hour
+
+// Dispatcher delivers events to webhooks and records every attempt
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	now    func() time.Time
+
+	// events are the event types webhooks can subscribe to; any type is accepted if empty
+	events map[string]bool
+	// retries bounds the attempts made to deliver an event; one attempt disables automatic retries
+	retries worker.Policy
+	// deadLetters records deliveries whose last automatic attempt failed, if set
+	deadLetters worker.DeadLetterStore
+	log         *slog.Logger
+}
+
+// Option configures a Dispatcher
+type Option func(*Dispatcher)
+
+// WithEventTypes restricts the event types webhooks can subscribe to to the given ones.
+// Test-fires are delivered regardless of subscriptions.
+func WithEventTypes(events ...string) Option {
+	return func(d *Dispatcher) {
+		d.events = make(map[string]bool, len(events))
+		for _, event := range events {
+			d.events[event] = true
+		}
+	}
+}
+
+// WithRetries retries failed deliveries of an event until maxAttempts attempts were made. The
+// n-th retry is due delay·2^(n-1) after the attempt it retries, capped at an hour; RetryDue
+// makes the retries that are due.
+func WithRetries(maxAttempts int, delay time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.retries = worker.NewPolicy(maxAttempts, delay, maxRetryDelay)
+	}
+}
+
+// WithDeadLetters dead-letters a delivery in store once its last automatic attempt failed.
+// Later attempts, i.e. manual redrives of it, aren't dead-lettered again.
+func WithDeadLetters(store worker.DeadLetterStore) Option {
+	return func(d *Dispatcher) {
+		d.deadLetters = store
+	}
+}
+
+// NewDispatcher creates a dispatcher sending requests with the given timeout
+func NewDispatcher(store Store, timeout time.Duration, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:   store,
+		client:  &http.Client{Timeout: timeout},
+		now:     time.Now,
+		retries: worker.NewPolicy(1, 0, maxRetryDelay),
+		log:     logger.Default(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Register validates and stores a new webhook subscribed to eventTypes, or to every event if
+// eventTypes is empty
+func (d *Dispatcher) Register(ctx context.Context, rawURL, secret string, eventTypes []string) (*Webhook, error) {
+	if err := validateURL(rawURL); err != nil {
+		return nil, err
+	}
+	if err := d.validateEventTypes(eventTypes); err != nil {
+		return nil, err
+	}
+	hook, err := d.store.CreateWebhook(ctx, &Webhook{URL: rawURL, Secret: secret, Active: true, EventTypes: eventTypes})
+	if err != nil {
+		return nil, err
+	}
+	d.log.Info("Registered webhook", "webhook_id", hook.ID, "url", hook.URL)
+	return hook, nil
+}
+
+// validateURL checks a webhook URL is an absolute http(s) URL
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook url must be an absolute http(s) URL", errors.ErrValidationFailed)
+	}
+	return nil
+}
+
+// validateEventTypes checks webhooks can subscribe to every one of eventTypes
+func (d *Dispatcher) validateEventTypes(eventTypes []string) error {
+	seen := make(map[string]bool, len(eventTypes))
+	for _, event := range eventTypes {
+		if event == "" || len(event) > maxEventLength {
+			return fmt.Errorf("%w: event types must be 1 to %d characters", errors.ErrValidationFailed, maxEventLength)
+		}
+		if len(d.events) > 0 && !d.events[event] {
+			return fmt.Errorf("%w: unknown event type %q", errors.ErrValidationFailed, event)
+		}
+		if seen[event] {
+			return fmt.Errorf("%w: duplicate event type %q", errors.ErrValidationFailed, event)
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// Update changes the fields of a webhook set in update
+func (d *Dispatcher) Update(ctx context.Context, webhookID int64, update Update) (*Webhook, error) {
+	hook, err := d.store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if update.URL != nil {
+		if err := validateURL(*update.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *update.URL
+	}
+	if update.EventTypes != nil {
+		if err := d.validateEventTypes(*update.EventTypes); err != nil {
+			return nil, err
+		}
+		hook.EventTypes = *update.EventTypes
+	}
+	if update.Secret != nil {
+		hook.Secret = *update.Secret
+	}
+	if update.Active != nil {
+		hook.Active = *update.Active
+	}
+
+	updated, err := d.store.UpdateWebhook(ctx, hook)
+	if err != nil {
+		return nil, err
+	}
+	d.log.Info("Updated webhook", "webhook_id", updated.ID, "url", updated.URL, "active", updated.Active, "event_types", updated.EventTypes)
+	return updated, nil
+}
+
+// Get retrieves a webhook
+func (d *Dispatcher) Get(ctx context.Context, webhookID int64) (*Webhook, error) {
+	return d.store.GetWebhook(ctx, webhookID)
+}
+
+// List returns every webhook, oldest first
+func (d *Dispatcher) List(ctx context.Context) ([]*Webhook, error) {
+	return d.store.ListWebhooks(ctx)
+}
+
+// Delete removes a webhook and its delivery log; pending retries of its deliveries are dropped
+func (d *Dispatcher) Delete(ctx context.Context, webhookID int64) error {
+	if err := d.store.DeleteWebhook(ctx, webhookID); err != nil {
+		return err
+	}
+	d.log.Info("Deleted webhook", "webhook_id", webhookID)
+	return nil
+}
+
+// Deliveries lists recent delivery attempts of a webhook, newest first
+func (d *Dispatcher) Deliveries(ctx context.Context, webhookID int64, limit int) ([]*Delivery, error) {
+	if _, err := d.store.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return d.store.ListDeliveries(ctx, webhookID, limit)
+}
+
+// Deliver sends an event to a webhook and records the attempt. Delivery failures are recorded
+// on the returned Delivery rather than returned as errors.
+func (d *Dispatcher) Deliver(ctx context.Context, hook *Webhook, event string, payload interface{}) (*Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	return d.send(ctx, hook, &Delivery{WebhookID: hook.ID, Event: event, Payload: body})
+}
+
+// Publish delivers an event to every active webhook subscribed to it. Each attempt is recorded in
+// the delivery log and can be redriven with Retry; only failures to read the webhooks or record
+// attempts are returned.
+func (d *Dispatcher) Publish(ctx context.Context, event string, payload interface{}) error {
+	hooks, err := d.store.ListActiveWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+		if _, err := d.Deliver(ctx, hook, event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestFire sends a test event to a webhook so integrators can check their endpoint
+func (d *Dispatcher) TestFire(ctx context.Context, webhookID int64) (*Delivery, error) {
+	hook, err := d.store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	d.log.Info("Test-firing webhook", "webhook_id", webhookID)
+	return d.Deliver(ctx, hook, EventTest, map[string]interface{}{
+		"webhook_id": webhookID,
+		"sent_at":    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Retry redrives a single failed delivery attempt with its original event and payload.
+// The new attempt is recorded separately and points back at the one it retries.
+func (d *Dispatcher) Retry(ctx context.Context, webhookID, deliveryID int64) (*Delivery, error) {
+	hook, err := d.store.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := d.store.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Succeeded {
+		return nil, fmt.Errorf("%w: delivery %d already succeeded", errors.ErrValidationFailed, deliveryID)
+	}
+
+	d.log.Info("Retrying delivery of webhook", "delivery_id", deliveryID, "webhook_id", webhookID)
+	return d.send(ctx, hook, retryOf(original))
+}
+
+// RetryDue makes up to limit automatic retries that are due and returns the number of failed
+// attempts it handled. Retries for a webhook that was deactivated or unsubscribed from the
+// event since are dropped. Its signature fits a sweeper task.
+func (d *Dispatcher) RetryDue(ctx context.Context, limit int) (int, error) {
+	// A claimed attempt becomes due again if it wasn't retried within the lease, e.g. because
+	// this process stopped
+	lease := d.client.Timeout + time.Minute
+	due, err := d.store.ClaimDueDeliveries(ctx, d.now(), lease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, original := range due {
+		hook, err := d.store.GetWebhook(ctx, original.WebhookID)
+		if stderrors.Is(err, errors.ErrWebhookNotFound) {
+			// Deleted since it was claimed
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !hook.Active || !hook.Subscribes(original.Event) {
+			d.log.Info("Dropping retry of delivery, webhook no longer receives the event", "delivery_id", original.ID, "webhook_id", hook.ID, "event", original.Event)
+			continue
+		}
+		d.log.Info("Retrying delivery of webhook", "delivery_id", original.ID, "webhook_id", hook.ID, "1", original.Attempt+1)
+		if _, err := d.send(ctx, hook, retryOf(original)); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+// retryOf returns the next attempt of a failed delivery
+func retryOf(original *Delivery) *Delivery {
+	return &Delivery{
+		WebhookID: original.WebhookID,
+		Event:     original.Event,
+		Payload:   original.Payload,
+		RetryOf:   &original.ID,
+		Attempt:   original.Attempt + 1,
+	}
+}
+
+// send performs one delivery attempt and records its outcome, scheduling a retry of a failed
+// attempt while attempts remain
+func (d *Dispatcher) send(ctx context.Context, hook *Webhook, delivery *Delivery) (*Delivery, error) {
+	if delivery.Attempt == 0 {
+		delivery.Attempt = 1
+	}
+	start := time.Now()
+	statusCode, err := d.post(ctx, hook, delivery)
+	delivery.LatencyMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.Succeeded = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+		d.log.Warn("Webhook delivery failed", "webhook_id", hook.ID, "event", delivery.Event, "attempt", delivery.Attempt, "err", err)
+		if delivery.Event != EventTest && !d.retries.Exhausted(delivery.Attempt) {
+			next := d.now().Add(d.retries.Backoff(delivery.Attempt))
+			delivery.NextAttemptAt = &next
+		}
+	}
+	recorded, err := d.store.RecordDelivery(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+	if !recorded.Succeeded && recorded.Event != EventTest && recorded.Attempt == d.retries.MaxAttempts {
+		if err := d.deadLetter(ctx, recorded); err != nil {
+			return nil, err
+		}
+	}
+	return recorded, nil
+}
+
+// deadLetter records a delivery whose last automatic attempt failed
+func (d *Dispatcher) deadLetter(ctx context.Context, delivery *Delivery) error {
+	if d.deadLetters == nil {
+		return nil
+	}
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter payload: %w", err)
+	}
+	_, err = d.deadLetters.CreateDeadLetter(ctx, &models.DeadLetter{
+		Kind:        models.DeadLetterKindWebhookDelivery,
+		ReferenceID: delivery.ID,
+		Attempts:    delivery.Attempt,
+		LastError:   delivery.Error,
+		Payload:     payload,
+	})
+	return err
+}
+
+// post sends the delivery and returns the response status code; any non-2xx status is an error
+func (d *Dispatcher) post(ctx context.Context, hook *Webhook, delivery *Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	tracecontext.Inject(ctx, req.Header)
+	if delivery.RetryOf != nil {
+		req.Header.Set(HeaderRetryOf, strconv.FormatInt(*delivery.RetryOf, 10))
+	}
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign computes the signature sent in HeaderSignature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,453 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory Store for dispatcher tests
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   map[int64]*Webhook
+	deliveries []*Delivery
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{webhooks: make(map[int64]*Webhook)}
+}
+
+func (s *memoryStore) CreateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	created := *hook
+	created.ID = s.lastID() + 1
+	s.webhooks[created.ID] = &created
+	return &created, nil
+}
+
+func (s *memoryStore) GetWebhook(ctx context.Context, webhookID int64) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hook, ok := s.webhooks[webhookID]
+	if !ok {
+		return nil, errors.ErrWebhookNotFound
+	}
+	return hook, nil
+}
+
+func (s *memoryStore) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var hooks []*Webhook
+	for id := int64(1); id <= s.lastID(); id++ {
+		if hook, ok := s.webhooks[id]; ok {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memoryStore) UpdateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[hook.ID]; !ok {
+		return nil, errors.ErrWebhookNotFound
+	}
+	updated := *hook
+	s.webhooks[hook.ID] = &updated
+	return &updated, nil
+}
+
+func (s *memoryStore) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[webhookID]; !ok {
+		return errors.ErrWebhookNotFound
+	}
+	delete(s.webhooks, webhookID)
+	kept := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.WebhookID != webhookID {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// lastID returns the highest webhook ID handed out; call with mu held
+func (s *memoryStore) lastID() int64 {
+	var last int64
+	for id := range s.webhooks {
+		if id > last {
+			last = id
+		}
+	}
+	return last
+}
+
+func (s *memoryStore) ListActiveWebhooks(ctx context.Context) ([]*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var hooks []*Webhook
+	for id := int64(1); id <= s.lastID(); id++ {
+		if hook, ok := s.webhooks[id]; ok && hook.Active {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memoryStore) RecordDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recorded := *delivery
+	recorded.ID = int64(len(s.deliveries) + 1)
+	if recorded.Attempt == 0 {
+		recorded.Attempt = 1
+	}
+	for _, d := range s.deliveries {
+		if recorded.RetryOf != nil && d.ID == *recorded.RetryOf {
+			d.NextAttemptAt = nil
+		}
+	}
+	s.deliveries = append(s.deliveries, &recorded)
+	return &recorded, nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*Delivery
+	for _, d := range s.deliveries {
+		if len(due) < limit && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			leased := now.Add(lease)
+			d.NextAttemptAt = &leased
+			claimed := *d
+			due = append(due, &claimed)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) GetDelivery(ctx context.Context, webhookID, deliveryID int64) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID && d.ID == deliveryID {
+			return d, nil
+		}
+	}
+	return nil, errors.ErrDeliveryNotFound
+}
+
+func (s *memoryStore) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []*Delivery
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if s.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, s.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func TestDispatcher_TestFireAndRetry(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	failing := true
+	var lastSignature, lastBody, lastTimestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		lastSignature, lastBody, lastTimestamp = r.Header.Get(HeaderSignature), string(body), r.Header.Get(HeaderTimestamp)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "boom")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := newMemoryStore()
+	dispatcher := NewDispatcher(store, time.Second)
+
+	hook, err := dispatcher.Register(ctx, server.URL, "s3cret", nil)
+	require.NoError(t, err)
+
+	// A failing endpoint is recorded, not returned as an error
+	first, err := dispatcher.TestFire(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.False(t, first.Succeeded)
+	assert.Equal(t, http.StatusInternalServerError, first.StatusCode)
+	assert.Contains(t, first.Error, "boom")
+	assert.Equal(t, EventTest, first.Event)
+	assert.Equal(t, Sign("s3cret", lastTimestamp, []byte(lastBody)), lastSignature)
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+
+	retried, err := dispatcher.Retry(ctx, hook.ID, first.ID)
+	require.NoError(t, err)
+	assert.True(t, retried.Succeeded)
+	assert.Equal(t, http.StatusNoContent, retried.StatusCode)
+	require.NotNil(t, retried.RetryOf)
+	assert.Equal(t, first.ID, *retried.RetryOf)
+	assert.JSONEq(t, string(first.Payload), string(retried.Payload))
+
+	// Successful deliveries are not redriven
+	_, err = dispatcher.Retry(ctx, hook.ID, retried.ID)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	deliveries, err := dispatcher.Deliveries(ctx, hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, retried.ID, deliveries[0].ID)
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	received := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[r.URL.Path]++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := newMemoryStore()
+	dispatcher := NewDispatcher(store, time.Second)
+	first, err := dispatcher.Register(ctx, server.URL+"/first", "", nil)
+	require.NoError(t, err)
+	_, err = dispatcher.Register(ctx, server.URL+"/second", "", nil)
+	require.NoError(t, err)
+	inactive, err := dispatcher.Register(ctx, server.URL+"/inactive", "", nil)
+	require.NoError(t, err)
+	store.webhooks[inactive.ID].Active = false
+
+	require.NoError(t, dispatcher.Publish(ctx, "preauthorization.expired", map[string]int64{"id": 7}))
+	assert.Equal(t, map[string]int{"/first": 1, "/second": 1}, received)
+
+	deliveries, err := dispatcher.Deliveries(ctx, first.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "preauthorization.expired", deliveries[0].Event)
+	assert.JSONEq(t, `{"id": 7}`, string(deliveries[0].Payload))
+}
+
+func TestDispatcher_Errors(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := NewDispatcher(newMemoryStore(), time.Second)
+
+	_, err := dispatcher.Register(ctx, "not a url", "", nil)
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	_, err = dispatcher.TestFire(ctx, 42)
+	assert.ErrorIs(t, err, errors.ErrWebhookNotFound)
+
+	hook, err := dispatcher.Register(ctx, "http://127.0.0.1:1", "", nil)
+	require.NoError(t, err)
+	_, err = dispatcher.Retry(ctx, hook.ID, 99)
+	assert.ErrorIs(t, err, errors.ErrDeliveryNotFound)
+
+	// Unreachable endpoints are recorded without a status code
+	delivery, err := dispatcher.TestFire(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.False(t, delivery.Succeeded)
+	assert.Zero(t, delivery.StatusCode)
+	assert.NotEmpty(t, delivery.Error)
+}
+
+func TestDispatcher_Subscriptions(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	received := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[r.URL.Path] = append(received[r.URL.Path], r.Header.Get(HeaderEvent))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := newMemoryStore()
+	dispatcher := NewDispatcher(store, time.Second, WithEventTypes("transaction.completed", "transaction.failed"))
+
+	_, err := dispatcher.Register(ctx, server.URL+"/all", "", nil)
+	require.NoError(t, err)
+	failures, err := dispatcher.Register(ctx, server.URL+"/failures", "", []string{"transaction.failed"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"transaction.failed"}, failures.EventTypes)
+
+	_, err = dispatcher.Register(ctx, server.URL+"/bad", "", []string{"account.created"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+	_, err = dispatcher.Register(ctx, server.URL+"/bad", "", []string{"transaction.failed", "transaction.failed"})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	require.NoError(t, dispatcher.Publish(ctx, "transaction.completed", map[string]int64{"id": 1}))
+	require.NoError(t, dispatcher.Publish(ctx, "transaction.failed", map[string]int64{"id": 2}))
+	assert.Equal(t, map[string][]string{
+		"/all":      {"transaction.completed", "transaction.failed"},
+		"/failures": {"transaction.failed"},
+	}, received)
+
+	// Resubscribing to every event and deactivating
+	everything := []string{}
+	updated, err := dispatcher.Update(ctx, failures.ID, Update{EventTypes: &everything})
+	require.NoError(t, err)
+	assert.True(t, updated.Subscribes("transaction.completed"))
+
+	inactive := false
+	_, err = dispatcher.Update(ctx, failures.ID, Update{Active: &inactive})
+	require.NoError(t, err)
+	badURL := "ftp://example.com"
+	_, err = dispatcher.Update(ctx, failures.ID, Update{URL: &badURL})
+	assert.ErrorIs(t, err, errors.ErrValidationFailed)
+
+	hooks, err := dispatcher.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.False(t, hooks[1].Active)
+
+	require.NoError(t, dispatcher.Delete(ctx, failures.ID))
+	_, err = dispatcher.Get(ctx, failures.ID)
+	assert.ErrorIs(t, err, errors.ErrWebhookNotFound)
+	assert.ErrorIs(t, dispatcher.Delete(ctx, failures.ID), errors.ErrWebhookNotFound)
+}
+
+func TestDispatcher_RetryDue(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore()
+	dispatcher := NewDispatcher(store, time.Second, WithRetries(3, time.Minute))
+	dispatcher.now = func() time.Time { return now }
+
+	hook, err := dispatcher.Register(ctx, server.URL, "", nil)
+	require.NoError(t, err)
+	first, err := dispatcher.Deliver(ctx, hook, "transaction.completed", map[string]int64{"id": 1})
+	require.NoError(t, err)
+	assert.False(t, first.Succeeded)
+	assert.Equal(t, 1, first.Attempt)
+	require.NotNil(t, first.NextAttemptAt)
+	assert.Equal(t, now.Add(time.Minute), *first.NextAttemptAt)
+
+	// Not due yet
+	n, err := dispatcher.RetryDue(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// The second attempt fails too and backs off twice as long
+	now = now.Add(time.Minute)
+	n, err = dispatcher.RetryDue(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err := dispatcher.Deliveries(ctx, hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	second := deliveries[0]
+	assert.Equal(t, 2, second.Attempt)
+	assert.Equal(t, first.ID, *second.RetryOf)
+	require.NotNil(t, second.NextAttemptAt)
+	assert.Equal(t, now.Add(2*time.Minute), *second.NextAttemptAt)
+	assert.Nil(t, deliveries[1].NextAttemptAt)
+
+	// The last attempt succeeds; nothing is left to retry
+	now = now.Add(2 * time.Minute)
+	n, err = dispatcher.RetryDue(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err = dispatcher.Deliveries(ctx, hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.True(t, deliveries[0].Succeeded)
+	assert.Equal(t, 3, deliveries[0].Attempt)
+
+	now = now.Add(time.Hour)
+	n, err = dispatcher.RetryDue(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// deadLetters is an in-memory dead letter store for dispatcher tests
+type deadLetters []*models.DeadLetter
+
+func (l *deadLetters) CreateDeadLetter(ctx context.Context, letter *models.DeadLetter) (*models.DeadLetter, error) {
+	*l = append(*l, letter)
+	return letter, nil
+}
+
+func TestDispatcher_RetryDueGivesUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryStore()
+	var dead deadLetters
+	dispatcher := NewDispatcher(store, time.Second, WithRetries(2, time.Minute), WithDeadLetters(&dead))
+	dispatcher.now = func() time.Time { return now }
+
+	hook, err := dispatcher.Register(ctx, "http://127.0.0.1:1", "", nil)
+	require.NoError(t, err)
+	first, err := dispatcher.Deliver(ctx, hook, "transaction.failed", map[string]int64{"id": 1})
+	require.NoError(t, err)
+	require.NotNil(t, first.NextAttemptAt)
+
+	now = now.Add(time.Minute)
+	n, err := dispatcher.RetryDue(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err := dispatcher.Deliveries(ctx, hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.False(t, deliveries[0].Succeeded)
+	assert.Nil(t, deliveries[0].NextAttemptAt, "the last attempt is not retried")
+
+	// The last attempt is dead-lettered, but not a manual redrive of it
+	require.Len(t, dead, 1)
+	assert.Equal(t, models.DeadLetterKindWebhookDelivery, dead[0].Kind)
+	assert.Equal(t, deliveries[0].ID, dead[0].ReferenceID)
+	assert.Equal(t, 2, dead[0].Attempts)
+	assert.NotEmpty(t, dead[0].LastError)
+	_, err = dispatcher.Retry(ctx, hook.ID, deliveries[0].ID)
+	require.NoError(t, err)
+	assert.Len(t, dead, 1)
+
+	// Test-fires are never retried automatically, nor dead-lettered
+	test, err := dispatcher.TestFire(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.Nil(t, test.NextAttemptAt)
+	assert.Len(t, dead, 1)
+}
@@ -0,0 +1,329 @@
+// Package webhook delivers event notifications to integrator endpoints and keeps a log of
+// every delivery attempt, so integrators can inspect failures and redrive them
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/errors"
+)
+
+// Webhook is a registered endpoint
+type Webhook struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Secret string `json:"-"`
+	Active bool   `json:"active"`
+	// EventTypes are the events delivered to the webhook; empty subscribes it to every event
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Subscribes checks if event is delivered to the webhook
+func (w *Webhook) Subscribes(event string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one attempt to deliver an event to a webhook
+type Delivery struct {
+	ID         int64           `json:"id"`
+	WebhookID  int64           `json:"webhook_id"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	StatusCode int             `json:"status_code,omitempty"` // zero if no response was received
+	LatencyMs  int64           `json:"latency_ms"`
+	Error      string          `json:"error,omitempty"`
+	Succeeded  bool            `json:"succeeded"`
+	RetryOf    *int64          `json:"retry_of,omitempty"` // the attempt this one redrives
+	Attempt    int             `json:"attempt"`            // 1 for the first attempt of an event
+	// NextAttemptAt is when a failed attempt is retried automatically, nil if it isn't
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Store persists webhooks and their delivery log
+type Store interface {
+	CreateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error)
+	GetWebhook(ctx context.Context, webhookID int64) (*Webhook, error)
+	// ListWebhooks returns every webhook, oldest first
+	ListWebhooks(ctx context.Context) ([]*Webhook, error)
+	// UpdateWebhook replaces the URL, secret, active flag and event types of a webhook
+	UpdateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error)
+	// DeleteWebhook removes a webhook together with its delivery log
+	DeleteWebhook(ctx context.Context, webhookID int64) error
+	// ListActiveWebhooks returns the webhooks events are delivered to, oldest first
+	ListActiveWebhooks(ctx context.Context) ([]*Webhook, error)
+	RecordDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error)
+	GetDelivery(ctx context.Context, webhookID, deliveryID int64) (*Delivery, error)
+	// ListDeliveries returns up to limit attempts for a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]*Delivery, error)
+	// ClaimDueDeliveries returns up to limit failed attempts due for a retry at now, oldest due
+	// first, and pushes their retry back to now+lease so that no other caller claims them while
+	// they are retried. An attempt is no longer due once a retry of it is recorded.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+}
+
+// PostgresStore is a Store backed by the webhooks and webhook_deliveries tables
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new Postgres webhook store
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// CreateWebhook registers a webhook
+func (s *PostgresStore) CreateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error) {
+	created := *hook
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO webhooks (url, secret, active, event_types) VALUES ($1, $2, $3, $4::jsonb)
+		RETURNING id, created_at
+	`, hook.URL, hook.Secret, hook.Active, eventTypesJSON(hook.EventTypes)).Scan(&created.ID, &created.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return &created, nil
+}
+
+// webhookColumns is the column list selected by every webhook read, in scanWebhook order
+const webhookColumns = `id, url, secret, active, event_types, created_at`
+
+// eventTypesJSON encodes event types for the event_types column
+func eventTypesJSON(eventTypes []string) string {
+	if len(eventTypes) == 0 {
+		return "[]"
+	}
+	encoded, _ := json.Marshal(eventTypes)
+	return string(encoded)
+}
+
+func scanWebhook(row rowScanner) (*Webhook, error) {
+	var hook Webhook
+	var eventTypes []byte
+	if err := row.Scan(&hook.ID, &hook.URL, &hook.Secret, &hook.Active, &eventTypes, &hook.CreatedAt); err != nil {
+		return nil, err
+	}
+	hook.EventTypes = []string{}
+	if err := json.Unmarshal(eventTypes, &hook.EventTypes); err != nil {
+		return nil, fmt.Errorf("invalid event types of webhook %d: %w", hook.ID, err)
+	}
+	return &hook, nil
+}
+
+// GetWebhook retrieves a webhook by its ID
+func (s *PostgresStore) GetWebhook(ctx context.Context, webhookID int64) (*Webhook, error) {
+	hook, err := scanWebhook(s.db.QueryRowContext(ctx, `
+		SELECT `+webhookColumns+` FROM webhooks WHERE id = $1
+	`, webhookID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%d", errors.ErrWebhookNotFound, webhookID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+func (s *PostgresStore) ListWebhooks(ctx context.Context) ([]*Webhook, error) {
+	return s.listWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+}
+
+// ListActiveWebhooks returns the webhooks events are delivered to, oldest first
+func (s *PostgresStore) ListActiveWebhooks(ctx context.Context) ([]*Webhook, error) {
+	return s.listWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE active ORDER BY id`)
+}
+
+func (s *PostgresStore) listWebhooks(ctx context.Context, query string) ([]*Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []*Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook replaces the URL, secret, active flag and event types of a webhook
+func (s *PostgresStore) UpdateWebhook(ctx context.Context, hook *Webhook) (*Webhook, error) {
+	updated, err := scanWebhook(s.db.QueryRowContext(ctx, `
+		UPDATE webhooks SET url = $2, secret = $3, active = $4, event_types = $5::jsonb
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		hook.ID, hook.URL, hook.Secret, hook.Active, eventTypesJSON(hook.EventTypes),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: id=%d", errors.ErrWebhookNotFound, hook.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteWebhook removes a webhook together with its delivery log
+func (s *PostgresStore) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("%w: id=%d", errors.ErrWebhookNotFound, webhookID)
+	}
+	return nil
+}
+
+// deliveryColumns is the column list selected by every delivery read, in scanDelivery order
+const deliveryColumns = `id, webhook_id, event, payload, COALESCE(status_code, 0), latency_ms, error, succeeded, retry_of, attempt, next_attempt_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDelivery(row rowScanner) (*Delivery, error) {
+	var d Delivery
+	var payload []byte
+	var retryOf sql.NullInt64
+	var nextAttemptAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.StatusCode, &d.LatencyMs, &d.Error, &d.Succeeded, &retryOf, &d.Attempt, &nextAttemptAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if retryOf.Valid {
+		d.RetryOf = &retryOf.Int64
+	}
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	return &d, nil
+}
+
+// RecordDelivery appends an attempt to the delivery log. Recording a retry cancels the automatic
+// retry of the attempt it redrives.
+func (s *PostgresStore) RecordDelivery(ctx context.Context, delivery *Delivery) (*Delivery, error) {
+	var statusCode sql.NullInt64
+	if delivery.StatusCode != 0 {
+		statusCode = sql.NullInt64{Int64: int64(delivery.StatusCode), Valid: true}
+	}
+	attempt := delivery.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+	recorded, err := scanDelivery(s.db.QueryRowContext(ctx, `
+		WITH redriven AS (
+			UPDATE webhook_deliveries SET next_attempt_at = NULL
+			WHERE id = $8 AND next_attempt_at IS NOT NULL
+		)
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status_code, latency_ms, error, succeeded, retry_of, attempt, next_attempt_at)
+		VALUES ($1, $2, $3::jsonb, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+deliveryColumns,
+		delivery.WebhookID, delivery.Event, string(delivery.Payload), statusCode,
+		delivery.LatencyMs, delivery.Error, delivery.Succeeded, delivery.RetryOf,
+		attempt, delivery.NextAttemptAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return recorded, nil
+}
+
+// GetDelivery retrieves one delivery attempt of a webhook
+func (s *PostgresStore) GetDelivery(ctx context.Context, webhookID, deliveryID int64) (*Delivery, error) {
+	delivery, err := scanDelivery(s.db.QueryRowContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE webhook_id = $1 AND id = $2
+	`, webhookID, deliveryID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: webhook_id=%d, attempt=%d", errors.ErrDeliveryNotFound, webhookID, deliveryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries returns up to limit attempts for a webhook, newest first
+func (s *PostgresStore) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]*Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries returns up to limit failed attempts due for a retry at now, oldest due
+// first, and pushes their retry back to now+lease so that no other caller claims them while they
+// are retried. An attempt is no longer due once a retry of it is recorded.
+func (s *PostgresStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			WHERE d.next_attempt_at <= $1
+			  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries r WHERE r.retry_of = d.id)
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
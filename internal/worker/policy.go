@@ -0,0 +1,46 @@
+// Package worker is the framework shared by background work: the retry policy deciding when a
+// failed attempt is retried and when it is given up, the dead letters recording the work given
+// up on, and a pool of worker goroutines running queued work concurrently. Asynchronous
+// transfers, scheduled transfers and webhook deliveries all retry with a Policy and are
+// dead-lettered once it is exhausted, so every kind of background work fails the same way.
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/models"
+)
+
+// Policy is how failed attempts of a piece of work are retried: after BaseDelay, doubling with
+// each further failure up to MaxDelay, until MaxAttempts attempts were made
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// NewPolicy creates a policy making at least one attempt
+func NewPolicy(maxAttempts int, baseDelay, maxDelay time.Duration) Policy {
+	return Policy{MaxAttempts: max(maxAttempts, 1), BaseDelay: baseDelay, MaxDelay: maxDelay}
+}
+
+// Backoff returns the delay before retrying work whose attempt-th attempt failed, counting from 1
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << max(attempt-1, 0)
+	if delay < p.BaseDelay || delay > p.MaxDelay {
+		// The doubling overflowed or passed the cap
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Exhausted checks if no attempt is left after the attempt-th failed
+func (p Policy) Exhausted(attempt int) bool {
+	return attempt >= p.MaxAttempts
+}
+
+// DeadLetterStore records work given up on. Recording the same work twice keeps the first record.
+type DeadLetterStore interface {
+	CreateDeadLetter(ctx context.Context, letter *models.DeadLetter) (*models.DeadLetter, error)
+}
@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy(4, time.Second, 5*time.Second)
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4), "capped")
+	assert.Equal(t, 5*time.Second, policy.Backoff(80), "overflow is capped")
+
+	assert.False(t, policy.Exhausted(3))
+	assert.True(t, policy.Exhausted(4))
+	assert.True(t, NewPolicy(0, time.Second, time.Minute).Exhausted(1), "at least one attempt")
+}
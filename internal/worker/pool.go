@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/khamiruf/internal_transfers_system_go/internal/clock"
+	"github.com/khamiruf/internal_transfers_system_go/internal/logger"
+	"github.com/khamiruf/internal_transfers_system_go/internal/metrics"
+)
+
+// batchSize is the number of items a worker attempts before checking for cancellation
+const batchSize = 50
+
+// ExecuteFunc attempts at most limit queued items and returns the number it attempted, such as
+// TransactionService.ExecuteQueuedTransferJobs. Concurrent calls must claim different items.
+type ExecuteFunc func(ctx context.Context, limit int) (int, error)
+
+// Task is one kind of queued work run by Concurrency workers
+type Task struct {
+	Name        string
+	Concurrency int
+	Execute     ExecuteFunc
+}
+
+// TaskStats is a snapshot of the counters of one task
+type TaskStats struct {
+	Workers     int    `json:"workers"`
+	Runs        uint64 `json:"runs"`
+	Failures    uint64 `json:"failures"`
+	Attempted   uint64 `json:"attempted"`
+	LastRunAt   string `json:"last_run_at,omitempty"`
+	LastFailure string `json:"last_failure,omitempty"`
+}
+
+// taskCounters are the live counters of a task
+type taskCounters struct {
+	runs        uint64
+	failures    uint64
+	attempted   uint64
+	lastRunAt   time.Time
+	lastFailure string
+}
+
+// Pool runs the workers of its tasks. A worker executes its task until the queue is drained and
+// then sleeps for the poll interval.
+type Pool struct {
+	tasks    []Task
+	interval time.Duration
+	now      func() time.Time
+	liveness *metrics.Workers
+
+	mu       sync.Mutex
+	counters map[string]*taskCounters
+	log      *slog.Logger
+}
+
+// New creates a pool running tasks, polling an empty queue every interval
+func New(interval time.Duration, tasks ...Task) *Pool {
+	counters := make(map[string]*taskCounters, len(tasks))
+	for i := range tasks {
+		tasks[i].Concurrency = max(tasks[i].Concurrency, 1)
+		counters[tasks[i].Name] = &taskCounters{}
+	}
+	return &Pool{
+		tasks:    tasks,
+		interval: interval,
+		now:      time.Now,
+		counters: counters,
+		log:      logger.Default(),
+	}
+}
+
+// SetClock makes the pool stamp its runs with c instead of the wall clock. Call it before Run.
+func (p *Pool) SetClock(c clock.Clock) {
+	p.now = c.Now
+}
+
+// SetLiveness makes every worker beat in workers, under its task name, after each run. Call it
+// before Run.
+func (p *Pool) SetLiveness(workers *metrics.Workers) {
+	p.liveness = workers
+}
+
+// Run starts the workers of every task and waits for them to stop once ctx is cancelled. An item
+// being attempted when ctx is cancelled is finished or rolled back by its database transaction.
+func (p *Pool) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, task := range p.tasks {
+		p.log.Info("Starting workers", "concurrency", task.Concurrency, "name", task.Name, "interval", p.interval)
+		for i := 0; i < task.Concurrency; i++ {
+			wg.Add(1)
+			go func(task Task, worker int) {
+				defer wg.Done()
+				p.liveness.Start(task.Name, worker, p.interval)
+				defer p.liveness.Stop(task.Name, worker)
+				p.work(ctx, task, worker)
+			}(task, i)
+		}
+	}
+	wg.Wait()
+
+	p.log.Info("Workers stopped")
+	return ctx.Err()
+}
+
+// work executes task until its queue is drained or a run fails, then waits for the interval,
+// until ctx is cancelled
+func (p *Pool) work(ctx context.Context, task Task, worker int) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		for ctx.Err() == nil {
+			n, err := task.Execute(ctx, batchSize)
+			p.record(task.Name, n, err)
+			p.liveness.Beat(task.Name, worker)
+			if err != nil {
+				p.log.Error("Worker run failed", "name", task.Name, "err", err)
+				break
+			}
+			if n < batchSize {
+				break
+			}
+		}
+		timer.Reset(p.interval)
+	}
+}
+
+// record updates the counters of a task after a run
+func (p *Pool) record(name string, attempted int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	counters := p.counters[name]
+	counters.runs++
+	counters.attempted += uint64(attempted)
+	counters.lastRunAt = p.now()
+	if err != nil {
+		counters.failures++
+		counters.lastFailure = err.Error()
+	}
+}
+
+// Stats returns the counters of every task, keyed by task name
+func (p *Pool) Stats() map[string]TaskStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]TaskStats, len(p.tasks))
+	for _, task := range p.tasks {
+		counters := p.counters[task.Name]
+		taskStats := TaskStats{
+			Workers:     task.Concurrency,
+			Runs:        counters.runs,
+			Failures:    counters.failures,
+			Attempted:   counters.attempted,
+			LastFailure: counters.lastFailure,
+		}
+		if !counters.lastRunAt.IsZero() {
+			taskStats.LastRunAt = counters.lastRunAt.UTC().Format(time.RFC3339)
+		}
+		stats[task.Name] = taskStats
+	}
+	return stats
+}
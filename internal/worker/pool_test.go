@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 120 queued jobs shared by the workers; the first run fails and is retried after the interval
+	var mu sync.Mutex
+	queued, failedOnce := 120, false
+	execute := func(ctx context.Context, limit int) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failedOnce {
+			failedOnce = true
+			return 0, errors.New("database unavailable")
+		}
+		n := min(limit, queued)
+		queued -= n
+		if queued == 0 {
+			cancel()
+		}
+		return n, nil
+	}
+
+	idle := func(ctx context.Context, limit int) (int, error) { return 0, nil }
+
+	pool := New(time.Millisecond, Task{Name: "transfer_jobs", Concurrency: 3, Execute: execute}, Task{Name: "webhook_retries", Execute: idle})
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool didn't stop after its context was cancelled")
+	}
+
+	mu.Lock()
+	assert.Zero(t, queued)
+	mu.Unlock()
+
+	stats := pool.Stats()
+	jobs := stats["transfer_jobs"]
+	assert.Equal(t, 3, jobs.Workers)
+	assert.Equal(t, uint64(120), jobs.Attempted)
+	assert.Equal(t, uint64(1), jobs.Failures)
+	assert.Equal(t, "database unavailable", jobs.LastFailure)
+	require.NotEmpty(t, jobs.LastRunAt)
+
+	// A task without a concurrency still gets a worker
+	assert.Equal(t, 1, stats["webhook_retries"].Workers)
+	assert.NotZero(t, stats["webhook_retries"].Runs)
+}
@@ -0,0 +1,8 @@
+-- Add value (effective) date to transactions, distinct from the recording time in created_at.
+-- Back-dated corrections carry a value_date earlier than their created_at.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS value_date TIMESTAMP WITH TIME ZONE;
+UPDATE transactions SET value_date = created_at WHERE value_date IS NULL;
+ALTER TABLE transactions ALTER COLUMN value_date SET DEFAULT NOW();
+ALTER TABLE transactions ALTER COLUMN value_date SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_value_date ON transactions(value_date);
@@ -0,0 +1,15 @@
+-- Record each account's funding at creation so total money in the system can be reconciled
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS initial_balance DECIMAL(20,5);
+
+-- Backfill existing accounts: current balance minus the net effect of completed transactions
+UPDATE accounts a
+SET initial_balance = a.balance - COALESCE((
+    SELECT SUM(CASE WHEN t.destination_account_id = a.account_id THEN t.amount ELSE -t.amount END)
+    FROM transactions t
+    WHERE (t.source_account_id = a.account_id OR t.destination_account_id = a.account_id)
+        AND t.status = 'complete'
+), 0)
+WHERE a.initial_balance IS NULL;
+
+ALTER TABLE accounts ALTER COLUMN initial_balance SET DEFAULT 0;
+ALTER TABLE accounts ALTER COLUMN initial_balance SET NOT NULL;
@@ -0,0 +1,8 @@
+-- Single-row lease naming the region allowed to write. Promotion bumps the epoch, which fences
+-- any instance still holding the previous epoch.
+CREATE TABLE IF NOT EXISTS region_lease (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    region VARCHAR(64) NOT NULL,
+    epoch BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
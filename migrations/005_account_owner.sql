@@ -0,0 +1,6 @@
+-- External owner reference and lifecycle status of each account
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS owner_ref VARCHAR(128);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+-- Lookups of all accounts held by an owner
+CREATE INDEX IF NOT EXISTS idx_accounts_owner_ref ON accounts(owner_ref) WHERE owner_ref IS NOT NULL;
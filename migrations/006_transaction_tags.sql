@@ -0,0 +1,5 @@
+-- Free-form labels (cost center, payroll run, ...) attached to transactions
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+-- Containment searches (tags @> '["payroll"]')
+CREATE INDEX IF NOT EXISTS idx_transactions_tags ON transactions USING GIN (tags jsonb_path_ops);
@@ -0,0 +1,13 @@
+-- Client-supplied idempotency key of a transaction: a retried request finds the recorded
+-- transaction instead of transferring twice. The unique index enforces exactly-once recording.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(300);
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_idempotency_key_key ON transactions(idempotency_key);
+
+-- Batches of transfers submitted under one batch-level idempotency key. The fingerprint of the
+-- items detects a key being reused for a different batch.
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    batch_key VARCHAR(128) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    item_count INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
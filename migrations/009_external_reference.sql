@@ -0,0 +1,4 @@
+-- Reference assigned to a transaction by the originating system (payment order ID, file row, ...).
+-- Unique, so the same external payment can't be recorded twice.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_reference VARCHAR(128);
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_external_reference_key ON transactions(external_reference);
@@ -0,0 +1,29 @@
+-- Credits held on the suspense account because their destination could not receive them
+CREATE TABLE IF NOT EXISTS suspense_items (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    resolution_transaction_id BIGINT REFERENCES transactions(id),
+    resolved_by VARCHAR(128),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspense_items_status ON suspense_items(status, id);
+
+-- Audit trail of every action taken on a suspense item
+CREATE TABLE IF NOT EXISTS suspense_item_events (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL REFERENCES suspense_items(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(128) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    transaction_id BIGINT REFERENCES transactions(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspense_item_events_item_id ON suspense_item_events(item_id, id);
@@ -0,0 +1,8 @@
+-- Add the business date each transaction is booked on, derived from the business day cutoff and
+-- timezone and distinct from created_at. Existing rows are booked on their UTC calendar date.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS business_date DATE;
+UPDATE transactions SET business_date = (created_at AT TIME ZONE 'UTC')::date WHERE business_date IS NULL;
+ALTER TABLE transactions ALTER COLUMN business_date SET DEFAULT (NOW() AT TIME ZONE 'UTC')::date;
+ALTER TABLE transactions ALTER COLUMN business_date SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_business_date ON transactions(business_date);
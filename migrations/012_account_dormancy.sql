@@ -0,0 +1,14 @@
+-- Time of the last balance movement of each account, used to detect dormant accounts.
+-- Existing accounts start from their latest transaction, or their creation if they have none.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP WITH TIME ZONE;
+UPDATE accounts a SET last_activity_at = COALESCE(
+    (SELECT MAX(t.created_at) FROM transactions t
+     WHERE t.source_account_id = a.account_id OR t.destination_account_id = a.account_id),
+    a.created_at,
+    NOW()
+) WHERE last_activity_at IS NULL;
+ALTER TABLE accounts ALTER COLUMN last_activity_at SET DEFAULT NOW();
+ALTER TABLE accounts ALTER COLUMN last_activity_at SET NOT NULL;
+
+-- Dormancy sweeps only look at active accounts
+CREATE INDEX IF NOT EXISTS idx_accounts_active_last_activity ON accounts(last_activity_at) WHERE status = 'active';
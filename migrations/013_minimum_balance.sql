@@ -0,0 +1,16 @@
+-- Account type, used to look up the minimum maintained balance of an account
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS account_type VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+-- Append-only audit trail of privileged actions
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(64) NOT NULL,
+    actor VARCHAR(128) NOT NULL,
+    account_id BIGINT,
+    transaction_id BIGINT,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_account_id ON audit_log(account_id, id DESC) WHERE account_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Funds held by active pre-authorizations. They stay in the balance but can't be spent by other
+-- transfers until the pre-authorization is executed or released.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS reserved_balance DECIMAL(20,5) NOT NULL DEFAULT 0 CHECK (reserved_balance >= 0);
+
+-- Two-step transfers: funds are reserved on the source when the pre-authorization is created and
+-- moved when it is executed. Active pre-authorizations past expires_at are released by the sweeper.
+CREATE TABLE IF NOT EXISTS preauthorizations (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    transaction_id BIGINT REFERENCES transactions(id),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The sweeper only looks at active pre-authorizations
+CREATE INDEX IF NOT EXISTS idx_preauthorizations_active_expires_at ON preauthorizations(expires_at) WHERE status = 'active';
@@ -0,0 +1,4 @@
+-- ISO 4217 currency of an account; amounts posted to it must be whole minor units of the
+-- currency. Accounts created before currencies existed have none and are only checked against
+-- the storage precision until one is set.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency CHAR(3);
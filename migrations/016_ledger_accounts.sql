@@ -0,0 +1,25 @@
+-- Chart of accounts of the double-entry ledger. Ledger accounts are identified by a stable code
+-- and may be backed by a balance-carrying account (e.g. the fee income account). Accounts are
+-- deactivated rather than deleted so historical postings keep referring to them.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    code VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    account_type VARCHAR(16) NOT NULL,
+    normal_side VARCHAR(6) NOT NULL,
+    account_id BIGINT REFERENCES accounts(account_id),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_accounts_type ON ledger_accounts(account_type, code);
+
+-- Default internal accounts
+INSERT INTO ledger_accounts (code, name, account_type, normal_side) VALUES
+    ('customer_deposits', 'Customer deposits', 'liability', 'credit'),
+    ('fee_income', 'Fee income', 'income', 'credit'),
+    ('interest_expense', 'Interest expense', 'expense', 'debit'),
+    ('suspense', 'Suspense', 'liability', 'credit'),
+    ('fx_gain', 'FX gain', 'income', 'credit'),
+    ('fx_loss', 'FX loss', 'expense', 'debit')
+ON CONFLICT (code) DO NOTHING;
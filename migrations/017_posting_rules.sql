@@ -0,0 +1,23 @@
+-- Posting templates of the ledger: each transfer type maps to one or more legs, each debiting one
+-- side and crediting the other with the transfer amount. A side is either a party of the transfer
+-- ('@source' or '@destination') or the code of a ledger account. New product flows are added by
+-- configuring rules here instead of changing service code.
+CREATE TABLE IF NOT EXISTS posting_rules (
+    transfer_type VARCHAR(32) NOT NULL,
+    leg SMALLINT NOT NULL CHECK (leg > 0),
+    debit VARCHAR(64) NOT NULL,
+    credit VARCHAR(64) NOT NULL,
+    description VARCHAR(128) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (transfer_type, leg),
+    CHECK (debit <> credit)
+);
+
+-- Default templates
+INSERT INTO posting_rules (transfer_type, leg, debit, credit, description) VALUES
+    ('standard', 1, '@source', '@destination', 'Transfer between customer accounts'),
+    ('fee', 1, '@source', 'fee_income', 'Fee charged to the source'),
+    ('interest', 1, 'interest_expense', '@destination', 'Interest paid to the destination'),
+    ('adjustment', 1, 'suspense', '@destination', 'Manual adjustment funded from suspense'),
+    ('reversal', 1, '@destination', '@source', 'Reversal of an earlier transfer')
+ON CONFLICT (transfer_type, leg) DO NOTHING;
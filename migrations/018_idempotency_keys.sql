@@ -0,0 +1,11 @@
+-- Idempotency keys of single transfers. The response of the first request is stored with its key
+-- in the transaction that made the transfer, so a retry with the same key gets the same response
+-- instead of a second debit. The fingerprint of the request detects a key reused for a different
+-- transfer.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(128) PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id),
+    response JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+-- Keyset pagination of an account's transactions walks (created_at, id) newest first from each
+-- side of the transfer; these indexes serve both halves without sorting the account's history.
+CREATE INDEX IF NOT EXISTS idx_transactions_source_created_id ON transactions(source_account_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination_created_id ON transactions(destination_account_id, created_at DESC, id DESC);
@@ -0,0 +1,28 @@
+-- Double-entry ledger. Every completed transfer posts one debit entry on the source account and
+-- one credit entry on the destination; an account's opening balance is a credit entry without a
+-- transaction. An account's balance is therefore the sum of its credits minus its debits.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id INTEGER REFERENCES transactions(id),
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    entry_type VARCHAR(6) NOT NULL CHECK (entry_type IN ('debit', 'credit')),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- A transfer is posted at most once
+    UNIQUE (transaction_id, entry_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries(account_id, id);
+
+-- Backfill opening balances and the entries of transfers completed before the ledger existed
+INSERT INTO ledger_entries (account_id, entry_type, amount, created_at)
+SELECT a.account_id, 'credit', a.initial_balance, a.created_at
+FROM accounts a
+WHERE a.initial_balance > 0
+  AND NOT EXISTS (SELECT 1 FROM ledger_entries e WHERE e.account_id = a.account_id AND e.transaction_id IS NULL);
+
+INSERT INTO ledger_entries (transaction_id, account_id, entry_type, amount, created_at)
+SELECT id, source_account_id, 'debit', amount, created_at FROM transactions WHERE status = 'complete'
+UNION ALL
+SELECT id, destination_account_id, 'credit', amount, created_at FROM transactions WHERE status = 'complete'
+ON CONFLICT (transaction_id, entry_type) DO NOTHING;
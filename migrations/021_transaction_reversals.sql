@@ -0,0 +1,5 @@
+-- A reversal is a compensating transfer linked to the transaction it reverses; the original is
+-- then marked 'reversed'. The partial unique index allows at most one reversal per transaction.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of INTEGER REFERENCES transactions(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_reversal_of_key ON transactions(reversal_of) WHERE reversal_of IS NOT NULL;
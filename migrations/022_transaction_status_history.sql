@@ -0,0 +1,26 @@
+-- Every status change of a transaction, with when it happened and who made it
+CREATE TABLE IF NOT EXISTS transaction_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_status_history_transaction_id ON transaction_status_history(transaction_id, id);
+
+-- Backfill the history of transactions recorded before it was kept: each was completed when it
+-- was recorded, and a reversed one was reversed when its compensating transfer was recorded
+INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, created_at)
+SELECT t.id, 'pending', 'complete', 'system', t.created_at
+FROM transactions t
+WHERE t.status IN ('complete', 'reversed')
+  AND NOT EXISTS (SELECT 1 FROM transaction_status_history h WHERE h.transaction_id = t.id);
+
+INSERT INTO transaction_status_history (transaction_id, from_status, to_status, actor, created_at)
+SELECT t.id, 'complete', 'reversed', 'system', r.created_at
+FROM transactions t
+JOIN transactions r ON r.reversal_of = t.id
+WHERE t.status = 'reversed'
+  AND NOT EXISTS (SELECT 1 FROM transaction_status_history h WHERE h.transaction_id = t.id AND h.to_status = 'reversed');
@@ -0,0 +1,16 @@
+-- Per-tenant overrides of limits, currency policy and features, so internal business units can
+-- differ in behavior without separate deployments. Settings a tenant doesn't set keep the
+-- deployment-wide behavior.
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id VARCHAR(64) PRIMARY KEY,
+    max_transfer_amount DECIMAL(20,5) CHECK (max_transfer_amount > 0),
+    allowed_currencies JSONB NOT NULL DEFAULT '[]'::jsonb,
+    features JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The tenant an account belongs to; accounts without one follow the deployment-wide behavior
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_tenant_id ON accounts(tenant_id) WHERE tenant_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- A cross-currency transfer debits amount in the source currency and credits converted_amount
+-- in the destination currency; fx_rate is the rate it was converted at. Both are NULL for
+-- transfers within one currency.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS converted_amount DECIMAL(20,5)
+    CONSTRAINT transactions_converted_amount_check CHECK (converted_amount > 0);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(24,12)
+    CONSTRAINT transactions_fx_rate_check CHECK (fx_rate > 0);
@@ -0,0 +1,19 @@
+-- Fee rules charged on transfers. Rules with an account_id apply to transfers from that account;
+-- global rules (account_id NULL) apply to accounts without rules of their own. A percentage
+-- value is in percent of the transfer amount.
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT REFERENCES accounts(account_id) ON DELETE CASCADE,
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('flat', 'percentage')),
+    value DECIMAL(20,5) NOT NULL CHECK (value > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_rules_account_id ON fee_rules(account_id);
+
+-- A fee is collected as its own transfer from the payer to the fees account, linked to the
+-- transfer it was charged on and the rule that charged it
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_of INTEGER REFERENCES transactions(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_rule_id BIGINT REFERENCES fee_rules(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_fee_of ON transactions(fee_of) WHERE fee_of IS NOT NULL;
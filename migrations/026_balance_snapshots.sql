@@ -0,0 +1,12 @@
+-- Balance of each account at fixed instants on the recorded (created_at) axis. Historical
+-- balance queries start from the latest snapshot at or before the requested instant and add
+-- the transactions recorded since, instead of backing out everything recorded after it from
+-- the current balance.
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id) ON DELETE CASCADE,
+    as_of TIMESTAMP WITH TIME ZONE NOT NULL,
+    balance DECIMAL(20,5) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT balance_snapshots_account_id_as_of_key UNIQUE (account_id, as_of)
+);
@@ -0,0 +1,13 @@
+-- A split transfer debits one source once and pays several destinations in one database
+-- transaction, e.g. a payroll run. Each leg is a transaction carrying the split's id.
+CREATE TABLE IF NOT EXISTS split_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    leg_count INTEGER NOT NULL CHECK (leg_count > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS split_id BIGINT REFERENCES split_transfers(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_split_id ON transactions(split_id) WHERE split_id IS NOT NULL;
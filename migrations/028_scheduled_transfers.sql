@@ -0,0 +1,20 @@
+-- Transfers requested now but made at execute_at. The sweeper executes due transfers through the
+-- transaction service; a failed attempt is retried at next_attempt_at until the attempts run out,
+-- after which the transfer is marked failed with the error of its last attempt.
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    execute_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT,
+    transaction_id BIGINT REFERENCES transactions(id),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The sweeper only looks at transfers still waiting for an attempt
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_due ON scheduled_transfers(next_attempt_at) WHERE status = 'scheduled';
@@ -0,0 +1,25 @@
+-- Recurring transfers. The sweeper turns each due occurrence of an active standing order into a
+-- scheduled transfer carrying the order's id, which is then made (and retried) like any other.
+CREATE TABLE IF NOT EXISTS standing_orders (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    destination_account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CHECK (amount > 0),
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE,
+    max_occurrences INTEGER CHECK (max_occurrences > 0),
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- The sweeper only looks at active standing orders
+CREATE INDEX IF NOT EXISTS idx_standing_orders_due ON standing_orders(next_run_at) WHERE status = 'active';
+
+ALTER TABLE scheduled_transfers ADD COLUMN IF NOT EXISTS standing_order_id BIGINT REFERENCES standing_orders(id);
+
+-- An occurrence is scheduled at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_transfers_standing_order ON scheduled_transfers(standing_order_id, execute_at) WHERE standing_order_id IS NOT NULL;
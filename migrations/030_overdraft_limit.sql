@@ -0,0 +1,6 @@
+-- Overdraft facility: an account may go negative down to minus its overdraft limit. Accounts
+-- without one keep the non-negative balance rule.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit DECIMAL(20,5) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit);
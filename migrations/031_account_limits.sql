@@ -0,0 +1,8 @@
+-- Outbound transfer limits of an account: per transaction, per business day and per month. A NULL
+-- limit doesn't apply.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS per_transaction_limit DECIMAL(20,5) CHECK (per_transaction_limit > 0);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS daily_limit DECIMAL(20,5) CHECK (daily_limit > 0);
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS monthly_limit DECIMAL(20,5) CHECK (monthly_limit > 0);
+
+-- Enforcing the limits sums the recent outbound transfers of the source account
+CREATE INDEX IF NOT EXISTS idx_transactions_source_business_date ON transactions(source_account_id, business_date);
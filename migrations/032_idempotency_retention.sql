@@ -0,0 +1,12 @@
+-- Sealed, expiring idempotency responses. A response sealed by the service (encrypted and
+-- authenticated) is stored in sealed_response instead of the plaintext response column. Records
+-- expire at expires_at, after which the key can be reused and the sweeper deletes the record;
+-- records without an expiry are kept.
+ALTER TABLE idempotency_keys ALTER COLUMN response DROP NOT NULL;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS sealed_response BYTEA;
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_response_check;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_response_check CHECK ((response IS NULL) <> (sealed_response IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at) WHERE expires_at IS NOT NULL;
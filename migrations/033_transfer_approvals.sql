@@ -0,0 +1,16 @@
+-- Maker-checker approval of large transfers. A transfer above the approval threshold is recorded
+-- in 'pending_approval' without moving funds, and its request is kept here until a second caller
+-- approves it (the transfer is then made and completes) or rejects it (it becomes 'rejected').
+CREATE TABLE IF NOT EXISTS transfer_approvals (
+    transaction_id INTEGER PRIMARY KEY REFERENCES transactions(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    requested_by VARCHAR(128) NOT NULL,
+    reviewed_by VARCHAR(128),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT transfer_approvals_four_eyes CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+-- Reviewers list the requests still waiting, oldest first
+CREATE INDEX IF NOT EXISTS idx_transfer_approvals_pending ON transfer_approvals(created_at) WHERE status = 'pending';
@@ -0,0 +1,5 @@
+-- Caps on the accounts of a tenant and on the transfers they send per business day, with the
+-- share of a cap from which usage is warned about. Unset caps don't apply.
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS max_accounts BIGINT CHECK (max_accounts > 0);
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS max_daily_transactions BIGINT CHECK (max_daily_transactions > 0);
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS quota_warn_percent SMALLINT CHECK (quota_warn_percent BETWEEN 1 AND 100);
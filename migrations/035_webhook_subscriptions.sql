@@ -0,0 +1,9 @@
+-- Event types a webhook subscribes to; an empty list subscribes it to every event
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS event_types JSONB NOT NULL DEFAULT '[]';
+
+-- Automatic retries: the number of an attempt for its event, and when a failed attempt is retried
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries(next_attempt_at)
+    WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,26 @@
+-- System float accounts: money enters the system by a transfer from the float account of its
+-- currency (funding) and leaves it by a transfer back (defunding), so a float's balance goes
+-- negative by the money funded into the system and is exempt from the balance rule.
+CREATE TABLE IF NOT EXISTS system_float_accounts (
+    currency CHAR(3) PRIMARY KEY,
+    account_id BIGINT NOT NULL UNIQUE REFERENCES accounts(account_id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit OR account_type = 'system_float');
+
+-- Funding history: the transfer of every funding and defunding and who made it
+CREATE TABLE IF NOT EXISTS system_fundings (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    currency CHAR(3) NOT NULL REFERENCES system_float_accounts(currency),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('fund', 'defund')),
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    amount DECIMAL(20,5) NOT NULL CONSTRAINT system_fundings_amount_check CHECK (amount > 0),
+    reference VARCHAR(128) NOT NULL DEFAULT '',
+    actor VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_system_fundings_currency ON system_fundings(currency, id);
@@ -0,0 +1,23 @@
+-- Transfers submitted asynchronously. A submission is queued here and answered at once; worker
+-- goroutines claim queued jobs and make their transfers through the transaction service. A job
+-- the transfer rules reject fails for good with the error code; any other error is retried at
+-- next_attempt_at until the attempts run out.
+CREATE TABLE IF NOT EXISTS transfer_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL,
+    destination_account_id BIGINT NOT NULL,
+    amount DECIMAL(20,5) NOT NULL CONSTRAINT transfer_jobs_amount_check CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'completed', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    transaction_id BIGINT REFERENCES transactions(id),
+    error_code VARCHAR(64),
+    last_error TEXT,
+    submitted_by VARCHAR(128) NOT NULL,
+    idempotency_key VARCHAR(128) UNIQUE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Workers only look at jobs still waiting for an attempt
+CREATE INDEX IF NOT EXISTS idx_transfer_jobs_queued ON transfer_jobs(next_attempt_at, id) WHERE status = 'queued';
@@ -0,0 +1,16 @@
+-- Background work given up on: transfer jobs and scheduled transfers that failed, and webhook
+-- deliveries whose last automatic attempt failed. Each is recorded once, with the error of its
+-- last attempt and a snapshot of the work, so operators can find everything the workers gave up
+-- on in one place.
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('transfer_job', 'scheduled_transfer', 'webhook_delivery')),
+    reference_id BIGINT NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT dead_letters_kind_reference_key UNIQUE (kind, reference_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_kind ON dead_letters(kind, id);
@@ -0,0 +1,12 @@
+-- API credentials and the scopes they grant. Only the SHA-256 hash of a key is stored: the key is
+-- shown once when the credential is issued. A revoked credential is kept for the audit trail.
+CREATE TABLE IF NOT EXISTS api_credentials (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT api_credentials_name_key UNIQUE (name),
+    CONSTRAINT api_credentials_key_hash_key UNIQUE (key_hash)
+);
@@ -0,0 +1,19 @@
+-- Delegations let a grantee, the actor a credential acts as, make transfers on behalf of a
+-- grantor, the external owner of the source accounts, until expires_at. A delegation can be
+-- narrowed to one source account and capped per transfer. Revoked delegations are kept for the
+-- audit trail.
+CREATE TABLE IF NOT EXISTS delegations (
+    id BIGSERIAL PRIMARY KEY,
+    grantor VARCHAR(128) NOT NULL,
+    grantee VARCHAR(128) NOT NULL,
+    source_account_id BIGINT,
+    max_amount DECIMAL(20,5) CONSTRAINT delegations_max_amount_check CHECK (max_amount > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(128) NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT delegations_source_account_id_fkey FOREIGN KEY (source_account_id) REFERENCES accounts(account_id)
+);
+
+-- Transfers look up the delegations a grantee holds from a grantor
+CREATE INDEX IF NOT EXISTS idx_delegations_grant ON delegations(grantor, grantee) WHERE revoked_at IS NULL;
@@ -0,0 +1,14 @@
+-- Timestamped operator notes on accounts, optionally linked to a case in the external support
+-- tracker, so support context lives next to the account. Notes are append-only.
+CREATE TABLE IF NOT EXISTS account_notes (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    body TEXT NOT NULL,
+    case_id VARCHAR(128),
+    author VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT account_notes_account_id_fkey FOREIGN KEY (account_id) REFERENCES accounts(account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_notes_account ON account_notes(account_id, id);
+CREATE INDEX IF NOT EXISTS idx_account_notes_case ON account_notes(case_id) WHERE case_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Keyset pagination of the admin account listing walks (balance, account_id) in either direction
+-- when ordered by balance; ordering by account ID uses the primary key.
+CREATE INDEX IF NOT EXISTS idx_accounts_balance_id ON accounts(balance, account_id);
@@ -0,0 +1,28 @@
+-- Manual balance adjustments: credits and debits an operator makes to an account outside normal
+-- transfers. Each is moved by a transfer from or to the adjustments account and recorded here
+-- with its reason code and the operator who made it.
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transactions(id),
+    account_id BIGINT NOT NULL REFERENCES accounts(account_id),
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('credit', 'debit')),
+    amount DECIMAL(20,5) NOT NULL CONSTRAINT balance_adjustments_amount_check CHECK (amount > 0),
+    reason_code VARCHAR(32) NOT NULL,
+    note VARCHAR(512) NOT NULL DEFAULT '',
+    actor VARCHAR(128) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_account ON balance_adjustments(account_id, id);
+
+-- The posting type of each ledger entry, as in posting_rules, so adjustments, fees and reversals
+-- can be told apart from standard transfers in the ledger
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS transfer_type VARCHAR(32) NOT NULL DEFAULT 'standard';
+
+UPDATE ledger_entries e SET transfer_type = 'fee'
+FROM transactions t
+WHERE t.id = e.transaction_id AND t.fee_of IS NOT NULL AND e.transfer_type = 'standard';
+
+UPDATE ledger_entries e SET transfer_type = 'reversal'
+FROM transactions t
+WHERE t.id = e.transaction_id AND t.reversal_of IS NOT NULL AND e.transfer_type = 'standard';
@@ -0,0 +1,3 @@
+-- Roles of API credentials: client, operator or auditor, each granting a set of scopes on top of
+-- the scopes granted directly. Existing credentials keep their scopes and have no role.
+ALTER TABLE api_credentials ADD COLUMN IF NOT EXISTS roles JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+-- Shared secrets server-to-server callers sign requests with instead of sending their API key.
+-- Unlike a key, a secret is kept as issued: the server needs it to recompute the signatures.
+ALTER TABLE api_credentials ADD COLUMN IF NOT EXISTS signing_secret TEXT;
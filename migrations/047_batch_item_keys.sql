@@ -0,0 +1,26 @@
+-- The key of a batch item's transaction is the digest of its batch key and item key
+-- (models.Batch.ItemKey), replacing "batch:<batch key>/<item key>" in idempotency_key, which
+-- needed 300 characters where every other idempotency key is limited to 128. Keys recorded before
+-- are carried over as their digest, with no owner, matched to the longest batch key they start
+-- with; a key whose batch is gone is hashed as it is, so it stays unique but is no longer looked up.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS batch_item_key VARCHAR(128);
+
+UPDATE transactions t
+SET batch_item_key = encode(sha256(convert_to(
+        '0:' || octet_length(m.batch_key) || ':' || m.batch_key || substr(t.idempotency_key, char_length(m.batch_key) + 8),
+        'UTF8')), 'hex')
+FROM (
+    SELECT DISTINCT ON (t.id) t.id, b.batch_key
+    FROM transactions t
+    JOIN transfer_batches b ON starts_with(t.idempotency_key, 'batch:' || b.batch_key || '/')
+    ORDER BY t.id, char_length(b.batch_key) DESC
+) m
+WHERE t.id = m.id;
+
+UPDATE transactions
+SET batch_item_key = encode(sha256(convert_to(idempotency_key, 'UTF8')), 'hex')
+WHERE idempotency_key IS NOT NULL AND batch_item_key IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_batch_item_key_key ON transactions(batch_item_key);
+DROP INDEX IF EXISTS transactions_idempotency_key_key;
+ALTER TABLE transactions DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,16 @@
+-- Idempotency keys belong to the caller that used them (auth.Owner: the ID of its credential, or
+-- the name of a token caller), so two callers picking the same key neither conflict nor see each
+-- other's transfers. Keys recorded before have no owner and are only matched by requests without
+-- a credential: retries of requests made before this migration by authenticated callers aren't
+-- recognised, so apply it once those have settled.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS owner VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (owner, idempotency_key);
+
+ALTER TABLE transfer_batches ADD COLUMN IF NOT EXISTS owner VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE transfer_batches DROP CONSTRAINT IF EXISTS transfer_batches_pkey;
+ALTER TABLE transfer_batches ADD CONSTRAINT transfer_batches_pkey PRIMARY KEY (owner, batch_key);
+
+ALTER TABLE transfer_jobs ADD COLUMN IF NOT EXISTS owner VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE transfer_jobs DROP CONSTRAINT IF EXISTS transfer_jobs_idempotency_key_key;
+ALTER TABLE transfer_jobs ADD CONSTRAINT transfer_jobs_idempotency_key_key UNIQUE (owner, idempotency_key);
@@ -0,0 +1,34 @@
+-- Ledger entries are posted from the posting template of their transfer type: each leg debits one
+-- side and credits the other. A side is a customer account or a ledger account of the chart; a
+-- ledger account backed by a balance-carrying account is posted against it, one without is kept in
+-- the ledger only, so its entries have no account_id.
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS leg SMALLINT NOT NULL DEFAULT 1 CHECK (leg > 0);
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS ledger_code VARCHAR(64) REFERENCES ledger_accounts(code);
+ALTER TABLE ledger_entries ALTER COLUMN account_id DROP NOT NULL;
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_side_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_side_check CHECK (account_id IS NOT NULL OR ledger_code IS NOT NULL);
+
+-- A transfer is still posted at most once, now one debit and one credit per leg
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_transaction_id_entry_type_key;
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_transaction_leg_key;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_transaction_leg_key UNIQUE (transaction_id, leg, entry_type);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_ledger_code ON ledger_entries(ledger_code, id) WHERE ledger_code IS NOT NULL;
+
+-- The seeded reversal and adjustment templates posted against the money the transfer moves: a
+-- reversal's source is the original destination, and an adjustment moves money from or to the
+-- adjustments account, whichever way it goes. Templates changed since are left alone.
+UPDATE posting_rules SET debit = '@source', credit = '@destination', description = 'Reversal of an earlier transfer, from its destination back to its source'
+WHERE transfer_type = 'reversal' AND leg = 1 AND debit = '@destination' AND credit = '@source'
+  AND NOT EXISTS (SELECT 1 FROM posting_rules WHERE transfer_type = 'reversal' AND leg > 1);
+UPDATE posting_rules SET debit = '@source', credit = '@destination', description = 'Manual adjustment from or to the adjustments account'
+WHERE transfer_type = 'adjustment' AND leg = 1 AND debit = 'suspense' AND credit = '@destination'
+  AND NOT EXISTS (SELECT 1 FROM posting_rules WHERE transfer_type = 'adjustment' AND leg > 1);
+
+-- Fees are credited to fee_income, which must be backed by the fees account to be posted; back it
+-- with the account earlier fees were collected on
+UPDATE ledger_accounts SET account_id = (
+    SELECT destination_account_id FROM transactions WHERE fee_of IS NOT NULL ORDER BY id DESC LIMIT 1
+), updated_at = NOW()
+WHERE code = 'fee_income' AND account_id IS NULL
+  AND EXISTS (SELECT 1 FROM transactions WHERE fee_of IS NOT NULL);
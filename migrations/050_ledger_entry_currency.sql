@@ -0,0 +1,8 @@
+-- A cross-currency transfer is posted in both currencies: each leg that crosses them is split
+-- through fx_position, debited in one currency and credited in the other, so the entries of each
+-- currency balance on their own. Entries posted before are in the currency of their accounts.
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
+
+INSERT INTO ledger_accounts (code, name, account_type, normal_side) VALUES
+    ('fx_position', 'FX position', 'asset', 'debit')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,22 @@
+-- Operators can cancel a queued transfer job, requeue one that failed or waits on a retry, and
+-- change the priority it is claimed in. Workers claim the most urgent ready job first.
+ALTER TABLE transfer_jobs ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal'
+    CONSTRAINT transfer_jobs_priority_check CHECK (priority IN ('critical', 'normal', 'bulk'));
+ALTER TABLE transfer_jobs DROP CONSTRAINT IF EXISTS transfer_jobs_status_check;
+ALTER TABLE transfer_jobs ADD CONSTRAINT transfer_jobs_status_check CHECK (status IN ('queued', 'completed', 'failed', 'cancelled'));
+
+-- Status history of every transfer job, from its submission on, with who changed it and why. A job
+-- has no transaction until it is made, so this is where its history before then is kept.
+CREATE TABLE IF NOT EXISTS transfer_job_events (
+    id BIGSERIAL PRIMARY KEY,
+    job_id BIGINT NOT NULL REFERENCES transfer_jobs(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    priority VARCHAR(10) NOT NULL,
+    actor VARCHAR(128) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfer_job_events_job_id ON transfer_job_events(job_id, id);